@@ -1,13 +1,17 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/dh85/outfitpicker/internal/application/usecases"
 	"github.com/dh85/outfitpicker/internal/cli"
 	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
 	"github.com/dh85/outfitpicker/internal/infrastructure/persistence"
 	infraServices "github.com/dh85/outfitpicker/internal/infrastructure/services"
 	"github.com/dh85/outfitpicker/internal/infrastructure/system"
@@ -15,30 +19,67 @@ import (
 
 var version = "dev"
 
-var bootstrapApplication = func(console cli.Console) (*cli.Application, bool) {
-	deps := newRuntimeDependencies()
+var bootstrapApplication = func(console cli.Console, options cli.GlobalOptions) (*cli.Application, bool) {
+	if _, err := newProfileManager(options.Profile).Active(); err != nil {
+		console.Error(fmt.Sprintf("Failed to load profile: %v", err))
+		if errors.Is(err, domainerrors.ErrProfileNotFound) {
+			console.Info(fmt.Sprintf("Create it with: outfitpicker profile create %s", options.Profile))
+		}
+		return nil, false
+	}
+	deps := newRuntimeDependencies(locateState(options.Profile, options.LockTimeout), options.LockTimeout)
+	deps.ReportWarning = func(err error) {
+		console.Warning(err.Error())
+	}
+	if !options.NoHooks {
+		deps.HookRunner = system.NewHookRunner(os.Stderr)
+	}
 	return cli.BootstrapApplication(deps, console)
 }
 
-var showMainMenu = func(app *cli.Application, console cli.Console) {
+var showMainMenu = func(app *cli.Application, console cli.Console, options cli.GlobalOptions) {
 	outfitService := cli.NewOutfitServiceFromRuntime(app)
-	presentation := cli.NewOutfitPresentation(app, console)
+	presentation := cli.NewOutfitPresentation(app, console).WithPreviewer(cli.NewOutfitPreviewer(options, console))
 	renderer := cli.NewMenuRenderer(console)
 	cli.NewMenuSystem(outfitService, app, presentation, renderer, console).ShowMainMenu()
 }
 
-var executeCommand = cli.ExecuteCommand
+var showTUI = func(app *cli.Application, _ cli.Console) error {
+	return cli.RunTUI(cli.NewOutfitServiceFromRuntime(app), app)
+}
+
+var executeCommand = cli.ExecuteCommandWithOptions
+
+var executeSetupCommand = cli.ExecuteSetupCommand
+
+var chooseProfile = cli.ChooseProfile
 
 var exitProcess = os.Exit
 
 func main() {
-	if printVersion(os.Args[1:], os.Stdout) {
+	console := cli.NewTerminalConsole()
+	options, args, err := cli.ParseGlobalOptions(os.Args[1:])
+	if err == nil {
+		options, err = options.WithProfileFromEnvironment(os.Getenv)
+	}
+	if err != nil {
+		console.Error(err.Error())
+		exitProcess(2)
+		return
+	}
+	if printVersion(args, os.Stdout) {
 		return
 	}
 
-	console := cli.NewTerminalConsole()
-	if len(os.Args) > 1 {
-		if handled, code := executeCommand(os.Args[1:], nil, console); handled {
+	if handled, code := executeSetupCommand(args, newSetupRuntime(options), console); handled {
+		if code != 0 {
+			exitProcess(code)
+		}
+		return
+	}
+
+	if len(args) > 0 {
+		if handled, code := executeCommand(args, nil, console, options); handled {
 			if code != 0 {
 				exitProcess(code)
 			}
@@ -46,19 +87,35 @@ func main() {
 		}
 	}
 
-	app, ok := bootstrapApplication(console)
+	if len(args) == 0 && options.Profile == "" {
+		profile, ok := chooseProfile(newProfileManager(""), console)
+		if !ok {
+			return
+		}
+		options.Profile = profile
+	}
+
+	app, ok := bootstrapApplication(console, options)
 	if !ok {
 		return
 	}
 
-	if handled, code := executeCommand(os.Args[1:], app, console); handled {
+	if handled, code := executeCommand(args, app, console, options); handled {
 		if code != 0 {
 			exitProcess(code)
 		}
 		return
 	}
 
-	showMainMenu(app, console)
+	if options.TUI {
+		if err := showTUI(app, console); err != nil {
+			console.Error(err.Error())
+			exitProcess(1)
+		}
+		return
+	}
+
+	showMainMenu(app, console, options)
 }
 
 func printVersion(args []string, output io.Writer) bool {
@@ -74,20 +131,161 @@ func printVersion(args []string, output io.Writer) bool {
 	}
 }
 
-func newRuntimeDependencies() cli.RuntimeDependencies {
-	configFileService := system.NewFileService[entities.Config](cliConfigFileName())
-	cacheFileService := system.NewFileService[entities.OutfitCache](cliCacheFileName())
-	configRepo := persistence.NewConfigRepository(configFileService)
-	cacheRepo := persistence.NewCacheRepository(cacheFileService)
+// stateLocation is where a profile's config and worn outfits are kept.
+// portable is the wardrobe whose .outfitpicker directory holds them, or empty
+// for the user config directory.
+type stateLocation struct {
+	profile  string
+	portable string
+}
 
-	return cli.RuntimeDependencies{
-		ConfigManager: usecases.NewConfigUseCase(configRepo),
-		CacheManager:  usecases.NewCacheUseCase(cacheRepo),
-		CategorySvc:   infraServices.NewCategoryScanner(system.NewDefaultFileManager()),
-		PathProvider: cli.FuncStoragePathProvider{
-			ConfigPathFunc: configFileService.FilePath,
-			CachePathFunc:  cacheFileService.FilePath,
+func (l stateLocation) directoryProvider() system.DirectoryProvider {
+	if l.portable != "" {
+		return system.NewPortableDirectoryProvider(l.portable)
+	}
+	return system.NewDefaultDirectoryProvider()
+}
+
+// workingWardrobe returns the portable wardrobe the working directory is in.
+var workingWardrobe = func() (string, bool) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	return system.FindPortableWardrobe(dir)
+}
+
+// locateState finds where profile's state is kept. Inside a portable wardrobe
+// that is the wardrobe's own directory. Elsewhere a profile whose wardrobe has
+// been made portable uses the wardrobe's state rather than its own.
+func locateState(profile string, lockTimeout time.Duration) stateLocation {
+	if root, ok := workingWardrobe(); ok {
+		return stateLocation{profile: profile, portable: root}
+	}
+	config, err := newRuntimeDependencies(stateLocation{profile: profile}, lockTimeout).ConfigManager.LoadOrCreate()
+	if err == nil && config != nil && system.IsPortableWardrobe(config.Root) {
+		return stateLocation{portable: config.Root}
+	}
+	return stateLocation{profile: profile}
+}
+
+// newProfileManager manages the profiles next to the default profile's files,
+// inside the portable wardrobe the working directory is in if there is one.
+// Backups stay with the profile they were taken from.
+func newProfileManager(profile string) *usecases.ProfileUseCase {
+	location := stateLocation{}
+	location.portable, _ = workingWardrobe()
+	store := system.NewProfileStore(location.directoryProvider(), persistence.BackupDirName)
+	return usecases.NewProfileUseCase(store, profile)
+}
+
+// newSetupRuntime serves the commands that run before a profile is loaded.
+// init opens the selected profile, and init --portable creates the wardrobe's
+// .outfitpicker directory before storing state in it.
+func newSetupRuntime(options cli.GlobalOptions) cli.SetupRuntime {
+	profiles := newProfileManager(options.Profile)
+	return cli.SetupRuntime{
+		Profiles: profiles,
+		Wardrobes: func() (cli.WardrobeInitializer, error) {
+			if _, err := profiles.Active(); err != nil {
+				return nil, err
+			}
+			deps := newRuntimeDependencies(locateState(options.Profile, options.LockTimeout), options.LockTimeout)
+			return usecases.NewInitUseCase(deps.ConfigManager, deps.CacheManager, func(root string) (usecases.ConfigManager, usecases.CacheManager, error) {
+				if err := system.CreatePortableDirectory(root); err != nil {
+					return nil, nil, err
+				}
+				portable := newRuntimeDependencies(stateLocation{portable: root}, options.LockTimeout)
+				return portable.ConfigManager, portable.CacheManager, nil
+			}), nil
 		},
+	}
+}
+
+func newRuntimeDependencies(location stateLocation, lockTimeout time.Duration) cli.RuntimeDependencies {
+	configFileService := system.NewFileService[entities.Config](cliConfigFileName(),
+		system.WithDataManager[entities.Config](system.NewDefaultDataManager(lockTimeout)),
+		system.WithUpgrader[entities.Config](persistence.ConfigSchema),
+		system.WithDirectoryProvider[entities.Config](location.directoryProvider()),
+		system.WithProfile[entities.Config](location.profile))
+	cacheFileService := system.NewFileService[entities.OutfitCache](cliCacheFileName(),
+		system.WithDataManager[entities.OutfitCache](system.NewDefaultDataManager(lockTimeout)),
+		system.WithUpgrader[entities.OutfitCache](persistence.CacheSchema),
+		system.WithDirectoryProvider[entities.OutfitCache](location.directoryProvider()),
+		system.WithProfile[entities.OutfitCache](location.profile))
+	store := persistence.NewSQLiteStore(func() (string, error) {
+		configPath, err := configFileService.FilePath()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(filepath.Dir(configPath), persistence.SQLiteFileName), nil
+	}, lockTimeout)
+	journal := persistence.NewJournal(func() (string, error) {
+		cachePath, err := cacheFileService.FilePath()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(filepath.Dir(cachePath), persistence.JournalFileName), nil
+	}, func(path string, fn func() error) error {
+		return system.LockPath(path, lockTimeout, fn)
+	})
+	backups := persistence.NewBackupStore(func() (string, error) {
+		configPath, err := configFileService.FilePath()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(filepath.Dir(configPath), persistence.BackupDirName), nil
+	})
+	hashFileService := system.NewFileService[entities.ContentHashIndex](cliHashFileName(),
+		system.WithDataManager[entities.ContentHashIndex](system.NewDefaultDataManager(lockTimeout)),
+		system.WithDirectoryProvider[entities.ContentHashIndex](location.directoryProvider()),
+		system.WithProfile[entities.ContentHashIndex](location.profile))
+	indexFileService := system.NewFileService[entities.WardrobeIndex](cliIndexFileName(),
+		system.WithDataManager[entities.WardrobeIndex](system.NewDefaultDataManager(lockTimeout)),
+		system.WithDirectoryProvider[entities.WardrobeIndex](location.directoryProvider()),
+		system.WithProfile[entities.WardrobeIndex](location.profile))
+	knownFileService := system.NewFileService[entities.KnownWardrobe](cliKnownWardrobeFileName(),
+		system.WithDataManager[entities.KnownWardrobe](system.NewDefaultDataManager(lockTimeout)),
+		system.WithDirectoryProvider[entities.KnownWardrobe](location.directoryProvider()),
+		system.WithProfile[entities.KnownWardrobe](location.profile))
+	queueFileService := system.NewFileService[entities.WearQueue](cliWearQueueFileName(),
+		system.WithDataManager[entities.WearQueue](system.NewDefaultDataManager(lockTimeout)),
+		system.WithDirectoryProvider[entities.WearQueue](location.directoryProvider()),
+		system.WithProfile[entities.WearQueue](location.profile))
+	storage := persistence.NewStorageSelector(configFileService, cacheFileService, store).WithJournal(journal)
+	if location.portable != "" {
+		storage = storage.WithPortableWardrobe(location.portable)
+	}
+	configRepo, cacheRepo := storage.Repositories()
+
+	pathProvider := cli.FuncStoragePathProvider{
+		ConfigPathFunc: configFileService.FilePath,
+		CachePathFunc:  cacheFileService.FilePath,
+	}
+	if storage.Selected() == entities.StorageSQLite {
+		pathProvider = cli.FuncStoragePathProvider{ConfigPathFunc: store.Path, CachePathFunc: store.Path}
+	}
+
+	wardrobe := infraServices.WardrobeSources{
+		system.NewZipSource(),
+		system.NewTarSource(),
+		system.NewDirectorySource(indexFileService),
+	}
+
+	return cli.RuntimeDependencies{
+		ConfigManager:    usecases.NewConfigUseCase(configRepo),
+		CacheManager:     usecases.NewCacheUseCase(cacheRepo),
+		CategorySvc:      infraServices.NewCategoryScanner(wardrobe, infraServices.WithScanContext(cli.InterruptContext)),
+		Installer:        system.NewOutfitInstaller(system.NewDefaultDirectoryProvider(), lockTimeout),
+		SelectionPlugins: system.NewSelectionPluginRunner(os.Stderr),
+		PathProvider:     pathProvider,
+		Storage:          storage,
+		WearHistory:      storage,
+		Journal:          journal,
+		Backups:          backups,
+		Hasher:           system.NewContentHasher(hashFileService),
+		KnownWardrobe:    knownFileService,
+		WearQueue:        queueFileService,
 		ConfigExists: func() bool {
 			path, err := configFileService.FilePath()
 			if err != nil {
@@ -102,3 +300,11 @@ func newRuntimeDependencies() cli.RuntimeDependencies {
 func cliConfigFileName() string { return "config.json" }
 
 func cliCacheFileName() string { return "cache.json" }
+
+func cliHashFileName() string { return "hashes.json" }
+
+func cliIndexFileName() string { return "index.json" }
+
+func cliKnownWardrobeFileName() string { return "known-wardrobe.json" }
+
+func cliWearQueueFileName() string { return "queued-wears.json" }
@@ -2,25 +2,40 @@ package main
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/dh85/outfitpicker/internal/cli"
+	"github.com/dh85/outfitpicker/internal/domain/entities"
 )
 
 func TestMain(t *testing.T) {
 	originalBootstrap := bootstrapApplication
 	originalShowMainMenu := showMainMenu
+	originalShowTUI := showTUI
 	originalExecuteCommand := executeCommand
+	originalExecuteSetupCommand := executeSetupCommand
+	originalChooseProfile := chooseProfile
 	originalExitProcess := exitProcess
 	originalArgs := os.Args
 	t.Cleanup(func() {
 		bootstrapApplication = originalBootstrap
 		showMainMenu = originalShowMainMenu
+		showTUI = originalShowTUI
 		executeCommand = originalExecuteCommand
+		executeSetupCommand = originalExecuteSetupCommand
+		chooseProfile = originalChooseProfile
 		exitProcess = originalExitProcess
 		os.Args = originalArgs
 	})
+	t.Setenv(cli.ProfileEnvironmentVariable, "")
+	chooseProfile = func(cli.ProfileManager, cli.Console) (string, bool) {
+		return entities.DefaultProfile, true
+	}
 
 	t.Run("shows help before bootstrap", func(t *testing.T) {
 		os.Args = []string{"outfitpicker", "--help"}
@@ -28,14 +43,14 @@ func TestMain(t *testing.T) {
 		showCalls := 0
 		executeCalls := 0
 
-		bootstrapApplication = func(cli.Console) (*cli.Application, bool) {
+		bootstrapApplication = func(cli.Console, cli.GlobalOptions) (*cli.Application, bool) {
 			bootstrapCalls++
 			return nil, false
 		}
-		showMainMenu = func(*cli.Application, cli.Console) {
+		showMainMenu = func(*cli.Application, cli.Console, cli.GlobalOptions) {
 			showCalls++
 		}
-		executeCommand = func(args []string, received cli.CommandRuntime, _ cli.Console) (bool, int) {
+		executeCommand = func(args []string, received cli.CommandRuntime, _ cli.Console, _ cli.GlobalOptions) (bool, int) {
 			executeCalls++
 			if received != nil {
 				t.Fatalf("executeCommand runtime = %v, want nil", received)
@@ -64,14 +79,14 @@ func TestMain(t *testing.T) {
 		bootstrapCalls := 0
 		showCalls := 0
 
-		bootstrapApplication = func(cli.Console) (*cli.Application, bool) {
+		bootstrapApplication = func(cli.Console, cli.GlobalOptions) (*cli.Application, bool) {
 			bootstrapCalls++
 			return nil, false
 		}
-		showMainMenu = func(*cli.Application, cli.Console) {
+		showMainMenu = func(*cli.Application, cli.Console, cli.GlobalOptions) {
 			showCalls++
 		}
-		executeCommand = func([]string, cli.CommandRuntime, cli.Console) (bool, int) {
+		executeCommand = func([]string, cli.CommandRuntime, cli.Console, cli.GlobalOptions) (bool, int) {
 			return false, 0
 		}
 
@@ -91,17 +106,17 @@ func TestMain(t *testing.T) {
 		showCalls := 0
 		app := &cli.Application{}
 
-		bootstrapApplication = func(cli.Console) (*cli.Application, bool) {
+		bootstrapApplication = func(cli.Console, cli.GlobalOptions) (*cli.Application, bool) {
 			bootstrapCalls++
 			return app, true
 		}
-		showMainMenu = func(received *cli.Application, _ cli.Console) {
+		showMainMenu = func(received *cli.Application, _ cli.Console, _ cli.GlobalOptions) {
 			showCalls++
 			if received != app {
 				t.Fatalf("showMainMenu() received %p, want %p", received, app)
 			}
 		}
-		executeCommand = func(args []string, _ cli.CommandRuntime, _ cli.Console) (bool, int) {
+		executeCommand = func(args []string, _ cli.CommandRuntime, _ cli.Console, _ cli.GlobalOptions) (bool, int) {
 			if len(args) != 0 {
 				t.Fatalf("executeCommand args = %#v, want empty", args)
 			}
@@ -118,20 +133,128 @@ func TestMain(t *testing.T) {
 		}
 	})
 
+	t.Run("passes global options to menu and commands", func(t *testing.T) {
+		os.Args = []string{"outfitpicker", "--no-preview", "--no-hooks"}
+		var bootstrapOptions, menuOptions, commandOptions cli.GlobalOptions
+
+		bootstrapApplication = func(_ cli.Console, options cli.GlobalOptions) (*cli.Application, bool) {
+			bootstrapOptions = options
+			return &cli.Application{}, true
+		}
+		showMainMenu = func(_ *cli.Application, _ cli.Console, options cli.GlobalOptions) {
+			menuOptions = options
+		}
+		executeCommand = func(args []string, _ cli.CommandRuntime, _ cli.Console, options cli.GlobalOptions) (bool, int) {
+			if len(args) != 0 {
+				t.Fatalf("executeCommand args = %#v, want global flags removed", args)
+			}
+			commandOptions = options
+			return false, 0
+		}
+
+		main()
+
+		if !menuOptions.NoPreview || !commandOptions.NoPreview {
+			t.Fatalf("options = %+v / %+v, want NoPreview", menuOptions, commandOptions)
+		}
+		if !bootstrapOptions.NoHooks {
+			t.Fatalf("bootstrap options = %+v, want NoHooks", bootstrapOptions)
+		}
+	})
+
+	t.Run("shows full-screen interface with tui flag", func(t *testing.T) {
+		os.Args = []string{"outfitpicker", "--tui"}
+		app := &cli.Application{}
+		tuiCalls := 0
+
+		bootstrapApplication = func(cli.Console, cli.GlobalOptions) (*cli.Application, bool) {
+			return app, true
+		}
+		showMainMenu = func(*cli.Application, cli.Console, cli.GlobalOptions) {
+			t.Fatal("showMainMenu should not be called")
+		}
+		showTUI = func(received *cli.Application, _ cli.Console) error {
+			tuiCalls++
+			if received != app {
+				t.Fatalf("showTUI() received %p, want %p", received, app)
+			}
+			return nil
+		}
+		executeCommand = func(args []string, _ cli.CommandRuntime, _ cli.Console, _ cli.GlobalOptions) (bool, int) {
+			if len(args) != 0 {
+				t.Fatalf("executeCommand args = %#v, want tui flag removed", args)
+			}
+			return false, 0
+		}
+
+		main()
+
+		if tuiCalls != 1 {
+			t.Fatalf("tuiCalls = %d, want 1", tuiCalls)
+		}
+	})
+
+	t.Run("exits on invalid global option", func(t *testing.T) {
+		os.Args = []string{"outfitpicker", "--lock-timeout", "soon", "pick"}
+		gotExitCode := -1
+
+		bootstrapApplication = func(cli.Console, cli.GlobalOptions) (*cli.Application, bool) {
+			t.Fatal("bootstrapApplication should not be called")
+			return nil, false
+		}
+		executeCommand = func([]string, cli.CommandRuntime, cli.Console, cli.GlobalOptions) (bool, int) {
+			t.Fatal("executeCommand should not be called")
+			return false, 0
+		}
+		exitProcess = func(code int) {
+			gotExitCode = code
+		}
+
+		main()
+
+		if gotExitCode != 2 {
+			t.Fatalf("exit code = %d, want 2", gotExitCode)
+		}
+	})
+
+	t.Run("exits when full-screen interface fails", func(t *testing.T) {
+		os.Args = []string{"outfitpicker", "--tui"}
+		gotExitCode := -1
+
+		bootstrapApplication = func(cli.Console, cli.GlobalOptions) (*cli.Application, bool) {
+			return &cli.Application{}, true
+		}
+		showTUI = func(*cli.Application, cli.Console) error {
+			return errors.New("not a terminal")
+		}
+		executeCommand = func([]string, cli.CommandRuntime, cli.Console, cli.GlobalOptions) (bool, int) {
+			return false, 0
+		}
+		exitProcess = func(code int) {
+			gotExitCode = code
+		}
+
+		main()
+
+		if gotExitCode != 1 {
+			t.Fatalf("exit code = %d, want 1", gotExitCode)
+		}
+	})
+
 	t.Run("runs command and skips menu when command is handled", func(t *testing.T) {
 		os.Args = []string{"outfitpicker", "list", "categories"}
 		app := &cli.Application{}
 		showCalls := 0
 		exitCalls := 0
 
-		bootstrapApplication = func(cli.Console) (*cli.Application, bool) {
+		bootstrapApplication = func(cli.Console, cli.GlobalOptions) (*cli.Application, bool) {
 			return app, true
 		}
-		showMainMenu = func(*cli.Application, cli.Console) {
+		showMainMenu = func(*cli.Application, cli.Console, cli.GlobalOptions) {
 			showCalls++
 		}
 		executeCalls := 0
-		executeCommand = func(args []string, received cli.CommandRuntime, _ cli.Console) (bool, int) {
+		executeCommand = func(args []string, received cli.CommandRuntime, _ cli.Console, _ cli.GlobalOptions) (bool, int) {
 			executeCalls++
 			if len(args) != 2 || args[0] != "list" || args[1] != "categories" {
 				t.Fatalf("executeCommand args = %#v, want list categories", args)
@@ -164,19 +287,115 @@ func TestMain(t *testing.T) {
 		}
 	})
 
+	t.Run("runs setup commands before bootstrap", func(t *testing.T) {
+		os.Args = []string{"outfitpicker", "--profile", "alex", "profile", "delete", "sam"}
+		gotExitCode := -1
+
+		bootstrapApplication = func(cli.Console, cli.GlobalOptions) (*cli.Application, bool) {
+			t.Fatal("bootstrapApplication should not be called")
+			return nil, false
+		}
+		executeSetupCommand = func(args []string, setup cli.SetupRuntime, _ cli.Console) (bool, int) {
+			if len(args) != 3 || args[0] != "profile" {
+				t.Fatalf("executeSetupCommand args = %#v, want profile delete sam", args)
+			}
+			if setup.Profiles.ActiveProfile() != "alex" || setup.Wardrobes == nil {
+				t.Fatalf("setup = %+v, want alex's profiles and wardrobe setup", setup)
+			}
+			return true, 1
+		}
+		exitProcess = func(code int) {
+			gotExitCode = code
+		}
+
+		main()
+
+		if gotExitCode != 1 {
+			t.Fatalf("exit code = %d, want 1", gotExitCode)
+		}
+	})
+
+	t.Run("asks for a profile before the menu unless one is selected", func(t *testing.T) {
+		executeSetupCommand = originalExecuteSetupCommand
+		executeCommand = func([]string, cli.CommandRuntime, cli.Console, cli.GlobalOptions) (bool, int) {
+			return false, 0
+		}
+		showMainMenu = func(*cli.Application, cli.Console, cli.GlobalOptions) {}
+		var bootstrapProfile string
+		bootstrapApplication = func(_ cli.Console, options cli.GlobalOptions) (*cli.Application, bool) {
+			bootstrapProfile = options.Profile
+			return &cli.Application{}, true
+		}
+		chooseCalls := 0
+		chooseProfile = func(cli.ProfileManager, cli.Console) (string, bool) {
+			chooseCalls++
+			return "sam", true
+		}
+
+		os.Args = []string{"outfitpicker"}
+		main()
+		if chooseCalls != 1 || bootstrapProfile != "sam" {
+			t.Fatalf("chooseCalls = %d, bootstrap profile = %q; want the chosen profile", chooseCalls, bootstrapProfile)
+		}
+
+		t.Setenv(cli.ProfileEnvironmentVariable, "kim")
+		main()
+		if chooseCalls != 1 || bootstrapProfile != "kim" {
+			t.Fatalf("chooseCalls = %d, bootstrap profile = %q; want the environment's profile", chooseCalls, bootstrapProfile)
+		}
+
+		os.Args = []string{"outfitpicker", "--profile", "alex"}
+		main()
+		if chooseCalls != 1 || bootstrapProfile != "alex" {
+			t.Fatalf("chooseCalls = %d, bootstrap profile = %q; want --profile to win", chooseCalls, bootstrapProfile)
+		}
+	})
+
+	t.Run("quits when no profile is chosen", func(t *testing.T) {
+		os.Args = []string{"outfitpicker"}
+		bootstrapApplication = func(cli.Console, cli.GlobalOptions) (*cli.Application, bool) {
+			t.Fatal("bootstrapApplication should not be called")
+			return nil, false
+		}
+		chooseProfile = func(cli.ProfileManager, cli.Console) (string, bool) {
+			return "", false
+		}
+
+		main()
+	})
+
+	t.Run("exits on invalid profile in the environment", func(t *testing.T) {
+		os.Args = []string{"outfitpicker", "pick"}
+		t.Setenv(cli.ProfileEnvironmentVariable, "../alex")
+		gotExitCode := -1
+		executeCommand = func([]string, cli.CommandRuntime, cli.Console, cli.GlobalOptions) (bool, int) {
+			t.Fatal("executeCommand should not be called")
+			return false, 0
+		}
+		exitProcess = func(code int) {
+			gotExitCode = code
+		}
+
+		main()
+
+		if gotExitCode != 2 {
+			t.Fatalf("exit code = %d, want 2", gotExitCode)
+		}
+	})
+
 	t.Run("exits with command status when handled command fails", func(t *testing.T) {
 		os.Args = []string{"outfitpicker", "reset"}
 		app := &cli.Application{}
 		gotExitCode := -1
 
-		bootstrapApplication = func(cli.Console) (*cli.Application, bool) {
+		bootstrapApplication = func(cli.Console, cli.GlobalOptions) (*cli.Application, bool) {
 			return app, true
 		}
-		showMainMenu = func(*cli.Application, cli.Console) {
+		showMainMenu = func(*cli.Application, cli.Console, cli.GlobalOptions) {
 			t.Fatal("showMainMenu should not be called")
 		}
 		executeCalls := 0
-		executeCommand = func([]string, cli.CommandRuntime, cli.Console) (bool, int) {
+		executeCommand = func([]string, cli.CommandRuntime, cli.Console, cli.GlobalOptions) (bool, int) {
 			executeCalls++
 			if executeCalls == 1 {
 				return false, 0
@@ -233,3 +452,221 @@ func TestPrintVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestNewRuntimeDependencies_UsesSelectedStorage(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	dataDir := filepath.Join(configHome, "outfitpicker")
+
+	cachePath, err := newRuntimeDependencies(stateLocation{}, 0).PathProvider.CacheFilePath()
+	if err != nil || cachePath != filepath.Join(dataDir, "cache.json") {
+		t.Fatalf("CacheFilePath() = %q, %v; want cache.json", cachePath, err)
+	}
+
+	if err := os.MkdirAll(dataDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, "config.json"), []byte(`{"version":1,"storage":"sqlite"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	deps := newRuntimeDependencies(stateLocation{}, 0)
+	for name, path := range map[string]func() (string, error){
+		"config": deps.PathProvider.ConfigFilePath,
+		"cache":  deps.PathProvider.CacheFilePath,
+	} {
+		if got, err := path(); err != nil || got != filepath.Join(dataDir, "outfitpicker.db") {
+			t.Fatalf("%s path = %q, %v; want the database", name, got, err)
+		}
+	}
+	if config, err := deps.ConfigManager.LoadOrCreate(); err != nil || config != nil {
+		t.Fatalf("Load() = %+v, %v; want an empty database", config, err)
+	}
+	if !deps.ConfigExists() {
+		t.Fatal("ConfigExists() = false, want config.json found")
+	}
+}
+
+func TestNewRuntimeDependencies_JournalsChangesNextToCache(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	deps := newRuntimeDependencies(stateLocation{}, 0)
+	if err := deps.ConfigManager.Save(&entities.Config{Root: filepath.Join(configHome, "wardrobe"), Language: "en"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	events, err := deps.Journal.Events()
+	if err != nil || len(events) != 2 || events[0].Type != entities.JournalBaseline || events[1].Type != entities.JournalConfigUpdate {
+		t.Fatalf("Events() = %+v, %v; want a baseline and the config save", events, err)
+	}
+	if _, err := os.Stat(filepath.Join(configHome, "outfitpicker", "journal.jsonl")); err != nil {
+		t.Fatalf("journal missing: %v", err)
+	}
+}
+
+func TestNewRuntimeDependencies_KeepsProfilesApart(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	if _, err := newProfileManager("").CreateProfile("alex"); err != nil {
+		t.Fatalf("CreateProfile() error = %v", err)
+	}
+	deps := newRuntimeDependencies(stateLocation{profile: "alex"}, 0)
+	if err := deps.ConfigManager.Save(&entities.Config{Root: filepath.Join(configHome, "wardrobe"), Language: "en"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	configPath, err := deps.PathProvider.ConfigFilePath()
+	if want := filepath.Join(configHome, "outfitpicker", "profiles", "alex", "config.json"); err != nil || configPath != want {
+		t.Fatalf("ConfigFilePath() = %q, %v; want %q", configPath, err, want)
+	}
+	if config, err := newRuntimeDependencies(stateLocation{}, 0).ConfigManager.LoadOrCreate(); err != nil || config != nil {
+		t.Fatalf("default profile config = %+v, %v; want none", config, err)
+	}
+}
+
+func TestBootstrapApplication_RejectsMissingProfile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	console := &recordingConsole{}
+
+	app, ok := bootstrapApplication(console, cli.GlobalOptions{Profile: "sam"})
+
+	if ok || app != nil {
+		t.Fatalf("bootstrapApplication() = %v, %t; want failure", app, ok)
+	}
+	if output := console.output.String(); !strings.Contains(output, "profile not found: sam") || !strings.Contains(output, "profile create sam") {
+		t.Fatalf("output = %q, want the missing profile and how to create it", output)
+	}
+}
+
+type recordingConsole struct {
+	output bytes.Buffer
+}
+
+func (c *recordingConsole) Prompt(string) string { return "" }
+func (c *recordingConsole) Println(args ...any)  { fmt.Fprintln(&c.output, args...) }
+func (c *recordingConsole) Printf(format string, args ...any) {
+	fmt.Fprintf(&c.output, format, args...)
+}
+func (c *recordingConsole) Info(message string)    { c.Println(message) }
+func (c *recordingConsole) Error(message string)   { c.Println(message) }
+func (c *recordingConsole) Warning(message string) { c.Println(message) }
+func (c *recordingConsole) Success(message string) { c.Println(message) }
+
+func TestNewRuntimeDependencies_KeepsBackupsNextToConfig(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	deps := newRuntimeDependencies(stateLocation{}, 0)
+	if err := deps.ConfigManager.Save(&entities.Config{Root: filepath.Join(configHome, "wardrobe"), Language: "en"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	config, err := deps.ConfigManager.LoadOrCreate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backup, err := deps.Backups.Create(entities.BackupManual, "", entities.BackupContents{Config: config}, "")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if want := filepath.Join(configHome, "outfitpicker", "backups"); filepath.Dir(backup.Path) != want {
+		t.Fatalf("backup path = %s, want it in %s", backup.Path, want)
+	}
+}
+
+func portableTestWardrobe(t *testing.T) string {
+	t.Helper()
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := os.MkdirTemp(homeDir, "outfitpicker-portable-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(root) })
+	return root
+}
+
+func stubWorkingWardrobe(t *testing.T, root string) {
+	t.Helper()
+	original := workingWardrobe
+	t.Cleanup(func() { workingWardrobe = original })
+	workingWardrobe = func() (string, bool) { return root, root != "" }
+}
+
+func TestLocateState(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	root := portableTestWardrobe(t)
+	stubWorkingWardrobe(t, "")
+
+	if location := locateState("alex", 0); location != (stateLocation{profile: "alex"}) {
+		t.Fatalf("locateState() = %+v, want the profile's own state", location)
+	}
+	if err := newRuntimeDependencies(stateLocation{}, 0).ConfigManager.Save(&entities.Config{Root: root, Language: "en"}); err != nil {
+		t.Fatal(err)
+	}
+	if location := locateState("", 0); location != (stateLocation{}) {
+		t.Fatalf("locateState() = %+v, want the profile's own state before the wardrobe is portable", location)
+	}
+	if err := os.Mkdir(filepath.Join(root, entities.PortableDirName), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if location := locateState("", 0); location != (stateLocation{portable: root}) {
+		t.Fatalf("locateState() = %+v, want the portable wardrobe", location)
+	}
+
+	stubWorkingWardrobe(t, root)
+	if location := locateState("alex", 0); location != (stateLocation{profile: "alex", portable: root}) {
+		t.Fatalf("locateState() inside the wardrobe = %+v, want its alex profile", location)
+	}
+	if _, err := newProfileManager("").CreateProfile("alex"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, entities.PortableDirName, "profiles", "alex")); err != nil {
+		t.Fatalf("profile created outside the wardrobe: %v", err)
+	}
+}
+
+func TestNewSetupRuntime_InitPortableCarriesProfileState(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	root := portableTestWardrobe(t)
+	stubWorkingWardrobe(t, "")
+
+	deps := newRuntimeDependencies(stateLocation{}, 0)
+	if err := deps.ConfigManager.Save(&entities.Config{Root: root, Language: "fr"}); err != nil {
+		t.Fatal(err)
+	}
+	cache := entities.NewOutfitCache().Updating("casual", entities.NewCategoryCache(2).Adding("a.avatar"))
+	if err := deps.CacheManager.Save(&cache); err != nil {
+		t.Fatal(err)
+	}
+
+	wardrobes, err := newSetupRuntime(cli.GlobalOptions{}).Wardrobes()
+	if err != nil {
+		t.Fatalf("Wardrobes() error = %v", err)
+	}
+	if _, carried, err := wardrobes.InitPortable(root, "en"); err != nil || !carried {
+		t.Fatalf("InitPortable() = %t, %v; want the profile carried over", carried, err)
+	}
+	stored, err := os.ReadFile(filepath.Join(root, entities.PortableDirName, "config.json"))
+	if err != nil || !strings.Contains(string(stored), `"root": "."`) {
+		t.Fatalf("portable config.json = %s, %v; want the root stored relative to the wardrobe", stored, err)
+	}
+
+	portable := newRuntimeDependencies(locateState("", 0), 0)
+	config, err := portable.ConfigManager.LoadOrCreate()
+	if err != nil || config.Root != root || config.Language != "fr" {
+		t.Fatalf("portable config = %+v, %v; want the profile's settings for %s", config, err, root)
+	}
+	carriedCache, err := portable.CacheManager.LoadOrCreate()
+	if err != nil || !carriedCache.Categories["casual"].WornOutfits["a.avatar"] {
+		t.Fatalf("portable cache = %+v, %v; want the worn outfits", carriedCache, err)
+	}
+
+	if _, _, err := wardrobes.InitPortable(root, "en"); err == nil {
+		t.Fatal("InitPortable() again error = nil, want already set up")
+	}
+	if _, err := newSetupRuntime(cli.GlobalOptions{Profile: "sam"}).Wardrobes(); err == nil {
+		t.Fatal("Wardrobes() for a missing profile error = nil")
+	}
+}
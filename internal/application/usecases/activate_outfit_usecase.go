@@ -0,0 +1,80 @@
+package usecases
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+	"github.com/dh85/outfitpicker/internal/domain/logic"
+)
+
+type ActivateOutfitUseCase struct {
+	categoryService interfaces.CategoryService
+	configManager   ConfigManager
+	installer       interfaces.OutfitInstaller
+}
+
+func NewActivateOutfitUseCase(categoryService interfaces.CategoryService, configManager ConfigManager, installer interfaces.OutfitInstaller) *ActivateOutfitUseCase {
+	return &ActivateOutfitUseCase{categoryService, configManager, installer}
+}
+
+// Execute installs outfit into the named slot and returns the slot used. An
+// outfit inside an archive cannot be installed.
+func (uc *ActivateOutfitUseCase) Execute(outfit entities.OutfitReference, slotName string) (entities.ActivationSlot, error) {
+	if err := logic.ValidateOutfit(outfit); err != nil {
+		return entities.ActivationSlot{}, err
+	}
+
+	config, slot, err := uc.loadSlot(slotName)
+	if err != nil {
+		return entities.ActivationSlot{}, err
+	}
+
+	files, err := categoryOutfits(uc.categoryService, config, outfit.Category.Name)
+	if err != nil {
+		return entities.ActivationSlot{}, err
+	}
+
+	file, found := findOutfit(files, outfit)
+	if !found {
+		return entities.ActivationSlot{}, errors.ErrNoOutfitsAvailable
+	}
+
+	source := filepath.Join(file.CategoryPath(), file.FileName)
+	if entities.InArchive(source) {
+		return entities.ActivationSlot{}, fmt.Errorf("%s: %w", outfit.FilePath(), errors.ErrOutfitInArchive)
+	}
+	if err := uc.installer.Install(source, slot); err != nil {
+		return entities.ActivationSlot{}, err
+	}
+	return slot, nil
+}
+
+// Restore rolls the named slot back to the previously active outfit.
+func (uc *ActivateOutfitUseCase) Restore(slotName string) (entities.ActivationSlot, error) {
+	_, slot, err := uc.loadSlot(slotName)
+	if err != nil {
+		return entities.ActivationSlot{}, err
+	}
+	if err := uc.installer.Restore(slot); err != nil {
+		return entities.ActivationSlot{}, err
+	}
+	return slot, nil
+}
+
+func (uc *ActivateOutfitUseCase) loadSlot(slotName string) (*entities.Config, entities.ActivationSlot, error) {
+	config, err := uc.configManager.LoadOrCreate()
+	if err != nil {
+		return nil, entities.ActivationSlot{}, err
+	}
+	if config == nil {
+		return nil, entities.ActivationSlot{}, errors.ErrConfigurationNotFound
+	}
+	slot, err := config.Slot(slotName)
+	if err != nil {
+		return nil, entities.ActivationSlot{}, err
+	}
+	return config, slot, nil
+}
@@ -0,0 +1,133 @@
+package usecases
+
+import (
+	stderrors "errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+func TestActivateOutfitUseCase_Execute(t *testing.T) {
+	slot := entities.ActivationSlot{Target: "/test/game/current.avatar", Mode: entities.ActivationModeCopy}
+	outfit := entities.NewOutfitReference("outfit1.avatar", entities.NewCategoryReference("casual", "/test/path/casual"))
+	files := []entities.FileEntry{{FileName: "outfit1.avatar"}}
+
+	t.Run("installs outfit into slot", func(t *testing.T) {
+		installer := &mockOutfitInstaller{}
+		uc := NewActivateOutfitUseCase(&mockCategoryService{outfitsResult: files}, &mockConfigUseCase{loadResult: activationConfig(t, slot)}, installer)
+
+		got, err := uc.Execute(outfit, "game")
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		if got != slot || installer.installSlot != slot {
+			t.Fatalf("Execute() slot = %#v, installed into %#v, want %#v", got, installer.installSlot, slot)
+		}
+		if want := filepath.Join("/test/path", "casual", "outfit1.avatar"); installer.installSource != want {
+			t.Fatalf("install source = %q, want %q", installer.installSource, want)
+		}
+	})
+
+	t.Run("refuses outfits inside archives", func(t *testing.T) {
+		config, err := entities.NewConfig("/test/packs.zip", nil, nil)
+		if err != nil {
+			t.Fatalf("NewConfig() error = %v", err)
+		}
+		if config, err = config.WithSlot("game", slot); err != nil {
+			t.Fatalf("WithSlot() error = %v", err)
+		}
+		installer := &mockOutfitInstaller{}
+		uc := NewActivateOutfitUseCase(&mockCategoryService{outfitsResult: files}, &mockConfigUseCase{loadResult: config}, installer)
+		archived := entities.NewOutfitReference("outfit1.avatar", entities.NewCategoryReference("casual", filepath.Join("/test/packs.zip", "casual")))
+
+		if _, err := uc.Execute(archived, "game"); !stderrors.Is(err, domainerrors.ErrOutfitInArchive) {
+			t.Fatalf("Execute() error = %v, want %v", err, domainerrors.ErrOutfitInArchive)
+		}
+		if installer.installSource != "" {
+			t.Fatalf("installed %q from an archive", installer.installSource)
+		}
+	})
+
+	tests := []struct {
+		name      string
+		outfit    entities.OutfitReference
+		config    *entities.Config
+		configErr error
+		category  *mockCategoryService
+		installer *mockOutfitInstaller
+		slotName  string
+		wantErr   error
+	}{
+		{name: "invalid outfit", outfit: entities.NewOutfitReference("", outfit.Category), config: activationConfig(t, slot), wantErr: nil},
+		{name: "config load fails", outfit: outfit, configErr: assert.AnError, wantErr: assert.AnError},
+		{name: "missing config", outfit: outfit, wantErr: domainerrors.ErrConfigurationNotFound},
+		{name: "unknown slot", outfit: outfit, config: activationConfig(t, slot), slotName: "other", wantErr: domainerrors.ErrSlotNotFound},
+		{name: "outfit lookup fails", outfit: outfit, config: activationConfig(t, slot), category: &mockCategoryService{outfitsError: assert.AnError}, wantErr: assert.AnError},
+		{name: "outfit missing", outfit: outfit, config: activationConfig(t, slot), category: &mockCategoryService{}, wantErr: domainerrors.ErrNoOutfitsAvailable},
+		{name: "install fails", outfit: outfit, config: activationConfig(t, slot), installer: &mockOutfitInstaller{installError: assert.AnError}, wantErr: assert.AnError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			category := tt.category
+			if category == nil {
+				category = &mockCategoryService{outfitsResult: files}
+			}
+			installer := tt.installer
+			if installer == nil {
+				installer = &mockOutfitInstaller{}
+			}
+			slotName := tt.slotName
+			if slotName == "" {
+				slotName = "game"
+			}
+			uc := NewActivateOutfitUseCase(category, &mockConfigUseCase{loadResult: tt.config, loadError: tt.configErr}, installer)
+
+			_, err := uc.Execute(tt.outfit, slotName)
+			if err == nil {
+				t.Fatal("Execute() error = nil, want error")
+			}
+			if tt.wantErr != nil && !stderrors.Is(err, tt.wantErr) {
+				t.Fatalf("Execute() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestActivateOutfitUseCase_Restore(t *testing.T) {
+	slot := entities.ActivationSlot{Target: "/test/game/current.avatar", Mode: entities.ActivationModeSymlink}
+
+	installer := &mockOutfitInstaller{}
+	uc := NewActivateOutfitUseCase(&mockCategoryService{}, &mockConfigUseCase{loadResult: activationConfig(t, slot)}, installer)
+	got, err := uc.Restore("game")
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if got != slot || installer.restoreSlot != slot {
+		t.Fatalf("Restore() slot = %#v, restored %#v, want %#v", got, installer.restoreSlot, slot)
+	}
+
+	if _, err := uc.Restore("other"); !stderrors.Is(err, domainerrors.ErrSlotNotFound) {
+		t.Fatalf("Restore(other) error = %v, want ErrSlotNotFound", err)
+	}
+
+	failing := NewActivateOutfitUseCase(&mockCategoryService{}, &mockConfigUseCase{loadResult: activationConfig(t, slot)}, &mockOutfitInstaller{restoreError: domainerrors.ErrNoPreviousActivation})
+	if _, err := failing.Restore("game"); !stderrors.Is(err, domainerrors.ErrNoPreviousActivation) {
+		t.Fatalf("Restore() error = %v, want ErrNoPreviousActivation", err)
+	}
+}
+
+func activationConfig(t *testing.T, slot entities.ActivationSlot) *entities.Config {
+	t.Helper()
+	config, err := entities.NewConfig("/test/path", nil, nil)
+	if err != nil {
+		t.Fatalf("NewConfig() error = %v", err)
+	}
+	config, err = config.WithSlot("game", slot)
+	if err != nil {
+		t.Fatalf("WithSlot() error = %v", err)
+	}
+	return config
+}
@@ -0,0 +1,174 @@
+package usecases
+
+import (
+	stderrors "errors"
+	"fmt"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+	"github.com/dh85/outfitpicker/internal/domain/validation"
+)
+
+// SnapshotsKept is how many automatic snapshots are kept; older ones are
+// pruned whenever a new one is taken.
+const SnapshotsKept = 10
+
+// Snapshot reasons name the destructive change a snapshot was taken before.
+const (
+	SnapshotFactoryReset   = "factory-reset"
+	SnapshotRootChange     = "root-change"
+	SnapshotReset          = "reset"
+	SnapshotResetAll       = "reset-all"
+	SnapshotJournalRestore = "restore"
+	SnapshotBackupRestore  = "backup-restore"
+	SnapshotReconcile      = "reconcile"
+)
+
+// BackupUseCase saves config and cache to backup archives and restores them.
+type BackupUseCase struct {
+	repo          interfaces.BackupRepository
+	configManager ConfigManager
+	cacheManager  CacheManager
+}
+
+func NewBackupUseCase(repo interfaces.BackupRepository, configManager ConfigManager, cacheManager CacheManager) *BackupUseCase {
+	return &BackupUseCase{repo, configManager, cacheManager}
+}
+
+// Create writes the stored config and cache to a new backup, at path if it is
+// not empty.
+func (uc *BackupUseCase) Create(path string) (entities.BackupInfo, error) {
+	config, err := uc.configManager.LoadOrCreate()
+	if err != nil {
+		return entities.BackupInfo{}, err
+	}
+	if config == nil {
+		return entities.BackupInfo{}, errors.ErrConfigurationNotFound
+	}
+	cache, err := uc.cacheManager.LoadOrCreate()
+	if err != nil {
+		return entities.BackupInfo{}, err
+	}
+	return uc.repo.Create(entities.BackupManual, "", entities.BackupContents{Config: config, Cache: cache}, path)
+}
+
+// Snapshot saves the state about to be changed for reason and prunes old
+// snapshots. config is the configuration to save; nil saves the stored one.
+// A snapshot is best effort: an unreadable cache is left out, and nothing is
+// saved when there is no readable configuration to go with it.
+func (uc *BackupUseCase) Snapshot(reason string, config *entities.Config) (entities.BackupInfo, error) {
+	if config == nil {
+		config, _ = uc.configManager.LoadOrCreate()
+	}
+	if config == nil {
+		return entities.BackupInfo{}, errors.ErrConfigurationNotFound
+	}
+	cache, err := uc.cacheManager.LoadOrCreate()
+	if err != nil {
+		cache = nil
+	}
+	snapshot, err := uc.repo.Create(entities.BackupSnapshot, reason, entities.BackupContents{Config: config, Cache: cache}, "")
+	if err != nil {
+		return entities.BackupInfo{}, err
+	}
+	backups, err := uc.repo.List()
+	if err != nil {
+		return snapshot, err
+	}
+	for _, old := range entities.BackupsBeyond(snapshotsOnly(backups), SnapshotsKept) {
+		if err := uc.repo.Delete(old); err != nil {
+			return snapshot, err
+		}
+	}
+	return snapshot, nil
+}
+
+// List returns the stored backups and snapshots, newest first.
+func (uc *BackupUseCase) List() ([]entities.BackupInfo, error) {
+	return uc.repo.List()
+}
+
+// validateRestoredConfig checks the roots and language of a configuration
+// read from a backup, letting roots go through the symbolic links it follows.
+func validateRestoredConfig(config entities.Config) error {
+	if err := validation.ValidateLanguage(&config.Language); err != nil {
+		return errors.MapError(err)
+	}
+	_, err := config.WithSymlinkPolicy(config.FollowSymlinks, config.SymlinkRoots)
+	return err
+}
+
+// Restore replaces config and cache with the contents of the backup called
+// name once the archive and its configuration have been validated. The
+// state it replaces is snapshotted first, and nothing is restored if that
+// snapshot cannot be saved. Should the cache not be restored, the previous
+// configuration is put back, so that config and cache still go together; a
+// configuration that could not be read is left replaced.
+func (uc *BackupUseCase) Restore(name string) (entities.BackupInfo, entities.BackupContents, error) {
+	backup, contents, err := uc.repo.Open(name)
+	if err != nil {
+		return entities.BackupInfo{}, entities.BackupContents{}, err
+	}
+	config := *contents.Config
+	if err := validateRestoredConfig(config); err != nil {
+		return entities.BackupInfo{}, entities.BackupContents{}, errors.NewInvalidBackupError(backup.Name, err.Error())
+	}
+	cache := entities.NewOutfitCache()
+	if contents.Cache != nil {
+		cache = *contents.Cache
+	}
+
+	previous, loadErr := uc.configManager.LoadOrCreate()
+	snapshot, err := uc.Snapshot(SnapshotBackupRestore, previous)
+	if err != nil && snapshot.Name == "" && !stderrors.Is(err, errors.ErrConfigurationNotFound) {
+		return entities.BackupInfo{}, entities.BackupContents{}, fmt.Errorf("could not take a %s snapshot: %w", SnapshotBackupRestore, err)
+	}
+	if err := uc.configManager.Replace(&config); err != nil {
+		return entities.BackupInfo{}, entities.BackupContents{}, err
+	}
+	if err := uc.cacheManager.Replace(&cache); err != nil {
+		var rollbackErr error
+		switch {
+		case previous != nil:
+			rollbackErr = uc.configManager.Replace(previous)
+		case loadErr == nil:
+			rollbackErr = uc.configManager.Delete()
+		}
+		if rollbackErr != nil {
+			return entities.BackupInfo{}, entities.BackupContents{}, fmt.Errorf("%w; the previous configuration could not be put back either: %v", err, rollbackErr)
+		}
+		return entities.BackupInfo{}, entities.BackupContents{}, err
+	}
+	return backup, entities.BackupContents{Config: &config, Cache: &cache}, nil
+}
+
+// Prune deletes all but the newest keep backups and the newest keep
+// snapshots, returning what it deleted.
+func (uc *BackupUseCase) Prune(keep int) ([]entities.BackupInfo, error) {
+	if keep < 0 {
+		return nil, errors.NewInvalidInputError("keep cannot be negative")
+	}
+	backups, err := uc.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	var pruned []entities.BackupInfo
+	for _, backup := range entities.BackupsBeyond(backups, keep) {
+		if err := uc.repo.Delete(backup); err != nil {
+			return pruned, err
+		}
+		pruned = append(pruned, backup)
+	}
+	return pruned, nil
+}
+
+func snapshotsOnly(backups []entities.BackupInfo) []entities.BackupInfo {
+	var snapshots []entities.BackupInfo
+	for _, backup := range backups {
+		if backup.Kind == entities.BackupSnapshot {
+			snapshots = append(snapshots, backup)
+		}
+	}
+	return snapshots
+}
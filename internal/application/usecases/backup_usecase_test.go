@@ -0,0 +1,246 @@
+package usecases
+
+import (
+	stderrors "errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+func backupTestConfig() *entities.Config {
+	return &entities.Config{Root: "/wardrobe", Language: "en", Revision: 5}
+}
+
+func backupTestCache() *entities.OutfitCache {
+	cache := entities.NewOutfitCache().Updating("shoes", entities.NewCategoryCache(2).Adding("a.avatar"))
+	cache.Revision = 9
+	return &cache
+}
+
+func TestBackupUseCase_Create(t *testing.T) {
+	repo := &mockBackupRepository{}
+	uc := NewBackupUseCase(repo, &mockConfigUseCase{loadResult: backupTestConfig()}, &mockCacheService{loadResult: backupTestCache()})
+
+	backup, err := uc.Create("/tmp/wardrobe.tar.gz")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if backup.Kind != entities.BackupManual || backup.Path != "/tmp/wardrobe.tar.gz" {
+		t.Fatalf("Create() = %+v, want a manual backup at the path", backup)
+	}
+	if len(repo.created) != 1 || repo.created[0].Config.Root != "/wardrobe" || repo.created[0].Cache == nil {
+		t.Fatalf("created = %+v, want config and cache", repo.created)
+	}
+}
+
+func TestBackupUseCase_CreateErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		configManager *mockConfigUseCase
+		cacheManager  *mockCacheService
+		repo          *mockBackupRepository
+		want          error
+	}{
+		{name: "config unreadable", configManager: &mockConfigUseCase{loadError: assert.AnError}, want: assert.AnError},
+		{name: "nothing configured", configManager: &mockConfigUseCase{}, want: errors.ErrConfigurationNotFound},
+		{name: "cache unreadable", cacheManager: &mockCacheService{loadError: assert.AnError}, want: assert.AnError},
+		{name: "archive fails", repo: &mockBackupRepository{createError: assert.AnError}, want: assert.AnError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.configManager == nil {
+				tt.configManager = &mockConfigUseCase{loadResult: backupTestConfig()}
+			}
+			if tt.cacheManager == nil {
+				tt.cacheManager = &mockCacheService{loadResult: backupTestCache()}
+			}
+			if tt.repo == nil {
+				tt.repo = &mockBackupRepository{}
+			}
+			if _, err := NewBackupUseCase(tt.repo, tt.configManager, tt.cacheManager).Create(""); !stderrors.Is(err, tt.want) {
+				t.Fatalf("Create() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackupUseCase_Snapshot(t *testing.T) {
+	start := time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC)
+	var stored []entities.BackupInfo
+	for i := 0; i < SnapshotsKept+2; i++ {
+		stored = append(stored, entities.BackupInfo{Name: fmt.Sprintf("snapshot-%d", i), Kind: entities.BackupSnapshot, CreatedAt: start.Add(time.Duration(i) * time.Hour)})
+	}
+	stored = append(stored, entities.BackupInfo{Name: "manual", Kind: entities.BackupManual, CreatedAt: start.Add(-time.Hour)})
+	repo := &mockBackupRepository{backups: stored}
+	uc := NewBackupUseCase(repo, &mockConfigUseCase{loadResult: backupTestConfig()}, &mockCacheService{loadError: assert.AnError})
+
+	snapshot, err := uc.Snapshot(SnapshotFactoryReset, nil)
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if snapshot.Kind != entities.BackupSnapshot || snapshot.Reason != SnapshotFactoryReset {
+		t.Fatalf("Snapshot() = %+v, want a factory-reset snapshot", snapshot)
+	}
+	if created := repo.created[0]; created.Config.Root != "/wardrobe" || created.Cache != nil {
+		t.Fatalf("created = %+v, want the stored config without the unreadable cache", created)
+	}
+	if len(repo.deleted) != 2 || repo.deleted[0].Name != "snapshot-1" || repo.deleted[1].Name != "snapshot-0" {
+		t.Fatalf("deleted = %+v, want the two oldest snapshots only", repo.deleted)
+	}
+
+	previous := &entities.Config{Root: "/old-wardrobe", Language: "en"}
+	if _, err := uc.Snapshot(SnapshotRootChange, previous); err != nil {
+		t.Fatal(err)
+	}
+	if repo.created[1].Config != previous {
+		t.Fatalf("created config = %+v, want the one passed in", repo.created[1].Config)
+	}
+}
+
+func TestBackupUseCase_SnapshotErrors(t *testing.T) {
+	cacheManager := &mockCacheService{}
+	if _, err := NewBackupUseCase(&mockBackupRepository{}, &mockConfigUseCase{loadError: assert.AnError}, cacheManager).Snapshot(SnapshotReset, nil); !stderrors.Is(err, errors.ErrConfigurationNotFound) {
+		t.Fatalf("Snapshot() with unreadable config error = %v, want %v", err, errors.ErrConfigurationNotFound)
+	}
+	configManager := &mockConfigUseCase{loadResult: backupTestConfig()}
+	if _, err := NewBackupUseCase(&mockBackupRepository{createError: assert.AnError}, configManager, cacheManager).Snapshot(SnapshotReset, nil); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("Snapshot() with failing archive error = %v, want %v", err, assert.AnError)
+	}
+	if _, err := NewBackupUseCase(&mockBackupRepository{listError: assert.AnError}, configManager, cacheManager).Snapshot(SnapshotReset, nil); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("Snapshot() with failing list error = %v, want %v", err, assert.AnError)
+	}
+	full := make([]entities.BackupInfo, SnapshotsKept+1)
+	for i := range full {
+		full[i].Kind = entities.BackupSnapshot
+	}
+	repo := &mockBackupRepository{backups: full, deleteError: assert.AnError}
+	if _, err := NewBackupUseCase(repo, configManager, cacheManager).Snapshot(SnapshotReset, nil); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("Snapshot() with failing prune error = %v, want %v", err, assert.AnError)
+	}
+}
+
+func TestBackupUseCase_Restore(t *testing.T) {
+	repo := &mockBackupRepository{opened: entities.BackupContents{Config: &entities.Config{Root: "/restored", Language: "en", Revision: 3}, Cache: backupTestCache()}}
+	configManager := &mockConfigUseCase{loadResult: backupTestConfig()}
+	cacheManager := &mockCacheService{}
+	uc := NewBackupUseCase(repo, configManager, cacheManager)
+
+	backup, contents, err := uc.Restore("backup-1")
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if backup.Name != "backup-1" || contents.Config.Root != "/restored" {
+		t.Fatalf("Restore() = %+v, %+v; want the restored backup", backup, contents)
+	}
+	if len(repo.created) != 1 || repo.created[0].Config.Root != "/wardrobe" {
+		t.Fatalf("created = %+v, want a snapshot of the replaced state", repo.created)
+	}
+	if configManager.saved.Root != "/restored" || !configManager.replaced {
+		t.Fatalf("saved config = %+v, want the restored config replacing the stored one", configManager.saved)
+	}
+	if !cacheManager.saved.Categories["shoes"].WornOutfits["a.avatar"] || !cacheManager.replaced {
+		t.Fatalf("saved cache = %+v, want the restored cache replacing the stored one", cacheManager.saved)
+	}
+
+	repo.opened.Cache = nil
+	if _, _, err := uc.Restore("snapshot-1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(cacheManager.saved.Categories) != 0 {
+		t.Fatalf("saved cache = %+v, want an empty cache when the backup has none", cacheManager.saved)
+	}
+}
+
+func TestBackupUseCase_RestoreErrors(t *testing.T) {
+	valid := entities.BackupContents{Config: &entities.Config{Root: "/restored", Language: "en"}}
+	tests := []struct {
+		name          string
+		repo          *mockBackupRepository
+		configManager *mockConfigUseCase
+		cacheManager  *mockCacheService
+		want          error
+	}{
+		{name: "archive invalid", repo: &mockBackupRepository{openError: errors.ErrInvalidBackup}, want: errors.ErrInvalidBackup},
+		{name: "config invalid", repo: &mockBackupRepository{opened: entities.BackupContents{Config: &entities.Config{Language: "en"}}}, want: errors.ErrInvalidBackup},
+		{name: "config save fails", repo: &mockBackupRepository{opened: valid}, configManager: &mockConfigUseCase{saveError: assert.AnError}, want: assert.AnError},
+		{name: "cache save fails", repo: &mockBackupRepository{opened: valid}, cacheManager: &mockCacheService{saveError: assert.AnError}, want: assert.AnError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.configManager == nil {
+				tt.configManager = &mockConfigUseCase{}
+			}
+			if tt.cacheManager == nil {
+				tt.cacheManager = &mockCacheService{}
+			}
+			_, _, err := NewBackupUseCase(tt.repo, tt.configManager, tt.cacheManager).Restore("backup")
+			if !stderrors.Is(err, tt.want) {
+				t.Fatalf("Restore() error = %v, want %v", err, tt.want)
+			}
+			if tt.want == errors.ErrInvalidBackup && tt.configManager.saved != nil {
+				t.Fatal("Restore() of an invalid backup replaced the config")
+			}
+		})
+	}
+}
+
+func TestBackupUseCase_RestoreRollsBack(t *testing.T) {
+	valid := entities.BackupContents{Config: &entities.Config{Root: "/restored", Language: "en"}}
+	previous := &entities.Config{Root: "/wardrobe", Language: "en"}
+
+	configManager := &mockConfigUseCase{loadResult: previous}
+	cacheManager := &mockCacheService{saveError: assert.AnError}
+	_, _, err := NewBackupUseCase(&mockBackupRepository{opened: valid}, configManager, cacheManager).Restore("backup")
+	if !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("Restore() error = %v, want %v", err, assert.AnError)
+	}
+	if configManager.saved != previous {
+		t.Fatalf("Restore() left config %+v, want the previous config put back", configManager.saved)
+	}
+
+	configManager = &mockConfigUseCase{loadResult: previous}
+	repo := &mockBackupRepository{opened: valid, createError: assert.AnError}
+	_, _, err = NewBackupUseCase(repo, configManager, &mockCacheService{}).Restore("backup")
+	if !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("Restore() with failing snapshot error = %v, want %v", err, assert.AnError)
+	}
+	if configManager.saved != nil {
+		t.Fatal("Restore() replaced the config without a snapshot of it")
+	}
+}
+
+func TestBackupUseCase_ListAndPrune(t *testing.T) {
+	start := time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC)
+	backups := []entities.BackupInfo{
+		{Name: "backup-old", Kind: entities.BackupManual, CreatedAt: start},
+		{Name: "backup-new", Kind: entities.BackupManual, CreatedAt: start.Add(time.Hour)},
+		{Name: "snapshot", Kind: entities.BackupSnapshot, CreatedAt: start},
+	}
+	repo := &mockBackupRepository{backups: backups}
+	uc := NewBackupUseCase(repo, &mockConfigUseCase{}, &mockCacheService{})
+
+	if listed, err := uc.List(); err != nil || len(listed) != 3 {
+		t.Fatalf("List() = %v, %v; want every backup", listed, err)
+	}
+	pruned, err := uc.Prune(1)
+	if err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+	if len(pruned) != 1 || pruned[0].Name != "backup-old" {
+		t.Fatalf("Prune() = %+v, want only the older backup", pruned)
+	}
+
+	var invalidInput *errors.InvalidInputError
+	if _, err := uc.Prune(-1); !stderrors.As(err, &invalidInput) {
+		t.Fatalf("Prune(-1) error = %v, want invalid input", err)
+	}
+	if _, err := NewBackupUseCase(&mockBackupRepository{listError: assert.AnError}, nil, nil).Prune(1); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("Prune() with failing list error = %v, want %v", err, assert.AnError)
+	}
+	if _, err := NewBackupUseCase(&mockBackupRepository{backups: backups, deleteError: assert.AnError}, nil, nil).Prune(0); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("Prune() with failing delete error = %v, want %v", err, assert.AnError)
+	}
+}
@@ -5,5 +5,11 @@ import "github.com/dh85/outfitpicker/internal/domain/entities"
 type CacheManager interface {
 	LoadOrCreate() (*entities.OutfitCache, error)
 	Save(cache *entities.OutfitCache) error
+	// Replace saves cache in place of whatever is stored, even a cache that
+	// cannot be read.
+	Replace(cache *entities.OutfitCache) error
+	// Update loads the cache, lets change modify it in place and saves it,
+	// holding the storage lock for the whole read-modify-write.
+	Update(change func(cache *entities.OutfitCache) error) error
 	Delete() error
 }
@@ -29,6 +29,25 @@ func (uc *CacheUseCase) Save(cache *entities.OutfitCache) error {
 	return uc.repo.Save(cache)
 }
 
+func (uc *CacheUseCase) Replace(cache *entities.OutfitCache) error {
+	return uc.repo.Replace(cache)
+}
+
+// Update applies change to the stored cache, starting from an empty cache
+// when none has been saved yet.
+func (uc *CacheUseCase) Update(change func(cache *entities.OutfitCache) error) error {
+	return uc.repo.Update(func(current *entities.OutfitCache) (*entities.OutfitCache, error) {
+		if current == nil {
+			cache := entities.NewOutfitCache()
+			current = &cache
+		}
+		if err := change(current); err != nil {
+			return nil, err
+		}
+		return current, nil
+	})
+}
+
 func (uc *CacheUseCase) Delete() error {
 	return uc.repo.Delete()
 }
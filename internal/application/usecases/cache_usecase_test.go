@@ -98,3 +98,28 @@ func TestCacheUseCase_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestCacheUseCase_Update(t *testing.T) {
+	t.Run("starts from an empty cache", func(t *testing.T) {
+		repo := &mockCacheRepo{}
+		err := NewCacheUseCase(repo).Update(func(cache *entities.OutfitCache) error {
+			*cache = cache.Updating("casual", entities.NewCategoryCache(2).Adding("a.avatar"))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if repo.loadResult == nil || !repo.loadResult.Categories["casual"].WornOutfits["a.avatar"] {
+			t.Fatalf("stored cache = %+v, want casual/a.avatar worn", repo.loadResult)
+		}
+	})
+
+	t.Run("returns change error without saving", func(t *testing.T) {
+		repo := &mockCacheRepo{}
+		err := NewCacheUseCase(repo).Update(func(*entities.OutfitCache) error { return assert.AnError })
+		assertError(t, true, err)
+		if repo.loadResult != nil {
+			t.Fatalf("stored cache = %+v, want nothing saved", repo.loadResult)
+		}
+	})
+}
@@ -9,6 +9,12 @@ import (
 type ConfigManager interface {
 	LoadOrCreate() (*entities.Config, error)
 	Save(config *entities.Config) error
+	// Replace saves config in place of whatever is stored, even a
+	// configuration that cannot be read.
+	Replace(config *entities.Config) error
+	// Update loads the configuration, lets change modify it in place and
+	// saves it, holding the storage lock for the whole read-modify-write.
+	Update(change func(config *entities.Config) error) error
 	Delete() error
 }
 
@@ -30,7 +36,7 @@ func (uc *CategoryManagementUseCase) ScanCategories() ([]entities.CategoryInfo,
 		return nil, errors.ErrConfigurationNotFound
 	}
 
-	return uc.categoryService.ScanCategories(config.Root, config.ExcludedCategories)
+	return scanWardrobe(uc.categoryService, config)
 }
 
 func (uc *CategoryManagementUseCase) GetOutfits(categoryPath string) ([]entities.FileEntry, error) {
@@ -15,7 +15,7 @@ func TestCategoryManagementUseCase_ScanCategories(t *testing.T) {
 		{
 			name: "scans categories successfully",
 			setup: func() *CategoryManagementUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				categories := []entities.CategoryInfo{
 					entities.NewCategoryInfo(
 						entities.NewCategoryReference("casual", "/test/path/casual"),
@@ -52,7 +52,7 @@ func TestCategoryManagementUseCase_ScanCategories(t *testing.T) {
 		{
 			name: "returns error when scan fails",
 			setup: func() *CategoryManagementUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				return NewCategoryManagementUseCase(
 					&mockCategoryService{scanError: assert.AnError},
 					&mockConfigUseCase{loadResult: config},
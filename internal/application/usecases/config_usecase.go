@@ -2,6 +2,7 @@ package usecases
 
 import (
 	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
 	"github.com/dh85/outfitpicker/internal/domain/interfaces"
 )
 
@@ -21,6 +22,24 @@ func (uc *ConfigUseCase) Save(config *entities.Config) error {
 	return uc.repo.Save(config)
 }
 
+func (uc *ConfigUseCase) Replace(config *entities.Config) error {
+	return uc.repo.Replace(config)
+}
+
+// Update applies change to the stored configuration. It fails with
+// ErrConfigurationNotFound when nothing has been configured yet.
+func (uc *ConfigUseCase) Update(change func(config *entities.Config) error) error {
+	return uc.repo.Update(func(current *entities.Config) (*entities.Config, error) {
+		if current == nil {
+			return nil, errors.ErrConfigurationNotFound
+		}
+		if err := change(current); err != nil {
+			return nil, err
+		}
+		return current, nil
+	})
+}
+
 func (uc *ConfigUseCase) Delete() error {
 	return uc.repo.Delete()
 }
@@ -1,9 +1,11 @@
 package usecases
 
 import (
+	stderrors "errors"
 	"testing"
 
 	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
 )
 
 func TestConfigUseCase_LoadOrCreate(t *testing.T) {
@@ -16,7 +18,7 @@ func TestConfigUseCase_LoadOrCreate(t *testing.T) {
 		{
 			name: "loads existing config",
 			setup: func() *ConfigUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				return NewConfigUseCase(&mockConfigRepo{loadResult: config})
 			},
 		},
@@ -48,7 +50,7 @@ func TestConfigUseCase_LoadOrCreate(t *testing.T) {
 }
 
 func TestConfigUseCase_Save(t *testing.T) {
-	config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+	config, _ := entities.NewConfig("/test/path", nil, nil)
 
 	tests := []struct {
 		name    string
@@ -98,3 +100,56 @@ func TestConfigUseCase_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigUseCase_Update(t *testing.T) {
+	changeErr := stderrors.New("change failed")
+	rename := func(config *entities.Config) error {
+		config.Root = "/new/path"
+		return nil
+	}
+	tests := []struct {
+		name     string
+		repo     *mockConfigRepo
+		change   func(*entities.Config) error
+		wantErr  error
+		wantRoot string
+	}{
+		{
+			name:     "applies change to stored config",
+			repo:     &mockConfigRepo{loadResult: &entities.Config{Root: "/old/path"}},
+			change:   rename,
+			wantRoot: "/new/path",
+		},
+		{
+			name:    "fails when nothing is configured",
+			repo:    &mockConfigRepo{},
+			change:  rename,
+			wantErr: domainerrors.ErrConfigurationNotFound,
+		},
+		{
+			name:     "keeps stored config when change fails",
+			repo:     &mockConfigRepo{loadResult: &entities.Config{Root: "/old/path"}},
+			change:   func(*entities.Config) error { return changeErr },
+			wantErr:  changeErr,
+			wantRoot: "/old/path",
+		},
+		{
+			name:    "returns load error",
+			repo:    &mockConfigRepo{loadError: assert.AnError},
+			change:  rename,
+			wantErr: assert.AnError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewConfigUseCase(tt.repo).Update(tt.change)
+			if !stderrors.Is(err, tt.wantErr) {
+				t.Fatalf("Update() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantRoot != "" && tt.repo.loadResult.Root != tt.wantRoot {
+				t.Errorf("stored root = %q, want %q", tt.repo.loadResult.Root, tt.wantRoot)
+			}
+		})
+	}
+}
@@ -24,5 +24,5 @@ func (uc *GetCategoriesUseCase) Execute() ([]entities.CategoryInfo, error) {
 		return nil, errors.ErrConfigurationNotFound
 	}
 
-	return uc.categoryService.ScanCategories(config.Root, config.ExcludedCategories)
+	return scanWardrobe(uc.categoryService, config)
 }
@@ -17,7 +17,7 @@ func TestGetCategoriesUseCase_Execute(t *testing.T) {
 		{
 			name: "gets categories successfully without exclusions",
 			setup: func() *GetCategoriesUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, map[string]bool{"excluded": true}, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, map[string]bool{"excluded": true})
 				return NewGetCategoriesUseCase(
 					&mockCategoryService{scanResult: []entities.CategoryInfo{
 						entities.NewCategoryInfo(
@@ -75,7 +75,7 @@ func TestGetCategoriesUseCase_Execute(t *testing.T) {
 		{
 			name: "returns error when scan fails",
 			setup: func() *GetCategoriesUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				return NewGetCategoriesUseCase(
 					&mockCategoryService{scanError: assert.AnError},
 					&mockConfigUseCase{loadResult: config},
@@ -0,0 +1,84 @@
+package usecases
+
+import (
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+	"github.com/dh85/outfitpicker/internal/domain/logic"
+)
+
+// PortableStorage makes root a portable wardrobe and returns the managers for
+// the config and cache kept inside it.
+type PortableStorage func(root string) (ConfigManager, CacheManager, error)
+
+// InitUseCase sets up a wardrobe without the interactive first-time setup.
+type InitUseCase struct {
+	configManager ConfigManager
+	cacheManager  CacheManager
+	portable      PortableStorage
+}
+
+// NewInitUseCase sets up wardrobes for the profile whose config and cache
+// configManager and cacheManager hold.
+func NewInitUseCase(configManager ConfigManager, cacheManager CacheManager, portable PortableStorage) *InitUseCase {
+	return &InitUseCase{configManager: configManager, cacheManager: cacheManager, portable: portable}
+}
+
+// Init configures the profile for the wardrobe at root. It fails with
+// ErrAlreadyInitialized if the profile is configured already.
+func (uc *InitUseCase) Init(root, language string) (*entities.Config, error) {
+	config, err := entities.NewConfig(root, &language, nil)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := uc.configManager.LoadOrCreate()
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errors.NewAlreadyInitializedError("this profile")
+	}
+	if err := uc.configManager.Save(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// InitPortable makes the wardrobe at root keep its own config and worn
+// outfits. When the profile is already configured for root, its settings and
+// worn outfits are copied in and carried is true; otherwise the wardrobe
+// starts afresh in language.
+func (uc *InitUseCase) InitPortable(root, language string) (config *entities.Config, carried bool, err error) {
+	config, err = entities.NewConfig(root, &language, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	existing, err := uc.configManager.LoadOrCreate()
+	if err != nil {
+		return nil, false, err
+	}
+	var cache *entities.OutfitCache
+	if existing != nil && logic.CanonicalRoot(existing.Root) == logic.CanonicalRoot(root) {
+		if cache, err = uc.cacheManager.LoadOrCreate(); err != nil {
+			return nil, false, err
+		}
+		copied := *existing
+		copied.Root, copied.Storage, copied.Revision = root, "", 0
+		config, carried = &copied, true
+	}
+
+	configManager, cacheManager, err := uc.portable(root)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := configManager.Save(config); err != nil {
+		return nil, false, err
+	}
+	if cache != nil {
+		copied := *cache
+		copied.OtherRoots, copied.Revision = nil, 0
+		if err := cacheManager.Save(&copied); err != nil {
+			return nil, false, err
+		}
+	}
+	return config, carried, nil
+}
@@ -0,0 +1,130 @@
+package usecases
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+type portableStorageStub struct {
+	roots         []string
+	configManager *mockConfigUseCase
+	cacheManager  *mockCacheService
+	err           error
+}
+
+func (p *portableStorageStub) open(root string) (ConfigManager, CacheManager, error) {
+	p.roots = append(p.roots, root)
+	return p.configManager, p.cacheManager, p.err
+}
+
+func newPortableStorageStub() *portableStorageStub {
+	return &portableStorageStub{configManager: &mockConfigUseCase{}, cacheManager: &mockCacheService{}}
+}
+
+func TestInitUseCase_Init(t *testing.T) {
+	root := "/test/wardrobe"
+	configManager := &mockConfigUseCase{}
+	uc := NewInitUseCase(configManager, &mockCacheService{}, nil)
+
+	config, err := uc.Init(root, "fr")
+	if err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if config.Root != root || config.Language != "fr" || configManager.saved != config {
+		t.Fatalf("Init() = %+v, saved %+v; want the new config saved", config, configManager.saved)
+	}
+
+	configManager.loadResult = config
+	if _, err := uc.Init(root, "en"); !stderrors.Is(err, errors.ErrAlreadyInitialized) {
+		t.Fatalf("Init() again error = %v, want %v", err, errors.ErrAlreadyInitialized)
+	}
+	if _, err := uc.Init(root, "xx"); !stderrors.Is(err, errors.ErrInvalidConfiguration) {
+		t.Fatalf("Init() with an unknown language error = %v, want %v", err, errors.ErrInvalidConfiguration)
+	}
+	if _, err := NewInitUseCase(&mockConfigUseCase{loadError: assert.AnError}, nil, nil).Init(root, "en"); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("Init() with an unreadable config error = %v, want %v", err, assert.AnError)
+	}
+}
+
+func TestInitUseCase_InitPortable(t *testing.T) {
+	root := "/test/wardrobe"
+
+	t.Run("starts afresh for another wardrobe", func(t *testing.T) {
+		portable := newPortableStorageStub()
+		existing := &entities.Config{Root: "/test/other", Language: "fr"}
+		uc := NewInitUseCase(&mockConfigUseCase{loadResult: existing}, &mockCacheService{}, portable.open)
+
+		config, carried, err := uc.InitPortable(root, "de")
+		if err != nil || carried {
+			t.Fatalf("InitPortable() = %+v, %t, %v; want a new config", config, carried, err)
+		}
+		if portable.roots[0] != root || portable.configManager.saved.Language != "de" || portable.cacheManager.saved != nil {
+			t.Fatalf("portable config = %+v, cache = %+v; want a fresh config and no cache", portable.configManager.saved, portable.cacheManager.saved)
+		}
+	})
+
+	t.Run("carries the profile over for the same wardrobe", func(t *testing.T) {
+		portable := newPortableStorageStub()
+		existing := &entities.Config{Root: root, Language: "fr", ExcludedCategories: map[string]bool{"hats": true}, Storage: entities.StorageSQLite, Revision: 7}
+		cache := entities.NewOutfitCache().Updating("casual", entities.NewCategoryCache(2).Adding("a.avatar"))
+		cache.OtherRoots = map[string]map[string]entities.CategoryCache{"/old": cache.Categories}
+		cache.Revision = 4
+		uc := NewInitUseCase(&mockConfigUseCase{loadResult: existing}, &mockCacheService{loadResult: &cache}, portable.open)
+
+		config, carried, err := uc.InitPortable(root, "en")
+		if err != nil || !carried {
+			t.Fatalf("InitPortable() = %+v, %t, %v; want the profile carried over", config, carried, err)
+		}
+		saved := portable.configManager.saved
+		if saved.Language != "fr" || !saved.ExcludedCategories["hats"] || saved.Storage != "" || saved.Revision != 0 {
+			t.Fatalf("portable config = %+v, want the profile's settings in JSON files", saved)
+		}
+		savedCache := portable.cacheManager.saved
+		if !savedCache.Categories["casual"].WornOutfits["a.avatar"] || savedCache.OtherRoots != nil || savedCache.Revision != 0 {
+			t.Fatalf("portable cache = %+v, want this wardrobe's worn outfits only", savedCache)
+		}
+		if existing.Storage != entities.StorageSQLite {
+			t.Fatal("InitPortable() changed the profile's config")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		same := &entities.Config{Root: root, Language: "en"}
+		emptyCache := entities.NewOutfitCache()
+		tests := []struct {
+			name     string
+			config   *mockConfigUseCase
+			cache    *mockCacheService
+			portable *portableStorageStub
+		}{
+			{name: "config unreadable", config: &mockConfigUseCase{loadError: assert.AnError}},
+			{name: "cache unreadable", config: &mockConfigUseCase{loadResult: same}, cache: &mockCacheService{loadError: assert.AnError}},
+			{name: "portable directory", portable: &portableStorageStub{err: assert.AnError}},
+			{name: "config save", portable: &portableStorageStub{configManager: &mockConfigUseCase{saveError: assert.AnError}}},
+			{name: "cache save", config: &mockConfigUseCase{loadResult: same}, cache: &mockCacheService{loadResult: &emptyCache},
+				portable: &portableStorageStub{configManager: &mockConfigUseCase{}, cacheManager: &mockCacheService{saveError: assert.AnError}}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if tt.config == nil {
+					tt.config = &mockConfigUseCase{}
+				}
+				if tt.cache == nil {
+					tt.cache = &mockCacheService{}
+				}
+				if tt.portable == nil {
+					tt.portable = newPortableStorageStub()
+				}
+				if _, _, err := NewInitUseCase(tt.config, tt.cache, tt.portable.open).InitPortable(root, "en"); !stderrors.Is(err, assert.AnError) {
+					t.Fatalf("InitPortable() error = %v, want %v", err, assert.AnError)
+				}
+			})
+		}
+		if _, _, err := NewInitUseCase(&mockConfigUseCase{}, nil, nil).InitPortable("", "en"); err == nil {
+			t.Fatal("InitPortable() with an empty root error = nil")
+		}
+	})
+}
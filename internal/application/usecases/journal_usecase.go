@@ -0,0 +1,62 @@
+package usecases
+
+import (
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+)
+
+// JournalUseCase rebuilds config and cache by replaying the journal.
+type JournalUseCase struct {
+	journal       interfaces.Journal
+	configManager ConfigManager
+	cacheManager  CacheManager
+}
+
+func NewJournalUseCase(journal interfaces.Journal, configManager ConfigManager, cacheManager CacheManager) *JournalUseCase {
+	return &JournalUseCase{journal, configManager, cacheManager}
+}
+
+// RestoreAt replaces config and cache with the state the journal recorded as
+// of at. The restore is journaled like any other change, so nothing recorded
+// after at is lost.
+func (uc *JournalUseCase) RestoreAt(at time.Time) (entities.JournalState, error) {
+	events, err := uc.journal.Events()
+	if err != nil {
+		return entities.JournalState{}, err
+	}
+	state := entities.ReplayJournal(events, at)
+	if state.Config == nil {
+		return entities.JournalState{}, errors.NewNothingToRestoreError(at)
+	}
+
+	config := *state.Config
+	if err := uc.configManager.Replace(&config); err != nil {
+		return entities.JournalState{}, err
+	}
+	cache := state.Cache
+	if err := uc.cacheManager.Replace(&cache); err != nil {
+		return entities.JournalState{}, err
+	}
+	return state, nil
+}
+
+// RebuildCache replaces the stored cache, readable or not, with the one the
+// whole journal replays to. A journal that does not start from a baseline
+// would lose whatever was worn before it, so nothing is rebuilt from it.
+func (uc *JournalUseCase) RebuildCache() (entities.OutfitCache, error) {
+	events, err := uc.journal.Events()
+	if err != nil {
+		return entities.OutfitCache{}, err
+	}
+	if !entities.HasBaseline(events) {
+		return entities.OutfitCache{}, errors.ErrIncompleteJournal
+	}
+	cache := entities.ReplayJournal(events, time.Time{}).Cache
+	if err := uc.cacheManager.Replace(&cache); err != nil {
+		return entities.OutfitCache{}, err
+	}
+	return cache, nil
+}
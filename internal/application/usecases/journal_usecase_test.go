@@ -0,0 +1,115 @@
+package usecases
+
+import (
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+var journalTestStart = time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC)
+
+func journalTestEvents() []entities.JournalEvent {
+	return []entities.JournalEvent{
+		{Type: entities.JournalBaseline, At: journalTestStart.Add(-24 * time.Hour)},
+		{Type: entities.JournalConfigUpdate, At: journalTestStart, Config: &entities.Config{Root: "/wardrobe", Language: "en", Revision: 4}},
+		{Type: entities.JournalWear, At: journalTestStart.Add(time.Hour), Category: "shoes", Outfit: "a.avatar", TotalOutfits: 2},
+		{Type: entities.JournalWear, At: journalTestStart.Add(48 * time.Hour), Category: "shoes", Outfit: "b.avatar", TotalOutfits: 2},
+	}
+}
+
+func TestJournalUseCase_RestoreAt(t *testing.T) {
+	configManager := &mockConfigUseCase{}
+	cacheManager := &mockCacheService{}
+	uc := NewJournalUseCase(&mockJournal{events: journalTestEvents()}, configManager, cacheManager)
+
+	state, err := uc.RestoreAt(journalTestStart.Add(24 * time.Hour))
+	if err != nil {
+		t.Fatalf("RestoreAt() error = %v", err)
+	}
+	if configManager.saved == nil || configManager.saved.Root != "/wardrobe" || !configManager.replaced {
+		t.Fatalf("saved config = %+v, want the recorded config replacing the stored one", configManager.saved)
+	}
+	worn := cacheManager.saved.Categories["shoes"].WornOutfits
+	if len(worn) != 1 || !worn["a.avatar"] || !cacheManager.replaced {
+		t.Fatalf("saved cache = %+v, want only a.avatar worn", cacheManager.saved)
+	}
+	if len(state.Cache.Categories["shoes"].WornOutfits) != 1 {
+		t.Fatalf("state = %+v, want the restored cache", state)
+	}
+}
+
+func TestJournalUseCase_RestoreAtErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		journal       *mockJournal
+		configManager *mockConfigUseCase
+		cacheManager  *mockCacheService
+		at            time.Time
+		want          error
+	}{
+		{name: "journal unreadable", journal: &mockJournal{err: assert.AnError}, want: assert.AnError},
+		{name: "nothing configured yet", journal: &mockJournal{events: journalTestEvents()}, at: journalTestStart.Add(-time.Hour), want: errors.ErrNothingToRestore},
+		{name: "config save fails", journal: &mockJournal{events: journalTestEvents()}, configManager: &mockConfigUseCase{saveError: assert.AnError}, want: assert.AnError},
+		{name: "cache save fails", journal: &mockJournal{events: journalTestEvents()}, cacheManager: &mockCacheService{saveError: assert.AnError}, want: assert.AnError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.configManager == nil {
+				tt.configManager = &mockConfigUseCase{}
+			}
+			if tt.cacheManager == nil {
+				tt.cacheManager = &mockCacheService{}
+			}
+			_, err := NewJournalUseCase(tt.journal, tt.configManager, tt.cacheManager).RestoreAt(tt.at)
+			if !stderrors.Is(err, tt.want) {
+				t.Fatalf("RestoreAt() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestJournalUseCase_RebuildCache(t *testing.T) {
+	cacheManager := &mockCacheService{}
+	uc := NewJournalUseCase(&mockJournal{events: journalTestEvents()}, &mockConfigUseCase{}, cacheManager)
+
+	cache, err := uc.RebuildCache()
+	if err != nil {
+		t.Fatalf("RebuildCache() error = %v", err)
+	}
+	if len(cache.Categories["shoes"].WornOutfits) != 2 || cacheManager.saved == nil || !cacheManager.replaced {
+		t.Fatalf("RebuildCache() = %+v, saved %+v; want both wears saved over the stored cache", cache, cacheManager.saved)
+	}
+
+	if _, err := NewJournalUseCase(&mockJournal{err: assert.AnError}, &mockConfigUseCase{}, cacheManager).RebuildCache(); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("RebuildCache() with unreadable journal error = %v, want %v", err, assert.AnError)
+	}
+	failing := &mockCacheService{saveError: assert.AnError}
+	if _, err := NewJournalUseCase(&mockJournal{events: journalTestEvents()}, &mockConfigUseCase{}, failing).RebuildCache(); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("RebuildCache() with failing save error = %v, want %v", err, assert.AnError)
+	}
+}
+
+func TestJournalUseCase_RebuildCacheNeedsABaseline(t *testing.T) {
+	tests := []struct {
+		name   string
+		events []entities.JournalEvent
+	}{
+		{name: "no events"},
+		{name: "no baseline", events: journalTestEvents()[1:]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheManager := &mockCacheService{}
+			_, err := NewJournalUseCase(&mockJournal{events: tt.events}, &mockConfigUseCase{}, cacheManager).RebuildCache()
+			if !stderrors.Is(err, errors.ErrIncompleteJournal) {
+				t.Fatalf("RebuildCache() error = %v, want %v", err, errors.ErrIncompleteJournal)
+			}
+			if cacheManager.saved != nil {
+				t.Fatalf("saved cache = %+v, want the stored cache left alone", cacheManager.saved)
+			}
+		})
+	}
+}
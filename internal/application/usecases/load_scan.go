@@ -0,0 +1,112 @@
+package usecases
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+	"github.com/dh85/outfitpicker/internal/domain/validation"
+)
+
+// LoadScan stands in for the wardrobe scanner while the application loads.
+// It answers each scan of a root, and each listing of a category, from the
+// first time it was made, failures included, so that the checks made on load
+// read the wardrobe once between them and wait on a hung root only once.
+// After Done it passes everything on to the scanner.
+type LoadScan struct {
+	categoryService interfaces.CategoryService
+	policy          string
+	scanned         *loadScanResults
+}
+
+// loadScanResults are the scans and listings made through a LoadScan and the
+// services it returns for symbolic link policies, by policy and path.
+type loadScanResults struct {
+	mu      sync.Mutex
+	done    bool
+	scans   map[string]loadScanResult[[]entities.CategoryInfo]
+	outfits map[string]loadScanResult[[]entities.FileEntry]
+}
+
+type loadScanResult[T any] struct {
+	value T
+	err   error
+}
+
+func NewLoadScan(categoryService interfaces.CategoryService) *LoadScan {
+	return &LoadScan{
+		categoryService: categoryService,
+		scanned: &loadScanResults{
+			scans:   map[string]loadScanResult[[]entities.CategoryInfo]{},
+			outfits: map[string]loadScanResult[[]entities.FileEntry]{},
+		},
+	}
+}
+
+// ScanCategories scans rootPath the first time it is asked for with
+// excludedCategories, and returns that scan afterwards.
+func (s *LoadScan) ScanCategories(rootPath string, excludedCategories map[string]bool) ([]entities.CategoryInfo, error) {
+	key := fmt.Sprint(s.policy, "\x00", rootPath, "\x00", excludedCategories)
+	return loadOnce(s.scanned, s.scanned.scans, key, func() ([]entities.CategoryInfo, error) {
+		return s.categoryService.ScanCategories(rootPath, excludedCategories)
+	})
+}
+
+// GetOutfits lists the category at categoryPath the first time it is asked
+// for, and returns that listing afterwards.
+func (s *LoadScan) GetOutfits(categoryPath string) ([]entities.FileEntry, error) {
+	key := fmt.Sprint(s.policy, "\x00", categoryPath)
+	return loadOnce(s.scanned, s.scanned.outfits, key, func() ([]entities.FileEntry, error) {
+		return s.categoryService.GetOutfits(categoryPath)
+	})
+}
+
+// FollowingSymlinks returns a LoadScan over the scanner treating symbolic
+// links as policy says, sharing what this one has scanned under the same
+// policy.
+func (s *LoadScan) FollowingSymlinks(policy validation.SymlinkPolicy) interfaces.CategoryService {
+	following := *s
+	if follower, ok := s.categoryService.(interfaces.SymlinkFollower); ok {
+		following.categoryService = follower.FollowingSymlinks(policy)
+	}
+	following.policy = fmt.Sprint(policy)
+	return &following
+}
+
+// Done ends the load: later scans and listings read the wardrobe again.
+func (s *LoadScan) Done() {
+	s.scanned.mu.Lock()
+	defer s.scanned.mu.Unlock()
+	s.scanned.done = true
+	clear(s.scanned.scans)
+	clear(s.scanned.outfits)
+}
+
+// loadOnce returns what results holds for key, reading it with read the
+// first time, or every time once the load is done.
+func loadOnce[T any](scanned *loadScanResults, results map[string]loadScanResult[[]T], key string, read func() ([]T, error)) ([]T, error) {
+	scanned.mu.Lock()
+	result, ok := results[key]
+	done := scanned.done
+	scanned.mu.Unlock()
+	if done {
+		return read()
+	}
+	if !ok {
+		result.value, result.err = read()
+		scanned.mu.Lock()
+		if !scanned.done {
+			results[key] = result
+		}
+		scanned.mu.Unlock()
+	}
+	return slices.Clone(result.value), result.err
+}
+
+// Ensure LoadScan implements the interfaces
+var (
+	_ interfaces.CategoryService = (*LoadScan)(nil)
+	_ interfaces.SymlinkFollower = (*LoadScan)(nil)
+)
@@ -0,0 +1,76 @@
+package usecases
+
+import (
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+	"github.com/dh85/outfitpicker/internal/domain/validation"
+)
+
+// countingScanner counts each scan and listing by symbolic link policy and
+// path.
+type countingScanner struct {
+	*rootedCategoryService
+	policy validation.SymlinkPolicy
+	reads  map[string]int
+}
+
+func (s *countingScanner) ScanCategories(rootPath string, excludedCategories map[string]bool) ([]entities.CategoryInfo, error) {
+	s.reads[fmt.Sprint(s.policy.Follow, " ", rootPath)]++
+	return s.rootedCategoryService.ScanCategories(rootPath, excludedCategories)
+}
+
+func (s *countingScanner) GetOutfits(categoryPath string) ([]entities.FileEntry, error) {
+	s.reads[fmt.Sprint(s.policy.Follow, " ", categoryPath)]++
+	return s.rootedCategoryService.GetOutfits(categoryPath)
+}
+
+func (s *countingScanner) FollowingSymlinks(policy validation.SymlinkPolicy) interfaces.CategoryService {
+	following := *s
+	following.policy = policy
+	return &following
+}
+
+func TestLoadScan_ReadsTheWardrobeOnceUntilDone(t *testing.T) {
+	scanner := &countingScanner{rootedCategoryService: newRootedCategoryService(), reads: map[string]int{}}
+	config := multiRootConfig(t, "/ssd", "/usb")
+	scan := NewLoadScan(scanner)
+
+	for range 2 {
+		if _, err := scanWardrobe(scan, config); err != nil {
+			t.Fatalf("scanWardrobe() error = %v", err)
+		}
+		if _, err := categoryOutfits(scan, config, "casual"); err != nil {
+			t.Fatalf("categoryOutfits() error = %v", err)
+		}
+	}
+	want := map[string]int{"false /ssd": 1, "false /usb": 1, "false /ssd/casual": 1, "false /usb/casual": 1}
+	if !reflect.DeepEqual(scanner.reads, want) {
+		t.Fatalf("reads = %v, want each root and category read once, failures included", scanner.reads)
+	}
+
+	following := scan.FollowingSymlinks(validation.SymlinkPolicy{Follow: true})
+	for range 2 {
+		if _, err := following.ScanCategories("/usb", nil); !stderrors.Is(err, assert.AnError) {
+			t.Fatalf("ScanCategories() error = %v, want %v", err, assert.AnError)
+		}
+	}
+	if scanner.reads["true /usb"] != 1 {
+		t.Errorf("reads = %v, want a scan following links made once on its own", scanner.reads)
+	}
+
+	scan.Done()
+	if _, err := scan.ScanCategories("/ssd", nil); err != nil {
+		t.Fatalf("ScanCategories() after Done error = %v", err)
+	}
+	if _, err := following.GetOutfits("/ssd/casual"); err != nil {
+		t.Fatalf("GetOutfits() after Done error = %v", err)
+	}
+	if scanner.reads["false /ssd"] != 2 || scanner.reads["true /ssd/casual"] != 1 {
+		t.Errorf("reads after Done = %v, want the wardrobe read again", scanner.reads)
+	}
+}
@@ -0,0 +1,170 @@
+package usecases
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+	"github.com/dh85/outfitpicker/internal/domain/logic"
+)
+
+// OutfitIdentityUseCase keeps the worn state of outfits whose files were
+// renamed or moved to another category, when the configuration matches
+// outfits by content.
+type OutfitIdentityUseCase struct {
+	configManager   ConfigManager
+	cacheManager    CacheManager
+	categoryService interfaces.CategoryService
+	hasher          interfaces.ContentHasher
+}
+
+func NewOutfitIdentityUseCase(configManager ConfigManager, cacheManager CacheManager, categoryService interfaces.CategoryService, hasher interfaces.ContentHasher) *OutfitIdentityUseCase {
+	return &OutfitIdentityUseCase{configManager, cacheManager, categoryService, hasher}
+}
+
+// Remember records the content hash of a worn outfit, so that it is still
+// recognised if its file is renamed before the next Relink.
+func (uc *OutfitIdentityUseCase) Remember(outfit entities.OutfitReference) error {
+	config, err := uc.configManager.LoadOrCreate()
+	if err != nil || config == nil || !config.MatchesContent() {
+		return err
+	}
+	path := filepath.Join(outfit.Category.Path, outfit.FileName)
+	if entities.InArchive(path) {
+		return nil
+	}
+	if _, err := uc.hasher.Hash(path); err != nil {
+		return err
+	}
+	return uc.hasher.Save()
+}
+
+// Relink finds worn outfits whose file is gone and moves their worn state to
+// the unworn file with the same contents, in whichever category it now is.
+// Worn outfits are hashed as it goes, so their hashes are known once they
+// are renamed; other files are only hashed while a worn outfit is missing.
+// While any root or category cannot be read, it does nothing, since the
+// outfits in it would look missing. Hashes of files that are gone and no
+// longer needed are forgotten.
+func (uc *OutfitIdentityUseCase) Relink() ([]entities.OutfitRename, error) {
+	config, err := uc.configManager.LoadOrCreate()
+	if err != nil || config == nil || !config.MatchesContent() {
+		return nil, err
+	}
+	files, unreadable := wardrobeOutfits(uc.categoryService, config)
+	if len(unreadable) > 0 {
+		return nil, nil
+	}
+	cache, err := uc.cacheManager.LoadOrCreate()
+	if err != nil {
+		return nil, err
+	}
+
+	type missingOutfit struct {
+		category, key, hash string
+	}
+	var missing []missingOutfit
+	for _, name := range sortedKeys(cache.Categories) {
+		present := make(map[string]entities.FileEntry, len(files[name]))
+		for _, file := range files[name] {
+			present[file.Key()] = file
+		}
+		for _, key := range sortedKeys(cache.Categories[name].WornOutfits) {
+			if file, ok := present[key]; ok {
+				if entities.InArchive(file.CategoryPath()) {
+					continue
+				}
+				if _, err := uc.hasher.Hash(filepath.Join(file.CategoryPath(), file.FileName)); err != nil {
+					return nil, err
+				}
+			} else if hash, ok := uc.hasher.Recorded(outfitPath(config, name, key)); ok {
+				missing = append(missing, missingOutfit{name, key, hash})
+			}
+		}
+	}
+
+	kept := make(map[string]bool)
+	for _, name := range sortedKeys(files) {
+		for _, file := range files[name] {
+			kept[filepath.Join(file.CategoryPath(), file.FileName)] = true
+		}
+	}
+
+	var renames []entities.OutfitRename
+	if len(missing) > 0 {
+		unworn := make(map[string][]entities.FileEntry)
+		for _, name := range sortedKeys(files) {
+			for _, file := range files[name] {
+				if cache.Categories[name].WornOutfits[file.Key()] || entities.InArchive(file.CategoryPath()) {
+					continue
+				}
+				hash, err := uc.hasher.Hash(filepath.Join(file.CategoryPath(), file.FileName))
+				if err != nil {
+					return nil, err
+				}
+				unworn[hash] = append(unworn[hash], file)
+			}
+		}
+		for _, outfit := range missing {
+			matches := unworn[outfit.hash]
+			if len(matches) == 0 {
+				kept[outfitPath(config, outfit.category, outfit.key)] = true
+				continue
+			}
+			unworn[outfit.hash] = matches[1:]
+			renames = append(renames, entities.OutfitRename{
+				FromCategory: outfit.category,
+				From:         outfit.key,
+				ToCategory:   matches[0].CategoryName(),
+				To:           matches[0].Key(),
+			})
+		}
+	}
+	uc.hasher.Retain(kept)
+	if err := uc.hasher.Save(); err != nil {
+		return nil, err
+	}
+	if len(renames) == 0 {
+		return nil, nil
+	}
+
+	err = uc.cacheManager.Update(func(cache *entities.OutfitCache) error {
+		for _, rename := range renames {
+			from := cache.Categories[rename.FromCategory]
+			if !from.WornOutfits[rename.From] {
+				continue
+			}
+			*cache = cache.Updating(rename.FromCategory, from.Removing(rename.From))
+			to, exists := cache.Categories[rename.ToCategory]
+			if !exists {
+				to = entities.NewCategoryCache(len(files[rename.ToCategory]))
+			}
+			*cache = cache.Updating(rename.ToCategory, to.Adding(rename.To))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return renames, nil
+}
+
+// outfitPath returns where the outfit keyed key in categoryName was kept,
+// working out its wardrobe root from the key.
+func outfitPath(config *entities.Config, categoryName, key string) string {
+	fileName, root := logic.SplitOutfitKey(key, config.WardrobeRoots())
+	if root == "" {
+		root = config.Root
+	}
+	return filepath.Join(root, categoryName, fileName)
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,243 @@
+package usecases
+
+import (
+	stderrors "errors"
+	"reflect"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+)
+
+// stubHasher hashes the files in current and remembers every hash it has
+// handed out, like the index of a real hasher.
+type stubHasher struct {
+	current  map[string]string
+	recorded map[string]string
+	hashed   []string
+	saves    int
+	err      error
+}
+
+func (h *stubHasher) Hash(path string) (string, error) {
+	hash, ok := h.current[path]
+	if h.err != nil || !ok {
+		return "", assert.AnError
+	}
+	h.hashed = append(h.hashed, path)
+	h.recorded[path] = hash
+	return hash, nil
+}
+
+func (h *stubHasher) Recorded(path string) (string, bool) {
+	hash, ok := h.recorded[path]
+	return hash, ok
+}
+
+func (h *stubHasher) Retain(paths map[string]bool) {
+	for path := range h.recorded {
+		if !paths[path] {
+			delete(h.recorded, path)
+		}
+	}
+}
+
+func (h *stubHasher) Save() error {
+	h.saves++
+	return nil
+}
+
+func contentIdentityConfig(t *testing.T) *entities.Config {
+	t.Helper()
+	return multiRootConfig(t, "/ssd").WithIdentity(entities.IdentityContent)
+}
+
+func renamedWardrobe() (*rootedCategoryService, *stubHasher, entities.OutfitCache) {
+	info := func(name string, count int) entities.CategoryInfo {
+		return entities.NewCategoryInfo(entities.NewCategoryReference(name, "/ssd/"+name), entities.CategoryStateHasOutfits, count)
+	}
+	service := &rootedCategoryService{
+		scans: map[string][]entities.CategoryInfo{"/ssd": {info("casual", 2), info("formal", 1)}},
+		outfits: map[string][]entities.FileEntry{
+			"/ssd/casual": {{FileName: "b.avatar"}, {FileName: "new-name.avatar"}},
+			"/ssd/formal": {{FileName: "moved.avatar"}},
+		},
+	}
+	hasher := &stubHasher{
+		current: map[string]string{
+			"/ssd/casual/b.avatar":        "hash-b",
+			"/ssd/casual/new-name.avatar": "hash-renamed",
+			"/ssd/formal/moved.avatar":    "hash-moved",
+		},
+		recorded: map[string]string{
+			"/ssd/casual/old-name.avatar": "hash-renamed",
+			"/ssd/casual/to-move.avatar":  "hash-moved",
+			"/ssd/casual/lost.avatar":     "hash-lost",
+		},
+	}
+	casual := entities.NewCategoryCache(5)
+	for _, outfit := range []string{"b.avatar", "gone.avatar", "lost.avatar", "old-name.avatar", "to-move.avatar"} {
+		casual = casual.Adding(outfit)
+	}
+	return service, hasher, entities.NewOutfitCache().Updating("casual", casual)
+}
+
+func TestOutfitIdentityUseCase_RelinkFollowsRenamesAndMoves(t *testing.T) {
+	service, hasher, cache := renamedWardrobe()
+	cacheManager := &mockCacheService{loadResult: &cache}
+	uc := NewOutfitIdentityUseCase(&mockConfigUseCase{loadResult: contentIdentityConfig(t)}, cacheManager, service, hasher)
+
+	renames, err := uc.Relink()
+	if err != nil {
+		t.Fatalf("Relink() error = %v", err)
+	}
+	want := []entities.OutfitRename{
+		{FromCategory: "casual", From: "old-name.avatar", ToCategory: "casual", To: "new-name.avatar"},
+		{FromCategory: "casual", From: "to-move.avatar", ToCategory: "formal", To: "moved.avatar"},
+	}
+	if !reflect.DeepEqual(renames, want) {
+		t.Fatalf("Relink() = %+v, want %+v", renames, want)
+	}
+	categories := cacheManager.loadResult.Categories
+	wantCasual := map[string]bool{"b.avatar": true, "gone.avatar": true, "lost.avatar": true, "new-name.avatar": true}
+	if !reflect.DeepEqual(categories["casual"].WornOutfits, wantCasual) {
+		t.Fatalf("casual worn = %v, want %v", categories["casual"].WornOutfits, wantCasual)
+	}
+	if formal := categories["formal"]; !formal.WornOutfits["moved.avatar"] || formal.TotalOutfits != 1 {
+		t.Fatalf("formal = %+v, want moved.avatar worn out of 1", formal)
+	}
+	wantRecorded := map[string]string{
+		"/ssd/casual/b.avatar":        "hash-b",
+		"/ssd/casual/new-name.avatar": "hash-renamed",
+		"/ssd/formal/moved.avatar":    "hash-moved",
+		"/ssd/casual/lost.avatar":     "hash-lost",
+	}
+	if !reflect.DeepEqual(hasher.recorded, wantRecorded) || hasher.saves != 1 {
+		t.Fatalf("recorded = %v after %d saves, want %v saved once, with the renamed paths forgotten", hasher.recorded, hasher.saves, wantRecorded)
+	}
+
+	hasher.hashed = nil
+	if renames, err := uc.Relink(); err != nil || len(renames) != 0 {
+		t.Fatalf("second Relink() = %+v, %v; want nothing left to match", renames, err)
+	}
+	if want := []string{"/ssd/casual/b.avatar", "/ssd/casual/new-name.avatar", "/ssd/formal/moved.avatar"}; !reflect.DeepEqual(hasher.hashed, want) {
+		t.Fatalf("hashed = %v, want only the worn outfits when every other file is worn", hasher.hashed)
+	}
+}
+
+func TestOutfitIdentityUseCase_ByFileName(t *testing.T) {
+	service, hasher, cache := renamedWardrobe()
+	uc := NewOutfitIdentityUseCase(&mockConfigUseCase{loadResult: multiRootConfig(t, "/ssd")}, &mockCacheService{loadResult: &cache}, service, hasher)
+
+	if renames, err := uc.Relink(); err != nil || renames != nil {
+		t.Fatalf("Relink() = %+v, %v; want nothing when outfits are matched by name", renames, err)
+	}
+	if err := uc.Remember(entities.NewOutfitReference("b.avatar", entities.NewCategoryReference("casual", "/ssd/casual"))); err != nil {
+		t.Fatalf("Remember() error = %v", err)
+	}
+	if len(hasher.hashed) != 0 || hasher.saves != 0 {
+		t.Fatalf("hashed %v and saved %d times, want no hashing", hasher.hashed, hasher.saves)
+	}
+}
+
+func TestOutfitIdentityUseCase_Remember(t *testing.T) {
+	_, hasher, _ := renamedWardrobe()
+	uc := NewOutfitIdentityUseCase(&mockConfigUseCase{loadResult: contentIdentityConfig(t)}, &mockCacheService{}, nil, hasher)
+
+	if err := uc.Remember(entities.NewOutfitReference("b.avatar", entities.NewCategoryReference("casual", "/ssd/casual"))); err != nil {
+		t.Fatalf("Remember() error = %v", err)
+	}
+	if hasher.recorded["/ssd/casual/b.avatar"] != "hash-b" || hasher.saves != 1 {
+		t.Fatalf("recorded = %v after %d saves, want b.avatar saved", hasher.recorded, hasher.saves)
+	}
+	if err := uc.Remember(entities.NewOutfitReference("missing.avatar", entities.NewCategoryReference("casual", "/ssd/casual"))); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("Remember(missing) error = %v, want %v", err, assert.AnError)
+	}
+}
+
+func TestOutfitIdentityUseCase_LeavesArchivedOutfitsAlone(t *testing.T) {
+	service := &rootedCategoryService{
+		scans: map[string][]entities.CategoryInfo{"/pack.zip": {
+			entities.NewCategoryInfo(entities.NewCategoryReference("casual", "/pack.zip/casual"), entities.CategoryStateHasOutfits, 2),
+		}},
+		outfits: map[string][]entities.FileEntry{"/pack.zip/casual": {{FileName: "a.avatar"}, {FileName: "b.avatar"}}},
+	}
+	hasher := &stubHasher{recorded: map[string]string{"/pack.zip/casual/lost.avatar": "hash-lost"}}
+	cache := entities.NewOutfitCache().Updating("casual", entities.NewCategoryCache(2).Adding("a.avatar").Adding("lost.avatar"))
+	config := multiRootConfig(t, "/pack.zip").WithIdentity(entities.IdentityContent)
+	uc := NewOutfitIdentityUseCase(&mockConfigUseCase{loadResult: config}, &mockCacheService{loadResult: &cache}, service, hasher)
+
+	if renames, err := uc.Relink(); err != nil || renames != nil {
+		t.Fatalf("Relink() = %+v, %v; want archived outfits left unhashed", renames, err)
+	}
+	if err := uc.Remember(entities.NewOutfitReference("a.avatar", entities.NewCategoryReference("casual", "/pack.zip/casual"))); err != nil {
+		t.Fatalf("Remember() of an archived outfit error = %v", err)
+	}
+	if len(hasher.hashed) != 0 {
+		t.Fatalf("hashed %v, want no archived outfit hashed", hasher.hashed)
+	}
+}
+
+func TestOutfitIdentityUseCase_RelinkWaitsForUnreadableCategories(t *testing.T) {
+	service, hasher, cache := renamedWardrobe()
+	// casual cannot be read, while formal holds a file with the contents of
+	// the worn b.avatar.
+	delete(service.outfits, "/ssd/casual")
+	service.outfits["/ssd/formal"] = append(service.outfits["/ssd/formal"], entities.FileEntry{FileName: "copy.avatar"})
+	hasher.current["/ssd/formal/copy.avatar"] = "hash-b"
+	hasher.recorded["/ssd/casual/b.avatar"] = "hash-b"
+	cacheManager := &mockCacheService{loadResult: &cache}
+	uc := NewOutfitIdentityUseCase(&mockConfigUseCase{loadResult: contentIdentityConfig(t)}, cacheManager, service, hasher)
+
+	if renames, err := uc.Relink(); err != nil || renames != nil {
+		t.Fatalf("Relink() = %+v, %v; want nothing relinked while casual is unreadable", renames, err)
+	}
+	if cacheManager.saveCalls != 0 || len(hasher.hashed) != 0 || hasher.saves != 0 {
+		t.Fatalf("saved the cache %d times and hashed %v; want nothing touched", cacheManager.saveCalls, hasher.hashed)
+	}
+	if _, ok := hasher.recorded["/ssd/casual/old-name.avatar"]; !ok {
+		t.Error("hashes of the unreadable category were forgotten")
+	}
+}
+
+func TestOutfitIdentityUseCase_RelinkErrors(t *testing.T) {
+	config := contentIdentityConfig(t)
+	tests := []struct {
+		name  string
+		setup func(service *rootedCategoryService, hasher *stubHasher, cacheManager *mockCacheService, configManager *mockConfigUseCase)
+	}{
+		{name: "config", setup: func(_ *rootedCategoryService, _ *stubHasher, _ *mockCacheService, configManager *mockConfigUseCase) {
+			configManager.loadError = assert.AnError
+		}},
+		{name: "cache", setup: func(_ *rootedCategoryService, _ *stubHasher, cacheManager *mockCacheService, _ *mockConfigUseCase) {
+			cacheManager.loadError = assert.AnError
+		}},
+		{name: "hash", setup: func(_ *rootedCategoryService, hasher *stubHasher, _ *mockCacheService, _ *mockConfigUseCase) {
+			hasher.err = assert.AnError
+		}},
+		{name: "save", setup: func(_ *rootedCategoryService, _ *stubHasher, cacheManager *mockCacheService, _ *mockConfigUseCase) {
+			cacheManager.saveError = assert.AnError
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, hasher, cache := renamedWardrobe()
+			cacheManager := &mockCacheService{loadResult: &cache}
+			configManager := &mockConfigUseCase{loadResult: config}
+			tt.setup(service, hasher, cacheManager, configManager)
+
+			if _, err := NewOutfitIdentityUseCase(configManager, cacheManager, service, hasher).Relink(); !stderrors.Is(err, assert.AnError) {
+				t.Fatalf("Relink() error = %v, want %v", err, assert.AnError)
+			}
+		})
+	}
+}
+
+func TestOutfitPath_FindsTheRootOfAKey(t *testing.T) {
+	config := multiRootConfig(t, "/ssd", "/nas")
+	if got := outfitPath(config, "casual", "a.avatar@/nas"); got != "/nas/casual/a.avatar" {
+		t.Fatalf("outfitPath(second root) = %q", got)
+	}
+	if got := outfitPath(config, "casual", "a.avatar"); got != "/ssd/casual/a.avatar" {
+		t.Fatalf("outfitPath(first root) = %q", got)
+	}
+}
@@ -1,8 +1,6 @@
 package usecases
 
 import (
-	"path/filepath"
-
 	"github.com/dh85/outfitpicker/internal/domain/entities"
 	"github.com/dh85/outfitpicker/internal/domain/interfaces"
 	"github.com/dh85/outfitpicker/internal/domain/logic"
@@ -33,8 +31,7 @@ func (uc *PickOutfitUseCase) LoadAvailableOutfits(categoryName string) ([]entiti
 		return nil, err
 	}
 
-	categoryPath := filepath.Join(config.Root, categoryName)
-	files, err := uc.categoryService.GetOutfits(categoryPath)
+	files, err := categoryOutfits(uc.categoryService, config, categoryName)
 	if err != nil {
 		return nil, err
 	}
@@ -59,9 +56,8 @@ func (uc *PickOutfitUseCase) LoadAvailableOutfits(categoryName string) ([]entiti
 	}
 
 	outfits := make([]entities.OutfitReference, 0, len(pool))
-	category := entities.NewCategoryReference(categoryName, categoryPath)
 	for _, file := range pool {
-		outfits = append(outfits, entities.NewOutfitReference(file.FileName, category))
+		outfits = append(outfits, entities.NewOutfitReferenceFromFile(categoryName, file))
 	}
 
 	return outfits, nil
@@ -21,7 +21,7 @@ func TestPickOutfitUseCase_LoadAvailableOutfits(t *testing.T) {
 			name:         "returns error when category name invalid",
 			categoryName: "",
 			setup: func() *PickOutfitUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				cache := entities.NewOutfitCache()
 				return NewPickOutfitUseCase(
 					&mockCategoryService{},
@@ -36,7 +36,7 @@ func TestPickOutfitUseCase_LoadAvailableOutfits(t *testing.T) {
 			name:         "returns nil when no outfits found",
 			categoryName: "casual",
 			setup: func() *PickOutfitUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				cache := entities.NewOutfitCache()
 				return NewPickOutfitUseCase(
 					&mockCategoryService{outfitsResult: []entities.FileEntry{}},
@@ -50,7 +50,7 @@ func TestPickOutfitUseCase_LoadAvailableOutfits(t *testing.T) {
 			name:         "returns nil when rotation is complete",
 			categoryName: "casual",
 			setup: func() *PickOutfitUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				cache := entities.NewOutfitCache()
 				categoryCache := entities.NewCategoryCache(2).Adding("outfit1.avatar").Adding("outfit2.avatar")
 				cache = cache.Updating("casual", categoryCache)
@@ -66,7 +66,7 @@ func TestPickOutfitUseCase_LoadAvailableOutfits(t *testing.T) {
 			name:         "filters worn outfits and preserves category path",
 			categoryName: "casual",
 			setup: func() *PickOutfitUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				cache := entities.NewOutfitCache()
 				categoryCache := entities.NewCategoryCache(3).Adding("outfit1.avatar")
 				cache = cache.Updating("casual", categoryCache)
@@ -85,7 +85,7 @@ func TestPickOutfitUseCase_LoadAvailableOutfits(t *testing.T) {
 			name:         "falls back to full file list when filtered pool is empty",
 			categoryName: "casual",
 			setup: func() *PickOutfitUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				cache := entities.NewOutfitCache()
 				categoryCache := entities.NewCategoryCache(3).Adding("outfit1.avatar").Adding("outfit2.avatar")
 				cache = cache.Updating("casual", categoryCache)
@@ -0,0 +1,92 @@
+package usecases
+
+import (
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+	"github.com/dh85/outfitpicker/internal/domain/validation"
+)
+
+// ProfileUseCase creates, lists, copies, and deletes profiles on behalf of
+// the profile selected for this run.
+type ProfileUseCase struct {
+	repo   interfaces.ProfileRepository
+	active string
+}
+
+// NewProfileUseCase manages profiles while active is in use. An empty active
+// name is the default profile.
+func NewProfileUseCase(repo interfaces.ProfileRepository, active string) *ProfileUseCase {
+	if active == "" {
+		active = entities.DefaultProfile
+	}
+	return &ProfileUseCase{repo: repo, active: active}
+}
+
+// ActiveProfile returns the name of the profile in use.
+func (uc *ProfileUseCase) ActiveProfile() string {
+	return uc.active
+}
+
+// Active returns the profile in use, or ErrProfileNotFound when a named
+// profile was selected before it was created.
+func (uc *ProfileUseCase) Active() (entities.Profile, error) {
+	if err := validateProfileName(uc.active); err != nil {
+		return entities.Profile{}, err
+	}
+	return uc.repo.Get(uc.active)
+}
+
+// ListProfiles returns the default profile followed by the named ones.
+func (uc *ProfileUseCase) ListProfiles() ([]entities.Profile, error) {
+	return uc.repo.List()
+}
+
+// CreateProfile creates an empty profile; outfitpicker runs first-time setup
+// the first time it is used.
+func (uc *ProfileUseCase) CreateProfile(name string) (entities.Profile, error) {
+	if err := validateNewProfileName(name); err != nil {
+		return entities.Profile{}, err
+	}
+	return uc.repo.Create(name)
+}
+
+// CopyProfile creates profile to from the state of profile from.
+func (uc *ProfileUseCase) CopyProfile(from, to string) (entities.Profile, error) {
+	if err := validateProfileName(from); err != nil {
+		return entities.Profile{}, err
+	}
+	if err := validateNewProfileName(to); err != nil {
+		return entities.Profile{}, err
+	}
+	return uc.repo.Copy(from, to)
+}
+
+// DeleteProfile deletes a named profile and everything in it. The default
+// profile and the profile in use cannot be deleted.
+func (uc *ProfileUseCase) DeleteProfile(name string) error {
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+	if entities.IsDefaultProfile(name) {
+		return errors.NewInvalidInputError("the default profile cannot be deleted")
+	}
+	if name == uc.active {
+		return errors.NewInvalidInputError("profile " + name + " is in use; select another profile with --profile to delete it")
+	}
+	return uc.repo.Delete(name)
+}
+
+func validateProfileName(name string) error {
+	if entities.IsDefaultProfile(name) {
+		return nil
+	}
+	return validation.ValidateProfileName(name)
+}
+
+func validateNewProfileName(name string) error {
+	if entities.IsDefaultProfile(name) {
+		return errors.NewProfileExistsError(entities.DefaultProfile)
+	}
+	return validation.ValidateProfileName(name)
+}
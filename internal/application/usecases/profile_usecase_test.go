@@ -0,0 +1,76 @@
+package usecases
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+func TestProfileUseCase_Active(t *testing.T) {
+	repo := &mockProfileRepository{profiles: map[string]bool{"alex": true}}
+
+	if uc := NewProfileUseCase(repo, ""); uc.ActiveProfile() != entities.DefaultProfile {
+		t.Fatalf("ActiveProfile() = %q, want the default profile", uc.ActiveProfile())
+	}
+	if profile, err := NewProfileUseCase(repo, "alex").Active(); err != nil || profile.Name != "alex" {
+		t.Fatalf("Active() = %+v, %v; want alex", profile, err)
+	}
+	if _, err := NewProfileUseCase(repo, "sam").Active(); !stderrors.Is(err, errors.ErrProfileNotFound) {
+		t.Fatalf("Active() of a missing profile error = %v, want %v", err, errors.ErrProfileNotFound)
+	}
+	var invalidInput *errors.InvalidInputError
+	if _, err := NewProfileUseCase(repo, "../alex").Active(); !stderrors.As(err, &invalidInput) {
+		t.Fatalf("Active() of an invalid name error = %v, want invalid input", err)
+	}
+}
+
+func TestProfileUseCase_CreateCopyAndList(t *testing.T) {
+	repo := &mockProfileRepository{}
+	uc := NewProfileUseCase(repo, "")
+
+	if profile, err := uc.CreateProfile("alex"); err != nil || profile.Name != "alex" {
+		t.Fatalf("CreateProfile() = %+v, %v; want alex", profile, err)
+	}
+	if profile, err := uc.CopyProfile(entities.DefaultProfile, "sam"); err != nil || profile.Name != "sam" {
+		t.Fatalf("CopyProfile() = %+v, %v; want sam", profile, err)
+	}
+	if len(repo.copied) != 1 || repo.copied[0] != [2]string{entities.DefaultProfile, "sam"} {
+		t.Fatalf("copied = %v, want default to sam", repo.copied)
+	}
+	if profiles, err := uc.ListProfiles(); err != nil || len(profiles) != 3 {
+		t.Fatalf("ListProfiles() = %+v, %v; want three profiles", profiles, err)
+	}
+}
+
+func TestProfileUseCase_RejectsInvalidChanges(t *testing.T) {
+	repo := &mockProfileRepository{profiles: map[string]bool{"alex": true}}
+	uc := NewProfileUseCase(repo, "alex")
+	var invalidInput *errors.InvalidInputError
+
+	if _, err := uc.CreateProfile(entities.DefaultProfile); !stderrors.Is(err, errors.ErrProfileExists) {
+		t.Fatalf("CreateProfile(default) error = %v, want %v", err, errors.ErrProfileExists)
+	}
+	if _, err := uc.CreateProfile("a/b"); !stderrors.As(err, &invalidInput) {
+		t.Fatalf("CreateProfile(a/b) error = %v, want invalid input", err)
+	}
+	if _, err := uc.CopyProfile("../x", "sam"); !stderrors.As(err, &invalidInput) {
+		t.Fatalf("CopyProfile() from an invalid name error = %v, want invalid input", err)
+	}
+	if _, err := uc.CopyProfile("alex", ""); !stderrors.Is(err, errors.ErrProfileExists) {
+		t.Fatalf("CopyProfile() onto the default profile error = %v, want %v", err, errors.ErrProfileExists)
+	}
+	for _, name := range []string{"", entities.DefaultProfile, "alex", ".x"} {
+		if err := uc.DeleteProfile(name); !stderrors.As(err, &invalidInput) {
+			t.Fatalf("DeleteProfile(%q) error = %v, want invalid input", name, err)
+		}
+	}
+	if len(repo.deleted) != 0 {
+		t.Fatalf("deleted = %v, want nothing deleted", repo.deleted)
+	}
+
+	if err := NewProfileUseCase(repo, "").DeleteProfile("alex"); err != nil || len(repo.deleted) != 1 {
+		t.Fatalf("DeleteProfile(alex) error = %v, deleted = %v", err, repo.deleted)
+	}
+}
@@ -0,0 +1,61 @@
+package usecases
+
+import (
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+	"github.com/dh85/outfitpicker/internal/domain/logic"
+)
+
+// ReconcileCacheUseCase brings the worn outfit cache back in line with the
+// wardrobe after files were added or deleted outside outfitpicker.
+type ReconcileCacheUseCase struct {
+	configManager   ConfigManager
+	cacheManager    CacheManager
+	categoryService interfaces.CategoryService
+}
+
+func NewReconcileCacheUseCase(configManager ConfigManager, cacheManager CacheManager, categoryService interfaces.CategoryService) *ReconcileCacheUseCase {
+	return &ReconcileCacheUseCase{configManager, cacheManager, categoryService}
+}
+
+// Check reports how the stored cache differs from the wardrobe without
+// changing it.
+func (uc *ReconcileCacheUseCase) Check() (entities.CacheReconciliation, error) {
+	var result entities.CacheReconciliation
+	config, err := loadConfig(uc.configManager)
+	if err != nil {
+		return result, err
+	}
+	outfits, unreadable := wardrobeOutfitKeys(uc.categoryService, config)
+	if result.Unreadable = unreadable; len(unreadable) > 0 {
+		return result, nil
+	}
+	cache, err := uc.cacheManager.LoadOrCreate()
+	if err != nil {
+		return result, err
+	}
+	_, result.Categories = logic.ReconcileCache(*cache, outfits)
+	return result, nil
+}
+
+// Execute reconciles the stored cache with the wardrobe and reports what it
+// changed.
+func (uc *ReconcileCacheUseCase) Execute() (entities.CacheReconciliation, error) {
+	var result entities.CacheReconciliation
+	config, err := loadConfig(uc.configManager)
+	if err != nil {
+		return result, err
+	}
+	outfits, unreadable := wardrobeOutfitKeys(uc.categoryService, config)
+	if result.Unreadable = unreadable; len(unreadable) > 0 {
+		return result, nil
+	}
+	err = uc.cacheManager.Update(func(cache *entities.OutfitCache) error {
+		*cache, result.Categories = logic.ReconcileCache(*cache, outfits)
+		return nil
+	})
+	if err != nil {
+		return entities.CacheReconciliation{}, err
+	}
+	return result, nil
+}
@@ -0,0 +1,139 @@
+package usecases
+
+import (
+	stderrors "errors"
+	"reflect"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+func staleWardrobe() (*rootedCategoryService, entities.OutfitCache) {
+	info := func(root, name string, count int) entities.CategoryInfo {
+		return entities.NewCategoryInfo(entities.NewCategoryReference(name, root+"/"+name), entities.CategoryStateHasOutfits, count)
+	}
+	service := &rootedCategoryService{
+		scans: map[string][]entities.CategoryInfo{
+			"/ssd": {info("/ssd", "casual", 1)},
+			"/nas": {info("/nas", "casual", 1)},
+		},
+		outfits: map[string][]entities.FileEntry{
+			"/ssd/casual": {{FileName: "a.avatar"}},
+			"/nas/casual": {{FileName: "b.avatar"}},
+		},
+	}
+	casual := entities.NewCategoryCache(5).Adding("a.avatar").Adding("b.avatar@/nas").Adding("deleted.avatar")
+	cache := entities.NewOutfitCache().
+		Updating("casual", casual).
+		Updating("beach", entities.NewCategoryCache(1).Adding("towel.avatar"))
+	return service, cache
+}
+
+func TestReconcileCacheUseCase_CheckAndExecute(t *testing.T) {
+	service, cache := staleWardrobe()
+	cacheManager := &mockCacheService{loadResult: &cache}
+	uc := NewReconcileCacheUseCase(&mockConfigUseCase{loadResult: multiRootConfig(t, "/ssd", "/nas")}, cacheManager, service)
+	want := []entities.CategoryReconciliation{
+		{Category: "beach", OldTotal: 1, Orphans: []string{"towel.avatar"}, Removed: true},
+		{Category: "casual", OldTotal: 5, NewTotal: 2, Orphans: []string{"deleted.avatar"}},
+	}
+
+	checked, err := uc.Check()
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !reflect.DeepEqual(checked.Categories, want) {
+		t.Fatalf("Check() = %+v, want %+v", checked.Categories, want)
+	}
+	if cacheManager.saved != nil {
+		t.Fatal("Check() saved the cache")
+	}
+
+	executed, err := uc.Execute()
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !reflect.DeepEqual(executed.Categories, want) {
+		t.Fatalf("Execute() = %+v, want %+v", executed.Categories, want)
+	}
+	saved := cacheManager.saved
+	if _, ok := saved.Categories["beach"]; ok || saved.Categories["casual"].TotalOutfits != 2 || saved.Categories["casual"].WornOutfits["deleted.avatar"] {
+		t.Errorf("saved cache = %+v, want it reconciled", saved)
+	}
+
+	again, err := uc.Execute()
+	if err != nil || again.Changed() {
+		t.Errorf("second Execute() = %+v, %v; want no changes", again, err)
+	}
+}
+
+func TestReconcileCacheUseCase_LeavesCacheWhenWardrobeUnreadable(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(service *rootedCategoryService)
+		want  []string
+	}{
+		{name: "root", setup: func(service *rootedCategoryService) { delete(service.scans, "/nas") }, want: []string{"/nas"}},
+		{name: "category", setup: func(service *rootedCategoryService) {
+			delete(service.outfits, "/ssd/casual")
+			delete(service.outfits, "/nas/casual")
+		}, want: []string{"/ssd/casual"}},
+		{name: "category in one root", setup: func(service *rootedCategoryService) {
+			service.scans["/nas"] = append(service.scans["/nas"], entities.NewCategoryInfo(
+				entities.NewCategoryReference("beach", "/nas/beach"), entities.CategoryStatePermissionDenied, 0))
+		}, want: []string{"/nas/beach"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, cache := staleWardrobe()
+			tt.setup(service)
+			cacheManager := &mockCacheService{loadResult: &cache}
+			uc := NewReconcileCacheUseCase(&mockConfigUseCase{loadResult: multiRootConfig(t, "/ssd", "/nas")}, cacheManager, service)
+
+			for name, run := range map[string]func() (entities.CacheReconciliation, error){"Check": uc.Check, "Execute": uc.Execute} {
+				result, err := run()
+				if err != nil {
+					t.Fatalf("%s() error = %v", name, err)
+				}
+				if result.Changed() || !reflect.DeepEqual(result.Unreadable, tt.want) {
+					t.Errorf("%s() = %+v, want only %v unreadable", name, result, tt.want)
+				}
+			}
+			if cacheManager.saved != nil {
+				t.Error("cache was saved while the wardrobe could not be read")
+			}
+		})
+	}
+}
+
+func TestReconcileCacheUseCase_Errors(t *testing.T) {
+	t.Run("no configuration", func(t *testing.T) {
+		service, cache := staleWardrobe()
+		uc := NewReconcileCacheUseCase(&mockConfigUseCase{}, &mockCacheService{loadResult: &cache}, service)
+		if _, err := uc.Check(); !stderrors.Is(err, domainerrors.ErrConfigurationNotFound) {
+			t.Errorf("Check() error = %v, want ErrConfigurationNotFound", err)
+		}
+	})
+
+	t.Run("config", func(t *testing.T) {
+		service, cache := staleWardrobe()
+		uc := NewReconcileCacheUseCase(&mockConfigUseCase{loadError: assert.AnError}, &mockCacheService{loadResult: &cache}, service)
+		if _, err := uc.Execute(); !stderrors.Is(err, assert.AnError) {
+			t.Errorf("Execute() error = %v, want %v", err, assert.AnError)
+		}
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		service, _ := staleWardrobe()
+		cacheManager := &mockCacheService{loadError: assert.AnError}
+		uc := NewReconcileCacheUseCase(&mockConfigUseCase{loadResult: multiRootConfig(t, "/ssd", "/nas")}, cacheManager, service)
+		if _, err := uc.Check(); !stderrors.Is(err, assert.AnError) {
+			t.Errorf("Check() error = %v, want %v", err, assert.AnError)
+		}
+		if _, err := uc.Execute(); !stderrors.Is(err, assert.AnError) {
+			t.Errorf("Execute() error = %v, want %v", err, assert.AnError)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package usecases
 
 import (
 	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
 	"github.com/dh85/outfitpicker/internal/domain/logic"
 )
 
@@ -15,6 +16,24 @@ func NewResetCategoryUseCase(configManager ConfigManager, cacheManager CacheMana
 }
 
 func (uc *ResetCategoryUseCase) Execute(categoryName string) error {
+	return uc.reset(categoryName, func(entities.CategoryCache) error { return nil })
+}
+
+// ExecuteIfUnchanged is Execute for a category whose worn outfits were shown
+// at wornRevision. It fails with ErrStaleRevision, leaving the category as it
+// is, when they have changed since.
+func (uc *ResetCategoryUseCase) ExecuteIfUnchanged(categoryName, wornRevision string) error {
+	return uc.reset(categoryName, func(current entities.CategoryCache) error {
+		if current.WornRevision() != wornRevision {
+			return errors.NewStaleCategoryError(categoryName)
+		}
+		return nil
+	})
+}
+
+// reset removes categoryName from the cache if check, run under the cache
+// lock with the category as stored, allows it.
+func (uc *ResetCategoryUseCase) reset(categoryName string, check func(current entities.CategoryCache) error) error {
 	if err := logic.ValidateCategoryName(categoryName); err != nil {
 		return err
 	}
@@ -23,13 +42,13 @@ func (uc *ResetCategoryUseCase) Execute(categoryName string) error {
 		return err
 	}
 
-	cache, err := uc.cacheManager.LoadOrCreate()
-	if err != nil {
-		return err
-	}
-
-	updatedCache := cache.Removing(categoryName)
-	return uc.cacheManager.Save(&updatedCache)
+	return uc.cacheManager.Update(func(cache *entities.OutfitCache) error {
+		if err := check(cache.Categories[categoryName]); err != nil {
+			return err
+		}
+		*cache = cache.Removing(categoryName)
+		return nil
+	})
 }
 
 func (uc *ResetCategoryUseCase) ExecuteAll() error {
@@ -37,6 +56,8 @@ func (uc *ResetCategoryUseCase) ExecuteAll() error {
 		return err
 	}
 
-	newCache := entities.NewOutfitCache()
-	return uc.cacheManager.Save(&newCache)
+	return uc.cacheManager.Update(func(cache *entities.OutfitCache) error {
+		*cache = cache.Clearing()
+		return nil
+	})
 }
@@ -1,9 +1,11 @@
 package usecases
 
 import (
+	stderrors "errors"
 	"testing"
 
 	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
 )
 
 func TestResetCategoryUseCase_Execute(t *testing.T) {
@@ -17,7 +19,7 @@ func TestResetCategoryUseCase_Execute(t *testing.T) {
 			name:         "resets category successfully",
 			categoryName: "casual",
 			setup: func() *ResetCategoryUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				cache := entities.NewOutfitCache().Updating("casual", entities.NewCategoryCache(2).Adding("outfit1.avatar"))
 				return NewResetCategoryUseCase(
 					&mockConfigUseCase{loadResult: config},
@@ -29,7 +31,7 @@ func TestResetCategoryUseCase_Execute(t *testing.T) {
 			name:         "returns error when category name invalid",
 			categoryName: "",
 			setup: func() *ResetCategoryUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				cache := entities.NewOutfitCache()
 				return NewResetCategoryUseCase(
 					&mockConfigUseCase{loadResult: config},
@@ -53,7 +55,7 @@ func TestResetCategoryUseCase_Execute(t *testing.T) {
 			name:         "returns error when cache load fails",
 			categoryName: "casual",
 			setup: func() *ResetCategoryUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				return NewResetCategoryUseCase(
 					&mockConfigUseCase{loadResult: config},
 					&mockCacheService{loadError: assert.AnError},
@@ -65,7 +67,7 @@ func TestResetCategoryUseCase_Execute(t *testing.T) {
 			name:         "returns error when cache save fails",
 			categoryName: "casual",
 			setup: func() *ResetCategoryUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				cache := entities.NewOutfitCache()
 				return NewResetCategoryUseCase(
 					&mockConfigUseCase{loadResult: config},
@@ -84,6 +86,36 @@ func TestResetCategoryUseCase_Execute(t *testing.T) {
 	}
 }
 
+func TestResetCategoryUseCase_ExecuteIfUnchanged(t *testing.T) {
+	config, _ := entities.NewConfig("/test/path", nil, nil)
+	shown := entities.NewCategoryCache(2).Adding("outfit1.avatar")
+	cache := entities.NewOutfitCache().Updating("casual", shown).Updating("formal", entities.NewCategoryCache(1).Adding("suit.avatar"))
+	caches := &mockCacheService{loadResult: &cache}
+	useCase := NewResetCategoryUseCase(&mockConfigUseCase{loadResult: config}, caches)
+
+	changed := cache.Updating("casual", shown.Adding("outfit2.avatar")).Updating("formal", entities.NewCategoryCache(1))
+	caches.loadResult = &changed
+	if err := useCase.ExecuteIfUnchanged("casual", shown.WornRevision()); !stderrors.Is(err, domainerrors.ErrStaleRevision) {
+		t.Fatalf("ExecuteIfUnchanged() after casual changed error = %v, want %v", err, domainerrors.ErrStaleRevision)
+	}
+	if caches.saveCalls != 0 {
+		t.Fatalf("saveCalls = %d, want 0 after a stale reset", caches.saveCalls)
+	}
+
+	otherChanged := cache.Updating("formal", entities.NewCategoryCache(1))
+	otherChanged.Revision = cache.Revision + 5
+	caches.loadResult = &otherChanged
+	if err := useCase.ExecuteIfUnchanged("casual", shown.WornRevision()); err != nil {
+		t.Fatalf("ExecuteIfUnchanged() after only formal changed error = %v", err)
+	}
+	if _, ok := caches.loadResult.Categories["casual"]; ok {
+		t.Fatal("casual was not reset")
+	}
+	if err := useCase.ExecuteIfUnchanged("shoes", entities.NewCategoryCache(0).WornRevision()); err != nil {
+		t.Fatalf("ExecuteIfUnchanged() for a category with nothing worn error = %v", err)
+	}
+}
+
 func TestResetCategoryUseCase_ExecuteAll(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -93,7 +125,7 @@ func TestResetCategoryUseCase_ExecuteAll(t *testing.T) {
 		{
 			name: "resets all categories successfully",
 			setup: func() *ResetCategoryUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				return NewResetCategoryUseCase(
 					&mockConfigUseCase{loadResult: config},
 					&mockCacheService{},
@@ -113,7 +145,7 @@ func TestResetCategoryUseCase_ExecuteAll(t *testing.T) {
 		{
 			name: "returns error when cache save fails",
 			setup: func() *ResetCategoryUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				return NewResetCategoryUseCase(
 					&mockConfigUseCase{loadResult: config},
 					&mockCacheService{saveError: assert.AnError},
@@ -0,0 +1,116 @@
+package usecases
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+	"github.com/dh85/outfitpicker/internal/domain/logic"
+)
+
+// SnapshotCategoryService stands in for the wardrobe while it is offline. It
+// lists the categories and outfits recorded by the last run that could read
+// the whole wardrobe.
+type SnapshotCategoryService struct {
+	configManager ConfigManager
+	known         interfaces.KnownWardrobeRepository
+}
+
+func NewSnapshotCategoryService(configManager ConfigManager, known interfaces.KnownWardrobeRepository) *SnapshotCategoryService {
+	return &SnapshotCategoryService{configManager, known}
+}
+
+// ScanCategories lists the recorded categories with outfits in the wardrobe
+// root rootPath. Categories recorded without any outfits belong to the first
+// root.
+func (s *SnapshotCategoryService) ScanCategories(rootPath string, excludedCategories map[string]bool) ([]entities.CategoryInfo, error) {
+	snapshot, err := s.snapshot(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	var categories []entities.CategoryInfo
+	for name := range snapshot.known.Categories {
+		count := len(snapshot.files(name))
+		if snapshot.origin != "" && count == 0 {
+			continue
+		}
+		category := entities.NewCategoryReference(name, filepath.Join(rootPath, name))
+		switch {
+		case excludedCategories[name]:
+			categories = append(categories, entities.NewCategoryInfo(category, entities.CategoryStateUserExcluded, 0))
+		case count == 0:
+			categories = append(categories, entities.NewCategoryInfo(category, entities.CategoryStateEmpty, 0))
+		default:
+			categories = append(categories, entities.NewCategoryInfo(category, entities.CategoryStateHasOutfits, count))
+		}
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].Category.Name < categories[j].Category.Name
+	})
+	return categories, nil
+}
+
+// GetOutfits lists the recorded outfits of the category at categoryPath,
+// sorted by name.
+func (s *SnapshotCategoryService) GetOutfits(categoryPath string) ([]entities.FileEntry, error) {
+	snapshot, err := s.snapshot(filepath.Dir(categoryPath))
+	if err != nil {
+		return nil, err
+	}
+	name := filepath.Base(categoryPath)
+	if !snapshot.known.HasCategory(name) {
+		return nil, fmt.Errorf("%s: %w", categoryPath, errors.ErrCategoryNotFound)
+	}
+	files := snapshot.files(name)
+	outfits := make([]entities.FileEntry, len(files))
+	for index, file := range files {
+		outfits[index] = entities.FileEntry{FileName: file}
+	}
+	return outfits, nil
+}
+
+// wardrobeSnapshot is the recorded wardrobe seen from one of its roots.
+type wardrobeSnapshot struct {
+	known entities.KnownWardrobe
+	roots []string
+	// origin is empty for the first root, or the root itself.
+	origin string
+}
+
+// snapshot loads the recorded wardrobe as seen from rootPath, which must be
+// one of the configured roots.
+func (s *SnapshotCategoryService) snapshot(rootPath string) (wardrobeSnapshot, error) {
+	config, err := loadConfig(s.configManager)
+	if err != nil {
+		return wardrobeSnapshot{}, err
+	}
+	known, err := loadKnownWardrobe(s.known)
+	if err != nil {
+		return wardrobeSnapshot{}, err
+	}
+	snapshot := wardrobeSnapshot{known: known, roots: config.WardrobeRoots()}
+	switch index := slices.Index(snapshot.roots, rootPath); {
+	case index < 0:
+		return wardrobeSnapshot{}, fmt.Errorf("%s: %w", rootPath, errors.ErrDirectoryNotFound)
+	case index > 0:
+		snapshot.origin = rootPath
+	}
+	return snapshot, nil
+}
+
+// files returns the sorted file names recorded for the category named name
+// in the snapshot's root.
+func (s wardrobeSnapshot) files(name string) []string {
+	var files []string
+	for key := range s.known.Categories[name] {
+		if file, root := logic.SplitOutfitKey(key, s.roots); root == s.origin {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
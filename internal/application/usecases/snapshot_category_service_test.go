@@ -0,0 +1,73 @@
+package usecases
+
+import (
+	stderrors "errors"
+	"reflect"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+func snapshotConfig(t *testing.T) *entities.Config {
+	t.Helper()
+	return multiRootConfig(t, "/ssd", "/nas")
+}
+
+func snapshotWardrobe() *mockKnownWardrobe {
+	return knownWardrobe(map[string][]string{
+		"casual": {"b.avatar", "a.avatar", "a.avatar@/nas"},
+		"formal": {"suit.avatar@/nas"},
+		"hats":   nil,
+	})
+}
+
+func TestSnapshotCategoryService_StandsInForTheWardrobe(t *testing.T) {
+	config := snapshotConfig(t)
+	config.ExcludedCategories = map[string]bool{"formal": true}
+	service := NewSnapshotCategoryService(&mockConfigUseCase{loadResult: config}, snapshotWardrobe())
+
+	infos, err := scanWardrobe(service, config)
+	if err != nil {
+		t.Fatalf("scanWardrobe() error = %v", err)
+	}
+	want := map[string]entities.CategoryState{"casual": entities.CategoryStateHasOutfits, "formal": entities.CategoryStateUserExcluded, "hats": entities.CategoryStateEmpty}
+	got := map[string]entities.CategoryState{}
+	for _, info := range infos {
+		got[info.Category.Name] = info.State
+	}
+	if !reflect.DeepEqual(got, want) || infos[0].OutfitCount != 3 {
+		t.Errorf("scanWardrobe() = %+v, want states %v and 3 casual outfits", infos, want)
+	}
+
+	nas, err := service.ScanCategories("/nas", nil)
+	if err != nil || len(nas) != 2 || nas[0].Category.Path != "/nas/casual" || nas[0].OutfitCount != 1 {
+		t.Errorf("ScanCategories(/nas) = %+v, %v; want casual and formal from /nas only", nas, err)
+	}
+
+	files, err := categoryOutfits(service, config, "casual")
+	if err != nil {
+		t.Fatalf("categoryOutfits() error = %v", err)
+	}
+	var keys []string
+	for _, file := range files {
+		keys = append(keys, file.Key())
+	}
+	if want := []string{"a.avatar", "b.avatar", "a.avatar@/nas"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("categoryOutfits() keys = %v, want %v", keys, want)
+	}
+}
+
+func TestSnapshotCategoryService_Failures(t *testing.T) {
+	service := NewSnapshotCategoryService(&mockConfigUseCase{loadResult: snapshotConfig(t)}, snapshotWardrobe())
+
+	if _, err := service.ScanCategories("/usb", nil); !stderrors.Is(err, domainerrors.ErrDirectoryNotFound) {
+		t.Errorf("ScanCategories() of another root error = %v, want ErrDirectoryNotFound", err)
+	}
+	if _, err := service.GetOutfits("/ssd/beach"); !stderrors.Is(err, domainerrors.ErrCategoryNotFound) {
+		t.Errorf("GetOutfits() of an unknown category error = %v, want ErrCategoryNotFound", err)
+	}
+	if _, err := NewSnapshotCategoryService(&mockConfigUseCase{}, snapshotWardrobe()).GetOutfits("/ssd/casual"); !stderrors.Is(err, domainerrors.ErrConfigurationNotFound) {
+		t.Errorf("GetOutfits() without a config error = %v, want ErrConfigurationNotFound", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
 )
 
 // Test error helper
@@ -35,6 +36,25 @@ func (m *mockConfigRepo) Save(config *entities.Config) error {
 	return m.saveError
 }
 
+func (m *mockConfigRepo) Replace(config *entities.Config) error {
+	return m.saveError
+}
+
+func (m *mockConfigRepo) Update(change func(current *entities.Config) (*entities.Config, error)) error {
+	if m.loadError != nil {
+		return m.loadError
+	}
+	updated, err := change(m.loadResult)
+	if err != nil || updated == nil {
+		return err
+	}
+	if m.saveError != nil {
+		return m.saveError
+	}
+	m.loadResult = updated
+	return nil
+}
+
 func (m *mockConfigRepo) Delete() error {
 	return m.deleteError
 }
@@ -54,6 +74,25 @@ func (m *mockCacheRepo) Save(cache *entities.OutfitCache) error {
 	return m.saveError
 }
 
+func (m *mockCacheRepo) Replace(cache *entities.OutfitCache) error {
+	return m.saveError
+}
+
+func (m *mockCacheRepo) Update(change func(current *entities.OutfitCache) (*entities.OutfitCache, error)) error {
+	if m.loadError != nil {
+		return m.loadError
+	}
+	updated, err := change(m.loadResult)
+	if err != nil || updated == nil {
+		return err
+	}
+	if m.saveError != nil {
+		return m.saveError
+	}
+	m.loadResult = updated
+	return nil
+}
+
 func (m *mockCacheRepo) Delete() error {
 	return m.deleteError
 }
@@ -64,6 +103,8 @@ type mockConfigUseCase struct {
 	loadError   error
 	saveError   error
 	deleteError error
+	saved       *entities.Config
+	replaced    bool
 }
 
 func (m *mockConfigUseCase) LoadOrCreate() (*entities.Config, error) {
@@ -71,9 +112,33 @@ func (m *mockConfigUseCase) LoadOrCreate() (*entities.Config, error) {
 }
 
 func (m *mockConfigUseCase) Save(config *entities.Config) error {
+	m.saved = config
 	return m.saveError
 }
 
+func (m *mockConfigUseCase) Replace(config *entities.Config) error {
+	m.replaced = true
+	return m.Save(config)
+}
+
+func (m *mockConfigUseCase) Update(change func(config *entities.Config) error) error {
+	if m.loadError != nil {
+		return m.loadError
+	}
+	if m.loadResult == nil {
+		return errors.ErrConfigurationNotFound
+	}
+	updated := *m.loadResult
+	if err := change(&updated); err != nil {
+		return err
+	}
+	if m.saveError != nil {
+		return m.saveError
+	}
+	m.loadResult = &updated
+	return nil
+}
+
 func (m *mockConfigUseCase) Delete() error {
 	return m.deleteError
 }
@@ -85,6 +150,8 @@ type mockCacheService struct {
 	saveErrors  []error
 	saveCalls   int
 	deleteError error
+	saved       *entities.OutfitCache
+	replaced    bool
 }
 
 func (m *mockCacheService) LoadOrCreate() (*entities.OutfitCache, error) {
@@ -92,6 +159,7 @@ func (m *mockCacheService) LoadOrCreate() (*entities.OutfitCache, error) {
 }
 
 func (m *mockCacheService) Save(cache *entities.OutfitCache) error {
+	m.saved = cache
 	if m.saveCalls < len(m.saveErrors) {
 		err := m.saveErrors[m.saveCalls]
 		m.saveCalls++
@@ -101,10 +169,99 @@ func (m *mockCacheService) Save(cache *entities.OutfitCache) error {
 	return m.saveError
 }
 
+func (m *mockCacheService) Replace(cache *entities.OutfitCache) error {
+	m.replaced = true
+	return m.Save(cache)
+}
+
+func (m *mockCacheService) Update(change func(cache *entities.OutfitCache) error) error {
+	if m.loadError != nil {
+		return m.loadError
+	}
+	updated := entities.NewOutfitCache()
+	if m.loadResult != nil {
+		updated = *m.loadResult
+	}
+	if err := change(&updated); err != nil {
+		return err
+	}
+	if err := m.Save(&updated); err != nil {
+		return err
+	}
+	m.loadResult = &updated
+	return nil
+}
+
 func (m *mockCacheService) Delete() error {
 	return m.deleteError
 }
 
+type mockJournal struct {
+	events []entities.JournalEvent
+	err    error
+}
+
+func (m *mockJournal) Events() ([]entities.JournalEvent, error) {
+	return m.events, m.err
+}
+
+type mockKnownWardrobe struct {
+	loadResult *entities.KnownWardrobe
+	loadError  error
+	saveError  error
+	saves      int
+}
+
+func knownWardrobe(outfits map[string][]string) *mockKnownWardrobe {
+	recorded := entities.NewKnownWardrobe(outfits, nil)
+	return &mockKnownWardrobe{loadResult: &recorded}
+}
+
+func (m *mockKnownWardrobe) Load() (*entities.KnownWardrobe, error) {
+	return m.loadResult, m.loadError
+}
+
+func (m *mockKnownWardrobe) Update(change func(current *entities.KnownWardrobe) (*entities.KnownWardrobe, error)) error {
+	if m.loadError != nil {
+		return m.loadError
+	}
+	updated, err := change(m.loadResult)
+	if err != nil || updated == nil {
+		return err
+	}
+	if m.saveError != nil {
+		return m.saveError
+	}
+	m.loadResult = updated
+	m.saves++
+	return nil
+}
+
+type mockWearQueue struct {
+	loadResult *entities.WearQueue
+	loadError  error
+	saveError  error
+}
+
+func (m *mockWearQueue) Load() (*entities.WearQueue, error) {
+	return m.loadResult, m.loadError
+}
+
+func (m *mockWearQueue) Update(change func(current *entities.WearQueue) (*entities.WearQueue, error)) error {
+	if m.loadError != nil {
+		return m.loadError
+	}
+	updated, err := change(m.loadResult)
+	if err != nil || updated == nil {
+		return err
+	}
+	if m.saveError != nil {
+		return m.saveError
+	}
+	m.loadResult = updated
+	return nil
+}
+
 // Mock services
 type mockCategoryService struct {
 	scanResult             []entities.CategoryInfo
@@ -132,6 +289,25 @@ func (m *mockCategoryService) GetOutfits(categoryPath string) ([]entities.FileEn
 	return m.outfitsResult, m.outfitsError
 }
 
+type mockOutfitInstaller struct {
+	installError  error
+	restoreError  error
+	installSource string
+	installSlot   entities.ActivationSlot
+	restoreSlot   entities.ActivationSlot
+}
+
+func (m *mockOutfitInstaller) Install(sourcePath string, slot entities.ActivationSlot) error {
+	m.installSource = sourcePath
+	m.installSlot = slot
+	return m.installError
+}
+
+func (m *mockOutfitInstaller) Restore(slot entities.ActivationSlot) error {
+	m.restoreSlot = slot
+	return m.restoreError
+}
+
 // Test assertion helpers
 func assertError(t *testing.T, wantErr bool, err error) {
 	t.Helper()
@@ -173,3 +349,93 @@ func isNil(i interface{}) bool {
 		return false
 	}
 }
+
+type mockBackupRepository struct {
+	created     []entities.BackupContents
+	createError error
+	backups     []entities.BackupInfo
+	listError   error
+	opened      entities.BackupContents
+	openError   error
+	deleted     []entities.BackupInfo
+	deleteError error
+}
+
+func (m *mockBackupRepository) Create(kind entities.BackupKind, reason string, contents entities.BackupContents, path string) (entities.BackupInfo, error) {
+	if m.createError != nil {
+		return entities.BackupInfo{}, m.createError
+	}
+	m.created = append(m.created, contents)
+	return entities.BackupInfo{Name: string(kind) + "-" + reason, Path: path, Kind: kind, Reason: reason}, nil
+}
+
+func (m *mockBackupRepository) List() ([]entities.BackupInfo, error) {
+	return m.backups, m.listError
+}
+
+func (m *mockBackupRepository) Open(name string) (entities.BackupInfo, entities.BackupContents, error) {
+	if m.openError != nil {
+		return entities.BackupInfo{}, entities.BackupContents{}, m.openError
+	}
+	return entities.BackupInfo{Name: name, Kind: entities.BackupManual}, m.opened, nil
+}
+
+func (m *mockBackupRepository) Delete(backup entities.BackupInfo) error {
+	if m.deleteError != nil {
+		return m.deleteError
+	}
+	m.deleted = append(m.deleted, backup)
+	return nil
+}
+
+type mockProfileRepository struct {
+	profiles    map[string]bool
+	copied      [][2]string
+	deleted     []string
+	createError error
+	listError   error
+}
+
+func (m *mockProfileRepository) profile(name string) entities.Profile {
+	return entities.Profile{Name: name, Dir: "/config/outfitpicker/profiles/" + name}
+}
+
+func (m *mockProfileRepository) List() ([]entities.Profile, error) {
+	if m.listError != nil {
+		return nil, m.listError
+	}
+	profiles := []entities.Profile{m.profile(entities.DefaultProfile)}
+	for name := range m.profiles {
+		profiles = append(profiles, m.profile(name))
+	}
+	return profiles, nil
+}
+
+func (m *mockProfileRepository) Get(name string) (entities.Profile, error) {
+	if !entities.IsDefaultProfile(name) && !m.profiles[name] {
+		return entities.Profile{}, errors.NewProfileNotFoundError(name)
+	}
+	return m.profile(name), nil
+}
+
+func (m *mockProfileRepository) Create(name string) (entities.Profile, error) {
+	if m.createError != nil {
+		return entities.Profile{}, m.createError
+	}
+	if m.profiles == nil {
+		m.profiles = make(map[string]bool)
+	}
+	m.profiles[name] = true
+	return m.profile(name), nil
+}
+
+func (m *mockProfileRepository) Delete(name string) error {
+	m.deleted = append(m.deleted, name)
+	delete(m.profiles, name)
+	return nil
+}
+
+func (m *mockProfileRepository) Copy(from, to string) (entities.Profile, error) {
+	m.copied = append(m.copied, [2]string{from, to})
+	return m.Create(to)
+}
@@ -0,0 +1,98 @@
+package usecases
+
+import (
+	"reflect"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+	"github.com/dh85/outfitpicker/internal/domain/logic"
+)
+
+// WardrobeChangesUseCase finds the categories and outfits added to or
+// removed from the wardrobe since the last run, and records the wardrobe for
+// the next one.
+type WardrobeChangesUseCase struct {
+	configManager   ConfigManager
+	cacheManager    CacheManager
+	categoryService interfaces.CategoryService
+	known           interfaces.KnownWardrobeRepository
+}
+
+// NewWardrobeChangesUseCase records the wardrobe in known. Without it,
+// nothing is recorded and no changes are found.
+func NewWardrobeChangesUseCase(configManager ConfigManager, cacheManager CacheManager, categoryService interfaces.CategoryService, known interfaces.KnownWardrobeRepository) *WardrobeChangesUseCase {
+	return &WardrobeChangesUseCase{configManager, cacheManager, categoryService, known}
+}
+
+// Execute returns what changed since the wardrobe was last recorded and
+// records it as it is now, adding new outfits to the new arrivals. The first
+// run only records the wardrobe, so nothing is reported as new.
+func (uc *WardrobeChangesUseCase) Execute() (entities.WardrobeChanges, error) {
+	var result entities.WardrobeChanges
+	if uc.known == nil {
+		return result, nil
+	}
+	config, err := loadConfig(uc.configManager)
+	if err != nil {
+		return result, err
+	}
+	outfits, unreadable := wardrobeOutfitKeys(uc.categoryService, config)
+	if result.Unreadable = unreadable; len(unreadable) > 0 {
+		return result, nil
+	}
+	cache, err := uc.cacheManager.LoadOrCreate()
+	if err != nil {
+		return result, err
+	}
+	recorded, err := loadKnownWardrobe(uc.known)
+	if err != nil {
+		return result, err
+	}
+	if !needsRecording(recorded, outfits, *cache) {
+		return result, nil
+	}
+
+	err = uc.known.Update(func(current *entities.KnownWardrobe) (*entities.KnownWardrobe, error) {
+		var previous entities.KnownWardrobe
+		if current != nil {
+			previous = *current
+		}
+		var arrivals map[string]map[string]bool
+		if previous.Recorded() {
+			result.Categories = logic.DiffWardrobe(previous, outfits)
+			arrivals = logic.TrackNewArrivals(previous.NewArrivals, result.Categories, outfits, *cache)
+		}
+		next := entities.NewKnownWardrobe(outfits, arrivals)
+		return &next, nil
+	})
+	if err != nil {
+		return entities.WardrobeChanges{}, err
+	}
+	return result, nil
+}
+
+// Forget drops the recorded wardrobe, so that the next run records another
+// wardrobe afresh instead of announcing everything in it as new.
+func (uc *WardrobeChangesUseCase) Forget() error {
+	if uc.known == nil {
+		return nil
+	}
+	return uc.known.Update(func(current *entities.KnownWardrobe) (*entities.KnownWardrobe, error) {
+		if current == nil || !current.Recorded() {
+			return nil, nil
+		}
+		return &entities.KnownWardrobe{}, nil
+	})
+}
+
+// needsRecording reports whether known records a different wardrobe than
+// outfits, or new arrivals that were worn or removed since.
+func needsRecording(known entities.KnownWardrobe, outfits map[string][]string, cache entities.OutfitCache) bool {
+	if !known.Recorded() {
+		return len(outfits) > 0
+	}
+	if len(logic.DiffWardrobe(known, outfits)) > 0 {
+		return true
+	}
+	return !reflect.DeepEqual(logic.TrackNewArrivals(known.NewArrivals, nil, outfits, cache), known.NewArrivals)
+}
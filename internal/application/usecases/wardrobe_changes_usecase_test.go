@@ -0,0 +1,161 @@
+package usecases
+
+import (
+	stderrors "errors"
+	"reflect"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+func changedWardrobe() *rootedCategoryService {
+	info := func(name string, count int) entities.CategoryInfo {
+		return entities.NewCategoryInfo(entities.NewCategoryReference(name, "/ssd/"+name), entities.CategoryStateHasOutfits, count)
+	}
+	return &rootedCategoryService{
+		scans: map[string][]entities.CategoryInfo{"/ssd": {info("beach", 1), info("casual", 2)}},
+		outfits: map[string][]entities.FileEntry{
+			"/ssd/beach":  {{FileName: "towel.avatar"}},
+			"/ssd/casual": {{FileName: "kept.avatar"}, {FileName: "new.avatar"}},
+		},
+	}
+}
+
+func emptyCache() *entities.OutfitCache {
+	cache := entities.NewOutfitCache()
+	return &cache
+}
+
+func TestWardrobeChangesUseCase_FirstRunOnlyRecords(t *testing.T) {
+	configManager := &mockConfigUseCase{loadResult: multiRootConfig(t, "/ssd")}
+	known := &mockKnownWardrobe{}
+	uc := NewWardrobeChangesUseCase(configManager, &mockCacheService{loadResult: emptyCache()}, changedWardrobe(), known)
+
+	changes, err := uc.Execute()
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if changes.Changed() {
+		t.Errorf("Execute() = %+v, want nothing reported on the first run", changes)
+	}
+	recorded := known.loadResult
+	if recorded == nil || !recorded.HasCategory("beach") || !recorded.HasOutfit("casual", "new.avatar") || recorded.NewArrivals != nil {
+		t.Errorf("recorded wardrobe = %+v, want the wardrobe known with no new arrivals", recorded)
+	}
+}
+
+func TestWardrobeChangesUseCase_ReportsChangesAndTracksArrivals(t *testing.T) {
+	configManager := &mockConfigUseCase{loadResult: multiRootConfig(t, "/ssd")}
+	known := knownWardrobe(map[string][]string{
+		"casual": {"kept.avatar", "gone.avatar"},
+		"hats":   {"cap.avatar"},
+	})
+	cache := entities.NewOutfitCache()
+	cacheManager := &mockCacheService{loadResult: &cache}
+	uc := NewWardrobeChangesUseCase(configManager, cacheManager, changedWardrobe(), known)
+
+	changes, err := uc.Execute()
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	want := []entities.CategoryChanges{
+		{Category: "beach", Added: true, AddedOutfits: []string{"towel.avatar"}},
+		{Category: "casual", AddedOutfits: []string{"new.avatar"}, RemovedOutfits: []string{"gone.avatar"}},
+		{Category: "hats", Removed: true, RemovedOutfits: []string{"cap.avatar"}},
+	}
+	if !reflect.DeepEqual(changes.Categories, want) {
+		t.Fatalf("Execute() = %+v, want %+v", changes.Categories, want)
+	}
+	wantArrivals := map[string]map[string]bool{"beach": {"towel.avatar": true}, "casual": {"new.avatar": true}}
+	if recorded := known.loadResult; !reflect.DeepEqual(recorded.NewArrivals, wantArrivals) || recorded.HasCategory("hats") {
+		t.Fatalf("recorded wardrobe = %+v, want new arrivals %v and hats forgotten", recorded, wantArrivals)
+	}
+
+	again, err := uc.Execute()
+	if err != nil || again.Changed() || known.saves != 1 {
+		t.Fatalf("second Execute() = %+v, %v; want no changes and nothing saved", again, err)
+	}
+
+	cache = cache.Updating("casual", entities.NewCategoryCache(2).Adding("new.avatar"))
+	if _, err := uc.Execute(); err != nil {
+		t.Fatalf("Execute() after wearing error = %v", err)
+	}
+	if arrivals := known.loadResult.NewArrivals; !reflect.DeepEqual(arrivals, map[string]map[string]bool{"beach": {"towel.avatar": true}}) {
+		t.Errorf("new arrivals after wearing new.avatar = %v", arrivals)
+	}
+}
+
+func TestWardrobeChangesUseCase_WaitsForUnreadableWardrobe(t *testing.T) {
+	service := changedWardrobe()
+	delete(service.outfits, "/ssd/beach")
+	known := knownWardrobe(map[string][]string{"casual": {"kept.avatar"}})
+	uc := NewWardrobeChangesUseCase(&mockConfigUseCase{loadResult: multiRootConfig(t, "/ssd")}, &mockCacheService{loadResult: emptyCache()}, service, known)
+
+	changes, err := uc.Execute()
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if changes.Changed() || !reflect.DeepEqual(changes.Unreadable, []string{"/ssd/beach"}) {
+		t.Errorf("Execute() = %+v, want only /ssd/beach unreadable", changes)
+	}
+	if known.saves != 0 {
+		t.Error("the wardrobe was recorded while part of it could not be read")
+	}
+}
+
+func TestWardrobeChangesUseCase_Forget(t *testing.T) {
+	known := knownWardrobe(map[string][]string{"casual": {"kept.avatar"}})
+	uc := NewWardrobeChangesUseCase(&mockConfigUseCase{loadResult: multiRootConfig(t, "/ssd")}, &mockCacheService{loadResult: emptyCache()}, changedWardrobe(), known)
+
+	if err := uc.Forget(); err != nil || known.loadResult.Recorded() {
+		t.Fatalf("Forget() = %v, recorded %+v; want the wardrobe forgotten", err, known.loadResult)
+	}
+	if err := uc.Forget(); err != nil || known.saves != 1 {
+		t.Errorf("second Forget() = %v after %d saves; want nothing saved again", err, known.saves)
+	}
+	changes, err := uc.Execute()
+	if err != nil || changes.Changed() || !known.loadResult.HasCategory("beach") {
+		t.Errorf("Execute() after Forget() = %+v, %v; want the wardrobe recorded afresh", changes, err)
+	}
+}
+
+func TestWardrobeChangesUseCase_WithoutRecording(t *testing.T) {
+	uc := NewWardrobeChangesUseCase(&mockConfigUseCase{}, &mockCacheService{loadError: assert.AnError}, changedWardrobe(), nil)
+	if changes, err := uc.Execute(); err != nil || changes.Changed() {
+		t.Errorf("Execute() = %+v, %v; want nothing", changes, err)
+	}
+	if err := uc.Forget(); err != nil {
+		t.Errorf("Forget() error = %v", err)
+	}
+}
+
+func TestWardrobeChangesUseCase_Errors(t *testing.T) {
+	t.Run("no configuration", func(t *testing.T) {
+		uc := NewWardrobeChangesUseCase(&mockConfigUseCase{}, &mockCacheService{loadResult: emptyCache()}, changedWardrobe(), &mockKnownWardrobe{})
+		if _, err := uc.Execute(); !stderrors.Is(err, domainerrors.ErrConfigurationNotFound) {
+			t.Errorf("Execute() error = %v, want ErrConfigurationNotFound", err)
+		}
+	})
+
+	t.Run("cache", func(t *testing.T) {
+		uc := NewWardrobeChangesUseCase(&mockConfigUseCase{loadResult: multiRootConfig(t, "/ssd")}, &mockCacheService{loadError: assert.AnError}, changedWardrobe(), &mockKnownWardrobe{})
+		if _, err := uc.Execute(); !stderrors.Is(err, assert.AnError) {
+			t.Errorf("Execute() error = %v, want %v", err, assert.AnError)
+		}
+	})
+
+	t.Run("recording", func(t *testing.T) {
+		uc := NewWardrobeChangesUseCase(&mockConfigUseCase{loadResult: multiRootConfig(t, "/ssd")}, &mockCacheService{loadResult: emptyCache()}, changedWardrobe(), &mockKnownWardrobe{loadError: assert.AnError})
+		if _, err := uc.Execute(); !stderrors.Is(err, assert.AnError) {
+			t.Errorf("Execute() error = %v, want %v", err, assert.AnError)
+		}
+	})
+
+	t.Run("save", func(t *testing.T) {
+		uc := NewWardrobeChangesUseCase(&mockConfigUseCase{loadResult: multiRootConfig(t, "/ssd")}, &mockCacheService{loadResult: emptyCache()}, changedWardrobe(), &mockKnownWardrobe{saveError: assert.AnError})
+		if _, err := uc.Execute(); !stderrors.Is(err, assert.AnError) {
+			t.Errorf("Execute() error = %v, want %v", err, assert.AnError)
+		}
+	})
+}
@@ -62,6 +62,17 @@ func (q *WardrobeQueries) GetRootDirectory() (string, error) {
 	return config.Root, nil
 }
 
+// GetRootStatuses scans each wardrobe root on its own, reporting what it
+// holds or why it could not be scanned.
+func (q *WardrobeQueries) GetRootStatuses() ([]entities.RootStatus, error) {
+	config, err := q.GetConfiguration()
+	if err != nil {
+		return nil, err
+	}
+	_, statuses := scanRoots(q.categorySvc, config)
+	return statuses, nil
+}
+
 func (q *WardrobeQueries) GetOutfitState(category entities.CategoryReference) (entities.CategoryOutfitState, error) {
 	config, err := q.GetConfiguration()
 	if err != nil {
@@ -72,13 +83,21 @@ func (q *WardrobeQueries) GetOutfitState(category entities.CategoryReference) (e
 	if err != nil {
 		return entities.CategoryOutfitState{}, err
 	}
+	return q.outfitState(config, cache, category)
+}
 
-	categoryPath := filepath.Join(config.Root, category.Name)
-	files, err := q.categorySvc.GetOutfits(categoryPath)
+// outfitState lists the outfits of category with their worn state in cache,
+// so that several categories can share one load of config and cache.
+func (q *WardrobeQueries) outfitState(config *entities.Config, cache *entities.OutfitCache, category entities.CategoryReference) (entities.CategoryOutfitState, error) {
+	files, err := categoryOutfits(q.categorySvc, config, category.Name)
 	if err != nil {
 		return entities.CategoryOutfitState{}, err
 	}
 
+	categoryPath := filepath.Join(config.Root, category.Name)
+	if len(files) > 0 {
+		categoryPath = files[0].CategoryPath()
+	}
 	categoryRef := entities.NewCategoryReference(category.Name, categoryPath)
 	categoryCache, ok := cache.Categories[category.Name]
 	if !ok {
@@ -89,27 +108,38 @@ func (q *WardrobeQueries) GetOutfitState(category entities.CategoryReference) (e
 	wornOutfits := make([]entities.OutfitReference, 0, len(files))
 	availableOutfits := make([]entities.OutfitReference, 0, len(files))
 	for _, file := range files {
-		outfit := entities.NewOutfitReference(file.FileName, categoryRef)
+		outfit := entities.NewOutfitReferenceFromFile(category.Name, file)
 		allOutfits = append(allOutfits, outfit)
-		if categoryCache.WornOutfits[file.FileName] {
+		if categoryCache.WornOutfits[file.Key()] {
 			wornOutfits = append(wornOutfits, outfit)
 			continue
 		}
 		availableOutfits = append(availableOutfits, outfit)
 	}
 
-	return entities.NewCategoryOutfitState(categoryRef, allOutfits, availableOutfits, wornOutfits), nil
+	state := entities.NewCategoryOutfitState(categoryRef, allOutfits, availableOutfits, wornOutfits)
+	return state.WithWornRevision(categoryCache.WornRevision()), nil
 }
 
+// GetAllOutfitStates lists the outfit state of every category with outfits,
+// loading config and cache once for all of them.
 func (q *WardrobeQueries) GetAllOutfitStates() (map[string]entities.CategoryOutfitState, error) {
 	categories, err := q.GetCategories()
 	if err != nil {
 		return nil, err
 	}
+	config, err := q.GetConfiguration()
+	if err != nil {
+		return nil, err
+	}
+	cache, err := q.cacheManager.LoadOrCreate()
+	if err != nil {
+		return nil, err
+	}
 
 	states := make(map[string]entities.CategoryOutfitState, len(categories))
 	for _, category := range categories {
-		state, err := q.GetOutfitState(category)
+		state, err := q.outfitState(config, cache, category)
 		if err != nil {
 			return nil, err
 		}
@@ -132,16 +162,14 @@ func (q *WardrobeQueries) ShowAllOutfits(categoryName string) ([]entities.Outfit
 		return nil, err
 	}
 
-	categoryPath := filepath.Join(config.Root, categoryName)
-	files, err := q.categorySvc.GetOutfits(categoryPath)
+	files, err := categoryOutfits(q.categorySvc, config, categoryName)
 	if err != nil {
 		return nil, err
 	}
 
-	category := entities.NewCategoryReference(categoryName, categoryPath)
 	outfits := make([]entities.OutfitReference, 0, len(files))
 	for _, file := range files {
-		outfits = append(outfits, entities.NewOutfitReference(file.FileName, category))
+		outfits = append(outfits, entities.NewOutfitReferenceFromFile(categoryName, file))
 	}
 	return outfits, nil
 }
@@ -157,7 +157,7 @@ func TestWardrobeQueries_GetOutfitState(t *testing.T) {
 			outfitsByPath: map[string][]entities.FileEntry{
 				wardrobeCategoryPath("casual"): {
 					{FileName: "jeans.avatar"},
-					{FileName: "shirt.avatar"},
+					{FileName: "shirt.avatar", PreviewFileName: "shirt.png"},
 					{FileName: "boots.avatar"},
 				},
 			},
@@ -177,9 +177,15 @@ func TestWardrobeQueries_GetOutfitState(t *testing.T) {
 		if got := outfitNames(state.WornOutfits); !reflect.DeepEqual(got, []string{"jeans.avatar"}) {
 			t.Fatalf("worn outfits = %v", got)
 		}
+		if state.WornRevision != cache.Categories["casual"].WornRevision() {
+			t.Fatalf("WornRevision = %q, want the casual category's", state.WornRevision)
+		}
 		if got := outfitNames(state.AvailableOutfits); !reflect.DeepEqual(got, []string{"shirt.avatar", "boots.avatar"}) {
 			t.Fatalf("available outfits = %v", got)
 		}
+		if state.AvailableOutfits[0].PreviewFileName != "shirt.png" || state.AvailableOutfits[1].PreviewFileName != "" {
+			t.Fatalf("available outfit previews = %#v", state.AvailableOutfits)
+		}
 		if !reflect.DeepEqual(service.outfitPaths, []string{wardrobeCategoryPath("casual")}) {
 			t.Fatalf("GetOutfits paths = %v", service.outfitPaths)
 		}
@@ -474,7 +480,7 @@ func newWardrobeQueries(config *entities.Config, cache entities.OutfitCache, ser
 
 func mustWardrobeConfig(t *testing.T, excluded map[string]bool) *entities.Config {
 	t.Helper()
-	config, err := entities.NewConfig(wardrobeRoot, nil, excluded, nil, nil)
+	config, err := entities.NewConfig(wardrobeRoot, nil, excluded)
 	if err != nil {
 		t.Fatalf("NewConfig() error = %v", err)
 	}
@@ -0,0 +1,183 @@
+package usecases
+
+import (
+	"path/filepath"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+	"github.com/dh85/outfitpicker/internal/domain/logic"
+)
+
+// followingSymlinks returns categoryService treating symbolic links as config
+// says, when it can follow them.
+func followingSymlinks(categoryService interfaces.CategoryService, config *entities.Config) interfaces.CategoryService {
+	if follower, ok := categoryService.(interfaces.SymlinkFollower); ok {
+		return follower.FollowingSymlinks(config.SymlinkPolicy())
+	}
+	return categoryService
+}
+
+// scanRoots scans each wardrobe root in config on its own.
+func scanRoots(categoryService interfaces.CategoryService, config *entities.Config) ([][]entities.CategoryInfo, []entities.RootStatus) {
+	categoryService = followingSymlinks(categoryService, config)
+	roots := config.WardrobeRoots()
+	infos := make([][]entities.CategoryInfo, len(roots))
+	statuses := make([]entities.RootStatus, len(roots))
+	for index, root := range roots {
+		statuses[index].Root = root
+		infos[index], statuses[index].Err = categoryService.ScanCategories(root, config.ExcludedCategories)
+		statuses[index].Categories = len(infos[index])
+		for _, info := range infos[index] {
+			statuses[index].Outfits += info.OutfitCount
+		}
+	}
+	return infos, statuses
+}
+
+// scanWardrobe lists the categories of every wardrobe root in config, merging
+// categories of the same name. A root that cannot be scanned is left out, so
+// an unmounted root does not hide the others; the scan only fails when no
+// root can be scanned.
+func scanWardrobe(categoryService interfaces.CategoryService, config *entities.Config) ([]entities.CategoryInfo, error) {
+	categoryService = followingSymlinks(categoryService, config)
+	if len(config.WardrobeRoots()) == 1 {
+		return categoryService.ScanCategories(config.Root, config.ExcludedCategories)
+	}
+	infos, statuses := scanRoots(categoryService, config)
+	scanned := make([][]entities.CategoryInfo, 0, len(infos))
+	for index, status := range statuses {
+		if status.Err == nil {
+			scanned = append(scanned, infos[index])
+		}
+	}
+	if len(scanned) == 0 {
+		return nil, statuses[0].Err
+	}
+	return logic.MergeCategoryInfos(scanned...), nil
+}
+
+// categoryOutfits lists the outfits in the category named categoryName across
+// every wardrobe root in config. As with scanWardrobe, a root without the
+// category, or that cannot be read, adds nothing unless none can be read.
+func categoryOutfits(categoryService interfaces.CategoryService, config *entities.Config, categoryName string) ([]entities.FileEntry, error) {
+	categoryService = followingSymlinks(categoryService, config)
+	var outfits []entities.FileEntry
+	var firstErr error
+	read := 0
+	for index, root := range config.WardrobeRoots() {
+		categoryPath := filepath.Join(root, categoryName)
+		files, err := categoryService.GetOutfits(categoryPath)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		read++
+		origin := ""
+		if index > 0 {
+			origin = root
+		}
+		for _, file := range files {
+			outfits = append(outfits, file.InCategory(categoryPath, origin))
+		}
+	}
+	if read == 0 {
+		return nil, firstErr
+	}
+	return outfits, nil
+}
+
+// wardrobeOutfitKeys lists the outfit keys of every category by name across
+// the wardrobe roots in config, including excluded categories. It also
+// returns the roots and categories that could not be read; while there are
+// any, the listing is incomplete and should not be relied on.
+func wardrobeOutfitKeys(categoryService interfaces.CategoryService, config *entities.Config) (map[string][]string, []string) {
+	files, unreadable := wardrobeOutfits(categoryService, config)
+	if len(unreadable) > 0 {
+		return nil, unreadable
+	}
+	outfits := make(map[string][]string, len(files))
+	for name, entries := range files {
+		keys := make([]string, 0, len(entries))
+		for _, file := range entries {
+			keys = append(keys, file.Key())
+		}
+		outfits[name] = keys
+	}
+	return outfits, nil
+}
+
+// wardrobeOutfits lists the outfits of every category by name across the
+// wardrobe roots in config, like wardrobeOutfitKeys, returning the roots and
+// categories that could not be read in place of an incomplete listing.
+func wardrobeOutfits(categoryService interfaces.CategoryService, config *entities.Config) (map[string][]entities.FileEntry, []string) {
+	var unreadable []string
+	infos, statuses := scanRoots(categoryService, config)
+	for index, status := range statuses {
+		if status.Err != nil {
+			unreadable = append(unreadable, status.Root)
+			continue
+		}
+		for _, info := range infos[index] {
+			if info.State.Failed() {
+				unreadable = append(unreadable, info.Category.Path)
+			}
+		}
+	}
+	if len(unreadable) > 0 {
+		return nil, unreadable
+	}
+
+	outfits := make(map[string][]entities.FileEntry)
+	for _, info := range logic.MergeCategoryInfos(infos...) {
+		files, err := categoryOutfits(categoryService, config, info.Category.Name)
+		if err != nil {
+			unreadable = append(unreadable, info.Category.Path)
+			continue
+		}
+		outfits[info.Category.Name] = files
+	}
+	if len(unreadable) > 0 {
+		return nil, unreadable
+	}
+	return outfits, nil
+}
+
+// loadConfig loads the configuration, failing with ErrConfigurationNotFound
+// when nothing has been configured yet.
+func loadConfig(configManager ConfigManager) (*entities.Config, error) {
+	config, err := configManager.LoadOrCreate()
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		return nil, errors.ErrConfigurationNotFound
+	}
+	return config, nil
+}
+
+// loadKnownWardrobe returns the wardrobe recorded by the last run that could
+// read all of it, which is empty when nothing was recorded or there is
+// nowhere to record it.
+func loadKnownWardrobe(known interfaces.KnownWardrobeRepository) (entities.KnownWardrobe, error) {
+	if known == nil {
+		return entities.KnownWardrobe{}, nil
+	}
+	recorded, err := known.Load()
+	if err != nil || recorded == nil {
+		return entities.KnownWardrobe{}, err
+	}
+	return *recorded, nil
+}
+
+// findOutfit returns the file among files that outfit refers to.
+func findOutfit(files []entities.FileEntry, outfit entities.OutfitReference) (entities.FileEntry, bool) {
+	for _, file := range files {
+		if file.Key() == outfit.Key() {
+			return file, true
+		}
+	}
+	return entities.FileEntry{}, false
+}
@@ -0,0 +1,200 @@
+package usecases
+
+import (
+	stderrors "errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+	"github.com/dh85/outfitpicker/internal/domain/validation"
+)
+
+// rootedCategoryService answers scans and outfit listings per path, failing
+// for paths it does not know, like a missing directory.
+type rootedCategoryService struct {
+	scans   map[string][]entities.CategoryInfo
+	outfits map[string][]entities.FileEntry
+}
+
+func (s *rootedCategoryService) ScanCategories(rootPath string, _ map[string]bool) ([]entities.CategoryInfo, error) {
+	infos, ok := s.scans[rootPath]
+	if !ok {
+		return nil, assert.AnError
+	}
+	return infos, nil
+}
+
+func (s *rootedCategoryService) GetOutfits(categoryPath string) ([]entities.FileEntry, error) {
+	files, ok := s.outfits[categoryPath]
+	if !ok {
+		return nil, assert.AnError
+	}
+	return files, nil
+}
+
+func multiRootConfig(t *testing.T, roots ...string) *entities.Config {
+	t.Helper()
+	config, err := entities.NewConfig(roots[0], nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err = config.WithRoots(roots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func newRootedCategoryService() *rootedCategoryService {
+	info := func(root, name string, state entities.CategoryState, count int) entities.CategoryInfo {
+		return entities.NewCategoryInfo(entities.NewCategoryReference(name, filepath.Join(root, name)), state, count)
+	}
+	return &rootedCategoryService{
+		scans: map[string][]entities.CategoryInfo{
+			"/ssd": {info("/ssd", "casual", entities.CategoryStateHasOutfits, 2), info("/ssd", "formal", entities.CategoryStateEmpty, 0)},
+			"/nas": {info("/nas", "casual", entities.CategoryStateHasOutfits, 1), info("/nas", "formal", entities.CategoryStateHasOutfits, 1)},
+		},
+		outfits: map[string][]entities.FileEntry{
+			"/ssd/casual": {{FileName: "a.avatar"}, {FileName: "b.avatar"}},
+			"/nas/casual": {{FileName: "a.avatar"}},
+			"/nas/formal": {{FileName: "suit.avatar"}},
+		},
+	}
+}
+
+func TestScanWardrobe_MergesRootsAndSkipsMissingOnes(t *testing.T) {
+	service := newRootedCategoryService()
+
+	infos, err := scanWardrobe(service, multiRootConfig(t, "/ssd", "/nas", "/unmounted"))
+	if err != nil {
+		t.Fatalf("scanWardrobe() error = %v", err)
+	}
+	want := []entities.CategoryInfo{
+		entities.NewCategoryInfo(entities.NewCategoryReference("casual", "/ssd/casual"), entities.CategoryStateHasOutfits, 3),
+		entities.NewCategoryInfo(entities.NewCategoryReference("formal", "/ssd/formal"), entities.CategoryStateHasOutfits, 1),
+	}
+	if !reflect.DeepEqual(infos, want) {
+		t.Fatalf("scanWardrobe() = %+v, want %+v", infos, want)
+	}
+
+	if _, err := scanWardrobe(service, multiRootConfig(t, "/unmounted", "/gone")); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("scanWardrobe() with no readable root error = %v, want %v", err, assert.AnError)
+	}
+	if _, err := scanWardrobe(service, multiRootConfig(t, "/unmounted")); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("scanWardrobe() with one missing root error = %v, want %v", err, assert.AnError)
+	}
+}
+
+func TestCategoryOutfits_KeysOutfitsByRoot(t *testing.T) {
+	service := newRootedCategoryService()
+
+	files, err := categoryOutfits(service, multiRootConfig(t, "/ssd", "/nas", "/unmounted"), "casual")
+	if err != nil {
+		t.Fatalf("categoryOutfits() error = %v", err)
+	}
+	var keys, paths []string
+	for _, file := range files {
+		keys = append(keys, file.Key())
+		paths = append(paths, file.CategoryPath())
+	}
+	if want := []string{"a.avatar", "b.avatar", "a.avatar@/nas"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("keys = %v, want %v", keys, want)
+	}
+	if want := []string{"/ssd/casual", "/ssd/casual", "/nas/casual"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("category paths = %v, want %v", paths, want)
+	}
+
+	if files, err := categoryOutfits(service, multiRootConfig(t, "/ssd", "/nas"), "formal"); err != nil || len(files) != 1 || files[0].Key() != "suit.avatar@/nas" {
+		t.Fatalf("categoryOutfits(formal) = %+v, %v; want the suit from the second root only", files, err)
+	}
+	if _, err := categoryOutfits(service, multiRootConfig(t, "/ssd", "/nas"), "hats"); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("categoryOutfits(hats) error = %v, want %v", err, assert.AnError)
+	}
+}
+
+func TestWardrobeQueries_AcrossRoots(t *testing.T) {
+	service := newRootedCategoryService()
+	config := multiRootConfig(t, "/ssd", "/nas", "/unmounted")
+	cache := entities.NewOutfitCache().Updating("casual", entities.NewCategoryCache(3).Adding("a.avatar@/nas"))
+	queries := NewWardrobeQueries(&mockConfigUseCase{loadResult: config}, &mockCacheService{loadResult: &cache}, service)
+
+	state, err := queries.GetOutfitState(entities.NewCategoryReference("casual", "/ssd/casual"))
+	if err != nil {
+		t.Fatalf("GetOutfitState() error = %v", err)
+	}
+	if len(state.AllOutfits) != 3 || len(state.WornOutfits) != 1 || state.WornOutfits[0].Root != "/nas" || state.WornOutfits[0].FilePath() != "/nas/casual/a.avatar" {
+		t.Fatalf("GetOutfitState() = %+v, want the second root's a.avatar worn", state)
+	}
+	if available := state.AvailableOutfits; len(available) != 2 || available[0].Key() != "a.avatar" {
+		t.Fatalf("available = %+v, want the first root's a.avatar still available", available)
+	}
+
+	statuses, err := queries.GetRootStatuses()
+	if err != nil || len(statuses) != 3 {
+		t.Fatalf("GetRootStatuses() = %+v, %v; want three roots", statuses, err)
+	}
+	if statuses[0] != (entities.RootStatus{Root: "/ssd", Categories: 2, Outfits: 2}) || statuses[1].Outfits != 2 || !stderrors.Is(statuses[2].Err, assert.AnError) {
+		t.Fatalf("GetRootStatuses() = %+v, want each root's counts and the unmounted one's error", statuses)
+	}
+	if _, err := NewWardrobeQueries(&mockConfigUseCase{}, nil, service).GetRootStatuses(); !stderrors.Is(err, domainerrors.ErrConfigurationNotFound) {
+		t.Fatalf("GetRootStatuses() without config error = %v", err)
+	}
+}
+
+func TestWearAndActivate_AcrossRoots(t *testing.T) {
+	service := newRootedCategoryService()
+	config := multiRootConfig(t, "/ssd", "/nas")
+	fromNAS := entities.NewOutfitReferenceFromFile("casual", entities.FileEntry{FileName: "a.avatar"}.InCategory("/nas/casual", "/nas"))
+
+	cacheManager := &mockCacheService{}
+	if err := NewWearOutfitUseCase(service, &mockConfigUseCase{loadResult: config}, cacheManager).Execute(fromNAS); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if worn := cacheManager.saved.Categories["casual"].WornOutfits; !worn["a.avatar@/nas"] || worn["a.avatar"] {
+		t.Fatalf("worn = %v, want only the second root's a.avatar", worn)
+	}
+
+	slot := entities.ActivationSlot{Target: "/test/game/current.avatar", Mode: entities.ActivationModeCopy}
+	config, _ = config.WithSlot("game", slot)
+	installer := &mockOutfitInstaller{}
+	if _, err := NewActivateOutfitUseCase(service, &mockConfigUseCase{loadResult: config}, installer).Execute(fromNAS, "game"); err != nil {
+		t.Fatalf("Activate() error = %v", err)
+	}
+	if installer.installSource != "/nas/casual/a.avatar" {
+		t.Fatalf("install source = %q, want the second root's file", installer.installSource)
+	}
+}
+
+// followingCategoryService is a rootedCategoryService that records the
+// symlink policy it was asked to follow.
+type followingCategoryService struct {
+	rootedCategoryService
+	policies []validation.SymlinkPolicy
+}
+
+func (s *followingCategoryService) FollowingSymlinks(policy validation.SymlinkPolicy) interfaces.CategoryService {
+	s.policies = append(s.policies, policy)
+	return &s.rootedCategoryService
+}
+
+func TestWardrobeRoots_FollowSymlinksAsConfigured(t *testing.T) {
+	service := &followingCategoryService{rootedCategoryService: *newRootedCategoryService()}
+	config, err := multiRootConfig(t, "/ssd", "/nas").WithSymlinkPolicy(true, []string{"/packs"})
+	if err != nil {
+		t.Fatalf("WithSymlinkPolicy() error = %v", err)
+	}
+
+	if _, err := scanWardrobe(service, config); err != nil {
+		t.Fatalf("scanWardrobe() error = %v", err)
+	}
+	if _, err := categoryOutfits(service, config, "casual"); err != nil {
+		t.Fatalf("categoryOutfits() error = %v", err)
+	}
+	want := validation.SymlinkPolicy{Follow: true, AllowedRoots: []string{"/packs"}}
+	if len(service.policies) != 2 || !reflect.DeepEqual(service.policies[0], want) || !reflect.DeepEqual(service.policies[1], want) {
+		t.Errorf("policies = %+v, want %+v for each read", service.policies, want)
+	}
+}
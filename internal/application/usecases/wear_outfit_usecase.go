@@ -1,7 +1,7 @@
 package usecases
 
 import (
-	"path/filepath"
+	"time"
 
 	"github.com/dh85/outfitpicker/internal/domain/entities"
 	"github.com/dh85/outfitpicker/internal/domain/errors"
@@ -20,6 +20,12 @@ func NewWearOutfitUseCase(categoryService interfaces.CategoryService, configMana
 }
 
 func (uc *WearOutfitUseCase) Execute(outfit entities.OutfitReference) error {
+	return uc.ExecuteAt(outfit, time.Now())
+}
+
+// ExecuteAt marks outfit worn as of the time at, such as when it was worn
+// while the wardrobe was offline.
+func (uc *WearOutfitUseCase) ExecuteAt(outfit entities.OutfitReference, at time.Time) error {
 	if err := logic.ValidateOutfit(outfit); err != nil {
 		return err
 	}
@@ -29,44 +35,36 @@ func (uc *WearOutfitUseCase) Execute(outfit entities.OutfitReference) error {
 		return err
 	}
 
-	cache, err := uc.cacheManager.LoadOrCreate()
+	files, err := categoryOutfits(uc.categoryService, config, outfit.Category.Name)
 	if err != nil {
 		return err
 	}
 
-	categoryPath := filepath.Join(config.Root, outfit.Category.Name)
-	files, err := uc.categoryService.GetOutfits(categoryPath)
-	if err != nil {
-		return err
+	if _, found := findOutfit(files, outfit); !found {
+		return errors.ErrNoOutfitsAvailable
 	}
 
-	found := false
-	for _, f := range files {
-		if f.FileName == outfit.FileName {
-			found = true
-			break
+	rotationCompleted := false
+	err = uc.cacheManager.Update(func(cache *entities.OutfitCache) error {
+		categoryCache, exists := cache.Categories[outfit.Category.Name]
+		if !exists {
+			categoryCache = entities.NewCategoryCache(len(files))
 		}
-	}
-	if !found {
-		return errors.ErrNoOutfitsAvailable
-	}
 
-	categoryCache, exists := cache.Categories[outfit.Category.Name]
-	if !exists {
-		categoryCache = entities.NewCategoryCache(len(files))
-	}
+		if categoryCache.WornOutfits[outfit.Key()] {
+			return nil
+		}
 
-	if categoryCache.WornOutfits[outfit.FileName] {
+		categoryCache = categoryCache.AddingAt(outfit.Key(), at)
+		*cache = cache.Updating(outfit.Category.Name, categoryCache)
+		rotationCompleted = logic.ShouldResetRotation(len(categoryCache.WornOutfits), len(files))
 		return nil
-	}
-
-	categoryCache = categoryCache.Adding(outfit.FileName)
-	updatedCache := cache.Updating(outfit.Category.Name, categoryCache)
-	if err := uc.cacheManager.Save(&updatedCache); err != nil {
+	})
+	if err != nil {
 		return err
 	}
 
-	if logic.ShouldResetRotation(len(categoryCache.WornOutfits), len(files)) {
+	if rotationCompleted {
 		return errors.NewRotationCompletedError(outfit.Category.Name)
 	}
 
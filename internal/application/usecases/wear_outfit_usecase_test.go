@@ -3,6 +3,7 @@ package usecases
 import (
 	stderrors "errors"
 	"testing"
+	"time"
 
 	"github.com/dh85/outfitpicker/internal/domain/entities"
 	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
@@ -24,7 +25,7 @@ func TestWearOutfitUseCase_Execute(t *testing.T) {
 				entities.NewCategoryReference("casual", "/test/path/casual"),
 			),
 			setup: func() *WearOutfitUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				cache := entities.NewOutfitCache()
 				return NewWearOutfitUseCase(
 					&mockCategoryService{outfitsResult: []entities.FileEntry{
@@ -43,7 +44,7 @@ func TestWearOutfitUseCase_Execute(t *testing.T) {
 				entities.NewCategoryReference("casual", "/test/path/casual"),
 			),
 			setup: func() *WearOutfitUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				cache := entities.NewOutfitCache()
 				return NewWearOutfitUseCase(
 					&mockCategoryService{},
@@ -75,7 +76,7 @@ func TestWearOutfitUseCase_Execute(t *testing.T) {
 				entities.NewCategoryReference("casual", "/test/path/casual"),
 			),
 			setup: func() *WearOutfitUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				return NewWearOutfitUseCase(
 					&mockCategoryService{},
 					&mockConfigUseCase{loadResult: config},
@@ -91,7 +92,7 @@ func TestWearOutfitUseCase_Execute(t *testing.T) {
 				entities.NewCategoryReference("casual", "/test/path/casual"),
 			),
 			setup: func() *WearOutfitUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				cache := entities.NewOutfitCache()
 				return NewWearOutfitUseCase(
 					&mockCategoryService{outfitsResult: []entities.FileEntry{
@@ -111,7 +112,7 @@ func TestWearOutfitUseCase_Execute(t *testing.T) {
 				entities.NewCategoryReference("casual", "/test/path/casual"),
 			),
 			setup: func() *WearOutfitUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				cache := entities.NewOutfitCache().Updating("casual", entities.NewCategoryCache(2).Adding("outfit1.avatar"))
 				return NewWearOutfitUseCase(
 					&mockCategoryService{outfitsResult: []entities.FileEntry{
@@ -130,7 +131,7 @@ func TestWearOutfitUseCase_Execute(t *testing.T) {
 				entities.NewCategoryReference("casual", "/test/path/casual"),
 			),
 			setup: func() *WearOutfitUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				cache := entities.NewOutfitCache().Updating("casual", entities.NewCategoryCache(2).Adding("outfit1.avatar"))
 				return NewWearOutfitUseCase(
 					&mockCategoryService{outfitsResult: []entities.FileEntry{
@@ -151,7 +152,7 @@ func TestWearOutfitUseCase_Execute(t *testing.T) {
 				entities.NewCategoryReference("casual", "/test/path/casual"),
 			),
 			setup: func() *WearOutfitUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				cache := entities.NewOutfitCache()
 				return NewWearOutfitUseCase(
 					&mockCategoryService{outfitsError: assert.AnError},
@@ -168,7 +169,7 @@ func TestWearOutfitUseCase_Execute(t *testing.T) {
 				entities.NewCategoryReference("casual", "/test/path/casual"),
 			),
 			setup: func() *WearOutfitUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				cache := entities.NewOutfitCache()
 				return NewWearOutfitUseCase(
 					&mockCategoryService{outfitsResult: []entities.FileEntry{
@@ -188,7 +189,7 @@ func TestWearOutfitUseCase_Execute(t *testing.T) {
 				entities.NewCategoryReference("casual", "/test/path/casual"),
 			),
 			setup: func() *WearOutfitUseCase {
-				config, _ := entities.NewConfig("/test/path", nil, nil, nil, nil)
+				config, _ := entities.NewConfig("/test/path", nil, nil)
 				cache := entities.NewOutfitCache().Updating("casual", entities.NewCategoryCache(2).Adding("outfit1.avatar"))
 				return NewWearOutfitUseCase(
 					&mockCategoryService{outfitsResult: []entities.FileEntry{
@@ -224,3 +225,47 @@ func TestWearOutfitUseCase_Execute(t *testing.T) {
 		})
 	}
 }
+
+func TestWearOutfitUseCase_Execute_KeepsOtherCategoriesInStoredCache(t *testing.T) {
+	config, _ := entities.NewConfig("/test/path", nil, nil)
+	cache := entities.NewOutfitCache().Updating("formal", entities.NewCategoryCache(3).Adding("suit.avatar"))
+	cacheManager := &mockCacheService{loadResult: &cache}
+	useCase := NewWearOutfitUseCase(
+		&mockCategoryService{outfitsResult: []entities.FileEntry{
+			{FileName: "outfit1.avatar"},
+			{FileName: "outfit2.avatar"},
+		}},
+		&mockConfigUseCase{loadResult: config},
+		cacheManager,
+	)
+
+	err := useCase.Execute(entities.NewOutfitReference("outfit1.avatar", entities.NewCategoryReference("casual", "/test/path/casual")))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if cacheManager.saveCalls != 1 {
+		t.Fatalf("save calls = %d, want 1", cacheManager.saveCalls)
+	}
+	stored := cacheManager.loadResult
+	if !stored.Categories["casual"].WornOutfits["outfit1.avatar"] || !stored.Categories["formal"].WornOutfits["suit.avatar"] {
+		t.Fatalf("stored cache = %+v, want both categories", stored.Categories)
+	}
+}
+
+func TestWearOutfitUseCase_ExecuteAt(t *testing.T) {
+	config, _ := entities.NewConfig("/test/path", nil, nil)
+	cacheManager := &mockCacheService{}
+	useCase := NewWearOutfitUseCase(
+		&mockCategoryService{outfitsResult: []entities.FileEntry{{FileName: "outfit1.avatar"}, {FileName: "outfit2.avatar"}}},
+		&mockConfigUseCase{loadResult: config},
+		cacheManager,
+	)
+
+	at := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	if err := useCase.ExecuteAt(entities.NewOutfitReference("outfit1.avatar", entities.NewCategoryReference("casual", "/test/path/casual")), at); err != nil {
+		t.Fatalf("ExecuteAt() error = %v", err)
+	}
+	if casual := cacheManager.loadResult.Categories["casual"]; !casual.WornOutfits["outfit1.avatar"] || !casual.LastUpdated.Equal(at) {
+		t.Fatalf("stored casual = %+v, want outfit1.avatar worn at %v", casual, at)
+	}
+}
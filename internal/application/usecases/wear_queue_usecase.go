@@ -0,0 +1,165 @@
+package usecases
+
+import (
+	stderrors "errors"
+	"io/fs"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+	"github.com/dh85/outfitpicker/internal/domain/logic"
+)
+
+// WearQueueUseCase keeps the outfits marked worn while the wardrobe is
+// offline, and applies them once it can be read again.
+type WearQueueUseCase struct {
+	configManager   ConfigManager
+	categoryService interfaces.CategoryService
+	known           interfaces.KnownWardrobeRepository
+	queue           interfaces.WearQueueRepository
+}
+
+// NewWearQueueUseCase queues wears of the outfits recorded in known in queue.
+// Without either, the wardrobe is never taken to be offline.
+func NewWearQueueUseCase(configManager ConfigManager, categoryService interfaces.CategoryService, known interfaces.KnownWardrobeRepository, queue interfaces.WearQueueRepository) *WearQueueUseCase {
+	return &WearQueueUseCase{configManager, categoryService, known, queue}
+}
+
+// Offline reports whether no wardrobe root can be scanned while an earlier
+// run recorded the wardrobe, so that the recording can stand in for it.
+func (uc *WearQueueUseCase) Offline() (bool, error) {
+	config, err := loadConfig(uc.configManager)
+	if err != nil || uc.queue == nil {
+		return false, err
+	}
+	known, err := loadKnownWardrobe(uc.known)
+	if err != nil || !known.Recorded() {
+		return false, err
+	}
+	_, statuses := scanRoots(uc.categoryService, config)
+	for _, status := range statuses {
+		if status.Err == nil {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Queue queues outfit to be marked worn, as of at, once the wardrobe is back.
+// The outfit must be in the recorded wardrobe; queuing it again does nothing.
+func (uc *WearQueueUseCase) Queue(outfit entities.OutfitReference, at time.Time) error {
+	if err := logic.ValidateOutfit(outfit); err != nil {
+		return err
+	}
+	known, err := loadKnownWardrobe(uc.known)
+	if err != nil {
+		return err
+	}
+	if !known.HasOutfit(outfit.Category.Name, outfit.Key()) {
+		return errors.ErrNoOutfitsAvailable
+	}
+	return uc.queue.Update(func(current *entities.WearQueue) (*entities.WearQueue, error) {
+		var queue entities.WearQueue
+		if current != nil {
+			queue = *current
+		}
+		updated := queue.Adding(entities.NewQueuedWear(outfit, at))
+		return &updated, nil
+	})
+}
+
+// Queued returns the queued wears, oldest first.
+func (uc *WearQueueUseCase) Queued() ([]entities.QueuedWear, error) {
+	queue, err := uc.load()
+	return queue.Wears, err
+}
+
+// Apply marks each queued outfit worn with wear as of the time it was
+// queued, oldest first. Wears of outfits no longer in the wardrobe are
+// dropped; wears that fail for any other reason stay queued, and the first
+// such failure is returned.
+func (uc *WearQueueUseCase) Apply(wear func(outfit entities.OutfitReference, at time.Time) error) (entities.AppliedWears, error) {
+	var result entities.AppliedWears
+	queue, err := uc.load()
+	if err != nil || len(queue.Wears) == 0 {
+		return result, err
+	}
+	config, err := loadConfig(uc.configManager)
+	if err != nil {
+		return result, err
+	}
+
+	var failed error
+	for _, queued := range queue.Wears {
+		err := wear(queued.Outfit(config.Root), queued.QueuedAt)
+		var rotationCompleted *errors.RotationCompletedError
+		switch {
+		case err == nil, stderrors.As(err, &rotationCompleted):
+			result.Applied = append(result.Applied, queued)
+		case stderrors.Is(err, errors.ErrNoOutfitsAvailable), stderrors.Is(err, fs.ErrNotExist):
+			result.Dropped = append(result.Dropped, queued)
+		case failed == nil:
+			failed = err
+		}
+	}
+
+	done := append(append([]entities.QueuedWear(nil), result.Applied...), result.Dropped...)
+	if len(done) > 0 {
+		err := uc.queue.Update(func(current *entities.WearQueue) (*entities.WearQueue, error) {
+			if current == nil {
+				return nil, nil
+			}
+			updated := current.Without(done)
+			return &updated, nil
+		})
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, failed
+}
+
+// Clear drops every queued wear and returns them.
+func (uc *WearQueueUseCase) Clear() ([]entities.QueuedWear, error) {
+	var cleared []entities.QueuedWear
+	if uc.queue == nil {
+		return nil, nil
+	}
+	err := uc.queue.Update(func(current *entities.WearQueue) (*entities.WearQueue, error) {
+		if current == nil || len(current.Wears) == 0 {
+			return nil, nil
+		}
+		cleared = current.Wears
+		return &entities.WearQueue{}, nil
+	})
+	return cleared, err
+}
+
+// PromoteRoot queues the queued wears of outfits from the wardrobe root
+// root, which has become the first root, as outfits of the first root.
+func (uc *WearQueueUseCase) PromoteRoot(root string) error {
+	if uc.queue == nil {
+		return nil
+	}
+	return uc.queue.Update(func(current *entities.WearQueue) (*entities.WearQueue, error) {
+		if current == nil || len(current.Wears) == 0 {
+			return nil, nil
+		}
+		promoted := current.PromotingRoot(root)
+		return &promoted, nil
+	})
+}
+
+// load returns the queued wears, which are none when nothing was queued or
+// there is nowhere to queue them.
+func (uc *WearQueueUseCase) load() (entities.WearQueue, error) {
+	if uc.queue == nil {
+		return entities.WearQueue{}, nil
+	}
+	queue, err := uc.queue.Load()
+	if err != nil || queue == nil {
+		return entities.WearQueue{}, err
+	}
+	return *queue, nil
+}
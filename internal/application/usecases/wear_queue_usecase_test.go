@@ -0,0 +1,147 @@
+package usecases
+
+import (
+	stderrors "errors"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+var queuedAt = time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+func queuedOutfit(category, fileName, root string) entities.OutfitReference {
+	outfit := entities.NewOutfitReference(fileName, entities.NewCategoryReference(category, root+"/"+category))
+	if root != "/ssd" {
+		outfit.Root = root
+	}
+	return outfit
+}
+
+func TestWearQueueUseCase_Offline(t *testing.T) {
+	online := newRootedCategoryService()
+	offline := &rootedCategoryService{}
+	tests := []struct {
+		name    string
+		config  *entities.Config
+		known   *mockKnownWardrobe
+		service *rootedCategoryService
+		want    bool
+	}{
+		{"every root readable", snapshotConfig(t), snapshotWardrobe(), online, false},
+		{"one root readable", multiRootConfig(t, "/unmounted", "/nas"), knownWardrobe(map[string][]string{"casual": nil}), online, false},
+		{"no root readable", snapshotConfig(t), snapshotWardrobe(), offline, true},
+		{"nothing recorded", multiRootConfig(t, "/ssd"), &mockKnownWardrobe{}, offline, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewWearQueueUseCase(&mockConfigUseCase{loadResult: tt.config}, tt.service, tt.known, &mockWearQueue{}).Offline()
+			if err != nil || got != tt.want {
+				t.Errorf("Offline() = %v, %v; want %v", got, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := NewWearQueueUseCase(&mockConfigUseCase{}, offline, snapshotWardrobe(), &mockWearQueue{}).Offline(); !stderrors.Is(err, domainerrors.ErrConfigurationNotFound) {
+		t.Errorf("Offline() without a config error = %v, want ErrConfigurationNotFound", err)
+	}
+	if got, err := NewWearQueueUseCase(&mockConfigUseCase{loadResult: snapshotConfig(t)}, offline, snapshotWardrobe(), nil).Offline(); got || err != nil {
+		t.Errorf("Offline() with nowhere to queue wears = %v, %v; want false", got, err)
+	}
+}
+
+func TestWearQueueUseCase_Queue(t *testing.T) {
+	queue := &mockWearQueue{}
+	uc := NewWearQueueUseCase(&mockConfigUseCase{loadResult: snapshotConfig(t)}, &rootedCategoryService{}, snapshotWardrobe(), queue)
+
+	for _, outfit := range []entities.OutfitReference{queuedOutfit("casual", "a.avatar", "/nas"), queuedOutfit("casual", "b.avatar", "/ssd"), queuedOutfit("casual", "a.avatar", "/nas")} {
+		if err := uc.Queue(outfit, queuedAt); err != nil {
+			t.Fatalf("Queue(%v) error = %v", outfit, err)
+		}
+	}
+	if err := uc.Queue(queuedOutfit("casual", "new.avatar", "/ssd"), queuedAt); !stderrors.Is(err, domainerrors.ErrNoOutfitsAvailable) {
+		t.Errorf("Queue() of an unrecorded outfit error = %v, want ErrNoOutfitsAvailable", err)
+	}
+	if err := uc.Queue(entities.OutfitReference{}, queuedAt); err == nil {
+		t.Error("Queue() of an invalid outfit succeeded, want an error")
+	}
+
+	queued, err := uc.Queued()
+	if err != nil || len(queued) != 2 || queued[0].Key() != "a.avatar@/nas" || queued[1].Key() != "b.avatar" || !queued[0].QueuedAt.Equal(queuedAt) {
+		t.Fatalf("Queued() = %+v, %v; want a.avatar@/nas then b.avatar", queued, err)
+	}
+
+	if err := uc.PromoteRoot("/nas"); err != nil {
+		t.Fatalf("PromoteRoot() error = %v", err)
+	}
+	if queued, _ := uc.Queued(); len(queued) != 2 || queued[0].Key() != "a.avatar" || queued[1].Key() != "b.avatar" {
+		t.Errorf("Queued() after PromoteRoot() = %+v, want a.avatar from the first root", queued)
+	}
+
+	cleared, err := uc.Clear()
+	if err != nil || len(cleared) != 2 || len(queue.loadResult.Wears) != 0 {
+		t.Errorf("Clear() = %+v, %v; want both wears cleared", cleared, err)
+	}
+	if cleared, err := NewWearQueueUseCase(&mockConfigUseCase{}, nil, nil, nil).Clear(); err != nil || cleared != nil {
+		t.Errorf("Clear() with nowhere to queue wears = %+v, %v; want nothing", cleared, err)
+	}
+	if err := NewWearQueueUseCase(&mockConfigUseCase{}, nil, nil, nil).PromoteRoot("/nas"); err != nil {
+		t.Errorf("PromoteRoot() with nowhere to queue wears error = %v", err)
+	}
+}
+
+func TestWearQueueUseCase_Apply(t *testing.T) {
+	var queued entities.WearQueue
+	for i, outfit := range []entities.OutfitReference{
+		queuedOutfit("casual", "a.avatar", "/ssd"),
+		queuedOutfit("casual", "b.avatar", "/ssd"),
+		queuedOutfit("formal", "suit.avatar", "/nas"),
+		queuedOutfit("casual", "a.avatar", "/nas"),
+	} {
+		queued = queued.Adding(entities.NewQueuedWear(outfit, queuedAt.Add(time.Duration(i)*time.Hour)))
+	}
+	queue := &mockWearQueue{loadResult: &queued}
+	uc := NewWearQueueUseCase(&mockConfigUseCase{loadResult: snapshotConfig(t)}, &rootedCategoryService{}, snapshotWardrobe(), queue)
+
+	var worn []entities.OutfitReference
+	var wornAt []time.Time
+	failure := stderrors.New("cache locked")
+	result, err := uc.Apply(func(outfit entities.OutfitReference, at time.Time) error {
+		worn = append(worn, outfit)
+		wornAt = append(wornAt, at)
+		switch outfit.Key() {
+		case "b.avatar":
+			return domainerrors.NewRotationCompletedError("casual")
+		case "suit.avatar@/nas":
+			return fs.ErrNotExist
+		case "a.avatar@/nas":
+			return failure
+		}
+		return nil
+	})
+	if !stderrors.Is(err, failure) {
+		t.Errorf("Apply() error = %v, want the failure that kept a wear queued", err)
+	}
+	if len(worn) != 4 || worn[2].Category.Path != "/nas/formal" || worn[0].Category.Path != "/ssd/casual" {
+		t.Errorf("Apply() wore %+v, want every queued outfit in its own root", worn)
+	}
+	if !wornAt[0].Equal(queuedAt) || !wornAt[3].Equal(queuedAt.Add(3*time.Hour)) {
+		t.Errorf("Apply() wore outfits at %v, want the times they were queued", wornAt)
+	}
+	if len(result.Applied) != 2 || len(result.Dropped) != 1 || result.Dropped[0].Key() != "suit.avatar@/nas" {
+		t.Errorf("Apply() = %+v, want a.avatar and b.avatar applied and suit.avatar dropped", result)
+	}
+	if left := queue.loadResult.Wears; len(left) != 1 || left[0].Key() != "a.avatar@/nas" {
+		t.Errorf("still queued = %+v, want only a.avatar@/nas", left)
+	}
+
+	result, err = NewWearQueueUseCase(&mockConfigUseCase{loadResult: snapshotConfig(t)}, &rootedCategoryService{}, snapshotWardrobe(), &mockWearQueue{}).Apply(func(entities.OutfitReference, time.Time) error {
+		t.Error("Apply() wore an outfit with nothing queued")
+		return nil
+	})
+	if err != nil || !result.Empty() {
+		t.Errorf("Apply() with nothing queued = %+v, %v; want nothing", result, err)
+	}
+}
@@ -1,14 +1,18 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
 )
 
+const staleConfigMessage = "Settings were changed in another outfitpicker session and have been reloaded. Nothing was saved; please try again."
+
 type AdvancedMenu struct {
 	outfitService OutfitService
 	console       Console
@@ -125,8 +129,9 @@ func (m AdvancedMenu) handlePathChange() menuTransition {
 		return advancedMenuTransition()
 	}
 
-	if pathChangeNeedsResetConfirmation(currentConfig.Root, updatedConfig.Root) {
-		m.terminal().Warning("Changing wardrobe path will reset worn outfit history.")
+	if pathChangeNeedsConfirmation(currentConfig.Root, updatedConfig.Root) {
+		m.terminal().Warning("Changing wardrobe path switches to the worn outfit history of the new path.")
+		m.terminal().Info("History for the current path is kept and comes back if you switch back to it.")
 		m.terminal().Println()
 		m.terminal().Printf("Current: %s\n", sanitizeTerminalText(displayWardrobePath(currentConfig.Root)))
 		m.terminal().Printf("New:     %s\n", sanitizeTerminalText(displayWardrobePath(updatedConfig.Root)))
@@ -138,8 +143,11 @@ func (m AdvancedMenu) handlePathChange() menuTransition {
 		}
 	}
 
-	if err := m.outfitService.UpdateConfiguration(updatedConfig); err != nil {
-		m.terminal().Error(fmt.Sprintf("Failed to update path: %v", err))
+	err = m.outfitService.UpdateConfiguration(basedOn(currentConfig, func(current *entities.Config) (*entities.Config, error) {
+		return buildUpdatedConfig(current, newPath, current.Language, cloneExcludedCategories(current.ExcludedCategories))
+	}))
+	if err != nil {
+		m.reportConfigUpdateError("update path", err)
 	} else {
 		m.terminal().Success(fmt.Sprintf("Outfit path updated to: %s", newPath))
 	}
@@ -166,14 +174,11 @@ func (m AdvancedMenu) handleLanguageChange() menuTransition {
 		normalized = currentConfig.Language
 	}
 
-	updatedConfig, err := buildUpdatedConfig(currentConfig, currentConfig.Root, normalized, cloneExcludedCategories(currentConfig.ExcludedCategories))
+	err = m.outfitService.UpdateConfiguration(basedOn(currentConfig, func(current *entities.Config) (*entities.Config, error) {
+		return buildUpdatedConfig(current, current.Root, normalized, cloneExcludedCategories(current.ExcludedCategories))
+	}))
 	if err != nil {
-		m.terminal().Error(fmt.Sprintf("Failed to update language: %v", err))
-		return advancedMenuTransition()
-	}
-
-	if err := m.outfitService.UpdateConfiguration(updatedConfig); err != nil {
-		m.terminal().Error(fmt.Sprintf("Failed to update language: %v", err))
+		m.reportConfigUpdateError("update language", err)
 	} else {
 		m.terminal().Success(fmt.Sprintf("Language updated to: %s", normalized))
 	}
@@ -280,19 +285,15 @@ func (m AdvancedMenu) applyExcludedAdd(currentConfig *entities.Config, nonExclud
 		return
 	}
 
-	newExcluded := cloneExcludedCategories(currentConfig.ExcludedCategories)
-	for _, category := range categoriesToAdd {
-		newExcluded[category] = true
-	}
-
-	updatedConfig, err := buildUpdatedConfig(currentConfig, currentConfig.Root, currentConfig.Language, newExcluded)
+	err := m.outfitService.UpdateConfiguration(basedOn(currentConfig, func(current *entities.Config) (*entities.Config, error) {
+		newExcluded := cloneExcludedCategories(current.ExcludedCategories)
+		for _, category := range categoriesToAdd {
+			newExcluded[category] = true
+		}
+		return buildUpdatedConfig(current, current.Root, current.Language, newExcluded)
+	}))
 	if err != nil {
-		m.terminal().Error(fmt.Sprintf("Failed to update excluded categories: %v", err))
-		return
-	}
-
-	if err := m.outfitService.UpdateConfiguration(updatedConfig); err != nil {
-		m.terminal().Error(fmt.Sprintf("Failed to update excluded categories: %v", err))
+		m.reportConfigUpdateError("update excluded categories", err)
 	} else {
 		m.terminal().Success(fmt.Sprintf("Added to exclusion list: %s", strings.Join(categoriesToAdd, ", ")))
 	}
@@ -325,19 +326,15 @@ func (m AdvancedMenu) applyExcludedRemove(currentConfig *entities.Config, exclud
 		return
 	}
 
-	newExcluded := cloneExcludedCategories(currentConfig.ExcludedCategories)
-	for _, category := range categoriesToRemove {
-		delete(newExcluded, category)
-	}
-
-	updatedConfig, err := buildUpdatedConfig(currentConfig, currentConfig.Root, currentConfig.Language, newExcluded)
+	err := m.outfitService.UpdateConfiguration(basedOn(currentConfig, func(current *entities.Config) (*entities.Config, error) {
+		newExcluded := cloneExcludedCategories(current.ExcludedCategories)
+		for _, category := range categoriesToRemove {
+			delete(newExcluded, category)
+		}
+		return buildUpdatedConfig(current, current.Root, current.Language, newExcluded)
+	}))
 	if err != nil {
-		m.terminal().Error(fmt.Sprintf("Failed to update excluded categories: %v", err))
-		return
-	}
-
-	if err := m.outfitService.UpdateConfiguration(updatedConfig); err != nil {
-		m.terminal().Error(fmt.Sprintf("Failed to update excluded categories: %v", err))
+		m.reportConfigUpdateError("update excluded categories", err)
 	} else {
 		m.terminal().Success(fmt.Sprintf("Removed from exclusion list: %s", strings.Join(categoriesToRemove, ", ")))
 	}
@@ -354,19 +351,27 @@ func (m AdvancedMenu) applyExcludedClear(currentConfig *entities.Config, exclude
 		return
 	}
 
-	updatedConfig, err := buildUpdatedConfig(currentConfig, currentConfig.Root, currentConfig.Language, map[string]bool{})
+	err := m.outfitService.UpdateConfiguration(basedOn(currentConfig, func(current *entities.Config) (*entities.Config, error) {
+		return buildUpdatedConfig(current, current.Root, current.Language, map[string]bool{})
+	}))
 	if err != nil {
-		m.terminal().Error(fmt.Sprintf("Failed to clear exclusions: %v", err))
-		return
-	}
-
-	if err := m.outfitService.UpdateConfiguration(updatedConfig); err != nil {
-		m.terminal().Error(fmt.Sprintf("Failed to clear exclusions: %v", err))
+		m.reportConfigUpdateError("clear exclusions", err)
 	} else {
 		m.terminal().Success("All exclusions cleared")
 	}
 }
 
+// reportConfigUpdateError tells the user why a settings change was not saved.
+// A stale revision means another session changed the settings after they were
+// shown, so the change is dropped and the next menu shows the fresh settings.
+func (m AdvancedMenu) reportConfigUpdateError(action string, err error) {
+	if errors.Is(err, domainerrors.ErrStaleRevision) {
+		m.terminal().Warning(staleConfigMessage)
+		return
+	}
+	m.terminal().Error(fmt.Sprintf("Failed to %s: %v", action, err))
+}
+
 func (m AdvancedMenu) handleResetSettings() menuTransition {
 	m.terminal().Println("WARNING: This will delete all configuration and worn outfit data.")
 	confirm := m.terminal().Prompt("Reset all settings and worn outfit data? [y/N]: ")
@@ -445,7 +450,7 @@ func (m AdvancedMenu) showResetAllPreview() {
 	m.terminal().Println()
 }
 
-func pathChangeNeedsResetConfirmation(currentRoot, newRoot string) bool {
+func pathChangeNeedsConfirmation(currentRoot, newRoot string) bool {
 	return strings.TrimSpace(currentRoot) != "" && strings.TrimSpace(currentRoot) != strings.TrimSpace(newRoot)
 }
 
@@ -9,7 +9,7 @@ import (
 	"github.com/dh85/outfitpicker/internal/domain/entities"
 )
 
-func TestPathChangeNeedsResetConfirmation(t *testing.T) {
+func TestPathChangeNeedsConfirmation(t *testing.T) {
 	tests := []struct {
 		name        string
 		currentRoot string
@@ -24,9 +24,9 @@ func TestPathChangeNeedsResetConfirmation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := pathChangeNeedsResetConfirmation(tt.currentRoot, tt.newRoot)
+			got := pathChangeNeedsConfirmation(tt.currentRoot, tt.newRoot)
 			if got != tt.want {
-				t.Fatalf("pathChangeNeedsResetConfirmation(%q, %q) = %t, want %t", tt.currentRoot, tt.newRoot, got, tt.want)
+				t.Fatalf("pathChangeNeedsConfirmation(%q, %q) = %t, want %t", tt.currentRoot, tt.newRoot, got, tt.want)
 			}
 		})
 	}
@@ -283,7 +283,7 @@ func TestAdvancedMenu_handlePathChange(t *testing.T) {
 
 		assertMenuDestination(t, menu.handlePathChange(), menuDestinationAdvanced)
 		assertCurrentConfigRoot(t, picker, cliTestOutfitRoot)
-		assertOutputContains(t, output.String(), "Changing wardrobe path will reset worn outfit history", "Current: "+displayWardrobePath(cliTestOutfitRoot), "New:     "+displayWardrobePath(cliTestNewOutfitRoot), "Continue? [y/N]")
+		assertOutputContains(t, output.String(), "Changing wardrobe path switches to the worn outfit history of the new path", "History for the current path is kept", "Current: "+displayWardrobePath(cliTestOutfitRoot), "New:     "+displayWardrobePath(cliTestNewOutfitRoot), "Continue? [y/N]")
 	})
 
 	t.Run("update error", func(t *testing.T) {
@@ -356,6 +356,28 @@ func TestAdvancedMenu_handleLanguageChange(t *testing.T) {
 	})
 }
 
+func TestAdvancedMenu_ReportsSettingsChangedElsewhere(t *testing.T) {
+	viewed := mustAdvancedMenuConfig(t, cliTestOutfitRoot, "en", nil)
+	viewed.Revision = 1
+	changed := mustAdvancedMenuConfig(t, cliTestOutfitRoot, "de", nil)
+	changed.Revision = 2
+	picker := newAdvancedMenuTestPicker(withConfig(viewed))
+	picker.config.savedElsewhere = changed
+	var output strings.Builder
+	menu := AdvancedMenu{
+		outfitService: newStubOutfitService(picker.stubRuntime),
+		console:       TerminalConsole{stdin: strings.NewReader("fr\n"), stdout: &output, stderr: &output},
+	}
+
+	assertMenuDestination(t, menu.handleLanguageChange(), menuDestinationAdvanced)
+
+	assertCurrentConfigLanguage(t, picker, "de")
+	assertOutputContains(t, output.String(), "changed in another outfitpicker session")
+	if len(picker.config.updatedConfigs) != 0 {
+		t.Fatalf("updatedConfigs = %d, want none", len(picker.config.updatedConfigs))
+	}
+}
+
 func TestAdvancedMenu_handleExcludedChange(t *testing.T) {
 	t.Run("configuration error", func(t *testing.T) {
 		picker := newAdvancedMenuTestPicker(withConfigError(errors.New("boom")))
@@ -693,7 +715,7 @@ func withPromptResponses(t *testing.T, responses ...string) func() {
 
 func mustAdvancedMenuConfig(t *testing.T, root, language string, excluded map[string]bool) *entities.Config {
 	t.Helper()
-	config, err := entities.NewConfig(root, &language, excluded, nil, nil)
+	config, err := entities.NewConfig(root, &language, excluded)
 	if err != nil {
 		t.Fatalf("NewConfig(%q) error = %v", root, err)
 	}
@@ -3,12 +3,27 @@ package cli
 import (
 	"errors"
 	"sort"
+	"time"
 
+	"github.com/dh85/outfitpicker/internal/application/usecases"
 	"github.com/dh85/outfitpicker/internal/domain/entities"
 	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
 	"github.com/dh85/outfitpicker/internal/domain/logic"
+	"github.com/dh85/outfitpicker/internal/domain/validation"
 )
 
+var errActivationUnavailable = errors.New("outfit activation is not available")
+
+var errStorageSelectionUnavailable = errors.New("storage selection is not available")
+
+var errJournalUnavailable = errors.New("the journal is not available")
+
+var errBackupsUnavailable = errors.New("backups are not available")
+
+var errProfilesUnavailable = errors.New("profiles are not available")
+
+var errWardrobeSetupUnavailable = errors.New("wardrobe setup is not available")
+
 func (a *Application) GetCategoryInfo() ([]entities.CategoryInfo, error) {
 	return a.wardrobe.GetCategoryInfo()
 }
@@ -21,6 +36,10 @@ func (a *Application) GetRootDirectory() (string, error) {
 	return a.wardrobe.GetRootDirectory()
 }
 
+func (a *Application) GetRootStatuses() ([]entities.RootStatus, error) {
+	return a.wardrobe.GetRootStatuses()
+}
+
 func (a *Application) GetConfiguration() (*entities.Config, error) {
 	return a.config.GetConfiguration()
 }
@@ -39,8 +58,146 @@ func (a *Application) CacheFilePath() (string, error) {
 	return a.pathProvider.CacheFilePath()
 }
 
-func (a *Application) UpdateConfiguration(config *entities.Config) error {
-	return a.config.UpdateConfiguration(config)
+func (a *Application) UpdateConfiguration(change ConfigChange) error {
+	return a.config.UpdateConfiguration(change)
+}
+
+func (a *Application) UpdateConfigurationMigratingHistory(change ConfigChange) error {
+	return a.config.UpdateConfigurationMigratingHistory(change)
+}
+
+// OutfitRenames returns the worn outfits found renamed or moved when the
+// wardrobe was loaded.
+func (a *Application) OutfitRenames() ([]entities.OutfitRename, error) {
+	if a.tracker == nil {
+		return nil, nil
+	}
+	return a.tracker.renames, a.tracker.err
+}
+
+// WardrobeChanges returns the categories and outfits added to or removed from
+// the wardrobe since the run before this one.
+func (a *Application) WardrobeChanges() (entities.WardrobeChanges, error) {
+	return a.changes.found, a.changes.err
+}
+
+// Offline reports whether the wardrobe could not be read when the
+// application loaded, so it shows the wardrobe as last recorded and queues
+// wears.
+func (a *Application) Offline() bool {
+	return a.queue.isOffline()
+}
+
+// QueuedWears returns the wears queued while the wardrobe was offline.
+func (a *Application) QueuedWears() ([]entities.QueuedWear, error) {
+	return a.queue.queue.Queued()
+}
+
+// ClearQueuedWears drops the queued wears without applying them.
+func (a *Application) ClearQueuedWears() ([]entities.QueuedWear, error) {
+	return a.queue.queue.Clear()
+}
+
+// AppliedWears returns what became of the queued wears when the application
+// loaded.
+func (a *Application) AppliedWears() (entities.AppliedWears, error) {
+	return a.queue.applied, a.queue.err
+}
+
+// ReconcileCache drops worn outfits and categories that are no longer in the
+// wardrobe and corrects outfit totals. The result includes what was
+// reconciled when the application loaded.
+func (a *Application) ReconcileCache() (entities.CacheReconciliation, error) {
+	return a.reconciler.run()
+}
+
+// SelectStorage moves config and cache to backend. The application keeps
+// using the previous backend, so it should exit afterwards.
+func (a *Application) SelectStorage(backend entities.StorageBackend) error {
+	if a.storage == nil {
+		return errStorageSelectionUnavailable
+	}
+	return a.storage.SelectStorage(backend)
+}
+
+// WearHistory lists recorded wears, newest first, in category unless it is
+// empty and up to limit unless it is zero.
+func (a *Application) WearHistory(category string, limit int) ([]entities.WearRecord, error) {
+	if a.wearHistory == nil {
+		return nil, domainerrors.ErrNoWearHistory
+	}
+	return a.wearHistory.WearHistory(category, limit)
+}
+
+// WearStats counts the recorded wears of each outfit, most worn first.
+func (a *Application) WearStats(category string) ([]entities.OutfitWearStats, error) {
+	if a.wearHistory == nil {
+		return nil, domainerrors.ErrNoWearHistory
+	}
+	return a.wearHistory.WearStats(category)
+}
+
+// RestoreAt replaces config and cache with the state the journal recorded as
+// of at, snapshotting the state it replaces.
+func (a *Application) RestoreAt(at time.Time) (entities.JournalState, error) {
+	if a.journal == nil {
+		return entities.JournalState{}, errJournalUnavailable
+	}
+	if err := a.snapshots.take(usecases.SnapshotJournalRestore, nil); err != nil {
+		return entities.JournalState{}, err
+	}
+	state, err := a.journal.RestoreAt(at)
+	if err != nil {
+		return entities.JournalState{}, err
+	}
+	a.session.ResetAll()
+	return state, nil
+}
+
+// RebuildCache replaces the cache with the one replaying the journal gives.
+func (a *Application) RebuildCache() (entities.OutfitCache, error) {
+	if a.journal == nil {
+		return entities.OutfitCache{}, errJournalUnavailable
+	}
+	return a.journal.RebuildCache()
+}
+
+// CreateBackup saves config and cache to a new backup archive, at path if it
+// is not empty.
+func (a *Application) CreateBackup(path string) (entities.BackupInfo, error) {
+	if a.backups == nil {
+		return entities.BackupInfo{}, errBackupsUnavailable
+	}
+	return a.backups.Create(path)
+}
+
+// ListBackups returns the backups and automatic snapshots, newest first.
+func (a *Application) ListBackups() ([]entities.BackupInfo, error) {
+	if a.backups == nil {
+		return nil, errBackupsUnavailable
+	}
+	return a.backups.List()
+}
+
+// RestoreBackup replaces config and cache with the backup called name.
+func (a *Application) RestoreBackup(name string) (entities.BackupInfo, entities.BackupContents, error) {
+	if a.backups == nil {
+		return entities.BackupInfo{}, entities.BackupContents{}, errBackupsUnavailable
+	}
+	backup, contents, err := a.backups.Restore(name)
+	if err != nil {
+		return entities.BackupInfo{}, entities.BackupContents{}, err
+	}
+	a.session.ResetAll()
+	return backup, contents, nil
+}
+
+// PruneBackups deletes all but the newest keep backups and snapshots.
+func (a *Application) PruneBackups(keep int) ([]entities.BackupInfo, error) {
+	if a.backups == nil {
+		return nil, errBackupsUnavailable
+	}
+	return a.backups.Prune(keep)
 }
 
 func (a *Application) FactoryReset() error {
@@ -71,6 +228,10 @@ func (a *Application) ResetCategory(categoryName string) error {
 	return a.commands.ResetCategory(categoryName)
 }
 
+func (a *Application) ResetCategoryIfUnchanged(categoryName, wornRevision string) error {
+	return a.commands.ResetCategoryIfUnchanged(categoryName, wornRevision)
+}
+
 func (a *Application) ResetAllCategories() error {
 	return a.commands.ResetAllCategories()
 }
@@ -83,6 +244,24 @@ func (a *Application) ShowNextUniqueRandomOutfitFrom(categoryName string) (*enti
 	return a.selection.ShowNextUniqueRandomOutfitFrom(categoryName)
 }
 
+func (a *Application) ShowRandomOutfitIncludingExcluded() (*entities.OutfitReference, error) {
+	return a.selection.ShowRandomOutfitIncludingExcluded()
+}
+
+func (a *Application) ActivateOutfit(outfit entities.OutfitReference, slotName string) (entities.ActivationSlot, error) {
+	if a.activation == nil {
+		return entities.ActivationSlot{}, errActivationUnavailable
+	}
+	return a.activation.Execute(outfit, slotName)
+}
+
+func (a *Application) RestoreSlot(slotName string) (entities.ActivationSlot, error) {
+	if a.activation == nil {
+		return entities.ActivationSlot{}, errActivationUnavailable
+	}
+	return a.activation.Restore(slotName)
+}
+
 func (a *Application) resetAfterWear(categoryName string) {
 	a.session.ResetAll()
 	a.session.ResetCategory(categoryName)
@@ -101,7 +280,29 @@ func cloneExcludedCategories(in map[string]bool) map[string]bool {
 }
 
 func buildUpdatedConfig(current *entities.Config, root, language string, excluded map[string]bool) (*entities.Config, error) {
-	return entities.NewConfig(root, &language, excluded, current.KnownCategories, current.KnownCategoryFiles)
+	if err := validation.ValidateLanguage(&language); err != nil {
+		return nil, domainerrors.MapError(err)
+	}
+	if excluded == nil {
+		excluded = make(map[string]bool)
+	}
+	updated := *current
+	updated.Language = language
+	updated.ExcludedCategories = excluded
+	// The roots are checked against the current symlink policy, so a root
+	// that is a followed link stays valid.
+	return updated.WithRoots(append([]string{root}, current.WardrobeRoots()[1:]...))
+}
+
+// basedOn guards change with the revision of the configuration the user was
+// shown, failing with ErrStaleRevision if another session saved since then.
+func basedOn(viewed *entities.Config, change ConfigChange) ConfigChange {
+	return func(current *entities.Config) (*entities.Config, error) {
+		if current.Revision != viewed.Revision {
+			return nil, domainerrors.NewStaleRevisionError(viewed.Revision, current.Revision)
+		}
+		return change(current)
+	}
 }
 
 func sortedCategoryNames(values map[string][]entities.OutfitReference) []string {
@@ -116,7 +317,7 @@ func sortedCategoryNames(values map[string][]entities.OutfitReference) []string
 func currentCategoryWornFileNames(state entities.CategoryOutfitState) map[string]bool {
 	result := make(map[string]bool, len(state.WornOutfits))
 	for _, outfit := range state.WornOutfits {
-		result[outfit.FileName] = true
+		result[outfit.Key()] = true
 	}
 	return result
 }
@@ -138,7 +339,7 @@ func availableOutfitsFromState(state entities.CategoryOutfitState) []entities.Ou
 func allOutfitsFromFiles(category entities.CategoryReference, files []entities.FileEntry) []entities.OutfitReference {
 	outfits := make([]entities.OutfitReference, 0, len(files))
 	for _, file := range files {
-		outfits = append(outfits, entities.NewOutfitReference(file.FileName, category))
+		outfits = append(outfits, entities.NewOutfitReference(file.FileName, category).WithPreview(file.PreviewFileName))
 	}
 	sort.Slice(outfits, func(i, j int) bool {
 		return outfits[i].FileName < outfits[j].FileName
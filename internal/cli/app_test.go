@@ -1,19 +1,23 @@
 package cli
 
 import (
+	"bytes"
 	"errors"
 	"reflect"
 	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/dh85/outfitpicker/internal/application/usecases"
 	"github.com/dh85/outfitpicker/internal/domain/entities"
 	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
 	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+	"github.com/dh85/outfitpicker/internal/domain/logic"
 )
 
 func TestApplication_GetCategories_FiltersOnlyCategoriesWithOutfits(t *testing.T) {
-	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 	categorySvc := &stubCategoryService{
 		scanCategoriesResult: []entities.CategoryInfo{
 			entities.NewCategoryInfo(entities.NewCategoryReference("casual", cliTestCategoryPath("casual")), entities.CategoryStateHasOutfits, 2),
@@ -33,7 +37,7 @@ func TestApplication_GetCategories_FiltersOnlyCategoriesWithOutfits(t *testing.T
 }
 
 func TestApplication_GetCategories_PropagatesCategoryInfoError(t *testing.T) {
-	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 	wantErr := errors.New("scan failed")
 	app := newTestApplication(config, &stubConfigManager{config: config}, &stubCacheManager{cache: newOutfitCachePtr()}, &stubCategoryService{scanCategoriesErr: wantErr})
 
@@ -44,7 +48,7 @@ func TestApplication_GetCategories_PropagatesCategoryInfoError(t *testing.T) {
 }
 
 func TestApplication_GetRootDirectory_ReturnsConfiguredRoot(t *testing.T) {
-	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 	app := newTestApplication(nil, &stubConfigManager{config: config}, &stubCacheManager{cache: newOutfitCachePtr()}, &stubCategoryService{})
 
 	root, err := app.GetRootDirectory()
@@ -75,6 +79,18 @@ func TestApplication_GetConfiguration_ReturnsConfigurationNotFoundForNilConfig(t
 	}
 }
 
+func TestApplication_ConfigCommandReportsConfigurationNotFound(t *testing.T) {
+	app := newTestApplication(nil, &stubConfigManager{}, &stubCacheManager{cache: newOutfitCachePtr()}, &stubCategoryService{})
+	var stderr bytes.Buffer
+
+	_, code := ExecuteCommand([]string{"config", "exclude", "casual"}, app, TerminalConsole{stdout: &bytes.Buffer{}, stderr: &stderr})
+
+	if code != 1 {
+		t.Fatalf("ExecuteCommand() code = %d, want 1", code)
+	}
+	assertOutputContains(t, stderr.String(), domainerrors.ErrConfigurationNotFound.Error())
+}
+
 func TestApplication_GetConfiguration_PropagatesLoadError(t *testing.T) {
 	wantErr := errors.New("load failed")
 	app := newTestApplication(nil, &stubConfigManager{err: wantErr}, &stubCacheManager{cache: newOutfitCachePtr()}, &stubCategoryService{})
@@ -86,7 +102,7 @@ func TestApplication_GetConfiguration_PropagatesLoadError(t *testing.T) {
 }
 
 func TestApplication_ShowNextUniqueRandomOutfit_SkipsExcludedCategories(t *testing.T) {
-	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), map[string]bool{"formal": true}, nil, nil)
+	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), map[string]bool{"formal": true})
 	categorySvc := &stubCategoryService{
 		scanCategoriesResult: []entities.CategoryInfo{
 			entities.NewCategoryInfo(entities.NewCategoryReference("casual", "/root/outfits/casual"), entities.CategoryStateHasOutfits, 1),
@@ -113,7 +129,7 @@ func TestApplication_ShowNextUniqueRandomOutfit_SkipsExcludedCategories(t *testi
 }
 
 func TestApplication_ShowNextUniqueRandomOutfitFrom_ResetsShownSession(t *testing.T) {
-	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 	categorySvc := &stubCategoryService{
 		scanCategoriesResult: []entities.CategoryInfo{
 			entities.NewCategoryInfo(entities.NewCategoryReference("casual", "/root/outfits/casual"), entities.CategoryStateHasOutfits, 2),
@@ -152,20 +168,25 @@ func TestApplication_ShowNextUniqueRandomOutfitFrom_ResetsShownSession(t *testin
 	}
 }
 
-func TestApplication_UpdateConfiguration_ResetsCacheWhenRootChanges(t *testing.T) {
-	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
-	updated, _ := entities.NewConfig(cliTestOtherOutfitRoot, stringPtr("en"), nil, nil, nil)
-	cacheManager := &stubCacheManager{cache: newOutfitCachePtr()}
+func TestApplication_UpdateConfiguration_SwitchesHistoryWhenRootChanges(t *testing.T) {
+	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
+	updated, _ := entities.NewConfig(cliTestOtherOutfitRoot, stringPtr("en"), nil)
+	worn := entities.NewOutfitCache().Updating("casual", entities.NewCategoryCache(2).Adding("one.avatar"))
+	cacheManager := &stubCacheManager{cache: &worn}
 	app := newTestApplication(config, &stubConfigManager{config: config}, cacheManager, &stubCategoryService{})
 	markGlobalShown(app, "casual/one.avatar")
 	markCategoryShown(app, "casual", "one.avatar")
 
-	err := app.UpdateConfiguration(updated)
+	err := app.UpdateConfiguration(replaceConfig(updated))
 	if err != nil {
 		t.Fatalf("UpdateConfiguration() error = %v", err)
 	}
-	if cacheManager.deleteCalls != 1 {
-		t.Fatalf("cache deleteCalls = %d, want 1", cacheManager.deleteCalls)
+	if cacheManager.deleteCalls != 0 {
+		t.Fatalf("cache deleteCalls = %d, want the history kept", cacheManager.deleteCalls)
+	}
+	oldRoot, newRoot := logic.CanonicalRoot(cliTestOutfitRoot), logic.CanonicalRoot(cliTestOtherOutfitRoot)
+	if cache := cacheManager.cache; cache.Root != newRoot || len(cache.Categories) != 0 || !cache.OtherRoots[oldRoot]["casual"].WornOutfits["one.avatar"] {
+		t.Fatalf("cache = %+v, want an empty rotation for the new root and the old one kept", cache)
 	}
 	if globalShownCount(app) != 0 {
 		t.Fatalf("expected global shown cache to reset, got %d entries", globalShownCount(app))
@@ -180,15 +201,75 @@ func TestApplication_UpdateConfiguration_ResetsCacheWhenRootChanges(t *testing.T
 	if gotConfig.Root != cliTestOtherOutfitRoot {
 		t.Fatalf("config root = %q, want %q", gotConfig.Root, cliTestOtherOutfitRoot)
 	}
+
+	if err := app.UpdateConfiguration(replaceConfig(config)); err != nil {
+		t.Fatalf("UpdateConfiguration() back error = %v", err)
+	}
+	if cache := cacheManager.cache; cache.Root != oldRoot || !cache.Categories["casual"].WornOutfits["one.avatar"] {
+		t.Fatalf("cache after switching back = %+v, want the old rotation restored", cache)
+	}
+}
+
+func TestApplication_UpdateConfiguration_ForgetsKnownWardrobeWhenRootChanges(t *testing.T) {
+	recorded := entities.NewKnownWardrobe(map[string][]string{"casual": {"one.avatar"}}, map[string]map[string]bool{"casual": {"one.avatar": true}})
+	known := &stubKnownWardrobe{known: &recorded}
+	queue := &stubWearQueue{queue: &entities.WearQueue{Wears: []entities.QueuedWear{{Category: "casual", FileName: "one.avatar"}}}}
+	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
+	configManager := &stubConfigManager{config: config}
+	app := buildApplication(config, RuntimeDependencies{
+		ConfigManager: configManager,
+		CacheManager:  &stubCacheManager{cache: newOutfitCachePtr()},
+		CategorySvc:   &stubCategoryService{},
+		KnownWardrobe: known,
+		WearQueue:     queue,
+	})
+
+	if err := app.UpdateConfiguration(replaceConfig(config)); err != nil {
+		t.Fatalf("UpdateConfiguration() error = %v", err)
+	}
+	if !known.known.Recorded() {
+		t.Fatal("known wardrobe was forgotten without a root change")
+	}
+	moved, _ := entities.NewConfig(cliTestOtherOutfitRoot, stringPtr("en"), nil)
+	if err := app.UpdateConfigurationMigratingHistory(replaceConfig(moved)); err != nil {
+		t.Fatalf("UpdateConfigurationMigratingHistory() error = %v", err)
+	}
+	if !known.known.Recorded() || known.known.NewArrivals == nil || len(queue.queue.Wears) != 1 {
+		t.Fatal("known wardrobe or queued wears were forgotten when the same wardrobe moved")
+	}
+	if err := app.UpdateConfiguration(replaceConfig(config)); err != nil {
+		t.Fatalf("UpdateConfiguration() error = %v", err)
+	}
+	if known.known.Recorded() || known.known.NewArrivals != nil {
+		t.Fatalf("known wardrobe = %+v, want another wardrobe recorded afresh", known.known)
+	}
+	if len(queue.queue.Wears) != 0 {
+		t.Fatalf("queued wears = %+v, want those for the old wardrobe dropped", queue.queue.Wears)
+	}
+}
+
+func TestApplication_UpdateConfigurationMigratingHistory(t *testing.T) {
+	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
+	updated, _ := entities.NewConfig(cliTestOtherOutfitRoot, stringPtr("en"), nil)
+	worn := entities.NewOutfitCache().Updating("casual", entities.NewCategoryCache(2).Adding("one.avatar"))
+	cacheManager := &stubCacheManager{cache: &worn}
+	app := newTestApplication(config, &stubConfigManager{config: config}, cacheManager, &stubCategoryService{})
+
+	if err := app.UpdateConfigurationMigratingHistory(replaceConfig(updated)); err != nil {
+		t.Fatalf("UpdateConfigurationMigratingHistory() error = %v", err)
+	}
+	if cache := cacheManager.cache; cache.Root != logic.CanonicalRoot(cliTestOtherOutfitRoot) || !cache.Categories["casual"].WornOutfits["one.avatar"] || cache.OtherRoots != nil {
+		t.Fatalf("cache = %+v, want the rotation carried to the new root", cache)
+	}
 }
 
 func TestApplication_UpdateConfiguration_DoesNotResetCacheWhenRootUnchanged(t *testing.T) {
-	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
-	updated, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("fr"), nil, nil, nil)
+	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
+	updated, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("fr"), nil)
 	cacheManager := &stubCacheManager{cache: newOutfitCachePtr()}
 	app := newTestApplication(config, &stubConfigManager{config: config}, cacheManager, &stubCategoryService{})
 
-	err := app.UpdateConfiguration(updated)
+	err := app.UpdateConfiguration(replaceConfig(updated))
 	if err != nil {
 		t.Fatalf("UpdateConfiguration() error = %v", err)
 	}
@@ -197,16 +278,16 @@ func TestApplication_UpdateConfiguration_DoesNotResetCacheWhenRootUnchanged(t *t
 	}
 }
 
-func TestApplication_UpdateConfiguration_PropagatesSaveAndDeleteErrors(t *testing.T) {
+func TestApplication_UpdateConfiguration_PropagatesSaveErrors(t *testing.T) {
 	t.Run("save error", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
-		updated, _ := entities.NewConfig(cliTestOtherOutfitRoot, stringPtr("en"), nil, nil, nil)
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
+		updated, _ := entities.NewConfig(cliTestOtherOutfitRoot, stringPtr("en"), nil)
 		wantErr := errors.New("save failed")
 		configManager := &stubConfigManager{config: config, saveErr: wantErr}
 		cacheManager := &stubCacheManager{cache: newOutfitCachePtr()}
 		app := newTestApplication(config, configManager, cacheManager, &stubCategoryService{})
 
-		err := app.UpdateConfiguration(updated)
+		err := app.UpdateConfiguration(replaceConfig(updated))
 		if !errors.Is(err, wantErr) {
 			t.Fatalf("UpdateConfiguration() error = %v, want %v", err, wantErr)
 		}
@@ -215,25 +296,77 @@ func TestApplication_UpdateConfiguration_PropagatesSaveAndDeleteErrors(t *testin
 		}
 	})
 
-	t.Run("delete error on root change", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
-		updated, _ := entities.NewConfig(cliTestOtherOutfitRoot, stringPtr("en"), nil, nil, nil)
-		wantErr := errors.New("delete failed")
-		cacheManager := &stubCacheManager{cache: newOutfitCachePtr(), deleteErr: wantErr}
+	t.Run("cache save error on root change", func(t *testing.T) {
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
+		updated, _ := entities.NewConfig(cliTestOtherOutfitRoot, stringPtr("en"), nil)
+		wantErr := errors.New("cache save failed")
+		cacheManager := &stubCacheManager{cache: newOutfitCachePtr(), saveErr: wantErr}
 		app := newTestApplication(config, &stubConfigManager{config: config}, cacheManager, &stubCategoryService{})
 
-		err := app.UpdateConfiguration(updated)
+		err := app.UpdateConfiguration(replaceConfig(updated))
 		if !errors.Is(err, wantErr) {
 			t.Fatalf("UpdateConfiguration() error = %v, want %v", err, wantErr)
 		}
-		if cacheManager.deleteCalls != 1 {
-			t.Fatalf("cache deleteCalls = %d, want 1", cacheManager.deleteCalls)
-		}
 	})
 }
 
+func TestApplication_UpdateConfiguration_ChangesStoredConfiguration(t *testing.T) {
+	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
+	stored, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), map[string]bool{"formal": true})
+	configManager := &stubConfigManager{config: stored}
+	cacheManager := &stubCacheManager{cache: newOutfitCachePtr()}
+	app := newTestApplication(config, configManager, cacheManager, &stubCategoryService{})
+
+	err := app.UpdateConfiguration(func(current *entities.Config) (*entities.Config, error) {
+		return buildUpdatedConfig(current, current.Root, "fr", cloneExcludedCategories(current.ExcludedCategories))
+	})
+	if err != nil {
+		t.Fatalf("UpdateConfiguration() error = %v", err)
+	}
+	if configManager.config.Language != "fr" || !configManager.config.ExcludedCategories["formal"] {
+		t.Fatalf("stored config = %+v, want language fr keeping formal excluded", configManager.config)
+	}
+
+	wantErr := errors.New("rejected")
+	err = app.UpdateConfiguration(func(*entities.Config) (*entities.Config, error) { return nil, wantErr })
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("UpdateConfiguration() error = %v, want %v", err, wantErr)
+	}
+	if configManager.config.Language != "fr" {
+		t.Fatalf("stored language = %q, want fr after rejected change", configManager.config.Language)
+	}
+}
+
+func TestApplication_UpdateConfiguration_RejectsChangeToStaleView(t *testing.T) {
+	viewed, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
+	viewed.Revision = 1
+	stored := *viewed
+	stored.Revision = 2
+	configManager := &stubConfigManager{config: &stored}
+	app := newTestApplication(viewed, configManager, &stubCacheManager{}, &stubCategoryService{})
+
+	err := app.UpdateConfiguration(basedOn(viewed, func(current *entities.Config) (*entities.Config, error) {
+		return buildUpdatedConfig(current, current.Root, "fr", cloneExcludedCategories(current.ExcludedCategories))
+	}))
+	if !errors.Is(err, domainerrors.ErrStaleRevision) {
+		t.Fatalf("UpdateConfiguration() error = %v, want %v", err, domainerrors.ErrStaleRevision)
+	}
+	if configManager.config.Language != "en" {
+		t.Fatalf("stored language = %q, want en", configManager.config.Language)
+	}
+
+	stored.Revision = 1
+	if err := app.UpdateConfiguration(basedOn(viewed, replaceConfig(viewed))); err != nil {
+		t.Fatalf("UpdateConfiguration() at current revision error = %v", err)
+	}
+}
+
+func replaceConfig(config *entities.Config) ConfigChange {
+	return func(*entities.Config) (*entities.Config, error) { return config, nil }
+}
+
 func TestApplication_FactoryReset_DeletesConfigCacheAndResetsState(t *testing.T) {
-	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 	configManager := &stubConfigManager{config: config}
 	cacheManager := &stubCacheManager{cache: newOutfitCachePtr()}
 	app := newTestApplication(config, configManager, cacheManager, &stubCategoryService{})
@@ -257,7 +390,7 @@ func TestApplication_FactoryReset_DeletesConfigCacheAndResetsState(t *testing.T)
 
 func TestApplication_FactoryReset_PropagatesDeleteErrors(t *testing.T) {
 	t.Run("config delete error", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 		wantErr := errors.New("config delete failed")
 		configManager := &stubConfigManager{config: config, deleteErr: wantErr}
 		cacheManager := &stubCacheManager{cache: newOutfitCachePtr()}
@@ -273,7 +406,7 @@ func TestApplication_FactoryReset_PropagatesDeleteErrors(t *testing.T) {
 	})
 
 	t.Run("cache delete error", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 		wantErr := errors.New("cache delete failed")
 		configManager := &stubConfigManager{config: config}
 		cacheManager := &stubCacheManager{cache: newOutfitCachePtr(), deleteErr: wantErr}
@@ -287,7 +420,7 @@ func TestApplication_FactoryReset_PropagatesDeleteErrors(t *testing.T) {
 }
 
 func TestApplication_GetOutfitState_BuildsStateFromConfigCacheAndFiles(t *testing.T) {
-	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 	cache := entities.NewOutfitCache()
 	cache.Categories["casual"] = entities.CategoryCache{
 		WornOutfits:  map[string]bool{"worn.avatar": true},
@@ -332,7 +465,7 @@ func TestApplication_GetOutfitState_PropagatesErrors(t *testing.T) {
 	})
 
 	t.Run("cache error", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 		wantErr := errors.New("cache load failed")
 		app := newTestApplication(config, &stubConfigManager{config: config}, &stubCacheManager{err: wantErr}, &stubCategoryService{})
 
@@ -343,7 +476,7 @@ func TestApplication_GetOutfitState_PropagatesErrors(t *testing.T) {
 	})
 
 	t.Run("category service error", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 		wantErr := errors.New("get outfits failed")
 		app := newTestApplication(config, &stubConfigManager{config: config}, &stubCacheManager{cache: newOutfitCachePtr()}, &stubCategoryService{outfitsErr: wantErr})
 
@@ -355,7 +488,7 @@ func TestApplication_GetOutfitState_PropagatesErrors(t *testing.T) {
 }
 
 func TestApplication_GetAllOutfitStates_ReturnsStatesForAllCategories(t *testing.T) {
-	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 	categorySvc := &stubCategoryService{
 		scanCategoriesResult: []entities.CategoryInfo{
 			entities.NewCategoryInfo(entities.NewCategoryReference("casual", cliTestCategoryPath("casual")), entities.CategoryStateHasOutfits, 2),
@@ -382,7 +515,7 @@ func TestApplication_GetAllOutfitStates_ReturnsStatesForAllCategories(t *testing
 
 func TestApplication_GetAllOutfitStates_PropagatesErrors(t *testing.T) {
 	t.Run("get categories error", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 		wantErr := errors.New("scan failed")
 		app := newTestApplication(config, &stubConfigManager{config: config}, &stubCacheManager{cache: newOutfitCachePtr()}, &stubCategoryService{scanCategoriesErr: wantErr})
 
@@ -393,7 +526,7 @@ func TestApplication_GetAllOutfitStates_PropagatesErrors(t *testing.T) {
 	})
 
 	t.Run("state error", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 		categorySvc := &stubCategoryService{
 			scanCategoriesResult: []entities.CategoryInfo{
 				entities.NewCategoryInfo(entities.NewCategoryReference("casual", cliTestCategoryPath("casual")), entities.CategoryStateHasOutfits, 1),
@@ -410,7 +543,7 @@ func TestApplication_GetAllOutfitStates_PropagatesErrors(t *testing.T) {
 }
 
 func TestApplication_GetAvailableOutfits_ReturnsAvailableOutfits(t *testing.T) {
-	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 	cache := entities.NewOutfitCache()
 	cache.Categories["casual"] = entities.CategoryCache{WornOutfits: map[string]bool{"worn.avatar": true}, TotalOutfits: 2}
 	app := newTestApplication(config, &stubConfigManager{config: config}, &stubCacheManager{cache: &cache}, &stubCategoryService{outfitsByPath: map[string][]entities.FileEntry{
@@ -438,7 +571,7 @@ func TestApplication_GetAvailableOutfits_PropagatesStateError(t *testing.T) {
 
 func TestApplication_ShowAllOutfits_ReturnsAllOutfitsAndErrors(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 		app := newTestApplication(config, &stubConfigManager{config: config}, &stubCacheManager{cache: newOutfitCachePtr()}, &stubCategoryService{outfitsByPath: map[string][]entities.FileEntry{
 			cliTestCategoryPath("casual"): {{FileName: "one.avatar"}, {FileName: "two.avatar"}},
 		}})
@@ -463,7 +596,7 @@ func TestApplication_ShowAllOutfits_ReturnsAllOutfitsAndErrors(t *testing.T) {
 	})
 
 	t.Run("category service error", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 		wantErr := errors.New("get outfits failed")
 		app := newTestApplication(config, &stubConfigManager{config: config}, &stubCacheManager{cache: newOutfitCachePtr()}, &stubCategoryService{outfitsErr: wantErr})
 
@@ -478,7 +611,7 @@ func TestApplication_WearOutfit_ResetsSessionOnSuccessAndRotationCompletion(t *t
 	outfit := entities.NewOutfitReference("one.avatar", entities.NewCategoryReference("casual", cliTestCategoryPath("casual")))
 
 	t.Run("success", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 		cache := entities.NewOutfitCache()
 		app := newTestApplication(config, &stubConfigManager{config: config}, &stubCacheManager{cache: &cache}, &stubCategoryService{outfitsByPath: map[string][]entities.FileEntry{
 			cliTestCategoryPath("casual"): {{FileName: "one.avatar"}, {FileName: "two.avatar"}},
@@ -499,7 +632,7 @@ func TestApplication_WearOutfit_ResetsSessionOnSuccessAndRotationCompletion(t *t
 	})
 
 	t.Run("rotation complete", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 		cache := entities.NewOutfitCache()
 		cache.Categories["casual"] = entities.CategoryCache{WornOutfits: map[string]bool{"two.avatar": true}, TotalOutfits: 2}
 		app := newTestApplication(config, &stubConfigManager{config: config}, &stubCacheManager{cache: &cache}, &stubCategoryService{outfitsByPath: map[string][]entities.FileEntry{
@@ -522,7 +655,7 @@ func TestApplication_WearOutfit_ResetsSessionOnSuccessAndRotationCompletion(t *t
 }
 
 func TestApplication_WearOutfit_DoesNotResetSessionForNonRotationError(t *testing.T) {
-	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 	outfit := entities.NewOutfitReference("one.avatar", entities.NewCategoryReference("casual", cliTestCategoryPath("casual")))
 	wantErr := errors.New("get outfits failed")
 	app := newTestApplication(config, &stubConfigManager{config: config}, &stubCacheManager{cache: newOutfitCachePtr()}, &stubCategoryService{outfitsErr: wantErr})
@@ -543,7 +676,7 @@ func TestApplication_WearOutfit_DoesNotResetSessionForNonRotationError(t *testin
 
 func TestApplication_ResetCategory_DeletesCategorySessionAndErrors(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 		cache := entities.NewOutfitCache()
 		cache.Categories["casual"] = entities.CategoryCache{WornOutfits: map[string]bool{"one.avatar": true}, TotalOutfits: 1}
 		app := newTestApplication(config, &stubConfigManager{config: config}, &stubCacheManager{cache: &cache}, &stubCategoryService{})
@@ -559,7 +692,7 @@ func TestApplication_ResetCategory_DeletesCategorySessionAndErrors(t *testing.T)
 	})
 
 	t.Run("use case error", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 		wantErr := errors.New("cache load failed")
 		app := newTestApplication(config, &stubConfigManager{config: config}, &stubCacheManager{err: wantErr}, &stubCategoryService{})
 		markCategoryShown(app, "casual", "one.avatar")
@@ -576,7 +709,7 @@ func TestApplication_ResetCategory_DeletesCategorySessionAndErrors(t *testing.T)
 
 func TestApplication_ResetAllCategories_ResetsAllSessionTrackingAndErrors(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 		cache := entities.NewOutfitCache()
 		cache.Categories["casual"] = entities.CategoryCache{WornOutfits: map[string]bool{"one.avatar": true}, TotalOutfits: 1}
 		app := newTestApplication(config, &stubConfigManager{config: config}, &stubCacheManager{cache: &cache}, &stubCategoryService{})
@@ -593,7 +726,7 @@ func TestApplication_ResetAllCategories_ResetsAllSessionTrackingAndErrors(t *tes
 	})
 
 	t.Run("use case error", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 		wantErr := errors.New("save failed")
 		app := newTestApplication(config, &stubConfigManager{config: config}, &stubCacheManager{saveErr: wantErr}, &stubCategoryService{})
 		markGlobalShown(app, "casual/one.avatar")
@@ -621,7 +754,7 @@ func TestApplication_ShowNextUniqueRandomOutfit_Branches(t *testing.T) {
 	})
 
 	t.Run("category info error", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 		wantErr := errors.New("scan failed")
 		app := newTestApplication(config, &stubConfigManager{config: config}, &stubCacheManager{cache: newOutfitCachePtr()}, &stubCategoryService{scanCategoriesErr: wantErr})
 
@@ -632,7 +765,7 @@ func TestApplication_ShowNextUniqueRandomOutfit_Branches(t *testing.T) {
 	})
 
 	t.Run("state error", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 		categorySvc := &stubCategoryService{
 			scanCategoriesResult: []entities.CategoryInfo{
 				entities.NewCategoryInfo(entities.NewCategoryReference("casual", cliTestCategoryPath("casual")), entities.CategoryStateHasOutfits, 1),
@@ -648,7 +781,7 @@ func TestApplication_ShowNextUniqueRandomOutfit_Branches(t *testing.T) {
 	})
 
 	t.Run("no available outfits returns nil", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 		categorySvc := &stubCategoryService{
 			scanCategoriesResult: []entities.CategoryInfo{
 				entities.NewCategoryInfo(entities.NewCategoryReference("empty", cliTestCategoryPath("empty")), entities.CategoryStateEmpty, 0),
@@ -666,7 +799,7 @@ func TestApplication_ShowNextUniqueRandomOutfit_Branches(t *testing.T) {
 	})
 
 	t.Run("single available outfit does not call randomInt", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 		categorySvc := &stubCategoryService{
 			scanCategoriesResult: []entities.CategoryInfo{
 				entities.NewCategoryInfo(entities.NewCategoryReference("casual", cliTestCategoryPath("casual")), entities.CategoryStateHasOutfits, 1),
@@ -691,7 +824,7 @@ func TestApplication_ShowNextUniqueRandomOutfit_Branches(t *testing.T) {
 	})
 
 	t.Run("resets global shown when all have been seen", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 		categorySvc := &stubCategoryService{
 			scanCategoriesResult: []entities.CategoryInfo{
 				entities.NewCategoryInfo(entities.NewCategoryReference("casual", cliTestCategoryPath("casual")), entities.CategoryStateHasOutfits, 2),
@@ -719,7 +852,7 @@ func TestApplication_ShowNextUniqueRandomOutfit_Branches(t *testing.T) {
 }
 
 func TestApplication_ShowNextUniqueRandomOutfitFrom_ReturnsNilForNoAvailableOutfits(t *testing.T) {
-	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 	cache := entities.NewOutfitCache()
 	cache.Categories["casual"] = entities.CategoryCache{WornOutfits: map[string]bool{"one.avatar": true}, TotalOutfits: 1}
 	app := newTestApplication(config, &stubConfigManager{config: config}, &stubCacheManager{cache: &cache}, &stubCategoryService{outfitsByPath: map[string][]entities.FileEntry{
@@ -821,7 +954,7 @@ func TestApplication_HelperFunctions(t *testing.T) {
 	})
 
 	t.Run("resetAfterWear clears session and category entry", func(t *testing.T) {
-		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+		config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 		app := newTestApplication(config, &stubConfigManager{config: config}, &stubCacheManager{cache: newOutfitCachePtr()}, &stubCategoryService{})
 		markGlobalShown(app, "casual/one.avatar")
 		markCategoryShown(app, "casual", "one.avatar")
@@ -865,6 +998,20 @@ func (s *stubConfigManager) Save(config *entities.Config) error {
 	s.config = config
 	return nil
 }
+func (s *stubConfigManager) Replace(config *entities.Config) error { return s.Save(config) }
+func (s *stubConfigManager) Update(change func(config *entities.Config) error) error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.config == nil {
+		return domainerrors.ErrConfigurationNotFound
+	}
+	updated := *s.config
+	if err := change(&updated); err != nil {
+		return err
+	}
+	return s.Save(&updated)
+}
 func (s *stubConfigManager) Delete() error {
 	s.deleteCalls++
 	if s.deleteErr != nil {
@@ -895,6 +1042,18 @@ func (s *stubCacheManager) Save(cache *entities.OutfitCache) error {
 	s.cache = cache
 	return nil
 }
+func (s *stubCacheManager) Replace(cache *entities.OutfitCache) error { return s.Save(cache) }
+func (s *stubCacheManager) Update(change func(cache *entities.OutfitCache) error) error {
+	current, err := s.LoadOrCreate()
+	if err != nil {
+		return err
+	}
+	updated := *current
+	if err := change(&updated); err != nil {
+		return err
+	}
+	return s.Save(&updated)
+}
 func (s *stubCacheManager) Delete() error {
 	s.deleteCalls++
 	if s.deleteErr != nil {
@@ -904,6 +1063,38 @@ func (s *stubCacheManager) Delete() error {
 	return nil
 }
 
+type stubKnownWardrobe struct {
+	known *entities.KnownWardrobe
+}
+
+func (s *stubKnownWardrobe) Load() (*entities.KnownWardrobe, error) {
+	return s.known, nil
+}
+func (s *stubKnownWardrobe) Update(change func(current *entities.KnownWardrobe) (*entities.KnownWardrobe, error)) error {
+	updated, err := change(s.known)
+	if err != nil || updated == nil {
+		return err
+	}
+	s.known = updated
+	return nil
+}
+
+type stubWearQueue struct {
+	queue *entities.WearQueue
+}
+
+func (s *stubWearQueue) Load() (*entities.WearQueue, error) {
+	return s.queue, nil
+}
+func (s *stubWearQueue) Update(change func(current *entities.WearQueue) (*entities.WearQueue, error)) error {
+	updated, err := change(s.queue)
+	if err != nil || updated == nil {
+		return err
+	}
+	s.queue = updated
+	return nil
+}
+
 type stubCategoryService struct {
 	scanCategoriesResult []entities.CategoryInfo
 	scanCategoriesErr    error
@@ -958,3 +1149,312 @@ func newOutfitCachePtr() *entities.OutfitCache {
 func stringPtr(value string) *string { return &value }
 
 var _ usecases.ConfigManager = (*stubConfigManager)(nil)
+
+type recordingOutfitInstaller struct {
+	sources  []string
+	restored []entities.ActivationSlot
+}
+
+func (r *recordingOutfitInstaller) Install(sourcePath string, _ entities.ActivationSlot) error {
+	r.sources = append(r.sources, sourcePath)
+	return nil
+}
+
+func (r *recordingOutfitInstaller) Restore(slot entities.ActivationSlot) error {
+	r.restored = append(r.restored, slot)
+	return nil
+}
+
+func TestApplication_ActivateOutfitAndRestoreSlot(t *testing.T) {
+	outfit := entities.NewOutfitReference("one.avatar", entities.NewCategoryReference("casual", cliTestCategoryPath("casual")))
+	slot := entities.ActivationSlot{Target: "/outfitpicker-test/game/current.avatar", Mode: entities.ActivationModeCopy}
+	config, err := mustTestConfig(t, cliTestOutfitRoot, nil).WithSlot("game", slot)
+	if err != nil {
+		t.Fatalf("WithSlot() error = %v", err)
+	}
+	categories := &stubCategoryService{outfitsByPath: map[string][]entities.FileEntry{
+		cliTestCategoryPath("casual"): {{FileName: "one.avatar"}},
+	}}
+
+	t.Run("unavailable without installer", func(t *testing.T) {
+		app := newTestApplication(config, &stubConfigManager{config: config}, &stubCacheManager{cache: newOutfitCachePtr()}, categories)
+
+		if _, err := app.ActivateOutfit(outfit, "game"); !errors.Is(err, errActivationUnavailable) {
+			t.Fatalf("ActivateOutfit() error = %v, want %v", err, errActivationUnavailable)
+		}
+		if _, err := app.RestoreSlot("game"); !errors.Is(err, errActivationUnavailable) {
+			t.Fatalf("RestoreSlot() error = %v, want %v", err, errActivationUnavailable)
+		}
+	})
+
+	t.Run("installs through the use case", func(t *testing.T) {
+		installer := &recordingOutfitInstaller{}
+		app := buildApplication(config, RuntimeDependencies{
+			ConfigManager: &stubConfigManager{config: config},
+			CacheManager:  &stubCacheManager{cache: newOutfitCachePtr()},
+			CategorySvc:   categories,
+			Installer:     installer,
+		})
+
+		got, err := app.ActivateOutfit(outfit, "game")
+		if err != nil || got != slot {
+			t.Fatalf("ActivateOutfit() = %#v, %v, want %#v", got, err, slot)
+		}
+		if len(installer.sources) != 1 || installer.sources[0] != outfit.FilePath() {
+			t.Fatalf("installed sources = %v, want %s", installer.sources, outfit.FilePath())
+		}
+		if got, err := app.RestoreSlot("game"); err != nil || got != slot || len(installer.restored) != 1 {
+			t.Fatalf("RestoreSlot() = %#v, %v, restored %v", got, err, installer.restored)
+		}
+	})
+}
+
+type recordingStorageSelector struct {
+	selected []entities.StorageBackend
+}
+
+func (r *recordingStorageSelector) SelectStorage(backend entities.StorageBackend) error {
+	r.selected = append(r.selected, backend)
+	return nil
+}
+
+func TestApplication_SelectStorage(t *testing.T) {
+	config := mustTestConfig(t, cliTestOutfitRoot, nil)
+	app := newTestApplication(config, &stubConfigManager{config: config}, &stubCacheManager{cache: newOutfitCachePtr()}, &stubCategoryService{})
+	if err := app.SelectStorage(entities.StorageSQLite); !errors.Is(err, errStorageSelectionUnavailable) {
+		t.Fatalf("SelectStorage() error = %v, want %v", err, errStorageSelectionUnavailable)
+	}
+
+	storage := &recordingStorageSelector{}
+	app = buildApplication(config, RuntimeDependencies{
+		ConfigManager: &stubConfigManager{config: config},
+		CacheManager:  &stubCacheManager{cache: newOutfitCachePtr()},
+		CategorySvc:   &stubCategoryService{},
+		Storage:       storage,
+	})
+	if err := app.SelectStorage(entities.StorageSQLite); err != nil || len(storage.selected) != 1 {
+		t.Fatalf("SelectStorage() = %v, selected %v; want it forwarded", err, storage.selected)
+	}
+}
+
+type stubJournal struct {
+	events []entities.JournalEvent
+}
+
+func (s *stubJournal) Events() ([]entities.JournalEvent, error) {
+	return s.events, nil
+}
+
+func TestApplication_Journal(t *testing.T) {
+	config := mustTestConfig(t, cliTestOutfitRoot, nil)
+	app := newTestApplication(config, &stubConfigManager{config: config}, &stubCacheManager{cache: newOutfitCachePtr()}, &stubCategoryService{})
+	if _, err := app.RestoreAt(time.Now()); !errors.Is(err, errJournalUnavailable) {
+		t.Fatalf("RestoreAt() error = %v, want %v", err, errJournalUnavailable)
+	}
+	if _, err := app.RebuildCache(); !errors.Is(err, errJournalUnavailable) {
+		t.Fatalf("RebuildCache() error = %v, want %v", err, errJournalUnavailable)
+	}
+
+	recorded := *config
+	journal := &stubJournal{events: []entities.JournalEvent{
+		{Type: entities.JournalBaseline, At: time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC)},
+		{Type: entities.JournalConfigUpdate, At: time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC), Config: &recorded},
+		{Type: entities.JournalWear, At: time.Date(2026, 9, 1, 10, 0, 0, 0, time.UTC), Category: "shoes", Outfit: "a.avatar", TotalOutfits: 2},
+	}}
+	cacheManager := &stubCacheManager{cache: newOutfitCachePtr()}
+	app = buildApplication(config, RuntimeDependencies{
+		ConfigManager: &stubConfigManager{config: config},
+		CacheManager:  cacheManager,
+		CategorySvc:   &stubCategoryService{},
+		Journal:       journal,
+	})
+	app.session.MarkCategoryShown("b.avatar", "shoes")
+
+	state, err := app.RestoreAt(time.Date(2026, 9, 1, 23, 0, 0, 0, time.UTC))
+	if err != nil || state.Config == nil || !state.Cache.Categories["shoes"].WornOutfits["a.avatar"] {
+		t.Fatalf("RestoreAt() = %+v, %v; want the recorded state", state, err)
+	}
+	if app.session.IsCategoryShown("b.avatar", "shoes") {
+		t.Fatal("RestoreAt() kept session-shown outfits")
+	}
+	if _, err := app.RestoreAt(time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC)); !errors.Is(err, domainerrors.ErrNothingToRestore) {
+		t.Fatalf("RestoreAt() before the journal error = %v, want %v", err, domainerrors.ErrNothingToRestore)
+	}
+	if cache, err := app.RebuildCache(); err != nil || len(cache.Categories["shoes"].WornOutfits) != 1 {
+		t.Fatalf("RebuildCache() = %+v, %v; want the replayed cache", cache, err)
+	}
+}
+
+type stubBackupRepository struct {
+	snapshots []string
+	roots     []string
+	createErr error
+	listErr   error
+	opened    entities.BackupContents
+}
+
+func (s *stubBackupRepository) Create(kind entities.BackupKind, reason string, contents entities.BackupContents, path string) (entities.BackupInfo, error) {
+	if s.createErr != nil {
+		return entities.BackupInfo{}, s.createErr
+	}
+	if kind == entities.BackupSnapshot {
+		s.snapshots = append(s.snapshots, reason)
+	}
+	s.roots = append(s.roots, contents.Config.Root)
+	return entities.BackupInfo{Name: string(kind), Path: path, Kind: kind, Reason: reason}, nil
+}
+
+func (s *stubBackupRepository) List() ([]entities.BackupInfo, error) { return nil, s.listErr }
+
+func (s *stubBackupRepository) Open(name string) (entities.BackupInfo, entities.BackupContents, error) {
+	return entities.BackupInfo{Name: name}, s.opened, nil
+}
+
+func (s *stubBackupRepository) Delete(entities.BackupInfo) error { return nil }
+
+func TestApplication_Backups(t *testing.T) {
+	config := mustTestConfig(t, cliTestOutfitRoot, nil)
+	app := newTestApplication(config, &stubConfigManager{config: config}, &stubCacheManager{}, &stubCategoryService{})
+	if _, err := app.CreateBackup(""); !errors.Is(err, errBackupsUnavailable) {
+		t.Fatalf("CreateBackup() error = %v, want %v", err, errBackupsUnavailable)
+	}
+	if _, err := app.ListBackups(); !errors.Is(err, errBackupsUnavailable) {
+		t.Fatalf("ListBackups() error = %v, want %v", err, errBackupsUnavailable)
+	}
+	if _, _, err := app.RestoreBackup("backup"); !errors.Is(err, errBackupsUnavailable) {
+		t.Fatalf("RestoreBackup() error = %v, want %v", err, errBackupsUnavailable)
+	}
+	if _, err := app.PruneBackups(1); !errors.Is(err, errBackupsUnavailable) {
+		t.Fatalf("PruneBackups() error = %v, want %v", err, errBackupsUnavailable)
+	}
+
+	restored := *config
+	repo := &stubBackupRepository{opened: entities.BackupContents{Config: &restored}}
+	app = buildApplication(config, RuntimeDependencies{
+		ConfigManager: &stubConfigManager{config: config},
+		CacheManager:  &stubCacheManager{},
+		CategorySvc:   &stubCategoryService{},
+		Backups:       repo,
+	})
+	if backup, err := app.CreateBackup("/tmp/wardrobe.tar.gz"); err != nil || backup.Path != "/tmp/wardrobe.tar.gz" {
+		t.Fatalf("CreateBackup() = %+v, %v", backup, err)
+	}
+	if _, err := app.ListBackups(); err != nil {
+		t.Fatalf("ListBackups() error = %v", err)
+	}
+	if _, err := app.PruneBackups(1); err != nil {
+		t.Fatalf("PruneBackups() error = %v", err)
+	}
+	app.session.MarkCategoryShown("a.avatar", "shoes")
+	if _, _, err := app.RestoreBackup("backup"); err != nil {
+		t.Fatalf("RestoreBackup() error = %v", err)
+	}
+	if app.session.IsCategoryShown("a.avatar", "shoes") {
+		t.Fatal("RestoreBackup() kept session-shown outfits")
+	}
+	if !reflect.DeepEqual(repo.snapshots, []string{"backup-restore"}) {
+		t.Fatalf("snapshots = %v, want one before the restore", repo.snapshots)
+	}
+}
+
+func TestApplication_SnapshotsBeforeDestructiveChanges(t *testing.T) {
+	config := mustTestConfig(t, cliTestOutfitRoot, nil)
+	repo := &stubBackupRepository{}
+	configManager := &stubConfigManager{config: config}
+	var warnings []error
+	app := buildApplication(config, RuntimeDependencies{
+		ConfigManager: configManager,
+		CacheManager:  &stubCacheManager{},
+		CategorySvc:   &stubCategoryService{},
+		Journal:       &stubJournal{events: []entities.JournalEvent{{Type: entities.JournalConfigUpdate, Config: config}}},
+		Backups:       repo,
+		ReportWarning: func(err error) { warnings = append(warnings, err) },
+	})
+
+	if err := app.ResetCategory("shoes"); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.ResetAllCategories(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.RestoreAt(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.UpdateConfiguration(func(current *entities.Config) (*entities.Config, error) {
+		next := *current
+		next.Language = "fr"
+		return &next, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	movedRoot := cliTestHomeTempDir(t, "outfitpicker-moved-*")
+	if err := app.UpdateConfiguration(func(current *entities.Config) (*entities.Config, error) {
+		next := *current
+		next.Root = movedRoot
+		return &next, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.FactoryReset(); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.FactoryReset(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"reset", "reset-all", "restore", "root-change", "factory-reset"}
+	if !reflect.DeepEqual(repo.snapshots, want) {
+		t.Fatalf("snapshots = %v, want %v", repo.snapshots, want)
+	}
+	if repo.roots[3] != cliTestOutfitRoot || repo.roots[4] != movedRoot {
+		t.Fatalf("snapshot roots = %v, want the root before the change, then the new one", repo.roots)
+	}
+	if len(warnings) != 0 {
+		t.Fatalf("warnings = %v, want none", warnings)
+	}
+
+	repo.listErr = errors.New("permission denied")
+	configManager.config = config
+	if err := app.ResetAllCategories(); err != nil {
+		t.Fatalf("ResetAllCategories() with unpruned snapshots error = %v, want the reset to go ahead", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Error(), "could not take a reset-all snapshot: permission denied") {
+		t.Fatalf("warnings = %v, want the failed pruning reported", warnings)
+	}
+}
+
+func TestApplication_FailedSnapshotStopsDestructiveChanges(t *testing.T) {
+	config := mustTestConfig(t, cliTestOutfitRoot, nil)
+	configManager := &stubConfigManager{config: config}
+	cacheManager := &stubCacheManager{}
+	app := buildApplication(config, RuntimeDependencies{
+		ConfigManager: configManager,
+		CacheManager:  cacheManager,
+		CategorySvc:   &stubCategoryService{},
+		Journal:       &stubJournal{events: []entities.JournalEvent{{Type: entities.JournalConfigUpdate, Config: config}}},
+		Backups:       &stubBackupRepository{createErr: errors.New("disk full")},
+	})
+	wantErr := func(name string, err error) {
+		t.Helper()
+		if err == nil || !strings.Contains(err.Error(), "snapshot: disk full") {
+			t.Fatalf("%s() error = %v, want the failed snapshot", name, err)
+		}
+	}
+
+	wantErr("FactoryReset", app.FactoryReset())
+	if configManager.deleteCalls != 0 || cacheManager.deleteCalls != 0 || configManager.config != config {
+		t.Fatal("FactoryReset() deleted state without a snapshot")
+	}
+	wantErr("ResetCategory", app.ResetCategory("shoes"))
+	wantErr("ResetAllCategories", app.ResetAllCategories())
+	_, err := app.RestoreAt(time.Now())
+	wantErr("RestoreAt", err)
+	wantErr("UpdateConfiguration", app.UpdateConfiguration(func(current *entities.Config) (*entities.Config, error) {
+		next := *current
+		next.Root = cliTestHomeTempDir(t, "outfitpicker-moved-*")
+		return &next, nil
+	}))
+	if configManager.config.Root != cliTestOutfitRoot {
+		t.Fatalf("root = %q after a failed snapshot, want it unchanged", configManager.config.Root)
+	}
+}
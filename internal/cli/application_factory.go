@@ -2,6 +2,7 @@ package cli
 
 import (
 	"math/rand"
+	"time"
 
 	"github.com/dh85/outfitpicker/internal/application/usecases"
 	"github.com/dh85/outfitpicker/internal/domain/entities"
@@ -9,12 +10,23 @@ import (
 )
 
 type RuntimeDependencies struct {
-	ConfigManager usecases.ConfigManager
-	CacheManager  usecases.CacheManager
-	CategorySvc   interfaces.CategoryService
-	RandomInt     func(int) int
-	ConfigExists  func() bool
-	PathProvider  StoragePathProvider
+	ConfigManager    usecases.ConfigManager
+	CacheManager     usecases.CacheManager
+	CategorySvc      interfaces.CategoryService
+	Installer        interfaces.OutfitInstaller
+	HookRunner       interfaces.HookRunner
+	SelectionPlugins interfaces.SelectionPluginRunner
+	ReportWarning    func(error)
+	RandomInt        func(int) int
+	ConfigExists     func() bool
+	PathProvider     StoragePathProvider
+	Storage          interfaces.StorageSelector
+	WearHistory      interfaces.WearHistoryRepository
+	Journal          interfaces.Journal
+	Backups          interfaces.BackupRepository
+	Hasher           interfaces.ContentHasher
+	KnownWardrobe    interfaces.KnownWardrobeRepository
+	WearQueue        interfaces.WearQueueRepository
 }
 
 type Application struct {
@@ -23,8 +35,19 @@ type Application struct {
 	commands     OutfitCommandHandler
 	randomInt    func(int) int
 	selection    RandomOutfitSelector
+	activation   *usecases.ActivateOutfitUseCase
+	hooks        *hookDispatcher
 	session      *OutfitSession
 	pathProvider StoragePathProvider
+	storage      interfaces.StorageSelector
+	wearHistory  interfaces.WearHistoryRepository
+	journal      *usecases.JournalUseCase
+	backups      *usecases.BackupUseCase
+	snapshots    *snapshotter
+	tracker      *outfitTracker
+	reconciler   *cacheReconciler
+	changes      *changeDetector
+	queue        *wearQueue
 }
 
 func buildApplication(config *entities.Config, deps RuntimeDependencies) *Application {
@@ -35,16 +58,52 @@ func buildApplication(config *entities.Config, deps RuntimeDependencies) *Applic
 	session := NewOutfitSession()
 	wardrobe := usecases.NewWardrobeQueries(deps.ConfigManager, deps.CacheManager, deps.CategorySvc)
 	configController := NewSessionConfigController(config, deps.ConfigManager, deps.CacheManager, session)
+	hooks := newHookDispatcher(deps.HookRunner, deps.ConfigManager, deps.ReportWarning)
 	commands := NewSessionCommandHandler(deps.CategorySvc, deps.ConfigManager, deps.CacheManager, session)
+	commands.hooks = hooks
 	app := &Application{
 		wardrobe:     wardrobe,
 		config:       configController,
 		commands:     commands,
 		randomInt:    randomInt,
 		session:      session,
+		hooks:        hooks,
 		pathProvider: deps.PathProvider,
+		storage:      deps.Storage,
+		wearHistory:  deps.WearHistory,
 	}
-	app.selection = NewRuntimeSelectionService(
+	if deps.Journal != nil {
+		app.journal = usecases.NewJournalUseCase(deps.Journal, deps.ConfigManager, deps.CacheManager)
+	}
+	if deps.Backups != nil {
+		app.backups = usecases.NewBackupUseCase(deps.Backups, deps.ConfigManager, deps.CacheManager)
+		app.snapshots = newSnapshotter(app.backups, deps.ReportWarning)
+		configController.snapshots = app.snapshots
+		commands.snapshots = app.snapshots
+	}
+	app.reconciler = &cacheReconciler{
+		reconcile: usecases.NewReconcileCacheUseCase(deps.ConfigManager, deps.CacheManager, deps.CategorySvc),
+		snapshots: app.snapshots,
+	}
+	app.changes = &changeDetector{
+		detect: usecases.NewWardrobeChangesUseCase(deps.ConfigManager, deps.CacheManager, deps.CategorySvc, deps.KnownWardrobe),
+	}
+	configController.changes = app.changes.detect
+	app.queue = &wearQueue{
+		queue: usecases.NewWearQueueUseCase(deps.ConfigManager, deps.CategorySvc, deps.KnownWardrobe, deps.WearQueue),
+		now:   time.Now,
+		wear:  commands.wearOutfitAt,
+	}
+	configController.queue = app.queue.queue
+	commands.queue = app.queue
+	if deps.Hasher != nil {
+		app.tracker = newOutfitTracker(usecases.NewOutfitIdentityUseCase(deps.ConfigManager, deps.CacheManager, deps.CategorySvc, deps.Hasher), deps.ReportWarning)
+		commands.tracker = app.tracker
+	}
+	if deps.Installer != nil {
+		app.activation = usecases.NewActivateOutfitUseCase(deps.CategorySvc, deps.ConfigManager, deps.Installer)
+	}
+	selection := NewRuntimeSelectionService(
 		deps.CategorySvc,
 		deps.ConfigManager,
 		deps.CacheManager,
@@ -56,5 +115,10 @@ func buildApplication(config *entities.Config, deps RuntimeDependencies) *Applic
 			return app.randomInt(length)
 		},
 	)
+	selection.plugins = deps.SelectionPlugins
+	selection.report = deps.ReportWarning
+	selection.hooks = hooks
+	selection.known = deps.KnownWardrobe
+	app.selection = selection
 	return app
 }
@@ -2,11 +2,13 @@ package cli
 
 import (
 	"errors"
+	"time"
 
 	"github.com/dh85/outfitpicker/internal/application/usecases"
 	"github.com/dh85/outfitpicker/internal/domain/entities"
 	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
 	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+	"github.com/dh85/outfitpicker/internal/domain/logic"
 )
 
 type SessionConfigController struct {
@@ -14,6 +16,9 @@ type SessionConfigController struct {
 	configManager usecases.ConfigManager
 	cacheManager  usecases.CacheManager
 	session       *OutfitSession
+	snapshots     *snapshotter
+	changes       *usecases.WardrobeChangesUseCase
+	queue         *usecases.WearQueueUseCase
 }
 
 func NewSessionConfigController(current *entities.Config, configManager usecases.ConfigManager, cacheManager usecases.CacheManager, session *OutfitSession) *SessionConfigController {
@@ -32,23 +37,93 @@ func (c *SessionConfigController) GetConfiguration() (*entities.Config, error) {
 	return config, nil
 }
 
-func (c *SessionConfigController) UpdateConfiguration(config *entities.Config) error {
-	currentRoot := ""
-	if c.current != nil {
-		currentRoot = c.current.Root
-	}
-	rootChanged := currentRoot != "" && currentRoot != config.Root
+// UpdateConfiguration applies change to the stored configuration in one
+// locked read-modify-write, so edits made by another process in the meantime
+// are not overwritten. Worn history is kept per wardrobe root: moving the
+// root puts the current rotation aside and brings back the one last used with
+// the new root, after snapshotting both together with the previous
+// configuration.
+func (c *SessionConfigController) UpdateConfiguration(change ConfigChange) error {
+	return c.updateConfiguration(change, false)
+}
+
+// UpdateConfigurationMigratingHistory is UpdateConfiguration for a wardrobe
+// that has moved: if the root changes, the current rotation goes with it.
+func (c *SessionConfigController) UpdateConfigurationMigratingHistory(change ConfigChange) error {
+	return c.updateConfiguration(change, true)
+}
 
-	if err := c.configManager.Save(config); err != nil {
+func (c *SessionConfigController) updateConfiguration(change ConfigChange, migrateHistory bool) error {
+	var previous entities.Config
+	var updated *entities.Config
+	err := c.configManager.Update(func(config *entities.Config) error {
+		next, err := change(config)
+		if err != nil {
+			return err
+		}
+		if config.Root != "" && config.Root != next.Root {
+			if err := c.snapshots.take(usecases.SnapshotRootChange, config); err != nil {
+				return err
+			}
+		}
+		previous = *config
+		updated = next
+		*config = *next
+		return nil
+	})
+	if err != nil {
 		return err
 	}
-	if rootChanged {
-		if err := c.cacheManager.Delete(); err != nil {
+	// The cache is rewritten for the new root after the config lock is
+	// released rather than inside it: the two stores are locked separately
+	// everywhere else, and holding one while waiting for the other would
+	// invite a lock-order deadlock. The snapshot only reads the cache, so it
+	// is taken under the lock and a failed one leaves the root unchanged. A
+	// rewrite that fails here is returned, and the snapshot holds the
+	// configuration and cache from before the change, so the switch can be
+	// undone with backup restore.
+	if previous.Root != "" && previous.Root != updated.Root {
+		from, to := logic.CanonicalRoot(previous.Root), logic.CanonicalRoot(updated.Root)
+		// Outfits of another root are keyed by that root as it was
+		// configured, so one that becomes the first root has its outfits
+		// keyed afresh rather than left behind.
+		promoted := ""
+		others := previous.WardrobeRoots()[1:]
+		if index := wardrobeRootIndex(others, updated.Root); index >= 0 {
+			promoted = others[index]
+		}
+		err := c.cacheManager.Update(func(cache *entities.OutfitCache) error {
+			if migrateHistory {
+				*cache = cache.MovingRoot(to, promoted)
+			} else {
+				*cache = cache.SwitchingRoot(from, to, promoted)
+			}
+			return nil
+		})
+		if err != nil {
 			return err
 		}
+		// Another wardrobe, or one whose outfits are keyed afresh, is
+		// recorded afresh on the next run rather than announced as new, and
+		// wears queued for the old one are not applied to it.
+		if (!migrateHistory || promoted != "") && c.changes != nil {
+			if err := c.changes.Forget(); err != nil {
+				return err
+			}
+		}
+		if c.queue != nil {
+			if !migrateHistory {
+				_, err = c.queue.Clear()
+			} else if promoted != "" {
+				err = c.queue.PromoteRoot(promoted)
+			}
+			if err != nil {
+				return err
+			}
+		}
 		c.session.ResetAll()
 	}
-	c.current = config
+	c.current = updated
 	return nil
 }
 
@@ -57,43 +132,85 @@ type SessionCommandHandler struct {
 	configManager usecases.ConfigManager
 	cacheManager  usecases.CacheManager
 	session       *OutfitSession
+	hooks         *hookDispatcher
+	snapshots     *snapshotter
+	tracker       *outfitTracker
+	queue         *wearQueue
 }
 
 func NewSessionCommandHandler(categorySvc interfaces.CategoryService, configManager usecases.ConfigManager, cacheManager usecases.CacheManager, session *OutfitSession) *SessionCommandHandler {
 	return &SessionCommandHandler{categorySvc: categorySvc, configManager: configManager, cacheManager: cacheManager, session: session}
 }
 
+// WearOutfit marks outfit worn. While the wardrobe is offline it is queued
+// instead, and ErrWearQueued is returned.
 func (h *SessionCommandHandler) WearOutfit(outfit entities.OutfitReference) error {
-	err := usecases.NewWearOutfitUseCase(h.categorySvc, h.configManager, h.cacheManager).Execute(outfit)
+	if h.queue.isOffline() {
+		return h.queue.add(outfit)
+	}
+	return h.wearOutfitAt(outfit, time.Now())
+}
+
+// wearOutfitAt marks outfit worn as of the time at.
+func (h *SessionCommandHandler) wearOutfitAt(outfit entities.OutfitReference, at time.Time) error {
+	err := usecases.NewWearOutfitUseCase(h.categorySvc, h.configManager, h.cacheManager).ExecuteAt(outfit, at)
 	if err == nil {
 		h.session.ResetAll()
+		h.tracker.remember(outfit)
+		h.hooks.notifyOutfit(entities.HookEventPostWear, outfit)
 		return nil
 	}
 
 	var rotationCompleted *domainerrors.RotationCompletedError
 	if errors.As(err, &rotationCompleted) {
 		h.session.ResetAll()
+		h.tracker.remember(outfit)
+		h.hooks.notifyOutfit(entities.HookEventPostWear, outfit)
+		h.hooks.notifyCategory(entities.HookEventRotationCompleted, rotationCompleted.Category)
 	}
 	return err
 }
 
 func (h *SessionCommandHandler) ResetCategory(categoryName string) error {
-	if err := usecases.NewResetCategoryUseCase(h.configManager, h.cacheManager).Execute(categoryName); err != nil {
+	return h.resetCategory(categoryName, func(reset *usecases.ResetCategoryUseCase) error {
+		return reset.Execute(categoryName)
+	})
+}
+
+func (h *SessionCommandHandler) ResetCategoryIfUnchanged(categoryName, wornRevision string) error {
+	return h.resetCategory(categoryName, func(reset *usecases.ResetCategoryUseCase) error {
+		return reset.ExecuteIfUnchanged(categoryName, wornRevision)
+	})
+}
+
+func (h *SessionCommandHandler) resetCategory(categoryName string, execute func(*usecases.ResetCategoryUseCase) error) error {
+	if err := h.snapshots.take(usecases.SnapshotReset, nil); err != nil {
+		return err
+	}
+	if err := execute(usecases.NewResetCategoryUseCase(h.configManager, h.cacheManager)); err != nil {
 		return err
 	}
 	h.session.ResetCategory(categoryName)
+	h.hooks.notifyCategory(entities.HookEventReset, categoryName)
 	return nil
 }
 
 func (h *SessionCommandHandler) ResetAllCategories() error {
+	if err := h.snapshots.take(usecases.SnapshotResetAll, nil); err != nil {
+		return err
+	}
 	if err := usecases.NewResetCategoryUseCase(h.configManager, h.cacheManager).ExecuteAll(); err != nil {
 		return err
 	}
 	h.session.ResetAll()
+	h.hooks.notifyCategory(entities.HookEventReset, "")
 	return nil
 }
 
 func (h *SessionCommandHandler) FactoryReset() error {
+	if err := h.snapshots.take(usecases.SnapshotFactoryReset, nil); err != nil {
+		return err
+	}
 	if err := h.configManager.Delete(); err != nil {
 		return err
 	}
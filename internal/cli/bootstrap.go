@@ -4,6 +4,7 @@ import (
 	stderrors "errors"
 	"fmt"
 
+	"github.com/dh85/outfitpicker/internal/application/usecases"
 	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
 )
 
@@ -19,16 +20,66 @@ func LoadApplicationFromExistingConfig(deps RuntimeDependencies) (*Application,
 		return nil, domainerrors.ErrConfigurationNotFound
 	}
 
-	return buildApplication(config, deps), nil
+	// Everything checked while loading shares one scan of the wardrobe. An
+	// unreadable wardrobe is shown as last recorded, queuing wears, and
+	// nothing is checked against it until it is back.
+	scan := usecases.NewLoadScan(deps.CategorySvc)
+	defer scan.Done()
+	deps.CategorySvc = scan
+	offline := wardrobeOffline(deps)
+	if offline {
+		deps.CategorySvc = usecases.NewSnapshotCategoryService(deps.ConfigManager, deps.KnownWardrobe)
+	}
+	app := buildApplication(config, deps)
+	if offline {
+		app.queue.offline = true
+		return app, nil
+	}
+	app.checkOnLoad(deps.ReportWarning)
+	return app, nil
+}
+
+// checkOnLoad makes the checks against the wardrobe that run when the
+// application loads, in order: renamed outfits are matched before queued
+// wears are applied, and both before reconciling drops the outfits that are
+// gone. Each keeps what it found for later, and a failure is reported with
+// report without stopping the application or the checks after it.
+func (app *Application) checkOnLoad(report func(error)) {
+	checks := []struct {
+		failure string
+		run     func() error
+	}{
+		{"could not match renamed outfits", app.tracker.relink},
+		{"could not apply wears queued while offline", app.queue.apply},
+		{"could not reconcile worn outfits with the wardrobe", func() error {
+			_, err := app.reconciler.run()
+			return err
+		}},
+		{"could not look for new outfits", app.changes.run},
+	}
+	for _, check := range checks {
+		reportLoadFailure(report, check.failure, check.run())
+	}
+}
+
+// reportLoadFailure reports err, if a check made while loading failed with
+// it, as failure without stopping the application from loading.
+func reportLoadFailure(report func(error), failure string, err error) {
+	if err != nil && report != nil {
+		report(fmt.Errorf("%s: %w", failure, err))
+	}
 }
 
+// CreateApplicationFromConfiguration saves the configuration set up from
+// configuration in place of any stored one, readable or not: setup only runs
+// when there is none, or once the user chose to replace it.
 func CreateApplicationFromConfiguration(configuration Configuration, deps RuntimeDependencies) (*Application, error) {
 	config, err := configuration.BuildConfig()
 	if err != nil {
 		return nil, err
 	}
 
-	if err := deps.ConfigManager.Save(config); err != nil {
+	if err := deps.ConfigManager.Replace(config); err != nil {
 		return nil, err
 	}
 
@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/dh85/outfitpicker/internal/application/usecases"
 	"github.com/dh85/outfitpicker/internal/domain/entities"
 	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
 	"github.com/dh85/outfitpicker/internal/infrastructure/system"
@@ -43,6 +44,87 @@ func TestLoadApplicationFromExistingConfig_PropagatesLoadError(t *testing.T) {
 	}
 }
 
+// countingCategoryService counts how often each root is scanned and each
+// category listed.
+type countingCategoryService struct {
+	stubCategoryService
+	scans    map[string]int
+	listings map[string]int
+}
+
+func (s *countingCategoryService) ScanCategories(rootPath string, excludedCategories map[string]bool) ([]entities.CategoryInfo, error) {
+	s.scans[rootPath]++
+	return s.stubCategoryService.ScanCategories(rootPath, excludedCategories)
+}
+
+func (s *countingCategoryService) GetOutfits(categoryPath string) ([]entities.FileEntry, error) {
+	s.listings[categoryPath]++
+	return s.stubCategoryService.GetOutfits(categoryPath)
+}
+
+func TestLoadApplicationFromExistingConfig_ScansTheWardrobeOnce(t *testing.T) {
+	config := mustTestConfig(t, cliTestOutfitRoot, nil)
+	casual := entities.NewCategoryReference("casual", cliTestCategoryPath("casual"))
+	categorySvc := &countingCategoryService{
+		stubCategoryService: stubCategoryService{
+			scanCategoriesResult: []entities.CategoryInfo{entities.NewCategoryInfo(casual, entities.CategoryStateHasOutfits, 1)},
+			outfitsByPath:        map[string][]entities.FileEntry{casual.Path: {{FileName: "one.avatar"}}},
+		},
+		scans:    map[string]int{},
+		listings: map[string]int{},
+	}
+	recorded := entities.NewKnownWardrobe(map[string][]string{"casual": {"one.avatar"}}, nil)
+	app, err := LoadApplicationFromExistingConfig(RuntimeDependencies{
+		ConfigManager: &stubConfigManager{config: config},
+		CacheManager:  &stubCacheManager{cache: newOutfitCachePtr()},
+		CategorySvc:   categorySvc,
+		KnownWardrobe: &stubKnownWardrobe{known: &recorded},
+	})
+	if err != nil {
+		t.Fatalf("LoadApplicationFromExistingConfig() error = %v", err)
+	}
+	if categorySvc.scans[cliTestOutfitRoot] != 1 || categorySvc.listings[casual.Path] != 1 {
+		t.Fatalf("scans = %v, listings = %v; want the wardrobe read once while loading", categorySvc.scans, categorySvc.listings)
+	}
+
+	if _, err := app.GetCategoryInfo(); err != nil {
+		t.Fatalf("GetCategoryInfo() error = %v", err)
+	}
+	if categorySvc.scans[cliTestOutfitRoot] != 2 {
+		t.Errorf("scans after loading = %v, want the wardrobe read again", categorySvc.scans)
+	}
+}
+
+func TestApplication_CheckOnLoad_ReportsEachFailure(t *testing.T) {
+	configManager := &stubConfigManager{err: errors.New("config unreadable")}
+	app := buildApplication(nil, RuntimeDependencies{
+		ConfigManager: configManager,
+		CacheManager:  &stubCacheManager{},
+		KnownWardrobe: &stubKnownWardrobe{},
+		WearQueue:     &stubWearQueue{queue: &entities.WearQueue{Wears: []entities.QueuedWear{{Category: "casual", FileName: "one.avatar"}}}},
+	})
+	app.tracker = newOutfitTracker(usecases.NewOutfitIdentityUseCase(configManager, &stubCacheManager{}, nil, nil), nil)
+	var reported []string
+
+	app.checkOnLoad(func(err error) { reported = append(reported, err.Error()) })
+
+	want := []string{
+		"could not match renamed outfits: config unreadable",
+		"could not apply wears queued while offline: config unreadable",
+		"could not reconcile worn outfits with the wardrobe: config unreadable",
+		"could not look for new outfits: config unreadable",
+	}
+	if !reflect.DeepEqual(reported, want) {
+		t.Fatalf("reported = %v, want %v", reported, want)
+	}
+	if _, err := app.OutfitRenames(); err == nil {
+		t.Error("OutfitRenames() error = nil, want the failure from loading")
+	}
+	if _, err := app.WardrobeChanges(); err == nil {
+		t.Error("WardrobeChanges() error = nil, want the failure from loading")
+	}
+}
+
 func TestBootstrapPicker_MissingConfigStartsSetup(t *testing.T) {
 	config := &Configuration{OutfitPath: cliTestOutfitRoot, Language: "en"}
 	recorder := &messageRecorder{}
@@ -299,7 +381,7 @@ func TestDefaultConfigFileExists_ReturnsFalseAndTrue(t *testing.T) {
 		t.Fatal("defaultConfigFileExists() = true, want false before saving config")
 	}
 
-	config, err := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), map[string]bool{"formal": true}, nil, nil)
+	config, err := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), map[string]bool{"formal": true})
 	if err != nil {
 		t.Fatalf("NewConfig() error = %v", err)
 	}
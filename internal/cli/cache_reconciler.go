@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"github.com/dh85/outfitpicker/internal/application/usecases"
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+)
+
+// cacheReconciler keeps the worn outfit cache in line with the wardrobe,
+// taking a snapshot before it drops any worn outfit so that it can be
+// restored. Corrected outfit counts are not worth a snapshot, which would
+// push older ones out of rotation.
+type cacheReconciler struct {
+	reconcile *usecases.ReconcileCacheUseCase
+	snapshots *snapshotter
+	found     []entities.CategoryReconciliation
+}
+
+// run reconciles the cache and returns everything reconciled so far,
+// including when the application loaded.
+func (r *cacheReconciler) run() (entities.CacheReconciliation, error) {
+	check, err := r.reconcile.Check()
+	if err != nil || !check.Changed() {
+		check.Categories = r.found
+		return check, err
+	}
+	if check.DropsWornOutfits() {
+		if err := r.snapshots.take(usecases.SnapshotReconcile, nil); err != nil {
+			check.Categories = r.found
+			return check, err
+		}
+	}
+	result, err := r.reconcile.Execute()
+	if err != nil {
+		return result, err
+	}
+	r.found = append(r.found, result.Categories...)
+	result.Categories = r.found
+	return result, nil
+}
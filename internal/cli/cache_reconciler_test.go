@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/application/usecases"
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+)
+
+func TestCacheReconciler_SnapshotsOnlyBeforeDroppingWornOutfits(t *testing.T) {
+	tests := []struct {
+		name      string
+		category  entities.CategoryCache
+		snapshots []string
+	}{
+		{name: "count correction", category: entities.NewCategoryCache(2)},
+		{name: "worn outfit dropped", category: entities.NewCategoryCache(2).Adding("a.avatar"), snapshots: []string{usecases.SnapshotReconcile}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := mustTestConfig(t, cliTestOutfitRoot, nil)
+			configManager := &stubConfigManager{config: config}
+			cache := entities.NewOutfitCache().Updating("casual", tt.category)
+			cacheManager := &stubCacheManager{cache: &cache}
+			repo := &stubBackupRepository{}
+			reconciler := &cacheReconciler{
+				reconcile: usecases.NewReconcileCacheUseCase(configManager, cacheManager, &stubCategoryService{}),
+				snapshots: newSnapshotter(usecases.NewBackupUseCase(repo, configManager, cacheManager), nil),
+			}
+
+			result, err := reconciler.run()
+			if err != nil || len(result.Categories) != 1 || !result.Categories[0].Removed {
+				t.Fatalf("run() = %+v, %v; want casual dropped", result, err)
+			}
+			if !reflect.DeepEqual(repo.snapshots, tt.snapshots) {
+				t.Fatalf("snapshots = %v, want %v", repo.snapshots, tt.snapshots)
+			}
+		})
+	}
+}
+
+func TestCacheReconciler_FailedSnapshotKeepsWornOutfits(t *testing.T) {
+	config := mustTestConfig(t, cliTestOutfitRoot, nil)
+	configManager := &stubConfigManager{config: config}
+	cache := entities.NewOutfitCache().Updating("casual", entities.NewCategoryCache(2).Adding("a.avatar"))
+	cacheManager := &stubCacheManager{cache: &cache}
+	repo := &stubBackupRepository{createErr: errors.New("disk full")}
+	reconciler := &cacheReconciler{
+		reconcile: usecases.NewReconcileCacheUseCase(configManager, cacheManager, &stubCategoryService{}),
+		snapshots: newSnapshotter(usecases.NewBackupUseCase(repo, configManager, cacheManager), nil),
+	}
+
+	if _, err := reconciler.run(); err == nil {
+		t.Fatal("run() error = nil, want the failed snapshot")
+	}
+	if !cacheManager.cache.Categories["casual"].WornOutfits["a.avatar"] {
+		t.Fatal("run() dropped worn outfits without a snapshot")
+	}
+}
@@ -1,9 +1,11 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
 )
 
 type CategoryMenu struct {
@@ -17,6 +19,8 @@ type CategoryMenu struct {
 
 func (m CategoryMenu) terminal() Console { return consoleOrDefault(m.console) }
 
+const staleWornHistoryMessage = "Worn outfits were changed in another outfitpicker session. Showing the latest state."
+
 type categoryMenuAction int
 
 const (
@@ -54,7 +58,15 @@ func (m CategoryMenu) Show() menuTransition {
 	input := m.terminal().Prompt(categoryMenuPrompt(view.defaultAction))
 	switch resolveCategoryMenuAction(input, view.exhausted) {
 	case categoryMenuActionResetAndPick:
-		if err := m.outfitService.ResetCategory(m.category.Name); err != nil {
+		// The reset is refused if another session has changed this
+		// category's worn outfits since they were shown, so the menu is
+		// redrawn from fresh state instead of resetting unseen history.
+		err := m.outfitService.ResetCategoryIfUnchanged(m.category.Name, state.WornRevision)
+		if errors.Is(err, domainerrors.ErrStaleRevision) {
+			m.terminal().Warning(staleWornHistoryMessage)
+			return categoryMenuTransition(m.category)
+		}
+		if err != nil {
 			m.terminal().Error(fmt.Sprintf("Error: %v", err))
 			return exitMenuTransition()
 		}
@@ -85,7 +97,7 @@ func (m CategoryMenu) handleOutfitLoop() menuTransition {
 		result := m.presentation.PresentOutfitWithChoice(*outfit)
 		switch result {
 		case OutfitChoiceWorn:
-			m.terminal().Success("Marked as worn. Goodbye!")
+			m.terminal().Success(wornGoodbye(m.outfitService))
 			return exitMenuTransition()
 		case OutfitChoiceSkipped:
 			continue
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
 )
 
 func TestBuildCategoryMenuView_NormalCategory(t *testing.T) {
@@ -194,6 +195,23 @@ func TestCategoryMenu_Show(t *testing.T) {
 		assertWearRequested(t, picker, "one.avatar")
 	})
 
+	t.Run("exhausted reset redraws when worn outfits changed elsewhere", func(t *testing.T) {
+		picker := newStubRuntime()
+		picker.wardrobe.outfitState = categoryMenuState("casual", []string{"one.avatar"}, nil, []string{"one.avatar"}).WithWornRevision("shown")
+		picker.commands.resetCategoryErr = domainerrors.NewStaleCategoryError("casual")
+		var output strings.Builder
+		menu := newCategoryMenuForTest(picker, "casual", nil)
+		menu.console = TerminalConsole{stdin: strings.NewReader("r\n"), stdout: &output, stderr: &output}
+
+		assertMenuTransition(t, menuDestinationCategory, menu.Show)
+
+		if len(picker.commands.resetRevisions) != 1 || picker.commands.resetRevisions[0] != "shown" {
+			t.Fatalf("reset revisions = %v, want the shown revision", picker.commands.resetRevisions)
+		}
+		assertNoCategoryRandomRequested(t, picker)
+		assertOutputContains(t, output.String(), staleWornHistoryMessage)
+	})
+
 	t.Run("invalid choice re-prompts with next-step hint", func(t *testing.T) {
 		picker := newStubRuntime()
 		picker.wardrobe.outfitState = categoryMenuState("casual", []string{"one.avatar"}, []string{"one.avatar"}, nil)
@@ -0,0 +1,20 @@
+package cli
+
+import (
+	"github.com/dh85/outfitpicker/internal/application/usecases"
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+)
+
+// changeDetector finds what was added to or removed from the wardrobe since
+// the last run, keeping it for the main menu and the changes command.
+type changeDetector struct {
+	detect *usecases.WardrobeChangesUseCase
+	found  entities.WardrobeChanges
+	err    error
+}
+
+// run looks for changes and keeps them.
+func (d *changeDetector) run() error {
+	d.found, d.err = d.detect.Execute()
+	return d.err
+}
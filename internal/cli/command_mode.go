@@ -4,29 +4,44 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"math/rand"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
+	"github.com/dh85/outfitpicker/internal/domain/validation"
 )
 
 type CommandRuntime interface {
 	WardrobeReader
 	ConfigurationController
 	OutfitCommandHandler
+	OutfitActivator
 	RandomOutfitSelector
 	StoragePathProvider
+	StorageSelector
+	JournalRestorer
+	BackupManager
+	OutfitRenameTracker
+	CacheReconciler
+	WardrobeChangeReporter
+	WearQueue
+	WearHistoryReader
 }
 
-var commandRandomIndex = rand.Intn
-
 // ExecuteCommand runs a non-interactive command. It returns handled=false when
 // args should fall through to the interactive menu.
 func ExecuteCommand(args []string, runtime CommandRuntime, console Console) (handled bool, exitCode int) {
+	return ExecuteCommandWithOptions(args, runtime, console, GlobalOptions{})
+}
+
+// ExecuteCommandWithOptions runs a non-interactive command with global options
+// already extracted by ParseGlobalOptions.
+func ExecuteCommandWithOptions(args []string, runtime CommandRuntime, console Console, options GlobalOptions) (handled bool, exitCode int) {
 	if len(args) == 0 {
 		return false, 0
 	}
@@ -46,15 +61,50 @@ func ExecuteCommand(args []string, runtime CommandRuntime, console Console) (han
 	if done {
 		return true, code
 	}
+	if flag := cli.misplacedFlag(); flag != "" {
+		console.Error(fmt.Sprintf("%s must come before the command", flag))
+		return true, 2
+	}
 	if runtime == nil {
 		return false, 0
 	}
 
 	commands := commandExecutor{
-		runtime: runtime,
-		service: NewOutfitService(runtime, runtime, runtime),
-		console: console,
+		runtime:   runtime,
+		service:   NewOutfitService(runtime, runtime, runtime),
+		console:   console,
+		previewer: NewOutfitPreviewer(options, console),
+	}
+	if err := ctx.Run(&commands); err != nil {
+		return true, commandExitCode(err, console)
+	}
+	return true, 0
+}
+
+// ExecuteSetupCommand runs a profile or init command, which manage profiles
+// and set up wardrobes before any profile is loaded. It returns handled=false
+// for other commands.
+func ExecuteSetupCommand(args []string, setup SetupRuntime, console Console) (handled bool, exitCode int) {
+	if len(args) == 0 || (args[0] != "profile" && args[0] != "init") {
+		return false, 0
+	}
+	console = consoleOrDefault(console)
+	cli := commandCLI{}
+	parser, err := newCommandParser(&cli, console)
+	if err != nil {
+		console.Error(fmt.Sprintf("Failed to initialize command parser: %v", err))
+		return true, 1
+	}
+
+	ctx, code, done := parseCommandContext(parser, args, console)
+	if done {
+		return true, code
 	}
+	if flag := cli.misplacedFlag(); flag != "" {
+		console.Error(fmt.Sprintf("%s must come before the command", flag))
+		return true, 2
+	}
+	commands := commandExecutor{profiles: setup.Profiles, wardrobes: setup.Wardrobes, console: console}
 	if err := ctx.Run(&commands); err != nil {
 		return true, commandExitCode(err, console)
 	}
@@ -62,12 +112,72 @@ func ExecuteCommand(args []string, runtime CommandRuntime, console Console) (han
 }
 
 type commandCLI struct {
-	Pick   pickCommand   `cmd:"" help:"Pick a random outfit and optionally mark it worn."`
-	List   listCommand   `cmd:"" help:"List categories or outfit rotation state."`
-	Reset  resetCommand  `cmd:"" help:"Reset worn outfit rotation state."`
-	Config configCommand `cmd:"" help:"Show or update configuration."`
-	Paths  pathsCommand  `cmd:"" help:"Show config, cache, and wardrobe paths."`
-	Doctor doctorCommand `cmd:"" help:"Check configuration, wardrobe, and cache health."`
+	globalFlags `embed:""`
+
+	Pick     pickCommand     `cmd:"" help:"Pick a random outfit and optionally mark it worn."`
+	Activate activateCommand `cmd:"" help:"Install an outfit into an activation slot."`
+	List     listCommand     `cmd:"" help:"List categories or outfit rotation state."`
+	Reset    resetCommand    `cmd:"" help:"Reset worn outfit rotation state."`
+	Config   configCommand   `cmd:"" help:"Show or update configuration."`
+	Paths    pathsCommand    `cmd:"" help:"Show config, cache, and wardrobe paths."`
+	Doctor   doctorCommand   `cmd:"" help:"Check configuration, wardrobe, and cache health."`
+	Restore  restoreCommand  `cmd:"" help:"Restore config and worn outfits as they were at an earlier date."`
+	Backup   backupCommand   `cmd:"" help:"Create, list, restore, or prune backups of config and worn outfits."`
+	Cache    cacheCommand    `cmd:"" help:"Check worn outfits against the wardrobe."`
+	Changes  changesCommand  `cmd:"" help:"Show categories and outfits added or removed since the last run."`
+	Queue    queueCommand    `cmd:"" help:"List or clear wears queued while the wardrobe is offline."`
+	History  historyCommand  `cmd:"" help:"List every recorded wear, newest first (sqlite storage only)."`
+	Stats    statsCommand    `cmd:"" help:"Count the recorded wears of each outfit (sqlite storage only)."`
+	Profile  profileCommand  `cmd:"" help:"Create, list, copy, or delete profiles, each with its own config and worn outfits."`
+	Init     initCommand     `cmd:"" help:"Set up a wardrobe without the interactive setup, optionally keeping its state inside it."`
+}
+
+type initCommand struct {
+	Root     string `arg:"" optional:"" help:"Wardrobe root directory. Defaults to the current directory." placeholder:"ROOT"`
+	Portable bool   `help:"Keep config and worn outfits in the wardrobe's .outfitpicker directory so they travel with it."`
+	Language string `help:"Language of a new configuration." placeholder:"CODE"`
+}
+
+func (c initCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.initWardrobe(strings.TrimSpace(c.Root), c.Portable, strings.TrimSpace(c.Language)))
+}
+
+type profileCommand struct {
+	Create profileCreateCommand `cmd:"" help:"Create an empty profile; it is set up the first time it is used."`
+	List   profileListCommand   `cmd:"" help:"List profiles, marking the one in use."`
+	Copy   profileCopyCommand   `cmd:"" help:"Create a profile from a copy of another's config and worn outfits."`
+	Delete profileDeleteCommand `cmd:"" help:"Delete a profile with its config, worn outfits, and backups."`
+}
+
+type profileCreateCommand struct {
+	Name string `arg:"" help:"Name of the new profile." placeholder:"NAME"`
+}
+
+func (c profileCreateCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.profileCreate(strings.TrimSpace(c.Name)))
+}
+
+type profileListCommand struct{}
+
+func (c profileListCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.profileList())
+}
+
+type profileCopyCommand struct {
+	From string `arg:"" help:"Profile to copy." placeholder:"FROM"`
+	To   string `arg:"" help:"Name of the new profile." placeholder:"TO"`
+}
+
+func (c profileCopyCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.profileCopy(strings.TrimSpace(c.From), strings.TrimSpace(c.To)))
+}
+
+type profileDeleteCommand struct {
+	Name string `arg:"" help:"Profile to delete." placeholder:"NAME"`
+}
+
+func (c profileDeleteCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.profileDelete(strings.TrimSpace(c.Name)))
 }
 
 type pickCommand struct {
@@ -75,12 +185,23 @@ type pickCommand struct {
 	IncludeExcluded bool   `help:"Include categories excluded from global random selection."`
 	MarkWorn        bool   `help:"Mark the picked outfit worn without prompting." xor:"mark-mode"`
 	NoMark          bool   `help:"Do not mark the picked outfit worn." xor:"mark-mode"`
+	Activate        string `help:"Install the picked outfit into the named activation slot." placeholder:"SLOT"`
 }
 
 func (c pickCommand) Run(executor *commandExecutor) error {
 	return commandExit(executor.pick(pickOptionsFromCommand(c)))
 }
 
+type activateCommand struct {
+	Outfit  string `arg:"" optional:"" help:"Outfit to activate, as CATEGORY/FILE or a file name." placeholder:"OUTFIT"`
+	Slot    string `required:"" help:"Activation slot to install into." placeholder:"SLOT"`
+	Restore bool   `help:"Roll the slot back to the previously active outfit."`
+}
+
+func (c activateCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.activate(strings.TrimSpace(c.Outfit), strings.TrimSpace(c.Slot), c.Restore))
+}
+
 type listCommand struct {
 	Categories listCategoriesCommand `cmd:"" help:"List wardrobe categories."`
 	Worn       listWornCommand       `cmd:"" help:"List outfits already worn."`
@@ -114,9 +235,21 @@ func (c resetCommand) Run(executor *commandExecutor) error {
 }
 
 type configCommand struct {
-	Get     configGetCommand     `cmd:"" help:"Show current configuration."`
-	SetRoot configSetRootCommand `cmd:"" name:"set-root" help:"Set the wardrobe root directory."`
-	Exclude configExcludeCommand `cmd:"" help:"Add categories to the exclusion list."`
+	Get                  configGetCommand                  `cmd:"" help:"Show current configuration."`
+	SetRoot              configSetRootCommand              `cmd:"" name:"set-root" help:"Set the wardrobe root directory."`
+	AddRoot              configAddRootCommand              `cmd:"" name:"add-root" help:"Read outfits from another wardrobe root as well."`
+	RemoveRoot           configRemoveRootCommand           `cmd:"" name:"remove-root" help:"Stop reading outfits from an extra wardrobe root."`
+	Exclude              configExcludeCommand              `cmd:"" help:"Add categories to the exclusion list."`
+	SetSlot              configSetSlotCommand              `cmd:"" name:"set-slot" help:"Add or update an activation slot."`
+	RemoveSlot           configRemoveSlotCommand           `cmd:"" name:"remove-slot" help:"Remove an activation slot."`
+	SetHook              configSetHookCommand              `cmd:"" name:"set-hook" help:"Run an executable when an event occurs."`
+	RemoveHook           configRemoveHookCommand           `cmd:"" name:"remove-hook" help:"Remove the hook for an event."`
+	SetSelectionPlugin   configSetSelectionPluginCommand   `cmd:"" name:"set-selection-plugin" help:"Rank outfits with an external executable instead of picking at random."`
+	ClearSelectionPlugin configClearSelectionPluginCommand `cmd:"" name:"clear-selection-plugin" help:"Go back to picking outfits at random."`
+	SetStorage           configSetStorageCommand           `cmd:"" name:"set-storage" help:"Move config and worn outfit history to JSON files or an SQLite database."`
+	SetIdentity          configSetIdentityCommand          `cmd:"" name:"set-identity" help:"Match worn outfits to their files by name, or by content so renamed and moved files keep their worn state."`
+	SetNewArrivals       configSetNewArrivalsCommand       `cmd:"" name:"set-new-arrivals" help:"Pick outfits added since an earlier run before the rest, or like any other outfit."`
+	SetSymlinks          configSetSymlinksCommand          `cmd:"" name:"set-symlinks" help:"Follow wardrobe roots and categories that are symbolic links, or reject them."`
 }
 
 type pathsCommand struct{}
@@ -125,10 +258,107 @@ func (c pathsCommand) Run(executor *commandExecutor) error {
 	return commandExit(executor.paths())
 }
 
-type doctorCommand struct{}
+type doctorCommand struct {
+	Repair bool `help:"Rebuild an unreadable cache file from the journal."`
+}
 
 func (c doctorCommand) Run(executor *commandExecutor) error {
-	return commandExit(executor.doctor())
+	return commandExit(executor.doctor(c.Repair))
+}
+
+type restoreCommand struct {
+	At string `required:"" help:"Date (YYYY-MM-DD, through the end of that day) or RFC 3339 time to restore." placeholder:"DATE"`
+}
+
+func (c restoreCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.restore(strings.TrimSpace(c.At)))
+}
+
+type changesCommand struct{}
+
+func (c changesCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.changes())
+}
+
+type queueCommand struct {
+	List  queueListCommand  `cmd:"" help:"List wears waiting for the wardrobe to be readable again."`
+	Clear queueClearCommand `cmd:"" help:"Drop queued wears without marking them worn."`
+}
+
+type queueListCommand struct{}
+
+func (c queueListCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.queueList())
+}
+
+type queueClearCommand struct{}
+
+func (c queueClearCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.queueClear())
+}
+
+type historyCommand struct {
+	Category string `help:"Only list wears from this category." placeholder:"NAME"`
+	Limit    int    `help:"How many wears to list; 0 lists them all." default:"20" placeholder:"N"`
+}
+
+func (c historyCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.history(c.Category, c.Limit))
+}
+
+type statsCommand struct {
+	Category string `help:"Only count wears from this category." placeholder:"NAME"`
+}
+
+func (c statsCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.stats(c.Category))
+}
+
+type cacheCommand struct {
+	Reconcile cacheReconcileCommand `cmd:"" help:"Drop worn outfits whose files were deleted and correct outfit totals."`
+}
+
+type cacheReconcileCommand struct{}
+
+func (c cacheReconcileCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.cacheReconcile())
+}
+
+type backupCommand struct {
+	Create  backupCreateCommand  `cmd:"" help:"Save config and worn outfits to a backup archive."`
+	List    backupListCommand    `cmd:"" help:"List backups and automatic snapshots, newest first."`
+	Restore backupRestoreCommand `cmd:"" help:"Replace config and worn outfits with a backup."`
+	Prune   backupPruneCommand   `cmd:"" help:"Delete old backups and snapshots."`
+}
+
+type backupCreateCommand struct {
+	Path string `arg:"" optional:"" help:"Archive to write instead of one in the backups directory." placeholder:"PATH"`
+}
+
+func (c backupCreateCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.backupCreate(strings.TrimSpace(c.Path)))
+}
+
+type backupListCommand struct{}
+
+func (c backupListCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.backupList())
+}
+
+type backupRestoreCommand struct {
+	Name string `arg:"" help:"Backup name from backup list, or the path of an archive." placeholder:"NAME"`
+}
+
+func (c backupRestoreCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.backupRestore(strings.TrimSpace(c.Name)))
+}
+
+type backupPruneCommand struct {
+	Keep int `help:"How many backups, and how many snapshots, to keep." default:"5" placeholder:"N"`
+}
+
+func (c backupPruneCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.backupPrune(c.Keep))
 }
 
 type configGetCommand struct{}
@@ -138,11 +368,28 @@ func (c configGetCommand) Run(executor *commandExecutor) error {
 }
 
 type configSetRootCommand struct {
-	Root string `arg:"" help:"New wardrobe root directory." placeholder:"PATH"`
+	Root           string `arg:"" help:"New wardrobe root directory." placeholder:"PATH"`
+	MigrateHistory bool   `help:"Carry worn outfits over to the new root, for a wardrobe that has moved, instead of keeping them for the old one."`
 }
 
 func (c configSetRootCommand) Run(executor *commandExecutor) error {
-	return commandExit(executor.configSetRoot(c.Root))
+	return commandExit(executor.configSetRoot(c.Root, c.MigrateHistory))
+}
+
+type configAddRootCommand struct {
+	Root string `arg:"" help:"Wardrobe root directory to add; its categories are merged with those of the same name." placeholder:"PATH"`
+}
+
+func (c configAddRootCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.configAddRoot(c.Root))
+}
+
+type configRemoveRootCommand struct {
+	Root string `arg:"" help:"Extra wardrobe root directory to remove." placeholder:"PATH"`
+}
+
+func (c configRemoveRootCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.configRemoveRoot(c.Root))
 }
 
 type configExcludeCommand struct {
@@ -153,6 +400,90 @@ func (c configExcludeCommand) Run(executor *commandExecutor) error {
 	return commandExit(executor.configExclude(c.Categories))
 }
 
+type configSetSlotCommand struct {
+	Name   string `arg:"" help:"Slot name." placeholder:"NAME"`
+	Target string `arg:"" help:"File that the other application loads." placeholder:"PATH"`
+	Mode   string `help:"Install by copying the outfit or by symlinking to it." enum:"copy,symlink" default:"copy"`
+}
+
+func (c configSetSlotCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.configSetSlot(c.Name, c.Target, entities.ActivationMode(c.Mode)))
+}
+
+type configRemoveSlotCommand struct {
+	Name string `arg:"" help:"Slot name." placeholder:"NAME"`
+}
+
+func (c configRemoveSlotCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.configRemoveSlot(c.Name))
+}
+
+type configSetHookCommand struct {
+	Timeout time.Duration `help:"Stop the hook after this long (default 10s). Must come before EVENT." placeholder:"DURATION"`
+	Event   string        `arg:"" help:"Event to hook: pre-pick, post-pick, post-wear, rotation-completed, or reset." enum:"pre-pick,post-pick,post-wear,rotation-completed,reset" placeholder:"EVENT"`
+	Command []string      `arg:"" passthrough:"" help:"Executable and arguments to run." placeholder:"COMMAND"`
+}
+
+func (c configSetHookCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.configSetHook(entities.HookEventName(c.Event), c.Command, c.Timeout))
+}
+
+type configRemoveHookCommand struct {
+	Event string `arg:"" help:"Event whose hook to remove." enum:"pre-pick,post-pick,post-wear,rotation-completed,reset" placeholder:"EVENT"`
+}
+
+func (c configRemoveHookCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.configRemoveHook(entities.HookEventName(c.Event)))
+}
+
+type configSetSelectionPluginCommand struct {
+	Timeout time.Duration `help:"Pick at random if the plugin takes longer than this (default 5s). Must come before COMMAND." placeholder:"DURATION"`
+	Command []string      `arg:"" passthrough:"" help:"Executable and arguments to run." placeholder:"COMMAND"`
+}
+
+func (c configSetSelectionPluginCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.configSetSelectionPlugin(c.Command, c.Timeout))
+}
+
+type configClearSelectionPluginCommand struct{}
+
+func (c configClearSelectionPluginCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.configClearSelectionPlugin())
+}
+
+type configSetStorageCommand struct {
+	Backend string `arg:"" help:"Storage backend: json or sqlite." enum:"json,sqlite" placeholder:"BACKEND"`
+}
+
+func (c configSetStorageCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.configSetStorage(entities.StorageBackend(c.Backend)))
+}
+
+type configSetIdentityCommand struct {
+	Identity string `arg:"" help:"Outfit identity: name or content." enum:"name,content" placeholder:"IDENTITY"`
+}
+
+func (c configSetIdentityCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.configSetIdentity(entities.OutfitIdentity(c.Identity)))
+}
+
+type configSetNewArrivalsCommand struct {
+	Policy string `arg:"" help:"New arrivals policy: first or mixed." enum:"first,mixed" placeholder:"POLICY"`
+}
+
+func (c configSetNewArrivalsCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.configSetNewArrivals(c.Policy == "first"))
+}
+
+type configSetSymlinksCommand struct {
+	Policy string   `arg:"" help:"Symlink policy: follow or reject." enum:"follow,reject" placeholder:"POLICY"`
+	Within []string `help:"Only follow links that lead inside this directory. Repeat for more than one." placeholder:"PATH"`
+}
+
+func (c configSetSymlinksCommand) Run(executor *commandExecutor) error {
+	return commandExit(executor.configSetSymlinks(c.Policy == "follow", c.Within))
+}
+
 func newCommandParser(cli *commandCLI, console Console) (*kong.Kong, error) {
 	return kong.New(
 		cli,
@@ -250,9 +581,12 @@ func commandExitCode(err error, console Console) int {
 }
 
 type commandExecutor struct {
-	runtime CommandRuntime
-	service OutfitService
-	console Console
+	runtime   CommandRuntime
+	service   OutfitService
+	console   Console
+	previewer OutfitPreviewer
+	profiles  ProfileManager
+	wardrobes func() (WardrobeInitializer, error)
 }
 
 func (e commandExecutor) pick(options pickOptions) int {
@@ -267,6 +601,11 @@ func (e commandExecutor) pick(options pickOptions) int {
 	}
 
 	e.showPickedOutfit(*outfit)
+	if options.activateSlot != "" {
+		if code := e.activateOutfit(*outfit, options.activateSlot); code != 0 {
+			return code
+		}
+	}
 	shouldMark, ok := e.shouldMarkPickedOutfit(options)
 	if !ok {
 		e.console.Error("Please answer yes or no")
@@ -277,6 +616,10 @@ func (e commandExecutor) pick(options pickOptions) int {
 		return 0
 	}
 	if err := e.service.WearOutfit(*outfit); err != nil {
+		if errors.Is(err, domainerrors.ErrWearQueued) {
+			e.console.Success("Queued to be marked worn once the wardrobe is back")
+			return 0
+		}
 		e.console.Error(fmt.Sprintf("Failed to mark outfit worn: %v", err))
 		return 1
 	}
@@ -284,39 +627,95 @@ func (e commandExecutor) pick(options pickOptions) int {
 	return 0
 }
 
-func (e commandExecutor) pickOutfit(options pickOptions) (*entities.OutfitReference, error) {
-	if options.categoryName != "" {
-		return e.runtime.ShowNextUniqueRandomOutfitFrom(options.categoryName)
+func (e commandExecutor) activate(outfitName, slotName string, restore bool) int {
+	if restore {
+		if outfitName != "" {
+			e.console.Error("Use either an outfit or --restore, not both")
+			return 2
+		}
+		slot, err := e.runtime.RestoreSlot(slotName)
+		if err != nil {
+			e.console.Error(fmt.Sprintf("Failed to restore slot: %v", err))
+			return 1
+		}
+		e.console.Success(fmt.Sprintf("Restored previous outfit in %s", slot.Target))
+		return 0
 	}
-	if options.includeExcluded {
-		return e.pickIncludingExcludedCategories()
+	if outfitName == "" {
+		e.console.Error("Specify an outfit to activate or use --restore")
+		return 2
 	}
-	return e.runtime.ShowNextUniqueRandomOutfit()
+
+	outfit, err := e.findOutfit(outfitName)
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to find outfit: %v", err))
+		return 1
+	}
+	return e.activateOutfit(outfit, slotName)
 }
 
-func (e commandExecutor) pickIncludingExcludedCategories() (*entities.OutfitReference, error) {
-	infos, err := e.service.GetCategoryInfo()
+func (e commandExecutor) activateOutfit(outfit entities.OutfitReference, slotName string) int {
+	slot, err := e.runtime.ActivateOutfit(outfit, slotName)
 	if err != nil {
-		return nil, err
+		e.console.Error(fmt.Sprintf("Failed to activate outfit: %v", err))
+		return 1
 	}
-	sort.Slice(infos, func(i, j int) bool {
-		return infos[i].Category.Name < infos[j].Category.Name
-	})
-	var available []entities.OutfitReference
-	for _, info := range infos {
-		if info.State != entities.CategoryStateHasOutfits && info.State != entities.CategoryStateUserExcluded {
-			continue
+	e.console.Success(fmt.Sprintf("Activated %s in %s", outfit.FileName, slot.Target))
+	return 0
+}
+
+// findOutfit resolves CATEGORY/FILE or a bare file name, with or without the
+// .avatar extension, to a single outfit.
+func (e commandExecutor) findOutfit(name string) (entities.OutfitReference, error) {
+	categoryName, fileName, hasCategory := strings.Cut(name, "/")
+	if !hasCategory {
+		fileName = name
+	}
+	var categories []string
+	if hasCategory {
+		categories = []string{categoryName}
+	} else {
+		infos, err := e.service.GetCategoryInfo()
+		if err != nil {
+			return entities.OutfitReference{}, err
+		}
+		for _, info := range infos {
+			categories = append(categories, info.Category.Name)
 		}
-		outfits, err := e.service.GetAvailableOutfits(info.Category)
+		sort.Strings(categories)
+	}
+
+	var matches []entities.OutfitReference
+	for _, category := range categories {
+		outfits, err := e.service.ShowAllOutfits(category)
 		if err != nil {
-			return nil, err
+			return entities.OutfitReference{}, err
+		}
+		for _, outfit := range outfits {
+			if outfit.FileName == fileName || displayOutfitName(outfit.FileName) == fileName {
+				matches = append(matches, outfit)
+			}
 		}
-		available = append(available, outfits...)
 	}
-	if len(available) == 0 {
-		return nil, nil
+
+	switch len(matches) {
+	case 0:
+		return entities.OutfitReference{}, fmt.Errorf("no outfit named %q", name)
+	case 1:
+		return matches[0], nil
+	default:
+		return entities.OutfitReference{}, fmt.Errorf("%q matches outfits in several categories; use CATEGORY/FILE", name)
+	}
+}
+
+func (e commandExecutor) pickOutfit(options pickOptions) (*entities.OutfitReference, error) {
+	if options.categoryName != "" {
+		return e.runtime.ShowNextUniqueRandomOutfitFrom(options.categoryName)
+	}
+	if options.includeExcluded {
+		return e.runtime.ShowRandomOutfitIncludingExcluded()
 	}
-	return &available[commandRandomIndex(len(available))], nil
+	return e.runtime.ShowNextUniqueRandomOutfit()
 }
 
 func (e commandExecutor) showPickedOutfit(outfit entities.OutfitReference) {
@@ -325,7 +724,11 @@ func (e commandExecutor) showPickedOutfit(outfit entities.OutfitReference) {
 	e.console.Printf("Category: %s\n", sanitizeTerminalText(outfit.Category.Name))
 	e.console.Printf("Outfit:   %s\n", sanitizeTerminalText(outfit.FileName))
 	e.console.Printf("Path:     %s\n", sanitizeTerminalText(outfit.FilePath()))
+	if preview := outfit.PreviewPath(); preview != "" {
+		e.console.Printf("Preview:  %s\n", sanitizeTerminalText(preview))
+	}
 	e.console.Println()
+	printOutfitPreview(e.console, e.previewer, outfit)
 }
 
 func (e commandExecutor) shouldMarkPickedOutfit(options pickOptions) (bool, bool) {
@@ -364,6 +767,10 @@ func (e commandExecutor) listCategories() int {
 		if info.OutfitCount == 1 {
 			outfitWord = "outfit"
 		}
+		if info.State.Failed() {
+			e.console.Printf("%s\t%s\t%d %s\t%s\n", sanitizeTerminalText(info.Category.Name), info.State, info.OutfitCount, outfitWord, categoryHint(info))
+			continue
+		}
 		e.console.Printf("%s\t%s\t%d %s\n", sanitizeTerminalText(info.Category.Name), info.State, info.OutfitCount, outfitWord)
 	}
 	return 0
@@ -435,18 +842,21 @@ func (e commandExecutor) paths() int {
 		return 1
 	}
 
-	wardrobe := "not configured"
+	wardrobes := []string{"not configured"}
 	if config, err := e.service.GetConfiguration(); err == nil && config != nil {
-		wardrobe = config.Root
+		wardrobes = config.WardrobeRoots()
 	}
 
 	e.console.Printf("Config file: %s\n", sanitizeTerminalText(configPath))
 	e.console.Printf("Cache file:  %s\n", sanitizeTerminalText(cachePath))
-	e.console.Printf("Wardrobe:    %s\n", sanitizeTerminalText(wardrobe))
+	e.console.Printf("Wardrobe:    %s\n", sanitizeTerminalText(wardrobes[0]))
+	for _, wardrobe := range wardrobes[1:] {
+		e.console.Printf("             %s\n", sanitizeTerminalText(wardrobe))
+	}
 	return 0
 }
 
-func (e commandExecutor) doctor() int {
+func (e commandExecutor) doctor(repair bool) int {
 	status := 0
 	configPath, err := e.runtime.ConfigFilePath()
 	if err != nil {
@@ -475,7 +885,11 @@ func (e commandExecutor) doctor() int {
 		return 1
 	}
 
-	if _, err := os.Stat(config.Root); err != nil {
+	if len(config.WardrobeRoots()) > 1 {
+		if e.doctorRoots() {
+			status = 1
+		}
+	} else if _, err := os.Stat(config.Root); err != nil {
 		if os.IsNotExist(err) {
 			e.doctorWarning("Wardrobe directory does not exist")
 			status = 1
@@ -486,6 +900,11 @@ func (e commandExecutor) doctor() int {
 	} else {
 		e.doctorOK("Wardrobe directory exists")
 	}
+	e.doctorRootLinks(config.WardrobeRoots())
+	if e.runtime.Offline() {
+		e.doctorWarning("Wardrobe is offline; categories below are as last seen")
+		status = 1
+	}
 
 	infos, err := e.service.GetCategoryInfo()
 	if err != nil {
@@ -505,6 +924,15 @@ func (e commandExecutor) doctor() int {
 			status = 1
 		case entities.CategoryStateUserExcluded:
 			e.doctorWarning(fmt.Sprintf("%s is excluded from random selection", info.Category.Name))
+		default:
+			if info.State.Failed() {
+				e.doctorWarning(fmt.Sprintf("%s can't be read: %s", info.Category.Name, info.Error))
+				e.console.Info(categoryHint(info))
+				status = 1
+			}
+		}
+		if info.LinkTarget != "" {
+			e.doctorOK(fmt.Sprintf("%s is a symbolic link to %s", info.Category.Name, sanitizeTerminalText(info.LinkTarget)))
 		}
 	}
 
@@ -514,84 +942,624 @@ func (e commandExecutor) doctor() int {
 	}
 	if _, err := e.runtime.GetAllOutfitStates(); err != nil {
 		e.doctorError("Cache file is invalid", err)
-		return 1
+		if !repair {
+			e.console.Info("Run 'outfitpicker doctor --repair' to rebuild it from the journal")
+			return 1
+		}
+		cache, err := e.runtime.RebuildCache()
+		if err != nil {
+			e.doctorError("Could not rebuild cache file from the journal", err)
+			return 1
+		}
+		worn := wornOutfitCount(cache)
+		e.doctorOK(fmt.Sprintf("Rebuilt cache file from the journal with %d worn %s", worn, pluralize("outfit", worn)))
+		return status
 	}
 	e.doctorOK("Cache file is valid")
+	if config.MatchesContent() && e.doctorRenames() {
+		status = 1
+	}
 	return status
 }
 
-func (e commandExecutor) doctorOK(message string) {
-	e.console.Success(message)
-}
-
-func (e commandExecutor) doctorWarning(message string) {
-	e.console.Warning(message)
-}
-
-func (e commandExecutor) doctorError(message string, err error) {
-	e.console.Error(fmt.Sprintf("%s: %v", message, err))
+// doctorRootLinks reports the wardrobe roots that go through symbolic links
+// and where they lead.
+func (e commandExecutor) doctorRootLinks(roots []string) {
+	for _, root := range roots {
+		absolute, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		if target, err := validation.EvalSymlinks(absolute); err == nil && target != absolute {
+			e.doctorOK(fmt.Sprintf("Wardrobe root %s is a symbolic link to %s", sanitizeTerminalText(root), sanitizeTerminalText(target)))
+		}
+	}
 }
 
-func (e commandExecutor) configGet() int {
-	config, err := e.service.GetConfiguration()
+// doctorRenames reports the worn outfits found renamed or moved when the
+// wardrobe was loaded, and returns whether they could not be matched.
+func (e commandExecutor) doctorRenames() bool {
+	renames, err := e.runtime.OutfitRenames()
 	if err != nil {
-		e.console.Error(fmt.Sprintf("Failed to load configuration: %v", err))
-		return 1
+		e.doctorError("Could not match renamed outfits", err)
+		return true
 	}
-	e.console.Printf("Root: %s\n", sanitizeTerminalText(config.Root))
-	e.console.Printf("Language: %s\n", sanitizeTerminalText(config.Language))
-	excluded := sortedEnabledKeys(config.ExcludedCategories)
-	if len(excluded) == 0 {
-		e.console.Println("Excluded: none")
-	} else {
-		e.console.Printf("Excluded: %s\n", sanitizeTerminalText(strings.Join(excluded, ", ")))
+	if len(renames) == 0 {
+		e.doctorOK("No renamed or moved outfits found")
+		return false
 	}
-	return 0
+	for _, rename := range renames {
+		e.doctorOK(fmt.Sprintf("Found %s renamed; its worn state moved with it", sanitizeTerminalText(rename.String())))
+	}
+	return false
 }
 
-func (e commandExecutor) configSetRoot(root string) int {
-	config, err := e.service.GetConfiguration()
-	if err != nil {
-		e.console.Error(fmt.Sprintf("Failed to load configuration: %v", err))
-		return 1
-	}
-	expandedRoot, err := expandHomePath(root)
+func (e commandExecutor) restore(at string) int {
+	until, err := parseRestoreTime(at, time.Local)
 	if err != nil {
-		e.console.Error(fmt.Sprintf("Failed to expand path: %v", err))
-		return 1
+		e.console.Error(err.Error())
+		return 2
 	}
-	updated, err := buildUpdatedConfig(config, expandedRoot, config.Language, cloneExcludedCategories(config.ExcludedCategories))
+	state, err := e.runtime.RestoreAt(until)
 	if err != nil {
-		e.console.Error(fmt.Sprintf("Failed to update path: %v", err))
-		return 1
-	}
-	if err := e.service.UpdateConfiguration(updated); err != nil {
-		e.console.Error(fmt.Sprintf("Failed to update path: %v", err))
+		e.console.Error(fmt.Sprintf("Failed to restore: %v", err))
 		return 1
 	}
-	e.console.Success(fmt.Sprintf("Outfit path updated to: %s", expandedRoot))
+	worn := wornOutfitCount(state.Cache)
+	e.console.Success(fmt.Sprintf("Restored config and worn outfits as of %s (%d worn %s)", at, worn, pluralize("outfit", worn)))
 	return 0
 }
 
-func (e commandExecutor) configExclude(categories []string) int {
-	config, err := e.service.GetConfiguration()
+func (e commandExecutor) changes() int {
+	changes, err := e.runtime.WardrobeChanges()
 	if err != nil {
-		e.console.Error(fmt.Sprintf("Failed to load configuration: %v", err))
+		e.console.Error(fmt.Sprintf("Failed to look for changes: %v", err))
 		return 1
 	}
-	excluded := cloneExcludedCategories(config.ExcludedCategories)
-	for _, category := range categories {
-		name := strings.TrimSpace(category)
-		if name != "" {
-			excluded[name] = true
+	if len(changes.Unreadable) > 0 {
+		for _, path := range changes.Unreadable {
+			e.console.Warning(fmt.Sprintf("Could not read %s", path))
 		}
+		e.console.Info("Changes are looked for again once the whole wardrobe can be read")
+		return 1
 	}
-	updated, err := buildUpdatedConfig(config, config.Root, config.Language, excluded)
-	if err != nil {
-		e.console.Error(fmt.Sprintf("Failed to update excluded categories: %v", err))
+	if !changes.Changed() {
+		e.console.Success("No outfits added or removed since last time")
+		return 0
+	}
+	for _, category := range changes.Categories {
+		name := sanitizeTerminalText(category.Category)
+		switch {
+		case category.Added:
+			e.console.Printf("New category %s\n", name)
+		case category.Removed:
+			e.console.Printf("Removed category %s\n", name)
+		}
+		for _, key := range category.AddedOutfits {
+			e.console.Printf("  + %s/%s\n", name, sanitizeTerminalText(key))
+		}
+		for _, key := range category.RemovedOutfits {
+			e.console.Printf("  - %s/%s\n", name, sanitizeTerminalText(key))
+		}
+	}
+	added, removed := changes.AddedCount(), changes.RemovedCount()
+	e.console.Success(fmt.Sprintf("%d %s added and %d removed since last time", added, pluralize("outfit", added), removed))
+	return 0
+}
+
+func (e commandExecutor) queueList() int {
+	if e.runtime.Offline() {
+		e.console.Warning("Wardrobe is offline; wears are queued until it can be read again")
+	}
+	queued, err := e.runtime.QueuedWears()
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to list queued wears: %v", err))
+		return 1
+	}
+	if len(queued) == 0 {
+		e.console.Info("No wears queued")
+		return 0
+	}
+	for _, wear := range queued {
+		e.console.Printf("%s\t%s/%s\n", wear.QueuedAt.Local().Format("2006-01-02 15:04:05"), sanitizeTerminalText(wear.Category), sanitizeTerminalText(wear.Key()))
+	}
+	return 0
+}
+
+func (e commandExecutor) queueClear() int {
+	cleared, err := e.runtime.ClearQueuedWears()
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to clear queued wears: %v", err))
+		return 1
+	}
+	e.console.Success(fmt.Sprintf("Dropped %d queued %s", len(cleared), pluralize("wear", len(cleared))))
+	return 0
+}
+
+func (e commandExecutor) history(category string, limit int) int {
+	if limit < 0 {
+		e.console.Error("--limit cannot be negative")
+		return 2
+	}
+	records, err := e.runtime.WearHistory(category, limit)
+	if err != nil {
+		e.reportWearHistoryError(err)
+		return 1
+	}
+	if len(records) == 0 {
+		e.console.Info("No wears recorded")
+		return 0
+	}
+	for _, record := range records {
+		e.console.Printf("%s\t%s/%s\n", record.WornAt.Local().Format("2006-01-02 15:04:05"), sanitizeTerminalText(record.Category), sanitizeTerminalText(record.Outfit))
+	}
+	return 0
+}
+
+func (e commandExecutor) stats(category string) int {
+	stats, err := e.runtime.WearStats(category)
+	if err != nil {
+		e.reportWearHistoryError(err)
+		return 1
+	}
+	if len(stats) == 0 {
+		e.console.Info("No wears recorded")
+		return 0
+	}
+	for _, stat := range stats {
+		e.console.Printf("%d\t%s/%s\tlast worn %s\n", stat.Wears, sanitizeTerminalText(stat.Category), sanitizeTerminalText(stat.Outfit), stat.LastWorn.Local().Format("2006-01-02"))
+	}
+	return 0
+}
+
+// reportWearHistoryError explains that the JSON files keep no wear history,
+// only the current rotation, and how to start recording it.
+func (e commandExecutor) reportWearHistoryError(err error) {
+	if errors.Is(err, domainerrors.ErrNoWearHistory) {
+		e.console.Error("Wear history is only kept with sqlite storage")
+		e.console.Info("Run 'outfitpicker config set-storage sqlite' to start recording every wear")
+		return
+	}
+	e.console.Error(fmt.Sprintf("Failed to read wear history: %v", err))
+}
+
+func (e commandExecutor) cacheReconcile() int {
+	result, err := e.runtime.ReconcileCache()
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to reconcile cache: %v", err))
+		return 1
+	}
+	if len(result.Unreadable) > 0 {
+		for _, path := range result.Unreadable {
+			e.console.Warning(fmt.Sprintf("Could not read %s", path))
+		}
+		e.console.Info("Worn outfits were left as they are, since outfits there would look deleted")
+		return 1
+	}
+	if !result.Changed() {
+		e.console.Success("Worn outfits match the wardrobe")
+		return 0
+	}
+	for _, category := range result.Categories {
+		name := sanitizeTerminalText(category.Category)
+		switch {
+		case category.Removed:
+			e.console.Printf("%s: no longer in the wardrobe; dropped it with %d worn %s\n", name, len(category.Orphans), pluralize("outfit", len(category.Orphans)))
+			continue
+		case category.OldTotal != category.NewTotal:
+			e.console.Printf("%s: %d %s, was %d\n", name, category.NewTotal, pluralize("outfit", category.NewTotal), category.OldTotal)
+		}
+		if len(category.Orphans) > 0 {
+			e.console.Printf("%s: dropped deleted %s %s\n", name, pluralize("outfit", len(category.Orphans)), sanitizeTerminalText(strings.Join(category.Orphans, ", ")))
+		}
+	}
+	e.console.Success(fmt.Sprintf("Reconciled %d %s with the wardrobe", len(result.Categories), pluralize("category", len(result.Categories))))
+	return 0
+}
+
+func (e commandExecutor) backupCreate(path string) int {
+	backup, err := e.runtime.CreateBackup(path)
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to create backup: %v", err))
+		return 1
+	}
+	e.console.Success(fmt.Sprintf("Created backup %s", sanitizeTerminalText(backup.Path)))
+	return 0
+}
+
+func (e commandExecutor) backupList() int {
+	backups, err := e.runtime.ListBackups()
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to list backups: %v", err))
+		return 1
+	}
+	if len(backups) == 0 {
+		e.console.Info("No backups found")
+		return 0
+	}
+	for _, backup := range backups {
+		kind := string(backup.Kind)
+		if backup.Reason != "" {
+			kind += " before " + backup.Reason
+		}
+		e.console.Printf("%s\t%s\t%s\n", sanitizeTerminalText(backup.Name), backup.CreatedAt.Local().Format("2006-01-02 15:04:05"), sanitizeTerminalText(kind))
+	}
+	return 0
+}
+
+func (e commandExecutor) backupRestore(name string) int {
+	if name == "" {
+		e.console.Error("backup name cannot be empty")
+		return 2
+	}
+	backup, contents, err := e.runtime.RestoreBackup(name)
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to restore backup: %v", err))
+		return 1
+	}
+	worn := 0
+	if contents.Cache != nil {
+		worn = wornOutfitCount(*contents.Cache)
+	}
+	e.console.Success(fmt.Sprintf("Restored config and worn outfits from %s (%d worn %s)", sanitizeTerminalText(backup.Name), worn, pluralize("outfit", worn)))
+	return 0
+}
+
+func (e commandExecutor) backupPrune(keep int) int {
+	if keep < 0 {
+		e.console.Error("--keep cannot be negative")
+		return 2
+	}
+	pruned, err := e.runtime.PruneBackups(keep)
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to prune backups: %v", err))
 		return 1
 	}
-	if err := e.service.UpdateConfiguration(updated); err != nil {
+	e.console.Success(fmt.Sprintf("Deleted %d old %s", len(pruned), pluralize("backup", len(pruned))))
+	return 0
+}
+
+func (e commandExecutor) profileCreate(name string) int {
+	if e.profiles == nil {
+		e.console.Error(fmt.Sprintf("Failed to create profile: %v", errProfilesUnavailable))
+		return 1
+	}
+	profile, err := e.profiles.CreateProfile(name)
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to create profile: %v", err))
+		return 1
+	}
+	e.console.Success(fmt.Sprintf("Created profile %s", sanitizeTerminalText(profile.Name)))
+	e.console.Info(fmt.Sprintf("Run outfitpicker --profile %s to set it up", sanitizeTerminalText(profile.Name)))
+	return 0
+}
+
+func (e commandExecutor) profileList() int {
+	if e.profiles == nil {
+		e.console.Error(fmt.Sprintf("Failed to list profiles: %v", errProfilesUnavailable))
+		return 1
+	}
+	profiles, err := e.profiles.ListProfiles()
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to list profiles: %v", err))
+		return 1
+	}
+	active := e.profiles.ActiveProfile()
+	for _, profile := range profiles {
+		marker := " "
+		if profile.Name == active {
+			marker = "*"
+		}
+		e.console.Printf("%s %s\t%s\n", marker, sanitizeTerminalText(profile.Name), sanitizeTerminalText(profile.Dir))
+	}
+	return 0
+}
+
+func (e commandExecutor) profileCopy(from, to string) int {
+	if e.profiles == nil {
+		e.console.Error(fmt.Sprintf("Failed to copy profile: %v", errProfilesUnavailable))
+		return 1
+	}
+	profile, err := e.profiles.CopyProfile(from, to)
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to copy profile: %v", err))
+		return 1
+	}
+	e.console.Success(fmt.Sprintf("Copied profile %s to %s", sanitizeTerminalText(from), sanitizeTerminalText(profile.Name)))
+	return 0
+}
+
+func (e commandExecutor) profileDelete(name string) int {
+	if e.profiles == nil {
+		e.console.Error(fmt.Sprintf("Failed to delete profile: %v", errProfilesUnavailable))
+		return 1
+	}
+	if err := e.profiles.DeleteProfile(name); err != nil {
+		e.console.Error(fmt.Sprintf("Failed to delete profile: %v", err))
+		return 1
+	}
+	e.console.Success(fmt.Sprintf("Deleted profile %s", sanitizeTerminalText(name)))
+	return 0
+}
+
+// initWardrobe sets the profile up for root, or with portable makes root keep
+// its own state. An empty root is the current directory.
+func (e commandExecutor) initWardrobe(root string, portable bool, language string) int {
+	if e.wardrobes == nil {
+		e.console.Error(fmt.Sprintf("Failed to set up wardrobe: %v", errWardrobeSetupUnavailable))
+		return 1
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to set up wardrobe: %v", err))
+		return 1
+	}
+	if language == "" {
+		language = entities.DefaultLanguage
+	}
+	wardrobes, err := e.wardrobes()
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to set up wardrobe: %v", err))
+		return 1
+	}
+	if !portable {
+		if _, err := wardrobes.Init(root, language); err != nil {
+			e.console.Error(fmt.Sprintf("Failed to set up wardrobe: %v", err))
+			return 1
+		}
+		e.console.Success(fmt.Sprintf("Set up wardrobe %s", sanitizeTerminalText(root)))
+		return 0
+	}
+
+	_, carried, err := wardrobes.InitPortable(root, language)
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to set up wardrobe: %v", err))
+		return 1
+	}
+	stateDir := filepath.Join(root, entities.PortableDirName)
+	e.console.Success(fmt.Sprintf("Made wardrobe %s portable", sanitizeTerminalText(root)))
+	if carried {
+		e.console.Info(fmt.Sprintf("Copied this profile's settings and worn outfits into %s", sanitizeTerminalText(stateDir)))
+	} else {
+		e.console.Info(fmt.Sprintf("Config and worn outfits are kept in %s", sanitizeTerminalText(stateDir)))
+	}
+	e.console.Info("It is used automatically whenever you work with this wardrobe")
+	return 0
+}
+
+// parseRestoreTime reads an RFC 3339 time, or a date meaning the last moment
+// of that day in location.
+func parseRestoreTime(value string, location *time.Location) (time.Time, error) {
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at, nil
+	}
+	day, err := time.ParseInLocation(time.DateOnly, value, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: use YYYY-MM-DD or an RFC 3339 time", value)
+	}
+	return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
+
+func wornOutfitCount(cache entities.OutfitCache) int {
+	count := 0
+	for _, category := range cache.Categories {
+		count += len(category.WornOutfits)
+	}
+	return count
+}
+
+// doctorRoots reports on each root of a wardrobe that has several and
+// returns whether any of them could not be read.
+func (e commandExecutor) doctorRoots() bool {
+	statuses, err := e.runtime.GetRootStatuses()
+	if err != nil {
+		e.doctorError("Could not scan wardrobe roots", err)
+		return true
+	}
+	problem := false
+	for _, root := range statuses {
+		name := sanitizeTerminalText(root.Root)
+		switch {
+		case errors.Is(root.Err, os.ErrNotExist):
+			e.doctorWarning(fmt.Sprintf("Wardrobe directory %s does not exist", name))
+			problem = true
+		case root.Err != nil:
+			e.doctorError(fmt.Sprintf("Wardrobe directory %s is not accessible", name), root.Err)
+			problem = true
+		default:
+			e.doctorOK(fmt.Sprintf("Wardrobe directory %s: %d %s, %d .avatar %s", name,
+				root.Categories, pluralize("category", root.Categories),
+				root.Outfits, pluralize("file", root.Outfits)))
+		}
+	}
+	return problem
+}
+
+func (e commandExecutor) doctorOK(message string) {
+	e.console.Success(message)
+}
+
+func (e commandExecutor) doctorWarning(message string) {
+	e.console.Warning(message)
+}
+
+func (e commandExecutor) doctorError(message string, err error) {
+	e.console.Error(fmt.Sprintf("%s: %v", message, err))
+}
+
+func (e commandExecutor) configGet() int {
+	config, err := e.service.GetConfiguration()
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to load configuration: %v", err))
+		return 1
+	}
+	e.console.Printf("Root: %s\n", sanitizeTerminalText(config.Root))
+	if roots := config.WardrobeRoots(); len(roots) > 1 {
+		e.console.Printf("Extra roots: %s\n", sanitizeTerminalText(strings.Join(roots[1:], ", ")))
+	}
+	e.console.Printf("Language: %s\n", sanitizeTerminalText(config.Language))
+	excluded := sortedEnabledKeys(config.ExcludedCategories)
+	if len(excluded) == 0 {
+		e.console.Println("Excluded: none")
+	} else {
+		e.console.Printf("Excluded: %s\n", sanitizeTerminalText(strings.Join(excluded, ", ")))
+	}
+	storage, err := entities.ParseStorageBackend(string(config.Storage))
+	if err != nil {
+		storage = config.Storage
+	}
+	e.console.Printf("Storage: %s\n", sanitizeTerminalText(string(storage)))
+	identity, err := entities.ParseOutfitIdentity(string(config.Identity))
+	if err != nil {
+		identity = config.Identity
+	}
+	e.console.Printf("Identity: %s\n", sanitizeTerminalText(string(identity)))
+	if config.PreferNewArrivals {
+		e.console.Println("New arrivals: first")
+	} else {
+		e.console.Println("New arrivals: mixed")
+	}
+	switch {
+	case !config.FollowSymlinks:
+		e.console.Println("Symlinks: reject")
+	case len(config.SymlinkRoots) == 0:
+		e.console.Println("Symlinks: follow")
+	default:
+		e.console.Printf("Symlinks: follow (within %s)\n", sanitizeTerminalText(strings.Join(config.SymlinkRoots, ", ")))
+	}
+	if config.SelectionPlugin == nil {
+		e.console.Println("Selection: random")
+	} else {
+		e.console.Printf("Selection: %s (timeout %s)\n", sanitizeTerminalText(config.SelectionPlugin.String()), config.SelectionPlugin.Timeout())
+	}
+	e.printSlots(config.Slots)
+	e.printHooks(config.Hooks)
+	return 0
+}
+
+func (e commandExecutor) printSlots(slots map[string]entities.ActivationSlot) {
+	if len(slots) == 0 {
+		e.console.Println("Slots: none")
+		return
+	}
+	e.console.Println("Slots:")
+	names := make([]string, 0, len(slots))
+	for name := range slots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		slot := slots[name]
+		e.console.Printf("  %s: %s (%s)\n", sanitizeTerminalText(name), sanitizeTerminalText(slot.Target), slot.Mode)
+	}
+}
+
+func (e commandExecutor) printHooks(hooks map[entities.HookEventName]entities.Hook) {
+	if len(hooks) == 0 {
+		e.console.Println("Hooks: none")
+		return
+	}
+	e.console.Println("Hooks:")
+	for _, event := range entities.HookEventNames {
+		if hook, ok := hooks[event]; ok {
+			e.console.Printf("  %s: %s (timeout %s)\n", event, sanitizeTerminalText(hook.String()), hook.Timeout())
+		}
+	}
+}
+
+func (e commandExecutor) configSetRoot(root string, migrateHistory bool) int {
+	expandedRoot, err := expandHomePath(root)
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to expand path: %v", err))
+		return 1
+	}
+	update := e.service.UpdateConfiguration
+	if migrateHistory {
+		update = e.service.UpdateConfigurationMigratingHistory
+	}
+	err = update(func(current *entities.Config) (*entities.Config, error) {
+		return buildUpdatedConfig(current, expandedRoot, current.Language, cloneExcludedCategories(current.ExcludedCategories))
+	})
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to update path: %v", err))
+		return 1
+	}
+	e.console.Success(fmt.Sprintf("Outfit path updated to: %s", expandedRoot))
+	if migrateHistory {
+		e.console.Info("Worn outfits moved to the new path")
+	}
+	return 0
+}
+
+func (e commandExecutor) configAddRoot(root string) int {
+	expandedRoot, err := expandHomePath(root)
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to expand path: %v", err))
+		return 1
+	}
+	err = e.service.UpdateConfiguration(func(current *entities.Config) (*entities.Config, error) {
+		roots := current.WardrobeRoots()
+		if wardrobeRootIndex(roots, expandedRoot) >= 0 {
+			return nil, domainerrors.NewInvalidInputError(fmt.Sprintf("%s is already a wardrobe root", expandedRoot))
+		}
+		return current.WithRoots(append(roots, expandedRoot))
+	})
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to add root: %v", err))
+		return 1
+	}
+	e.console.Success(fmt.Sprintf("Added wardrobe root: %s", expandedRoot))
+	return 0
+}
+
+func (e commandExecutor) configRemoveRoot(root string) int {
+	expandedRoot, err := expandHomePath(root)
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to expand path: %v", err))
+		return 1
+	}
+	err = e.service.UpdateConfiguration(func(current *entities.Config) (*entities.Config, error) {
+		roots := current.WardrobeRoots()
+		index := wardrobeRootIndex(roots, expandedRoot)
+		switch {
+		case index < 0:
+			return nil, domainerrors.NewInvalidInputError(fmt.Sprintf("%s is not a wardrobe root", expandedRoot))
+		case index == 0:
+			return nil, domainerrors.NewInvalidInputError("the first root can't be removed; use config set-root to change it")
+		}
+		return current.WithRoots(append(roots[:index:index], roots[index+1:]...))
+	})
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to remove root: %v", err))
+		return 1
+	}
+	e.console.Success(fmt.Sprintf("Removed wardrobe root: %s", expandedRoot))
+	return 0
+}
+
+// wardrobeRootIndex returns the position of root in roots, or -1.
+func wardrobeRootIndex(roots []string, root string) int {
+	for index, candidate := range roots {
+		if filepath.Clean(candidate) == filepath.Clean(root) {
+			return index
+		}
+	}
+	return -1
+}
+
+func (e commandExecutor) configExclude(categories []string) int {
+	var excluded map[string]bool
+	err := e.service.UpdateConfiguration(func(current *entities.Config) (*entities.Config, error) {
+		excluded = cloneExcludedCategories(current.ExcludedCategories)
+		for _, category := range categories {
+			name := strings.TrimSpace(category)
+			if name != "" {
+				excluded[name] = true
+			}
+		}
+		return buildUpdatedConfig(current, current.Root, current.Language, excluded)
+	})
+	if err != nil {
 		e.console.Error(fmt.Sprintf("Failed to update excluded categories: %v", err))
 		return 1
 	}
@@ -599,6 +1567,185 @@ func (e commandExecutor) configExclude(categories []string) int {
 	return 0
 }
 
+func (e commandExecutor) configSetSlot(name, target string, mode entities.ActivationMode) int {
+	expandedTarget, err := expandHomePath(target)
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to expand path: %v", err))
+		return 1
+	}
+	slot, err := entities.NewActivationSlot(expandedTarget, mode)
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to update slot: %v", err))
+		return 1
+	}
+	err = e.service.UpdateConfiguration(func(current *entities.Config) (*entities.Config, error) {
+		return current.WithSlot(name, slot)
+	})
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to update slot: %v", err))
+		return 1
+	}
+	e.console.Success(fmt.Sprintf("Slot %s installs into %s (%s)", name, slot.Target, slot.Mode))
+	return 0
+}
+
+func (e commandExecutor) configRemoveSlot(name string) int {
+	err := e.service.UpdateConfiguration(func(current *entities.Config) (*entities.Config, error) {
+		return current.WithoutSlot(name)
+	})
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to remove slot: %v", err))
+		return 1
+	}
+	e.console.Success(fmt.Sprintf("Removed slot %s", name))
+	return 0
+}
+
+func (e commandExecutor) configSetHook(event entities.HookEventName, command []string, timeout time.Duration) int {
+	executable, err := expandHomePath(command[0])
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to expand path: %v", err))
+		return 1
+	}
+	hook, err := entities.NewHook(executable, command[1:], timeout)
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to update hook: %v", err))
+		return 1
+	}
+	err = e.service.UpdateConfiguration(func(current *entities.Config) (*entities.Config, error) {
+		return current.WithHook(event, hook), nil
+	})
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to update hook: %v", err))
+		return 1
+	}
+	e.console.Success(fmt.Sprintf("%s hook runs %s", event, hook.String()))
+	return 0
+}
+
+func (e commandExecutor) configRemoveHook(event entities.HookEventName) int {
+	err := e.service.UpdateConfiguration(func(current *entities.Config) (*entities.Config, error) {
+		return current.WithoutHook(event)
+	})
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to remove hook: %v", err))
+		return 1
+	}
+	e.console.Success(fmt.Sprintf("Removed %s hook", event))
+	return 0
+}
+
+func (e commandExecutor) configSetSelectionPlugin(command []string, timeout time.Duration) int {
+	executable, err := expandHomePath(command[0])
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to expand path: %v", err))
+		return 1
+	}
+	plugin, err := entities.NewSelectionPlugin(executable, command[1:], timeout)
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to update selection plugin: %v", err))
+		return 1
+	}
+	err = e.service.UpdateConfiguration(func(current *entities.Config) (*entities.Config, error) {
+		return current.WithSelectionPlugin(&plugin), nil
+	})
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to update selection plugin: %v", err))
+		return 1
+	}
+	e.console.Success(fmt.Sprintf("Outfits are now ranked by %s", plugin.String()))
+	return 0
+}
+
+func (e commandExecutor) configClearSelectionPlugin() int {
+	err := e.service.UpdateConfiguration(func(current *entities.Config) (*entities.Config, error) {
+		return current.WithSelectionPlugin(nil), nil
+	})
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to update selection plugin: %v", err))
+		return 1
+	}
+	e.console.Success("Outfits are now picked at random")
+	return 0
+}
+
+func (e commandExecutor) configSetStorage(backend entities.StorageBackend) int {
+	if err := e.runtime.SelectStorage(backend); err != nil {
+		e.console.Error(fmt.Sprintf("Failed to switch storage: %v", err))
+		return 1
+	}
+	e.console.Success(fmt.Sprintf("Config and worn outfits are now stored in %s", storageDescription(backend)))
+	return 0
+}
+
+func (e commandExecutor) configSetIdentity(identity entities.OutfitIdentity) int {
+	err := e.service.UpdateConfiguration(func(current *entities.Config) (*entities.Config, error) {
+		return current.WithIdentity(identity), nil
+	})
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to update identity: %v", err))
+		return 1
+	}
+	if identity == entities.IdentityContent {
+		e.console.Success("Worn outfits are now also recognised by their contents")
+		e.console.Info("A worn outfit that is renamed or moved to another category keeps its worn state")
+	} else {
+		e.console.Success("Worn outfits are now recognised by file name")
+	}
+	return 0
+}
+
+func (e commandExecutor) configSetNewArrivals(prefer bool) int {
+	err := e.service.UpdateConfiguration(func(current *entities.Config) (*entities.Config, error) {
+		return current.WithNewArrivalsPreferred(prefer), nil
+	})
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to update new arrivals policy: %v", err))
+		return 1
+	}
+	if prefer {
+		e.console.Success("Outfits added since an earlier run are now picked first until they are worn")
+	} else {
+		e.console.Success("New arrivals are now picked like any other outfit")
+	}
+	return 0
+}
+
+func (e commandExecutor) configSetSymlinks(follow bool, within []string) int {
+	allowedRoots := make([]string, len(within))
+	for index, root := range within {
+		expanded, err := expandHomePath(root)
+		if err != nil {
+			e.console.Error(fmt.Sprintf("Failed to expand path: %v", err))
+			return 1
+		}
+		allowedRoots[index] = expanded
+	}
+	err := e.service.UpdateConfiguration(func(current *entities.Config) (*entities.Config, error) {
+		return current.WithSymlinkPolicy(follow, allowedRoots)
+	})
+	if err != nil {
+		e.console.Error(fmt.Sprintf("Failed to update symlink policy: %v", err))
+		return 1
+	}
+	switch {
+	case !follow:
+		e.console.Success("Symbolic links in the wardrobe are now rejected")
+	case len(allowedRoots) == 0:
+		e.console.Success("Symbolic links in the wardrobe are now followed")
+	default:
+		e.console.Success(fmt.Sprintf("Symbolic links in the wardrobe are now followed within %s", strings.Join(allowedRoots, ", ")))
+	}
+	return 0
+}
+
+func storageDescription(backend entities.StorageBackend) string {
+	if backend == entities.StorageSQLite {
+		return "an SQLite database"
+	}
+	return "JSON files"
+}
+
 type pickMarkMode int
 
 const (
@@ -611,6 +1758,7 @@ type pickOptions struct {
 	categoryName    string
 	includeExcluded bool
 	markMode        pickMarkMode
+	activateSlot    string
 }
 
 func pickOptionsFromCommand(command pickCommand) pickOptions {
@@ -625,6 +1773,7 @@ func pickOptionsFromCommand(command pickCommand) pickOptions {
 		categoryName:    strings.TrimSpace(command.Category),
 		includeExcluded: command.IncludeExcluded,
 		markMode:        markMode,
+		activateSlot:    strings.TrimSpace(command.Activate),
 	}
 }
 
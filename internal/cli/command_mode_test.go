@@ -3,12 +3,16 @@ package cli
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
 )
 
 func TestExecuteCommand_Paths(t *testing.T) {
@@ -29,6 +33,22 @@ func TestExecuteCommand_Paths(t *testing.T) {
 		assertOutputContains(t, stdout.String(), "Config file: /state/outfitpicker/config.json", "Cache file:  /state/outfitpicker/cache.json", "Wardrobe:    "+cliTestOutfitRoot)
 	})
 
+	t.Run("shows every wardrobe root", func(t *testing.T) {
+		runtime := newStubRuntime()
+		config, err := mustCommandConfig(t, cliTestOutfitRoot, nil).WithRoots([]string{cliTestOutfitRoot, cliTestNewOutfitRoot})
+		if err != nil {
+			t.Fatalf("WithRoots() error = %v", err)
+		}
+		runtime.config.currentConfig = config
+		runtime.pathProvider = StaticStoragePathProvider{ConfigPath: "/config.json", CachePath: "/cache.json"}
+
+		var stdout bytes.Buffer
+		if _, code := ExecuteCommand([]string{"paths"}, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("paths exit code = %d, want 0", code)
+		}
+		assertOutputContains(t, stdout.String(), "Wardrobe:    "+cliTestOutfitRoot+"\n             "+cliTestNewOutfitRoot+"\n")
+	})
+
 	t.Run("config path error", func(t *testing.T) {
 		runtime := newStubRuntime()
 		runtime.pathProvider = FuncStoragePathProvider{
@@ -76,6 +96,7 @@ func TestExecuteCommand_Doctor(t *testing.T) {
 			entities.NewCategoryInfo(entities.NewCategoryReference("Shoes", filepath.Join(wardrobeDir, "Shoes")), entities.CategoryStateNoAvatarFiles, 0),
 			entities.NewCategoryInfo(entities.NewCategoryReference("Jackets", filepath.Join(wardrobeDir, "Jackets")), entities.CategoryStateUserExcluded, 3),
 			entities.NewCategoryInfo(entities.NewCategoryReference("Hats", filepath.Join(wardrobeDir, "Hats")), entities.CategoryStateHasOutfits, 2),
+			entities.NewCategoryInfo(entities.NewCategoryReference("NAS", filepath.Join(wardrobeDir, "NAS")), entities.CategoryStateTimedOut, 0).WithError(errors.New("reading NAS timed out after 10s")),
 		}
 		runtime.wardrobe.allOutfitStates = map[string]entities.CategoryOutfitState{}
 
@@ -85,7 +106,7 @@ func TestExecuteCommand_Doctor(t *testing.T) {
 		if !handled || code != 1 {
 			t.Fatalf("ExecuteCommand() = handled %t code %d, want handled true code 1 for warnings", handled, code)
 		}
-		assertOutputContains(t, stdout.String(), "Config file exists", "Wardrobe directory exists", "Found 3 categories", "Found 5 .avatar files", "Shoes has no .avatar files", "Jackets is excluded from random selection", "Cache file is valid")
+		assertOutputContains(t, stdout.String(), "Config file exists", "Wardrobe directory exists", "Found 4 categories", "Found 5 .avatar files", "Shoes has no .avatar files", "Jackets is excluded from random selection", "NAS can't be read: reading NAS timed out after 10s", "Check that "+filepath.Join(wardrobeDir, "NAS")+" is reachable", "Cache file is valid")
 	})
 
 	t.Run("invalid cache fails", func(t *testing.T) {
@@ -299,21 +320,9 @@ func TestExecuteCommand_PickCategory(t *testing.T) {
 }
 
 func TestExecuteCommand_PickIncludeExcluded(t *testing.T) {
-	originalRandomIndex := commandRandomIndex
-	commandRandomIndex = func(int) int { return 1 }
-	t.Cleanup(func() { commandRandomIndex = originalRandomIndex })
-
 	runtime := newStubRuntime()
-	casual := entities.NewCategoryReference("casual", cliTestCategoryPath("casual"))
-	formal := entities.NewCategoryReference("formal", cliTestCategoryPath("formal"))
-	runtime.wardrobe.categoryInfos = []entities.CategoryInfo{
-		entities.NewCategoryInfo(casual, entities.CategoryStateHasOutfits, 1),
-		entities.NewCategoryInfo(formal, entities.CategoryStateUserExcluded, 1),
-	}
-	runtime.wardrobe.availableOutfitsByName = map[string][]entities.OutfitReference{
-		"casual": {entities.NewOutfitReference("casual.avatar", casual)},
-		"formal": {entities.NewOutfitReference("formal.avatar", formal)},
-	}
+	formal := entities.NewOutfitReference("formal.avatar", entities.NewCategoryReference("formal", cliTestCategoryPath("formal")))
+	runtime.random.excludedResult = stubSelectorResult{outfit: &formal}
 
 	var stdout bytes.Buffer
 	handled, code := ExecuteCommand([]string{"pick", "--include-excluded", "--mark-worn"}, runtime, TerminalConsole{stdout: &stdout})
@@ -321,8 +330,8 @@ func TestExecuteCommand_PickIncludeExcluded(t *testing.T) {
 	if !handled || code != 0 {
 		t.Fatalf("ExecuteCommand() = handled %t code %d, want handled true code 0", handled, code)
 	}
-	if runtime.random.globalCalls != 0 {
-		t.Fatalf("global random calls = %d, want 0 for include-excluded path", runtime.random.globalCalls)
+	if runtime.random.globalCalls != 0 || runtime.random.excludedCalls != 1 {
+		t.Fatalf("global random calls = %d, include-excluded calls = %d, want 0 and 1", runtime.random.globalCalls, runtime.random.excludedCalls)
 	}
 	if len(runtime.commands.wearCalls) != 1 || runtime.commands.wearCalls[0].Category.Name != "formal" {
 		t.Fatalf("wear calls = %#v, want formal outfit", runtime.commands.wearCalls)
@@ -363,9 +372,6 @@ func TestExecuteCommand_PickNoOutfits(t *testing.T) {
 
 func TestExecuteCommand_PickIncludeExcludedNoOutfits(t *testing.T) {
 	runtime := newStubRuntime()
-	runtime.wardrobe.categoryInfos = []entities.CategoryInfo{
-		entities.NewCategoryInfo(entities.NewCategoryReference("empty", cliTestCategoryPath("empty")), entities.CategoryStateEmpty, 0),
-	}
 	var stdout bytes.Buffer
 
 	handled, code := ExecuteCommand([]string{"pick", "--include-excluded", "--mark-worn"}, runtime, TerminalConsole{stdout: &stdout})
@@ -376,13 +382,9 @@ func TestExecuteCommand_PickIncludeExcludedNoOutfits(t *testing.T) {
 	assertOutputContains(t, stdout.String(), "No outfits available")
 }
 
-func TestExecuteCommand_PickIncludeExcludedPropagatesAvailableOutfitError(t *testing.T) {
+func TestExecuteCommand_PickIncludeExcludedPropagatesSelectionError(t *testing.T) {
 	runtime := newStubRuntime()
-	category := entities.NewCategoryReference("formal", cliTestCategoryPath("formal"))
-	runtime.wardrobe.categoryInfos = []entities.CategoryInfo{
-		entities.NewCategoryInfo(category, entities.CategoryStateUserExcluded, 1),
-	}
-	runtime.wardrobe.availableOutfitErrors = map[string]error{"formal": errors.New("available outfits failed")}
+	runtime.random.excludedResult = stubSelectorResult{err: errors.New("available outfits failed")}
 	var stderr bytes.Buffer
 
 	handled, code := ExecuteCommand([]string{"pick", "--include-excluded", "--mark-worn"}, runtime, TerminalConsole{stderr: &stderr})
@@ -395,7 +397,7 @@ func TestExecuteCommand_PickIncludeExcludedPropagatesAvailableOutfitError(t *tes
 
 func mustCommandConfig(t *testing.T, root string, excluded map[string]bool) *entities.Config {
 	t.Helper()
-	config, err := entities.NewConfig(root, nil, excluded, nil, nil)
+	config, err := entities.NewConfig(root, nil, excluded)
 	if err != nil {
 		t.Fatalf("NewConfig(%q) error = %v", root, err)
 	}
@@ -433,6 +435,9 @@ func TestExecuteCommand_ListCategories(t *testing.T) {
 	runtime.wardrobe.categoryInfos = []entities.CategoryInfo{
 		entities.NewCategoryInfo(entities.NewCategoryReference("shoes", cliTestCategoryPath("shoes")), entities.CategoryStateHasOutfits, 2),
 		entities.NewCategoryInfo(entities.NewCategoryReference("hats", cliTestCategoryPath("hats")), entities.CategoryStateEmpty, 0),
+		entities.NewCategoryInfo(entities.NewCategoryReference("shared", cliTestCategoryPath("shared")), entities.CategoryStateSymlinkRejected, 0),
+		entities.NewCategoryInfo(entities.NewCategoryReference("loop", cliTestCategoryPath("loop")), entities.CategoryStateSymlinkLoop, 0),
+		entities.NewCategoryInfo(entities.NewCategoryReference("away", cliTestCategoryPath("away")), entities.CategoryStateSymlinkOutsideRoots, 0),
 	}
 
 	var stdout bytes.Buffer
@@ -441,7 +446,10 @@ func TestExecuteCommand_ListCategories(t *testing.T) {
 	if !handled || code != 0 {
 		t.Fatalf("ExecuteCommand() = handled %t code %d, want handled true code 0", handled, code)
 	}
-	assertOutputContains(t, stdout.String(), "shoes", "hasOutfits", "2 outfits", "hats", "empty")
+	assertOutputContains(t, stdout.String(), "shoes", "hasOutfits", "2 outfits", "hats", "empty",
+		"shared\tsymlinkRejected\t0 outfits\tRun outfitpicker config set-symlinks follow, or replace the link "+cliTestCategoryPath("shared")+" with the folder it points to",
+		"loop\tsymlinkLoop\t0 outfits\tPoint the link "+cliTestCategoryPath("loop")+" at a folder that does not hold it",
+		"away\tsymlinkOutsideRoots\t0 outfits\tAllow where "+cliTestCategoryPath("away")+" leads with outfitpicker config set-symlinks follow --within")
 }
 
 func TestExecuteCommand_ListWornAndUnworn(t *testing.T) {
@@ -544,6 +552,25 @@ func TestExecuteCommand_Config(t *testing.T) {
 			t.Fatal("expected existing excluded category to be preserved")
 		}
 		assertOutputContains(t, stdout.String(), "Outfit path updated")
+		if runtime.config.migrations != 0 {
+			t.Fatalf("migrations = %d, want history kept for the old root", runtime.config.migrations)
+		}
+	})
+
+	t.Run("set-root migrating history", func(t *testing.T) {
+		runtime := newStubRuntime()
+		runtime.config.currentConfig = mustTestConfig(t, cliTestOutfitRoot, nil)
+		var stdout bytes.Buffer
+
+		handled, code := ExecuteCommand([]string{"config", "set-root", "--migrate-history", cliTestNewOutfitRoot}, runtime, TerminalConsole{stdout: &stdout})
+
+		if !handled || code != 0 {
+			t.Fatalf("ExecuteCommand() = handled %t code %d, want handled true code 0", handled, code)
+		}
+		if runtime.config.migrations != 1 || len(runtime.config.updatedConfigs) != 1 {
+			t.Fatalf("migrations = %d, updated configs = %d; want one migrating update", runtime.config.migrations, len(runtime.config.updatedConfigs))
+		}
+		assertOutputContains(t, stdout.String(), "Outfit path updated", "Worn outfits moved to the new path")
 	})
 
 	t.Run("exclude", func(t *testing.T) {
@@ -564,6 +591,170 @@ func TestExecuteCommand_Config(t *testing.T) {
 		}
 		assertOutputContains(t, stdout.String(), "Excluded categories updated", "hats", "shoes")
 	})
+
+	t.Run("set-identity", func(t *testing.T) {
+		runtime := newStubRuntime()
+		runtime.config.currentConfig = mustTestConfig(t, cliTestOutfitRoot, nil)
+		var stdout bytes.Buffer
+
+		if _, code := ExecuteCommand([]string{"config", "set-identity", "content"}, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("set-identity content exit code = %d, want 0", code)
+		}
+		if !runtime.config.currentConfig.MatchesContent() {
+			t.Fatal("expected outfits to be matched by content")
+		}
+		if _, code := ExecuteCommand([]string{"config", "get"}, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("get exit code = %d, want 0", code)
+		}
+		if _, code := ExecuteCommand([]string{"config", "set-identity", "name"}, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("set-identity name exit code = %d, want 0", code)
+		}
+		if runtime.config.currentConfig.Identity != entities.IdentityFileName {
+			t.Fatalf("identity = %q, want name", runtime.config.currentConfig.Identity)
+		}
+		assertOutputContains(t, stdout.String(), "recognised by their contents", "Identity: content", "recognised by file name")
+
+		runtime.config.updateErr = errors.New("disk full")
+		var stderr bytes.Buffer
+		if _, code := ExecuteCommand([]string{"config", "set-identity", "content"}, runtime, TerminalConsole{stderr: &stderr}); code != 1 {
+			t.Fatalf("set-identity with a failing save exit code = %d, want 1", code)
+		}
+		assertOutputContains(t, stderr.String(), "Failed to update identity: disk full")
+	})
+
+	t.Run("set-new-arrivals", func(t *testing.T) {
+		runtime := newStubRuntime()
+		runtime.config.currentConfig = mustTestConfig(t, cliTestOutfitRoot, nil)
+		var stdout bytes.Buffer
+
+		if _, code := ExecuteCommand([]string{"config", "set-new-arrivals", "first"}, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("set-new-arrivals first exit code = %d, want 0", code)
+		}
+		if !runtime.config.currentConfig.PreferNewArrivals {
+			t.Fatal("expected new arrivals to be picked first")
+		}
+		if _, code := ExecuteCommand([]string{"config", "get"}, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("get exit code = %d, want 0", code)
+		}
+		if _, code := ExecuteCommand([]string{"config", "set-new-arrivals", "mixed"}, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("set-new-arrivals mixed exit code = %d, want 0", code)
+		}
+		if runtime.config.currentConfig.PreferNewArrivals {
+			t.Fatal("expected new arrivals to be picked like any other outfit")
+		}
+		assertOutputContains(t, stdout.String(), "picked first until they are worn", "New arrivals: first", "picked like any other outfit")
+
+		runtime.config.updateErr = errors.New("disk full")
+		var stderr bytes.Buffer
+		if _, code := ExecuteCommand([]string{"config", "set-new-arrivals", "first"}, runtime, TerminalConsole{stderr: &stderr}); code != 1 {
+			t.Fatalf("set-new-arrivals with a failing save exit code = %d, want 1", code)
+		}
+		assertOutputContains(t, stderr.String(), "Failed to update new arrivals policy: disk full")
+	})
+
+	t.Run("set-symlinks", func(t *testing.T) {
+		runtime := newStubRuntime()
+		runtime.config.currentConfig = mustTestConfig(t, cliTestOutfitRoot, nil)
+		var stdout bytes.Buffer
+
+		if _, code := ExecuteCommand([]string{"config", "set-symlinks", "follow", "--within", cliTestOutfitRoot, "--within", "/outfitpicker-test/packs"}, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("set-symlinks follow exit code = %d, want 0", code)
+		}
+		if config := runtime.config.currentConfig; !config.FollowSymlinks || len(config.SymlinkRoots) != 2 {
+			t.Fatalf("config = %+v, want links followed within two roots", config)
+		}
+		if _, code := ExecuteCommand([]string{"config", "get"}, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("get exit code = %d, want 0", code)
+		}
+		if _, code := ExecuteCommand([]string{"config", "set-symlinks", "follow"}, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("set-symlinks follow exit code = %d, want 0", code)
+		}
+		if _, code := ExecuteCommand([]string{"config", "set-symlinks", "reject"}, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("set-symlinks reject exit code = %d, want 0", code)
+		}
+		if runtime.config.currentConfig.FollowSymlinks {
+			t.Fatal("expected symbolic links to be rejected")
+		}
+		if _, code := ExecuteCommand([]string{"config", "get"}, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("get exit code = %d, want 0", code)
+		}
+		assertOutputContains(t, stdout.String(),
+			"now followed within "+cliTestOutfitRoot+", /outfitpicker-test/packs",
+			"Symlinks: follow (within "+cliTestOutfitRoot+", /outfitpicker-test/packs)",
+			"Symbolic links in the wardrobe are now followed\x1b",
+			"Symbolic links in the wardrobe are now rejected",
+			"Symlinks: reject",
+		)
+
+		var stderr bytes.Buffer
+		if _, code := ExecuteCommand([]string{"config", "set-symlinks", "reject", "--within", "/outfitpicker-test/packs"}, runtime, TerminalConsole{stderr: &stderr}); code != 1 {
+			t.Fatalf("set-symlinks reject with allowed roots exit code = %d, want 1", code)
+		}
+		assertOutputContains(t, stderr.String(), "Failed to update symlink policy: invalid input: allowed roots only apply when symlinks are followed")
+	})
+
+	t.Run("add-root and remove-root", func(t *testing.T) {
+		runtime := newStubRuntime()
+		runtime.config.currentConfig = mustTestConfig(t, cliTestOutfitRoot, nil)
+		var stdout bytes.Buffer
+
+		if _, code := ExecuteCommand([]string{"config", "add-root", cliTestNewOutfitRoot}, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("add-root exit code = %d, want 0", code)
+		}
+		want := []string{cliTestOutfitRoot, cliTestNewOutfitRoot}
+		if got := runtime.config.currentConfig.WardrobeRoots(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("roots after add-root = %v, want %v", got, want)
+		}
+
+		if _, code := ExecuteCommand([]string{"config", "get"}, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("get exit code = %d, want 0", code)
+		}
+		if _, code := ExecuteCommand([]string{"config", "set-root", "/outfitpicker-test/moved"}, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("set-root exit code = %d, want 0", code)
+		}
+		want = []string{"/outfitpicker-test/moved", cliTestNewOutfitRoot}
+		if got := runtime.config.currentConfig.WardrobeRoots(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("roots after set-root = %v, want the extra root kept in %v", got, want)
+		}
+
+		if _, code := ExecuteCommand([]string{"config", "remove-root", cliTestNewOutfitRoot}, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("remove-root exit code = %d, want 0", code)
+		}
+		if got := runtime.config.currentConfig.WardrobeRoots(); !reflect.DeepEqual(got, []string{"/outfitpicker-test/moved"}) {
+			t.Fatalf("roots after remove-root = %v, want only the first root", got)
+		}
+		assertOutputContains(t, stdout.String(), "Added wardrobe root: "+cliTestNewOutfitRoot, "Extra roots: "+cliTestNewOutfitRoot, "Removed wardrobe root: "+cliTestNewOutfitRoot)
+	})
+
+	t.Run("root changes that are refused", func(t *testing.T) {
+		tests := []struct {
+			name string
+			args []string
+			want string
+		}{
+			{name: "add existing root", args: []string{"config", "add-root", cliTestOutfitRoot + "/"}, want: "is already a wardrobe root"},
+			{name: "add restricted root", args: []string{"config", "add-root", "/etc/outfits"}, want: "Failed to add root"},
+			{name: "remove unknown root", args: []string{"config", "remove-root", "/outfitpicker-test/other"}, want: "is not a wardrobe root"},
+			{name: "remove first root", args: []string{"config", "remove-root", cliTestOutfitRoot}, want: "use config set-root to change it"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				runtime := newStubRuntime()
+				runtime.config.currentConfig = mustTestConfig(t, cliTestOutfitRoot, nil)
+				var stderr bytes.Buffer
+
+				handled, code := ExecuteCommand(tt.args, runtime, TerminalConsole{stderr: &stderr})
+
+				if !handled || code != 1 {
+					t.Fatalf("ExecuteCommand() = handled %t code %d, want handled true code 1", handled, code)
+				}
+				if len(runtime.config.updatedConfigs) != 0 {
+					t.Fatalf("updated configs = %d, want none", len(runtime.config.updatedConfigs))
+				}
+				assertOutputContains(t, stderr.String(), tt.want)
+			})
+		}
+	})
 }
 
 func TestExecuteCommand_Help(t *testing.T) {
@@ -609,7 +800,7 @@ func TestExecuteCommand_ReturnsUnhandledForInteractiveMode(t *testing.T) {
 
 func mustTestConfig(t *testing.T, root string, excluded map[string]bool) *entities.Config {
 	t.Helper()
-	config, err := entities.NewConfig(root, stringPtr("en"), excluded, nil, nil)
+	config, err := entities.NewConfig(root, stringPtr("en"), excluded)
 	if err != nil {
 		t.Fatalf("NewConfig() error = %v", err)
 	}
@@ -633,3 +824,1039 @@ func assertOutputNotContains(t *testing.T, output string, parts ...string) {
 		}
 	}
 }
+
+func TestExecuteCommand_PickActivate(t *testing.T) {
+	runtime := newStubRuntime()
+	outfit := entities.NewOutfitReference("boots.avatar", entities.NewCategoryReference("shoes", cliTestCategoryPath("shoes")))
+	runtime.random.globalResults = []stubSelectorResult{{outfit: &outfit}}
+	runtime.activator.slot = entities.ActivationSlot{Target: "/outfitpicker-test/game/current.avatar", Mode: entities.ActivationModeCopy}
+	var stdout bytes.Buffer
+
+	handled, code := ExecuteCommand([]string{"pick", "--activate", "game", "--mark-worn"}, runtime, TerminalConsole{stdout: &stdout})
+
+	if !handled || code != 0 {
+		t.Fatalf("ExecuteCommand() = handled %t code %d, want handled true code 0", handled, code)
+	}
+	if len(runtime.activator.activateCalls) != 1 || runtime.activator.activateSlots[0] != "game" {
+		t.Fatalf("activate calls = %#v slots %v, want boots.avatar into game", runtime.activator.activateCalls, runtime.activator.activateSlots)
+	}
+	assertOutputContains(t, stdout.String(), "Activated boots.avatar in /outfitpicker-test/game/current.avatar", "Marked worn")
+
+	t.Run("failure skips marking worn", func(t *testing.T) {
+		runtime := newStubRuntime()
+		runtime.random.globalResults = []stubSelectorResult{{outfit: &outfit}}
+		runtime.activator.activateErr = domainerrors.ErrSlotNotFound
+		var stderr bytes.Buffer
+
+		_, code := ExecuteCommand([]string{"pick", "--activate", "game", "--mark-worn"}, runtime, TerminalConsole{stdout: &bytes.Buffer{}, stderr: &stderr})
+
+		if code != 1 {
+			t.Fatalf("ExecuteCommand() code = %d, want 1", code)
+		}
+		if len(runtime.commands.wearCalls) != 0 {
+			t.Fatalf("wear calls = %#v, want none", runtime.commands.wearCalls)
+		}
+		assertOutputContains(t, stderr.String(), "Failed to activate outfit: activation slot not found")
+	})
+}
+
+func TestExecuteCommand_Activate(t *testing.T) {
+	newActivateRuntime := func() *stubRuntime {
+		runtime := newStubRuntime()
+		runtime.wardrobe.categoryInfos = []entities.CategoryInfo{
+			entities.NewCategoryInfo(mainMenuCategory("shoes"), entities.CategoryStateHasOutfits, 1),
+			entities.NewCategoryInfo(mainMenuCategory("boots"), entities.CategoryStateHasOutfits, 2),
+		}
+		runtime.wardrobe.allOutfitsByCategory["shoes"] = []entities.OutfitReference{mainMenuOutfit("shoes", "boots.avatar")}
+		runtime.wardrobe.allOutfitsByCategory["boots"] = []entities.OutfitReference{mainMenuOutfit("boots", "boots.avatar"), mainMenuOutfit("boots", "chelsea.avatar")}
+		runtime.activator.slot = entities.ActivationSlot{Target: "/outfitpicker-test/game/current.avatar", Mode: entities.ActivationModeSymlink}
+		return runtime
+	}
+
+	tests := []struct {
+		name         string
+		args         []string
+		wantCode     int
+		wantCategory string
+		wantFile     string
+		wantOutput   string
+	}{
+		{name: "bare name", args: []string{"activate", "chelsea", "--slot", "game"}, wantCategory: "boots", wantFile: "chelsea.avatar", wantOutput: "Activated chelsea.avatar"},
+		{name: "category and file", args: []string{"activate", "shoes/boots.avatar", "--slot", "game"}, wantCategory: "shoes", wantFile: "boots.avatar", wantOutput: "Activated boots.avatar"},
+		{name: "ambiguous", args: []string{"activate", "boots", "--slot", "game"}, wantCode: 1, wantOutput: "matches outfits in several categories"},
+		{name: "missing", args: []string{"activate", "sandals", "--slot", "game"}, wantCode: 1, wantOutput: `no outfit named "sandals"`},
+		{name: "no outfit", args: []string{"activate", "--slot", "game"}, wantCode: 2, wantOutput: "Specify an outfit"},
+		{name: "outfit with restore", args: []string{"activate", "chelsea", "--slot", "game", "--restore"}, wantCode: 2, wantOutput: "not both"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runtime := newActivateRuntime()
+			var output bytes.Buffer
+
+			handled, code := ExecuteCommand(tt.args, runtime, TerminalConsole{stdout: &output, stderr: &output})
+
+			if !handled || code != tt.wantCode {
+				t.Fatalf("ExecuteCommand() = handled %t code %d, want handled true code %d", handled, code, tt.wantCode)
+			}
+			assertOutputContains(t, output.String(), tt.wantOutput)
+			if tt.wantFile == "" {
+				if len(runtime.activator.activateCalls) != 0 {
+					t.Fatalf("activate calls = %#v, want none", runtime.activator.activateCalls)
+				}
+				return
+			}
+			got := runtime.activator.activateCalls
+			if len(got) != 1 || got[0].Category.Name != tt.wantCategory || got[0].FileName != tt.wantFile {
+				t.Fatalf("activate calls = %#v, want %s/%s", got, tt.wantCategory, tt.wantFile)
+			}
+		})
+	}
+
+	t.Run("restore", func(t *testing.T) {
+		runtime := newActivateRuntime()
+		var stdout bytes.Buffer
+
+		_, code := ExecuteCommand([]string{"activate", "--restore", "--slot", "game"}, runtime, TerminalConsole{stdout: &stdout})
+
+		if code != 0 || len(runtime.activator.restoreCalls) != 1 || runtime.activator.restoreCalls[0] != "game" {
+			t.Fatalf("code = %d restore calls = %v, want game restored", code, runtime.activator.restoreCalls)
+		}
+		assertOutputContains(t, stdout.String(), "Restored previous outfit in /outfitpicker-test/game/current.avatar")
+	})
+
+	t.Run("restore failure", func(t *testing.T) {
+		runtime := newActivateRuntime()
+		runtime.activator.restoreErr = domainerrors.ErrNoPreviousActivation
+		var stderr bytes.Buffer
+
+		_, code := ExecuteCommand([]string{"activate", "--restore", "--slot", "game"}, runtime, TerminalConsole{stderr: &stderr})
+
+		if code != 1 {
+			t.Fatalf("ExecuteCommand() code = %d, want 1", code)
+		}
+		assertOutputContains(t, stderr.String(), "no previously active outfit to restore")
+	})
+
+	t.Run("requires slot", func(t *testing.T) {
+		var stderr bytes.Buffer
+		_, code := ExecuteCommand([]string{"activate", "chelsea"}, nil, TerminalConsole{stdout: &bytes.Buffer{}, stderr: &stderr})
+		if code != 2 {
+			t.Fatalf("ExecuteCommand() code = %d, want 2", code)
+		}
+	})
+}
+
+func TestExecuteCommand_ConfigSlots(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Fatalf("UserHomeDir() error = %v", err)
+	}
+
+	t.Run("set-slot", func(t *testing.T) {
+		runtime := newStubRuntime()
+		runtime.config.currentConfig = mustCommandConfig(t, cliTestOutfitRoot, nil)
+		var stdout bytes.Buffer
+
+		_, code := ExecuteCommand([]string{"config", "set-slot", "game", "~/game/current.avatar", "--mode", "symlink"}, runtime, TerminalConsole{stdout: &stdout})
+
+		if code != 0 || len(runtime.config.updatedConfigs) != 1 {
+			t.Fatalf("code = %d updates = %d, want one update", code, len(runtime.config.updatedConfigs))
+		}
+		want := entities.ActivationSlot{Target: filepath.Join(home, "game", "current.avatar"), Mode: entities.ActivationModeSymlink}
+		if got := runtime.config.updatedConfigs[0].Slots["game"]; got != want {
+			t.Fatalf("slot = %#v, want %#v", got, want)
+		}
+		assertOutputContains(t, stdout.String(), "Slot game installs into")
+	})
+
+	t.Run("get and remove", func(t *testing.T) {
+		runtime := newStubRuntime()
+		config, err := mustCommandConfig(t, cliTestOutfitRoot, nil).WithSlot("game", entities.ActivationSlot{Target: "/outfitpicker-test/game/current.avatar", Mode: entities.ActivationModeCopy})
+		if err != nil {
+			t.Fatalf("WithSlot() error = %v", err)
+		}
+		runtime.config.currentConfig = config
+		var stdout bytes.Buffer
+
+		ExecuteCommand([]string{"config", "get"}, runtime, TerminalConsole{stdout: &stdout})
+		assertOutputContains(t, stdout.String(), "Slots:", "game: /outfitpicker-test/game/current.avatar (copy)")
+
+		_, code := ExecuteCommand([]string{"config", "remove-slot", "game"}, runtime, TerminalConsole{stdout: &stdout})
+		if code != 0 || len(runtime.config.currentConfig.Slots) != 0 {
+			t.Fatalf("code = %d slots = %v, want slot removed", code, runtime.config.currentConfig.Slots)
+		}
+		assertOutputContains(t, stdout.String(), "Removed slot game")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name string
+			args []string
+			want string
+		}{
+			{name: "relative target", args: []string{"config", "set-slot", "game", "current.avatar"}, want: "Failed to update slot"},
+			{name: "invalid name", args: []string{"config", "set-slot", "a/b", "/outfitpicker-test/current.avatar"}, want: "Failed to update slot"},
+			{name: "unknown slot", args: []string{"config", "remove-slot", "game"}, want: "Failed to remove slot: activation slot not found"},
+		}
+		for _, tt := range tests {
+			runtime := newStubRuntime()
+			runtime.config.currentConfig = mustCommandConfig(t, cliTestOutfitRoot, nil)
+			var stderr bytes.Buffer
+
+			_, code := ExecuteCommand(tt.args, runtime, TerminalConsole{stdout: &bytes.Buffer{}, stderr: &stderr})
+
+			if code != 1 {
+				t.Fatalf("%s: code = %d, want 1", tt.name, code)
+			}
+			assertOutputContains(t, stderr.String(), tt.want)
+		}
+	})
+}
+
+func TestBuildUpdatedConfigPreservesSettings(t *testing.T) {
+	slot := entities.ActivationSlot{Target: "/outfitpicker-test/game/current.avatar", Mode: entities.ActivationModeCopy}
+	current, err := mustCommandConfig(t, cliTestOutfitRoot, nil).WithSlot("game", slot)
+	if err != nil {
+		t.Fatalf("WithSlot() error = %v", err)
+	}
+	current = current.WithHook(entities.HookEventPostWear, entities.Hook{Command: "notify"}).WithSelectionPlugin(&entities.SelectionPlugin{Command: "ranker"})
+	current.Revision = 9
+	current.Storage = entities.StorageSQLite
+	if current, err = current.WithSymlinkPolicy(true, []string{cliTestOutfitRoot}); err != nil {
+		t.Fatalf("WithSymlinkPolicy() error = %v", err)
+	}
+
+	updated, err := buildUpdatedConfig(current, cliTestNewOutfitRoot, "en", nil)
+	if err != nil {
+		t.Fatalf("buildUpdatedConfig() error = %v", err)
+	}
+	if updated.Slots["game"] != slot {
+		t.Fatalf("slots = %v, want game preserved", updated.Slots)
+	}
+	if _, ok := updated.Hook(entities.HookEventPostWear); !ok {
+		t.Fatalf("hooks = %v, want post-wear preserved", updated.Hooks)
+	}
+	if updated.SelectionPlugin == nil {
+		t.Fatal("selection plugin was not preserved")
+	}
+	if updated.Revision != 9 {
+		t.Fatalf("revision = %d, want 9", updated.Revision)
+	}
+	if updated.Storage != entities.StorageSQLite {
+		t.Fatalf("storage = %q, want sqlite preserved", updated.Storage)
+	}
+	if updated.Root != cliTestNewOutfitRoot || !updated.FollowSymlinks || len(updated.SymlinkRoots) != 1 {
+		t.Fatalf("config = %+v, want the new root and the symlink policy preserved", updated)
+	}
+	if _, err := buildUpdatedConfig(current, cliTestNewOutfitRoot, "xx", nil); err == nil {
+		t.Fatal("buildUpdatedConfig() took an unsupported language")
+	}
+}
+
+func TestExecuteCommand_PickCancelledByHook(t *testing.T) {
+	runtime := newStubRuntime()
+	cancelled := fmt.Errorf("%w: %w", domainerrors.ErrPickCancelled, errors.New(`pre-pick hook "sync" failed: exit status 1`))
+	runtime.random.globalResults = []stubSelectorResult{{err: cancelled}}
+	var stdout, stderr bytes.Buffer
+
+	_, code := ExecuteCommand([]string{"pick", "--mark-worn"}, runtime, TerminalConsole{stdout: &stdout, stderr: &stderr})
+
+	if code != 1 || len(runtime.commands.wearCalls) != 0 {
+		t.Fatalf("ExecuteCommand() code = %d, wear calls = %d, want 1 and none", code, len(runtime.commands.wearCalls))
+	}
+	assertOutputContains(t, stderr.String(), `pick cancelled: pre-pick hook "sync" failed`)
+	assertOutputNotContains(t, stdout.String(), "Outfit picked")
+}
+
+func TestExecuteCommand_ConfigHooks(t *testing.T) {
+	t.Run("set-hook", func(t *testing.T) {
+		runtime := newStubRuntime()
+		runtime.config.currentConfig = mustCommandConfig(t, cliTestOutfitRoot, nil)
+		var stdout bytes.Buffer
+
+		_, code := ExecuteCommand([]string{"config", "set-hook", "--timeout", "30s", "post-wear", "notify-send", "--urgency", "low", "--no-hooks"}, runtime, TerminalConsole{stdout: &stdout})
+
+		if code != 0 || len(runtime.config.updatedConfigs) != 1 {
+			t.Fatalf("code = %d updates = %d, want one update", code, len(runtime.config.updatedConfigs))
+		}
+		hook, ok := runtime.config.updatedConfigs[0].Hook(entities.HookEventPostWear)
+		if !ok || hook.Command != "notify-send" || strings.Join(hook.Args, " ") != "--urgency low --no-hooks" || hook.TimeoutSeconds != 30 {
+			t.Fatalf("hook = %#v, want notify-send --urgency low --no-hooks with 30s timeout", hook)
+		}
+		assertOutputContains(t, stdout.String(), "post-wear hook runs notify-send --urgency low --no-hooks")
+	})
+
+	t.Run("get and remove", func(t *testing.T) {
+		runtime := newStubRuntime()
+		runtime.config.currentConfig = mustCommandConfig(t, cliTestOutfitRoot, nil).WithHook(entities.HookEventReset, entities.Hook{Command: "notify"})
+		var stdout bytes.Buffer
+
+		ExecuteCommand([]string{"config", "get"}, runtime, TerminalConsole{stdout: &stdout})
+		assertOutputContains(t, stdout.String(), "Hooks:", "reset: notify (timeout 10s)")
+
+		_, code := ExecuteCommand([]string{"config", "remove-hook", "reset"}, runtime, TerminalConsole{stdout: &stdout})
+		if code != 0 || len(runtime.config.currentConfig.Hooks) != 0 {
+			t.Fatalf("code = %d hooks = %v, want hook removed", code, runtime.config.currentConfig.Hooks)
+		}
+		assertOutputContains(t, stdout.String(), "Removed reset hook")
+
+		stdout.Reset()
+		ExecuteCommand([]string{"config", "get"}, runtime, TerminalConsole{stdout: &stdout})
+		assertOutputContains(t, stdout.String(), "Hooks: none")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			args     []string
+			wantCode int
+			want     string
+		}{
+			{name: "unknown event", args: []string{"config", "set-hook", "post-lunch", "notify"}, wantCode: 2},
+			{name: "fractional timeout", args: []string{"config", "set-hook", "--timeout", "1500ms", "reset", "notify"}, wantCode: 1, want: "Failed to update hook"},
+			{name: "unknown hook", args: []string{"config", "remove-hook", "reset"}, wantCode: 1, want: "Failed to remove hook: hook not found"},
+			{name: "global flag after the command", args: []string{"config", "remove-hook", "reset", "--no-hooks"}, wantCode: 2, want: "--no-hooks must come before the command"},
+		}
+		for _, tt := range tests {
+			runtime := newStubRuntime()
+			runtime.config.currentConfig = mustCommandConfig(t, cliTestOutfitRoot, nil)
+			var stderr bytes.Buffer
+
+			_, code := ExecuteCommand(tt.args, runtime, TerminalConsole{stdout: &bytes.Buffer{}, stderr: &stderr})
+
+			if code != tt.wantCode {
+				t.Fatalf("%s: code = %d, want %d", tt.name, code, tt.wantCode)
+			}
+			assertOutputContains(t, stderr.String(), tt.want)
+		}
+	})
+}
+
+func TestExecuteCommand_ConfigSlotAndHookStorageErrors(t *testing.T) {
+	commands := []struct {
+		args       []string
+		updateWant string
+	}{
+		{args: []string{"config", "set-slot", "game", "/outfitpicker-test/game/current.avatar"}, updateWant: "Failed to update slot"},
+		{args: []string{"config", "remove-slot", "game"}, updateWant: "Failed to remove slot"},
+		{args: []string{"config", "set-hook", "reset", "notify"}, updateWant: "Failed to update hook"},
+		{args: []string{"config", "remove-hook", "reset"}, updateWant: "Failed to remove hook"},
+		{args: []string{"config", "set-selection-plugin", "ranker"}, updateWant: "Failed to update selection plugin"},
+		{args: []string{"config", "clear-selection-plugin"}, updateWant: "Failed to update selection plugin"},
+	}
+
+	for _, command := range commands {
+		t.Run(strings.Join(command.args[1:2], " ")+" load", func(t *testing.T) {
+			runtime := newStubRuntime()
+			runtime.config.loadErr = errors.New("config unreadable")
+			var stderr bytes.Buffer
+
+			_, code := ExecuteCommand(command.args, runtime, TerminalConsole{stderr: &stderr})
+
+			if code != 1 {
+				t.Fatalf("ExecuteCommand() code = %d, want 1", code)
+			}
+			assertOutputContains(t, stderr.String(), command.updateWant+": config unreadable")
+		})
+
+		t.Run(strings.Join(command.args[1:2], " ")+" save", func(t *testing.T) {
+			runtime := newStubRuntime()
+			config, err := mustCommandConfig(t, cliTestOutfitRoot, nil).WithSlot("game", entities.ActivationSlot{Target: "/outfitpicker-test/game/current.avatar", Mode: entities.ActivationModeCopy})
+			if err != nil {
+				t.Fatalf("WithSlot() error = %v", err)
+			}
+			runtime.config.currentConfig = config.WithHook(entities.HookEventReset, entities.Hook{Command: "notify"})
+			runtime.config.updateErr = errors.New("disk full")
+			var stderr bytes.Buffer
+
+			_, code := ExecuteCommand(command.args, runtime, TerminalConsole{stdout: &bytes.Buffer{}, stderr: &stderr})
+
+			if code != 1 {
+				t.Fatalf("ExecuteCommand() code = %d, want 1", code)
+			}
+			assertOutputContains(t, stderr.String(), command.updateWant+": disk full")
+		})
+	}
+}
+
+func TestExecuteCommand_ConfigSelectionPlugin(t *testing.T) {
+	runtime := newStubRuntime()
+	runtime.config.currentConfig = mustCommandConfig(t, cliTestOutfitRoot, nil)
+	var stdout bytes.Buffer
+
+	ExecuteCommand([]string{"config", "get"}, runtime, TerminalConsole{stdout: &stdout})
+	assertOutputContains(t, stdout.String(), "Selection: random")
+
+	_, code := ExecuteCommand([]string{"config", "set-selection-plugin", "--timeout", "2s", "ranker", "--prefer", "fresh"}, runtime, TerminalConsole{stdout: &stdout})
+	plugin := runtime.config.currentConfig.SelectionPlugin
+	if code != 0 || plugin == nil || plugin.String() != "ranker --prefer fresh" || plugin.TimeoutSeconds != 2 {
+		t.Fatalf("code = %d plugin = %#v, want ranker --prefer fresh with 2s timeout", code, plugin)
+	}
+	assertOutputContains(t, stdout.String(), "Outfits are now ranked by ranker --prefer fresh")
+
+	stdout.Reset()
+	ExecuteCommand([]string{"config", "get"}, runtime, TerminalConsole{stdout: &stdout})
+	assertOutputContains(t, stdout.String(), "Selection: ranker --prefer fresh (timeout 2s)")
+
+	_, code = ExecuteCommand([]string{"config", "clear-selection-plugin"}, runtime, TerminalConsole{stdout: &stdout})
+	if code != 0 || runtime.config.currentConfig.SelectionPlugin != nil {
+		t.Fatalf("code = %d plugin = %#v, want cleared", code, runtime.config.currentConfig.SelectionPlugin)
+	}
+	assertOutputContains(t, stdout.String(), "Outfits are now picked at random")
+
+	var stderr bytes.Buffer
+	_, code = ExecuteCommand([]string{"config", "set-selection-plugin", "--timeout", "1500ms", "ranker"}, runtime, TerminalConsole{stdout: &stdout, stderr: &stderr})
+	if code != 1 {
+		t.Fatalf("fractional timeout code = %d, want 1", code)
+	}
+	assertOutputContains(t, stderr.String(), "Failed to update selection plugin")
+}
+
+func TestExecuteCommand_ConfigSetStorage(t *testing.T) {
+	runtime := newStubRuntime()
+	runtime.config.currentConfig = mustCommandConfig(t, cliTestOutfitRoot, nil)
+	var stdout, stderr bytes.Buffer
+	console := TerminalConsole{stdout: &stdout, stderr: &stderr}
+
+	ExecuteCommand([]string{"config", "get"}, runtime, console)
+	assertOutputContains(t, stdout.String(), "Storage: json")
+
+	_, code := ExecuteCommand([]string{"config", "set-storage", "sqlite"}, runtime, console)
+	if code != 0 || len(runtime.selectedStorage) != 1 || runtime.selectedStorage[0] != entities.StorageSQLite {
+		t.Fatalf("code = %d selected = %v, want sqlite selected", code, runtime.selectedStorage)
+	}
+	assertOutputContains(t, stdout.String(), "Config and worn outfits are now stored in an SQLite database")
+
+	_, code = ExecuteCommand([]string{"config", "set-storage", "json"}, runtime, console)
+	if code != 0 {
+		t.Fatalf("code = %d, want 0", code)
+	}
+	assertOutputContains(t, stdout.String(), "Config and worn outfits are now stored in JSON files")
+
+	runtime.selectStorageErr = errors.New("database is read-only")
+	_, code = ExecuteCommand([]string{"config", "set-storage", "sqlite"}, runtime, console)
+	if code != 1 {
+		t.Fatalf("failing switch code = %d, want 1", code)
+	}
+	assertOutputContains(t, stderr.String(), "Failed to switch storage: database is read-only")
+
+	runtime.config.currentConfig.Storage = entities.StorageSQLite
+	stdout.Reset()
+	ExecuteCommand([]string{"config", "get"}, runtime, console)
+	assertOutputContains(t, stdout.String(), "Storage: sqlite")
+}
+
+func TestExecuteCommand_Restore(t *testing.T) {
+	runtime := newStubRuntime()
+	runtime.restoreState = entities.JournalState{
+		Cache: entities.NewOutfitCache().Updating("shoes", entities.NewCategoryCache(3).Adding("a.avatar").Adding("b.avatar")),
+	}
+	var stdout, stderr bytes.Buffer
+	console := TerminalConsole{stdout: &stdout, stderr: &stderr}
+
+	_, code := ExecuteCommand([]string{"restore", "--at", "2026-09-01"}, runtime, console)
+	if code != 0 || len(runtime.restoredAt) != 1 {
+		t.Fatalf("code = %d restored = %v, want one restore", code, runtime.restoredAt)
+	}
+	want := time.Date(2026, 9, 2, 0, 0, 0, 0, time.Local).Add(-time.Nanosecond)
+	if !runtime.restoredAt[0].Equal(want) {
+		t.Fatalf("restored at %v, want the end of the day %v", runtime.restoredAt[0], want)
+	}
+	assertOutputContains(t, stdout.String(), "Restored config and worn outfits as of 2026-09-01 (2 worn outfits)")
+
+	_, code = ExecuteCommand([]string{"restore", "--at", "2026-09-01T08:30:00Z"}, runtime, console)
+	if code != 0 || !runtime.restoredAt[1].Equal(time.Date(2026, 9, 1, 8, 30, 0, 0, time.UTC)) {
+		t.Fatalf("code = %d restored = %v, want the exact time", code, runtime.restoredAt)
+	}
+
+	_, code = ExecuteCommand([]string{"restore", "--at", "last week"}, runtime, console)
+	if code != 2 || len(runtime.restoredAt) != 2 {
+		t.Fatalf("invalid date code = %d restored = %v, want 2 and no restore", code, runtime.restoredAt)
+	}
+	assertOutputContains(t, stderr.String(), `invalid date "last week"`)
+
+	runtime.restoreErr = domainerrors.NewNothingToRestoreError(want)
+	_, code = ExecuteCommand([]string{"restore", "--at", "2026-09-01"}, runtime, console)
+	if code != 1 {
+		t.Fatalf("failing restore code = %d, want 1", code)
+	}
+	assertOutputContains(t, stderr.String(), "Failed to restore: journal has no configuration to restore")
+}
+
+func TestExecuteCommand_Backup(t *testing.T) {
+	runtime := newStubRuntime()
+	var stdout, stderr bytes.Buffer
+	console := TerminalConsole{stdout: &stdout, stderr: &stderr}
+
+	if _, code := ExecuteCommand([]string{"backup", "list"}, runtime, console); code != 0 {
+		t.Fatalf("empty list code = %d, want 0", code)
+	}
+	assertOutputContains(t, stdout.String(), "No backups found")
+
+	_, code := ExecuteCommand([]string{"backup", "create"}, runtime, console)
+	if code != 0 || !reflect.DeepEqual(runtime.createdBackups, []string{""}) {
+		t.Fatalf("create code = %d created = %v, want one backup in the backups directory", code, runtime.createdBackups)
+	}
+	assertOutputContains(t, stdout.String(), "Created backup /outfitpicker-test/backups/backup-1.tar.gz")
+	if _, code := ExecuteCommand([]string{"backup", "create", "/tmp/wardrobe.tar.gz"}, runtime, console); code != 0 || runtime.createdBackups[1] != "/tmp/wardrobe.tar.gz" {
+		t.Fatalf("create at path code = %d created = %v", code, runtime.createdBackups)
+	}
+
+	created := time.Date(2026, 9, 1, 8, 0, 0, 0, time.Local)
+	runtime.backups = []entities.BackupInfo{
+		{Name: "snapshot-1", Kind: entities.BackupSnapshot, Reason: "factory-reset", CreatedAt: created.Add(time.Hour)},
+		{Name: "backup-1", Kind: entities.BackupManual, CreatedAt: created},
+	}
+	if _, code := ExecuteCommand([]string{"backup", "list"}, runtime, console); code != 0 {
+		t.Fatalf("list code = %d, want 0", code)
+	}
+	assertOutputContains(t, stdout.String(), "snapshot-1\t2026-09-01 09:00:00\tsnapshot before factory-reset")
+	assertOutputContains(t, stdout.String(), "backup-1\t2026-09-01 08:00:00\tbackup\n")
+
+	cache := entities.NewOutfitCache().Updating("shoes", entities.NewCategoryCache(3).Adding("a.avatar"))
+	runtime.restoredContents = entities.BackupContents{Cache: &cache}
+	if _, code := ExecuteCommand([]string{"backup", "restore", "backup-1"}, runtime, console); code != 0 || runtime.restoredBackups[0] != "backup-1" {
+		t.Fatalf("restore code = %d restored = %v", code, runtime.restoredBackups)
+	}
+	assertOutputContains(t, stdout.String(), "Restored config and worn outfits from backup-1 (1 worn outfit)")
+	runtime.restoredContents = entities.BackupContents{}
+	if _, code := ExecuteCommand([]string{"backup", "restore", "snapshot-1"}, runtime, console); code != 0 {
+		t.Fatalf("restore without cache code = %d, want 0", code)
+	}
+	assertOutputContains(t, stdout.String(), "from snapshot-1 (0 worn outfits)")
+	if _, code := ExecuteCommand([]string{"backup", "restore", " "}, runtime, console); code != 2 || len(runtime.restoredBackups) != 2 {
+		t.Fatalf("empty restore code = %d restored = %v, want 2 and no restore", code, runtime.restoredBackups)
+	}
+
+	if _, code := ExecuteCommand([]string{"backup", "prune"}, runtime, console); code != 0 || runtime.prunedKeep[0] != 5 {
+		t.Fatalf("prune code = %d keep = %v, want the default of 5", code, runtime.prunedKeep)
+	}
+	assertOutputContains(t, stdout.String(), "Deleted 0 old backups")
+	if _, code := ExecuteCommand([]string{"backup", "prune", "--keep", "1"}, runtime, console); code != 0 {
+		t.Fatalf("prune code = %d, want 0", code)
+	}
+	assertOutputContains(t, stdout.String(), "Deleted 1 old backup")
+	if strings.Contains(stdout.String(), "Deleted 1 old backups") {
+		t.Fatalf("output = %q, want the singular", stdout.String())
+	}
+	if _, code := ExecuteCommand([]string{"backup", "prune", "--keep", "-1"}, runtime, console); code != 2 || len(runtime.prunedKeep) != 2 {
+		t.Fatalf("negative prune code = %d keep = %v, want 2 and no prune", code, runtime.prunedKeep)
+	}
+}
+
+func TestExecuteCommand_BackupFailures(t *testing.T) {
+	runtime := newStubRuntime()
+	runtime.backupErr = errors.New("disk full")
+	var stdout, stderr bytes.Buffer
+	console := TerminalConsole{stdout: &stdout, stderr: &stderr}
+
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"backup", "create"}, want: "Failed to create backup: disk full"},
+		{args: []string{"backup", "list"}, want: "Failed to list backups: disk full"},
+		{args: []string{"backup", "restore", "backup-1"}, want: "Failed to restore backup: disk full"},
+		{args: []string{"backup", "prune"}, want: "Failed to prune backups: disk full"},
+	} {
+		if _, code := ExecuteCommand(tt.args, runtime, console); code != 1 {
+			t.Fatalf("%v code = %d, want 1", tt.args, code)
+		}
+		assertOutputContains(t, stderr.String(), tt.want)
+	}
+}
+
+func TestExecuteSetupCommand_Profiles(t *testing.T) {
+	profiles := newStubProfileManager("alex")
+	profiles.active = "alex"
+	var stdout, stderr bytes.Buffer
+	console := TerminalConsole{stdout: &stdout, stderr: &stderr}
+
+	if handled, code := ExecuteSetupCommand([]string{"pick"}, SetupRuntime{Profiles: profiles}, console); handled || code != 0 {
+		t.Fatalf("pick = handled %t code %d, want it left for ExecuteCommand", handled, code)
+	}
+	if handled, code := ExecuteSetupCommand([]string{"profile", "list"}, SetupRuntime{Profiles: profiles}, console); !handled || code != 0 {
+		t.Fatalf("list = handled %t code %d, want handled", handled, code)
+	}
+	assertOutputContains(t, stdout.String(), "  default\t/outfitpicker-test/default\n", "* alex\t/outfitpicker-test/alex\n")
+
+	if _, code := ExecuteSetupCommand([]string{"profile", "create", "sam"}, SetupRuntime{Profiles: profiles}, console); code != 0 || !reflect.DeepEqual(profiles.created, []string{"sam"}) {
+		t.Fatalf("create code = %d created = %v", code, profiles.created)
+	}
+	assertOutputContains(t, stdout.String(), "Created profile sam", "outfitpicker --profile sam")
+	if _, code := ExecuteSetupCommand([]string{"profile", "copy", "default", "kim"}, SetupRuntime{Profiles: profiles}, console); code != 0 || profiles.copied[0] != [2]string{"default", "kim"} {
+		t.Fatalf("copy code = %d copied = %v", code, profiles.copied)
+	}
+	assertOutputContains(t, stdout.String(), "Copied profile default to kim")
+	if _, code := ExecuteSetupCommand([]string{"profile", "delete", "sam"}, SetupRuntime{Profiles: profiles}, console); code != 0 || !reflect.DeepEqual(profiles.deleted, []string{"sam"}) {
+		t.Fatalf("delete code = %d deleted = %v", code, profiles.deleted)
+	}
+	assertOutputContains(t, stdout.String(), "Deleted profile sam")
+
+	if _, code := ExecuteSetupCommand([]string{"profile", "copy", "default"}, SetupRuntime{Profiles: profiles}, console); code != 2 {
+		t.Fatalf("copy without a target code = %d, want 2", code)
+	}
+}
+
+func TestExecuteSetupCommand_ProfileFailures(t *testing.T) {
+	profiles := newStubProfileManager()
+	profiles.err = errors.New("disk full")
+	var stdout, stderr bytes.Buffer
+	console := TerminalConsole{stdout: &stdout, stderr: &stderr}
+
+	for _, tt := range []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"profile", "create", "sam"}, want: "Failed to create profile: disk full"},
+		{args: []string{"profile", "list"}, want: "Failed to list profiles: disk full"},
+		{args: []string{"profile", "copy", "default", "sam"}, want: "Failed to copy profile: disk full"},
+		{args: []string{"profile", "delete", "sam"}, want: "Failed to delete profile: disk full"},
+	} {
+		if _, code := ExecuteSetupCommand(tt.args, SetupRuntime{Profiles: profiles}, console); code != 1 {
+			t.Fatalf("%v code = %d, want 1", tt.args, code)
+		}
+		assertOutputContains(t, stderr.String(), tt.want)
+
+		if _, code := ExecuteCommand(tt.args, newStubRuntime(), console); code != 1 {
+			t.Fatalf("%v without profiles code = %d, want 1", tt.args, code)
+		}
+		assertOutputContains(t, stderr.String(), errProfilesUnavailable.Error())
+	}
+}
+
+type stubWardrobeInitializer struct {
+	roots    []string
+	language string
+	portable bool
+	carried  bool
+	err      error
+}
+
+func (s *stubWardrobeInitializer) Init(root, language string) (*entities.Config, error) {
+	s.roots, s.language = append(s.roots, root), language
+	return &entities.Config{Root: root, Language: language}, s.err
+}
+
+func (s *stubWardrobeInitializer) InitPortable(root, language string) (*entities.Config, bool, error) {
+	s.portable = true
+	config, err := s.Init(root, language)
+	return config, s.carried, err
+}
+
+func TestExecuteSetupCommand_Init(t *testing.T) {
+	wardrobes := &stubWardrobeInitializer{}
+	setup := SetupRuntime{Wardrobes: func() (WardrobeInitializer, error) { return wardrobes, nil }}
+	var stdout, stderr bytes.Buffer
+	console := TerminalConsole{stdout: &stdout, stderr: &stderr}
+	root := cliTestHomeTempDir(t, "outfitpicker-init-wardrobe-*")
+
+	if handled, code := ExecuteSetupCommand([]string{"init", root, "--language", "fr"}, setup, console); !handled || code != 0 {
+		t.Fatalf("init = handled %t code %d, want handled", handled, code)
+	}
+	if wardrobes.portable || wardrobes.roots[0] != root || wardrobes.language != "fr" {
+		t.Fatalf("Init() got %+v, want %s in fr", wardrobes, root)
+	}
+	assertOutputContains(t, stdout.String(), "Set up wardrobe "+root)
+
+	workingDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, code := ExecuteSetupCommand([]string{"init", "--portable"}, setup, console); code != 0 {
+		t.Fatalf("init --portable code = %d, want 0", code)
+	}
+	if !wardrobes.portable || wardrobes.roots[1] != workingDir || wardrobes.language != entities.DefaultLanguage {
+		t.Fatalf("InitPortable() got %+v, want the working directory in the default language", wardrobes)
+	}
+	assertOutputContains(t, stdout.String(), "Made wardrobe "+workingDir+" portable", "Config and worn outfits are kept in "+filepath.Join(workingDir, entities.PortableDirName))
+
+	wardrobes.carried = true
+	if _, code := ExecuteSetupCommand([]string{"init", root, "--portable"}, setup, console); code != 0 {
+		t.Fatalf("init --portable code = %d, want 0", code)
+	}
+	assertOutputContains(t, stdout.String(), "Copied this profile's settings and worn outfits into "+filepath.Join(root, entities.PortableDirName))
+}
+
+func TestExecuteSetupCommand_InitFailures(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	console := TerminalConsole{stdout: &stdout, stderr: &stderr}
+	failing := &stubWardrobeInitializer{err: errors.New("already set up")}
+
+	for _, tt := range []struct {
+		name  string
+		args  []string
+		setup SetupRuntime
+		want  string
+	}{
+		{name: "unavailable", args: []string{"init"}, want: errWardrobeSetupUnavailable.Error()},
+		{name: "profile", args: []string{"init"}, setup: SetupRuntime{Wardrobes: func() (WardrobeInitializer, error) {
+			return nil, errors.New("profile not found: sam")
+		}}, want: "Failed to set up wardrobe: profile not found: sam"},
+		{name: "init", args: []string{"init"}, setup: SetupRuntime{Wardrobes: func() (WardrobeInitializer, error) { return failing, nil }}, want: "Failed to set up wardrobe: already set up"},
+		{name: "portable", args: []string{"init", "--portable"}, setup: SetupRuntime{Wardrobes: func() (WardrobeInitializer, error) { return failing, nil }}, want: "Failed to set up wardrobe: already set up"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, code := ExecuteSetupCommand(tt.args, tt.setup, console); code != 1 {
+				t.Fatalf("%v code = %d, want 1", tt.args, code)
+			}
+			assertOutputContains(t, stderr.String(), tt.want)
+		})
+	}
+	if _, code := ExecuteCommand([]string{"init"}, newStubRuntime(), console); code != 1 {
+		t.Fatalf("init without setup code = %d, want 1", code)
+	}
+	if _, code := ExecuteSetupCommand([]string{"init", "a", "b"}, SetupRuntime{}, console); code != 2 {
+		t.Fatalf("init with two roots code = %d, want 2", code)
+	}
+}
+
+func TestExecuteCommand_DoctorRoots(t *testing.T) {
+	runtime := newStubRuntime()
+	stateDir := t.TempDir()
+	configPath := filepath.Join(stateDir, "config.json")
+	if err := os.WriteFile(configPath, []byte("{}"), 0600); err != nil {
+		t.Fatalf("WriteFile(config) error = %v", err)
+	}
+	runtime.pathProvider = StaticStoragePathProvider{ConfigPath: configPath, CachePath: filepath.Join(stateDir, "cache.json")}
+	config, err := mustCommandConfig(t, "/ssd", nil).WithRoots([]string{"/ssd", "/nas", "/usb"})
+	if err != nil {
+		t.Fatalf("WithRoots() error = %v", err)
+	}
+	runtime.config.currentConfig = config
+	runtime.wardrobe.rootStatuses = []entities.RootStatus{
+		{Root: "/ssd", Categories: 2, Outfits: 1},
+		{Root: "/nas", Err: &os.PathError{Op: "open", Path: "/nas", Err: os.ErrNotExist}},
+		{Root: "/usb", Err: errors.New("permission denied")},
+	}
+	runtime.wardrobe.allOutfitStates = map[string]entities.CategoryOutfitState{}
+
+	var stdout, stderr bytes.Buffer
+	handled, code := ExecuteCommand([]string{"doctor"}, runtime, TerminalConsole{stdout: &stdout, stderr: &stderr})
+
+	if !handled || code != 1 {
+		t.Fatalf("ExecuteCommand() = handled %t code %d, want handled true code 1", handled, code)
+	}
+	assertOutputContains(t, stdout.String(), "Wardrobe directory /ssd: 2 categories, 1 .avatar file", "Wardrobe directory /nas does not exist", "Cache file is valid")
+	assertOutputContains(t, stderr.String(), "Wardrobe directory /usb is not accessible: permission denied")
+
+	runtime.wardrobe.rootErr = errors.New("config unreadable")
+	stderr.Reset()
+	if _, code := ExecuteCommand([]string{"doctor"}, runtime, TerminalConsole{stdout: &stdout, stderr: &stderr}); code != 1 {
+		t.Fatalf("doctor exit code = %d, want 1", code)
+	}
+	assertOutputContains(t, stderr.String(), "Could not scan wardrobe roots: config unreadable")
+}
+
+func TestExecuteCommand_DoctorRenames(t *testing.T) {
+	newRuntime := func(t *testing.T) *stubRuntime {
+		runtime := newStubRuntime()
+		stateDir := t.TempDir()
+		configPath := filepath.Join(stateDir, "config.json")
+		if err := os.WriteFile(configPath, []byte("{}"), 0600); err != nil {
+			t.Fatalf("WriteFile(config) error = %v", err)
+		}
+		runtime.pathProvider = StaticStoragePathProvider{ConfigPath: configPath, CachePath: filepath.Join(stateDir, "cache.json")}
+		runtime.config.currentConfig = mustCommandConfig(t, cliTestHomeTempDir(t, "outfitpicker-doctor-wardrobe-*"), nil).WithIdentity(entities.IdentityContent)
+		runtime.wardrobe.allOutfitStates = map[string]entities.CategoryOutfitState{}
+		return runtime
+	}
+
+	t.Run("reports renames", func(t *testing.T) {
+		runtime := newRuntime(t)
+		runtime.renames = []entities.OutfitRename{{FromCategory: "casual", From: "old.avatar", ToCategory: "formal", To: "new.avatar"}}
+		var stdout bytes.Buffer
+
+		if _, code := ExecuteCommand([]string{"doctor"}, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("doctor exit code = %d, want 0", code)
+		}
+		assertOutputContains(t, stdout.String(), "Found casual/old.avatar -> formal/new.avatar renamed; its worn state moved with it")
+	})
+
+	t.Run("no renames", func(t *testing.T) {
+		var stdout bytes.Buffer
+		if _, code := ExecuteCommand([]string{"doctor"}, newRuntime(t), TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("doctor exit code = %d, want 0", code)
+		}
+		assertOutputContains(t, stdout.String(), "No renamed or moved outfits found")
+	})
+
+	t.Run("matching failed", func(t *testing.T) {
+		runtime := newRuntime(t)
+		runtime.renameErr = errors.New("index unreadable")
+		var stderr bytes.Buffer
+
+		if _, code := ExecuteCommand([]string{"doctor"}, runtime, TerminalConsole{stdout: &bytes.Buffer{}, stderr: &stderr}); code != 1 {
+			t.Fatalf("doctor exit code = %d, want 1", code)
+		}
+		assertOutputContains(t, stderr.String(), "Could not match renamed outfits: index unreadable")
+	})
+}
+
+func TestExecuteCommand_Changes(t *testing.T) {
+	t.Run("lists changes", func(t *testing.T) {
+		runtime := newStubRuntime()
+		runtime.changes = entities.WardrobeChanges{Categories: []entities.CategoryChanges{
+			{Category: "beach", Added: true, AddedOutfits: []string{"towel.avatar"}},
+			{Category: "casual", AddedOutfits: []string{"new.avatar@/mnt/nas"}, RemovedOutfits: []string{"old.avatar"}},
+			{Category: "hats", Removed: true, RemovedOutfits: []string{"cap.avatar"}},
+		}}
+		var stdout bytes.Buffer
+
+		if _, code := ExecuteCommand([]string{"changes"}, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("changes exit code = %d, want 0", code)
+		}
+		assertOutputContains(t, stdout.String(),
+			"New category beach",
+			"+ beach/towel.avatar",
+			"+ casual/new.avatar@/mnt/nas",
+			"- casual/old.avatar",
+			"Removed category hats",
+			"- hats/cap.avatar",
+			"2 outfits added and 2 removed since last time",
+		)
+	})
+
+	t.Run("nothing changed", func(t *testing.T) {
+		var stdout bytes.Buffer
+		if _, code := ExecuteCommand([]string{"changes"}, newStubRuntime(), TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("changes exit code = %d, want 0", code)
+		}
+		assertOutputContains(t, stdout.String(), "No outfits added or removed since last time")
+	})
+
+	t.Run("unreadable wardrobe", func(t *testing.T) {
+		runtime := newStubRuntime()
+		runtime.changes = entities.WardrobeChanges{Unreadable: []string{"/mnt/nas"}}
+		var stdout bytes.Buffer
+
+		if _, code := ExecuteCommand([]string{"changes"}, runtime, TerminalConsole{stdout: &stdout}); code != 1 {
+			t.Fatalf("changes exit code = %d, want 1", code)
+		}
+		assertOutputContains(t, stdout.String(), "Could not read /mnt/nas", "once the whole wardrobe can be read")
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		runtime := newStubRuntime()
+		runtime.changesErr = errors.New("config locked")
+		var stderr bytes.Buffer
+
+		if _, code := ExecuteCommand([]string{"changes"}, runtime, TerminalConsole{stdout: &bytes.Buffer{}, stderr: &stderr}); code != 1 {
+			t.Fatalf("changes exit code = %d, want 1", code)
+		}
+		assertOutputContains(t, stderr.String(), "Failed to look for changes: config locked")
+	})
+}
+
+func TestExecuteCommand_Queue(t *testing.T) {
+	queuedAt := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	t.Run("lists queued wears while offline", func(t *testing.T) {
+		runtime := newStubRuntime()
+		runtime.offline = true
+		runtime.queued = []entities.QueuedWear{
+			{Category: "casual", FileName: "look.avatar", QueuedAt: queuedAt},
+			{Category: "formal", FileName: "suit.avatar", Root: "/mnt/nas", QueuedAt: queuedAt},
+		}
+		var stdout bytes.Buffer
+
+		if _, code := ExecuteCommand([]string{"queue", "list"}, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("queue list exit code = %d, want 0", code)
+		}
+		assertOutputContains(t, stdout.String(), "Wardrobe is offline", queuedAt.Local().Format("2006-01-02 15:04:05")+"\tcasual/look.avatar", "formal/suit.avatar@/mnt/nas")
+	})
+
+	t.Run("nothing queued", func(t *testing.T) {
+		var stdout bytes.Buffer
+		if _, code := ExecuteCommand([]string{"queue", "list"}, newStubRuntime(), TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("queue list exit code = %d, want 0", code)
+		}
+		assertOutputContains(t, stdout.String(), "No wears queued")
+	})
+
+	t.Run("clears queued wears", func(t *testing.T) {
+		runtime := newStubRuntime()
+		runtime.queued = []entities.QueuedWear{{Category: "casual", FileName: "look.avatar", QueuedAt: queuedAt}}
+		var stdout bytes.Buffer
+
+		if _, code := ExecuteCommand([]string{"queue", "clear"}, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("queue clear exit code = %d, want 0", code)
+		}
+		assertOutputContains(t, stdout.String(), "Dropped 1 queued wear")
+		if runtime.queued != nil {
+			t.Errorf("queued = %+v after clearing, want none", runtime.queued)
+		}
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		runtime := newStubRuntime()
+		runtime.queueErr = errors.New("config locked")
+		var stderr bytes.Buffer
+
+		for _, args := range [][]string{{"queue", "list"}, {"queue", "clear"}} {
+			if _, code := ExecuteCommand(args, runtime, TerminalConsole{stdout: &bytes.Buffer{}, stderr: &stderr}); code != 1 {
+				t.Fatalf("%v exit code = %d, want 1", args, code)
+			}
+		}
+		assertOutputContains(t, stderr.String(), "Failed to list queued wears: config locked", "Failed to clear queued wears: config locked")
+	})
+}
+
+func TestExecuteCommand_WearHistory(t *testing.T) {
+	wornAt := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	t.Run("lists wears and counts", func(t *testing.T) {
+		runtime := newStubRuntime()
+		runtime.wears = []entities.WearRecord{{Category: "casual", Outfit: "look.avatar", WornAt: wornAt}}
+		runtime.wearStats = []entities.OutfitWearStats{{Category: "casual", Outfit: "look.avatar", Wears: 3, LastWorn: wornAt}}
+		var stdout bytes.Buffer
+
+		for _, args := range [][]string{{"history", "--category", "casual", "--limit", "5"}, {"history"}, {"stats", "--category", "casual"}} {
+			if _, code := ExecuteCommand(args, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+				t.Fatalf("%v exit code = %d, want 0", args, code)
+			}
+		}
+		if want := []string{"history casual 5", "history  20", "stats casual"}; !reflect.DeepEqual(runtime.historyCalls, want) {
+			t.Fatalf("history calls = %q, want %q", runtime.historyCalls, want)
+		}
+		assertOutputContains(t, stdout.String(), wornAt.Local().Format("2006-01-02 15:04:05")+"\tcasual/look.avatar", "3\tcasual/look.avatar\tlast worn "+wornAt.Local().Format("2006-01-02"))
+	})
+
+	t.Run("nothing recorded", func(t *testing.T) {
+		var stdout bytes.Buffer
+		for _, args := range [][]string{{"history"}, {"stats"}} {
+			if _, code := ExecuteCommand(args, newStubRuntime(), TerminalConsole{stdout: &stdout}); code != 0 {
+				t.Fatalf("%v exit code = %d, want 0", args, code)
+			}
+		}
+		assertOutputContains(t, stdout.String(), "No wears recorded")
+	})
+
+	t.Run("json storage", func(t *testing.T) {
+		runtime := newStubRuntime()
+		runtime.historyErr = domainerrors.ErrNoWearHistory
+		var stdout, stderr bytes.Buffer
+
+		if _, code := ExecuteCommand([]string{"stats"}, runtime, TerminalConsole{stdout: &stdout, stderr: &stderr}); code != 1 {
+			t.Fatalf("stats exit code = %d, want 1", code)
+		}
+		assertOutputContains(t, stderr.String(), "Wear history is only kept with sqlite storage")
+		assertOutputContains(t, stdout.String(), "outfitpicker config set-storage sqlite")
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		runtime := newStubRuntime()
+		runtime.historyErr = errors.New("database locked")
+		var stderr bytes.Buffer
+
+		if _, code := ExecuteCommand([]string{"history"}, runtime, TerminalConsole{stdout: &bytes.Buffer{}, stderr: &stderr}); code != 1 {
+			t.Fatalf("history exit code = %d, want 1", code)
+		}
+		if _, code := ExecuteCommand([]string{"history", "--limit=-1"}, runtime, TerminalConsole{stdout: &bytes.Buffer{}, stderr: &stderr}); code != 2 {
+			t.Fatalf("history --limit -1 exit code = %d, want 2", code)
+		}
+		assertOutputContains(t, stderr.String(), "Failed to read wear history: database locked", "--limit cannot be negative")
+	})
+}
+
+func TestExecuteCommand_CacheReconcile(t *testing.T) {
+	t.Run("reports differences", func(t *testing.T) {
+		runtime := newStubRuntime()
+		runtime.reconciliation = entities.CacheReconciliation{Categories: []entities.CategoryReconciliation{
+			{Category: "beach", OldTotal: 2, Orphans: []string{"towel.avatar"}, Removed: true},
+			{Category: "casual", OldTotal: 3, NewTotal: 1, Orphans: []string{"a.avatar", "b.avatar"}},
+			{Category: "formal", OldTotal: 1, NewTotal: 2},
+		}}
+		var stdout bytes.Buffer
+
+		if _, code := ExecuteCommand([]string{"cache", "reconcile"}, runtime, TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("cache reconcile exit code = %d, want 0", code)
+		}
+		assertOutputContains(t, stdout.String(),
+			"beach: no longer in the wardrobe; dropped it with 1 worn outfit",
+			"casual: 1 outfit, was 3",
+			"casual: dropped deleted outfits a.avatar, b.avatar",
+			"formal: 2 outfits, was 1",
+			"Reconciled 3 categories with the wardrobe",
+		)
+	})
+
+	t.Run("nothing to change", func(t *testing.T) {
+		var stdout bytes.Buffer
+		if _, code := ExecuteCommand([]string{"cache", "reconcile"}, newStubRuntime(), TerminalConsole{stdout: &stdout}); code != 0 {
+			t.Fatalf("cache reconcile exit code = %d, want 0", code)
+		}
+		assertOutputContains(t, stdout.String(), "Worn outfits match the wardrobe")
+	})
+
+	t.Run("unreadable wardrobe", func(t *testing.T) {
+		runtime := newStubRuntime()
+		runtime.reconciliation = entities.CacheReconciliation{Unreadable: []string{"/mnt/nas"}}
+		var stdout bytes.Buffer
+
+		if _, code := ExecuteCommand([]string{"cache", "reconcile"}, runtime, TerminalConsole{stdout: &stdout}); code != 1 {
+			t.Fatalf("cache reconcile exit code = %d, want 1", code)
+		}
+		assertOutputContains(t, stdout.String(), "Could not read /mnt/nas", "Worn outfits were left as they are")
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		runtime := newStubRuntime()
+		runtime.reconcileErr = errors.New("cache locked")
+		var stderr bytes.Buffer
+
+		if _, code := ExecuteCommand([]string{"cache", "reconcile"}, runtime, TerminalConsole{stdout: &bytes.Buffer{}, stderr: &stderr}); code != 1 {
+			t.Fatalf("cache reconcile exit code = %d, want 1", code)
+		}
+		assertOutputContains(t, stderr.String(), "Failed to reconcile cache: cache locked")
+	})
+}
+
+func TestExecuteCommand_DoctorRepair(t *testing.T) {
+	newRuntime := func(t *testing.T) *stubRuntime {
+		runtime := newStubRuntime()
+		stateDir := t.TempDir()
+		configPath := filepath.Join(stateDir, "config.json")
+		if err := os.WriteFile(configPath, []byte("{}"), 0600); err != nil {
+			t.Fatalf("WriteFile(config) error = %v", err)
+		}
+		runtime.pathProvider = StaticStoragePathProvider{ConfigPath: configPath, CachePath: filepath.Join(stateDir, "cache.json")}
+		runtime.config.currentConfig = mustCommandConfig(t, cliTestHomeTempDir(t, "outfitpicker-doctor-wardrobe-*"), nil)
+		runtime.wardrobe.allOutfitStatesErr = errors.New("bad json")
+		return runtime
+	}
+
+	t.Run("suggests repair", func(t *testing.T) {
+		runtime := newRuntime(t)
+		var stdout, stderr bytes.Buffer
+		_, code := ExecuteCommand([]string{"doctor"}, runtime, TerminalConsole{stdout: &stdout, stderr: &stderr})
+		if code != 1 || runtime.rebuildCalls != 0 {
+			t.Fatalf("code = %d rebuilds = %d, want 1 and no rebuild", code, runtime.rebuildCalls)
+		}
+		assertOutputContains(t, stdout.String(), "outfitpicker doctor --repair")
+	})
+
+	t.Run("rebuilds from the journal", func(t *testing.T) {
+		runtime := newRuntime(t)
+		runtime.rebuiltCache = entities.NewOutfitCache().Updating("shoes", entities.NewCategoryCache(3).Adding("a.avatar"))
+		var stdout, stderr bytes.Buffer
+		_, code := ExecuteCommand([]string{"doctor", "--repair"}, runtime, TerminalConsole{stdout: &stdout, stderr: &stderr})
+		if code != 0 || runtime.rebuildCalls != 1 {
+			t.Fatalf("code = %d rebuilds = %d, want 0 and one rebuild", code, runtime.rebuildCalls)
+		}
+		assertOutputContains(t, stderr.String(), "Cache file is invalid")
+		assertOutputContains(t, stdout.String(), "Rebuilt cache file from the journal with 1 worn outfit")
+	})
+
+	t.Run("rebuild fails", func(t *testing.T) {
+		runtime := newRuntime(t)
+		runtime.rebuildCacheErr = errors.New("disk full")
+		var stdout, stderr bytes.Buffer
+		_, code := ExecuteCommand([]string{"doctor", "--repair"}, runtime, TerminalConsole{stdout: &stdout, stderr: &stderr})
+		if code != 1 {
+			t.Fatalf("code = %d, want 1", code)
+		}
+		assertOutputContains(t, stderr.String(), "Could not rebuild cache file from the journal: disk full")
+	})
+}
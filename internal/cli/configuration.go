@@ -90,10 +90,8 @@ func setupCategoryPreviewStatus(info entities.CategoryInfo) string {
 		return "empty"
 	case entities.CategoryStateNoAvatarFiles:
 		return "no .avatar files found"
-	case entities.CategoryStateUserExcluded:
-		return "excluded"
 	default:
-		return string(info.State)
+		return categoryStateLabel(info.State)
 	}
 }
 
@@ -222,6 +220,9 @@ func setupCategorySelectionSuffix(info entities.CategoryInfo) string {
 	case entities.CategoryStateNoAvatarFiles:
 		return " (no .avatar files)"
 	default:
+		if info.State.Failed() {
+			return fmt.Sprintf(" (%s)", categoryStateLabel(info.State))
+		}
 		return ""
 	}
 }
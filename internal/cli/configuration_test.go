@@ -296,6 +296,11 @@ func TestSetupCategorySelectionSuffix(t *testing.T) {
 			info: entities.NewCategoryInfo(entities.NewCategoryReference("Docs", "/root/Docs"), entities.CategoryStateNoAvatarFiles, 0),
 			want: " (no .avatar files)",
 		},
+		{
+			name: "timed out",
+			info: entities.NewCategoryInfo(entities.NewCategoryReference("NAS", "/root/NAS"), entities.CategoryStateTimedOut, 0),
+			want: " (not responding)",
+		},
 		{
 			name: "other state has no suffix",
 			info: entities.NewCategoryInfo(entities.NewCategoryReference("Excluded", "/root/Excluded"), entities.CategoryStateUserExcluded, 0),
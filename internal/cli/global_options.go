@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/validation"
+)
+
+// GlobalOptions holds flags that apply before command dispatch, to both the
+// interactive menus and non-interactive commands.
+type GlobalOptions struct {
+	TUI       bool
+	NoPreview bool
+	NoHooks   bool
+	// LockTimeout bounds the wait for config, cache and slot locks held by
+	// another instance. Zero waits until the lock is released.
+	LockTimeout time.Duration
+	// Profile names the profile whose config and worn outfits are used. Empty
+	// means none was chosen on the command line or in the environment.
+	Profile string
+}
+
+// ProfileEnvironmentVariable selects a profile when --profile is not given.
+const ProfileEnvironmentVariable = "OUTFITPICKER_PROFILE"
+
+// globalFlags declares the options handled by ParseGlobalOptions so that they
+// appear in --help output. Their values are never read from the kong parse.
+type globalFlags struct {
+	TUI         bool          `name:"tui" help:"Use the full-screen keyboard interface instead of the prompt-based menu."`
+	NoPreview   bool          `name:"no-preview" help:"Do not show companion outfit images in the terminal."`
+	NoHooks     bool          `name:"no-hooks" help:"Do not run configured hooks."`
+	LockTimeout time.Duration `name:"lock-timeout" placeholder:"DURATION" help:"Give up waiting for another instance's file lock after this long (e.g. 5s). Waits indefinitely by default."`
+	Profile     string        `name:"profile" placeholder:"NAME" help:"Use the named profile's config and worn outfits. Defaults to $OUTFITPICKER_PROFILE, then the default profile."`
+}
+
+// ParseGlobalOptions extracts the global flags given before the command from
+// args and returns the remaining arguments for command parsing. Scanning
+// stops at the first other argument, so that the command's own arguments,
+// such as a hook's command line, are left alone.
+func ParseGlobalOptions(args []string) (GlobalOptions, []string, error) {
+	var options GlobalOptions
+	for index := 0; index < len(args); index++ {
+		arg := args[index]
+		if value, ok := strings.CutPrefix(arg, lockTimeoutFlag+"="); ok {
+			timeout, err := parseLockTimeout(value)
+			if err != nil {
+				return GlobalOptions{}, nil, err
+			}
+			options.LockTimeout = timeout
+			continue
+		}
+		if value, ok := strings.CutPrefix(arg, profileFlag+"="); ok {
+			profile, err := parseProfile(value)
+			if err != nil {
+				return GlobalOptions{}, nil, err
+			}
+			options.Profile = profile
+			continue
+		}
+		switch arg {
+		case profileFlag:
+			if index+1 >= len(args) {
+				return GlobalOptions{}, nil, fmt.Errorf("%s needs a profile name", profileFlag)
+			}
+			index++
+			profile, err := parseProfile(args[index])
+			if err != nil {
+				return GlobalOptions{}, nil, err
+			}
+			options.Profile = profile
+		case lockTimeoutFlag:
+			if index+1 >= len(args) {
+				return GlobalOptions{}, nil, fmt.Errorf("%s needs a duration such as 5s", lockTimeoutFlag)
+			}
+			index++
+			timeout, err := parseLockTimeout(args[index])
+			if err != nil {
+				return GlobalOptions{}, nil, err
+			}
+			options.LockTimeout = timeout
+		case "--tui":
+			options.TUI = true
+		case "--no-preview":
+			options.NoPreview = true
+		case "--no-hooks":
+			options.NoHooks = true
+		default:
+			return options, append([]string{}, args[index:]...), nil
+		}
+	}
+	return options, []string{}, nil
+}
+
+// misplacedFlag names a global flag that was given after the command, where
+// ParseGlobalOptions no longer takes it, or returns "".
+func (f globalFlags) misplacedFlag() string {
+	switch {
+	case f.TUI:
+		return "--tui"
+	case f.NoPreview:
+		return "--no-preview"
+	case f.NoHooks:
+		return "--no-hooks"
+	case f.LockTimeout != 0:
+		return lockTimeoutFlag
+	case f.Profile != "":
+		return profileFlag
+	default:
+		return ""
+	}
+}
+
+const lockTimeoutFlag = "--lock-timeout"
+
+func parseLockTimeout(value string) (time.Duration, error) {
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		return 0, fmt.Errorf("invalid %s %q: want a non-negative duration such as 5s", lockTimeoutFlag, value)
+	}
+	return timeout, nil
+}
+
+const profileFlag = "--profile"
+
+func parseProfile(value string) (string, error) {
+	if value == "" {
+		return "", fmt.Errorf("%s needs a profile name", profileFlag)
+	}
+	if value == entities.DefaultProfile {
+		return entities.DefaultProfile, nil
+	}
+	if err := validation.ValidateProfileName(value); err != nil {
+		return "", fmt.Errorf("invalid %s: %w", profileFlag, err)
+	}
+	return value, nil
+}
+
+// WithProfileFromEnvironment fills in Profile from OUTFITPICKER_PROFILE when
+// --profile was not given.
+func (o GlobalOptions) WithProfileFromEnvironment(getenv func(string) string) (GlobalOptions, error) {
+	value := strings.TrimSpace(getenv(ProfileEnvironmentVariable))
+	if o.Profile != "" || value == "" {
+		return o, nil
+	}
+	profile, err := parseProfile(value)
+	if err != nil {
+		return GlobalOptions{}, fmt.Errorf("%s: %w", ProfileEnvironmentVariable, err)
+	}
+	o.Profile = profile
+	return o, nil
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseGlobalOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		want     GlobalOptions
+		wantArgs []string
+	}{
+		{name: "no args", args: nil, wantArgs: []string{}},
+		{name: "tui only", args: []string{"--tui"}, want: GlobalOptions{TUI: true}, wantArgs: []string{}},
+		{name: "tui with command", args: []string{"--tui", "pick", "--no-mark"}, want: GlobalOptions{TUI: true}, wantArgs: []string{"pick", "--no-mark"}},
+		{name: "no preview", args: []string{"--no-preview", "pick"}, want: GlobalOptions{NoPreview: true}, wantArgs: []string{"pick"}},
+		{name: "no hooks", args: []string{"--no-hooks", "pick"}, want: GlobalOptions{NoHooks: true}, wantArgs: []string{"pick"}},
+		{name: "lock timeout", args: []string{"--lock-timeout", "2s", "pick"}, want: GlobalOptions{LockTimeout: 2 * time.Second}, wantArgs: []string{"pick"}},
+		{name: "lock timeout with equals", args: []string{"--lock-timeout=1m", "wear", "casual/a.avatar"}, want: GlobalOptions{LockTimeout: time.Minute}, wantArgs: []string{"wear", "casual/a.avatar"}},
+		{name: "profile", args: []string{"--profile", "alex", "list", "worn"}, want: GlobalOptions{Profile: "alex"}, wantArgs: []string{"list", "worn"}},
+		{name: "profile with equals", args: []string{"--profile=default", "--no-hooks", "pick"}, want: GlobalOptions{Profile: "default", NoHooks: true}, wantArgs: []string{"pick"}},
+		{name: "leaves flags after the command", args: []string{"config", "set-hook", "post-pick", "mycmd", "--no-hooks", "--tui"}, wantArgs: []string{"config", "set-hook", "post-pick", "mycmd", "--no-hooks", "--tui"}},
+		{name: "stops at double dash", args: []string{"--", "--tui"}, wantArgs: []string{"--", "--tui"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, args, err := ParseGlobalOptions(tt.args)
+			if err != nil {
+				t.Fatalf("ParseGlobalOptions() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("ParseGlobalOptions() options = %#v, want %#v", got, tt.want)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Fatalf("ParseGlobalOptions() args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestParseGlobalOptions_InvalidLockTimeout(t *testing.T) {
+	for _, args := range [][]string{
+		{"--lock-timeout"},
+		{"--lock-timeout", "soon"},
+		{"--lock-timeout=-1s"},
+	} {
+		_, _, err := ParseGlobalOptions(args)
+		if err == nil || !strings.Contains(err.Error(), "--lock-timeout") {
+			t.Fatalf("ParseGlobalOptions(%q) error = %v, want --lock-timeout error", args, err)
+		}
+	}
+}
+
+func TestParseGlobalOptions_InvalidProfile(t *testing.T) {
+	for _, args := range [][]string{
+		{"--profile"},
+		{"--profile", "../alex"},
+		{"--profile="},
+	} {
+		_, _, err := ParseGlobalOptions(args)
+		if err == nil || !strings.Contains(err.Error(), "--profile") {
+			t.Fatalf("ParseGlobalOptions(%q) error = %v, want --profile error", args, err)
+		}
+	}
+}
+
+func TestGlobalOptions_WithProfileFromEnvironment(t *testing.T) {
+	environment := func(value string) func(string) string {
+		return func(name string) string {
+			if name != ProfileEnvironmentVariable {
+				t.Fatalf("getenv(%q), want %s", name, ProfileEnvironmentVariable)
+			}
+			return value
+		}
+	}
+
+	if got, err := (GlobalOptions{}).WithProfileFromEnvironment(environment(" sam ")); err != nil || got.Profile != "sam" {
+		t.Fatalf("WithProfileFromEnvironment() = %+v, %v; want sam", got, err)
+	}
+	if got, err := (GlobalOptions{Profile: "alex"}).WithProfileFromEnvironment(environment("sam")); err != nil || got.Profile != "alex" {
+		t.Fatalf("WithProfileFromEnvironment() = %+v, %v; want --profile to win", got, err)
+	}
+	if got, err := (GlobalOptions{}).WithProfileFromEnvironment(environment("")); err != nil || got.Profile != "" {
+		t.Fatalf("WithProfileFromEnvironment() = %+v, %v; want no profile", got, err)
+	}
+	if _, err := (GlobalOptions{}).WithProfileFromEnvironment(environment("a b")); err == nil || !strings.Contains(err.Error(), ProfileEnvironmentVariable) {
+		t.Fatalf("WithProfileFromEnvironment() error = %v, want %s error", err, ProfileEnvironmentVariable)
+	}
+}
+
+func TestGlobalFlags_MisplacedFlag(t *testing.T) {
+	tests := []struct {
+		flags globalFlags
+		want  string
+	}{
+		{globalFlags{}, ""},
+		{globalFlags{TUI: true}, "--tui"},
+		{globalFlags{NoPreview: true}, "--no-preview"},
+		{globalFlags{NoHooks: true}, "--no-hooks"},
+		{globalFlags{LockTimeout: time.Second}, "--lock-timeout"},
+		{globalFlags{Profile: "alex"}, "--profile"},
+	}
+	for _, tt := range tests {
+		if got := tt.flags.misplacedFlag(); got != tt.want {
+			t.Errorf("misplacedFlag(%+v) = %q, want %q", tt.flags, got, tt.want)
+		}
+	}
+}
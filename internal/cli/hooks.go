@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/application/usecases"
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+)
+
+// hookDispatcher runs the hook configured for an event. A nil dispatcher or
+// runner means hooks are disabled.
+type hookDispatcher struct {
+	runner        interfaces.HookRunner
+	configManager usecases.ConfigManager
+	report        func(error)
+	now           func() time.Time
+}
+
+func newHookDispatcher(runner interfaces.HookRunner, configManager usecases.ConfigManager, report func(error)) *hookDispatcher {
+	if runner == nil {
+		return nil
+	}
+	return &hookDispatcher{runner: runner, configManager: configManager, report: report, now: time.Now}
+}
+
+// runForCategory runs the hook for event and returns its failure.
+func (d *hookDispatcher) runForCategory(event entities.HookEventName, categoryName string) error {
+	return d.run(event, func(payload entities.HookEvent) entities.HookEvent {
+		return payload.WithCategory(categoryName)
+	})
+}
+
+// notifyOutfit runs the hook for event and reports a failure without
+// interrupting the caller, whose change has already been made.
+func (d *hookDispatcher) notifyOutfit(event entities.HookEventName, outfit entities.OutfitReference) {
+	d.notify(d.run(event, func(payload entities.HookEvent) entities.HookEvent {
+		return payload.WithOutfit(outfit)
+	}))
+}
+
+// notifyCategory is notifyOutfit for events about a whole category, or about
+// every category when categoryName is empty.
+func (d *hookDispatcher) notifyCategory(event entities.HookEventName, categoryName string) {
+	d.notify(d.runForCategory(event, categoryName))
+}
+
+func (d *hookDispatcher) run(event entities.HookEventName, scope func(entities.HookEvent) entities.HookEvent) error {
+	if d == nil {
+		return nil
+	}
+	config, err := d.configManager.LoadOrCreate()
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return nil
+	}
+	hook, ok := config.Hook(event)
+	if !ok {
+		return nil
+	}
+	return d.runner.Run(hook, scope(entities.NewHookEvent(event, config.Root, d.now())))
+}
+
+func (d *hookDispatcher) notify(err error) {
+	if err != nil && d.report != nil {
+		d.report(err)
+	}
+}
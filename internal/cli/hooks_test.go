@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+type recordingHookRunner struct {
+	err    error
+	hooks  []entities.Hook
+	events []entities.HookEvent
+}
+
+func (r *recordingHookRunner) Run(hook entities.Hook, event entities.HookEvent) error {
+	r.hooks = append(r.hooks, hook)
+	r.events = append(r.events, event)
+	return r.err
+}
+
+func (r *recordingHookRunner) eventNames() []entities.HookEventName {
+	names := make([]entities.HookEventName, 0, len(r.events))
+	for _, event := range r.events {
+		names = append(names, event.Event)
+	}
+	return names
+}
+
+func newHookedTestApplication(t *testing.T, cache *entities.OutfitCache, runner *recordingHookRunner, reported *[]error) (*Application, *entities.Config) {
+	t.Helper()
+	config, err := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
+	if err != nil {
+		t.Fatalf("NewConfig() error = %v", err)
+	}
+	for _, event := range entities.HookEventNames {
+		config = config.WithHook(event, entities.Hook{Command: "hook-" + string(event)})
+	}
+	app := buildApplication(config, RuntimeDependencies{
+		ConfigManager: &stubConfigManager{config: config},
+		CacheManager:  &stubCacheManager{cache: cache},
+		CategorySvc: &stubCategoryService{
+			scanCategoriesResult: []entities.CategoryInfo{
+				entities.NewCategoryInfo(entities.NewCategoryReference("casual", cliTestCategoryPath("casual")), entities.CategoryStateHasOutfits, 2),
+			},
+			outfitsByPath: map[string][]entities.FileEntry{
+				cliTestCategoryPath("casual"): {{FileName: "one.avatar"}, {FileName: "two.avatar"}},
+			},
+		},
+		HookRunner:    runner,
+		ReportWarning: func(err error) { *reported = append(*reported, err) },
+	})
+	return app, config
+}
+
+func TestApplication_Hooks(t *testing.T) {
+	outfit := entities.NewOutfitReference("one.avatar", entities.NewCategoryReference("casual", cliTestCategoryPath("casual")))
+
+	t.Run("post-wear", func(t *testing.T) {
+		runner := &recordingHookRunner{}
+		var reported []error
+		app, _ := newHookedTestApplication(t, newOutfitCachePtr(), runner, &reported)
+
+		if err := app.WearOutfit(outfit); err != nil {
+			t.Fatalf("WearOutfit() error = %v", err)
+		}
+
+		assertHookEvents(t, runner, entities.HookEventPostWear)
+		event := runner.events[0]
+		if runner.hooks[0].Command != "hook-post-wear" || event.Root != cliTestOutfitRoot || event.Outfit != "one.avatar" || event.OutfitPath != outfit.FilePath() || event.Time.IsZero() {
+			t.Fatalf("hook %#v ran with event %#v", runner.hooks[0], event)
+		}
+	})
+
+	t.Run("rotation completed", func(t *testing.T) {
+		runner := &recordingHookRunner{}
+		var reported []error
+		cache := entities.NewOutfitCache()
+		cache.Categories["casual"] = entities.CategoryCache{WornOutfits: map[string]bool{"two.avatar": true}, TotalOutfits: 2}
+		app, _ := newHookedTestApplication(t, &cache, runner, &reported)
+
+		if err := app.WearOutfit(outfit); !isRotationCompleteError(err) {
+			t.Fatalf("WearOutfit() error = %v, want rotation complete", err)
+		}
+
+		assertHookEvents(t, runner, entities.HookEventPostWear, entities.HookEventRotationCompleted)
+		if runner.events[1].Category != "casual" || runner.events[1].Outfit != "" {
+			t.Fatalf("rotation-completed event = %#v", runner.events[1])
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		runner := &recordingHookRunner{}
+		var reported []error
+		app, _ := newHookedTestApplication(t, newOutfitCachePtr(), runner, &reported)
+
+		if err := app.ResetCategory("casual"); err != nil {
+			t.Fatalf("ResetCategory() error = %v", err)
+		}
+		if err := app.ResetAllCategories(); err != nil {
+			t.Fatalf("ResetAllCategories() error = %v", err)
+		}
+
+		assertHookEvents(t, runner, entities.HookEventReset, entities.HookEventReset)
+		if runner.events[0].Category != "casual" || runner.events[1].Category != "" {
+			t.Fatalf("reset events = %#v", runner.events)
+		}
+	})
+
+	t.Run("pick", func(t *testing.T) {
+		runner := &recordingHookRunner{}
+		var reported []error
+		app, _ := newHookedTestApplication(t, newOutfitCachePtr(), runner, &reported)
+
+		picked, err := app.ShowNextUniqueRandomOutfitFrom("casual")
+		if err != nil || picked == nil {
+			t.Fatalf("ShowNextUniqueRandomOutfitFrom() = %v, %v", picked, err)
+		}
+		if _, err := app.ShowNextUniqueRandomOutfit(); err != nil {
+			t.Fatalf("ShowNextUniqueRandomOutfit() error = %v", err)
+		}
+
+		assertHookEvents(t, runner, entities.HookEventPrePick, entities.HookEventPostPick, entities.HookEventPrePick, entities.HookEventPostPick)
+		if runner.events[0].Category != "casual" || runner.events[1].Outfit != picked.FileName || runner.events[2].Category != "" {
+			t.Fatalf("pick events = %#v", runner.events)
+		}
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		wantErr := errors.New("hook exited with status 1")
+		runner := &recordingHookRunner{err: wantErr}
+		var reported []error
+		app, _ := newHookedTestApplication(t, newOutfitCachePtr(), runner, &reported)
+
+		picked, err := app.ShowNextUniqueRandomOutfit()
+		if picked != nil || !errors.Is(err, wantErr) || !errors.Is(err, domainerrors.ErrPickCancelled) {
+			t.Fatalf("ShowNextUniqueRandomOutfit() = %v, %v; want pick cancelled by %v", picked, err, wantErr)
+		}
+		assertHookEvents(t, runner, entities.HookEventPrePick)
+		if len(reported) != 0 {
+			t.Fatalf("pre-pick failure was reported as a warning: %v", reported)
+		}
+		if err := app.WearOutfit(outfit); err != nil {
+			t.Fatalf("WearOutfit() error = %v, want hook failure reported separately", err)
+		}
+		assertHookEvents(t, runner, entities.HookEventPrePick, entities.HookEventPostWear)
+		if len(reported) != 1 || !errors.Is(reported[0], wantErr) {
+			t.Fatalf("reported = %v, want %v", reported, wantErr)
+		}
+	})
+
+	t.Run("unconfigured event", func(t *testing.T) {
+		runner := &recordingHookRunner{}
+		var reported []error
+		app, config := newHookedTestApplication(t, newOutfitCachePtr(), runner, &reported)
+		withoutHook, err := config.WithoutHook(entities.HookEventPostPick)
+		if err != nil {
+			t.Fatalf("WithoutHook() error = %v", err)
+		}
+		*config = *withoutHook
+
+		if _, err := app.ShowRandomOutfitIncludingExcluded(); err != nil {
+			t.Fatalf("ShowRandomOutfitIncludingExcluded() error = %v", err)
+		}
+
+		assertHookEvents(t, runner, entities.HookEventPrePick)
+	})
+}
+
+func TestHookDispatcher_DisabledAndConfigErrors(t *testing.T) {
+	if dispatcher := newHookDispatcher(nil, &stubConfigManager{}, nil); dispatcher != nil {
+		t.Fatalf("newHookDispatcher(nil) = %#v, want nil", dispatcher)
+	}
+	var disabled *hookDispatcher
+	if err := disabled.runForCategory(entities.HookEventPrePick, ""); err != nil {
+		t.Fatalf("disabled runForCategory() error = %v", err)
+	}
+
+	runner := &recordingHookRunner{}
+	loadErr := errors.New("config unreadable")
+	var reported []error
+	dispatcher := newHookDispatcher(runner, &stubConfigManager{err: loadErr}, func(err error) { reported = append(reported, err) })
+	dispatcher.notifyCategory(entities.HookEventReset, "")
+	if len(reported) != 1 || !errors.Is(reported[0], loadErr) {
+		t.Fatalf("reported = %v, want %v", reported, loadErr)
+	}
+
+	missing := newHookDispatcher(runner, &stubConfigManager{}, nil)
+	if err := missing.runForCategory(entities.HookEventPrePick, ""); err != nil {
+		t.Fatalf("runForCategory() without config error = %v", err)
+	}
+	missing.notifyCategory(entities.HookEventReset, "")
+	assertHookEvents(t, runner)
+}
+
+func assertHookEvents(t *testing.T, runner *recordingHookRunner, want ...entities.HookEventName) {
+	t.Helper()
+	got := runner.eventNames()
+	if len(got) != len(want) {
+		t.Fatalf("hook events = %v, want %v", got, want)
+	}
+	for index := range want {
+		if got[index] != want[index] {
+			t.Fatalf("hook events = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"golang.org/x/term"
+)
+
+const (
+	previewColumns      = 32
+	previewRows         = 16
+	previewCellWidthPx  = 8
+	previewCellHeightPx = 16
+	kittyChunkSize      = 4096
+	sixelLevels         = 6
+)
+
+type previewProtocol int
+
+const (
+	previewProtocolNone previewProtocol = iota
+	previewProtocolKitty
+	previewProtocolITerm2
+	previewProtocolSixel
+	previewProtocolHalfBlock
+)
+
+// OutfitPreviewer renders an outfit's companion image as terminal output.
+type OutfitPreviewer interface {
+	RenderPreview(outfit entities.OutfitReference) (string, error)
+}
+
+type imagePreviewer struct {
+	protocol previewProtocol
+	readFile func(string) ([]byte, error)
+}
+
+var (
+	previewGetenv         = os.Getenv
+	previewIsTerminalFunc = isTerminalWriter
+)
+
+// NewOutfitPreviewer returns a previewer for the console's terminal, or nil when
+// previews are disabled, the output is not a terminal, or the terminal cannot
+// display images.
+func NewOutfitPreviewer(options GlobalOptions, console Console) OutfitPreviewer {
+	if options.NoPreview {
+		return nil
+	}
+	terminal, ok := consoleOrDefault(console).(TerminalConsole)
+	if !ok || !previewIsTerminalFunc(terminal.output()) {
+		return nil
+	}
+	protocol := detectPreviewProtocol(previewGetenv)
+	if protocol == previewProtocolNone {
+		return nil
+	}
+	return imagePreviewer{protocol: protocol, readFile: os.ReadFile}
+}
+
+func isTerminalWriter(output io.Writer) bool {
+	file, ok := output.(*os.File)
+	return ok && term.IsTerminal(int(file.Fd()))
+}
+
+func detectPreviewProtocol(getenv func(string) string) previewProtocol {
+	termName := strings.ToLower(getenv("TERM"))
+	program := getenv("TERM_PROGRAM")
+	switch {
+	case termName == "dumb":
+		return previewProtocolNone
+	case getenv("TMUX") != "" || strings.HasPrefix(termName, "screen"):
+		// Multiplexers swallow graphics escapes unless explicitly passed through.
+		return previewProtocolHalfBlock
+	case getenv("KITTY_WINDOW_ID") != "" || strings.Contains(termName, "kitty") || program == "ghostty":
+		return previewProtocolKitty
+	case program == "iTerm.app" || program == "WezTerm" || getenv("LC_TERMINAL") == "iTerm2":
+		return previewProtocolITerm2
+	case strings.Contains(termName, "sixel") || strings.HasPrefix(termName, "foot") || strings.HasPrefix(termName, "mlterm"):
+		return previewProtocolSixel
+	default:
+		return previewProtocolHalfBlock
+	}
+}
+
+func (p imagePreviewer) RenderPreview(outfit entities.OutfitReference) (string, error) {
+	path := outfit.PreviewPath()
+	if path == "" {
+		return "", nil
+	}
+	data, err := p.readFile(path)
+	if err != nil {
+		return "", err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("could not decode %s: %w", outfit.PreviewFileName, err)
+	}
+
+	switch p.protocol {
+	case previewProtocolKitty:
+		return encodeKittyImage(img)
+	case previewProtocolITerm2:
+		return encodeITerm2Image(data), nil
+	case previewProtocolSixel:
+		width, height := fitPreviewSize(img.Bounds(), previewColumns*previewCellWidthPx, previewRows*previewCellHeightPx)
+		return encodeSixel(scalePreviewImage(img, width, height)), nil
+	default:
+		width, height := fitPreviewSize(img.Bounds(), previewColumns, previewRows*2)
+		return encodeHalfBlocks(scalePreviewImage(img, width, height)), nil
+	}
+}
+
+func printOutfitPreview(console Console, previewer OutfitPreviewer, outfit entities.OutfitReference) {
+	if previewer == nil || outfit.PreviewFileName == "" || outfit.InArchive() {
+		return
+	}
+	preview, err := previewer.RenderPreview(outfit)
+	if err != nil {
+		console.Warning(fmt.Sprintf("Could not show preview: %v", err))
+		return
+	}
+	console.Printf("%s\n", preview)
+}
+
+func encodeKittyImage(img image.Image) (string, error) {
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, img); err != nil {
+		return "", err
+	}
+	payload := base64.StdEncoding.EncodeToString(encoded.Bytes())
+
+	var out strings.Builder
+	for start := 0; start < len(payload); start += kittyChunkSize {
+		end := min(start+kittyChunkSize, len(payload))
+		more := 0
+		if end < len(payload) {
+			more = 1
+		}
+		if start == 0 {
+			// q=2 stops the terminal from replying on stdin, which would corrupt prompts.
+			fmt.Fprintf(&out, "\x1b_Ga=T,f=100,q=2,c=%d,m=%d;%s\x1b\\", previewColumns, more, payload[start:end])
+			continue
+		}
+		fmt.Fprintf(&out, "\x1b_Gm=%d;%s\x1b\\", more, payload[start:end])
+	}
+	return out.String(), nil
+}
+
+func encodeITerm2Image(data []byte) string {
+	return fmt.Sprintf("\x1b]1337;File=inline=1;size=%d;width=%d;preserveAspectRatio=1:%s\a",
+		len(data), previewColumns, base64.StdEncoding.EncodeToString(data))
+}
+
+// fitPreviewSize scales bounds down to fit within maxWidth x maxHeight,
+// preserving the aspect ratio and never enlarging the image.
+func fitPreviewSize(bounds image.Rectangle, maxWidth, maxHeight int) (int, int) {
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= 0 || height <= 0 {
+		return 1, 1
+	}
+	if width > maxWidth {
+		height = max(1, height*maxWidth/width)
+		width = maxWidth
+	}
+	if height > maxHeight {
+		width = max(1, width*maxHeight/height)
+		height = maxHeight
+	}
+	return width, height
+}
+
+// scalePreviewImage resamples img with nearest-neighbour sampling. Transparent
+// pixels are composited over black.
+func scalePreviewImage(img image.Image, width, height int) *image.RGBA {
+	bounds := img.Bounds()
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		sourceY := bounds.Min.Y + y*bounds.Dy()/height
+		for x := range width {
+			sourceX := bounds.Min.X + x*bounds.Dx()/width
+			pixel := color.RGBAModel.Convert(img.At(sourceX, sourceY)).(color.RGBA)
+			pixel.A = 0xff
+			scaled.SetRGBA(x, y, pixel)
+		}
+	}
+	return scaled
+}
+
+func encodeHalfBlocks(img *image.RGBA) string {
+	bounds := img.Bounds()
+	var out strings.Builder
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += 2 {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			top := img.RGBAAt(x, y)
+			fmt.Fprintf(&out, "\x1b[38;2;%d;%d;%dm", top.R, top.G, top.B)
+			if y+1 < bounds.Max.Y {
+				bottom := img.RGBAAt(x, y+1)
+				fmt.Fprintf(&out, "\x1b[48;2;%d;%d;%dm", bottom.R, bottom.G, bottom.B)
+			}
+			out.WriteString("▀")
+		}
+		out.WriteString(uiReset)
+		if y+2 < bounds.Max.Y {
+			out.WriteString("\n")
+		}
+	}
+	return out.String()
+}
+
+// encodeSixel emits img as a sixel sequence using a fixed 6x6x6 colour cube.
+func encodeSixel(img *image.RGBA) string {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "\x1bPq\"1;1;%d;%d", width, height)
+	for index := range sixelLevels * sixelLevels * sixelLevels {
+		red, green, blue := index/(sixelLevels*sixelLevels), index/sixelLevels%sixelLevels, index%sixelLevels
+		fmt.Fprintf(&out, "#%d;2;%d;%d;%d", index, red*20, green*20, blue*20)
+	}
+
+	for top := 0; top < height; top += 6 {
+		bands := map[int][]byte{}
+		for x := range width {
+			for offset := 0; offset < 6 && top+offset < height; offset++ {
+				index := sixelColorIndex(img.RGBAAt(bounds.Min.X+x, bounds.Min.Y+top+offset))
+				if bands[index] == nil {
+					bands[index] = make([]byte, width)
+				}
+				bands[index][x] |= 1 << offset
+			}
+		}
+
+		indexes := make([]int, 0, len(bands))
+		for index := range bands {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+		for _, index := range indexes {
+			fmt.Fprintf(&out, "#%d", index)
+			writeSixelRow(&out, bands[index])
+			out.WriteString("$")
+		}
+		out.WriteString("-")
+	}
+	out.WriteString("\x1b\\")
+	return out.String()
+}
+
+func sixelColorIndex(pixel color.RGBA) int {
+	level := func(value uint8) int {
+		return (int(value)*(sixelLevels-1) + 127) / 255
+	}
+	return level(pixel.R)*sixelLevels*sixelLevels + level(pixel.G)*sixelLevels + level(pixel.B)
+}
+
+func writeSixelRow(out *strings.Builder, row []byte) {
+	for start := 0; start < len(row); {
+		end := start
+		for end < len(row) && row[end] == row[start] {
+			end++
+		}
+		char := byte('?' + row[start])
+		if run := end - start; run > 3 {
+			fmt.Fprintf(out, "!%d%c", run, char)
+		} else {
+			out.WriteString(strings.Repeat(string(char), run))
+		}
+		start = end
+	}
+}
@@ -0,0 +1,291 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+)
+
+func TestDetectPreviewProtocol(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want previewProtocol
+	}{
+		{name: "dumb terminal", env: map[string]string{"TERM": "dumb"}, want: previewProtocolNone},
+		{name: "kitty window", env: map[string]string{"TERM": "xterm-256color", "KITTY_WINDOW_ID": "1"}, want: previewProtocolKitty},
+		{name: "kitty term", env: map[string]string{"TERM": "xterm-kitty"}, want: previewProtocolKitty},
+		{name: "ghostty", env: map[string]string{"TERM_PROGRAM": "ghostty"}, want: previewProtocolKitty},
+		{name: "iterm2", env: map[string]string{"TERM_PROGRAM": "iTerm.app"}, want: previewProtocolITerm2},
+		{name: "iterm2 over ssh", env: map[string]string{"LC_TERMINAL": "iTerm2"}, want: previewProtocolITerm2},
+		{name: "foot", env: map[string]string{"TERM": "foot"}, want: previewProtocolSixel},
+		{name: "sixel term", env: map[string]string{"TERM": "xterm-sixel"}, want: previewProtocolSixel},
+		{name: "tmux falls back", env: map[string]string{"TERM": "xterm-kitty", "TMUX": "/tmp/tmux"}, want: previewProtocolHalfBlock},
+		{name: "plain terminal", env: map[string]string{"TERM": "xterm-256color"}, want: previewProtocolHalfBlock},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := detectPreviewProtocol(func(key string) string { return tt.env[key] })
+			if got != tt.want {
+				t.Fatalf("detectPreviewProtocol() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewOutfitPreviewer(t *testing.T) {
+	restore := withPreviewTerminal(t, true, map[string]string{"TERM": "xterm-256color"})
+	defer restore()
+
+	if previewer := NewOutfitPreviewer(GlobalOptions{}, TerminalConsole{}); previewer == nil {
+		t.Fatal("NewOutfitPreviewer() = nil, want previewer for terminal output")
+	}
+	if previewer := NewOutfitPreviewer(GlobalOptions{NoPreview: true}, TerminalConsole{}); previewer != nil {
+		t.Fatalf("NewOutfitPreviewer() with --no-preview = %#v, want nil", previewer)
+	}
+	if previewer := NewOutfitPreviewer(GlobalOptions{}, &recordingConsole{}); previewer != nil {
+		t.Fatalf("NewOutfitPreviewer() for non-terminal console = %#v, want nil", previewer)
+	}
+
+	previewGetenv = func(string) string { return "dumb" }
+	if previewer := NewOutfitPreviewer(GlobalOptions{}, TerminalConsole{}); previewer != nil {
+		t.Fatalf("NewOutfitPreviewer() for dumb terminal = %#v, want nil", previewer)
+	}
+
+	previewIsTerminalFunc = isTerminalWriter
+	if previewer := NewOutfitPreviewer(GlobalOptions{}, TerminalConsole{stdout: &bytes.Buffer{}}); previewer != nil {
+		t.Fatalf("NewOutfitPreviewer() for buffer output = %#v, want nil", previewer)
+	}
+}
+
+func TestImagePreviewer_RenderPreview(t *testing.T) {
+	data := testPreviewPNG(t, 4, 4)
+	outfit := previewOutfit("look.png")
+	readFile := func(path string) ([]byte, error) {
+		if path != outfit.PreviewPath() {
+			t.Fatalf("readFile(%q), want %q", path, outfit.PreviewPath())
+		}
+		return data, nil
+	}
+
+	tests := []struct {
+		protocol previewProtocol
+		want     []string
+	}{
+		{protocol: previewProtocolKitty, want: []string{"\x1b_Ga=T,f=100,q=2,c=32,m=0;", "\x1b\\"}},
+		{protocol: previewProtocolITerm2, want: []string{"\x1b]1337;File=inline=1;size=", "width=32", "\a"}},
+		{protocol: previewProtocolSixel, want: []string{"\x1bPq\"1;1;4;4", "#215;2;100;100;100", "\x1b\\"}},
+		{protocol: previewProtocolHalfBlock, want: []string{"\x1b[38;2;255;255;255m\x1b[48;2;0;0;0m▀", uiReset}},
+	}
+
+	for _, tt := range tests {
+		previewer := imagePreviewer{protocol: tt.protocol, readFile: readFile}
+		got, err := previewer.RenderPreview(outfit)
+		if err != nil {
+			t.Fatalf("RenderPreview(%v) error = %v", tt.protocol, err)
+		}
+		assertOutputContains(t, got, tt.want...)
+	}
+}
+
+func TestImagePreviewer_RenderPreviewErrors(t *testing.T) {
+	t.Run("no preview", func(t *testing.T) {
+		previewer := imagePreviewer{protocol: previewProtocolHalfBlock}
+		if got, err := previewer.RenderPreview(previewOutfit("")); got != "" || err != nil {
+			t.Fatalf("RenderPreview() = %q, %v, want empty", got, err)
+		}
+	})
+
+	t.Run("read failure", func(t *testing.T) {
+		previewer := imagePreviewer{protocol: previewProtocolHalfBlock, readFile: func(string) ([]byte, error) {
+			return nil, os.ErrNotExist
+		}}
+		if _, err := previewer.RenderPreview(previewOutfit("look.png")); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("RenderPreview() error = %v, want not exist", err)
+		}
+	})
+
+	t.Run("invalid image", func(t *testing.T) {
+		previewer := imagePreviewer{protocol: previewProtocolHalfBlock, readFile: func(string) ([]byte, error) {
+			return []byte("not an image"), nil
+		}}
+		_, err := previewer.RenderPreview(previewOutfit("look.png"))
+		if err == nil || !strings.Contains(err.Error(), "could not decode look.png") {
+			t.Fatalf("RenderPreview() error = %v, want decode error", err)
+		}
+	})
+}
+
+func TestPrintOutfitPreview(t *testing.T) {
+	outfit := previewOutfit("look.png")
+
+	t.Run("prints rendered preview", func(t *testing.T) {
+		console := &recordingConsole{}
+		printOutfitPreview(console, stubPreviewer{output: "IMAGE"}, outfit)
+		assertOutputContains(t, console.output.String(), "IMAGE\n")
+	})
+
+	t.Run("warns on failure", func(t *testing.T) {
+		console := &recordingConsole{}
+		printOutfitPreview(console, stubPreviewer{err: errors.New("broken")}, outfit)
+		assertOutputContains(t, console.warnings.String(), "Could not show preview: broken")
+	})
+
+	t.Run("skips outfits without a readable preview or previewer", func(t *testing.T) {
+		console := &recordingConsole{}
+		archived := entities.NewOutfitReference("look.avatar", entities.NewCategoryReference("casual", filepath.Join("packs.zip", "casual"))).WithPreview("look.png")
+		printOutfitPreview(console, nil, outfit)
+		printOutfitPreview(console, stubPreviewer{output: "IMAGE"}, previewOutfit(""))
+		printOutfitPreview(console, stubPreviewer{output: "IMAGE"}, archived)
+		if console.output.Len() != 0 {
+			t.Fatalf("output = %q, want empty", console.output.String())
+		}
+	})
+}
+
+func TestEncodeKittyImage_ChunksLargePayloads(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	seed := uint32(1)
+	for index := range img.Pix {
+		seed = seed*1664525 + 1013904223
+		img.Pix[index] = byte(seed >> 24)
+	}
+
+	got, err := encodeKittyImage(img)
+	if err != nil {
+		t.Fatalf("encodeKittyImage() error = %v", err)
+	}
+	assertOutputContains(t, got, "\x1b_Ga=T,f=100,q=2,c=32,m=1;", "\x1b_Gm=0;")
+}
+
+func TestPreviewImageHelpers(t *testing.T) {
+	if width, height := fitPreviewSize(image.Rect(0, 0, 400, 200), 32, 32); width != 32 || height != 16 {
+		t.Fatalf("fitPreviewSize(wide) = %dx%d, want 32x16", width, height)
+	}
+	if width, height := fitPreviewSize(image.Rect(0, 0, 100, 400), 32, 32); width != 8 || height != 32 {
+		t.Fatalf("fitPreviewSize(tall) = %dx%d, want 8x32", width, height)
+	}
+	if width, height := fitPreviewSize(image.Rect(0, 0, 0, 0), 32, 32); width != 1 || height != 1 {
+		t.Fatalf("fitPreviewSize(empty) = %dx%d, want 1x1", width, height)
+	}
+
+	var row strings.Builder
+	writeSixelRow(&row, []byte{1, 1, 1, 1, 1, 2, 2, 0})
+	if got := row.String(); got != "!5@AA?" {
+		t.Fatalf("writeSixelRow() = %q, want !5@AA?", got)
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 2, 3))
+	img.SetRGBA(0, 2, color.RGBA{R: 255, A: 255})
+	blocks := encodeHalfBlocks(img)
+	if lines := strings.Count(blocks, "\n") + 1; lines != 2 {
+		t.Fatalf("encodeHalfBlocks() lines = %d, want 2", lines)
+	}
+	assertOutputContains(t, blocks, "\x1b[38;2;255;0;0m▀")
+}
+
+func TestOutfitPresentation_ShowsPreview(t *testing.T) {
+	var output strings.Builder
+	presentation := NewOutfitPresentation(&stubCommandHandler{}, TerminalConsole{stdin: strings.NewReader("s\n"), stdout: &output}).
+		WithPreviewer(stubPreviewer{output: "IMAGE"})
+
+	presentation.PresentOutfitWithChoice(previewOutfit("look.png"))
+
+	assertOutputContains(t, output.String(), "📁 casual\n\nIMAGE\n[W] Mark worn and quit")
+}
+
+func TestExecuteCommand_PickShowsPreview(t *testing.T) {
+	dir := cliTestHomeTempDir(t, "outfitpicker-preview-*")
+	if err := os.WriteFile(filepath.Join(dir, "boots.png"), testPreviewPNG(t, 2, 2), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	outfit := entities.NewOutfitReference("boots.avatar", entities.NewCategoryReference("shoes", dir)).WithPreview("boots.png")
+	restore := withPreviewTerminal(t, true, map[string]string{"TERM": "xterm-256color"})
+	defer restore()
+
+	for _, options := range []GlobalOptions{{}, {NoPreview: true}} {
+		runtime := newStubRuntime()
+		runtime.random.globalResults = []stubSelectorResult{{outfit: &outfit}}
+		var stdout bytes.Buffer
+
+		handled, code := ExecuteCommandWithOptions([]string{"pick", "--no-mark"}, runtime, TerminalConsole{stdout: &stdout}, options)
+
+		if !handled || code != 0 {
+			t.Fatalf("ExecuteCommandWithOptions() = handled %t code %d, want handled true code 0", handled, code)
+		}
+		assertOutputContains(t, stdout.String(), "Preview:  "+filepath.Join(dir, "boots.png"))
+		if options.NoPreview {
+			assertOutputNotContains(t, stdout.String(), "▀")
+		} else {
+			assertOutputContains(t, stdout.String(), "▀")
+		}
+	}
+}
+
+type stubPreviewer struct {
+	output string
+	err    error
+}
+
+func (p stubPreviewer) RenderPreview(entities.OutfitReference) (string, error) {
+	return p.output, p.err
+}
+
+func withPreviewTerminal(t *testing.T, isTerminal bool, env map[string]string) func() {
+	t.Helper()
+	originalGetenv := previewGetenv
+	originalIsTerminal := previewIsTerminalFunc
+	previewGetenv = func(key string) string { return env[key] }
+	previewIsTerminalFunc = func(io.Writer) bool { return isTerminal }
+	return func() {
+		previewGetenv = originalGetenv
+		previewIsTerminalFunc = originalIsTerminal
+	}
+}
+
+func previewOutfit(previewFileName string) entities.OutfitReference {
+	return outfitPresentationOutfit("casual", "look.avatar").WithPreview(previewFileName)
+}
+
+func testPreviewPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			if y%2 == 0 {
+				img.SetRGBA(x, y, color.RGBA{R: 255, G: 255, B: 255, A: 255})
+			}
+		}
+	}
+	var data bytes.Buffer
+	if err := png.Encode(&data, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	return data.Bytes()
+}
+
+type recordingConsole struct {
+	output   strings.Builder
+	warnings strings.Builder
+}
+
+func (c *recordingConsole) Prompt(string) string { return "" }
+func (c *recordingConsole) Println(args ...any)  { c.output.WriteString(fmt.Sprintln(args...)) }
+func (c *recordingConsole) Printf(format string, args ...any) {
+	fmt.Fprintf(&c.output, format, args...)
+}
+func (c *recordingConsole) Info(string)            {}
+func (c *recordingConsole) Error(string)           {}
+func (c *recordingConsole) Warning(message string) { c.warnings.WriteString(message) }
+func (c *recordingConsole) Success(string)         {}
@@ -1,10 +1,16 @@
 package cli
 
 import (
+	"archive/zip"
+	"bytes"
 	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/dh85/outfitpicker/internal/domain/entities"
 	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
@@ -92,7 +98,7 @@ func TestIntegration_WearFlowPersistsAcrossRestart(t *testing.T) {
 	}
 }
 
-func TestIntegration_RootPathChangeResetsPersistedWornState(t *testing.T) {
+func TestIntegration_RootPathChangeKeepsWornStatePerRoot(t *testing.T) {
 	t.Setenv("XDG_CONFIG_HOME", integrationConfigHome(t))
 	deps := newProductionStyleRuntimeDependencies()
 	rootOne := integrationWardrobeRoot(t, map[string][]string{
@@ -111,20 +117,11 @@ func TestIntegration_RootPathChangeResetsPersistedWornState(t *testing.T) {
 		t.Fatalf("WearOutfit() error = %v", err)
 	}
 
-	cachePath := integrationCachePath(t)
-	if !integrationFileExists(cachePath) {
-		t.Fatalf("cache file %q was not created before path change", cachePath)
-	}
-
 	menu := AdvancedMenu{outfitService: NewOutfitServiceFromRuntime(app)}
 	restore := withPromptResponses(t, rootTwo, "y")
 	defer restore()
 	menu.handlePathChange()
 
-	if integrationFileExists(cachePath) {
-		t.Fatalf("cache file %q still exists after root change", cachePath)
-	}
-
 	reloaded, err := LoadApplicationFromExistingConfig(deps)
 	if err != nil {
 		t.Fatalf("LoadApplicationFromExistingConfig() error = %v", err)
@@ -139,6 +136,456 @@ func TestIntegration_RootPathChangeResetsPersistedWornState(t *testing.T) {
 	if len(state.AvailableOutfits) != 1 || state.AvailableOutfits[0].FileName != "one.avatar" {
 		t.Fatalf("available outfits = %#v, want one.avatar available in new root", state.AvailableOutfits)
 	}
+
+	if _, code := ExecuteCommand([]string{"config", "set-root", rootOne}, reloaded, TerminalConsole{stdout: &bytes.Buffer{}}); code != 0 {
+		t.Fatalf("config set-root back exit code = %d", code)
+	}
+	reloaded, err = LoadApplicationFromExistingConfig(deps)
+	if err != nil {
+		t.Fatalf("LoadApplicationFromExistingConfig() error = %v", err)
+	}
+	state, err = reloaded.GetOutfitState(entities.NewCategoryReference("casual", ""))
+	if err != nil {
+		t.Fatalf("GetOutfitState() error = %v", err)
+	}
+	if len(state.WornOutfits) != 1 || state.WornOutfits[0].FileName != "one.avatar" {
+		t.Fatalf("worn outfits = %#v, want one.avatar back after switching back", state.WornOutfits)
+	}
+}
+
+func TestIntegration_SetRootToAnotherRootKeepsItsWornState(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", integrationConfigHome(t))
+	deps := newProductionStyleRuntimeDependencies()
+	rootOne := integrationWardrobeRoot(t, map[string][]string{
+		"casual": {"one.avatar"},
+	})
+	rootTwo := integrationWardrobeRoot(t, map[string][]string{
+		"casual": {"one.avatar", "two.avatar"},
+	})
+
+	app, err := CreateApplicationFromConfiguration(Configuration{OutfitPath: rootOne, Language: "en"}, deps)
+	if err != nil {
+		t.Fatalf("CreateApplicationFromConfiguration() error = %v", err)
+	}
+	if _, code := ExecuteCommand([]string{"config", "add-root", rootTwo}, app, TerminalConsole{stdout: &bytes.Buffer{}}); code != 0 {
+		t.Fatalf("config add-root exit code = %d", code)
+	}
+	outfit := entities.NewOutfitReference("two.avatar", entities.NewCategoryReference("casual", filepath.Join(rootTwo, "casual")))
+	outfit.Root = rootTwo
+	if err := app.WearOutfit(outfit); err != nil {
+		t.Fatalf("WearOutfit() error = %v", err)
+	}
+
+	if _, code := ExecuteCommand([]string{"config", "set-root", rootTwo}, app, TerminalConsole{stdout: &bytes.Buffer{}}); code != 0 {
+		t.Fatalf("config set-root exit code = %d", code)
+	}
+	reloaded, err := LoadApplicationFromExistingConfig(deps)
+	if err != nil {
+		t.Fatalf("LoadApplicationFromExistingConfig() error = %v", err)
+	}
+	state, err := reloaded.GetOutfitState(entities.NewCategoryReference("casual", ""))
+	if err != nil {
+		t.Fatalf("GetOutfitState() error = %v", err)
+	}
+	if len(state.WornOutfits) != 1 || state.WornOutfits[0].FileName != "two.avatar" || state.WornOutfits[0].Root != "" {
+		t.Fatalf("worn outfits = %#v, want two.avatar worn from the new first root", state.WornOutfits)
+	}
+}
+
+func TestIntegration_SetRootMigratingHistoryCarriesWornState(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", integrationConfigHome(t))
+	deps := newProductionStyleRuntimeDependencies()
+	oldRoot := integrationWardrobeRoot(t, map[string][]string{
+		"casual": {"one.avatar", "two.avatar"},
+	})
+	newRoot := integrationWardrobeRoot(t, map[string][]string{
+		"casual": {"one.avatar", "two.avatar"},
+	})
+
+	app, err := CreateApplicationFromConfiguration(Configuration{OutfitPath: oldRoot, Language: "en"}, deps)
+	if err != nil {
+		t.Fatalf("CreateApplicationFromConfiguration() error = %v", err)
+	}
+	if err := app.WearOutfit(entities.NewOutfitReference("one.avatar", entities.NewCategoryReference("casual", filepath.Join(oldRoot, "casual")))); err != nil {
+		t.Fatalf("WearOutfit() error = %v", err)
+	}
+
+	if _, code := ExecuteCommand([]string{"config", "set-root", "--migrate-history", newRoot}, app, TerminalConsole{stdout: &bytes.Buffer{}}); code != 0 {
+		t.Fatalf("config set-root --migrate-history exit code = %d", code)
+	}
+	reloaded, err := LoadApplicationFromExistingConfig(deps)
+	if err != nil {
+		t.Fatalf("LoadApplicationFromExistingConfig() error = %v", err)
+	}
+	state, err := reloaded.GetOutfitState(entities.NewCategoryReference("casual", ""))
+	if err != nil {
+		t.Fatalf("GetOutfitState() error = %v", err)
+	}
+	if len(state.WornOutfits) != 1 || state.WornOutfits[0].FileName != "one.avatar" {
+		t.Fatalf("worn outfits = %#v, want one.avatar carried to the new root", state.WornOutfits)
+	}
+}
+
+func TestIntegration_ContentIdentityFollowsRenamedOutfits(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", integrationConfigHome(t))
+	deps := newProductionStyleRuntimeDependencies()
+	root := integrationWardrobeRoot(t, map[string][]string{
+		"casual": {"one.avatar", "two.avatar"},
+		"formal": {"suit.avatar"},
+	})
+	for _, name := range []string{"one.avatar", "two.avatar"} {
+		if err := os.WriteFile(filepath.Join(root, "casual", name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app, err := CreateApplicationFromConfiguration(Configuration{OutfitPath: root, Language: "en"}, deps)
+	if err != nil {
+		t.Fatalf("CreateApplicationFromConfiguration() error = %v", err)
+	}
+	if _, code := ExecuteCommand([]string{"config", "set-identity", "content"}, app, TerminalConsole{stdout: &bytes.Buffer{}}); code != 0 {
+		t.Fatalf("config set-identity exit code = %d", code)
+	}
+	if err := app.WearOutfit(entities.NewOutfitReference("one.avatar", entities.NewCategoryReference("casual", filepath.Join(root, "casual")))); err != nil {
+		t.Fatalf("WearOutfit() error = %v", err)
+	}
+	if err := os.Rename(filepath.Join(root, "casual", "one.avatar"), filepath.Join(root, "formal", "first.avatar")); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := LoadApplicationFromExistingConfig(deps)
+	if err != nil {
+		t.Fatalf("LoadApplicationFromExistingConfig() error = %v", err)
+	}
+	state, err := reloaded.GetOutfitState(entities.NewCategoryReference("formal", ""))
+	if err != nil {
+		t.Fatalf("GetOutfitState() error = %v", err)
+	}
+	if len(state.WornOutfits) != 1 || state.WornOutfits[0].FileName != "first.avatar" {
+		t.Fatalf("worn outfits = %#v, want first.avatar worn after the move", state.WornOutfits)
+	}
+	var stdout bytes.Buffer
+	ExecuteCommand([]string{"doctor"}, reloaded, TerminalConsole{stdout: &stdout, stderr: &bytes.Buffer{}})
+	assertOutputContains(t, stdout.String(), "Found casual/one.avatar -> formal/first.avatar renamed")
+}
+
+func TestIntegration_ReconcilesCacheWithDeletedOutfits(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", integrationConfigHome(t))
+	deps := newProductionStyleRuntimeDependencies()
+	root := integrationWardrobeRoot(t, map[string][]string{
+		"casual": {"one.avatar", "two.avatar"},
+		"formal": {"suit.avatar", "tux.avatar"},
+	})
+
+	app, err := CreateApplicationFromConfiguration(Configuration{OutfitPath: root, Language: "en"}, deps)
+	if err != nil {
+		t.Fatalf("CreateApplicationFromConfiguration() error = %v", err)
+	}
+	for _, outfit := range []entities.OutfitReference{
+		entities.NewOutfitReference("one.avatar", entities.NewCategoryReference("casual", filepath.Join(root, "casual"))),
+		entities.NewOutfitReference("suit.avatar", entities.NewCategoryReference("formal", filepath.Join(root, "formal"))),
+	} {
+		if err := app.WearOutfit(outfit); err != nil {
+			t.Fatalf("WearOutfit(%s) error = %v", outfit.FileName, err)
+		}
+	}
+	if err := os.Remove(filepath.Join(root, "casual", "one.avatar")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(root, "formal")); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := LoadApplicationFromExistingConfig(deps)
+	if err != nil {
+		t.Fatalf("LoadApplicationFromExistingConfig() error = %v", err)
+	}
+	cache, err := deps.CacheManager.LoadOrCreate()
+	if err != nil {
+		t.Fatalf("LoadOrCreate() error = %v", err)
+	}
+	if _, ok := cache.Categories["formal"]; ok || len(cache.Categories["casual"].WornOutfits) != 0 || cache.Categories["casual"].TotalOutfits != 1 {
+		t.Fatalf("cache = %+v, want it reconciled on load", cache)
+	}
+
+	var stdout bytes.Buffer
+	if _, code := ExecuteCommand([]string{"cache", "reconcile"}, reloaded, TerminalConsole{stdout: &stdout}); code != 0 {
+		t.Fatalf("cache reconcile exit code = %d", code)
+	}
+	assertOutputContains(t, stdout.String(), "casual: dropped deleted outfit one.avatar", "formal: no longer in the wardrobe; dropped it with 1 worn outfit", "Reconciled 2 categories with the wardrobe")
+
+	stdout.Reset()
+	ExecuteCommand([]string{"backup", "list"}, reloaded, TerminalConsole{stdout: &stdout})
+	assertOutputContains(t, stdout.String(), "reconcile")
+}
+
+func TestIntegration_AnnouncesAndPrefersNewArrivals(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", integrationConfigHome(t))
+	deps := newProductionStyleRuntimeDependencies()
+	root := integrationWardrobeRoot(t, map[string][]string{
+		"casual": {"a.avatar", "b.avatar", "c.avatar"},
+		"hats":   {"cap.avatar"},
+	})
+	if _, err := CreateApplicationFromConfiguration(Configuration{OutfitPath: root, Language: "en"}, deps); err != nil {
+		t.Fatalf("CreateApplicationFromConfiguration() error = %v", err)
+	}
+	first, err := LoadApplicationFromExistingConfig(deps)
+	if err != nil {
+		t.Fatalf("LoadApplicationFromExistingConfig() error = %v", err)
+	}
+	var stdout bytes.Buffer
+	ExecuteCommand([]string{"changes"}, first, TerminalConsole{stdout: &stdout})
+	assertOutputContains(t, stdout.String(), "No outfits added or removed since last time")
+
+	if err := os.WriteFile(filepath.Join(root, "casual", "d.avatar"), []byte("new"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(root, "hats")); err != nil {
+		t.Fatal(err)
+	}
+	second, err := LoadApplicationFromExistingConfig(deps)
+	if err != nil {
+		t.Fatalf("LoadApplicationFromExistingConfig() error = %v", err)
+	}
+	stdout.Reset()
+	if _, code := ExecuteCommand([]string{"changes"}, second, TerminalConsole{stdout: &stdout}); code != 0 {
+		t.Fatalf("changes exit code = %d", code)
+	}
+	assertOutputContains(t, stdout.String(), "+ casual/d.avatar", "Removed category hats", "- hats/cap.avatar")
+
+	if _, code := ExecuteCommand([]string{"config", "set-new-arrivals", "first"}, second, TerminalConsole{stdout: &bytes.Buffer{}}); code != 0 {
+		t.Fatalf("config set-new-arrivals exit code = %d", code)
+	}
+	outfit, err := second.ShowNextUniqueRandomOutfit()
+	if err != nil || outfit == nil || outfit.FileName != "d.avatar" {
+		t.Fatalf("ShowNextUniqueRandomOutfit() = %v, %v; want the new arrival", outfit, err)
+	}
+	if err := second.WearOutfit(*outfit); err != nil {
+		t.Fatalf("WearOutfit() error = %v", err)
+	}
+
+	third, err := LoadApplicationFromExistingConfig(deps)
+	if err != nil {
+		t.Fatalf("LoadApplicationFromExistingConfig() error = %v", err)
+	}
+	if changes, err := third.WardrobeChanges(); err != nil || changes.Changed() {
+		t.Fatalf("WardrobeChanges() = %+v, %v; want nothing new", changes, err)
+	}
+	known, err := deps.KnownWardrobe.Load()
+	if err != nil || known == nil || !known.HasOutfit("casual", "d.avatar") || known.NewArrivals != nil {
+		t.Fatalf("known wardrobe = %+v, %v; want d.avatar recorded and no new arrivals once it was worn", known, err)
+	}
+}
+
+func TestIntegration_OfflineWardrobeQueuesWearsUntilItIsBack(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", integrationConfigHome(t))
+	deps := newProductionStyleRuntimeDependencies()
+	root := integrationWardrobeRoot(t, map[string][]string{
+		"casual": {"a.avatar", "b.avatar"},
+		"formal": {"suit.avatar"},
+	})
+	if _, err := CreateApplicationFromConfiguration(Configuration{OutfitPath: root, Language: "en"}, deps); err != nil {
+		t.Fatalf("CreateApplicationFromConfiguration() error = %v", err)
+	}
+	if _, err := LoadApplicationFromExistingConfig(deps); err != nil {
+		t.Fatalf("LoadApplicationFromExistingConfig() error = %v", err)
+	}
+
+	unmounted := root + "-unmounted"
+	if err := os.Rename(root, unmounted); err != nil {
+		t.Fatal(err)
+	}
+	offline, err := LoadApplicationFromExistingConfig(deps)
+	if err != nil {
+		t.Fatalf("LoadApplicationFromExistingConfig() offline error = %v", err)
+	}
+	if !offline.Offline() {
+		t.Fatal("Offline() = false with the wardrobe unmounted, want true")
+	}
+	infos, err := offline.GetCategoryInfo()
+	if err != nil || len(infos) != 2 || infos[0].OutfitCount != 2 {
+		t.Fatalf("GetCategoryInfo() offline = %+v, %v; want both categories as last seen", infos, err)
+	}
+	before, err := deps.ConfigManager.LoadOrCreate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	if _, code := ExecuteCommand([]string{"pick", "--category", "formal", "--mark-worn"}, offline, TerminalConsole{stdout: &stdout}); code != 0 {
+		t.Fatalf("pick exit code = %d, output %q", code, stdout.String())
+	}
+	assertOutputContains(t, stdout.String(), "Queued to be marked worn once the wardrobe is back")
+	if err := offline.WearOutfit(entities.NewOutfitReference("a.avatar", entities.NewCategoryReference("casual", filepath.Join(root, "casual")))); !errors.Is(err, domainerrors.ErrWearQueued) {
+		t.Fatalf("WearOutfit() offline error = %v, want ErrWearQueued", err)
+	}
+	if after, err := deps.ConfigManager.LoadOrCreate(); err != nil || after.Revision != before.Revision {
+		t.Fatalf("config after queuing wears = %+v, %v; want revision %d", after, err, before.Revision)
+	}
+	stdout.Reset()
+	ExecuteCommand([]string{"queue", "list"}, offline, TerminalConsole{stdout: &stdout})
+	assertOutputContains(t, stdout.String(), "Wardrobe is offline", "formal/suit.avatar", "casual/a.avatar")
+	stdout.Reset()
+	if _, code := ExecuteCommand([]string{"doctor"}, offline, TerminalConsole{stdout: &stdout, stderr: &stdout}); code != 1 {
+		t.Fatalf("doctor exit code = %d offline, want 1", code)
+	}
+	assertOutputContains(t, stdout.String(), "Wardrobe is offline; categories below are as last seen", "Found 2 categories")
+
+	if err := os.Rename(unmounted, root); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(root, "formal", "suit.avatar")); err != nil {
+		t.Fatal(err)
+	}
+	online, err := LoadApplicationFromExistingConfig(deps)
+	if err != nil {
+		t.Fatalf("LoadApplicationFromExistingConfig() online error = %v", err)
+	}
+	applied, err := online.AppliedWears()
+	if err != nil || online.Offline() || len(applied.Applied) != 1 || applied.Applied[0].FileName != "a.avatar" || len(applied.Dropped) != 1 {
+		t.Fatalf("AppliedWears() = %+v, %v; want a.avatar applied and suit.avatar dropped", applied, err)
+	}
+	state, err := online.GetOutfitState(entities.NewCategoryReference("casual", ""))
+	if err != nil || len(state.WornOutfits) != 1 || state.WornOutfits[0].FileName != "a.avatar" {
+		t.Fatalf("worn outfits = %#v, %v; want a.avatar", state.WornOutfits, err)
+	}
+	if queued, err := online.QueuedWears(); err != nil || len(queued) != 0 {
+		t.Fatalf("QueuedWears() = %+v, %v; want none left", queued, err)
+	}
+}
+
+func TestIntegration_PicksFromOutfitPackArchives(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", integrationConfigHome(t))
+	deps := newProductionStyleRuntimeDependencies()
+	pack := filepath.Join(cliTestHomeTempDir(t, "outfitpicker-integration-packs-"), "summer.zip")
+	file, err := os.Create(pack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := zip.NewWriter(file)
+	for _, name := range []string{"casual/a.avatar", "casual/b.avatar", "notes.txt"} {
+		if _, err := archive.Create(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateApplicationFromConfiguration(Configuration{OutfitPath: pack, Language: "en"}, deps); err != nil {
+		t.Fatalf("CreateApplicationFromConfiguration() error = %v", err)
+	}
+	app, err := LoadApplicationFromExistingConfig(deps)
+	if err != nil {
+		t.Fatalf("LoadApplicationFromExistingConfig() error = %v", err)
+	}
+
+	infos, err := app.GetCategoryInfo()
+	if err != nil || len(infos) != 1 || infos[0].Category.Name != "casual" || infos[0].OutfitCount != 2 {
+		t.Fatalf("GetCategoryInfo() = %+v, %v; want casual read from the archive", infos, err)
+	}
+	var stdout bytes.Buffer
+	if _, code := ExecuteCommand([]string{"pick", "--category", "casual", "--mark-worn"}, app, TerminalConsole{stdout: &stdout}); code != 0 {
+		t.Fatalf("pick exit code = %d, output %q", code, stdout.String())
+	}
+	assertOutputContains(t, stdout.String(), "Path:     zip:"+filepath.ToSlash(pack)+"!/casual/")
+
+	reloaded, err := LoadApplicationFromExistingConfig(deps)
+	if err != nil {
+		t.Fatalf("LoadApplicationFromExistingConfig() error = %v", err)
+	}
+	state, err := reloaded.GetOutfitState(entities.NewCategoryReference("casual", ""))
+	if err != nil || len(state.WornOutfits) != 1 || len(state.AvailableOutfits) != 1 {
+		t.Fatalf("GetOutfitState() = %+v, %v; want the picked outfit worn", state, err)
+	}
+}
+
+func TestIntegration_FollowsSymlinkedCategoriesOnceAllowed(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", integrationConfigHome(t))
+	deps := newProductionStyleRuntimeDependencies()
+	root := integrationWardrobeRoot(t, map[string][]string{"casual": {"tee.avatar"}})
+	packs := integrationWardrobeRoot(t, map[string][]string{"summer": {"linen.avatar", "shorts.avatar"}})
+	if err := os.Symlink(filepath.Join(packs, "summer"), filepath.Join(root, "shared")); err != nil {
+		t.Skipf("os.Symlink() unavailable: %v", err)
+	}
+	if err := os.Symlink(root, filepath.Join(root, "loop")); err != nil {
+		t.Fatal(err)
+	}
+	linkedRoot := filepath.Join(packs, "wardrobe")
+	if err := os.Symlink(root, linkedRoot); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CreateApplicationFromConfiguration(Configuration{OutfitPath: root, Language: "en"}, deps); err != nil {
+		t.Fatalf("CreateApplicationFromConfiguration() error = %v", err)
+	}
+	app, err := LoadApplicationFromExistingConfig(deps)
+	if err != nil {
+		t.Fatalf("LoadApplicationFromExistingConfig() error = %v", err)
+	}
+	states := func(app *Application) map[string]entities.CategoryState {
+		t.Helper()
+		infos, err := app.GetCategoryInfo()
+		if err != nil {
+			t.Fatalf("GetCategoryInfo() error = %v", err)
+		}
+		states := make(map[string]entities.CategoryState, len(infos))
+		for _, info := range infos {
+			states[info.Category.Name] = info.State
+		}
+		return states
+	}
+	if got := states(app); got["shared"] != entities.CategoryStateSymlinkRejected || got["loop"] != entities.CategoryStateSymlinkRejected {
+		t.Fatalf("category states = %v, want the links rejected", got)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if _, code := ExecuteCommand([]string{"config", "set-root", linkedRoot}, app, TerminalConsole{stdout: &stdout, stderr: &stderr}); code != 1 {
+		t.Fatalf("set-root to a symlinked root exit code = %d, want 1 while links are rejected", code)
+	}
+	for _, args := range [][]string{
+		{"config", "set-symlinks", "follow", "--within", root, "--within", packs},
+		{"config", "set-root", linkedRoot},
+	} {
+		if _, code := ExecuteCommand(args, app, TerminalConsole{stdout: &stdout, stderr: &stderr}); code != 0 {
+			t.Fatalf("%v exit code = %d, output %q", args, code, stderr.String())
+		}
+	}
+
+	reloaded, err := LoadApplicationFromExistingConfig(deps)
+	if err != nil {
+		t.Fatalf("LoadApplicationFromExistingConfig() error = %v", err)
+	}
+	want := map[string]entities.CategoryState{
+		"casual": entities.CategoryStateHasOutfits,
+		"loop":   entities.CategoryStateSymlinkLoop,
+		"shared": entities.CategoryStateHasOutfits,
+	}
+	if got := states(reloaded); !reflect.DeepEqual(got, want) {
+		t.Fatalf("category states = %v, want %v", got, want)
+	}
+	stdout.Reset()
+	if _, code := ExecuteCommand([]string{"pick", "--category", "shared", "--mark-worn"}, reloaded, TerminalConsole{stdout: &stdout}); code != 0 {
+		t.Fatalf("pick exit code = %d, output %q", code, stdout.String())
+	}
+	stdout.Reset()
+	if _, code := ExecuteCommand([]string{"doctor"}, reloaded, TerminalConsole{stdout: &stdout, stderr: &stdout}); code != 1 {
+		t.Fatalf("doctor exit code = %d, want 1 for the loop", code)
+	}
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolvedPack, err := filepath.EvalSymlinks(filepath.Join(packs, "summer"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertOutputContains(t, stdout.String(),
+		"Wardrobe root "+linkedRoot+" is a symbolic link to "+resolvedRoot,
+		"shared is a symbolic link to "+resolvedPack,
+		"loop can't be read",
+		"Point the link "+filepath.Join(linkedRoot, "loop")+" at a folder that does not hold it",
+	)
 }
 
 func TestIntegration_ExcludedCategoriesHonoredEndToEnd(t *testing.T) {
@@ -219,6 +666,207 @@ func TestIntegration_FactoryResetDeletesConfigAndCache(t *testing.T) {
 	}
 }
 
+func TestIntegration_UnversionedFilesAreUpgradedAndBackedUp(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", integrationConfigHome(t))
+	deps := newProductionStyleRuntimeDependencies()
+	root := integrationWardrobeRoot(t, map[string][]string{
+		"casual": {"one.avatar", "two.avatar"},
+	})
+
+	configPath := integrationConfigPath(t)
+	cachePath := integrationCachePath(t)
+	legacyConfig := `{"root":` + strconv.Quote(root) + `,"language":"en"}`
+	legacyCache := `{"categories":{"casual":{"wornOutfits":{"one.avatar":true},"totalOutfits":2}}}`
+	integrationWriteFile(t, configPath, legacyConfig)
+	integrationWriteFile(t, cachePath, legacyCache)
+
+	app, err := LoadApplicationFromExistingConfig(deps)
+	if err != nil {
+		t.Fatalf("LoadApplicationFromExistingConfig() error = %v", err)
+	}
+	if err := app.WearOutfit(entities.NewOutfitReference("two.avatar", entities.NewCategoryReference("casual", filepath.Join(root, "casual")))); err == nil {
+		t.Fatal("WearOutfit() error = nil, want the upgraded history to complete the rotation")
+	}
+
+	for path, original := range map[string]string{configPath: legacyConfig, cachePath: legacyCache} {
+		backup, err := os.ReadFile(path + ".v0.bak")
+		if err != nil || string(backup) != original {
+			t.Fatalf("backup of %s = %q, %v; want original document", filepath.Base(path), backup, err)
+		}
+	}
+	cacheData, err := os.ReadFile(cachePath)
+	if err != nil || !strings.Contains(string(cacheData), `"version": 2`) {
+		t.Fatalf("cache after save = %s, %v; want current schema version", cacheData, err)
+	}
+}
+
+func TestIntegration_NewerConfigFailsWithoutOfferingRecovery(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", integrationConfigHome(t))
+	deps := newProductionStyleRuntimeDependencies()
+	configPath := integrationConfigPath(t)
+	future := `{"version":99,"root":"/wardrobe","language":"en"}`
+	integrationWriteFile(t, configPath, future)
+
+	_, err := LoadApplicationFromExistingConfig(deps)
+	if !errors.Is(err, domainerrors.ErrNewerSchema) {
+		t.Fatalf("LoadApplicationFromExistingConfig() error = %v, want %v", err, domainerrors.ErrNewerSchema)
+	}
+	if _, ok := BootstrapApplication(deps, nil); ok {
+		t.Fatal("BootstrapApplication() expected failure for a config from a newer version")
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != future {
+		t.Fatalf("config = %s, want the newer config left untouched", data)
+	}
+}
+
+func TestIntegration_SwitchingToSQLiteKeepsConfigAndWornOutfits(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", integrationConfigHome(t))
+	root := integrationWardrobeRoot(t, map[string][]string{
+		"casual": {"one.avatar", "two.avatar", "three.avatar"},
+	})
+	casual := entities.NewCategoryReference("casual", filepath.Join(root, "casual"))
+
+	app, err := CreateApplicationFromConfiguration(Configuration{OutfitPath: root, Language: "en"}, newProductionStyleRuntimeDependencies())
+	if err != nil {
+		t.Fatalf("CreateApplicationFromConfiguration() error = %v", err)
+	}
+	if err := app.WearOutfit(entities.NewOutfitReference("one.avatar", casual)); err != nil {
+		t.Fatalf("WearOutfit() error = %v", err)
+	}
+	if _, code := ExecuteCommand([]string{"config", "set-storage", "sqlite"}, app, nil); code != 0 {
+		t.Fatalf("config set-storage sqlite exit code = %d", code)
+	}
+
+	sqliteApp, err := LoadApplicationFromExistingConfig(newProductionStyleRuntimeDependencies())
+	if err != nil {
+		t.Fatalf("LoadApplicationFromExistingConfig() error = %v", err)
+	}
+	config, err := sqliteApp.GetConfiguration()
+	if err != nil || config.Root != root || config.Storage != entities.StorageSQLite {
+		t.Fatalf("GetConfiguration() = %+v, %v; want config loaded from sqlite", config, err)
+	}
+	if err := sqliteApp.WearOutfit(entities.NewOutfitReference("two.avatar", casual)); err != nil {
+		t.Fatalf("WearOutfit() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(integrationConfigPath(t)), "outfitpicker.db")); err != nil {
+		t.Fatalf("database missing: %v", err)
+	}
+
+	if _, code := ExecuteCommand([]string{"config", "set-storage", "json"}, sqliteApp, nil); code != 0 {
+		t.Fatalf("config set-storage json exit code = %d", code)
+	}
+	jsonApp, err := LoadApplicationFromExistingConfig(newProductionStyleRuntimeDependencies())
+	if err != nil {
+		t.Fatalf("LoadApplicationFromExistingConfig() error = %v", err)
+	}
+	state, err := jsonApp.GetOutfitState(entities.NewCategoryReference("casual", ""))
+	if err != nil || len(state.WornOutfits) != 2 {
+		t.Fatalf("worn outfits = %#v, %v; want both wears back in cache.json", state.WornOutfits, err)
+	}
+}
+
+func TestIntegration_JournalRestoresEarlierStateAndRepairsCache(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", integrationConfigHome(t))
+	root := integrationWardrobeRoot(t, map[string][]string{
+		"casual": {"one.avatar", "two.avatar", "three.avatar"},
+	})
+	casual := entities.NewCategoryReference("casual", filepath.Join(root, "casual"))
+
+	app, err := CreateApplicationFromConfiguration(Configuration{OutfitPath: root, Language: "en"}, newProductionStyleRuntimeDependencies())
+	if err != nil {
+		t.Fatalf("CreateApplicationFromConfiguration() error = %v", err)
+	}
+	if err := app.WearOutfit(entities.NewOutfitReference("one.avatar", casual)); err != nil {
+		t.Fatalf("WearOutfit() error = %v", err)
+	}
+	beforeSecondWear := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	if err := app.WearOutfit(entities.NewOutfitReference("two.avatar", casual)); err != nil {
+		t.Fatalf("WearOutfit() error = %v", err)
+	}
+
+	cachePath := filepath.Join(filepath.Dir(integrationConfigPath(t)), "cache.json")
+	integrationWriteFile(t, cachePath, "{not json")
+	var stdout bytes.Buffer
+	if _, code := ExecuteCommand([]string{"doctor", "--repair"}, app, TerminalConsole{stdout: &stdout, stderr: &bytes.Buffer{}}); code != 0 {
+		t.Fatalf("doctor --repair exit code = %d, output %q", code, stdout.String())
+	}
+	state, err := app.GetOutfitState(entities.NewCategoryReference("casual", ""))
+	if err != nil || len(state.WornOutfits) != 2 {
+		t.Fatalf("worn outfits after repair = %#v, %v; want both wears rebuilt", state.WornOutfits, err)
+	}
+
+	at := beforeSecondWear.UTC().Format(time.RFC3339Nano)
+	if _, code := ExecuteCommand([]string{"restore", "--at", at}, app, TerminalConsole{stdout: &stdout}); code != 0 {
+		t.Fatalf("restore exit code = %d", code)
+	}
+	restarted, err := LoadApplicationFromExistingConfig(newProductionStyleRuntimeDependencies())
+	if err != nil {
+		t.Fatalf("LoadApplicationFromExistingConfig() error = %v", err)
+	}
+	state, err = restarted.GetOutfitState(entities.NewCategoryReference("casual", ""))
+	if err != nil || len(state.WornOutfits) != 1 || state.WornOutfits[0].FileName != "one.avatar" {
+		t.Fatalf("worn outfits after restore = %#v, %v; want only one.avatar", state.WornOutfits, err)
+	}
+
+	if _, code := ExecuteCommand([]string{"restore", "--at", time.Now().UTC().Format(time.RFC3339Nano)}, restarted, TerminalConsole{stdout: &stdout}); code != 0 {
+		t.Fatalf("restore to now exit code = %d", code)
+	}
+	state, err = restarted.GetOutfitState(entities.NewCategoryReference("casual", ""))
+	if err != nil || len(state.WornOutfits) != 1 {
+		t.Fatalf("worn outfits after restoring to now = %#v, %v; want the earlier restore kept", state.WornOutfits, err)
+	}
+}
+
+func TestIntegration_SnapshotBeforeFactoryResetRestoresState(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", integrationConfigHome(t))
+	root := integrationWardrobeRoot(t, map[string][]string{
+		"casual": {"one.avatar", "two.avatar"},
+	})
+	casual := entities.NewCategoryReference("casual", filepath.Join(root, "casual"))
+
+	app, err := CreateApplicationFromConfiguration(Configuration{OutfitPath: root, Language: "en"}, newProductionStyleRuntimeDependencies())
+	if err != nil {
+		t.Fatalf("CreateApplicationFromConfiguration() error = %v", err)
+	}
+	if err := app.WearOutfit(entities.NewOutfitReference("one.avatar", casual)); err != nil {
+		t.Fatalf("WearOutfit() error = %v", err)
+	}
+	if err := app.FactoryReset(); err != nil {
+		t.Fatalf("FactoryReset() error = %v", err)
+	}
+	if _, err := os.Stat(integrationConfigPath(t)); !os.IsNotExist(err) {
+		t.Fatalf("config after factory reset: %v, want it removed", err)
+	}
+
+	backups, err := app.ListBackups()
+	if err != nil || len(backups) != 1 || backups[0].Reason != "factory-reset" {
+		t.Fatalf("ListBackups() = %+v, %v; want the factory-reset snapshot", backups, err)
+	}
+	var stdout bytes.Buffer
+	if _, code := ExecuteCommand([]string{"backup", "restore", backups[0].Name}, app, TerminalConsole{stdout: &stdout, stderr: &stdout}); code != 0 {
+		t.Fatalf("backup restore exit code = %d, output %q", code, stdout.String())
+	}
+	restarted, err := LoadApplicationFromExistingConfig(newProductionStyleRuntimeDependencies())
+	if err != nil {
+		t.Fatalf("LoadApplicationFromExistingConfig() error = %v", err)
+	}
+	state, err := restarted.GetOutfitState(entities.NewCategoryReference("casual", ""))
+	if err != nil || len(state.WornOutfits) != 1 || state.WornOutfits[0].FileName != "one.avatar" {
+		t.Fatalf("worn outfits after restore = %#v, %v; want one.avatar", state.WornOutfits, err)
+	}
+}
+
+func integrationWriteFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatalf("MkdirAll(%q) error = %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("WriteFile(%q) error = %v", path, err)
+	}
+}
+
 func integrationWardrobeRoot(t *testing.T, categories map[string][]string) string {
 	t.Helper()
 	root := cliTestHomeTempDir(t, "outfitpicker-integration-wardrobe-")
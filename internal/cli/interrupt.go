@@ -0,0 +1,19 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// interruptSignals are the signals that interrupt outfitpicker.
+var interruptSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// InterruptContext returns a context that is cancelled when outfitpicker is
+// interrupted, such as with Ctrl+C, so that a wardrobe scan waiting on a hung
+// directory gives up at once. Until cancel is called the signals cancel the
+// context instead of stopping the process; afterwards they stop it again.
+func InterruptContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), interruptSignals...)
+}
@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestInterruptContext_CancelledWhenInterrupted(t *testing.T) {
+	ctx, cancel := InterruptContext()
+	defer cancel()
+
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := process.Signal(os.Interrupt); err != nil {
+		t.Skipf("cannot interrupt the test process: %v", err)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context was not cancelled by the interrupt")
+	}
+}
@@ -47,6 +47,16 @@ func (m MainMenu) Show() menuTransition {
 		m.renderer.ShowWardrobeSummary(rootPath, categoryInfos, m.outfitService)
 	}
 
+	if m.outfitService.Offline() {
+		queued, _ := m.outfitService.QueuedWears()
+		m.renderer.ShowOffline(len(queued))
+	}
+	if changes, err := m.outfitService.WardrobeChanges(); err == nil {
+		m.renderer.ShowWardrobeChanges(changes)
+	}
+	if applied, err := m.outfitService.AppliedWears(); err == nil {
+		m.renderer.ShowAppliedWears(applied)
+	}
 	if len(availableCategories) > 0 {
 		m.renderer.ShowAvailableCategories(availableCategories, m.outfitService)
 	}
@@ -110,7 +120,7 @@ func (m MainMenu) handleRandomOutfit() menuTransition {
 		result := m.presentation.PresentOutfitWithCategoryChoice(*randomOutfit, randomOutfit.Category.Name)
 		switch result {
 		case OutfitChoiceWorn:
-			m.terminal().Success("Marked as worn. Goodbye!")
+			m.terminal().Success(wornGoodbye(m.outfitService))
 			return exitMenuTransition()
 		case OutfitChoiceBack:
 			return mainMenuTransition()
@@ -204,12 +214,12 @@ func (m MainMenu) handleManualSelection() menuTransition {
 		}
 
 		selectedOutfit := allOutfits[outfitIndex-1]
-		result := m.presentation.PresentManualOutfit(selectedOutfit, selectedCategory.Name, wornFileNames[selectedOutfit.FileName])
+		result := m.presentation.PresentManualOutfit(selectedOutfit, selectedCategory.Name, wornFileNames[selectedOutfit.Key()])
 		switch result {
 		case OutfitChoiceSkipped, OutfitChoiceBack:
 			continue
 		case OutfitChoiceWorn:
-			m.terminal().Success("Marked as worn. Goodbye!")
+			m.terminal().Success(wornGoodbye(m.outfitService))
 			return exitMenuTransition()
 		case OutfitChoiceQuit:
 			m.terminal().Println("Goodbye!")
@@ -217,3 +227,12 @@ func (m MainMenu) handleManualSelection() menuTransition {
 		}
 	}
 }
+
+// wornGoodbye is the farewell once an outfit is marked worn, or queued to be
+// while the wardrobe is offline.
+func wornGoodbye(service OutfitService) string {
+	if service.Offline() {
+		return "Queued to be marked worn. Goodbye!"
+	}
+	return "Marked as worn. Goodbye!"
+}
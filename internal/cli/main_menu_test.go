@@ -46,6 +46,39 @@ func TestMainMenu_Show(t *testing.T) {
 		assertMenuTransitionWithPrompts(t, menuDestinationExit, menu.Show, "q")
 	})
 
+	t.Run("offline shows the wardrobe as last seen", func(t *testing.T) {
+		picker := newStubRuntime()
+		picker.wardrobe.categoryInfos = []entities.CategoryInfo{entities.NewCategoryInfo(mainMenuCategory("casual"), entities.CategoryStateHasOutfits, 1)}
+		picker.offline = true
+		picker.queued = []entities.QueuedWear{{Category: "casual", FileName: "one.avatar"}, {Category: "casual", FileName: "two.avatar"}}
+		menu := newMainMenuForTest(picker)
+		menu.outfitService = NewOutfitServiceFromRuntime(picker)
+
+		output := captureStdout(t, func() {
+			assertMenuTransitionWithPrompts(t, menuDestinationExit, menu.Show, "q")
+		})
+		assertOutputContains(t, output, "Offline: your outfit folder can't be read", "(2 wears queued)")
+	})
+
+	t.Run("shows wears applied since going offline", func(t *testing.T) {
+		picker := newStubRuntime()
+		picker.wardrobe.categoryInfos = []entities.CategoryInfo{entities.NewCategoryInfo(mainMenuCategory("casual"), entities.CategoryStateHasOutfits, 1)}
+		picker.applied = entities.AppliedWears{
+			Applied: []entities.QueuedWear{{Category: "casual", FileName: "one.avatar"}},
+			Dropped: []entities.QueuedWear{{Category: "casual", FileName: "gone.avatar", Root: "/nas"}},
+		}
+		menu := newMainMenuForTest(picker)
+		menu.outfitService = NewOutfitServiceFromRuntime(picker)
+
+		output := captureStdout(t, func() {
+			assertMenuTransitionWithPrompts(t, menuDestinationExit, menu.Show, "q")
+		})
+		assertOutputContains(t, output, "Queued While Offline", "casual: one", "(marked worn)", "casual: gone", "(no longer in the wardrobe)")
+		if strings.Contains(output, "Offline:") {
+			t.Errorf("Show() online output = %q, want no offline banner", output)
+		}
+	})
+
 	t.Run("shows with no available categories", func(t *testing.T) {
 		infos := []entities.CategoryInfo{entities.NewCategoryInfo(mainMenuCategory("docs"), entities.CategoryStateNoAvatarFiles, 0)}
 		picker := newStubRuntime()
@@ -174,6 +207,23 @@ func TestMainMenu_handleRandomOutfit(t *testing.T) {
 		assertMenuTransitionWithPrompts(t, menuDestinationMain, menu.handleRandomOutfit, "b")
 	})
 
+	t.Run("offline wear is queued", func(t *testing.T) {
+		picker := newStubRuntime()
+		picker.random.globalResults = []stubSelectorResult{{outfit: mainMenuOutfitPtr("casual", "one.avatar")}}
+		picker.commands.wearErr = domainerrors.ErrWearQueued
+		picker.offline = true
+		var output strings.Builder
+		menu := newMainMenuForTest(picker)
+		menu.outfitService = NewOutfitServiceFromRuntime(picker)
+		menu.console = TerminalConsole{stdout: &output}
+
+		presented := captureStdout(t, func() {
+			assertMenuTransitionWithPrompts(t, menuDestinationExit, menu.handleRandomOutfit, "w")
+		})
+		assertOutputContains(t, presented, "marked worn once it's back")
+		assertOutputContains(t, output.String(), "Queued to be marked worn. Goodbye!")
+	})
+
 	t.Run("quit on wear failure", func(t *testing.T) {
 		picker := newStubRuntime()
 		picker.random.globalResults = []stubSelectorResult{{outfit: mainMenuOutfitPtr("casual", "one.avatar")}}
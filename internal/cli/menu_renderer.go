@@ -91,22 +91,25 @@ func (r MenuRenderer) ShowAvailableCategories(availableCategories []entities.Cat
 func (r MenuRenderer) ShowUnavailableCategories(categoryInfos []entities.CategoryInfo, outfitService OutfitService) {
 	var excluded []string
 	var noOutfits []string
+	var unreadable []string
 
 	for _, info := range categoryInfos {
-		switch info.State {
-		case entities.CategoryStateUserExcluded:
+		switch {
+		case info.State == entities.CategoryStateUserExcluded:
 			count, err := outfitService.GetActualOutfitCount(info.Category)
 			if err == nil {
 				excluded = append(excluded, fmt.Sprintf("%s (%d outfits)", sanitizeTerminalText(info.Category.Name), count))
 			} else {
 				excluded = append(excluded, sanitizeTerminalText(info.Category.Name))
 			}
-		case entities.CategoryStateEmpty, entities.CategoryStateNoAvatarFiles:
-			noOutfits = append(noOutfits, fmt.Sprintf("%s (Add .avatar files to %s)", sanitizeTerminalText(info.Category.Name), sanitizeTerminalText(info.Category.Path)))
+		case info.State == entities.CategoryStateEmpty, info.State == entities.CategoryStateNoAvatarFiles:
+			noOutfits = append(noOutfits, fmt.Sprintf("%s (%s)", sanitizeTerminalText(info.Category.Name), categoryHint(info)))
+		case info.State.Failed():
+			unreadable = append(unreadable, fmt.Sprintf("%s: %s (%s)", sanitizeTerminalText(info.Category.Name), categoryStateLabel(info.State), categoryHint(info)))
 		}
 	}
 
-	if len(excluded) == 0 && len(noOutfits) == 0 {
+	if len(excluded) == 0 && len(noOutfits) == 0 && len(unreadable) == 0 {
 		return
 	}
 
@@ -123,6 +126,76 @@ func (r MenuRenderer) ShowUnavailableCategories(categoryInfos []entities.Categor
 			r.terminal().Printf("    • %s\n", line)
 		}
 	}
+	if len(unreadable) > 0 {
+		sort.Strings(unreadable)
+		r.terminal().Printf("  ⛔ %s\n", Dim("Can't read:"))
+		for _, line := range unreadable {
+			r.terminal().Printf("    • %s\n", line)
+		}
+	}
+}
+
+// ShowWardrobeChanges lists the categories and outfits added to and removed
+// from the wardrobe since the last run, if there are any.
+func (r MenuRenderer) ShowWardrobeChanges(changes entities.WardrobeChanges) {
+	var added, removed []string
+	for _, category := range changes.Categories {
+		name := sanitizeTerminalText(category.Category)
+		switch {
+		case category.Added:
+			added = append(added, fmt.Sprintf("%s %s", name, Dim(fmt.Sprintf("(new category, %d %s)", len(category.AddedOutfits), pluralize("outfit", len(category.AddedOutfits))))))
+		case category.Removed:
+			removed = append(removed, fmt.Sprintf("%s %s", name, Dim("(category removed)")))
+		default:
+			if len(category.AddedOutfits) > 0 {
+				added = append(added, fmt.Sprintf("%s: %s", name, displayOutfitNames(category.AddedOutfits)))
+			}
+			if len(category.RemovedOutfits) > 0 {
+				removed = append(removed, fmt.Sprintf("%s: %s", name, displayOutfitNames(category.RemovedOutfits)))
+			}
+		}
+	}
+	if len(added) > 0 {
+		r.terminal().Println()
+		SectionWithConsole(r.console, "New Since Last Time", "✨", uiGreen)
+		for _, line := range added {
+			r.terminal().Printf("  • %s\n", line)
+		}
+	}
+	if len(removed) > 0 {
+		r.terminal().Println()
+		SectionWithConsole(r.console, "Removed Since Last Time", "🗑", uiYellow)
+		for _, line := range removed {
+			r.terminal().Printf("  • %s\n", line)
+		}
+	}
+}
+
+// ShowOffline explains that the wardrobe is shown as last recorded because it
+// cannot be read, and how many wears are waiting for it.
+func (r MenuRenderer) ShowOffline(queued int) {
+	r.terminal().Warning("Offline: your outfit folder can't be read, so this is your wardrobe as last seen")
+	message := "Outfits you wear are queued and marked worn once the folder is back"
+	if queued > 0 {
+		message += fmt.Sprintf(" (%d %s queued)", queued, pluralize("wear", queued))
+	}
+	r.terminal().Info(message)
+}
+
+// ShowAppliedWears lists the wears queued while the wardrobe was offline that
+// were marked worn, or dropped because their outfits are gone, on this run.
+func (r MenuRenderer) ShowAppliedWears(applied entities.AppliedWears) {
+	if applied.Empty() {
+		return
+	}
+	r.terminal().Println()
+	SectionWithConsole(r.console, "Queued While Offline", "📥", uiGreen)
+	for _, wear := range applied.Applied {
+		r.terminal().Printf("  • %s: %s %s\n", sanitizeTerminalText(wear.Category), displayOutfitName(wear.FileName), Dim("(marked worn)"))
+	}
+	for _, wear := range applied.Dropped {
+		r.terminal().Printf("  • %s: %s %s\n", sanitizeTerminalText(wear.Category), displayOutfitName(wear.FileName), Dim("(no longer in the wardrobe)"))
+	}
 }
 
 func (r MenuRenderer) ShowMenuOptions() {
@@ -171,7 +244,7 @@ func (r MenuRenderer) ShowManualSelectionOutfits(allOutfits []entities.OutfitRef
 	SectionWithConsole(r.console, fmt.Sprintf("Outfits in %s", sanitizeTerminalText(categoryName)), "👗", uiBlue)
 	for index, outfit := range allOutfits {
 		wornStatus := ""
-		if wornFileNames[outfit.FileName] {
+		if wornFileNames[outfit.Key()] {
 			wornStatus = " " + Dim("(worn)")
 		}
 		r.terminal().Printf("  %s %s%s\n", KeyLabel(fmt.Sprintf("%d", index+1)), displayOutfitName(outfit.FileName), wornStatus)
@@ -201,3 +274,54 @@ func max(a, b int) int {
 	}
 	return b
 }
+
+// categoryStateLabel describes the state of a category without outfits to
+// offer.
+func categoryStateLabel(state entities.CategoryState) string {
+	switch state {
+	case entities.CategoryStateEmpty:
+		return "empty"
+	case entities.CategoryStateNoAvatarFiles:
+		return "no .avatar files"
+	case entities.CategoryStateUserExcluded:
+		return "excluded"
+	case entities.CategoryStateTimedOut:
+		return "not responding"
+	case entities.CategoryStateUnreadable:
+		return "can't be read"
+	case entities.CategoryStatePermissionDenied:
+		return "permission denied"
+	case entities.CategoryStateSymlinkRejected:
+		return "symbolic link"
+	case entities.CategoryStateSymlinkLoop:
+		return "symbolic link loop"
+	case entities.CategoryStateSymlinkOutsideRoots:
+		return "link outside allowed roots"
+	default:
+		return string(state)
+	}
+}
+
+// categoryHint suggests what would give a category without outfits some, or
+// returns "" when there is nothing to suggest.
+func categoryHint(info entities.CategoryInfo) string {
+	path := sanitizeTerminalText(info.Category.Path)
+	switch info.State {
+	case entities.CategoryStateEmpty, entities.CategoryStateNoAvatarFiles:
+		return fmt.Sprintf("Add .avatar files to %s", path)
+	case entities.CategoryStateTimedOut:
+		return fmt.Sprintf("Check that %s is reachable", path)
+	case entities.CategoryStateUnreadable:
+		return fmt.Sprintf("Check the disk or share holding %s", path)
+	case entities.CategoryStatePermissionDenied:
+		return fmt.Sprintf("Give your user read access to %s", path)
+	case entities.CategoryStateSymlinkRejected:
+		return fmt.Sprintf("Run outfitpicker config set-symlinks follow, or replace the link %s with the folder it points to", path)
+	case entities.CategoryStateSymlinkLoop:
+		return fmt.Sprintf("Point the link %s at a folder that does not hold it", path)
+	case entities.CategoryStateSymlinkOutsideRoots:
+		return fmt.Sprintf("Allow where %s leads with outfitpicker config set-symlinks follow --within", path)
+	default:
+		return ""
+	}
+}
@@ -91,6 +91,8 @@ func TestMenuRenderer_ShowUnavailableCategories(t *testing.T) {
 				entities.NewCategoryInfo(excludedFallbackCategory, entities.CategoryStateUserExcluded, 1),
 				entities.NewCategoryInfo(emptyCategory, entities.CategoryStateEmpty, 0),
 				entities.NewCategoryInfo(noAvatarCategory, entities.CategoryStateNoAvatarFiles, 0),
+				entities.NewCategoryInfo(rendererCategory("nas"), entities.CategoryStateTimedOut, 0),
+				entities.NewCategoryInfo(rendererCategory("locked"), entities.CategoryStatePermissionDenied, 0),
 			}, service)
 		})
 
@@ -102,7 +104,32 @@ func TestMenuRenderer_ShowUnavailableCategories(t *testing.T) {
 			!strings.Contains(output, "docs (Add .avatar files to "+cliTestCategoryPath("docs")+")") {
 			t.Fatalf("ShowUnavailableCategories() no-outfits output missing expected text: %q", output)
 		}
+		if !strings.Contains(output, "Can't read:") ||
+			!strings.Contains(output, "nas: not responding (Check that "+cliTestCategoryPath("nas")+" is reachable)") ||
+			!strings.Contains(output, "locked: permission denied (Give your user read access to "+cliTestCategoryPath("locked")+")") {
+			t.Fatalf("ShowUnavailableCategories() unreadable output missing expected text: %q", output)
+		}
+	})
+}
+
+func TestMenuRenderer_ShowWardrobeChanges(t *testing.T) {
+	renderer := MenuRenderer{}
+	output := captureStdout(t, func() {
+		renderer.ShowWardrobeChanges(entities.WardrobeChanges{Categories: []entities.CategoryChanges{
+			{Category: "beach", Added: true, AddedOutfits: []string{"towel.avatar"}},
+			{Category: "casual", AddedOutfits: []string{"linen.avatar", "denim.avatar"}, RemovedOutfits: []string{"old.avatar"}},
+			{Category: "hats", Removed: true, RemovedOutfits: []string{"cap.avatar"}},
+		}})
 	})
+	for _, want := range []string{"New Since Last Time", "beach", "new category, 1 outfit", "casual: linen, denim", "Removed Since Last Time", "casual: old", "hats", "category removed"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("ShowWardrobeChanges() output missing %q: %q", want, output)
+		}
+	}
+
+	if output := captureStdout(t, func() { renderer.ShowWardrobeChanges(entities.WardrobeChanges{}) }); output != "" {
+		t.Errorf("ShowWardrobeChanges() without changes = %q, want nothing", output)
+	}
 }
 
 func TestMenuRenderer_ShowWornOutfits(t *testing.T) {
@@ -9,14 +9,22 @@ import (
 )
 
 type OutfitPresentation struct {
-	commands OutfitCommandHandler
-	console  Console
+	commands  OutfitCommandHandler
+	console   Console
+	previewer OutfitPreviewer
 }
 
 func NewOutfitPresentation(commands OutfitCommandHandler, consoles ...Console) OutfitPresentation {
 	return OutfitPresentation{commands: commands, console: optionalConsole(consoles)}
 }
 
+// WithPreviewer returns a copy of the presentation that shows companion images
+// using previewer. A nil previewer disables previews.
+func (p OutfitPresentation) WithPreviewer(previewer OutfitPreviewer) OutfitPresentation {
+	p.previewer = previewer
+	return p
+}
+
 func (p OutfitPresentation) terminal() Console {
 	return consoleOrDefault(p.console)
 }
@@ -38,6 +46,7 @@ func (p OutfitPresentation) PresentManualOutfit(outfit entities.OutfitReference,
 	}
 
 	p.terminal().Printf("\nYou selected: %s from %s%s\n", cleanName, safeCategory, wornText)
+	printOutfitPreview(p.terminal(), p.previewer, outfit)
 	promptText := "Wear this outfit? (y)es, (n)o, or (q)uit? "
 	if isWorn {
 		promptText = "Wear outfit again? (y)es, (n)o, or (q)uit? "
@@ -67,6 +76,7 @@ func (p OutfitPresentation) presentOutfit(outfit entities.OutfitReference, categ
 
 	p.terminal().Printf("\n👗 %s\n", sanitizeTerminalText(outfit.FileName))
 	p.terminal().Printf("📁 %s\n\n", sanitizeTerminalText(categoryName))
+	printOutfitPreview(p.terminal(), p.previewer, outfit)
 	p.terminal().Println("[W] Mark worn and quit")
 	p.terminal().Println("[S] Skip")
 	p.terminal().Println("[B] Back")
@@ -101,6 +111,10 @@ func (p OutfitPresentation) handleWearChoice(outfit entities.OutfitReference) Ou
 		p.terminal().Info(fmt.Sprintf("Use reset category or reset all to make %s available again.", rotationCompleted.Category))
 		return OutfitChoiceWorn
 	}
+	if errors.Is(err, domainerrors.ErrWearQueued) {
+		p.terminal().Info("Your outfit folder is offline, so this will be marked worn once it's back.")
+		return OutfitChoiceWorn
+	}
 
 	p.terminal().Error("Could not save this outfit. Please try again.")
 	return OutfitChoiceQuit
@@ -10,6 +10,8 @@ type OutfitService struct {
 	wardrobe WardrobeReader
 	config   ConfigurationController
 	commands OutfitCommandHandler
+	changes  WardrobeChangeReporter
+	queue    WearQueue
 }
 
 func NewOutfitService(wardrobe WardrobeReader, config ConfigurationController, commands OutfitCommandHandler) OutfitService {
@@ -25,7 +27,47 @@ func NewOutfitServiceFromRuntime(runtime interface {
 	ConfigurationController
 	OutfitCommandHandler
 }) OutfitService {
-	return NewOutfitService(runtime, runtime, runtime)
+	service := NewOutfitService(runtime, runtime, runtime)
+	if changes, ok := runtime.(WardrobeChangeReporter); ok {
+		service.changes = changes
+	}
+	if queue, ok := runtime.(WearQueue); ok {
+		service.queue = queue
+	}
+	return service
+}
+
+// Offline reports whether the wardrobe is shown as last recorded because it
+// cannot be read, or false when the runtime has no wear queue.
+func (s OutfitService) Offline() bool {
+	return s.queue != nil && s.queue.Offline()
+}
+
+// QueuedWears returns the wears queued while the wardrobe is offline, or
+// nothing when the runtime has no wear queue.
+func (s OutfitService) QueuedWears() ([]entities.QueuedWear, error) {
+	if s.queue == nil {
+		return nil, nil
+	}
+	return s.queue.QueuedWears()
+}
+
+// AppliedWears reports what became of the wears queued while the wardrobe
+// was offline, or nothing when the runtime has no wear queue.
+func (s OutfitService) AppliedWears() (entities.AppliedWears, error) {
+	if s.queue == nil {
+		return entities.AppliedWears{}, nil
+	}
+	return s.queue.AppliedWears()
+}
+
+// WardrobeChanges reports what was added to or removed from the wardrobe
+// since the last run, or nothing when the runtime does not look for changes.
+func (s OutfitService) WardrobeChanges() (entities.WardrobeChanges, error) {
+	if s.changes == nil {
+		return entities.WardrobeChanges{}, nil
+	}
+	return s.changes.WardrobeChanges()
 }
 
 func (s OutfitService) GetAvailableOutfits(category entities.CategoryReference) ([]entities.OutfitReference, error) {
@@ -68,8 +110,12 @@ func (s OutfitService) GetConfiguration() (*entities.Config, error) {
 	return s.config.GetConfiguration()
 }
 
-func (s OutfitService) UpdateConfiguration(config *entities.Config) error {
-	return s.config.UpdateConfiguration(config)
+func (s OutfitService) UpdateConfiguration(change ConfigChange) error {
+	return s.config.UpdateConfiguration(change)
+}
+
+func (s OutfitService) UpdateConfigurationMigratingHistory(change ConfigChange) error {
+	return s.config.UpdateConfigurationMigratingHistory(change)
 }
 
 func (s OutfitService) WearOutfit(outfit entities.OutfitReference) error {
@@ -80,6 +126,10 @@ func (s OutfitService) ResetCategory(categoryName string) error {
 	return s.commands.ResetCategory(categoryName)
 }
 
+func (s OutfitService) ResetCategoryIfUnchanged(categoryName, wornRevision string) error {
+	return s.commands.ResetCategoryIfUnchanged(categoryName, wornRevision)
+}
+
 func (s OutfitService) ResetAllCategories() error {
 	return s.commands.ResetAllCategories()
 }
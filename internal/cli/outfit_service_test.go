@@ -214,3 +214,39 @@ func outfitServiceOutfits(category entities.CategoryReference, names []string) [
 	}
 	return outfits
 }
+
+func TestOutfitService_WardrobeChanges(t *testing.T) {
+	picker := newStubRuntime()
+	picker.changes = entities.WardrobeChanges{Categories: []entities.CategoryChanges{{Category: "beach", Added: true}}}
+
+	changes, err := NewOutfitServiceFromRuntime(picker).WardrobeChanges()
+	if err != nil || !reflect.DeepEqual(changes, picker.changes) {
+		t.Fatalf("WardrobeChanges() = %+v, %v; want the runtime's changes", changes, err)
+	}
+	if changes, err := newStubOutfitService(picker).WardrobeChanges(); err != nil || changes.Changed() {
+		t.Fatalf("WardrobeChanges() without a change reporter = %+v, %v; want nothing", changes, err)
+	}
+}
+
+func TestOutfitService_WearQueue(t *testing.T) {
+	picker := newStubRuntime()
+	picker.offline = true
+	picker.queued = []entities.QueuedWear{{Category: "casual", FileName: "look.avatar"}}
+	picker.applied = entities.AppliedWears{Dropped: picker.queued}
+
+	service := NewOutfitServiceFromRuntime(picker)
+	queued, err := service.QueuedWears()
+	if !service.Offline() || err != nil || len(queued) != 1 {
+		t.Fatalf("Offline(), QueuedWears() = %v, %+v, %v; want the runtime's queue", service.Offline(), queued, err)
+	}
+	if applied, err := service.AppliedWears(); err != nil || !reflect.DeepEqual(applied, picker.applied) {
+		t.Fatalf("AppliedWears() = %+v, %v; want the runtime's", applied, err)
+	}
+
+	without := newStubOutfitService(picker)
+	queued, err = without.QueuedWears()
+	applied, appliedErr := without.AppliedWears()
+	if without.Offline() || err != nil || queued != nil || appliedErr != nil || !applied.Empty() {
+		t.Fatal("an outfit service without a wear queue reported one")
+	}
+}
@@ -31,6 +31,25 @@ func (s *OutfitSession) IsCategoryShown(fileName, category string) bool {
 	return s.categoryShown[category] != nil && s.categoryShown[category][fileName]
 }
 
+// GlobalShownKeys returns the CATEGORY/FILE keys shown by cross-category picks.
+func (s *OutfitSession) GlobalShownKeys() []string {
+	keys := make([]string, 0, len(s.globalShown))
+	for key := range s.globalShown {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// CategoryShownKeys returns the CATEGORY/FILE keys shown by picks within
+// category.
+func (s *OutfitSession) CategoryShownKeys(category string) []string {
+	keys := make([]string, 0, len(s.categoryShown[category]))
+	for fileName := range s.categoryShown[category] {
+		keys = append(keys, category+"/"+fileName)
+	}
+	return keys
+}
+
 func (s *OutfitSession) ResetGlobal() {
 	s.globalShown = map[string]bool{}
 }
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/dh85/outfitpicker/internal/application/usecases"
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+)
+
+// outfitTracker keeps the worn state of outfits whose files are renamed or
+// moved, when the configuration matches outfits by content. A nil tracker
+// means content hashes are not available.
+type outfitTracker struct {
+	identity *usecases.OutfitIdentityUseCase
+	report   func(error)
+	renames  []entities.OutfitRename
+	err      error
+}
+
+func newOutfitTracker(identity *usecases.OutfitIdentityUseCase, report func(error)) *outfitTracker {
+	if identity == nil {
+		return nil
+	}
+	return &outfitTracker{identity: identity, report: report}
+}
+
+// relink moves the worn state of renamed and moved outfits, keeping what it
+// found for doctor.
+func (t *outfitTracker) relink() error {
+	if t == nil {
+		return nil
+	}
+	renames, err := t.identity.Relink()
+	t.renames = append(t.renames, renames...)
+	t.err = err
+	return err
+}
+
+// remember records the contents of an outfit that was just worn.
+func (t *outfitTracker) remember(outfit entities.OutfitReference) {
+	if t == nil {
+		return
+	}
+	if err := t.identity.Remember(outfit); err != nil && t.report != nil {
+		t.report(fmt.Errorf("could not record the contents of %s: %w", outfit.FileName, err))
+	}
+}
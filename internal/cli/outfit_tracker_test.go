@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/application/usecases"
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+)
+
+func TestOutfitTracker_ReportsFailuresWithoutStopping(t *testing.T) {
+	configManager := &stubConfigManager{err: errors.New("config unreadable")}
+	var reported []string
+	tracker := newOutfitTracker(usecases.NewOutfitIdentityUseCase(configManager, &stubCacheManager{}, nil, nil), func(err error) {
+		reported = append(reported, err.Error())
+	})
+
+	if err := tracker.relink(); err == nil || tracker.err != err {
+		t.Fatalf("relink() error = %v, kept %v; want the failure kept for doctor", err, tracker.err)
+	}
+	tracker.remember(entities.NewOutfitReference("one.avatar", entities.NewCategoryReference("casual", "/wardrobe/casual")))
+
+	if len(reported) != 1 || !strings.Contains(reported[0], "could not record the contents of one.avatar") {
+		t.Fatalf("reported = %v, want the failed remember reported", reported)
+	}
+}
+
+func TestOutfitTracker_NilWithoutHashes(t *testing.T) {
+	tracker := newOutfitTracker(nil, nil)
+	if tracker != nil {
+		t.Fatalf("newOutfitTracker(nil) = %+v, want nil", tracker)
+	}
+	if err := tracker.relink(); err != nil {
+		t.Fatalf("relink() error = %v", err)
+	}
+	tracker.remember(entities.OutfitReference{})
+
+	app := newTestApplication(nil, &stubConfigManager{}, &stubCacheManager{}, nil)
+	if renames, err := app.OutfitRenames(); renames != nil || err != nil {
+		t.Fatalf("OutfitRenames() = %+v, %v; want nothing without content hashes", renames, err)
+	}
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+)
+
+// ChooseProfile asks which profile to use before the interactive menu starts.
+// It only asks when named profiles exist, and an empty answer keeps the
+// default profile. It returns false when the user quits.
+func ChooseProfile(profiles ProfileManager, console Console) (string, bool) {
+	console = consoleOrDefault(console)
+	available, err := profiles.ListProfiles()
+	if err != nil {
+		console.Warning(fmt.Sprintf("Could not list profiles: %v", err))
+		return entities.DefaultProfile, true
+	}
+	if len(available) < 2 {
+		return entities.DefaultProfile, true
+	}
+
+	SectionWithConsole(console, "Profiles", "👤", uiCyan)
+	for index, profile := range available {
+		console.Printf("  %s %s\n", KeyLabel(strconv.Itoa(index+1)), sanitizeTerminalText(profile.Name))
+	}
+	for {
+		input := console.Prompt("\nChoose a profile (Enter for default): ")
+		if isQuitInput(input) {
+			return "", false
+		}
+		choice := normalizeChoiceInput(input)
+		if choice == "" {
+			return entities.DefaultProfile, true
+		}
+		if index, err := strconv.Atoi(choice); err == nil && index > 0 && index <= len(available) {
+			return available[index-1].Name, true
+		}
+		for _, profile := range available {
+			if normalizeChoiceInput(profile.Name) == choice {
+				return profile.Name, true
+			}
+		}
+		console.Error("Invalid choice")
+	}
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+)
+
+type stubProfileManager struct {
+	active   string
+	profiles []entities.Profile
+	err      error
+	created  []string
+	copied   [][2]string
+	deleted  []string
+}
+
+func newStubProfileManager(names ...string) *stubProfileManager {
+	manager := &stubProfileManager{active: entities.DefaultProfile}
+	for _, name := range append([]string{entities.DefaultProfile}, names...) {
+		manager.profiles = append(manager.profiles, entities.Profile{Name: name, Dir: "/outfitpicker-test/" + name})
+	}
+	return manager
+}
+
+func (s *stubProfileManager) ActiveProfile() string { return s.active }
+
+func (s *stubProfileManager) ListProfiles() ([]entities.Profile, error) {
+	return s.profiles, s.err
+}
+
+func (s *stubProfileManager) CreateProfile(name string) (entities.Profile, error) {
+	if s.err != nil {
+		return entities.Profile{}, s.err
+	}
+	s.created = append(s.created, name)
+	return entities.Profile{Name: name}, nil
+}
+
+func (s *stubProfileManager) CopyProfile(from, to string) (entities.Profile, error) {
+	if s.err != nil {
+		return entities.Profile{}, s.err
+	}
+	s.copied = append(s.copied, [2]string{from, to})
+	return entities.Profile{Name: to}, nil
+}
+
+func (s *stubProfileManager) DeleteProfile(name string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.deleted = append(s.deleted, name)
+	return nil
+}
+
+func TestChooseProfile(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   string
+		wantOK bool
+	}{
+		{name: "enter keeps default", input: "\n", want: entities.DefaultProfile, wantOK: true},
+		{name: "number", input: "3\n", want: "sam", wantOK: true},
+		{name: "name", input: "Alex\n", want: "alex", wantOK: true},
+		{name: "asks again after invalid choice", input: "9\n2\n", want: "alex", wantOK: true},
+		{name: "quit", input: "q\n", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+			console := TerminalConsole{stdin: strings.NewReader(tt.input), stdout: &output, stderr: &output}
+
+			got, ok := ChooseProfile(newStubProfileManager("alex", "sam"), console)
+
+			if got != tt.want || ok != tt.wantOK {
+				t.Fatalf("ChooseProfile() = %q, %t; want %q, %t", got, ok, tt.want, tt.wantOK)
+			}
+			assertOutputContains(t, output.String(), "Profiles", "default", "alex", "sam")
+		})
+	}
+}
+
+func TestChooseProfile_DoesNotAskWithoutNamedProfiles(t *testing.T) {
+	var output bytes.Buffer
+	console := TerminalConsole{stdin: strings.NewReader("q\n"), stdout: &output, stderr: &output}
+
+	if got, ok := ChooseProfile(newStubProfileManager(), console); got != entities.DefaultProfile || !ok {
+		t.Fatalf("ChooseProfile() = %q, %t; want the default profile", got, ok)
+	}
+	manager := newStubProfileManager("alex")
+	manager.err = errors.New("permission denied")
+	if got, ok := ChooseProfile(manager, console); got != entities.DefaultProfile || !ok {
+		t.Fatalf("ChooseProfile() with unreadable profiles = %q, %t; want the default profile", got, ok)
+	}
+	assertOutputContains(t, output.String(), "Could not list profiles: permission denied")
+	if strings.Contains(output.String(), "Choose a profile") {
+		t.Fatalf("output = %q, want no prompt", output.String())
+	}
+}
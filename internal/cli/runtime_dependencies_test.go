@@ -2,6 +2,7 @@ package cli
 
 import (
 	"os"
+	"path/filepath"
 
 	"github.com/dh85/outfitpicker/internal/application/usecases"
 	"github.com/dh85/outfitpicker/internal/domain/entities"
@@ -11,19 +12,58 @@ import (
 )
 
 func newProductionStyleRuntimeDependencies() RuntimeDependencies {
-	configFileService := system.NewFileService[entities.Config](configFileName)
-	cacheFileService := system.NewFileService[entities.OutfitCache](cacheFileName)
-	configRepo := persistence.NewConfigRepository(configFileService)
-	cacheRepo := persistence.NewCacheRepository(cacheFileService)
+	configFileService := system.NewFileService[entities.Config](configFileName,
+		system.WithUpgrader[entities.Config](persistence.ConfigSchema))
+	cacheFileService := system.NewFileService[entities.OutfitCache](cacheFileName,
+		system.WithUpgrader[entities.OutfitCache](persistence.CacheSchema))
+	store := persistence.NewSQLiteStore(func() (string, error) {
+		configPath, err := configFileService.FilePath()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(filepath.Dir(configPath), persistence.SQLiteFileName), nil
+	}, 0)
+	journal := persistence.NewJournal(func() (string, error) {
+		cachePath, err := cacheFileService.FilePath()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(filepath.Dir(cachePath), persistence.JournalFileName), nil
+	}, nil)
+	backups := persistence.NewBackupStore(func() (string, error) {
+		configPath, err := configFileService.FilePath()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(filepath.Dir(configPath), persistence.BackupDirName), nil
+	})
+	storage := persistence.NewStorageSelector(configFileService, cacheFileService, store).WithJournal(journal)
+	configRepo, cacheRepo := storage.Repositories()
+	hashFileService := system.NewFileService[entities.ContentHashIndex]("hashes.json")
+	indexFileService := system.NewFileService[entities.WardrobeIndex]("index.json")
+	knownFileService := system.NewFileService[entities.KnownWardrobe]("known-wardrobe.json")
+	queueFileService := system.NewFileService[entities.WearQueue]("queued-wears.json")
+
+	wardrobe := infraServices.WardrobeSources{
+		system.NewZipSource(),
+		system.NewTarSource(),
+		system.NewDirectorySource(indexFileService),
+	}
 
 	return RuntimeDependencies{
 		ConfigManager: usecases.NewConfigUseCase(configRepo),
 		CacheManager:  usecases.NewCacheUseCase(cacheRepo),
-		CategorySvc:   infraServices.NewCategoryScanner(system.NewDefaultFileManager()),
+		CategorySvc:   infraServices.NewCategoryScanner(wardrobe),
 		PathProvider: FuncStoragePathProvider{
 			ConfigPathFunc: configFileService.FilePath,
 			CachePathFunc:  cacheFileService.FilePath,
 		},
+		Storage:       storage,
+		Journal:       journal,
+		Backups:       backups,
+		Hasher:        system.NewContentHasher(hashFileService),
+		KnownWardrobe: knownFileService,
+		WearQueue:     queueFileService,
 		ConfigExists: func() bool {
 			path, err := configFileService.FilePath()
 			if err != nil {
@@ -1,12 +1,93 @@
 package cli
 
-import "github.com/dh85/outfitpicker/internal/domain/entities"
+import (
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+)
 
 type StoragePathProvider interface {
 	ConfigFilePath() (string, error)
 	CacheFilePath() (string, error)
 }
 
+type StorageSelector interface {
+	SelectStorage(backend entities.StorageBackend) error
+}
+
+// JournalRestorer rebuilds stored state from the journal of changes.
+type JournalRestorer interface {
+	RestoreAt(at time.Time) (entities.JournalState, error)
+	RebuildCache() (entities.OutfitCache, error)
+}
+
+// BackupManager saves config and cache to backup archives and restores them.
+type BackupManager interface {
+	CreateBackup(path string) (entities.BackupInfo, error)
+	ListBackups() ([]entities.BackupInfo, error)
+	RestoreBackup(name string) (entities.BackupInfo, entities.BackupContents, error)
+	PruneBackups(keep int) ([]entities.BackupInfo, error)
+}
+
+// OutfitRenameTracker reports the worn outfits that were found renamed or
+// moved, and kept their worn state, when the wardrobe was loaded.
+type OutfitRenameTracker interface {
+	OutfitRenames() ([]entities.OutfitRename, error)
+}
+
+// WardrobeChangeReporter reports the categories and outfits added to or
+// removed from the wardrobe since the last run.
+type WardrobeChangeReporter interface {
+	WardrobeChanges() (entities.WardrobeChanges, error)
+}
+
+// WearQueue reports whether the wardrobe is offline, in which case wears are
+// queued until it can be read again, and what became of the wears queued
+// before.
+type WearQueue interface {
+	Offline() bool
+	QueuedWears() ([]entities.QueuedWear, error)
+	ClearQueuedWears() ([]entities.QueuedWear, error)
+	AppliedWears() (entities.AppliedWears, error)
+}
+
+// WearHistoryReader answers queries over every recorded wear, which only the
+// sqlite backend keeps.
+type WearHistoryReader interface {
+	WearHistory(category string, limit int) ([]entities.WearRecord, error)
+	WearStats(category string) ([]entities.OutfitWearStats, error)
+}
+
+// CacheReconciler brings the worn outfit cache back in line with the
+// wardrobe.
+type CacheReconciler interface {
+	ReconcileCache() (entities.CacheReconciliation, error)
+}
+
+// ProfileManager creates, lists, copies, and deletes profiles. Profile
+// commands use it without loading any profile.
+type ProfileManager interface {
+	ActiveProfile() string
+	ListProfiles() ([]entities.Profile, error)
+	CreateProfile(name string) (entities.Profile, error)
+	CopyProfile(from, to string) (entities.Profile, error)
+	DeleteProfile(name string) error
+}
+
+// WardrobeInitializer sets up a wardrobe from the command line instead of the
+// interactive first-time setup.
+type WardrobeInitializer interface {
+	Init(root, language string) (*entities.Config, error)
+	InitPortable(root, language string) (config *entities.Config, carried bool, err error)
+}
+
+// SetupRuntime is what the commands that run before a profile is loaded need.
+// Wardrobes opens the selected profile only when init runs.
+type SetupRuntime struct {
+	Profiles  ProfileManager
+	Wardrobes func() (WardrobeInitializer, error)
+}
+
 type WardrobeReader interface {
 	GetCategoryInfo() ([]entities.CategoryInfo, error)
 	GetCategories() ([]entities.CategoryReference, error)
@@ -15,23 +96,47 @@ type WardrobeReader interface {
 	GetAvailableOutfits(category entities.CategoryReference) ([]entities.OutfitReference, error)
 	ShowAllOutfits(categoryName string) ([]entities.OutfitReference, error)
 	GetRootDirectory() (string, error)
+	// GetRootStatuses reports on each wardrobe root separately.
+	GetRootStatuses() ([]entities.RootStatus, error)
 }
 
+// ConfigChange derives the configuration to store from the one currently on
+// disk. It runs while the configuration file is locked, so it must not prompt.
+type ConfigChange func(current *entities.Config) (*entities.Config, error)
+
 type ConfigurationController interface {
 	GetConfiguration() (*entities.Config, error)
-	UpdateConfiguration(config *entities.Config) error
+	UpdateConfiguration(change ConfigChange) error
+	// UpdateConfigurationMigratingHistory carries worn history to a changed
+	// root instead of keeping it for the old one.
+	UpdateConfigurationMigratingHistory(change ConfigChange) error
 }
 
 type OutfitCommandHandler interface {
 	WearOutfit(outfit entities.OutfitReference) error
 	ResetCategory(categoryName string) error
+	// ResetCategoryIfUnchanged resets categoryName unless its worn outfits
+	// have changed since they were read at wornRevision, failing with
+	// ErrStaleRevision if they have.
+	ResetCategoryIfUnchanged(categoryName, wornRevision string) error
 	ResetAllCategories() error
 	FactoryReset() error
 }
 
+type OutfitActivator interface {
+	ActivateOutfit(outfit entities.OutfitReference, slotName string) (entities.ActivationSlot, error)
+	RestoreSlot(slotName string) (entities.ActivationSlot, error)
+}
+
+// RandomOutfitSelector picks outfits for every front end. Each pick runs the
+// pre-pick and post-pick hooks, and a failing pre-pick hook cancels it with
+// ErrPickCancelled.
 type RandomOutfitSelector interface {
 	ShowNextUniqueRandomOutfit() (*entities.OutfitReference, error)
 	ShowNextUniqueRandomOutfitFrom(categoryName string) (*entities.OutfitReference, error)
+	// ShowRandomOutfitIncludingExcluded picks from every category, excluded
+	// ones too, without regard to what the session has shown.
+	ShowRandomOutfitIncludingExcluded() (*entities.OutfitReference, error)
 }
 
 type StaticStoragePathProvider struct {
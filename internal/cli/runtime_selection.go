@@ -2,18 +2,26 @@ package cli
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/dh85/outfitpicker/internal/application/usecases"
 	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
 	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+	"github.com/dh85/outfitpicker/internal/domain/logic"
 )
 
 type RuntimeSelectionService struct {
 	configManager   usecases.ConfigManager
+	cacheManager    usecases.CacheManager
 	categoryInfo    *usecases.GetCategoriesUseCase
 	pickOutfit      *usecases.PickOutfitUseCase
 	session         *OutfitSession
 	randomIndexFunc func(int) int
+	plugins         interfaces.SelectionPluginRunner
+	report          func(error)
+	hooks           *hookDispatcher
+	known           interfaces.KnownWardrobeRepository
 }
 
 func NewRuntimeSelectionService(
@@ -25,6 +33,7 @@ func NewRuntimeSelectionService(
 ) *RuntimeSelectionService {
 	return &RuntimeSelectionService{
 		configManager:   configManager,
+		cacheManager:    cacheManager,
 		categoryInfo:    usecases.NewGetCategoriesUseCase(categoryService, configManager),
 		pickOutfit:      usecases.NewPickOutfitUseCase(categoryService, configManager, cacheManager),
 		session:         session,
@@ -33,6 +42,33 @@ func NewRuntimeSelectionService(
 }
 
 func (s *RuntimeSelectionService) ShowNextUniqueRandomOutfit() (*entities.OutfitReference, error) {
+	return s.pick("", s.nextUniqueRandomOutfit)
+}
+
+func (s *RuntimeSelectionService) ShowNextUniqueRandomOutfitFrom(categoryName string) (*entities.OutfitReference, error) {
+	return s.pick(categoryName, func() (*entities.OutfitReference, error) {
+		return s.nextUniqueRandomOutfitFrom(categoryName)
+	})
+}
+
+func (s *RuntimeSelectionService) ShowRandomOutfitIncludingExcluded() (*entities.OutfitReference, error) {
+	return s.pick("", s.randomOutfitIncludingExcluded)
+}
+
+// pick runs choose between the pre-pick and post-pick hooks. A failing
+// pre-pick hook cancels the pick before anything is chosen.
+func (s *RuntimeSelectionService) pick(categoryName string, choose func() (*entities.OutfitReference, error)) (*entities.OutfitReference, error) {
+	if err := s.hooks.runForCategory(entities.HookEventPrePick, categoryName); err != nil {
+		return nil, fmt.Errorf("%w: %w", domainerrors.ErrPickCancelled, err)
+	}
+	outfit, err := choose()
+	if err == nil && outfit != nil {
+		s.hooks.notifyOutfit(entities.HookEventPostPick, *outfit)
+	}
+	return outfit, err
+}
+
+func (s *RuntimeSelectionService) nextUniqueRandomOutfit() (*entities.OutfitReference, error) {
 	config, err := s.configManager.LoadOrCreate()
 	if err != nil {
 		return nil, err
@@ -68,13 +104,14 @@ func (s *RuntimeSelectionService) ShowNextUniqueRandomOutfit() (*entities.Outfit
 		s.session.ResetGlobal()
 		available = allAvailable
 	}
+	available = s.newArrivalsFirst(config, available)
 
-	selected := available[s.randomIndex(len(available))]
+	selected := available[s.chooseIndex("", available, s.session.GlobalShownKeys())]
 	s.session.MarkGlobalShown(outfitKey(selected))
 	return &selected, nil
 }
 
-func (s *RuntimeSelectionService) ShowNextUniqueRandomOutfitFrom(categoryName string) (*entities.OutfitReference, error) {
+func (s *RuntimeSelectionService) nextUniqueRandomOutfitFrom(categoryName string) (*entities.OutfitReference, error) {
 	available, err := s.pickOutfit.LoadAvailableOutfits(categoryName)
 	if err != nil {
 		return nil, err
@@ -88,12 +125,83 @@ func (s *RuntimeSelectionService) ShowNextUniqueRandomOutfitFrom(categoryName st
 		s.session.ResetCategory(categoryName)
 		unseen = available
 	}
+	if config, err := s.configManager.LoadOrCreate(); err == nil {
+		unseen = s.newArrivalsFirst(config, unseen)
+	}
 
-	selected := unseen[s.randomIndex(len(unseen))]
-	s.session.MarkCategoryShown(selected.FileName, categoryName)
+	selected := unseen[s.chooseIndex(categoryName, unseen, s.session.CategoryShownKeys(categoryName))]
+	s.session.MarkCategoryShown(selected.Key(), categoryName)
 	return &selected, nil
 }
 
+// newArrivalsFirst narrows outfits to the new arrivals among them when config
+// prefers them. Without a readable recorded wardrobe there are none.
+func (s *RuntimeSelectionService) newArrivalsFirst(config *entities.Config, outfits []entities.OutfitReference) []entities.OutfitReference {
+	if config == nil || !config.PreferNewArrivals || s.known == nil {
+		return outfits
+	}
+	known, err := s.known.Load()
+	if err != nil || known == nil {
+		return outfits
+	}
+	return logic.NewArrivalsFirst(outfits, *known)
+}
+
+func (s *RuntimeSelectionService) randomOutfitIncludingExcluded() (*entities.OutfitReference, error) {
+	infos, err := s.categoryInfo.Execute()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Category.Name < infos[j].Category.Name
+	})
+	var available []entities.OutfitReference
+	for _, info := range infos {
+		if info.State != entities.CategoryStateHasOutfits && info.State != entities.CategoryStateUserExcluded {
+			continue
+		}
+		outfits, err := s.pickOutfit.LoadAvailableOutfits(info.Category.Name)
+		if err != nil {
+			return nil, err
+		}
+		available = append(available, outfits...)
+	}
+	if len(available) == 0 {
+		return nil, nil
+	}
+	return &available[s.randomIndex(len(available))], nil
+}
+
+// chooseIndex asks the configured selection plugin to pick one of candidates,
+// falling back to uniform random selection when no plugin is configured or
+// the plugin fails.
+func (s *RuntimeSelectionService) chooseIndex(categoryName string, candidates []entities.OutfitReference, shown []string) int {
+	if len(candidates) <= 1 || s.plugins == nil {
+		return s.randomIndex(len(candidates))
+	}
+	config, err := s.configManager.LoadOrCreate()
+	if err != nil || config == nil || config.SelectionPlugin == nil {
+		return s.randomIndex(len(candidates))
+	}
+	cache, err := s.cacheManager.LoadOrCreate()
+	if err != nil {
+		cache = nil
+	}
+
+	request := entities.NewSelectionRequest(categoryName, candidates, cache, shown)
+	response, err := s.plugins.Select(*config.SelectionPlugin, request)
+	if err == nil {
+		var index int
+		if index, err = response.Choose(request); err == nil {
+			return index
+		}
+	}
+	if s.report != nil {
+		s.report(fmt.Errorf("%w; picking at random instead", err))
+	}
+	return s.randomIndex(len(candidates))
+}
+
 func (s *RuntimeSelectionService) randomIndex(length int) int {
 	if length <= 1 {
 		return 0
@@ -102,7 +210,7 @@ func (s *RuntimeSelectionService) randomIndex(length int) int {
 }
 
 func outfitKey(outfit entities.OutfitReference) string {
-	return fmt.Sprintf("%s/%s", outfit.Category.Name, outfit.FileName)
+	return fmt.Sprintf("%s/%s", outfit.Category.Name, outfit.Key())
 }
 
 func filterUnseenOutfits(outfits []entities.OutfitReference, session *OutfitSession) []entities.OutfitReference {
@@ -118,7 +226,7 @@ func filterUnseenOutfits(outfits []entities.OutfitReference, session *OutfitSess
 func filterCategoryUnseenOutfits(outfits []entities.OutfitReference, category string, session *OutfitSession) []entities.OutfitReference {
 	result := make([]entities.OutfitReference, 0, len(outfits))
 	for _, outfit := range outfits {
-		if !session.IsCategoryShown(outfit.FileName, category) {
+		if !session.IsCategoryShown(outfit.Key(), category) {
 			result = append(result, outfit)
 		}
 	}
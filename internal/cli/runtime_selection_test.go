@@ -2,13 +2,14 @@ package cli
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/dh85/outfitpicker/internal/domain/entities"
 )
 
 func TestRuntimeSelectionService_ShowNextUniqueRandomOutfit_SkipsExcludedCategories(t *testing.T) {
-	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), map[string]bool{"formal": true}, nil, nil)
+	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), map[string]bool{"formal": true})
 	categorySvc := &stubCategoryService{
 		scanCategoriesResult: []entities.CategoryInfo{
 			entities.NewCategoryInfo(entities.NewCategoryReference("casual", "/root/outfits/casual"), entities.CategoryStateHasOutfits, 1),
@@ -39,8 +40,44 @@ func TestRuntimeSelectionService_ShowNextUniqueRandomOutfit_SkipsExcludedCategor
 	}
 }
 
+func TestRuntimeSelectionService_PrefersNewArrivals(t *testing.T) {
+	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
+	categorySvc := &stubCategoryService{
+		scanCategoriesResult: []entities.CategoryInfo{
+			entities.NewCategoryInfo(entities.NewCategoryReference("casual", cliTestCategoryPath("casual")), entities.CategoryStateHasOutfits, 3),
+		},
+		outfitsByPath: map[string][]entities.FileEntry{
+			cliTestCategoryPath("casual"): {{FileName: "a.avatar"}, {FileName: "b.avatar"}, {FileName: "new.avatar"}},
+		},
+	}
+	configManager := &stubConfigManager{config: config}
+	selector := NewRuntimeSelectionService(categorySvc, configManager, &stubCacheManager{cache: newOutfitCachePtr()}, NewOutfitSession(), func(int) int { return 0 })
+	recorded := entities.NewKnownWardrobe(nil, map[string]map[string]bool{"casual": {"new.avatar": true}})
+	selector.known = &stubKnownWardrobe{known: &recorded}
+
+	first, err := selector.ShowNextUniqueRandomOutfitFrom("casual")
+	if err != nil || first == nil || first.FileName != "a.avatar" {
+		t.Fatalf("ShowNextUniqueRandomOutfitFrom() = %v, %v; want a.avatar while new arrivals are mixed in", first, err)
+	}
+
+	configManager.config = config.WithNewArrivalsPreferred(true)
+	for _, pick := range []func() (*entities.OutfitReference, error){
+		func() (*entities.OutfitReference, error) { return selector.ShowNextUniqueRandomOutfitFrom("casual") },
+		selector.ShowNextUniqueRandomOutfit,
+	} {
+		outfit, err := pick()
+		if err != nil || outfit == nil || outfit.FileName != "new.avatar" {
+			t.Fatalf("pick = %v, %v; want the new arrival first", outfit, err)
+		}
+	}
+	next, err := selector.ShowNextUniqueRandomOutfit()
+	if err != nil || next == nil || next.FileName != "a.avatar" {
+		t.Fatalf("pick after the new arrival was shown = %v, %v; want a.avatar", next, err)
+	}
+}
+
 func TestRuntimeSelectionService_ShowNextUniqueRandomOutfitFrom_ResetsShownSession(t *testing.T) {
-	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil, nil, nil)
+	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
 	categorySvc := &stubCategoryService{
 		outfitsByPath: map[string][]entities.FileEntry{
 			cliTestCategoryPath("casual"): {
@@ -97,3 +134,160 @@ func TestRuntimeSelectionService_ShowNextUniqueRandomOutfit_PropagatesSelectionE
 		t.Fatalf("ShowNextUniqueRandomOutfit() error = %v, want %v", err, wantErr)
 	}
 }
+
+type stubSelectionPlugins struct {
+	response entities.SelectionResponse
+	err      error
+	requests []entities.SelectionRequest
+}
+
+func (s *stubSelectionPlugins) Select(_ entities.SelectionPlugin, request entities.SelectionRequest) (entities.SelectionResponse, error) {
+	s.requests = append(s.requests, request)
+	return s.response, s.err
+}
+
+func newPluginSelectionService(t *testing.T, plugins *stubSelectionPlugins, reported *[]error) (*RuntimeSelectionService, *OutfitSession) {
+	t.Helper()
+	config, err := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), nil)
+	if err != nil {
+		t.Fatalf("NewConfig() error = %v", err)
+	}
+	config = config.WithSelectionPlugin(&entities.SelectionPlugin{Command: "ranker"})
+	cache := entities.NewOutfitCache()
+	cache.Categories["casual"] = entities.CategoryCache{WornOutfits: map[string]bool{"worn.avatar": true}, TotalOutfits: 4}
+	categorySvc := &stubCategoryService{
+		scanCategoriesResult: []entities.CategoryInfo{
+			entities.NewCategoryInfo(entities.NewCategoryReference("casual", cliTestCategoryPath("casual")), entities.CategoryStateHasOutfits, 4),
+		},
+		outfitsByPath: map[string][]entities.FileEntry{
+			cliTestCategoryPath("casual"): {{FileName: "a.avatar"}, {FileName: "b.avatar"}, {FileName: "c.avatar"}, {FileName: "worn.avatar"}},
+		},
+	}
+	session := NewOutfitSession()
+	selector := NewRuntimeSelectionService(categorySvc, &stubConfigManager{config: config}, &stubCacheManager{cache: &cache}, session, func(int) int { return 0 })
+	selector.plugins = plugins
+	selector.report = func(err error) { *reported = append(*reported, err) }
+	return selector, session
+}
+
+func TestRuntimeSelectionService_SelectionPlugin(t *testing.T) {
+	t.Run("uses the plugin ranking", func(t *testing.T) {
+		plugins := &stubSelectionPlugins{response: entities.SelectionResponse{ProtocolVersion: entities.SelectionProtocolVersion, Ranking: []string{"casual/c.avatar", "casual/a.avatar"}}}
+		var reported []error
+		selector, session := newPluginSelectionService(t, plugins, &reported)
+		session.MarkGlobalShown("casual/b.avatar")
+
+		outfit, err := selector.ShowNextUniqueRandomOutfit()
+		if err != nil || outfit == nil || outfit.FileName != "c.avatar" {
+			t.Fatalf("ShowNextUniqueRandomOutfit() = %v, %v, want c.avatar", outfit, err)
+		}
+		if len(reported) != 0 {
+			t.Fatalf("reported = %v, want none", reported)
+		}
+		request := plugins.requests[0]
+		if request.Category != "" || len(request.Candidates) != 2 || request.Candidates[0].ID != "casual/a.avatar" {
+			t.Fatalf("candidates = %#v, want unseen unworn outfits", request.Candidates)
+		}
+		if len(request.SessionShown) != 1 || request.SessionShown[0] != "casual/b.avatar" {
+			t.Fatalf("SessionShown = %v", request.SessionShown)
+		}
+		if worn := request.WornOutfits["casual"]; len(worn) != 1 || worn[0] != "worn.avatar" {
+			t.Fatalf("WornOutfits = %v", request.WornOutfits)
+		}
+	})
+
+	t.Run("category picks send the category", func(t *testing.T) {
+		plugins := &stubSelectionPlugins{response: entities.SelectionResponse{ProtocolVersion: entities.SelectionProtocolVersion, Ranking: []string{"casual/b.avatar"}}}
+		var reported []error
+		selector, session := newPluginSelectionService(t, plugins, &reported)
+		session.MarkCategoryShown("a.avatar", "casual")
+
+		outfit, err := selector.ShowNextUniqueRandomOutfitFrom("casual")
+		if err != nil || outfit == nil || outfit.FileName != "b.avatar" {
+			t.Fatalf("ShowNextUniqueRandomOutfitFrom() = %v, %v, want b.avatar", outfit, err)
+		}
+		request := plugins.requests[0]
+		if request.Category != "casual" || len(request.SessionShown) != 1 || request.SessionShown[0] != "casual/a.avatar" {
+			t.Fatalf("request = %#v", request)
+		}
+	})
+
+	t.Run("falls back to random", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			plugins *stubSelectionPlugins
+			want    string
+		}{
+			{name: "plugin error", plugins: &stubSelectionPlugins{err: errors.New(`selection plugin "ranker" failed: exit status 1`)}, want: "exit status 1; picking at random instead"},
+			{name: "bad version", plugins: &stubSelectionPlugins{response: entities.SelectionResponse{ProtocolVersion: 99, Ranking: []string{"casual/b.avatar"}}}, want: "unsupported protocol version"},
+			{name: "unknown ranking", plugins: &stubSelectionPlugins{response: entities.SelectionResponse{ProtocolVersion: entities.SelectionProtocolVersion, Ranking: []string{"hats/cap.avatar"}}}, want: "did not rank any candidate"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var reported []error
+				selector, _ := newPluginSelectionService(t, tt.plugins, &reported)
+
+				outfit, err := selector.ShowNextUniqueRandomOutfitFrom("casual")
+				if err != nil || outfit == nil || outfit.FileName != "a.avatar" {
+					t.Fatalf("ShowNextUniqueRandomOutfitFrom() = %v, %v, want random index 0", outfit, err)
+				}
+				if len(reported) != 1 || !strings.Contains(reported[0].Error(), tt.want) {
+					t.Fatalf("reported = %v, want %q", reported, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("skips the plugin when it has nothing to rank or is not configured", func(t *testing.T) {
+		plugins := &stubSelectionPlugins{}
+		var reported []error
+		selector, session := newPluginSelectionService(t, plugins, &reported)
+		session.MarkCategoryShown("a.avatar", "casual")
+		session.MarkCategoryShown("b.avatar", "casual")
+
+		if outfit, err := selector.ShowNextUniqueRandomOutfitFrom("casual"); err != nil || outfit == nil || outfit.FileName != "c.avatar" {
+			t.Fatalf("ShowNextUniqueRandomOutfitFrom() = %v, %v, want c.avatar", outfit, err)
+		}
+		selector.configManager = &stubConfigManager{config: mustTestConfig(t, cliTestOutfitRoot, nil)}
+		if _, err := selector.ShowNextUniqueRandomOutfit(); err != nil {
+			t.Fatalf("ShowNextUniqueRandomOutfit() error = %v", err)
+		}
+		if len(plugins.requests) != 0 {
+			t.Fatalf("plugin requests = %d, want none", len(plugins.requests))
+		}
+	})
+}
+
+func TestRuntimeSelectionService_ShowRandomOutfitIncludingExcluded(t *testing.T) {
+	config, _ := entities.NewConfig(cliTestOutfitRoot, stringPtr("en"), map[string]bool{"formal": true})
+	categorySvc := &stubCategoryService{
+		scanCategoriesResult: []entities.CategoryInfo{
+			entities.NewCategoryInfo(entities.NewCategoryReference("formal", cliTestCategoryPath("formal")), entities.CategoryStateHasOutfits, 1),
+			entities.NewCategoryInfo(entities.NewCategoryReference("casual", cliTestCategoryPath("casual")), entities.CategoryStateHasOutfits, 1),
+			entities.NewCategoryInfo(entities.NewCategoryReference("empty", cliTestCategoryPath("empty")), entities.CategoryStateEmpty, 0),
+		},
+		outfitsByPath: map[string][]entities.FileEntry{
+			cliTestCategoryPath("casual"): {{FileName: "casual.avatar"}},
+			cliTestCategoryPath("formal"): {{FileName: "formal.avatar"}},
+		},
+	}
+	var lengths []int
+	selector := NewRuntimeSelectionService(
+		categorySvc,
+		&stubConfigManager{config: config},
+		&stubCacheManager{cache: newOutfitCachePtr()},
+		NewOutfitSession(),
+		func(length int) int {
+			lengths = append(lengths, length)
+			return 1
+		},
+	)
+
+	outfit, err := selector.ShowRandomOutfitIncludingExcluded()
+	if err != nil || outfit == nil {
+		t.Fatalf("ShowRandomOutfitIncludingExcluded() = %v, %v", outfit, err)
+	}
+	if outfit.Category.Name != "formal" || len(lengths) != 1 || lengths[0] != 2 {
+		t.Fatalf("picked %s from %v candidates, want formal from 2", outfit.Category.Name, lengths)
+	}
+}
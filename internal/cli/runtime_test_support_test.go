@@ -1,9 +1,11 @@
 package cli
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/dh85/outfitpicker/internal/domain/entities"
 )
@@ -65,6 +67,7 @@ type stubWardrobeReader struct {
 	showAllOutfitsErr      error
 	rootDirectory          string
 	rootErr                error
+	rootStatuses           []entities.RootStatus
 }
 
 func newStubWardrobeReader() *stubWardrobeReader {
@@ -129,18 +132,41 @@ func (s *stubWardrobeReader) GetRootDirectory() (string, error) {
 	return s.rootDirectory, s.rootErr
 }
 
+func (s *stubWardrobeReader) GetRootStatuses() ([]entities.RootStatus, error) {
+	return s.rootStatuses, s.rootErr
+}
+
 type stubConfigurationController struct {
 	currentConfig  *entities.Config
 	loadErr        error
 	updateErr      error
 	updatedConfigs []*entities.Config
+	// savedElsewhere, when set, replaces currentConfig just before the next
+	// update, as if another session had saved in the meantime.
+	savedElsewhere *entities.Config
+	migrations     int
 }
 
 func (s *stubConfigurationController) GetConfiguration() (*entities.Config, error) {
 	return s.currentConfig, s.loadErr
 }
 
-func (s *stubConfigurationController) UpdateConfiguration(config *entities.Config) error {
+func (s *stubConfigurationController) UpdateConfigurationMigratingHistory(change ConfigChange) error {
+	s.migrations++
+	return s.UpdateConfiguration(change)
+}
+
+func (s *stubConfigurationController) UpdateConfiguration(change ConfigChange) error {
+	if s.loadErr != nil {
+		return s.loadErr
+	}
+	if s.savedElsewhere != nil {
+		s.currentConfig, s.savedElsewhere = s.savedElsewhere, nil
+	}
+	config, err := change(s.currentConfig)
+	if err != nil {
+		return err
+	}
 	s.updatedConfigs = append(s.updatedConfigs, config)
 	if s.updateErr == nil {
 		s.currentConfig = config
@@ -153,6 +179,7 @@ type stubCommandHandler struct {
 	wearCalls          []entities.OutfitReference
 	resetCategoryErr   error
 	resetCategoryCalls []string
+	resetRevisions     []string
 	resetAllErr        error
 	resetAllCalls      int
 	factoryResetErr    error
@@ -169,6 +196,11 @@ func (s *stubCommandHandler) ResetCategory(categoryName string) error {
 	return s.resetCategoryErr
 }
 
+func (s *stubCommandHandler) ResetCategoryIfUnchanged(categoryName, wornRevision string) error {
+	s.resetRevisions = append(s.resetRevisions, wornRevision)
+	return s.ResetCategory(categoryName)
+}
+
 func (s *stubCommandHandler) ResetAllCategories() error {
 	s.resetAllCalls++
 	return s.resetAllErr
@@ -179,11 +211,33 @@ func (s *stubCommandHandler) FactoryReset() error {
 	return s.factoryResetErr
 }
 
+type stubOutfitActivator struct {
+	slot          entities.ActivationSlot
+	activateErr   error
+	activateCalls []entities.OutfitReference
+	activateSlots []string
+	restoreErr    error
+	restoreCalls  []string
+}
+
+func (s *stubOutfitActivator) ActivateOutfit(outfit entities.OutfitReference, slotName string) (entities.ActivationSlot, error) {
+	s.activateCalls = append(s.activateCalls, outfit)
+	s.activateSlots = append(s.activateSlots, slotName)
+	return s.slot, s.activateErr
+}
+
+func (s *stubOutfitActivator) RestoreSlot(slotName string) (entities.ActivationSlot, error) {
+	s.restoreCalls = append(s.restoreCalls, slotName)
+	return s.slot, s.restoreErr
+}
+
 type stubRandomOutfitSelector struct {
 	globalResults   []stubSelectorResult
 	globalCalls     int
 	categoryResults []stubSelectorResult
 	categoryCalls   int
+	excludedResult  stubSelectorResult
+	excludedCalls   int
 }
 
 func (s *stubRandomOutfitSelector) ShowNextUniqueRandomOutfit() (*entities.OutfitReference, error) {
@@ -204,20 +258,63 @@ func (s *stubRandomOutfitSelector) ShowNextUniqueRandomOutfitFrom(categoryName s
 	return result.outfit, result.err
 }
 
+func (s *stubRandomOutfitSelector) ShowRandomOutfitIncludingExcluded() (*entities.OutfitReference, error) {
+	s.excludedCalls++
+	return s.excludedResult.outfit, s.excludedResult.err
+}
+
 type stubRuntime struct {
 	wardrobe     *stubWardrobeReader
 	config       *stubConfigurationController
 	commands     *stubCommandHandler
 	random       *stubRandomOutfitSelector
+	activator    *stubOutfitActivator
 	pathProvider StoragePathProvider
+
+	selectedStorage  []entities.StorageBackend
+	selectStorageErr error
+
+	restoredAt      []time.Time
+	restoreState    entities.JournalState
+	restoreErr      error
+	rebuiltCache    entities.OutfitCache
+	rebuildCacheErr error
+	rebuildCalls    int
+
+	createdBackups   []string
+	backups          []entities.BackupInfo
+	backupErr        error
+	restoredBackups  []string
+	restoredContents entities.BackupContents
+	prunedKeep       []int
+
+	renames   []entities.OutfitRename
+	renameErr error
+
+	reconciliation entities.CacheReconciliation
+	reconcileErr   error
+
+	changes    entities.WardrobeChanges
+	changesErr error
+
+	offline  bool
+	queued   []entities.QueuedWear
+	applied  entities.AppliedWears
+	queueErr error
+
+	wears        []entities.WearRecord
+	wearStats    []entities.OutfitWearStats
+	historyErr   error
+	historyCalls []string
 }
 
 func newStubRuntime() *stubRuntime {
 	return &stubRuntime{
-		wardrobe: newStubWardrobeReader(),
-		config:   &stubConfigurationController{},
-		commands: &stubCommandHandler{},
-		random:   &stubRandomOutfitSelector{},
+		wardrobe:  newStubWardrobeReader(),
+		config:    &stubConfigurationController{},
+		commands:  &stubCommandHandler{},
+		random:    &stubRandomOutfitSelector{},
+		activator: &stubOutfitActivator{},
 		pathProvider: StaticStoragePathProvider{
 			ConfigPath: "/outfitpicker-test/config.json",
 			CachePath:  "/outfitpicker-test/cache.json",
@@ -257,6 +354,10 @@ func (s *stubRuntime) GetRootDirectory() (string, error) {
 	return s.wardrobe.GetRootDirectory()
 }
 
+func (s *stubRuntime) GetRootStatuses() ([]entities.RootStatus, error) {
+	return s.wardrobe.GetRootStatuses()
+}
+
 func (s *stubRuntime) GetConfiguration() (*entities.Config, error) {
 	return s.config.GetConfiguration()
 }
@@ -269,8 +370,104 @@ func (s *stubRuntime) CacheFilePath() (string, error) {
 	return s.pathProvider.CacheFilePath()
 }
 
-func (s *stubRuntime) UpdateConfiguration(config *entities.Config) error {
-	return s.config.UpdateConfiguration(config)
+func (s *stubRuntime) SelectStorage(backend entities.StorageBackend) error {
+	s.selectedStorage = append(s.selectedStorage, backend)
+	return s.selectStorageErr
+}
+
+func (s *stubRuntime) RestoreAt(at time.Time) (entities.JournalState, error) {
+	s.restoredAt = append(s.restoredAt, at)
+	return s.restoreState, s.restoreErr
+}
+
+func (s *stubRuntime) RebuildCache() (entities.OutfitCache, error) {
+	s.rebuildCalls++
+	return s.rebuiltCache, s.rebuildCacheErr
+}
+
+func (s *stubRuntime) CreateBackup(path string) (entities.BackupInfo, error) {
+	s.createdBackups = append(s.createdBackups, path)
+	if s.backupErr != nil {
+		return entities.BackupInfo{}, s.backupErr
+	}
+	if path == "" {
+		path = "/outfitpicker-test/backups/backup-1.tar.gz"
+	}
+	return entities.BackupInfo{Name: "backup-1", Path: path, Kind: entities.BackupManual}, nil
+}
+
+func (s *stubRuntime) ListBackups() ([]entities.BackupInfo, error) {
+	return s.backups, s.backupErr
+}
+
+func (s *stubRuntime) RestoreBackup(name string) (entities.BackupInfo, entities.BackupContents, error) {
+	s.restoredBackups = append(s.restoredBackups, name)
+	if s.backupErr != nil {
+		return entities.BackupInfo{}, entities.BackupContents{}, s.backupErr
+	}
+	return entities.BackupInfo{Name: name}, s.restoredContents, nil
+}
+
+func (s *stubRuntime) PruneBackups(keep int) ([]entities.BackupInfo, error) {
+	s.prunedKeep = append(s.prunedKeep, keep)
+	if s.backupErr != nil {
+		return nil, s.backupErr
+	}
+	if keep >= len(s.backups) {
+		return nil, nil
+	}
+	return s.backups[keep:], nil
+}
+
+func (s *stubRuntime) WardrobeChanges() (entities.WardrobeChanges, error) {
+	return s.changes, s.changesErr
+}
+
+func (s *stubRuntime) Offline() bool {
+	return s.offline
+}
+
+func (s *stubRuntime) QueuedWears() ([]entities.QueuedWear, error) {
+	return s.queued, s.queueErr
+}
+
+func (s *stubRuntime) WearHistory(category string, limit int) ([]entities.WearRecord, error) {
+	s.historyCalls = append(s.historyCalls, fmt.Sprintf("history %s %d", category, limit))
+	return s.wears, s.historyErr
+}
+
+func (s *stubRuntime) WearStats(category string) ([]entities.OutfitWearStats, error) {
+	s.historyCalls = append(s.historyCalls, "stats "+category)
+	return s.wearStats, s.historyErr
+}
+
+func (s *stubRuntime) ClearQueuedWears() ([]entities.QueuedWear, error) {
+	if s.queueErr != nil {
+		return nil, s.queueErr
+	}
+	cleared := s.queued
+	s.queued = nil
+	return cleared, nil
+}
+
+func (s *stubRuntime) AppliedWears() (entities.AppliedWears, error) {
+	return s.applied, s.queueErr
+}
+
+func (s *stubRuntime) ReconcileCache() (entities.CacheReconciliation, error) {
+	return s.reconciliation, s.reconcileErr
+}
+
+func (s *stubRuntime) OutfitRenames() ([]entities.OutfitRename, error) {
+	return s.renames, s.renameErr
+}
+
+func (s *stubRuntime) UpdateConfiguration(change ConfigChange) error {
+	return s.config.UpdateConfiguration(change)
+}
+
+func (s *stubRuntime) UpdateConfigurationMigratingHistory(change ConfigChange) error {
+	return s.config.UpdateConfigurationMigratingHistory(change)
 }
 
 func (s *stubRuntime) WearOutfit(outfit entities.OutfitReference) error {
@@ -281,6 +478,10 @@ func (s *stubRuntime) ResetCategory(categoryName string) error {
 	return s.commands.ResetCategory(categoryName)
 }
 
+func (s *stubRuntime) ResetCategoryIfUnchanged(categoryName, wornRevision string) error {
+	return s.commands.ResetCategoryIfUnchanged(categoryName, wornRevision)
+}
+
 func (s *stubRuntime) ResetAllCategories() error {
 	return s.commands.ResetAllCategories()
 }
@@ -296,3 +497,15 @@ func (s *stubRuntime) ShowNextUniqueRandomOutfit() (*entities.OutfitReference, e
 func (s *stubRuntime) ShowNextUniqueRandomOutfitFrom(categoryName string) (*entities.OutfitReference, error) {
 	return s.random.ShowNextUniqueRandomOutfitFrom(categoryName)
 }
+
+func (s *stubRuntime) ActivateOutfit(outfit entities.OutfitReference, slotName string) (entities.ActivationSlot, error) {
+	return s.activator.ActivateOutfit(outfit, slotName)
+}
+
+func (s *stubRuntime) RestoreSlot(slotName string) (entities.ActivationSlot, error) {
+	return s.activator.RestoreSlot(slotName)
+}
+
+func (s *stubRuntime) ShowRandomOutfitIncludingExcluded() (*entities.OutfitReference, error) {
+	return s.random.ShowRandomOutfitIncludingExcluded()
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dh85/outfitpicker/internal/application/usecases"
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+// snapshotter saves config and cache before a destructive change. A nil
+// snapshotter means backups are disabled.
+type snapshotter struct {
+	backups *usecases.BackupUseCase
+	report  func(error)
+}
+
+func newSnapshotter(backups *usecases.BackupUseCase, report func(error)) *snapshotter {
+	if backups == nil {
+		return nil
+	}
+	return &snapshotter{backups: backups, report: report}
+}
+
+// take snapshots the stored state, or config with the stored cache when
+// config is not nil. A snapshot that cannot be saved is returned so that the
+// change it guards is not made; one saved before older snapshots failed to be
+// pruned is only reported. There is nothing to save before anything is
+// configured.
+func (s *snapshotter) take(reason string, config *entities.Config) error {
+	if s == nil {
+		return nil
+	}
+	snapshot, err := s.backups.Snapshot(reason, config)
+	if err == nil || errors.Is(err, domainerrors.ErrConfigurationNotFound) {
+		return nil
+	}
+	err = fmt.Errorf("could not take a %s snapshot: %w", reason, err)
+	if snapshot.Name == "" {
+		return err
+	}
+	if s.report != nil {
+		s.report(err)
+	}
+	return nil
+}
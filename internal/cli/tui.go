@@ -0,0 +1,540 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+type tuiPane int
+
+const (
+	tuiPaneCategories tuiPane = iota
+	tuiPaneOutfits
+)
+
+const (
+	tuiRotationBarWidth = 10
+	tuiMinCategoryWidth = 24
+	tuiMaxCategoryWidth = 40
+	tuiChromeHeight     = 6
+)
+
+type tuiCategory struct {
+	info    entities.CategoryInfo
+	state   entities.CategoryOutfitState
+	outfits []entities.OutfitReference
+	worn    map[string]bool
+}
+
+// TUI is the full-screen alternative to MenuSystem. It drives the same
+// OutfitService and RandomOutfitSelector so selection and wear behaviour
+// match the prompt-based menus.
+type TUI struct {
+	outfitService OutfitService
+	selector      RandomOutfitSelector
+	screen        tuiScreen
+
+	rootPath      string
+	categories    []tuiCategory
+	focus         tuiPane
+	categoryIndex int
+	outfitIndex   int
+	picked        *entities.OutfitReference
+	status        string
+	statusColor   string
+	confirmReset  bool
+}
+
+func newTUI(outfitService OutfitService, selector RandomOutfitSelector, screen tuiScreen) *TUI {
+	return &TUI{outfitService: outfitService, selector: selector, screen: screen}
+}
+
+// RunTUI runs the full-screen interface on the process terminal until the
+// user quits.
+func RunTUI(outfitService OutfitService, selector RandomOutfitSelector) error {
+	screen, err := openTerminalScreen(os.Stdin, os.Stdout)
+	if err != nil {
+		return err
+	}
+	defer screen.Close()
+	return newTUI(outfitService, selector, screen).Run()
+}
+
+// Run draws the interface and processes keys until the user quits or input
+// ends.
+func (t *TUI) Run() error {
+	t.reload()
+	for {
+		if err := t.screen.Draw(t.render()); err != nil {
+			return err
+		}
+		key, err := t.screen.ReadKey()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if !t.handleKey(key) {
+			return nil
+		}
+	}
+}
+
+func (t *TUI) reload() {
+	selectedName := ""
+	if category := t.currentCategory(); category != nil {
+		selectedName = category.info.Category.Name
+	}
+
+	if rootPath, err := t.outfitService.GetRootDirectory(); err == nil {
+		t.rootPath = rootPath
+	}
+
+	infos, err := t.outfitService.GetCategoryInfo()
+	if err != nil {
+		t.categories = nil
+		t.setError(fmt.Sprintf("Could not load categories: %v", err))
+		return
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Category.Name < infos[j].Category.Name
+	})
+
+	categories := make([]tuiCategory, 0, len(infos))
+	for _, info := range infos {
+		category := tuiCategory{info: info, worn: map[string]bool{}}
+		if info.State == entities.CategoryStateHasOutfits || info.State == entities.CategoryStateUserExcluded {
+			state, err := t.outfitService.GetOutfitState(info.Category)
+			if err == nil {
+				category.state = state
+				category.outfits = sortedOutfits(state.AllOutfits)
+				category.worn = currentCategoryWornFileNames(state)
+			}
+		}
+		categories = append(categories, category)
+	}
+	t.categories = categories
+
+	t.categoryIndex = 0
+	for index, category := range categories {
+		if category.info.Category.Name == selectedName {
+			t.categoryIndex = index
+			break
+		}
+	}
+	t.clampOutfitIndex()
+}
+
+func (t *TUI) handleKey(key tuiKey) bool {
+	if t.confirmReset {
+		t.confirmReset = false
+		if key.kind == tuiKeyRune && (key.char == 'y' || key.char == 'Y') {
+			t.resetCurrentCategory()
+		} else {
+			t.setInfo("Reset cancelled")
+		}
+		return true
+	}
+
+	switch key.kind {
+	case tuiKeyInterrupt, tuiKeyEscape:
+		return false
+	case tuiKeyUp:
+		t.move(-1)
+	case tuiKeyDown:
+		t.move(1)
+	case tuiKeyLeft:
+		t.focus = tuiPaneCategories
+	case tuiKeyRight:
+		t.focus = tuiPaneOutfits
+	case tuiKeyTab:
+		t.toggleFocus()
+	case tuiKeyEnter:
+		if t.focus == tuiPaneCategories {
+			t.focus = tuiPaneOutfits
+		} else {
+			t.wearSelected()
+		}
+	case tuiKeyRune:
+		return t.handleRune(key.char)
+	}
+	return true
+}
+
+func (t *TUI) handleRune(char rune) bool {
+	switch char {
+	case 'q', 'Q':
+		return false
+	case 'k':
+		t.move(-1)
+	case 'j':
+		t.move(1)
+	case 'h':
+		t.focus = tuiPaneCategories
+	case 'l':
+		t.focus = tuiPaneOutfits
+	case 'r', 'R':
+		t.pickRandom()
+	case 'p', 'P':
+		t.pickFromCategory()
+	case 'w', 'W':
+		t.wearSelected()
+	case 'x', 'X':
+		if category := t.currentCategory(); category != nil {
+			t.confirmReset = true
+			t.setWarning(fmt.Sprintf("Reset worn outfits for %s? (y/n)", category.info.Category.Name))
+		}
+	}
+	return true
+}
+
+func (t *TUI) toggleFocus() {
+	if t.focus == tuiPaneCategories {
+		t.focus = tuiPaneOutfits
+		return
+	}
+	t.focus = tuiPaneCategories
+}
+
+func (t *TUI) move(delta int) {
+	if t.focus == tuiPaneCategories {
+		if len(t.categories) == 0 {
+			return
+		}
+		t.categoryIndex = clampIndex(t.categoryIndex+delta, len(t.categories))
+		t.outfitIndex = 0
+		return
+	}
+	category := t.currentCategory()
+	if category == nil || len(category.outfits) == 0 {
+		return
+	}
+	t.outfitIndex = clampIndex(t.outfitIndex+delta, len(category.outfits))
+}
+
+func (t *TUI) pickRandom() {
+	outfit, err := t.selector.ShowNextUniqueRandomOutfit()
+	t.showPick(outfit, err, "No outfits available")
+}
+
+func (t *TUI) pickFromCategory() {
+	category := t.currentCategory()
+	if category == nil {
+		return
+	}
+	name := category.info.Category.Name
+	outfit, err := t.selector.ShowNextUniqueRandomOutfitFrom(name)
+	t.showPick(outfit, err, fmt.Sprintf("No outfits available in %s", name))
+}
+
+func (t *TUI) showPick(outfit *entities.OutfitReference, err error, emptyMessage string) {
+	if err != nil {
+		t.setError(fmt.Sprintf("Error: %v", err))
+		return
+	}
+	if outfit == nil {
+		t.setInfo(emptyMessage)
+		return
+	}
+
+	t.picked = outfit
+	t.selectOutfit(*outfit)
+	t.focus = tuiPaneOutfits
+	t.setInfo(fmt.Sprintf("Picked %s from %s. Press w to wear it or r/p for another.", displayOutfitName(outfit.FileName), sanitizeTerminalText(outfit.Category.Name)))
+}
+
+func (t *TUI) selectOutfit(outfit entities.OutfitReference) {
+	for categoryIndex, category := range t.categories {
+		if category.info.Category.Name != outfit.Category.Name {
+			continue
+		}
+		t.categoryIndex = categoryIndex
+		for outfitIndex, candidate := range category.outfits {
+			if candidate.Key() == outfit.Key() {
+				t.outfitIndex = outfitIndex
+				return
+			}
+		}
+		t.outfitIndex = 0
+		return
+	}
+}
+
+func (t *TUI) wearSelected() {
+	outfit := t.currentOutfit()
+	if outfit == nil {
+		t.setInfo("Select an outfit first")
+		return
+	}
+
+	err := t.outfitService.WearOutfit(*outfit)
+	var rotationCompleted *domainerrors.RotationCompletedError
+	switch {
+	case err == nil:
+		t.setSuccess(fmt.Sprintf("Marked %s as worn", displayOutfitName(outfit.FileName)))
+	case errors.As(err, &rotationCompleted):
+		t.setSuccess(fmt.Sprintf("You have now worn all outfits in %s. Press x to reset it.", sanitizeTerminalText(rotationCompleted.Category)))
+	case errors.Is(err, domainerrors.ErrWearQueued):
+		t.setInfo(fmt.Sprintf("Queued %s; it is marked worn once the outfit folder is back", displayOutfitName(outfit.FileName)))
+	default:
+		t.setError(fmt.Sprintf("Could not save this outfit: %v", err))
+		return
+	}
+	t.picked = nil
+	t.reload()
+}
+
+func (t *TUI) resetCurrentCategory() {
+	category := t.currentCategory()
+	if category == nil {
+		return
+	}
+	name := category.info.Category.Name
+	err := t.outfitService.ResetCategoryIfUnchanged(name, category.state.WornRevision)
+	if errors.Is(err, domainerrors.ErrStaleRevision) {
+		t.setInfo(staleWornHistoryMessage)
+		t.reload()
+		return
+	}
+	if err != nil {
+		t.setError(fmt.Sprintf("Failed to reset category: %v", err))
+		return
+	}
+	t.setSuccess(fmt.Sprintf("Reset worn outfits for %s", name))
+	t.reload()
+}
+
+func (t *TUI) currentCategory() *tuiCategory {
+	if t.categoryIndex < 0 || t.categoryIndex >= len(t.categories) {
+		return nil
+	}
+	return &t.categories[t.categoryIndex]
+}
+
+func (t *TUI) currentOutfit() *entities.OutfitReference {
+	category := t.currentCategory()
+	if category == nil || t.outfitIndex < 0 || t.outfitIndex >= len(category.outfits) {
+		return nil
+	}
+	return &category.outfits[t.outfitIndex]
+}
+
+func (t *TUI) clampOutfitIndex() {
+	category := t.currentCategory()
+	if category == nil || len(category.outfits) == 0 {
+		t.outfitIndex = 0
+		return
+	}
+	t.outfitIndex = clampIndex(t.outfitIndex, len(category.outfits))
+}
+
+func (t *TUI) setInfo(message string)    { t.status, t.statusColor = message, uiBlue }
+func (t *TUI) setSuccess(message string) { t.status, t.statusColor = message, uiGreen }
+func (t *TUI) setWarning(message string) { t.status, t.statusColor = message, uiYellow }
+func (t *TUI) setError(message string)   { t.status, t.statusColor = message, uiRed }
+
+func (t *TUI) render() string {
+	width, height := t.screen.Size()
+	if width < tuiMinCategoryWidth*2 {
+		width = tuiMinCategoryWidth * 2
+	}
+	if height < tuiChromeHeight+1 {
+		height = tuiChromeHeight + 1
+	}
+
+	leftWidth := min(max(width/3, tuiMinCategoryWidth), tuiMaxCategoryWidth)
+	rightWidth := width - leftWidth - 3
+	bodyHeight := height - tuiChromeHeight
+
+	title := "👗 Outfit Picker"
+	if t.rootPath != "" {
+		title += " — " + sanitizeTerminalText(displayWardrobePath(t.rootPath))
+	}
+	if t.outfitService.Offline() {
+		title += " (offline, as last seen)"
+	}
+
+	lines := []string{
+		Colorize(tuiFit(" "+title, width), uiBold+uiCyan),
+		Colorize(strings.Repeat("─", width), uiCyan),
+		t.paneTitle(" Categories", leftWidth, tuiPaneCategories) + Colorize(" │ ", uiCyan) + t.paneTitle(t.outfitPaneTitle(), rightWidth, tuiPaneOutfits),
+	}
+
+	left := t.categoryLines(leftWidth, bodyHeight)
+	right := t.outfitLines(rightWidth, bodyHeight)
+	for row := 0; row < bodyHeight; row++ {
+		lines = append(lines, left[row]+Colorize(" │ ", uiCyan)+right[row])
+	}
+
+	lines = append(lines,
+		Colorize(strings.Repeat("─", width), uiCyan),
+		Colorize(tuiFit(" "+sanitizeTerminalText(t.status), width), t.statusColor),
+		Dim(tuiFit(" ↑↓ move  ←→/tab switch  r random  p pick here  w/enter wear  x reset  q quit", width)),
+	)
+	return strings.Join(lines, "\r\n")
+}
+
+func (t *TUI) paneTitle(title string, width int, pane tuiPane) string {
+	style := uiBold
+	if t.focus == pane {
+		style = uiBold + uiGreen
+	}
+	return Colorize(tuiFit(title, width), style)
+}
+
+func (t *TUI) outfitPaneTitle() string {
+	category := t.currentCategory()
+	if category == nil {
+		return "Outfits"
+	}
+	return fmt.Sprintf("Outfits in %s", sanitizeTerminalText(category.info.Category.Name))
+}
+
+func (t *TUI) categoryLines(width, height int) []string {
+	lines := make([]string, height)
+	if len(t.categories) == 0 {
+		lines[0] = Dim(tuiFit(" No categories found", width))
+		fillBlankLines(lines, width)
+		return lines
+	}
+
+	offset := scrollOffset(t.categoryIndex, len(t.categories), height)
+	for row := 0; row < height && offset+row < len(t.categories); row++ {
+		index := offset + row
+		category := t.categories[index]
+		text := tuiFit(" "+categoryRowText(category, width-1), width)
+		switch {
+		case index == t.categoryIndex && t.focus == tuiPaneCategories:
+			text = Colorize(text, tuiReverse)
+		case index == t.categoryIndex:
+			text = Colorize(text, uiBold)
+		case category.info.State != entities.CategoryStateHasOutfits:
+			text = Dim(text)
+		}
+		lines[row] = text
+	}
+	fillBlankLines(lines, width)
+	return lines
+}
+
+func categoryRowText(category tuiCategory, width int) string {
+	name := sanitizeTerminalText(category.info.Category.Name)
+	var detail string
+	switch category.info.State {
+	case entities.CategoryStateHasOutfits:
+		detail = fmt.Sprintf("%s %d/%d", rotationBar(category.state.WornCount(), category.state.TotalCount(), tuiRotationBarWidth), category.state.WornCount(), category.state.TotalCount())
+	case entities.CategoryStateUserExcluded:
+		detail = "excluded"
+	default:
+		detail = "no outfits"
+		if category.info.State.Failed() {
+			detail = categoryStateLabel(category.info.State)
+		}
+	}
+	nameWidth := max(width-utf8.RuneCountInString(detail)-1, 1)
+	return tuiFit(name, nameWidth) + " " + detail
+}
+
+func (t *TUI) outfitLines(width, height int) []string {
+	lines := make([]string, height)
+	category := t.currentCategory()
+	if category == nil || len(category.outfits) == 0 {
+		message := " No outfits in this category"
+		switch {
+		case category == nil || category.info.State == entities.CategoryStateUserExcluded:
+		case category.info.State.Failed():
+			message = " " + categoryHint(category.info)
+		default:
+			message = fmt.Sprintf(" Add .avatar files to %s", sanitizeTerminalText(category.info.Category.Path))
+		}
+		lines[0] = Dim(tuiFit(message, width))
+		fillBlankLines(lines, width)
+		return lines
+	}
+
+	offset := scrollOffset(t.outfitIndex, len(category.outfits), height)
+	for row := 0; row < height && offset+row < len(category.outfits); row++ {
+		index := offset + row
+		outfit := category.outfits[index]
+		marker := "  "
+		if category.worn[outfit.Key()] {
+			marker = "✓ "
+		}
+		if t.picked != nil && t.picked.Category.Name == outfit.Category.Name && t.picked.Key() == outfit.Key() {
+			marker = "★ "
+		}
+		text := tuiFit(" "+marker+displayOutfitName(outfit.FileName), width)
+		switch {
+		case index == t.outfitIndex && t.focus == tuiPaneOutfits:
+			text = Colorize(text, tuiReverse)
+		case category.worn[outfit.Key()]:
+			text = Colorize(text, uiGreen)
+		}
+		lines[row] = text
+	}
+	fillBlankLines(lines, width)
+	return lines
+}
+
+func rotationBar(worn, total, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = min(worn*width/total, width)
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+func tuiFit(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	length := utf8.RuneCountInString(text)
+	if length <= width {
+		return text + strings.Repeat(" ", width-length)
+	}
+	runes := []rune(text)
+	return string(runes[:width-1]) + "…"
+}
+
+func fillBlankLines(lines []string, width int) {
+	for index, line := range lines {
+		if line == "" {
+			lines[index] = strings.Repeat(" ", width)
+		}
+	}
+}
+
+func scrollOffset(selected, total, height int) int {
+	if total <= height || selected < height {
+		return 0
+	}
+	return min(selected-height+1, total-height)
+}
+
+func clampIndex(index, length int) int {
+	if index < 0 {
+		return 0
+	}
+	if index >= length {
+		return length - 1
+	}
+	return index
+}
+
+func sortedOutfits(outfits []entities.OutfitReference) []entities.OutfitReference {
+	sorted := make([]entities.OutfitReference, len(outfits))
+	copy(sorted, outfits)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].FileName < sorted[j].FileName
+	})
+	return sorted
+}
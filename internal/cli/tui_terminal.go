@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+const (
+	tuiEnterAltScreen = "\x1b[?1049h"
+	tuiExitAltScreen  = "\x1b[?1049l"
+	tuiHideCursor     = "\x1b[?25l"
+	tuiShowCursor     = "\x1b[?25h"
+	tuiClearScreen    = "\x1b[H\x1b[2J"
+	tuiReverse        = "\x1b[7m"
+	tuiDefaultWidth   = 80
+	tuiDefaultHeight  = 24
+	// tuiEscapeWait is how long an ESC waits for the rest of an escape
+	// sequence, which a terminal may send in a later read, before it counts
+	// as the Escape key.
+	tuiEscapeWait = 50 * time.Millisecond
+)
+
+var errTUIRequiresTerminal = errors.New("the full-screen interface requires an interactive terminal")
+
+type tuiKeyKind int
+
+const (
+	tuiKeyRune tuiKeyKind = iota
+	tuiKeyUp
+	tuiKeyDown
+	tuiKeyLeft
+	tuiKeyRight
+	tuiKeyEnter
+	tuiKeyTab
+	tuiKeyEscape
+	tuiKeyInterrupt
+	tuiKeyUnknown
+)
+
+type tuiKey struct {
+	kind tuiKeyKind
+	char rune
+}
+
+func runeKey(char rune) tuiKey {
+	return tuiKey{kind: tuiKeyRune, char: char}
+}
+
+// tuiScreen is the terminal surface the full-screen interface draws on.
+type tuiScreen interface {
+	ReadKey() (tuiKey, error)
+	Size() (width, height int)
+	Draw(frame string) error
+}
+
+type terminalScreen struct {
+	input    *os.File
+	output   *os.File
+	reader   *tuiInput
+	restored bool
+	state    *term.State
+}
+
+func openTerminalScreen(input, output *os.File) (*terminalScreen, error) {
+	if !term.IsTerminal(int(input.Fd())) || !term.IsTerminal(int(output.Fd())) {
+		return nil, errTUIRequiresTerminal
+	}
+	state, err := term.MakeRaw(int(input.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("could not switch terminal to raw mode: %w", err)
+	}
+	_, _ = io.WriteString(output, tuiEnterAltScreen+tuiHideCursor)
+	return &terminalScreen{
+		input:  input,
+		output: output,
+		reader: newTUIInput(input),
+		state:  state,
+	}, nil
+}
+
+func (s *terminalScreen) ReadKey() (tuiKey, error) {
+	return decodeTUIKey(s.reader)
+}
+
+func (s *terminalScreen) Size() (int, int) {
+	width, height, err := term.GetSize(int(s.output.Fd()))
+	if err != nil || width <= 0 || height <= 0 {
+		return tuiDefaultWidth, tuiDefaultHeight
+	}
+	return width, height
+}
+
+func (s *terminalScreen) Draw(frame string) error {
+	_, err := io.WriteString(s.output, tuiClearScreen+frame)
+	return err
+}
+
+func (s *terminalScreen) Close() error {
+	if s.restored {
+		return nil
+	}
+	s.restored = true
+	_, _ = io.WriteString(s.output, tuiShowCursor+tuiExitAltScreen)
+	return term.Restore(int(s.input.Fd()), s.state)
+}
+
+// tuiInput reads the bytes typed at the terminal on its own goroutine, so
+// that decoding a key can wait a moment for bytes that have not arrived yet.
+type tuiInput struct {
+	bytes chan byte
+	err   error
+}
+
+func newTUIInput(input io.Reader) *tuiInput {
+	in := &tuiInput{bytes: make(chan byte, 64)}
+	go func() {
+		reader := bufio.NewReader(input)
+		for {
+			b, err := reader.ReadByte()
+			if err != nil {
+				in.err = err
+				close(in.bytes)
+				return
+			}
+			in.bytes <- b
+		}
+	}()
+	return in
+}
+
+// ReadByte waits for the next byte, returning the input's error once it has
+// ended.
+func (in *tuiInput) ReadByte() (byte, error) {
+	b, ok := <-in.bytes
+	if !ok {
+		return 0, in.err
+	}
+	return b, nil
+}
+
+// readByteWithin returns the next byte if one arrives within wait.
+func (in *tuiInput) readByteWithin(wait time.Duration) (byte, bool) {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case b, ok := <-in.bytes:
+		return b, ok
+	case <-timer.C:
+		return 0, false
+	}
+}
+
+func decodeTUIKey(reader *tuiInput) (tuiKey, error) {
+	b, err := reader.ReadByte()
+	if err != nil {
+		return tuiKey{}, err
+	}
+
+	switch b {
+	case 0x03, 0x04:
+		return tuiKey{kind: tuiKeyInterrupt}, nil
+	case '\r', '\n':
+		return tuiKey{kind: tuiKeyEnter}, nil
+	case '\t':
+		return tuiKey{kind: tuiKeyTab}, nil
+	case 0x1b:
+		return decodeTUIEscape(reader)
+	}
+
+	if b < 0x80 {
+		return runeKey(rune(b)), nil
+	}
+	encoded := []byte{b}
+	for !utf8.FullRune(encoded) {
+		next, err := reader.ReadByte()
+		if err != nil {
+			return tuiKey{}, err
+		}
+		encoded = append(encoded, next)
+	}
+	char, _ := utf8.DecodeRune(encoded)
+	return runeKey(char), nil
+}
+
+// decodeTUIEscape decodes what follows an ESC. An ESC followed by nothing
+// within tuiEscapeWait is the Escape key; a terminal can split an arrow
+// key's sequence across reads, so what has arrived so far is not enough to
+// tell.
+func decodeTUIEscape(reader *tuiInput) (tuiKey, error) {
+	introducer, ok := reader.readByteWithin(tuiEscapeWait)
+	if !ok {
+		return tuiKey{kind: tuiKeyEscape}, nil
+	}
+	if introducer != '[' && introducer != 'O' {
+		return tuiKey{kind: tuiKeyUnknown}, nil
+	}
+
+	var sequence strings.Builder
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return tuiKey{kind: tuiKeyUnknown}, nil
+		}
+		if b >= 0x40 && b <= 0x7e {
+			sequence.WriteByte(b)
+			break
+		}
+		sequence.WriteByte(b)
+	}
+
+	switch sequence.String() {
+	case "A":
+		return tuiKey{kind: tuiKeyUp}, nil
+	case "B":
+		return tuiKey{kind: tuiKeyDown}, nil
+	case "C":
+		return tuiKey{kind: tuiKeyRight}, nil
+	case "D":
+		return tuiKey{kind: tuiKeyLeft}, nil
+	case "Z":
+		return tuiKey{kind: tuiKeyTab}, nil
+	default:
+		return tuiKey{kind: tuiKeyUnknown}, nil
+	}
+}
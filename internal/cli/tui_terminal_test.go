@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDecodeTUIKey(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []tuiKey
+	}{
+		{name: "arrows", input: "\x1b[A\x1b[B\x1b[C\x1b[D", want: []tuiKey{{kind: tuiKeyUp}, {kind: tuiKeyDown}, {kind: tuiKeyRight}, {kind: tuiKeyLeft}}},
+		{name: "application mode arrows", input: "\x1bOA\x1bOB", want: []tuiKey{{kind: tuiKeyUp}, {kind: tuiKeyDown}}},
+		{name: "enter tab and back tab", input: "\r\n\t\x1b[Z", want: []tuiKey{{kind: tuiKeyEnter}, {kind: tuiKeyEnter}, {kind: tuiKeyTab}, {kind: tuiKeyTab}}},
+		{name: "interrupt", input: "\x03\x04", want: []tuiKey{{kind: tuiKeyInterrupt}, {kind: tuiKeyInterrupt}}},
+		{name: "letters and unicode", input: "wé", want: []tuiKey{runeKey('w'), runeKey('é')}},
+		{name: "unknown csi sequence", input: "\x1b[3~", want: []tuiKey{{kind: tuiKeyUnknown}}},
+		{name: "alt key", input: "\x1bx", want: []tuiKey{{kind: tuiKeyUnknown}}},
+		{name: "lone escape", input: "\x1b", want: []tuiKey{{kind: tuiKeyEscape}}},
+		{name: "truncated sequence", input: "\x1b[1", want: []tuiKey{{kind: tuiKeyUnknown}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := newTUIInput(strings.NewReader(tt.input))
+			for index, want := range tt.want {
+				got, err := decodeTUIKey(reader)
+				if err != nil {
+					t.Fatalf("decodeTUIKey() #%d error = %v", index, err)
+				}
+				if got != want {
+					t.Fatalf("decodeTUIKey() #%d = %#v, want %#v", index, got, want)
+				}
+			}
+			if _, err := decodeTUIKey(reader); !errors.Is(err, io.EOF) {
+				t.Fatalf("decodeTUIKey() after input error = %v, want EOF", err)
+			}
+		})
+	}
+}
+
+func TestDecodeTUIKey_WaitsForSplitEscapeSequences(t *testing.T) {
+	input, output := io.Pipe()
+	reader := newTUIInput(input)
+	go func() {
+		for _, chunk := range []string{"\x1b", "[", "A", "q"} {
+			if _, err := io.WriteString(output, chunk); err != nil {
+				return
+			}
+		}
+		_ = output.Close()
+	}()
+
+	for index, want := range []tuiKey{{kind: tuiKeyUp}, runeKey('q')} {
+		got, err := decodeTUIKey(reader)
+		if err != nil || got != want {
+			t.Fatalf("decodeTUIKey() #%d = %#v, %v; want %#v", index, got, err, want)
+		}
+	}
+}
+
+func TestOpenTerminalScreen_RejectsNonTerminal(t *testing.T) {
+	file, err := os.CreateTemp(t.TempDir(), "tui-*")
+	if err != nil {
+		t.Fatalf("CreateTemp() error = %v", err)
+	}
+	defer file.Close()
+
+	if _, err := openTerminalScreen(file, file); !errors.Is(err, errTUIRequiresTerminal) {
+		t.Fatalf("openTerminalScreen() error = %v, want %v", err, errTUIRequiresTerminal)
+	}
+}
@@ -0,0 +1,323 @@
+package cli
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+type fakeTUIScreen struct {
+	keys   []tuiKey
+	frames []string
+	width  int
+	height int
+	err    error
+}
+
+func (s *fakeTUIScreen) ReadKey() (tuiKey, error) {
+	if len(s.keys) == 0 {
+		if s.err != nil {
+			return tuiKey{}, s.err
+		}
+		return tuiKey{}, io.EOF
+	}
+	key := s.keys[0]
+	s.keys = s.keys[1:]
+	return key, nil
+}
+
+func (s *fakeTUIScreen) Size() (int, int) {
+	if s.width == 0 {
+		return 100, 20
+	}
+	return s.width, s.height
+}
+
+func (s *fakeTUIScreen) Draw(frame string) error {
+	s.frames = append(s.frames, frame)
+	return nil
+}
+
+func (s *fakeTUIScreen) lastFrame() string {
+	if len(s.frames) == 0 {
+		return ""
+	}
+	return s.frames[len(s.frames)-1]
+}
+
+func newTUIForTest(picker *stubRuntime, keys ...tuiKey) (*TUI, *fakeTUIScreen) {
+	screen := &fakeTUIScreen{keys: keys}
+	return newTUI(newStubOutfitService(picker), picker.random, screen), screen
+}
+
+func newTUITestRuntime() *stubRuntime {
+	picker := newStubRuntime()
+	casual := mainMenuCategory("casual")
+	club := mainMenuCategory("club")
+	picker.wardrobe.rootDirectory = cliTestOutfitRoot
+	picker.wardrobe.categoryInfos = []entities.CategoryInfo{
+		entities.NewCategoryInfo(club, entities.CategoryStateHasOutfits, 2),
+		entities.NewCategoryInfo(casual, entities.CategoryStateHasOutfits, 3),
+		entities.NewCategoryInfo(mainMenuCategory("empty"), entities.CategoryStateEmpty, 0),
+	}
+	picker.wardrobe.outfitStates["casual"] = mainMenuState(casual, []string{"a.avatar", "b.avatar", "c.avatar"}, []string{"b.avatar", "c.avatar"}, []string{"a.avatar"})
+	picker.wardrobe.outfitStates["club"] = mainMenuState(club, []string{"x.avatar", "y.avatar"}, []string{"x.avatar", "y.avatar"}, nil)
+	return picker
+}
+
+func TestTUI_RendersCategoriesOutfitsAndRotationBars(t *testing.T) {
+	picker := newTUITestRuntime()
+	tui, screen := newTUIForTest(picker)
+
+	if err := tui.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	frame := screen.lastFrame()
+	assertOutputContains(t, frame, "Outfit Picker", "Categories", "Outfits in casual", "casual", "club", "no outfits", "1/3", "0/2", "✓ a", "  b")
+	assertOutputContains(t, frame, rotationBar(1, 3, tuiRotationBarWidth))
+}
+
+func TestTUI_NavigatesAndWearsSelectedOutfit(t *testing.T) {
+	picker := newTUITestRuntime()
+	tui, screen := newTUIForTest(picker,
+		tuiKey{kind: tuiKeyDown},
+		tuiKey{kind: tuiKeyUp},
+		tuiKey{kind: tuiKeyEnter},
+		tuiKey{kind: tuiKeyDown},
+		runeKey('w'),
+		runeKey('q'),
+	)
+
+	if err := tui.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	assertWearRequested(t, picker, "b.avatar")
+	assertOutputContains(t, screen.lastFrame(), "Marked b as worn")
+}
+
+func TestTUI_RandomPickSelectsOutfitAcrossCategories(t *testing.T) {
+	picker := newTUITestRuntime()
+	picker.random.globalResults = []stubSelectorResult{{outfit: mainMenuOutfitPtr("club", "y.avatar")}}
+	tui, screen := newTUIForTest(picker, runeKey('r'), tuiKey{kind: tuiKeyEnter})
+
+	if err := tui.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	assertWearRequested(t, picker, "y.avatar")
+	if len(screen.frames) < 2 {
+		t.Fatalf("frames = %d, want at least 2", len(screen.frames))
+	}
+	assertOutputContains(t, screen.frames[1], "Picked y from club", "★ y", "Outfits in club")
+}
+
+func TestTUI_PickFromCategoryUsesCategorySelector(t *testing.T) {
+	picker := newTUITestRuntime()
+	picker.random.categoryResults = []stubSelectorResult{{outfit: mainMenuOutfitPtr("casual", "c.avatar")}}
+	tui, screen := newTUIForTest(picker, runeKey('p'), runeKey('p'))
+
+	if err := tui.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	assertCategoryRandomRequestCount(t, picker, 1)
+	assertOutputContains(t, screen.frames[1], "Picked c from casual")
+	assertOutputContains(t, screen.lastFrame(), "No outfits available in casual")
+}
+
+func TestTUI_ReportsSelectorErrors(t *testing.T) {
+	picker := newTUITestRuntime()
+	picker.random.globalResults = []stubSelectorResult{{err: errors.New("selector failed")}}
+	tui, screen := newTUIForTest(picker, runeKey('r'))
+
+	if err := tui.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	assertOutputContains(t, screen.lastFrame(), "Error: selector failed")
+}
+
+func TestTUI_WearReportsRotationCompleteAndFailure(t *testing.T) {
+	t.Run("rotation complete", func(t *testing.T) {
+		picker := newTUITestRuntime()
+		picker.commands.wearErr = domainerrors.NewRotationCompletedError("casual")
+		tui, screen := newTUIForTest(picker, tuiKey{kind: tuiKeyTab}, runeKey('w'))
+
+		if err := tui.Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		assertOutputContains(t, screen.lastFrame(), "You have now worn all outfits in casual")
+	})
+
+	t.Run("save failure", func(t *testing.T) {
+		picker := newTUITestRuntime()
+		picker.commands.wearErr = errors.New("disk full")
+		tui, screen := newTUIForTest(picker, tuiKey{kind: tuiKeyRight}, runeKey('w'))
+
+		if err := tui.Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		assertOutputContains(t, screen.lastFrame(), "Could not save this outfit: disk full")
+	})
+}
+
+func TestTUI_OfflineQueuesWears(t *testing.T) {
+	picker := newTUITestRuntime()
+	picker.offline = true
+	picker.commands.wearErr = domainerrors.ErrWearQueued
+	screen := &fakeTUIScreen{keys: []tuiKey{{kind: tuiKeyTab}, runeKey('w')}}
+	tui := newTUI(NewOutfitServiceFromRuntime(picker), picker.random, screen)
+
+	if err := tui.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	assertOutputContains(t, screen.lastFrame(), "(offline, as last seen)", "Queued a; it is marked worn once the outfit folder is back")
+}
+
+func TestTUI_ResetRequiresConfirmation(t *testing.T) {
+	t.Run("confirmed", func(t *testing.T) {
+		picker := newTUITestRuntime()
+		tui, screen := newTUIForTest(picker, runeKey('x'), runeKey('y'))
+
+		if err := tui.Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		assertCategoryResetRequested(t, picker, "casual")
+		assertOutputContains(t, screen.frames[1], "Reset worn outfits for casual? (y/n)")
+		assertOutputContains(t, screen.lastFrame(), "Reset worn outfits for casual")
+	})
+
+	t.Run("changed elsewhere", func(t *testing.T) {
+		picker := newTUITestRuntime()
+		picker.commands.resetCategoryErr = domainerrors.NewStaleCategoryError("casual")
+		tui, screen := newTUIForTest(picker, runeKey('x'), runeKey('y'))
+
+		if err := tui.Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if len(picker.commands.resetRevisions) != 1 {
+			t.Fatalf("reset revisions = %v, want the shown category's", picker.commands.resetRevisions)
+		}
+		assertOutputContains(t, screen.lastFrame(), "Worn outfits were changed in another outfitpicker session")
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		picker := newTUITestRuntime()
+		tui, screen := newTUIForTest(picker, runeKey('x'), runeKey('n'))
+
+		if err := tui.Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		assertNoCategoryResetRequested(t, picker)
+		assertOutputContains(t, screen.lastFrame(), "Reset cancelled")
+	})
+}
+
+func TestTUI_QuitKeysStopTheLoop(t *testing.T) {
+	for _, key := range []tuiKey{runeKey('q'), {kind: tuiKeyEscape}, {kind: tuiKeyInterrupt}} {
+		picker := newTUITestRuntime()
+		tui, screen := newTUIForTest(picker, key, runeKey('w'))
+
+		if err := tui.Run(); err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if len(screen.keys) != 1 {
+			t.Fatalf("remaining keys = %d, want 1 after quitting", len(screen.keys))
+		}
+	}
+}
+
+func TestTUI_ShowsCategoryLoadError(t *testing.T) {
+	picker := newStubRuntime()
+	picker.wardrobe.categoryInfoErr = errors.New("scan failed")
+	tui, screen := newTUIForTest(picker, runeKey('w'))
+
+	if err := tui.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	assertOutputContains(t, screen.lastFrame(), "No categories found", "Select an outfit first")
+	assertOutputContains(t, screen.frames[0], "Could not load categories: scan failed")
+}
+
+func TestTUI_ShowsCategoriesThatCannotBeRead(t *testing.T) {
+	picker := newStubRuntime()
+	locked := mainMenuCategory("locked")
+	picker.wardrobe.categoryInfos = []entities.CategoryInfo{entities.NewCategoryInfo(locked, entities.CategoryStatePermissionDenied, 0)}
+	tui, screen := newTUIForTest(picker)
+
+	if err := tui.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	assertOutputContains(t, screen.lastFrame(), "permission denied", "Give your user read access to")
+}
+
+func TestTUI_ReturnsReadErrors(t *testing.T) {
+	picker := newTUITestRuntime()
+	tui, screen := newTUIForTest(picker)
+	screen.err = errors.New("read failed")
+
+	if err := tui.Run(); err == nil || err.Error() != "read failed" {
+		t.Fatalf("Run() error = %v, want read failed", err)
+	}
+}
+
+func TestTUI_ScrollsLongOutfitLists(t *testing.T) {
+	picker := newStubRuntime()
+	category := mainMenuCategory("casual")
+	var names []string
+	for _, letter := range "abcdefghijklmnop" {
+		names = append(names, string(letter)+".avatar")
+	}
+	picker.wardrobe.categoryInfos = []entities.CategoryInfo{entities.NewCategoryInfo(category, entities.CategoryStateHasOutfits, len(names))}
+	picker.wardrobe.outfitStates["casual"] = mainMenuState(category, names, names, nil)
+
+	keys := []tuiKey{{kind: tuiKeyRight}}
+	for range names {
+		keys = append(keys, runeKey('j'))
+	}
+	tui, screen := newTUIForTest(picker, keys...)
+	screen.width, screen.height = 60, 10
+
+	if err := tui.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	frame := screen.lastFrame()
+	assertOutputContains(t, frame, " p ")
+	assertOutputNotContains(t, frame, "   a ")
+	if lines := strings.Count(frame, "\r\n") + 1; lines != 10 {
+		t.Fatalf("frame lines = %d, want 10", lines)
+	}
+}
+
+func TestTUIHelpers(t *testing.T) {
+	if got := rotationBar(0, 0, 4); got != "░░░░" {
+		t.Fatalf("rotationBar(0, 0) = %q", got)
+	}
+	if got := rotationBar(5, 4, 4); got != "████" {
+		t.Fatalf("rotationBar(5, 4) = %q", got)
+	}
+	if got := tuiFit("wardrobe", 5); got != "ward…" {
+		t.Fatalf("tuiFit() = %q, want ward…", got)
+	}
+	if got := tuiFit("ab", 4); got != "ab  " {
+		t.Fatalf("tuiFit() = %q, want padded", got)
+	}
+	if got := tuiFit("ab", 0); got != "" {
+		t.Fatalf("tuiFit() = %q, want empty", got)
+	}
+	if got := clampIndex(-1, 3); got != 0 {
+		t.Fatalf("clampIndex(-1) = %d", got)
+	}
+	if got := clampIndex(7, 3); got != 2 {
+		t.Fatalf("clampIndex(7) = %d", got)
+	}
+	if got := scrollOffset(9, 10, 4); got != 6 {
+		t.Fatalf("scrollOffset() = %d, want 6", got)
+	}
+}
@@ -58,6 +58,14 @@ func displayOutfitName(fileName string) string {
 	return sanitizeTerminalText(strings.TrimSuffix(fileName, ".avatar"))
 }
 
+func displayOutfitNames(fileNames []string) string {
+	names := make([]string, len(fileNames))
+	for index, fileName := range fileNames {
+		names[index] = displayOutfitName(fileName)
+	}
+	return strings.Join(names, ", ")
+}
+
 func Section(title, icon, color string) {
 	SectionWithConsole(nil, title, icon, color)
 }
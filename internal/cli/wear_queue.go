@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/application/usecases"
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+// wearQueue queues the outfits worn while the wardrobe is offline, and
+// applies them when a later run can read it, keeping what it applied for the
+// main menu.
+type wearQueue struct {
+	queue   *usecases.WearQueueUseCase
+	now     func() time.Time
+	wear    func(outfit entities.OutfitReference, at time.Time) error
+	offline bool
+	applied entities.AppliedWears
+	err     error
+}
+
+// wardrobeOffline reports whether no wardrobe root can be read while an
+// earlier run recorded the wardrobe, so that the recording can stand in for
+// it. A failure to tell is reported and treated as online.
+func wardrobeOffline(deps RuntimeDependencies) bool {
+	offline, err := usecases.NewWearQueueUseCase(deps.ConfigManager, deps.CategorySvc, deps.KnownWardrobe, deps.WearQueue).Offline()
+	reportLoadFailure(deps.ReportWarning, "could not tell whether the wardrobe is offline", err)
+	return offline
+}
+
+// isOffline reports whether the application is showing the recorded
+// wardrobe instead of the real one.
+func (q *wearQueue) isOffline() bool {
+	return q != nil && q.offline
+}
+
+// add queues outfit and returns ErrWearQueued, or why it could not be queued.
+func (q *wearQueue) add(outfit entities.OutfitReference) error {
+	if err := q.queue.Queue(outfit, q.now()); err != nil {
+		return err
+	}
+	return domainerrors.ErrWearQueued
+}
+
+// apply wears the queued outfits as of when they were queued and keeps what
+// it applied.
+func (q *wearQueue) apply() error {
+	q.applied, q.err = q.queue.Apply(q.wear)
+	return q.err
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/application/usecases"
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+)
+
+func TestWearQueue_ReportsFailures(t *testing.T) {
+	var reported []string
+	report := func(err error) { reported = append(reported, err.Error()) }
+	configManager := &stubConfigManager{err: errors.New("config unreadable")}
+
+	if wardrobeOffline(RuntimeDependencies{ConfigManager: configManager, ReportWarning: report}) {
+		t.Error("wardrobeOffline() = true when the config cannot be read, want false")
+	}
+	if len(reported) != 1 || !strings.Contains(reported[0], "could not tell whether the wardrobe is offline: config unreadable") {
+		t.Errorf("reported = %v", reported)
+	}
+	queued := &stubWearQueue{queue: &entities.WearQueue{Wears: []entities.QueuedWear{{Category: "casual", FileName: "look.avatar"}}}}
+	queue := &wearQueue{
+		queue: usecases.NewWearQueueUseCase(configManager, nil, nil, queued),
+		wear: func(entities.OutfitReference, time.Time) error {
+			t.Error("apply() wore an outfit without a config")
+			return nil
+		},
+	}
+	if err := queue.apply(); err == nil {
+		t.Error("apply() error = nil, want the config failure")
+	}
+
+	app := &Application{queue: queue}
+	if _, err := app.AppliedWears(); err == nil {
+		t.Error("AppliedWears() error = nil, want the failure from loading")
+	}
+	if app.Offline() || (&Application{}).Offline() {
+		t.Error("Offline() = true for an application that loaded online")
+	}
+}
@@ -0,0 +1,63 @@
+package entities
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+	"github.com/dh85/outfitpicker/internal/domain/validation"
+)
+
+// ActivationMode controls how an outfit is installed into a slot.
+type ActivationMode string
+
+const (
+	ActivationModeCopy    ActivationMode = "copy"
+	ActivationModeSymlink ActivationMode = "symlink"
+)
+
+// PreviousActivationSuffix is appended to a slot target to name the file that
+// holds the previously active outfit.
+const PreviousActivationSuffix = ".previous"
+
+// ActivationSlot is a named location that another application loads an
+// outfit from.
+type ActivationSlot struct {
+	Target string         `json:"target"`
+	Mode   ActivationMode `json:"mode"`
+}
+
+// NewActivationSlot creates and validates an activation slot. An empty mode
+// defaults to copy.
+func NewActivationSlot(target string, mode ActivationMode) (ActivationSlot, error) {
+	if strings.TrimSpace(target) == "" {
+		return ActivationSlot{}, errors.NewInvalidInputError("slot target cannot be empty")
+	}
+	if !filepath.IsAbs(target) {
+		return ActivationSlot{}, errors.NewInvalidInputError("slot target must be an absolute path")
+	}
+	if mode == "" {
+		mode = ActivationModeCopy
+	}
+	if mode != ActivationModeCopy && mode != ActivationModeSymlink {
+		return ActivationSlot{}, errors.NewInvalidInputError("slot mode must be copy or symlink")
+	}
+	// The target itself becomes a symlink in symlink mode, so only its
+	// directory is checked for symlink components.
+	if err := validation.ValidatePath(filepath.Dir(target)); err != nil {
+		return ActivationSlot{}, errors.MapError(err)
+	}
+
+	return ActivationSlot{Target: filepath.Clean(target), Mode: mode}, nil
+}
+
+// PreviousPath returns the path holding the outfit that was active before the
+// most recent activation.
+func (s ActivationSlot) PreviousPath() string {
+	return s.Target + PreviousActivationSuffix
+}
+
+func isValidSlotName(name string) bool {
+	trimmed := strings.TrimSpace(name)
+	return trimmed != "" && trimmed == name && !strings.ContainsAny(name, `/\`)
+}
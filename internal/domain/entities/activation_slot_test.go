@@ -0,0 +1,86 @@
+package entities
+
+import (
+	stderrors "errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+func TestNewActivationSlot(t *testing.T) {
+	target := filepath.Join(string(filepath.Separator)+"Users", "user", "game", "current.avatar")
+
+	tests := []struct {
+		name     string
+		target   string
+		mode     ActivationMode
+		wantMode ActivationMode
+		wantErr  bool
+	}{
+		{name: "copy", target: target, mode: ActivationModeCopy, wantMode: ActivationModeCopy},
+		{name: "symlink", target: target, mode: ActivationModeSymlink, wantMode: ActivationModeSymlink},
+		{name: "defaults to copy", target: target, wantMode: ActivationModeCopy},
+		{name: "empty target", target: "  ", wantErr: true},
+		{name: "relative target", target: "current.avatar", wantErr: true},
+		{name: "unknown mode", target: target, mode: "hardlink", wantErr: true},
+		{name: "restricted directory", target: "/etc/current.avatar", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slot, err := NewActivationSlot(tt.target, tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewActivationSlot() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if slot.Target != tt.target || slot.Mode != tt.wantMode {
+				t.Fatalf("NewActivationSlot() = %#v, want target %q mode %q", slot, tt.target, tt.wantMode)
+			}
+			if slot.PreviousPath() != tt.target+PreviousActivationSuffix {
+				t.Fatalf("PreviousPath() = %q", slot.PreviousPath())
+			}
+		})
+	}
+}
+
+func TestConfig_Slots(t *testing.T) {
+	config, err := NewConfig("/Users/user/outfits", nil, nil)
+	if err != nil {
+		t.Fatalf("NewConfig() error = %v", err)
+	}
+	slot := ActivationSlot{Target: "/Users/user/game/current.avatar", Mode: ActivationModeCopy}
+
+	if _, err := config.Slot("game"); !stderrors.Is(err, errors.ErrSlotNotFound) {
+		t.Fatalf("Slot() error = %v, want ErrSlotNotFound", err)
+	}
+	for _, name := range []string{"", " game", "a/b"} {
+		if _, err := config.WithSlot(name, slot); err == nil {
+			t.Fatalf("WithSlot(%q) error = nil, want invalid name", name)
+		}
+	}
+
+	updated, err := config.WithSlot("game", slot)
+	if err != nil {
+		t.Fatalf("WithSlot() error = %v", err)
+	}
+	if len(config.Slots) != 0 {
+		t.Fatal("WithSlot() modified the original configuration")
+	}
+	if got, err := updated.Slot("game"); err != nil || got != slot {
+		t.Fatalf("Slot() = %#v, %v, want %#v", got, err, slot)
+	}
+
+	removed, err := updated.WithoutSlot("game")
+	if err != nil {
+		t.Fatalf("WithoutSlot() error = %v", err)
+	}
+	if len(removed.Slots) != 0 || len(updated.Slots) != 1 {
+		t.Fatalf("WithoutSlot() slots = %v, original = %v", removed.Slots, updated.Slots)
+	}
+	if _, err := removed.WithoutSlot("game"); !stderrors.Is(err, errors.ErrSlotNotFound) {
+		t.Fatalf("WithoutSlot() missing error = %v, want ErrSlotNotFound", err)
+	}
+}
@@ -0,0 +1,96 @@
+package entities
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ArchiveFormat is a kind of archive a wardrobe can be read from without
+// extracting it.
+type ArchiveFormat string
+
+const (
+	ArchiveZip ArchiveFormat = "zip"
+	ArchiveTar ArchiveFormat = "tar"
+)
+
+// ArchiveFormatOf returns the format of the archive at path, judged by its
+// extension, or "" when it does not name one. Gzipped tar archives count as
+// tar. Only a regular file is an archive: a directory named summer.zip is
+// read as a directory. A path that does not exist is judged by its name
+// alone, so that a missing archive is reported as one.
+func ArchiveFormatOf(path string) ArchiveFormat {
+	format := archiveFormatOfName(path)
+	if format == "" {
+		return ""
+	}
+	if info, err := os.Stat(path); err == nil && !info.Mode().IsRegular() {
+		return ""
+	}
+	return format
+}
+
+func archiveFormatOfName(name string) ArchiveFormat {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return ArchiveZip
+	case strings.HasSuffix(lower, ".tar"), strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return ArchiveTar
+	default:
+		return ""
+	}
+}
+
+// ArchivePath is a path to a file inside an archive.
+type ArchivePath struct {
+	Format ArchiveFormat
+	// Archive is the filesystem path of the archive.
+	Archive string
+	// Name is the slash-separated path inside the archive, "" for the
+	// archive itself.
+	Name string
+}
+
+// SplitArchivePath splits a path that leads into an archive, such as
+// /packs/summer.zip/casual/look.avatar, at the first element that names an
+// archive. It reports false for a path that does not go through one.
+func SplitArchivePath(path string) (ArchivePath, bool) {
+	cleaned := filepath.Clean(path)
+	elements := strings.Split(cleaned, string(filepath.Separator))
+	archive := ""
+	for index, element := range elements {
+		archive += element
+		if format := ArchiveFormatOf(archive); format != "" {
+			return ArchivePath{
+				Format:  format,
+				Archive: archive,
+				Name:    strings.Join(elements[index+1:], "/"),
+			}, true
+		}
+		archive += string(filepath.Separator)
+	}
+	return ArchivePath{}, false
+}
+
+// URI returns the path as a URI naming the archive and the file inside it,
+// such as zip:/packs/summer.zip!/casual/look.avatar.
+func (p ArchivePath) URI() string {
+	return string(p.Format) + ":" + filepath.ToSlash(p.Archive) + "!/" + p.Name
+}
+
+// InArchive reports whether path leads into an archive.
+func InArchive(path string) bool {
+	_, ok := SplitArchivePath(path)
+	return ok
+}
+
+// fileURI returns path as it is shown to users and plugins: a URI for a
+// file inside an archive, and the path itself otherwise.
+func fileURI(path string) string {
+	if archived, ok := SplitArchivePath(path); ok {
+		return archived.URI()
+	}
+	return path
+}
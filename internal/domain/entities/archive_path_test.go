@@ -0,0 +1,68 @@
+package entities
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveFormatOf(t *testing.T) {
+	tests := map[string]ArchiveFormat{
+		"summer.zip":    ArchiveZip,
+		"Summer.ZIP":    ArchiveZip,
+		"summer.tar":    ArchiveTar,
+		"summer.tar.gz": ArchiveTar,
+		"summer.tgz":    ArchiveTar,
+		"summer":        "",
+		"summer.gz":     "",
+	}
+	for name, want := range tests {
+		if got := ArchiveFormatOf(name); got != want {
+			t.Errorf("ArchiveFormatOf(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestSplitArchivePath(t *testing.T) {
+	packs := filepath.Join(string(filepath.Separator)+"home", "packs")
+	archive := filepath.Join(packs, "summer.zip")
+
+	got, ok := SplitArchivePath(filepath.Join(archive, "casual", "look.avatar"))
+	if !ok || got != (ArchivePath{Format: ArchiveZip, Archive: archive, Name: "casual/look.avatar"}) {
+		t.Fatalf("SplitArchivePath() = %+v, %t", got, ok)
+	}
+	if want := "zip:" + filepath.ToSlash(archive) + "!/casual/look.avatar"; got.URI() != want {
+		t.Errorf("URI() = %q, want %q", got.URI(), want)
+	}
+	if got, ok := SplitArchivePath(archive); !ok || got.Archive != archive || got.Name != "" {
+		t.Errorf("SplitArchivePath() of the archive itself = %+v, %t", got, ok)
+	}
+	if _, ok := SplitArchivePath(filepath.Join(packs, "casual", "look.avatar")); ok {
+		t.Error("SplitArchivePath() split a path that does not go through an archive")
+	}
+	if !InArchive(filepath.Join(archive, "casual")) || InArchive(packs) {
+		t.Error("InArchive() disagrees with SplitArchivePath()")
+	}
+}
+
+func TestArchiveFormatOf_OnlyRegularFiles(t *testing.T) {
+	dir := t.TempDir()
+	named := filepath.Join(dir, "summer.zip")
+	if err := os.Mkdir(named, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := ArchiveFormatOf(named); got != "" {
+		t.Errorf("ArchiveFormatOf() of a directory = %q, want none", got)
+	}
+	if InArchive(filepath.Join(named, "casual")) {
+		t.Error("InArchive() held for a path through a directory named like an archive")
+	}
+
+	archive := filepath.Join(dir, "winter.tar")
+	if err := os.WriteFile(archive, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got := ArchiveFormatOf(archive); got != ArchiveTar {
+		t.Errorf("ArchiveFormatOf() of an archive = %q, want %q", got, ArchiveTar)
+	}
+}
@@ -0,0 +1,57 @@
+package entities
+
+import (
+	"sort"
+	"time"
+)
+
+// BackupKind tells automatic snapshots from backups the user asked for.
+type BackupKind string
+
+const (
+	// BackupManual is a backup created with backup create.
+	BackupManual BackupKind = "backup"
+	// BackupSnapshot is taken automatically before a destructive change.
+	BackupSnapshot BackupKind = "snapshot"
+)
+
+// BackupInfo describes a stored backup archive.
+type BackupInfo struct {
+	Name string
+	Path string
+	Kind BackupKind
+	// Reason names the change a snapshot was taken before.
+	Reason    string
+	CreatedAt time.Time
+	Size      int64
+}
+
+// BackupContents is the state a backup archive holds. Cache is nil when no
+// outfit had been worn or the cache could not be read.
+type BackupContents struct {
+	Config *Config
+	Cache  *OutfitCache
+}
+
+// SortBackupsNewestFirst orders backups by creation time, newest first.
+func SortBackupsNewestFirst(backups []BackupInfo) {
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+}
+
+// BackupsBeyond returns the backups of each kind after the newest keep, the
+// ones pruning removes.
+func BackupsBeyond(backups []BackupInfo, keep int) []BackupInfo {
+	sorted := append([]BackupInfo(nil), backups...)
+	SortBackupsNewestFirst(sorted)
+	seen := map[BackupKind]int{}
+	var beyond []BackupInfo
+	for _, backup := range sorted {
+		seen[backup.Kind]++
+		if seen[backup.Kind] > keep {
+			beyond = append(beyond, backup)
+		}
+	}
+	return beyond
+}
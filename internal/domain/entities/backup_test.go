@@ -0,0 +1,42 @@
+package entities
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBackupsBeyond(t *testing.T) {
+	start := time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC)
+	backup := func(name string, kind BackupKind, hours int) BackupInfo {
+		return BackupInfo{Name: name, Kind: kind, CreatedAt: start.Add(time.Duration(hours) * time.Hour)}
+	}
+	backups := []BackupInfo{
+		backup("backup-old", BackupManual, 0),
+		backup("snapshot-new", BackupSnapshot, 3),
+		backup("backup-new", BackupManual, 2),
+		backup("snapshot-old", BackupSnapshot, 1),
+	}
+
+	names := func(backups []BackupInfo) []string {
+		var names []string
+		for _, backup := range backups {
+			names = append(names, backup.Name)
+		}
+		return names
+	}
+	if got := names(BackupsBeyond(backups, 1)); !reflect.DeepEqual(got, []string{"snapshot-old", "backup-old"}) {
+		t.Fatalf("BackupsBeyond(1) = %v, want the older backup and snapshot", got)
+	}
+	if got := BackupsBeyond(backups, 2); got != nil {
+		t.Fatalf("BackupsBeyond(2) = %v, want none", got)
+	}
+	if backups[0].Name != "backup-old" {
+		t.Fatalf("BackupsBeyond() reordered its argument: %v", names(backups))
+	}
+
+	SortBackupsNewestFirst(backups)
+	if got := names(backups); !reflect.DeepEqual(got, []string{"snapshot-new", "backup-new", "snapshot-old", "backup-old"}) {
+		t.Fatalf("SortBackupsNewestFirst() = %v", got)
+	}
+}
@@ -1,6 +1,12 @@
 package entities
 
-import "time"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+)
 
 // CategoryCache tracks worn outfits for a single category.
 type CategoryCache struct {
@@ -31,6 +37,21 @@ func (c CategoryCache) RotationProgress() float64 {
 	return float64(len(c.WornOutfits)) / float64(c.TotalOutfits)
 }
 
+// WornRevision identifies the set of worn outfits, so that a change made to
+// them after they were shown can be told apart. Totals and timestamps do not
+// affect it.
+func (c CategoryCache) WornRevision() string {
+	keys := make([]string, 0, len(c.WornOutfits))
+	for key, worn := range c.WornOutfits {
+		if worn {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	sum := sha256.Sum256([]byte(strings.Join(keys, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
 // RemainingOutfits returns the number of unworn outfits.
 func (c CategoryCache) RemainingOutfits() int {
 	remaining := c.TotalOutfits - len(c.WornOutfits)
@@ -42,6 +63,11 @@ func (c CategoryCache) RemainingOutfits() int {
 
 // Adding returns a new cache with the outfit marked as worn.
 func (c CategoryCache) Adding(fileName string) CategoryCache {
+	return c.AddingAt(fileName, time.Now())
+}
+
+// AddingAt returns a new cache with the outfit marked as worn at the time at.
+func (c CategoryCache) AddingAt(fileName string, at time.Time) CategoryCache {
 	if c.WornOutfits[fileName] {
 		return c
 	}
@@ -50,6 +76,24 @@ func (c CategoryCache) Adding(fileName string) CategoryCache {
 		newWorn[k] = v
 	}
 	newWorn[fileName] = true
+	return CategoryCache{
+		WornOutfits:  newWorn,
+		TotalOutfits: c.TotalOutfits,
+		LastUpdated:  at,
+	}
+}
+
+// Removing returns a new cache without the outfit marked as worn.
+func (c CategoryCache) Removing(fileName string) CategoryCache {
+	if !c.WornOutfits[fileName] {
+		return c
+	}
+	newWorn := make(map[string]bool, len(c.WornOutfits))
+	for k, v := range c.WornOutfits {
+		if k != fileName {
+			newWorn[k] = v
+		}
+	}
 	return CategoryCache{
 		WornOutfits:  newWorn,
 		TotalOutfits: c.TotalOutfits,
@@ -67,6 +111,17 @@ type OutfitCache struct {
 	Categories map[string]CategoryCache `json:"categories"`
 	Version    int                      `json:"version"`
 	CreatedAt  time.Time                `json:"createdAt"`
+	// Revision increases with every save. A save based on an older revision
+	// than the stored one is rejected with ErrStaleRevision.
+	Revision uint64 `json:"revision,omitempty"`
+	// Root is the canonical wardrobe root Categories belong to. Caches
+	// written before history was kept per root leave it empty; theirs belongs
+	// to the configured root.
+	Root string `json:"root,omitempty"`
+	// OtherRoots keeps the categories of wardrobe roots that are not
+	// configured at the moment, by canonical root, so that switching back to
+	// one restores its rotation.
+	OtherRoots map[string]map[string]CategoryCache `json:"otherRoots,omitempty"`
 }
 
 // NewOutfitCache creates a new outfit cache.
@@ -89,6 +144,9 @@ func (o OutfitCache) Updating(path string, cache CategoryCache) OutfitCache {
 		Categories: newCategories,
 		Version:    o.Version,
 		CreatedAt:  o.CreatedAt,
+		Revision:   o.Revision,
+		Root:       o.Root,
+		OtherRoots: o.OtherRoots,
 	}
 }
 
@@ -107,6 +165,9 @@ func (o OutfitCache) Removing(path string) OutfitCache {
 		Categories: newCategories,
 		Version:    o.Version,
 		CreatedAt:  o.CreatedAt,
+		Revision:   o.Revision,
+		Root:       o.Root,
+		OtherRoots: o.OtherRoots,
 	}
 }
 
@@ -130,5 +191,145 @@ func (o OutfitCache) ResetAll() OutfitCache {
 		Categories: newCategories,
 		Version:    o.Version,
 		CreatedAt:  o.CreatedAt,
+		Revision:   o.Revision,
+		Root:       o.Root,
+		OtherRoots: o.OtherRoots,
+	}
+}
+
+// Clearing returns a new cache with no worn outfits for the current root,
+// keeping the history of other roots.
+func (o OutfitCache) Clearing() OutfitCache {
+	cleared := o
+	cleared.Categories = make(map[string]CategoryCache)
+	return cleared
+}
+
+// SwitchingRoot returns a new cache for the wardrobe at root to. The current
+// categories are kept for root from, or for o.Root when it is set, and the
+// categories kept for to, if any, become current. When the wardrobe root
+// promoted, which was another of the wardrobe's roots, becomes the first
+// root, the outfits worn from it are not kept for from but stay current,
+// keyed as outfits of the first root.
+func (o OutfitCache) SwitchingRoot(from, to, promoted string) OutfitCache {
+	if o.Root != "" {
+		from = o.Root
+	}
+	if from == to {
+		return o.promotingRoot(promoted)
+	}
+	current, promotedCategories := splitRoot(o.Categories, promoted)
+	others := make(map[string]map[string]CategoryCache, len(o.OtherRoots)+1)
+	for root, categories := range o.OtherRoots {
+		others[root] = categories
+	}
+	if len(current) > 0 && from != "" {
+		others[from] = current
+	}
+	switched := o
+	switched.Categories = mergeCategories(others[to], promotedCategories)
+	delete(others, to)
+	if len(others) == 0 {
+		others = nil
+	}
+	switched.OtherRoots = others
+	switched.Root = to
+	return switched
+}
+
+// MovingRoot returns a new cache whose current categories belong to the
+// wardrobe at root to, for a wardrobe that has moved there. Anything kept
+// for to before is replaced. The outfits worn from promoted, as in
+// SwitchingRoot, are keyed as outfits of the first root.
+func (o OutfitCache) MovingRoot(to, promoted string) OutfitCache {
+	moved := o.promotingRoot(promoted)
+	moved.Root = to
+	if _, ok := o.OtherRoots[to]; ok {
+		others := make(map[string]map[string]CategoryCache, len(o.OtherRoots))
+		for root, categories := range o.OtherRoots {
+			if root != to {
+				others[root] = categories
+			}
+		}
+		if len(others) == 0 {
+			others = nil
+		}
+		moved.OtherRoots = others
+	}
+	return moved
+}
+
+// promotingRoot returns a new cache whose current outfits worn from the
+// wardrobe root root are keyed as outfits of the first root.
+func (o OutfitCache) promotingRoot(root string) OutfitCache {
+	rest, promoted := splitRoot(o.Categories, root)
+	if len(promoted) == 0 {
+		return o
+	}
+	updated := o
+	updated.Categories = mergeCategories(rest, promoted)
+	return updated
+}
+
+// splitRoot takes the outfits worn from the wardrobe root root out of
+// categories, returning the categories left and, keyed by file name, the
+// outfits taken. Categories is left unchanged.
+func splitRoot(categories map[string]CategoryCache, root string) (rest, taken map[string]CategoryCache) {
+	if root == "" {
+		return categories, nil
+	}
+	suffix := OutfitKey(root, "")
+	rest = make(map[string]CategoryCache, len(categories))
+	for name, category := range categories {
+		kept, moved := make(map[string]bool), make(map[string]bool)
+		for key, worn := range category.WornOutfits {
+			if fileName, ok := strings.CutSuffix(key, suffix); ok && fileName != "" {
+				moved[fileName] = worn
+			} else {
+				kept[key] = worn
+			}
+		}
+		if len(moved) == 0 {
+			rest[name] = category
+			continue
+		}
+		if taken == nil {
+			taken = make(map[string]CategoryCache)
+		}
+		taken[name] = CategoryCache{WornOutfits: moved, TotalOutfits: category.TotalOutfits, LastUpdated: category.LastUpdated}
+		if len(kept) > 0 {
+			category.WornOutfits = kept
+			rest[name] = category
+		}
+	}
+	return rest, taken
+}
+
+// mergeCategories returns the categories of into with the worn outfits of
+// from added. Neither is changed.
+func mergeCategories(into, from map[string]CategoryCache) map[string]CategoryCache {
+	merged := make(map[string]CategoryCache, len(into)+len(from))
+	for name, category := range into {
+		merged[name] = category
+	}
+	for name, added := range from {
+		category, ok := merged[name]
+		if !ok {
+			merged[name] = added
+			continue
+		}
+		worn := make(map[string]bool, len(category.WornOutfits)+len(added.WornOutfits))
+		for key, value := range category.WornOutfits {
+			worn[key] = value
+		}
+		for key, value := range added.WornOutfits {
+			worn[key] = worn[key] || value
+		}
+		category.WornOutfits = worn
+		if added.LastUpdated.After(category.LastUpdated) {
+			category.LastUpdated = added.LastUpdated
+		}
+		merged[name] = category
 	}
+	return merged
 }
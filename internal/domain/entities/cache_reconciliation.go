@@ -0,0 +1,40 @@
+package entities
+
+// CategoryReconciliation is what reconciling one category's cache with the
+// wardrobe found.
+type CategoryReconciliation struct {
+	Category string
+	// OldTotal is the recorded outfit count and NewTotal the actual one.
+	OldTotal int
+	NewTotal int
+	// Orphans are worn outfits whose files no longer exist.
+	Orphans []string
+	// Removed is set when the category is no longer in the wardrobe.
+	Removed bool
+}
+
+// CacheReconciliation lists the categories whose cache did not match the
+// wardrobe, in name order.
+type CacheReconciliation struct {
+	Categories []CategoryReconciliation
+	// Unreadable lists the wardrobe roots or categories that could not be
+	// read. Nothing is reconciled while there are any, because their
+	// outfits would look deleted.
+	Unreadable []string
+}
+
+// Changed reports whether any category differed from the wardrobe.
+func (r CacheReconciliation) Changed() bool {
+	return len(r.Categories) > 0
+}
+
+// DropsWornOutfits reports whether reconciling forgets any worn outfit, rather
+// than only correcting outfit counts.
+func (r CacheReconciliation) DropsWornOutfits() bool {
+	for _, category := range r.Categories {
+		if len(category.Orphans) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package entities
+
+import "testing"
+
+func TestCacheReconciliation_Changed(t *testing.T) {
+	if (CacheReconciliation{Unreadable: []string{"/nas"}}).Changed() {
+		t.Error("Changed() = true with no categories, want false")
+	}
+	if !(CacheReconciliation{Categories: []CategoryReconciliation{{Category: "casual", OldTotal: 1, NewTotal: 2}}}).Changed() {
+		t.Error("Changed() = false with a category, want true")
+	}
+}
+
+func TestCacheReconciliation_DropsWornOutfits(t *testing.T) {
+	totals := CacheReconciliation{Categories: []CategoryReconciliation{
+		{Category: "casual", OldTotal: 1, NewTotal: 2},
+		{Category: "hats", Removed: true},
+	}}
+	if totals.DropsWornOutfits() {
+		t.Error("DropsWornOutfits() = true for count corrections, want false")
+	}
+	totals.Categories = append(totals.Categories, CategoryReconciliation{Category: "formal", Orphans: []string{"suit.avatar"}})
+	if !totals.DropsWornOutfits() {
+		t.Error("DropsWornOutfits() = false with an orphaned outfit, want true")
+	}
+}
@@ -3,6 +3,7 @@ package entities
 import (
 	"encoding/json"
 	"testing"
+	"time"
 )
 
 func TestNewCategoryCache(t *testing.T) {
@@ -65,6 +66,28 @@ func TestCategoryCache_Adding(t *testing.T) {
 	if len(sameAgain.WornOutfits) != 1 {
 		t.Error("Adding same outfit twice should not increase count")
 	}
+
+	at := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	if earlier := updated.AddingAt("outfit2.avatar", at); !earlier.LastUpdated.Equal(at) || len(earlier.WornOutfits) != 2 {
+		t.Errorf("AddingAt() = %+v, want outfit2.avatar worn at %v", earlier, at)
+	}
+}
+
+func TestCategoryCache_RemovingOutfit(t *testing.T) {
+	cache := NewCategoryCache(5).
+		Adding("outfit1.avatar").
+		Adding("outfit2.avatar")
+
+	updated := cache.Removing("outfit1.avatar")
+	if len(updated.WornOutfits) != 1 || !updated.WornOutfits["outfit2.avatar"] {
+		t.Errorf("WornOutfits = %v, want only outfit2.avatar", updated.WornOutfits)
+	}
+	if !cache.WornOutfits["outfit1.avatar"] {
+		t.Error("Removing should not change the original cache")
+	}
+	if unchanged := updated.Removing("outfit1.avatar"); len(unchanged.WornOutfits) != 1 {
+		t.Error("Removing an outfit that is not worn should change nothing")
+	}
 }
 
 func TestCategoryCache_Reset(t *testing.T) {
@@ -81,6 +104,21 @@ func TestCategoryCache_Reset(t *testing.T) {
 	}
 }
 
+func TestCategoryCache_WornRevision(t *testing.T) {
+	worn := NewCategoryCache(3).Adding("one.avatar").Adding("two.avatar")
+	same := CategoryCache{WornOutfits: map[string]bool{"two.avatar": true, "one.avatar": true, "three.avatar": false}, TotalOutfits: 5}
+
+	if worn.WornRevision() != same.WornRevision() {
+		t.Fatal("WornRevision() differs for the same worn outfits")
+	}
+	if worn.WornRevision() == worn.Adding("three.avatar").WornRevision() || worn.WornRevision() == worn.Removing("one.avatar").WornRevision() {
+		t.Fatal("WornRevision() unchanged after the worn outfits changed")
+	}
+	if (CategoryCache{}).WornRevision() != NewCategoryCache(2).WornRevision() {
+		t.Fatal("WornRevision() differs between empty categories")
+	}
+}
+
 func TestCategoryCache_RemainingOutfits(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -269,3 +307,102 @@ func TestOutfitCache_JSONMarshaling(t *testing.T) {
 		t.Errorf("Categories length = %v, want %v", len(unmarshaled.Categories), len(cache.Categories))
 	}
 }
+
+func TestOutfitCache_CopiesKeepRevision(t *testing.T) {
+	cache := NewOutfitCache().Updating("casual", NewCategoryCache(2).Adding("a.avatar"))
+	cache.Revision = 7
+	cache.Root = "/work"
+	cache.OtherRoots = map[string]map[string]CategoryCache{"/home": {"formal": NewCategoryCache(1)}}
+
+	copies := map[string]OutfitCache{
+		"Updating": cache.Updating("formal", NewCategoryCache(1)),
+		"Removing": cache.Removing("casual"),
+		"ResetAll": cache.ResetAll(),
+		"Clearing": cache.Clearing(),
+	}
+	for name, copy := range copies {
+		if copy.Revision != 7 || copy.Root != "/work" || len(copy.OtherRoots["/home"]) != 1 {
+			t.Errorf("%s() = %+v, want revision 7 and the root history kept", name, copy)
+		}
+	}
+	if cleared := cache.Clearing(); len(cleared.Categories) != 0 || len(cache.Categories) != 1 {
+		t.Errorf("Clearing() = %+v, want no categories and the original unchanged", cleared)
+	}
+}
+
+func TestOutfitCache_SwitchingRoot(t *testing.T) {
+	work := NewOutfitCache().Updating("casual", NewCategoryCache(2).Adding("a.avatar"))
+
+	atHome := work.SwitchingRoot("/work", "/home", "")
+	if atHome.Root != "/home" || len(atHome.Categories) != 0 || !atHome.OtherRoots["/work"]["casual"].WornOutfits["a.avatar"] {
+		t.Fatalf("SwitchingRoot(/work, /home) = %+v, want an empty rotation with /work kept", atHome)
+	}
+	if work.Root != "" || work.OtherRoots != nil {
+		t.Fatalf("SwitchingRoot() changed the original: %+v", work)
+	}
+	atHome = atHome.Updating("formal", NewCategoryCache(1).Adding("b.avatar"))
+
+	back := atHome.SwitchingRoot("/ignored", "/work", "")
+	if back.Root != "/work" || !back.Categories["casual"].WornOutfits["a.avatar"] || len(back.OtherRoots) != 1 || len(back.OtherRoots["/home"]) != 1 {
+		t.Fatalf("SwitchingRoot(back) = %+v, want the /work rotation restored and /home kept", back)
+	}
+	if same := back.SwitchingRoot("", "/work", ""); same.Root != "/work" || len(same.OtherRoots) != 1 {
+		t.Fatalf("SwitchingRoot() to the current root = %+v, want it unchanged", same)
+	}
+
+	empty := NewOutfitCache().SwitchingRoot("/work", "/home", "")
+	if empty.OtherRoots != nil || empty.Root != "/home" {
+		t.Fatalf("SwitchingRoot() of empty cache = %+v, want nothing kept", empty)
+	}
+	unknown := work.SwitchingRoot("", "/home", "")
+	if unknown.OtherRoots != nil || len(unknown.Categories) != 0 {
+		t.Fatalf("SwitchingRoot() from an unknown root = %+v, want nothing kept", unknown)
+	}
+}
+
+func TestOutfitCache_PromotingAnotherRoot(t *testing.T) {
+	work := NewOutfitCache().Updating("casual", NewCategoryCache(3).Adding("a.avatar").Adding(OutfitKey("/nas", "b.avatar")).Adding(OutfitKey("/usb", "c.avatar")))
+	work = work.Updating("formal", NewCategoryCache(1).Adding(OutfitKey("/nas", "suit.avatar")))
+	work.Root = "/work"
+
+	atNAS := work.SwitchingRoot("", "/nas", "/nas")
+	if casual := atNAS.Categories["casual"].WornOutfits; len(casual) != 1 || !casual["b.avatar"] || !atNAS.Categories["formal"].WornOutfits["suit.avatar"] {
+		t.Fatalf("SwitchingRoot() categories = %+v, want the outfits of /nas keyed by file name", atNAS.Categories)
+	}
+	kept := atNAS.OtherRoots["/work"]
+	if worn := kept["casual"].WornOutfits; len(worn) != 2 || !worn["a.avatar"] || !worn[OutfitKey("/usb", "c.avatar")] {
+		t.Fatalf("SwitchingRoot() kept %+v for /work, want its other outfits", kept)
+	}
+	if _, ok := kept["formal"]; ok {
+		t.Fatalf("SwitchingRoot() kept %+v for /work, want formal, worn only from /nas, gone", kept)
+	}
+	if !work.Categories["casual"].WornOutfits[OutfitKey("/nas", "b.avatar")] {
+		t.Fatal("SwitchingRoot() changed the original")
+	}
+
+	moved := work.MovingRoot("/nas", "/nas")
+	if casual := moved.Categories["casual"].WornOutfits; len(casual) != 3 || !casual["a.avatar"] || !casual["b.avatar"] || moved.Root != "/nas" {
+		t.Fatalf("MovingRoot() = %+v, want the outfits of /nas keyed by file name with the rest", moved)
+	}
+}
+
+func TestOutfitCache_MovingRoot(t *testing.T) {
+	cache := NewOutfitCache().Updating("casual", NewCategoryCache(2).Adding("a.avatar"))
+	cache.Root = "/old"
+	cache.OtherRoots = map[string]map[string]CategoryCache{"/new": {}, "/home": {}}
+
+	moved := cache.MovingRoot("/new", "")
+	if moved.Root != "/new" || !moved.Categories["casual"].WornOutfits["a.avatar"] {
+		t.Fatalf("MovingRoot() = %+v, want the rotation carried to /new", moved)
+	}
+	if _, kept := moved.OtherRoots["/new"]; kept || len(moved.OtherRoots) != 1 || len(cache.OtherRoots) != 2 {
+		t.Fatalf("MovingRoot() other roots = %v, want /new replaced and the original unchanged", moved.OtherRoots)
+	}
+	cache.OtherRoots = map[string]map[string]CategoryCache{"/new": {}}
+	if moved := cache.MovingRoot("/new", ""); moved.OtherRoots != nil {
+		t.Fatalf("MovingRoot() other roots = %v, want none", moved.OtherRoots)
+	}
+	if moved := cache.MovingRoot("/elsewhere", ""); len(moved.OtherRoots) != 1 {
+		t.Fatalf("MovingRoot() other roots = %v, want them kept", moved.OtherRoots)
+	}
+}
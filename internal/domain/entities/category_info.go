@@ -8,13 +8,48 @@ const (
 	CategoryStateEmpty         CategoryState = "empty"
 	CategoryStateNoAvatarFiles CategoryState = "noAvatarFiles"
 	CategoryStateUserExcluded  CategoryState = "userExcluded"
+	// CategoryStateTimedOut is a category whose directory did not answer
+	// within the scanner's timeout, such as one on a hung network mount.
+	CategoryStateTimedOut CategoryState = "timedOut"
+	// CategoryStateUnreadable is a category whose directory could not be
+	// read for any other reason.
+	CategoryStateUnreadable CategoryState = "unreadable"
+	// CategoryStatePermissionDenied is a category whose directory the user
+	// may not read.
+	CategoryStatePermissionDenied CategoryState = "permissionDenied"
+	// CategoryStateSymlinkRejected is a category that is a symbolic link,
+	// which is not followed unless the config asks for links to be.
+	CategoryStateSymlinkRejected CategoryState = "symlinkRejected"
+	// CategoryStateSymlinkLoop is a category that is a symbolic link leading
+	// back to itself or to the wardrobe it is in.
+	CategoryStateSymlinkLoop CategoryState = "symlinkLoop"
+	// CategoryStateSymlinkOutsideRoots is a category that is a symbolic link
+	// leading outside the roots links may be followed into.
+	CategoryStateSymlinkOutsideRoots CategoryState = "symlinkOutsideRoots"
 )
 
+// Failed reports whether the state is one of a category that could not be
+// read.
+func (s CategoryState) Failed() bool {
+	switch s {
+	case CategoryStateTimedOut, CategoryStateUnreadable, CategoryStatePermissionDenied,
+		CategoryStateSymlinkRejected, CategoryStateSymlinkLoop, CategoryStateSymlinkOutsideRoots:
+		return true
+	default:
+		return false
+	}
+}
+
 // CategoryInfo combines a category with its current state information.
 type CategoryInfo struct {
 	Category    CategoryReference `json:"category"`
 	State       CategoryState     `json:"state"`
 	OutfitCount int               `json:"outfitCount"`
+	// Error describes why the category could not be read, if it could not.
+	Error string `json:"error,omitempty"`
+	// LinkTarget is where the category's directory leads when it is a
+	// symbolic link that was followed.
+	LinkTarget string `json:"linkTarget,omitempty"`
 }
 
 // NewCategoryInfo creates a new category info.
@@ -25,3 +60,10 @@ func NewCategoryInfo(category CategoryReference, state CategoryState, outfitCoun
 		OutfitCount: outfitCount,
 	}
 }
+
+// WithError returns a copy of the info recording why the category could not
+// be read.
+func (info CategoryInfo) WithError(err error) CategoryInfo {
+	info.Error = err.Error()
+	return info
+}
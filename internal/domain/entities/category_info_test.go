@@ -2,6 +2,7 @@ package entities
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
 )
 
@@ -14,6 +15,12 @@ func TestCategoryState_JSONMarshaling(t *testing.T) {
 		{"empty", CategoryStateEmpty},
 		{"no avatar files", CategoryStateNoAvatarFiles},
 		{"user excluded", CategoryStateUserExcluded},
+		{"timed out", CategoryStateTimedOut},
+		{"unreadable", CategoryStateUnreadable},
+		{"permission denied", CategoryStatePermissionDenied},
+		{"symlink rejected", CategoryStateSymlinkRejected},
+		{"symlink loop", CategoryStateSymlinkLoop},
+		{"symlink outside roots", CategoryStateSymlinkOutsideRoots},
 	}
 
 	for _, tt := range tests {
@@ -68,3 +75,27 @@ func TestCategoryInfo_JSONMarshaling(t *testing.T) {
 		t.Errorf("round-trip failed: got %v, want %v", unmarshaled, info)
 	}
 }
+
+func TestCategoryInfo_WithError(t *testing.T) {
+	ref := NewCategoryReference("nas", "/Volumes/nas/nas")
+	info := NewCategoryInfo(ref, CategoryStateTimedOut, 0)
+
+	failed := info.WithError(errors.New("reading /Volumes/nas/nas timed out"))
+
+	if failed.Error != "reading /Volumes/nas/nas timed out" || info.Error != "" {
+		t.Errorf("WithError() = %q, original %q; want the error on the copy only", failed.Error, info.Error)
+	}
+}
+
+func TestCategoryState_Failed(t *testing.T) {
+	for _, state := range []CategoryState{CategoryStateTimedOut, CategoryStateUnreadable, CategoryStatePermissionDenied, CategoryStateSymlinkRejected, CategoryStateSymlinkLoop, CategoryStateSymlinkOutsideRoots} {
+		if !state.Failed() {
+			t.Errorf("%s.Failed() = false, want true", state)
+		}
+	}
+	for _, state := range []CategoryState{CategoryStateHasOutfits, CategoryStateEmpty, CategoryStateNoAvatarFiles, CategoryStateUserExcluded} {
+		if state.Failed() {
+			t.Errorf("%s.Failed() = true, want false", state)
+		}
+	}
+}
@@ -6,6 +6,10 @@ type CategoryOutfitState struct {
 	AllOutfits       []OutfitReference
 	AvailableOutfits []OutfitReference
 	WornOutfits      []OutfitReference
+	// WornRevision is the category's worn revision in the outfit cache the
+	// state was read from, so callers can tell when its worn outfits have
+	// changed since.
+	WornRevision string
 }
 
 // NewCategoryOutfitState creates a new category outfit state.
@@ -21,6 +25,12 @@ func NewCategoryOutfitState(
 	}
 }
 
+// WithWornRevision returns a copy of the state read at worn revision.
+func (c CategoryOutfitState) WithWornRevision(revision string) CategoryOutfitState {
+	c.WornRevision = revision
+	return c
+}
+
 func (c CategoryOutfitState) TotalCount() int {
 	return len(c.AllOutfits)
 }
@@ -104,3 +104,11 @@ func TestCategoryOutfitState_IsRotationComplete(t *testing.T) {
 		})
 	}
 }
+
+func TestCategoryOutfitState_WithWornRevision(t *testing.T) {
+	state := NewCategoryOutfitState(NewCategoryReference("casual", "/wardrobe/casual"), nil, nil, nil)
+	stamped := state.WithWornRevision("abc")
+	if stamped.WornRevision != "abc" || state.WornRevision != "" {
+		t.Fatalf("WornRevision = %q (original %q), want abc (original empty)", stamped.WornRevision, state.WornRevision)
+	}
+}
@@ -1,6 +1,7 @@
 package entities
 
 import (
+	"path/filepath"
 	"strings"
 
 	"github.com/dh85/outfitpicker/internal/domain/errors"
@@ -9,13 +10,41 @@ import (
 
 const DefaultLanguage = "en"
 
+// PortableDirName is the directory inside a wardrobe root that holds its
+// config and worn outfits in portable mode. It is never a category.
+const PortableDirName = ".outfitpicker"
+
 // Config represents the application configuration.
 type Config struct {
-	Root               string                     `json:"root"`
-	Language           string                     `json:"language"`
-	ExcludedCategories map[string]bool            `json:"excludedCategories"`
-	KnownCategories    map[string]bool            `json:"knownCategories"`
-	KnownCategoryFiles map[string]map[string]bool `json:"knownCategoryFiles"`
+	// Version is the schema version the configuration was stored with. The
+	// repository stamps the current one on every save.
+	Version int    `json:"version"`
+	Root    string `json:"root"`
+	// Roots lists every wardrobe root when there is more than one, starting
+	// with Root. Categories of the same name are merged across them.
+	Roots              []string                  `json:"roots,omitempty"`
+	Language           string                    `json:"language"`
+	ExcludedCategories map[string]bool           `json:"excludedCategories"`
+	Slots              map[string]ActivationSlot `json:"slots,omitempty"`
+	Hooks              map[HookEventName]Hook    `json:"hooks,omitempty"`
+	SelectionPlugin    *SelectionPlugin          `json:"selectionPlugin,omitempty"`
+	// Storage selects where config and cache are kept. When it is sqlite,
+	// config.json only records this selection.
+	Storage StorageBackend `json:"storage,omitempty"`
+	// Identity selects how worn outfits are matched to their files. Empty
+	// means by file name.
+	Identity OutfitIdentity `json:"identity,omitempty"`
+	// PreferNewArrivals picks new arrivals before other outfits.
+	PreferNewArrivals bool `json:"preferNewArrivals,omitempty"`
+	// FollowSymlinks lets wardrobe roots and categories be symbolic links,
+	// such as category folders that lead to shared packs.
+	FollowSymlinks bool `json:"followSymlinks,omitempty"`
+	// SymlinkRoots, when set, are the directories followed symbolic links
+	// must lead into.
+	SymlinkRoots []string `json:"symlinkRoots,omitempty"`
+	// Revision increases with every save. A save based on an older revision
+	// than the stored one is rejected with ErrStaleRevision.
+	Revision uint64 `json:"revision,omitempty"`
 }
 
 // NewConfig creates and validates a new configuration.
@@ -23,8 +52,6 @@ func NewConfig(
 	root string,
 	language *string,
 	excludedCategories map[string]bool,
-	knownCategories map[string]bool,
-	knownCategoryFiles map[string]map[string]bool,
 ) (*Config, error) {
 	// Validate root is not empty
 	if strings.TrimSpace(root) == "" {
@@ -51,18 +78,172 @@ func NewConfig(
 	if excludedCategories == nil {
 		excludedCategories = make(map[string]bool)
 	}
-	if knownCategories == nil {
-		knownCategories = make(map[string]bool)
-	}
-	if knownCategoryFiles == nil {
-		knownCategoryFiles = make(map[string]map[string]bool)
-	}
 
 	return &Config{
 		Root:               root,
 		Language:           lang,
 		ExcludedCategories: excludedCategories,
-		KnownCategories:    knownCategories,
-		KnownCategoryFiles: knownCategoryFiles,
 	}, nil
 }
+
+// WardrobeRoots returns every wardrobe root, starting with Root.
+func (c Config) WardrobeRoots() []string {
+	roots := []string{c.Root}
+	for _, root := range c.Roots {
+		if root != c.Root {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+// WithRoots returns a copy of the configuration that reads outfits from
+// roots, the first of which becomes Root. Repeated roots are dropped.
+func (c Config) WithRoots(roots []string) (*Config, error) {
+	unique := make([]string, 0, len(roots))
+	seen := make(map[string]bool, len(roots))
+	for _, root := range roots {
+		if strings.TrimSpace(root) == "" {
+			return nil, errors.NewInvalidInputError("root directory cannot be empty")
+		}
+		if err := validation.ValidatePathWithPolicy(root, c.SymlinkPolicy()); err != nil {
+			return nil, errors.MapError(err)
+		}
+		if cleaned := filepath.Clean(root); !seen[cleaned] {
+			seen[cleaned] = true
+			unique = append(unique, root)
+		}
+	}
+	if len(unique) == 0 {
+		return nil, errors.NewInvalidInputError("at least one root directory is needed")
+	}
+	c.Root, c.Roots = unique[0], nil
+	if len(unique) > 1 {
+		c.Roots = unique
+	}
+	return &c, nil
+}
+
+// Slot returns the activation slot registered under name.
+func (c Config) Slot(name string) (ActivationSlot, error) {
+	slot, ok := c.Slots[name]
+	if !ok {
+		return ActivationSlot{}, errors.ErrSlotNotFound
+	}
+	return slot, nil
+}
+
+// WithSlot returns a copy of the configuration with slot registered under name.
+func (c Config) WithSlot(name string, slot ActivationSlot) (*Config, error) {
+	if !isValidSlotName(name) {
+		return nil, errors.NewInvalidInputError("slot name cannot be empty or contain path separators")
+	}
+	slots := make(map[string]ActivationSlot, len(c.Slots)+1)
+	for key, value := range c.Slots {
+		slots[key] = value
+	}
+	slots[name] = slot
+	c.Slots = slots
+	return &c, nil
+}
+
+// WithoutSlot returns a copy of the configuration without the named slot.
+func (c Config) WithoutSlot(name string) (*Config, error) {
+	if _, ok := c.Slots[name]; !ok {
+		return nil, errors.ErrSlotNotFound
+	}
+	slots := make(map[string]ActivationSlot, len(c.Slots))
+	for key, value := range c.Slots {
+		if key != name {
+			slots[key] = value
+		}
+	}
+	c.Slots = slots
+	return &c, nil
+}
+
+// Hook returns the hook registered for event, if any.
+func (c Config) Hook(event HookEventName) (Hook, bool) {
+	hook, ok := c.Hooks[event]
+	return hook, ok
+}
+
+// WithHook returns a copy of the configuration with hook registered for event.
+func (c Config) WithHook(event HookEventName, hook Hook) *Config {
+	hooks := make(map[HookEventName]Hook, len(c.Hooks)+1)
+	for key, value := range c.Hooks {
+		hooks[key] = value
+	}
+	hooks[event] = hook
+	c.Hooks = hooks
+	return &c
+}
+
+// WithoutHook returns a copy of the configuration without the hook for event.
+func (c Config) WithoutHook(event HookEventName) (*Config, error) {
+	if _, ok := c.Hooks[event]; !ok {
+		return nil, errors.ErrHookNotFound
+	}
+	hooks := make(map[HookEventName]Hook, len(c.Hooks))
+	for key, value := range c.Hooks {
+		if key != event {
+			hooks[key] = value
+		}
+	}
+	c.Hooks = hooks
+	return &c, nil
+}
+
+// WithSelectionPlugin returns a copy of the configuration that selects
+// outfits with plugin, or uniformly at random when plugin is nil.
+func (c Config) WithSelectionPlugin(plugin *SelectionPlugin) *Config {
+	c.SelectionPlugin = plugin
+	return &c
+}
+
+// WithIdentity returns a copy of the configuration that matches worn outfits
+// to their files by identity.
+func (c Config) WithIdentity(identity OutfitIdentity) *Config {
+	c.Identity = identity
+	return &c
+}
+
+// MatchesContent reports whether worn outfits are also matched by content.
+func (c Config) MatchesContent() bool {
+	return c.Identity == IdentityContent
+}
+
+// WithNewArrivalsPreferred returns a copy of the configuration that picks new
+// arrivals first when prefer is set.
+func (c Config) WithNewArrivalsPreferred(prefer bool) *Config {
+	c.PreferNewArrivals = prefer
+	return &c
+}
+
+// SymlinkPolicy returns how wardrobe paths may go through symbolic links.
+func (c Config) SymlinkPolicy() validation.SymlinkPolicy {
+	return validation.SymlinkPolicy{Follow: c.FollowSymlinks, AllowedRoots: c.SymlinkRoots}
+}
+
+// WithSymlinkPolicy returns a copy of the configuration that follows symbolic
+// links when follow is set, keeping them inside allowedRoots when any are
+// given. It fails when a wardrobe root goes through a link the new policy
+// does not follow.
+func (c Config) WithSymlinkPolicy(follow bool, allowedRoots []string) (*Config, error) {
+	if !follow && len(allowedRoots) > 0 {
+		return nil, errors.NewInvalidInputError("allowed roots only apply when symlinks are followed")
+	}
+	for _, root := range allowedRoots {
+		if strings.TrimSpace(root) == "" {
+			return nil, errors.NewInvalidInputError("allowed root cannot be empty")
+		}
+		if err := validation.ValidatePath(root); err != nil {
+			return nil, errors.MapError(err)
+		}
+	}
+	c.FollowSymlinks, c.SymlinkRoots = follow, nil
+	if len(allowedRoots) > 0 {
+		c.SymlinkRoots = append([]string(nil), allowedRoots...)
+	}
+	return c.WithRoots(c.WardrobeRoots())
+}
@@ -7,14 +7,12 @@ type ConfigBuilder struct {
 	rootPath           *string
 	language           *string
 	excludedCategories map[string]bool
-	knownCategories    map[string]bool
 }
 
 // NewConfigBuilder creates a new ConfigBuilder.
 func NewConfigBuilder() *ConfigBuilder {
 	return &ConfigBuilder{
 		excludedCategories: make(map[string]bool),
-		knownCategories:    make(map[string]bool),
 	}
 }
 
@@ -44,20 +42,6 @@ func (b *ConfigBuilder) ExcludeCategory(category string) *ConfigBuilder {
 	return b
 }
 
-// Include includes multiple categories using variadic parameters.
-func (b *ConfigBuilder) Include(categories ...string) *ConfigBuilder {
-	for _, cat := range categories {
-		b.knownCategories[cat] = true
-	}
-	return b
-}
-
-// IncludeCategory includes a single category.
-func (b *ConfigBuilder) IncludeCategory(category string) *ConfigBuilder {
-	b.knownCategories[category] = true
-	return b
-}
-
 // Build creates a validated Config instance.
 func (b *ConfigBuilder) Build() (*Config, error) {
 	if b.rootPath == nil {
@@ -68,7 +52,5 @@ func (b *ConfigBuilder) Build() (*Config, error) {
 		*b.rootPath,
 		b.language,
 		b.excludedCategories,
-		b.knownCategories,
-		nil,
 	)
 }
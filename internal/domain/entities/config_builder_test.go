@@ -67,39 +67,6 @@ func TestConfigBuilder_ExcludeCategory(t *testing.T) {
 	}
 }
 
-func TestConfigBuilder_IncludeCategories(t *testing.T) {
-	builder := NewConfigBuilder()
-	config, err := builder.
-		RootDirectory("/Users/user/outfits").
-		Include("casual", "formal").
-		Build()
-
-	if err != nil {
-		t.Fatalf("Build() error = %v", err)
-	}
-	if !config.KnownCategories["casual"] {
-		t.Error("KnownCategories should contain 'casual'")
-	}
-	if !config.KnownCategories["formal"] {
-		t.Error("KnownCategories should contain 'formal'")
-	}
-}
-
-func TestConfigBuilder_IncludeCategory(t *testing.T) {
-	builder := NewConfigBuilder()
-	config, err := builder.
-		RootDirectory("/Users/user/outfits").
-		IncludeCategory("casual").
-		Build()
-
-	if err != nil {
-		t.Fatalf("Build() error = %v", err)
-	}
-	if !config.KnownCategories["casual"] {
-		t.Error("KnownCategories should contain 'casual'")
-	}
-}
-
 func TestConfigBuilder_NoRootError(t *testing.T) {
 	builder := NewConfigBuilder()
 	_, err := builder.Build()
@@ -138,9 +105,7 @@ func TestConfigBuilder_Chaining(t *testing.T) {
 		RootDirectory("/Users/user/outfits").
 		Language("fr").
 		Exclude("formal", "winter").
-		Include("casual", "sport").
 		ExcludeCategory("business").
-		IncludeCategory("weekend").
 		Build()
 
 	if err != nil {
@@ -156,7 +121,4 @@ func TestConfigBuilder_Chaining(t *testing.T) {
 	if len(config.ExcludedCategories) != 3 {
 		t.Errorf("ExcludedCategories length = %v, want 3", len(config.ExcludedCategories))
 	}
-	if len(config.KnownCategories) != 3 {
-		t.Errorf("KnownCategories length = %v, want 3", len(config.KnownCategories))
-	}
 }
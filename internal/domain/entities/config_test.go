@@ -2,6 +2,9 @@ package entities
 
 import (
 	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -49,7 +52,7 @@ func TestNewConfig(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			config, err := NewConfig(tt.root, tt.lang, nil, nil, nil)
+			config, err := NewConfig(tt.root, tt.lang, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("NewConfig() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -68,7 +71,7 @@ func TestNewConfig(t *testing.T) {
 
 func TestConfig_WithExcludedCategories(t *testing.T) {
 	excluded := map[string]bool{"formal": true, "winter": true}
-	config, err := NewConfig("/Users/user/outfits", nil, excluded, nil, nil)
+	config, err := NewConfig("/Users/user/outfits", nil, excluded)
 
 	if err != nil {
 		t.Fatalf("NewConfig() error = %v", err)
@@ -82,39 +85,114 @@ func TestConfig_WithExcludedCategories(t *testing.T) {
 	}
 }
 
-func TestConfig_WithKnownCategories(t *testing.T) {
-	known := map[string]bool{"casual": true, "formal": true}
-	config, err := NewConfig("/Users/user/outfits", nil, nil, known, nil)
+func TestConfig_WithNewArrivalsPreferred(t *testing.T) {
+	config, err := NewConfig("/Users/user/outfits", nil, nil)
+	if err != nil {
+		t.Fatalf("NewConfig() error = %v", err)
+	}
+	updated := config.WithNewArrivalsPreferred(true)
+	if !updated.PreferNewArrivals || config.PreferNewArrivals {
+		t.Error("WithNewArrivalsPreferred() changed the original configuration")
+	}
+}
 
+func TestConfig_WithRoots(t *testing.T) {
+	config, err := NewConfig("/Users/user/outfits", nil, nil)
 	if err != nil {
 		t.Fatalf("NewConfig() error = %v", err)
 	}
+	if roots := config.WardrobeRoots(); !reflect.DeepEqual(roots, []string{"/Users/user/outfits"}) {
+		t.Fatalf("WardrobeRoots() = %v, want the root alone", roots)
+	}
+
+	updated, err := config.WithRoots([]string{"/Volumes/nas/outfits", "/Users/user/outfits", "/Volumes/nas/outfits/"})
+	if err != nil {
+		t.Fatalf("WithRoots() error = %v", err)
+	}
+	want := []string{"/Volumes/nas/outfits", "/Users/user/outfits"}
+	if updated.Root != want[0] || !reflect.DeepEqual(updated.Roots, want) || !reflect.DeepEqual(updated.WardrobeRoots(), want) {
+		t.Fatalf("WithRoots() = %+v, want %v with the first as Root", updated, want)
+	}
+	if config.Roots != nil {
+		t.Fatal("WithRoots() changed the original config")
+	}
+
+	single, err := updated.WithRoots([]string{"/Users/user/outfits"})
+	if err != nil || single.Roots != nil || single.Root != "/Users/user/outfits" {
+		t.Fatalf("WithRoots(one) = %+v, %v; want no Roots list", single, err)
+	}
+	if roots := (Config{Root: "/a", Roots: []string{"/b", "/a"}}).WardrobeRoots(); !reflect.DeepEqual(roots, []string{"/a", "/b"}) {
+		t.Fatalf("WardrobeRoots() = %v, want Root first", roots)
+	}
 
-	if len(config.KnownCategories) != 2 {
-		t.Errorf("KnownCategories length = %v, want 2", len(config.KnownCategories))
+	for _, roots := range [][]string{nil, {" "}, {"/Users/user/outfits", "/home/../etc"}} {
+		if _, err := config.WithRoots(roots); err == nil {
+			t.Fatalf("WithRoots(%q) error = nil", roots)
+		}
 	}
 }
 
-func TestConfig_WithKnownCategoryFiles(t *testing.T) {
-	files := map[string]map[string]bool{
-		"casual": {"outfit1.avatar": true, "outfit2.avatar": true},
+func TestConfig_WithSymlinkPolicy(t *testing.T) {
+	workspaceDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd() error = %v", err)
+	}
+	base, err := os.MkdirTemp(workspaceDir, "config-symlinks-")
+	if err != nil {
+		t.Fatalf("MkdirTemp() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.RemoveAll(base)
+	})
+	shared := filepath.Join(base, "shared")
+	if err := os.Mkdir(shared, 0o755); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+	linked := filepath.Join(base, "linked")
+	if err := os.Symlink(shared, linked); err != nil {
+		t.Skipf("os.Symlink() unavailable: %v", err)
 	}
-	config, err := NewConfig("/Users/user/outfits", nil, nil, nil, files)
 
+	config, err := NewConfig("/Users/user/outfits", nil, nil)
 	if err != nil {
 		t.Fatalf("NewConfig() error = %v", err)
 	}
+	if _, err := config.WithRoots([]string{linked}); err == nil {
+		t.Fatal("WithRoots() of a symlinked root succeeded while links are rejected")
+	}
 
-	if len(config.KnownCategoryFiles) != 1 {
-		t.Errorf("KnownCategoryFiles length = %v, want 1", len(config.KnownCategoryFiles))
+	following, err := config.WithSymlinkPolicy(true, []string{base})
+	if err != nil {
+		t.Fatalf("WithSymlinkPolicy() error = %v", err)
+	}
+	if policy := following.SymlinkPolicy(); !policy.Follow || !reflect.DeepEqual(policy.AllowedRoots, []string{base}) {
+		t.Fatalf("SymlinkPolicy() = %+v, want links followed within %s", policy, base)
+	}
+	linkedRoot, err := following.WithRoots([]string{linked})
+	if err != nil {
+		t.Fatalf("WithRoots() of a symlinked root error = %v", err)
+	}
+	if _, err := linkedRoot.WithSymlinkPolicy(true, []string{filepath.Join(base, "elsewhere")}); err == nil {
+		t.Error("WithSymlinkPolicy() kept a root that leads outside the allowed roots")
+	}
+	if _, err := linkedRoot.WithSymlinkPolicy(false, nil); err == nil {
+		t.Error("WithSymlinkPolicy() rejected links while a root is one")
+	}
+	for _, roots := range [][]string{{" "}, {"/home/../etc"}} {
+		if _, err := config.WithSymlinkPolicy(true, roots); err == nil {
+			t.Errorf("WithSymlinkPolicy(%q) error = nil", roots)
+		}
+	}
+	if _, err := config.WithSymlinkPolicy(false, []string{base}); err == nil {
+		t.Error("WithSymlinkPolicy() took allowed roots without following links")
 	}
-	if len(config.KnownCategoryFiles["casual"]) != 2 {
-		t.Errorf("KnownCategoryFiles[casual] length = %v, want 2", len(config.KnownCategoryFiles["casual"]))
+	if rejecting, err := following.WithSymlinkPolicy(false, nil); err != nil || rejecting.FollowSymlinks || rejecting.SymlinkRoots != nil {
+		t.Errorf("WithSymlinkPolicy(false) = %+v, %v; want links rejected again", rejecting, err)
 	}
 }
 
 func TestConfig_JSONMarshaling(t *testing.T) {
-	config, err := NewConfig("/Users/user/outfits", stringPtr("es"), nil, nil, nil)
+	config, err := NewConfig("/Users/user/outfits", stringPtr("es"), nil)
 	if err != nil {
 		t.Fatalf("NewConfig() error = %v", err)
 	}
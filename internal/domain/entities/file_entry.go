@@ -8,6 +8,27 @@ type FileEntry struct {
 	categoryPath string
 	FileName     string
 	IsDirectory  bool
+	// IsSymlink marks a symbolic link; IsDirectory then tells whether it
+	// points at a directory.
+	IsSymlink bool
+	// PreviewFileName names a companion image in the same directory, if any.
+	PreviewFileName string
+	// Root is the wardrobe root the file was found in, when that is not the
+	// first root.
+	Root string
+}
+
+// OutfitKey identifies an outfit among its category's worn outfits. Outfits
+// in the first wardrobe root are keyed by file name, as they were before
+// there could be several roots; outfits in other roots also name their root
+// as configured. When another root becomes the first, the worn and queued
+// outfits keyed by it are keyed afresh by file name, as OutfitCache.
+// SwitchingRoot and WearQueue.PromotingRoot do.
+func OutfitKey(root, fileName string) string {
+	if root == "" {
+		return fileName
+	}
+	return fileName + "@" + root
 }
 
 // NewFileEntry creates a new file entry from a file path.
@@ -32,6 +53,20 @@ func NewFileEntryWithDir(filePath string, isDir bool) FileEntry {
 	}
 }
 
+// Key returns the entry's OutfitKey.
+func (f FileEntry) Key() string {
+	return OutfitKey(f.Root, f.FileName)
+}
+
+// InCategory returns a copy of the entry located in categoryPath of the
+// wardrobe root origin, which is empty for the first root.
+func (f FileEntry) InCategory(categoryPath, origin string) FileEntry {
+	f.filePath = filepath.Join(categoryPath, f.FileName)
+	f.categoryPath = categoryPath
+	f.Root = origin
+	return f
+}
+
 // CategoryPath returns the directory path containing this file.
 func (f FileEntry) CategoryPath() string {
 	return f.categoryPath
@@ -88,3 +88,21 @@ func TestFileEntry_Equality(t *testing.T) {
 		}
 	})
 }
+
+func TestFileEntry_KeyAndInCategory(t *testing.T) {
+	entry := FileEntry{FileName: "look.avatar", PreviewFileName: "look.png"}
+	if entry.Key() != "look.avatar" {
+		t.Fatalf("Key() = %q, want the file name", entry.Key())
+	}
+
+	located := entry.InCategory("/nas/outfits/casual", "/nas/outfits")
+	if located.Key() != "look.avatar@/nas/outfits" || located.CategoryPath() != "/nas/outfits/casual" || located.CategoryName() != "casual" {
+		t.Fatalf("InCategory() = %+v, want it keyed and located in the second root", located)
+	}
+	if located.PreviewFileName != "look.png" || entry.Root != "" {
+		t.Fatalf("InCategory() = %+v from %+v, want a copy keeping the preview", located, entry)
+	}
+	if OutfitKey("", "look.avatar") != "look.avatar" {
+		t.Fatal("OutfitKey() for the first root should be the file name")
+	}
+}
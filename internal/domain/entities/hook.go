@@ -0,0 +1,110 @@
+package entities
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+// HookEventName identifies the point at which a hook runs.
+type HookEventName string
+
+const (
+	HookEventPrePick           HookEventName = "pre-pick"
+	HookEventPostPick          HookEventName = "post-pick"
+	HookEventPostWear          HookEventName = "post-wear"
+	HookEventRotationCompleted HookEventName = "rotation-completed"
+	HookEventReset             HookEventName = "reset"
+)
+
+// HookEventNames lists every event a hook can be registered for.
+var HookEventNames = []HookEventName{
+	HookEventPrePick,
+	HookEventPostPick,
+	HookEventPostWear,
+	HookEventRotationCompleted,
+	HookEventReset,
+}
+
+// DefaultHookTimeout bounds hooks that do not set their own timeout.
+const DefaultHookTimeout = 10 * time.Second
+
+// ParseHookEventName validates name against HookEventNames.
+func ParseHookEventName(name string) (HookEventName, error) {
+	for _, event := range HookEventNames {
+		if string(event) == name {
+			return event, nil
+		}
+	}
+	return "", errors.NewInvalidInputError(fmt.Sprintf("unknown hook event %q", name))
+}
+
+// Hook is a user-defined executable run when an event occurs.
+type Hook struct {
+	Command        string   `json:"command"`
+	Args           []string `json:"args,omitempty"`
+	TimeoutSeconds int      `json:"timeoutSeconds,omitempty"`
+}
+
+// NewHook creates and validates a hook. A zero timeout uses
+// DefaultHookTimeout.
+func NewHook(command string, args []string, timeout time.Duration) (Hook, error) {
+	if strings.TrimSpace(command) == "" {
+		return Hook{}, errors.NewInvalidInputError("hook command cannot be empty")
+	}
+	if timeout < 0 {
+		return Hook{}, errors.NewInvalidInputError("hook timeout cannot be negative")
+	}
+	if timeout%time.Second != 0 {
+		return Hook{}, errors.NewInvalidInputError("hook timeout must be a whole number of seconds")
+	}
+	return Hook{
+		Command:        command,
+		Args:           append([]string(nil), args...),
+		TimeoutSeconds: int(timeout / time.Second),
+	}, nil
+}
+
+// Timeout returns how long the hook may run before it is stopped.
+func (h Hook) Timeout() time.Duration {
+	if h.TimeoutSeconds <= 0 {
+		return DefaultHookTimeout
+	}
+	return time.Duration(h.TimeoutSeconds) * time.Second
+}
+
+// String returns the hook command line for display.
+func (h Hook) String() string {
+	return strings.Join(append([]string{h.Command}, h.Args...), " ")
+}
+
+// HookEvent is the payload passed to a hook on stdin.
+type HookEvent struct {
+	Event      HookEventName `json:"event"`
+	Time       time.Time     `json:"time"`
+	Root       string        `json:"root,omitempty"`
+	Category   string        `json:"category,omitempty"`
+	Outfit     string        `json:"outfit,omitempty"`
+	OutfitPath string        `json:"outfitPath,omitempty"`
+}
+
+// NewHookEvent creates an event payload for the given wardrobe root.
+func NewHookEvent(name HookEventName, root string, at time.Time) HookEvent {
+	return HookEvent{Event: name, Time: at.UTC(), Root: root}
+}
+
+// WithCategory returns a copy of the event scoped to a category.
+func (e HookEvent) WithCategory(category string) HookEvent {
+	e.Category = category
+	return e
+}
+
+// WithOutfit returns a copy of the event describing outfit.
+func (e HookEvent) WithOutfit(outfit OutfitReference) HookEvent {
+	e.Category = outfit.Category.Name
+	e.Outfit = outfit.FileName
+	e.OutfitPath = outfit.FilePath()
+	return e
+}
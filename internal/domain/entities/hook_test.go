@@ -0,0 +1,105 @@
+package entities
+
+import (
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+func TestParseHookEventName(t *testing.T) {
+	for _, event := range HookEventNames {
+		got, err := ParseHookEventName(string(event))
+		if err != nil || got != event {
+			t.Fatalf("ParseHookEventName(%q) = %q, %v", event, got, err)
+		}
+	}
+	if _, err := ParseHookEventName("post-lunch"); err == nil {
+		t.Fatal("ParseHookEventName(post-lunch) error = nil, want error")
+	}
+}
+
+func TestNewHook(t *testing.T) {
+	args := []string{"--quiet"}
+	hook, err := NewHook("notify", args, 30*time.Second)
+	if err != nil {
+		t.Fatalf("NewHook() error = %v", err)
+	}
+	args[0] = "--changed"
+	if hook.Command != "notify" || hook.Args[0] != "--quiet" || hook.Timeout() != 30*time.Second {
+		t.Fatalf("NewHook() = %#v", hook)
+	}
+	if hook.String() != "notify --quiet" {
+		t.Fatalf("String() = %q", hook.String())
+	}
+
+	defaulted, err := NewHook("notify", nil, 0)
+	if err != nil {
+		t.Fatalf("NewHook() error = %v", err)
+	}
+	if defaulted.Timeout() != DefaultHookTimeout {
+		t.Fatalf("Timeout() = %v, want %v", defaulted.Timeout(), DefaultHookTimeout)
+	}
+
+	for _, tt := range []struct {
+		name    string
+		command string
+		timeout time.Duration
+	}{
+		{name: "empty command", command: " "},
+		{name: "negative timeout", command: "notify", timeout: -time.Second},
+		{name: "fractional timeout", command: "notify", timeout: 1500 * time.Millisecond},
+	} {
+		if _, err := NewHook(tt.command, nil, tt.timeout); err == nil {
+			t.Fatalf("%s: NewHook() error = nil, want error", tt.name)
+		}
+	}
+}
+
+func TestHookEvent(t *testing.T) {
+	at := time.Date(2026, 3, 1, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+	outfit := NewOutfitReference("boots.avatar", NewCategoryReference("shoes", "/Users/user/outfits/shoes"))
+
+	event := NewHookEvent(HookEventPostWear, "/Users/user/outfits", at).WithOutfit(outfit)
+
+	if event.Event != HookEventPostWear || !event.Time.Equal(at) || event.Time.Location() != time.UTC {
+		t.Fatalf("NewHookEvent() = %#v", event)
+	}
+	if event.Category != "shoes" || event.Outfit != "boots.avatar" || event.OutfitPath != outfit.FilePath() {
+		t.Fatalf("WithOutfit() = %#v", event)
+	}
+	if reset := NewHookEvent(HookEventReset, "/Users/user/outfits", at).WithCategory("shoes"); reset.Category != "shoes" || reset.Outfit != "" {
+		t.Fatalf("WithCategory() = %#v", reset)
+	}
+}
+
+func TestConfig_Hooks(t *testing.T) {
+	config, err := NewConfig("/Users/user/outfits", nil, nil)
+	if err != nil {
+		t.Fatalf("NewConfig() error = %v", err)
+	}
+	hook := Hook{Command: "notify"}
+
+	if _, ok := config.Hook(HookEventPostWear); ok {
+		t.Fatal("Hook() found a hook in an empty config")
+	}
+	updated := config.WithHook(HookEventPostWear, hook)
+	if got, ok := updated.Hook(HookEventPostWear); !ok || got.Command != "notify" {
+		t.Fatalf("Hook() = %#v, %t", got, ok)
+	}
+	if len(config.Hooks) != 0 {
+		t.Fatalf("WithHook() changed the original config: %v", config.Hooks)
+	}
+
+	removed, err := updated.WithoutHook(HookEventPostWear)
+	if err != nil {
+		t.Fatalf("WithoutHook() error = %v", err)
+	}
+	if len(removed.Hooks) != 0 || len(updated.Hooks) != 1 {
+		t.Fatalf("WithoutHook() hooks = %v, original = %v", removed.Hooks, updated.Hooks)
+	}
+	if _, err := removed.WithoutHook(HookEventPostWear); !stderrors.Is(err, errors.ErrHookNotFound) {
+		t.Fatalf("WithoutHook() error = %v, want ErrHookNotFound", err)
+	}
+}
@@ -0,0 +1,240 @@
+package entities
+
+import (
+	"reflect"
+	"sort"
+	"time"
+)
+
+// JournalEventType names a change recorded in the journal.
+type JournalEventType string
+
+const (
+	// JournalWear marks an outfit worn.
+	JournalWear JournalEventType = "wear"
+	// JournalUnwear marks a worn outfit unworn again.
+	JournalUnwear JournalEventType = "unwear"
+	// JournalReset clears the worn outfits of one category.
+	JournalReset JournalEventType = "reset"
+	// JournalResetAll clears the worn outfits of every category.
+	JournalResetAll JournalEventType = "reset-all"
+	// JournalConfigUpdate replaces the configuration.
+	JournalConfigUpdate JournalEventType = "config"
+	// JournalFactoryReset removes the configuration and all worn outfits.
+	JournalFactoryReset JournalEventType = "factory-reset"
+	// JournalRootSwitch replaces the cache with the whole cache after the
+	// wardrobe root changed, including the history kept for other roots.
+	JournalRootSwitch JournalEventType = "root"
+	// JournalBaseline replaces config and cache with what was stored when
+	// the journal was started.
+	JournalBaseline JournalEventType = "baseline"
+)
+
+// JournalEvent is one entry of the append-only journal of changes to config
+// and cache.
+type JournalEvent struct {
+	Type JournalEventType `json:"type"`
+	At   time.Time        `json:"at"`
+	// Category and Outfit identify what a wear, unwear or reset changed.
+	Category string `json:"category,omitempty"`
+	Outfit   string `json:"outfit,omitempty"`
+	// TotalOutfits is the size of the category when an outfit was worn.
+	TotalOutfits int `json:"totalOutfits,omitempty"`
+	// Config is the whole configuration after a config update, or the one
+	// stored when a baseline was recorded.
+	Config *Config `json:"config,omitempty"`
+	// Cache is the whole cache after a root switch, or the one stored when a
+	// baseline was recorded.
+	Cache *OutfitCache `json:"cache,omitempty"`
+}
+
+// HasBaseline reports whether events start from a recorded baseline, so that
+// replaying them does not lose what was stored before the journal began.
+func HasBaseline(events []JournalEvent) bool {
+	return len(events) > 0 && events[0].Type == JournalBaseline
+}
+
+// JournalState is the config and cache rebuilt by replaying the journal.
+// Config is nil when nothing was configured at that point.
+type JournalState struct {
+	Config *Config
+	Cache  OutfitCache
+}
+
+// ReplayJournal applies events in order, starting from nothing configured and
+// nothing worn. Events after until are ignored unless until is zero.
+func ReplayJournal(events []JournalEvent, until time.Time) JournalState {
+	state := JournalState{Cache: NewOutfitCache()}
+	for _, event := range events {
+		if !until.IsZero() && event.At.After(until) {
+			continue
+		}
+		state = state.Applying(event)
+	}
+	return state
+}
+
+// Applying returns the state after event.
+func (s JournalState) Applying(event JournalEvent) JournalState {
+	switch event.Type {
+	case JournalWear:
+		category, ok := s.Cache.Categories[event.Category]
+		if !ok {
+			category = NewCategoryCache(event.TotalOutfits)
+		}
+		category = category.Adding(event.Outfit)
+		category.TotalOutfits = event.TotalOutfits
+		category.LastUpdated = event.At
+		s.Cache = s.Cache.Updating(event.Category, category)
+	case JournalUnwear:
+		category, ok := s.Cache.Categories[event.Category]
+		if !ok {
+			break
+		}
+		worn := make(map[string]bool, len(category.WornOutfits))
+		for outfit := range category.WornOutfits {
+			if outfit != event.Outfit {
+				worn[outfit] = true
+			}
+		}
+		category.WornOutfits = worn
+		category.LastUpdated = event.At
+		s.Cache = s.Cache.Updating(event.Category, category)
+	case JournalReset:
+		s.Cache = s.Cache.Removing(event.Category)
+	case JournalResetAll:
+		s.Cache = s.Cache.Clearing()
+	case JournalConfigUpdate:
+		if event.Config != nil {
+			config := *event.Config
+			s.Config = &config
+		}
+	case JournalFactoryReset:
+		s.Config = nil
+		s.Cache = emptyCacheLike(s.Cache)
+	case JournalRootSwitch:
+		s.Cache = recordedCache(event.Cache, s.Cache)
+	case JournalBaseline:
+		s.Config = nil
+		if event.Config != nil {
+			config := *event.Config
+			s.Config = &config
+		}
+		s.Cache = recordedCache(event.Cache, emptyCacheLike(s.Cache))
+	}
+	return s
+}
+
+// recordedCache returns the cache an event recorded whole, or fallback when
+// it recorded none.
+func recordedCache(recorded *OutfitCache, fallback OutfitCache) OutfitCache {
+	if recorded == nil {
+		return fallback
+	}
+	cache := *recorded
+	if cache.Categories == nil {
+		cache.Categories = map[string]CategoryCache{}
+	}
+	return cache
+}
+
+func emptyCacheLike(cache OutfitCache) OutfitCache {
+	return OutfitCache{Categories: map[string]CategoryCache{}, Version: cache.Version, CreatedAt: cache.CreatedAt}
+}
+
+// CacheChanges returns the events that turn before into after. A nil before
+// means nothing was stored. A change of wardrobe root, or of the history kept
+// for other roots, is recorded as the whole cache after it.
+func CacheChanges(before, after *OutfitCache, at time.Time) []JournalEvent {
+	var previous map[string]CategoryCache
+	var previousRoot OutfitCache
+	if before != nil {
+		previous = before.Categories
+		previousRoot = *before
+	}
+	var next map[string]CategoryCache
+	if after != nil {
+		next = after.Categories
+		if !sameRoots(previousRoot, *after) {
+			cache := *after
+			cache.Revision = 0
+			return []JournalEvent{{Type: JournalRootSwitch, At: at, Cache: &cache}}
+		}
+	}
+
+	var events []JournalEvent
+	if len(next) == 0 && wornCategories(previous) > 1 {
+		return append(events, JournalEvent{Type: JournalResetAll, At: at})
+	}
+	for _, name := range sortedCacheCategories(previous) {
+		if len(previous[name].WornOutfits) == 0 {
+			continue
+		}
+		category, kept := next[name]
+		if !kept || len(category.WornOutfits) == 0 {
+			events = append(events, JournalEvent{Type: JournalReset, At: at, Category: name})
+			continue
+		}
+		for _, outfit := range sortedWornOutfits(previous[name]) {
+			if !category.WornOutfits[outfit] {
+				events = append(events, JournalEvent{Type: JournalUnwear, At: at, Category: name, Outfit: outfit})
+			}
+		}
+	}
+	for _, name := range sortedCacheCategories(next) {
+		category := next[name]
+		for _, outfit := range sortedWornOutfits(category) {
+			if !previous[name].WornOutfits[outfit] {
+				events = append(events, JournalEvent{
+					Type: JournalWear, At: at, Category: name, Outfit: outfit, TotalOutfits: category.TotalOutfits,
+				})
+			}
+		}
+	}
+	return events
+}
+
+// CacheReplacement returns the events that replace whatever was stored with
+// cache.
+func CacheReplacement(cache *OutfitCache, at time.Time) []JournalEvent {
+	return append([]JournalEvent{{Type: JournalResetAll, At: at}}, CacheChanges(nil, cache, at)...)
+}
+
+// sameRoots reports whether a and b keep their categories for the same
+// wardrobe root and the same history for other roots.
+func sameRoots(a, b OutfitCache) bool {
+	if a.Root != b.Root {
+		return false
+	}
+	return len(a.OtherRoots) == 0 && len(b.OtherRoots) == 0 || reflect.DeepEqual(a.OtherRoots, b.OtherRoots)
+}
+
+func wornCategories(categories map[string]CategoryCache) int {
+	count := 0
+	for _, category := range categories {
+		if len(category.WornOutfits) > 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func sortedCacheCategories(categories map[string]CategoryCache) []string {
+	names := make([]string, 0, len(categories))
+	for name := range categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedWornOutfits(category CategoryCache) []string {
+	outfits := make([]string, 0, len(category.WornOutfits))
+	for outfit, worn := range category.WornOutfits {
+		if worn {
+			outfits = append(outfits, outfit)
+		}
+	}
+	sort.Strings(outfits)
+	return outfits
+}
@@ -0,0 +1,238 @@
+package entities
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var journalTestTime = time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+
+func journalTestCache(categories map[string][]string) *OutfitCache {
+	cache := NewOutfitCache()
+	for name, outfits := range categories {
+		category := NewCategoryCache(3)
+		for _, outfit := range outfits {
+			category = category.Adding(outfit)
+		}
+		cache = cache.Updating(name, category)
+	}
+	return &cache
+}
+
+func journalEventSummary(events []JournalEvent) []string {
+	summary := make([]string, 0, len(events))
+	for _, event := range events {
+		summary = append(summary, string(event.Type)+" "+event.Category+"/"+event.Outfit)
+	}
+	return summary
+}
+
+func TestCacheChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		before *OutfitCache
+		after  *OutfitCache
+		want   []string
+	}{
+		{name: "nothing stored", after: journalTestCache(map[string][]string{"shoes": {"a"}}), want: []string{"wear shoes/a"}},
+		{name: "unchanged", before: journalTestCache(map[string][]string{"shoes": {"a"}}), after: journalTestCache(map[string][]string{"shoes": {"a"}}), want: []string{}},
+		{
+			name:   "wear and unwear",
+			before: journalTestCache(map[string][]string{"shoes": {"a", "b"}}),
+			after:  journalTestCache(map[string][]string{"shoes": {"a", "c"}}),
+			want:   []string{"unwear shoes/b", "wear shoes/c"},
+		},
+		{
+			name:   "category removed",
+			before: journalTestCache(map[string][]string{"shoes": {"a"}, "hats": {"b"}}),
+			after:  journalTestCache(map[string][]string{"hats": {"b"}}),
+			want:   []string{"reset shoes/"},
+		},
+		{
+			name:   "category emptied",
+			before: journalTestCache(map[string][]string{"shoes": {"a"}}),
+			after:  journalTestCache(map[string][]string{"shoes": nil}),
+			want:   []string{"reset shoes/"},
+		},
+		{
+			name:   "every category cleared",
+			before: journalTestCache(map[string][]string{"shoes": {"a"}, "hats": {"b"}}),
+			after:  journalTestCache(nil),
+			want:   []string{"reset-all /"},
+		},
+		{name: "deleted", before: journalTestCache(map[string][]string{"shoes": {"a"}}), want: []string{"reset shoes/"}},
+		{
+			name:   "root switched",
+			before: journalTestCache(map[string][]string{"shoes": {"a"}}),
+			after:  switchedJournalTestCache(journalTestCache(map[string][]string{"shoes": {"a"}}), "/wardrobe", "/other"),
+			want:   []string{"root /"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := journalEventSummary(CacheChanges(tt.before, tt.after, journalTestTime))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("CacheChanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func switchedJournalTestCache(cache *OutfitCache, from, to string) *OutfitCache {
+	switched := cache.SwitchingRoot(from, to, "")
+	return &switched
+}
+
+func TestCacheChanges_WearRecordsCategorySize(t *testing.T) {
+	events := CacheChanges(nil, journalTestCache(map[string][]string{"shoes": {"a"}}), journalTestTime)
+	if events[0].TotalOutfits != 3 || !events[0].At.Equal(journalTestTime) {
+		t.Fatalf("event = %+v, want total outfits 3 at %v", events[0], journalTestTime)
+	}
+}
+
+func TestCacheReplacement(t *testing.T) {
+	got := journalEventSummary(CacheReplacement(journalTestCache(map[string][]string{"shoes": {"a"}}), journalTestTime))
+	want := []string{"reset-all /", "wear shoes/a"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("CacheReplacement() = %v, want %v", got, want)
+	}
+}
+
+func TestReplayJournal(t *testing.T) {
+	day := func(n int) time.Time { return journalTestTime.AddDate(0, 0, n) }
+	config := &Config{Root: "/wardrobe", Language: "en"}
+	changed := &Config{Root: "/wardrobe", Language: "fr"}
+	events := []JournalEvent{
+		{Type: JournalConfigUpdate, At: day(0), Config: config},
+		{Type: JournalWear, At: day(1), Category: "shoes", Outfit: "a", TotalOutfits: 3},
+		{Type: JournalWear, At: day(1), Category: "shoes", Outfit: "b", TotalOutfits: 3},
+		{Type: JournalWear, At: day(1), Category: "hats", Outfit: "c", TotalOutfits: 2},
+		{Type: JournalUnwear, At: day(2), Category: "shoes", Outfit: "a"},
+		{Type: JournalUnwear, At: day(2), Category: "coats", Outfit: "z"},
+		{Type: JournalReset, At: day(3), Category: "hats"},
+		{Type: JournalConfigUpdate, At: day(4), Config: changed},
+		{Type: JournalResetAll, At: day(5)},
+		{Type: JournalWear, At: day(6), Category: "hats", Outfit: "d", TotalOutfits: 2},
+		{Type: JournalFactoryReset, At: day(7)},
+	}
+
+	tests := []struct {
+		name     string
+		until    time.Time
+		language string
+		worn     map[string][]string
+	}{
+		{name: "before anything", until: day(-1), worn: map[string][]string{}},
+		{name: "after wears", until: day(1), language: "en", worn: map[string][]string{"shoes": {"a", "b"}, "hats": {"c"}}},
+		{name: "after unwear", until: day(2), language: "en", worn: map[string][]string{"shoes": {"b"}, "hats": {"c"}}},
+		{name: "after reset", until: day(3), language: "en", worn: map[string][]string{"shoes": {"b"}}},
+		{name: "after config update", until: day(4), language: "fr", worn: map[string][]string{"shoes": {"b"}}},
+		{name: "after reset all", until: day(6), language: "fr", worn: map[string][]string{"hats": {"d"}}},
+		{name: "everything", language: "", worn: map[string][]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := ReplayJournal(events, tt.until)
+			if tt.language == "" {
+				if state.Config != nil {
+					t.Fatalf("Config = %+v, want nil", state.Config)
+				}
+			} else if state.Config == nil || state.Config.Language != tt.language {
+				t.Fatalf("Config = %+v, want language %q", state.Config, tt.language)
+			}
+			got := map[string][]string{}
+			for name, category := range state.Cache.Categories {
+				got[name] = sortedWornOutfits(category)
+			}
+			if !reflect.DeepEqual(got, tt.worn) {
+				t.Fatalf("worn = %v, want %v", got, tt.worn)
+			}
+		})
+	}
+
+	state := ReplayJournal(events, day(1))
+	if shoes := state.Cache.Categories["shoes"]; shoes.TotalOutfits != 3 || !shoes.LastUpdated.Equal(day(1)) {
+		t.Fatalf("shoes = %+v, want total 3 updated at %v", shoes, day(1))
+	}
+}
+
+func TestReplayJournal_Baseline(t *testing.T) {
+	stored := NewOutfitCache().Updating("shoes", NewCategoryCache(3).Adding("a"))
+	events := []JournalEvent{
+		{Type: JournalBaseline, At: journalTestTime, Config: &Config{Root: "/wardrobe"}, Cache: &stored},
+		{Type: JournalWear, At: journalTestTime, Category: "shoes", Outfit: "b", TotalOutfits: 3},
+	}
+	if !HasBaseline(events) || HasBaseline(events[1:]) || HasBaseline(nil) {
+		t.Fatal("HasBaseline() should only accept events starting from a baseline")
+	}
+
+	state := ReplayJournal(events, time.Time{})
+	if state.Config == nil || state.Config.Root != "/wardrobe" {
+		t.Fatalf("Config = %+v, want the baseline config", state.Config)
+	}
+	if got := sortedWornOutfits(state.Cache.Categories["shoes"]); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("worn = %v, want the baseline outfit and the later wear", got)
+	}
+	if len(stored.Categories["shoes"].WornOutfits) != 1 {
+		t.Fatal("replay changed the recorded baseline")
+	}
+
+	state = ReplayJournal([]JournalEvent{{Type: JournalBaseline, At: journalTestTime}}, time.Time{})
+	if state.Config != nil || len(state.Cache.Categories) != 0 {
+		t.Fatalf("empty baseline = %+v, want nothing configured or worn", state)
+	}
+}
+
+func TestReplayJournal_RootSwitches(t *testing.T) {
+	day := func(n int) time.Time { return journalTestTime.AddDate(0, 0, n) }
+	first := journalTestCache(map[string][]string{"shoes": {"a"}})
+	first.Root = "/wardrobe"
+	switched := switchedJournalTestCache(first, "", "/other")
+	worn := switched.Updating("hats", NewCategoryCache(2).Adding("b"))
+	cleared := worn.Clearing()
+	back := switchedJournalTestCache(&cleared, "", "/wardrobe")
+
+	var events []JournalEvent
+	events = append(events, CacheChanges(nil, first, day(0))...)
+	events = append(events, CacheChanges(first, switched, day(1))...)
+	events = append(events, CacheChanges(switched, &worn, day(2))...)
+	events = append(events, JournalEvent{Type: JournalResetAll, At: day(3)})
+	events = append(events, CacheChanges(&cleared, back, day(4))...)
+
+	tests := []struct {
+		name   string
+		until  time.Time
+		root   string
+		worn   map[string][]string
+		others []string
+	}{
+		{name: "before the switch", until: day(0), root: "/wardrobe", worn: map[string][]string{"shoes": {"a"}}},
+		{name: "after the switch", until: day(1), root: "/other", worn: map[string][]string{}, others: []string{"/wardrobe"}},
+		{name: "worn in the other root", until: day(2), root: "/other", worn: map[string][]string{"hats": {"b"}}, others: []string{"/wardrobe"}},
+		{name: "reset all keeps other roots", until: day(3), root: "/other", worn: map[string][]string{}, others: []string{"/wardrobe"}},
+		{name: "switched back", root: "/wardrobe", worn: map[string][]string{"shoes": {"a"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := ReplayJournal(events, tt.until)
+			if state.Cache.Root != tt.root {
+				t.Fatalf("Root = %q, want %q", state.Cache.Root, tt.root)
+			}
+			got := map[string][]string{}
+			for name, category := range state.Cache.Categories {
+				got[name] = sortedWornOutfits(category)
+			}
+			if !reflect.DeepEqual(got, tt.worn) {
+				t.Fatalf("worn = %v, want %v", got, tt.worn)
+			}
+			var others []string
+			for root := range state.Cache.OtherRoots {
+				others = append(others, root)
+			}
+			if !reflect.DeepEqual(others, tt.others) {
+				t.Fatalf("other roots = %v, want %v", others, tt.others)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package entities
+
+// KnownWardrobe is what the wardrobe held when an earlier run last read all
+// of it, and the outfits that have arrived since. It is kept in a file of its
+// own, so recording the wardrobe on every run does not rewrite or journal
+// the configuration.
+type KnownWardrobe struct {
+	// Categories lists the outfit keys of each category by name.
+	Categories map[string]map[string]bool `json:"categories"`
+	// NewArrivals lists, by category, outfits that appeared after the
+	// wardrobe was first recorded and have not been worn since.
+	NewArrivals map[string]map[string]bool `json:"newArrivals,omitempty"`
+}
+
+// NewKnownWardrobe records outfits, the outfit keys of each category by name,
+// with arrivals as its new arrivals.
+func NewKnownWardrobe(outfits map[string][]string, arrivals map[string]map[string]bool) KnownWardrobe {
+	categories := make(map[string]map[string]bool, len(outfits))
+	for category, keys := range outfits {
+		files := make(map[string]bool, len(keys))
+		for _, key := range keys {
+			files[key] = true
+		}
+		categories[category] = files
+	}
+	return KnownWardrobe{Categories: categories, NewArrivals: arrivals}
+}
+
+// Recorded reports whether an earlier run recorded the wardrobe.
+func (w KnownWardrobe) Recorded() bool {
+	return len(w.Categories) > 0
+}
+
+// HasCategory reports whether the category named name was recorded.
+func (w KnownWardrobe) HasCategory(name string) bool {
+	_, ok := w.Categories[name]
+	return ok
+}
+
+// HasOutfit reports whether the outfit with key was recorded in category.
+func (w KnownWardrobe) HasOutfit(category, key string) bool {
+	return w.Categories[category][key]
+}
+
+// IsNewArrival reports whether the outfit with key in category is a new
+// arrival.
+func (w KnownWardrobe) IsNewArrival(category, key string) bool {
+	return w.NewArrivals[category][key]
+}
@@ -0,0 +1,24 @@
+package entities
+
+import "testing"
+
+func TestKnownWardrobe(t *testing.T) {
+	var unknown KnownWardrobe
+	if unknown.Recorded() || unknown.HasCategory("casual") || unknown.HasOutfit("casual", "one.avatar") {
+		t.Fatal("an empty recording knows about the wardrobe")
+	}
+
+	known := NewKnownWardrobe(
+		map[string][]string{"casual": {"one.avatar", "two.avatar@/nas"}, "empty": nil},
+		map[string]map[string]bool{"casual": {"two.avatar@/nas": true}},
+	)
+	if !known.Recorded() || !known.HasCategory("empty") || known.HasCategory("formal") {
+		t.Errorf("categories = %+v, want casual and empty", known.Categories)
+	}
+	if !known.HasOutfit("casual", "two.avatar@/nas") || known.HasOutfit("casual", "three.avatar") || len(known.Categories["casual"]) != 2 {
+		t.Errorf("casual = %+v, want one.avatar and two.avatar@/nas", known.Categories["casual"])
+	}
+	if !known.IsNewArrival("casual", "two.avatar@/nas") || known.IsNewArrival("casual", "one.avatar") || known.IsNewArrival("formal", "two.avatar@/nas") {
+		t.Errorf("IsNewArrival() does not match %v", known.NewArrivals)
+	}
+}
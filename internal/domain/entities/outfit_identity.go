@@ -0,0 +1,67 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+// OutfitIdentity selects how worn outfits are matched to their files.
+type OutfitIdentity string
+
+const (
+	// IdentityFileName matches worn outfits by file name only, so a renamed
+	// or moved file starts out unworn.
+	IdentityFileName OutfitIdentity = "name"
+	// IdentityContent also recognises an outfit by a hash of its contents,
+	// so a renamed or moved file keeps its worn state.
+	IdentityContent OutfitIdentity = "content"
+)
+
+// ParseOutfitIdentity validates name. An empty name selects file names.
+func ParseOutfitIdentity(name string) (OutfitIdentity, error) {
+	switch identity := OutfitIdentity(name); identity {
+	case "", IdentityFileName:
+		return IdentityFileName, nil
+	case IdentityContent:
+		return IdentityContent, nil
+	default:
+		return "", errors.NewInvalidInputError("identity must be name or content")
+	}
+}
+
+// ContentHash is the hash of a file's contents together with the size and
+// modification time it was computed for. It is reused while both are
+// unchanged.
+type ContentHash struct {
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	Hash    string    `json:"hash"`
+}
+
+// Matches reports whether the hash is still valid for a file of size that
+// was last modified at modTime.
+func (h ContentHash) Matches(size int64, modTime time.Time) bool {
+	return h.Size == size && h.ModTime.Equal(modTime)
+}
+
+// ContentHashIndex keeps the content hashes computed so far by file path.
+// Entries of files that no longer exist are kept, so that a renamed file can
+// be matched to the hash it had under its old name.
+type ContentHashIndex struct {
+	Files map[string]ContentHash `json:"files"`
+}
+
+// OutfitRename is a worn outfit whose file was found under another name or
+// in another category, and whose worn state moved along with it.
+type OutfitRename struct {
+	FromCategory string
+	From         string
+	ToCategory   string
+	To           string
+}
+
+// String describes the rename as category/file -> category/file.
+func (r OutfitRename) String() string {
+	return r.FromCategory + "/" + r.From + " -> " + r.ToCategory + "/" + r.To
+}
@@ -0,0 +1,55 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseOutfitIdentity(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    OutfitIdentity
+		wantErr bool
+	}{
+		{name: "", want: IdentityFileName},
+		{name: "name", want: IdentityFileName},
+		{name: "content", want: IdentityContent},
+		{name: "inode", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseOutfitIdentity(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseOutfitIdentity(%q) = %q, %v; want %q, error %t", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestContentHash_Matches(t *testing.T) {
+	modTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	hash := ContentHash{Size: 10, ModTime: modTime, Hash: "sha256:abc"}
+
+	if !hash.Matches(10, modTime.In(time.Local)) {
+		t.Error("Matches() = false for the same size and time")
+	}
+	if hash.Matches(11, modTime) || hash.Matches(10, modTime.Add(time.Second)) {
+		t.Error("Matches() = true after the size or time changed")
+	}
+}
+
+func TestConfig_WithIdentity(t *testing.T) {
+	config := Config{Root: "/test/wardrobe"}
+	if config.MatchesContent() {
+		t.Error("MatchesContent() = true by default")
+	}
+	updated := config.WithIdentity(IdentityContent)
+	if !updated.MatchesContent() || config.MatchesContent() {
+		t.Errorf("WithIdentity(content) = %+v from %+v, want only the copy changed", updated, config)
+	}
+}
+
+func TestOutfitRename_String(t *testing.T) {
+	rename := OutfitRename{FromCategory: "casual", From: "old.avatar", ToCategory: "formal", To: "new.avatar"}
+	if got := rename.String(); got != "casual/old.avatar -> formal/new.avatar" {
+		t.Errorf("String() = %q", got)
+	}
+}
@@ -7,8 +7,12 @@ import (
 
 // OutfitReference references a specific outfit file within a category.
 type OutfitReference struct {
-	FileName string            `json:"fileName"`
-	Category CategoryReference `json:"category"`
+	FileName        string            `json:"fileName"`
+	Category        CategoryReference `json:"category"`
+	PreviewFileName string            `json:"previewFileName,omitempty"`
+	// Root is the wardrobe root the outfit comes from, when that is not the
+	// first root.
+	Root string `json:"root,omitempty"`
 }
 
 // NewOutfitReference creates a new outfit reference.
@@ -19,9 +23,46 @@ func NewOutfitReference(fileName string, category CategoryReference) OutfitRefer
 	}
 }
 
-// FilePath returns the complete filesystem path to the outfit file.
+// NewOutfitReferenceFromFile references the outfit file found in the category
+// named categoryName, keeping the root it came from.
+func NewOutfitReferenceFromFile(categoryName string, file FileEntry) OutfitReference {
+	category := NewCategoryReference(categoryName, file.CategoryPath())
+	outfit := NewOutfitReference(file.FileName, category).WithPreview(file.PreviewFileName)
+	outfit.Root = file.Root
+	return outfit
+}
+
+// Key returns the outfit's OutfitKey.
+func (o OutfitReference) Key() string {
+	return OutfitKey(o.Root, o.FileName)
+}
+
+// FilePath returns the complete filesystem path to the outfit file. An
+// outfit inside an archive gets a URI naming the archive and the file in
+// it, such as zip:/packs/summer.zip!/casual/look.avatar.
 func (o OutfitReference) FilePath() string {
-	return filepath.Join(o.Category.Path, o.FileName)
+	return fileURI(filepath.Join(o.Category.Path, o.FileName))
+}
+
+// InArchive reports whether the outfit is inside an archive rather than a
+// plain file.
+func (o OutfitReference) InArchive() bool {
+	return InArchive(o.Category.Path)
+}
+
+// WithPreview returns a copy of the reference linked to a companion image.
+func (o OutfitReference) WithPreview(previewFileName string) OutfitReference {
+	o.PreviewFileName = previewFileName
+	return o
+}
+
+// PreviewPath returns the path to the companion image, or "" when there is
+// none. Like FilePath, it is a URI for an image inside an archive.
+func (o OutfitReference) PreviewPath() string {
+	if o.PreviewFileName == "" {
+		return ""
+	}
+	return fileURI(filepath.Join(o.Category.Path, o.PreviewFileName))
 }
 
 func (o OutfitReference) String() string {
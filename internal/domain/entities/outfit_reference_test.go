@@ -18,6 +18,21 @@ func TestNewOutfitReference(t *testing.T) {
 	}
 }
 
+func TestNewOutfitReferenceFromFile(t *testing.T) {
+	file := FileEntry{FileName: "look.avatar", PreviewFileName: "look.png"}.InCategory(filepath.Join("/nas", "casual"), "/nas")
+	ref := NewOutfitReferenceFromFile("casual", file)
+
+	if ref.Category != NewCategoryReference("casual", filepath.Join("/nas", "casual")) || ref.PreviewFileName != "look.png" {
+		t.Errorf("NewOutfitReferenceFromFile() = %+v, want the file's category and preview", ref)
+	}
+	if ref.Root != "/nas" || ref.Key() != file.Key() {
+		t.Errorf("Key() = %q, want %q", ref.Key(), file.Key())
+	}
+	if got := NewOutfitReference("look.avatar", ref.Category).Key(); got != "look.avatar" {
+		t.Errorf("Key() without a root = %q, want the file name", got)
+	}
+}
+
 func TestOutfitReference_FilePath(t *testing.T) {
 	category := NewCategoryReference("casual", "/Users/user/outfits/casual")
 	ref := NewOutfitReference("jeans-tshirt.avatar", category)
@@ -28,6 +43,22 @@ func TestOutfitReference_FilePath(t *testing.T) {
 	}
 }
 
+func TestOutfitReference_PathsIntoArchives(t *testing.T) {
+	archive := filepath.Join(string(filepath.Separator)+"packs", "summer.tar.gz")
+	ref := NewOutfitReference("look.avatar", NewCategoryReference("casual", filepath.Join(archive, "casual"))).WithPreview("look.png")
+
+	prefix := "tar:" + filepath.ToSlash(archive) + "!/casual/"
+	if got := ref.FilePath(); got != prefix+"look.avatar" {
+		t.Errorf("FilePath() = %v, want %v", got, prefix+"look.avatar")
+	}
+	if got := ref.PreviewPath(); got != prefix+"look.png" {
+		t.Errorf("PreviewPath() = %v, want %v", got, prefix+"look.png")
+	}
+	if !ref.InArchive() || NewOutfitReference("look.avatar", NewCategoryReference("casual", "/outfits/casual")).InArchive() {
+		t.Error("InArchive() should hold only for outfits inside archives")
+	}
+}
+
 func TestOutfitReference_String(t *testing.T) {
 	category := NewCategoryReference("casual", "/Users/user/outfits/casual")
 	ref := NewOutfitReference("jeans-tshirt.avatar", category)
@@ -56,3 +87,33 @@ func TestOutfitReference_JSONMarshaling(t *testing.T) {
 		t.Errorf("round-trip failed: got %v, want %v", unmarshaled, ref)
 	}
 }
+
+func TestOutfitReference_PreviewPath(t *testing.T) {
+	category := NewCategoryReference("chic", "/Users/user/outfits/chic")
+	ref := NewOutfitReference("chic4.avatar", category)
+
+	if got := ref.PreviewPath(); got != "" {
+		t.Errorf("PreviewPath() without preview = %v, want empty", got)
+	}
+
+	withPreview := ref.WithPreview("chic4.png")
+	want := filepath.Join("/Users/user/outfits/chic", "chic4.png")
+	if got := withPreview.PreviewPath(); got != want {
+		t.Errorf("PreviewPath() = %v, want %v", got, want)
+	}
+	if ref.PreviewFileName != "" {
+		t.Errorf("WithPreview() modified the original reference")
+	}
+
+	data, err := json.Marshal(withPreview)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var unmarshaled OutfitReference
+	if err := json.Unmarshal(data, &unmarshaled); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if unmarshaled != withPreview {
+		t.Errorf("round-trip failed: got %v, want %v", unmarshaled, withPreview)
+	}
+}
@@ -0,0 +1,18 @@
+package entities
+
+// DefaultProfile names the profile whose files sit directly in the
+// outfitpicker config directory, where they were kept before profiles.
+const DefaultProfile = "default"
+
+// Profile is a named set of config, worn outfits, journal, and backups.
+type Profile struct {
+	Name string
+	// Dir is the directory holding the profile's files.
+	Dir string
+}
+
+// IsDefaultProfile reports whether name selects the default profile; an empty
+// name does too.
+func IsDefaultProfile(name string) bool {
+	return name == "" || name == DefaultProfile
+}
@@ -0,0 +1,11 @@
+package entities
+
+import "testing"
+
+func TestIsDefaultProfile(t *testing.T) {
+	for name, want := range map[string]bool{"": true, DefaultProfile: true, "alex": false, "Default": false} {
+		if got := IsDefaultProfile(name); got != want {
+			t.Errorf("IsDefaultProfile(%q) = %t, want %t", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package entities
+
+import (
+	"path/filepath"
+	"time"
+)
+
+// QueuedWear is an outfit marked worn while the wardrobe could not be read.
+// It is applied to the worn outfits once the wardrobe is back.
+type QueuedWear struct {
+	Category string `json:"category"`
+	FileName string `json:"fileName"`
+	// Root is the wardrobe root the outfit comes from, when that is not the
+	// first root.
+	Root     string    `json:"root,omitempty"`
+	QueuedAt time.Time `json:"queuedAt"`
+}
+
+// NewQueuedWear queues outfit as worn at the time at.
+func NewQueuedWear(outfit OutfitReference, at time.Time) QueuedWear {
+	return QueuedWear{
+		Category: outfit.Category.Name,
+		FileName: outfit.FileName,
+		Root:     outfit.Root,
+		QueuedAt: at,
+	}
+}
+
+// Key returns the queued outfit's OutfitKey.
+func (w QueuedWear) Key() string {
+	return OutfitKey(w.Root, w.FileName)
+}
+
+// Outfit returns the queued outfit, locating outfits without a Root in
+// firstRoot.
+func (w QueuedWear) Outfit(firstRoot string) OutfitReference {
+	root := w.Root
+	if root == "" {
+		root = firstRoot
+	}
+	outfit := NewOutfitReference(w.FileName, NewCategoryReference(w.Category, filepath.Join(root, w.Category)))
+	outfit.Root = w.Root
+	return outfit
+}
+
+// WearQueue is the outfits marked worn while the wardrobe was offline, in the
+// order they were worn. It is kept in a file of its own, so queuing a wear
+// does not rewrite or journal the configuration.
+type WearQueue struct {
+	Wears []QueuedWear `json:"wears"`
+}
+
+// Adding returns the queue with wear added, unless the same outfit is queued
+// already.
+func (q WearQueue) Adding(wear QueuedWear) WearQueue {
+	if q.IsQueued(wear.Category, wear.Key()) {
+		return q
+	}
+	return WearQueue{Wears: append(append([]QueuedWear(nil), q.Wears...), wear)}
+}
+
+// Without returns the queue without the wears in done.
+func (q WearQueue) Without(done []QueuedWear) WearQueue {
+	remove := make(map[[2]string]bool, len(done))
+	for _, wear := range done {
+		remove[[2]string{wear.Category, wear.Key()}] = true
+	}
+	var kept []QueuedWear
+	for _, wear := range q.Wears {
+		if !remove[[2]string{wear.Category, wear.Key()}] {
+			kept = append(kept, wear)
+		}
+	}
+	return WearQueue{Wears: kept}
+}
+
+// PromotingRoot returns the queue with the wears of outfits from the
+// wardrobe root root, which has become the first root, queued as outfits of
+// the first root. A wear that is then queued twice is kept once.
+func (q WearQueue) PromotingRoot(root string) WearQueue {
+	var promoted WearQueue
+	for _, wear := range q.Wears {
+		if root != "" && wear.Root == root {
+			wear.Root = ""
+		}
+		promoted = promoted.Adding(wear)
+	}
+	return promoted
+}
+
+// IsQueued reports whether the outfit with key in category is queued to be
+// marked worn.
+func (q WearQueue) IsQueued(category, key string) bool {
+	for _, wear := range q.Wears {
+		if wear.Category == category && wear.Key() == key {
+			return true
+		}
+	}
+	return false
+}
+
+// AppliedWears is what became of the wears queued while the wardrobe was
+// offline once it could be read again.
+type AppliedWears struct {
+	// Applied lists the wears now recorded as worn.
+	Applied []QueuedWear
+	// Dropped lists the wears whose outfits are no longer in the wardrobe.
+	Dropped []QueuedWear
+}
+
+// Empty reports whether no queued wear was applied or dropped.
+func (a AppliedWears) Empty() bool {
+	return len(a.Applied) == 0 && len(a.Dropped) == 0
+}
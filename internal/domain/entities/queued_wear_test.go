@@ -0,0 +1,60 @@
+package entities
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestQueuedWear_Outfit(t *testing.T) {
+	at := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	first := NewQueuedWear(NewOutfitReference("look.avatar", NewCategoryReference("casual", filepath.Join("/outfits", "casual"))), at)
+	if first.Key() != "look.avatar" || first.QueuedAt != at {
+		t.Errorf("NewQueuedWear() = %+v, want look.avatar queued at %v", first, at)
+	}
+	if got := first.Outfit("/outfits"); got.Category.Path != filepath.Join("/outfits", "casual") || got.Key() != "look.avatar" {
+		t.Errorf("Outfit() = %+v, want look.avatar in the first root", got)
+	}
+
+	other := NewOutfitReference("look.avatar", NewCategoryReference("casual", filepath.Join("/nas", "casual")))
+	other.Root = "/nas"
+	wear := NewQueuedWear(other, at)
+	if got := wear.Outfit("/outfits"); got.Category.Path != filepath.Join("/nas", "casual") || got.Key() != other.Key() {
+		t.Errorf("Outfit() = %+v, want %+v", got, other)
+	}
+}
+
+func TestWearQueue(t *testing.T) {
+	look := QueuedWear{Category: "casual", FileName: "look.avatar"}
+	remote := QueuedWear{Category: "casual", FileName: "look.avatar", Root: "/nas"}
+	var empty WearQueue
+	queued := empty.Adding(look).Adding(remote).Adding(look)
+
+	if len(queued.Wears) != 2 {
+		t.Fatalf("Wears = %v, want look.avatar from each root once", queued.Wears)
+	}
+	if !queued.IsQueued("casual", remote.Key()) || queued.IsQueued("formal", look.Key()) || empty.IsQueued("casual", look.Key()) {
+		t.Errorf("IsQueued() does not match %v", queued.Wears)
+	}
+	if left := queued.Without([]QueuedWear{look}); len(left.Wears) != 1 || left.Wears[0] != remote || len(queued.Wears) != 2 {
+		t.Errorf("Without() = %v, want only %v", left.Wears, remote)
+	}
+
+	other := QueuedWear{Category: "casual", FileName: "suit.avatar", Root: "/usb"}
+	promoted := queued.Adding(other).PromotingRoot("/nas")
+	if len(promoted.Wears) != 2 || promoted.Wears[0] != look || promoted.Wears[1] != other || len(queued.Wears) != 2 {
+		t.Errorf("PromotingRoot() = %v, want look.avatar once from the first root and %v kept", promoted.Wears, other)
+	}
+	if same := queued.PromotingRoot(""); len(same.Wears) != 2 {
+		t.Errorf("PromotingRoot() of no root = %v, want the queue unchanged", same.Wears)
+	}
+}
+
+func TestAppliedWears_Empty(t *testing.T) {
+	if !(AppliedWears{}).Empty() {
+		t.Error("Empty() = false with nothing applied, want true")
+	}
+	if (AppliedWears{Dropped: []QueuedWear{{Category: "casual", FileName: "gone.avatar"}}}).Empty() {
+		t.Error("Empty() = true with a dropped wear, want false")
+	}
+}
@@ -0,0 +1,10 @@
+package entities
+
+// RootStatus describes what a scan found in one wardrobe root.
+type RootStatus struct {
+	Root       string
+	Categories int
+	Outfits    int
+	// Err is why the root could not be scanned, or nil.
+	Err error
+}
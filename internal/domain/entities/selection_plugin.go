@@ -0,0 +1,145 @@
+package entities
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+// SelectionProtocolVersion is the version of the JSON exchanged with
+// selection plugins. Plugins must echo it in their response.
+const SelectionProtocolVersion = 1
+
+// DefaultSelectionPluginTimeout bounds plugins that do not set their own
+// timeout.
+const DefaultSelectionPluginTimeout = 5 * time.Second
+
+// SelectionPlugin is an external executable that ranks candidate outfits in
+// place of uniform random selection.
+type SelectionPlugin struct {
+	Command        string   `json:"command"`
+	Args           []string `json:"args,omitempty"`
+	TimeoutSeconds int      `json:"timeoutSeconds,omitempty"`
+}
+
+// NewSelectionPlugin creates and validates a selection plugin. A zero timeout
+// uses DefaultSelectionPluginTimeout.
+func NewSelectionPlugin(command string, args []string, timeout time.Duration) (SelectionPlugin, error) {
+	if strings.TrimSpace(command) == "" {
+		return SelectionPlugin{}, errors.NewInvalidInputError("selection plugin command cannot be empty")
+	}
+	if timeout < 0 || timeout%time.Second != 0 {
+		return SelectionPlugin{}, errors.NewInvalidInputError("selection plugin timeout must be a whole, non-negative number of seconds")
+	}
+	return SelectionPlugin{
+		Command:        command,
+		Args:           append([]string(nil), args...),
+		TimeoutSeconds: int(timeout / time.Second),
+	}, nil
+}
+
+// Timeout returns how long the plugin may run before selection falls back to
+// random.
+func (p SelectionPlugin) Timeout() time.Duration {
+	if p.TimeoutSeconds <= 0 {
+		return DefaultSelectionPluginTimeout
+	}
+	return time.Duration(p.TimeoutSeconds) * time.Second
+}
+
+// String returns the plugin command line for display.
+func (p SelectionPlugin) String() string {
+	return strings.Join(append([]string{p.Command}, p.Args...), " ")
+}
+
+// SelectionCandidate describes one outfit a plugin may choose.
+type SelectionCandidate struct {
+	ID          string `json:"id"`
+	Category    string `json:"category"`
+	FileName    string `json:"fileName"`
+	Path        string `json:"path"`
+	PreviewPath string `json:"previewPath,omitempty"`
+}
+
+// NewSelectionCandidate describes outfit for a selection request. Its ID is
+// CATEGORY/KEY, which is CATEGORY/FILE for outfits in the first root.
+func NewSelectionCandidate(outfit OutfitReference) SelectionCandidate {
+	return SelectionCandidate{
+		ID:          outfit.Category.Name + "/" + outfit.Key(),
+		Category:    outfit.Category.Name,
+		FileName:    outfit.FileName,
+		Path:        outfit.FilePath(),
+		PreviewPath: outfit.PreviewPath(),
+	}
+}
+
+// SelectionRequest is written to a selection plugin's stdin.
+type SelectionRequest struct {
+	ProtocolVersion int                  `json:"protocolVersion"`
+	Category        string               `json:"category,omitempty"`
+	Candidates      []SelectionCandidate `json:"candidates"`
+	WornOutfits     map[string][]string  `json:"wornOutfits"`
+	LastUpdated     map[string]time.Time `json:"lastUpdated,omitempty"`
+	SessionShown    []string             `json:"sessionShown"`
+}
+
+// NewSelectionRequest builds a request for candidates. Category is empty for
+// picks across all categories; wear history comes from cache and shown lists
+// the CATEGORY/FILE ids already shown this session.
+func NewSelectionRequest(category string, candidates []OutfitReference, cache *OutfitCache, shown []string) SelectionRequest {
+	request := SelectionRequest{
+		ProtocolVersion: SelectionProtocolVersion,
+		Category:        category,
+		Candidates:      make([]SelectionCandidate, 0, len(candidates)),
+		WornOutfits:     map[string][]string{},
+		SessionShown:    append([]string{}, shown...),
+	}
+	for _, candidate := range candidates {
+		request.Candidates = append(request.Candidates, NewSelectionCandidate(candidate))
+	}
+	if cache != nil {
+		request.LastUpdated = map[string]time.Time{}
+		for name, categoryCache := range cache.Categories {
+			worn := make([]string, 0, len(categoryCache.WornOutfits))
+			for fileName, isWorn := range categoryCache.WornOutfits {
+				if isWorn {
+					worn = append(worn, fileName)
+				}
+			}
+			sort.Strings(worn)
+			request.WornOutfits[name] = worn
+			if !categoryCache.LastUpdated.IsZero() {
+				request.LastUpdated[name] = categoryCache.LastUpdated.UTC()
+			}
+		}
+	}
+	sort.Strings(request.SessionShown)
+	return request
+}
+
+// SelectionResponse is read from a selection plugin's stdout. Ranking lists
+// candidate ids from most to least preferred.
+type SelectionResponse struct {
+	ProtocolVersion int      `json:"protocolVersion"`
+	Ranking         []string `json:"ranking"`
+}
+
+// Choose returns the index in request.Candidates of the highest ranked known
+// candidate.
+func (r SelectionResponse) Choose(request SelectionRequest) (int, error) {
+	if r.ProtocolVersion != request.ProtocolVersion {
+		return 0, errors.NewInvalidInputError("selection plugin answered with an unsupported protocol version")
+	}
+	indexes := make(map[string]int, len(request.Candidates))
+	for index, candidate := range request.Candidates {
+		indexes[candidate.ID] = index
+	}
+	for _, id := range r.Ranking {
+		if index, ok := indexes[id]; ok {
+			return index, nil
+		}
+	}
+	return 0, errors.NewInvalidInputError("selection plugin did not rank any candidate")
+}
@@ -0,0 +1,103 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewSelectionPlugin(t *testing.T) {
+	plugin, err := NewSelectionPlugin("ranker", []string{"--fresh"}, 2*time.Second)
+	if err != nil {
+		t.Fatalf("NewSelectionPlugin() error = %v", err)
+	}
+	if plugin.Timeout() != 2*time.Second || plugin.String() != "ranker --fresh" {
+		t.Fatalf("NewSelectionPlugin() = %#v", plugin)
+	}
+	if defaulted := (SelectionPlugin{Command: "ranker"}); defaulted.Timeout() != DefaultSelectionPluginTimeout {
+		t.Fatalf("Timeout() = %v, want %v", defaulted.Timeout(), DefaultSelectionPluginTimeout)
+	}
+
+	for _, tt := range []struct {
+		command string
+		timeout time.Duration
+	}{
+		{command: ""},
+		{command: "ranker", timeout: -time.Second},
+		{command: "ranker", timeout: 300 * time.Millisecond},
+	} {
+		if _, err := NewSelectionPlugin(tt.command, nil, tt.timeout); err == nil {
+			t.Fatalf("NewSelectionPlugin(%q, %v) error = nil, want error", tt.command, tt.timeout)
+		}
+	}
+}
+
+func TestNewSelectionRequest(t *testing.T) {
+	shoes := NewCategoryReference("shoes", "/Users/user/outfits/shoes")
+	boots := NewOutfitReference("boots.avatar", shoes).WithPreview("boots.png")
+	updated := time.Date(2026, 2, 1, 8, 0, 0, 0, time.UTC)
+	cache := NewOutfitCache()
+	cache.Categories["shoes"] = CategoryCache{WornOutfits: map[string]bool{"sandals.avatar": true, "clogs.avatar": true, "mules.avatar": false}, TotalOutfits: 4, LastUpdated: updated}
+
+	request := NewSelectionRequest("shoes", []OutfitReference{boots}, &cache, []string{"shoes/z.avatar", "shoes/a.avatar"})
+
+	if request.ProtocolVersion != SelectionProtocolVersion || request.Category != "shoes" {
+		t.Fatalf("NewSelectionRequest() = %#v", request)
+	}
+	want := SelectionCandidate{ID: "shoes/boots.avatar", Category: "shoes", FileName: "boots.avatar", Path: boots.FilePath(), PreviewPath: boots.PreviewPath()}
+	if len(request.Candidates) != 1 || request.Candidates[0] != want {
+		t.Fatalf("Candidates = %#v, want %#v", request.Candidates, want)
+	}
+	if worn := request.WornOutfits["shoes"]; len(worn) != 2 || worn[0] != "clogs.avatar" || worn[1] != "sandals.avatar" {
+		t.Fatalf("WornOutfits = %v", request.WornOutfits)
+	}
+	if !request.LastUpdated["shoes"].Equal(updated) {
+		t.Fatalf("LastUpdated = %v", request.LastUpdated)
+	}
+	if request.SessionShown[0] != "shoes/a.avatar" {
+		t.Fatalf("SessionShown = %v, want sorted", request.SessionShown)
+	}
+
+	empty := NewSelectionRequest("", nil, nil, nil)
+	if empty.WornOutfits == nil || empty.SessionShown == nil || empty.LastUpdated != nil {
+		t.Fatalf("NewSelectionRequest() without history = %#v", empty)
+	}
+}
+
+func TestSelectionResponse_Choose(t *testing.T) {
+	shoes := NewCategoryReference("shoes", "/Users/user/outfits/shoes")
+	request := NewSelectionRequest("", []OutfitReference{NewOutfitReference("boots.avatar", shoes), NewOutfitReference("clogs.avatar", shoes)}, nil, nil)
+
+	tests := []struct {
+		name     string
+		response SelectionResponse
+		want     int
+		wantErr  bool
+	}{
+		{name: "first ranked", response: SelectionResponse{ProtocolVersion: 1, Ranking: []string{"shoes/clogs.avatar", "shoes/boots.avatar"}}, want: 1},
+		{name: "skips unknown ids", response: SelectionResponse{ProtocolVersion: 1, Ranking: []string{"hats/cap.avatar", "shoes/boots.avatar"}}, want: 0},
+		{name: "wrong version", response: SelectionResponse{ProtocolVersion: 2, Ranking: []string{"shoes/boots.avatar"}}, wantErr: true},
+		{name: "nothing ranked", response: SelectionResponse{ProtocolVersion: 1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.response.Choose(request)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Fatalf("Choose() = %d, %v, want %d (error %t)", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_WithSelectionPlugin(t *testing.T) {
+	config, err := NewConfig("/Users/user/outfits", nil, nil)
+	if err != nil {
+		t.Fatalf("NewConfig() error = %v", err)
+	}
+	updated := config.WithSelectionPlugin(&SelectionPlugin{Command: "ranker"})
+	if config.SelectionPlugin != nil || updated.SelectionPlugin == nil {
+		t.Fatalf("WithSelectionPlugin() original = %v, updated = %v", config.SelectionPlugin, updated.SelectionPlugin)
+	}
+	if cleared := updated.WithSelectionPlugin(nil); cleared.SelectionPlugin != nil {
+		t.Fatalf("WithSelectionPlugin(nil) = %v", cleared.SelectionPlugin)
+	}
+}
@@ -0,0 +1,43 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+// StorageBackend names where config and worn outfit history are stored.
+type StorageBackend string
+
+const (
+	StorageJSON   StorageBackend = "json"
+	StorageSQLite StorageBackend = "sqlite"
+)
+
+// ParseStorageBackend validates name. An empty name selects the JSON files.
+func ParseStorageBackend(name string) (StorageBackend, error) {
+	switch backend := StorageBackend(name); backend {
+	case "", StorageJSON:
+		return StorageJSON, nil
+	case StorageSQLite:
+		return StorageSQLite, nil
+	default:
+		return "", errors.NewInvalidInputError("storage must be json or sqlite")
+	}
+}
+
+// WearRecord is one time an outfit was marked worn. Records outlive rotation
+// resets, so they form the full wear history.
+type WearRecord struct {
+	Category string
+	Outfit   string
+	WornAt   time.Time
+}
+
+// OutfitWearStats summarises the wear history of one outfit.
+type OutfitWearStats struct {
+	Category string
+	Outfit   string
+	Wears    int
+	LastWorn time.Time
+}
@@ -0,0 +1,22 @@
+package entities
+
+import "testing"
+
+func TestParseStorageBackend(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    StorageBackend
+		wantErr bool
+	}{
+		{name: "", want: StorageJSON},
+		{name: "json", want: StorageJSON},
+		{name: "sqlite", want: StorageSQLite},
+		{name: "postgres", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseStorageBackend(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseStorageBackend(%q) = %q, %v; want %q, error %t", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
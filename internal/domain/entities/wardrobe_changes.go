@@ -0,0 +1,46 @@
+package entities
+
+// CategoryChanges lists the outfits that appeared in or disappeared from one
+// category since the wardrobe was last seen.
+type CategoryChanges struct {
+	Category string
+	// Added is set when the whole category is new, and Removed when it is
+	// gone; AddedOutfits and RemovedOutfits then list all of its outfits.
+	Added          bool
+	Removed        bool
+	AddedOutfits   []string
+	RemovedOutfits []string
+}
+
+// WardrobeChanges is how the wardrobe differs from the last run, by category
+// name.
+type WardrobeChanges struct {
+	Categories []CategoryChanges
+	// Unreadable lists the wardrobe roots or categories that could not be
+	// read. Changes are not looked for while there are any, because their
+	// outfits would look removed.
+	Unreadable []string
+}
+
+// Changed reports whether any category or outfit was added or removed.
+func (c WardrobeChanges) Changed() bool {
+	return len(c.Categories) > 0
+}
+
+// AddedCount returns how many outfits were added.
+func (c WardrobeChanges) AddedCount() int {
+	count := 0
+	for _, category := range c.Categories {
+		count += len(category.AddedOutfits)
+	}
+	return count
+}
+
+// RemovedCount returns how many outfits were removed.
+func (c WardrobeChanges) RemovedCount() int {
+	count := 0
+	for _, category := range c.Categories {
+		count += len(category.RemovedOutfits)
+	}
+	return count
+}
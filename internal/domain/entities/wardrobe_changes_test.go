@@ -0,0 +1,24 @@
+package entities
+
+import "testing"
+
+func TestWardrobeChanges_Counts(t *testing.T) {
+	if (WardrobeChanges{Unreadable: []string{"/nas"}}).Changed() {
+		t.Error("Changed() = true with no categories, want false")
+	}
+
+	changes := WardrobeChanges{Categories: []CategoryChanges{
+		{Category: "beach", Added: true, AddedOutfits: []string{"towel.avatar", "hat.avatar"}},
+		{Category: "casual", AddedOutfits: []string{"new.avatar"}, RemovedOutfits: []string{"old.avatar"}},
+		{Category: "hats", Removed: true, RemovedOutfits: []string{"cap.avatar"}},
+	}}
+	if !changes.Changed() {
+		t.Error("Changed() = false, want true")
+	}
+	if got := changes.AddedCount(); got != 3 {
+		t.Errorf("AddedCount() = %d, want 3", got)
+	}
+	if got := changes.RemovedCount(); got != 2 {
+		t.Errorf("RemovedCount() = %d, want 2", got)
+	}
+}
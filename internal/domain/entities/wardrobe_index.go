@@ -0,0 +1,50 @@
+package entities
+
+import "time"
+
+// IndexedEntry is one entry of an indexed directory.
+type IndexedEntry struct {
+	Name        string `json:"name"`
+	IsDirectory bool   `json:"dir,omitempty"`
+	IsSymlink   bool   `json:"link,omitempty"`
+}
+
+// IndexedDirectory is the listing of a wardrobe directory together with the
+// modification time it was read at. It is reused while the directory's
+// modification time is unchanged, since adding, removing, or renaming an
+// entry updates it.
+type IndexedDirectory struct {
+	ModTime time.Time      `json:"modTime"`
+	Entries []IndexedEntry `json:"entries"`
+}
+
+// Matches reports whether the listing is still valid for a directory last
+// modified at modTime.
+func (d IndexedDirectory) Matches(modTime time.Time) bool {
+	return d.ModTime.Equal(modTime)
+}
+
+// FileEntries returns the listing as file entries.
+func (d IndexedDirectory) FileEntries() []FileEntry {
+	entries := make([]FileEntry, len(d.Entries))
+	for index, entry := range d.Entries {
+		entries[index] = FileEntry{FileName: entry.Name, IsDirectory: entry.IsDirectory, IsSymlink: entry.IsSymlink}
+	}
+	return entries
+}
+
+// NewIndexedDirectory records entries as the listing of a directory last
+// modified at modTime.
+func NewIndexedDirectory(modTime time.Time, entries []FileEntry) IndexedDirectory {
+	indexed := make([]IndexedEntry, len(entries))
+	for index, entry := range entries {
+		indexed[index] = IndexedEntry{Name: entry.FileName, IsDirectory: entry.IsDirectory, IsSymlink: entry.IsSymlink}
+	}
+	return IndexedDirectory{ModTime: modTime, Entries: indexed}
+}
+
+// WardrobeIndex keeps the listings of wardrobe directories by path, so that
+// later runs only read the directories that changed.
+type WardrobeIndex struct {
+	Directories map[string]IndexedDirectory `json:"directories"`
+}
@@ -0,0 +1,19 @@
+package entities
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIndexedDirectory(t *testing.T) {
+	modTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	indexed := NewIndexedDirectory(modTime, []FileEntry{{FileName: "one.avatar"}, {FileName: "extras", IsDirectory: true}, {FileName: "shared", IsDirectory: true, IsSymlink: true}})
+
+	if !indexed.Matches(modTime.In(time.FixedZone("CET", 3600))) || indexed.Matches(modTime.Add(time.Nanosecond)) {
+		t.Error("Matches() should compare instants")
+	}
+	entries := indexed.FileEntries()
+	if len(entries) != 3 || entries[0].FileName != "one.avatar" || entries[0].IsDirectory || !entries[1].IsDirectory || entries[1].IsSymlink || !entries[2].IsSymlink {
+		t.Errorf("FileEntries() = %+v", entries)
+	}
+}
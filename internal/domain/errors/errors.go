@@ -3,6 +3,7 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 // Top-level errors
@@ -15,15 +16,32 @@ var (
 	ErrFileSystem            = errors.New("file system error")
 	ErrCache                 = errors.New("cache error")
 	ErrInvalidConfiguration  = errors.New("invalid configuration")
+	ErrSlotNotFound          = errors.New("activation slot not found")
+	ErrNoPreviousActivation  = errors.New("no previously active outfit to restore")
+	ErrHookNotFound          = errors.New("hook not found")
+	ErrStaleRevision         = errors.New("stored state changed since it was loaded")
+	ErrNewerSchema           = errors.New("stored data was written by a newer version of outfitpicker")
+	ErrNothingToRestore      = errors.New("journal has no configuration to restore")
+	ErrInvalidBackup         = errors.New("invalid backup archive")
+	ErrProfileNotFound       = errors.New("profile not found")
+	ErrProfileExists         = errors.New("profile already exists")
+	ErrAlreadyInitialized    = errors.New("already set up")
+	ErrWearQueued            = errors.New("wear queued until the wardrobe can be read again")
+	ErrOutfitInArchive       = errors.New("outfit is inside an archive")
+	ErrPickCancelled         = errors.New("pick cancelled")
+	ErrNoWearHistory         = errors.New("wear history is only kept with sqlite storage")
+	ErrIncompleteJournal     = errors.New("journal does not record what was stored before it was started")
 )
 
 // Config errors
 var (
-	ErrPathTraversal     = errors.New("path traversal not allowed")
-	ErrPathTooLong       = errors.New("path too long")
-	ErrRestrictedPath    = errors.New("restricted path")
-	ErrSymlinkNotAllowed = errors.New("symlink not allowed")
-	ErrInvalidCharacters = errors.New("invalid characters")
+	ErrPathTraversal       = errors.New("path traversal not allowed")
+	ErrPathTooLong         = errors.New("path too long")
+	ErrRestrictedPath      = errors.New("restricted path")
+	ErrSymlinkNotAllowed   = errors.New("symlink not allowed")
+	ErrSymlinkLoop         = errors.New("symlink loop")
+	ErrSymlinkOutsideRoots = errors.New("symlink leads outside the allowed roots")
+	ErrInvalidCharacters   = errors.New("invalid characters")
 )
 
 // File system errors
@@ -72,14 +90,93 @@ func NewRotationCompletedError(category string) error {
 	return &RotationCompletedError{Category: category}
 }
 
+// NewStaleRevisionError reports a save based on revision expected when the
+// stored state is already at revision stored.
+func NewStaleRevisionError(expected, stored uint64) error {
+	return fmt.Errorf("%w: saving revision %d over revision %d", ErrStaleRevision, expected, stored)
+}
+
+// NewStaleCategoryError reports a change based on worn outfits in category
+// that have changed since they were loaded.
+func NewStaleCategoryError(category string) error {
+	return fmt.Errorf("%w: worn outfits in %s have changed", ErrStaleRevision, category)
+}
+
+// NewNewerSchemaError reports a stored document at a schema version this
+// build cannot read because it only knows versions up to supported.
+func NewNewerSchemaError(document string, version, supported int) error {
+	return fmt.Errorf("%w: %s has schema version %d, but this version only supports up to %d; upgrade outfitpicker to use it",
+		ErrNewerSchema, document, version, supported)
+}
+
+// NewNothingToRestoreError reports a restore to a point before the journal
+// recorded any configuration.
+func NewNothingToRestoreError(at time.Time) error {
+	return fmt.Errorf("%w as of %s", ErrNothingToRestore, at.Format(time.RFC3339))
+}
+
+// NewInvalidBackupError reports a backup archive that cannot be restored.
+func NewInvalidBackupError(name, problem string) error {
+	return fmt.Errorf("%w %s: %s", ErrInvalidBackup, name, problem)
+}
+
+// NewProfileNotFoundError reports a profile that has not been created.
+func NewProfileNotFoundError(name string) error {
+	return fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+}
+
+// NewProfileExistsError reports a profile name that is already taken.
+func NewProfileExistsError(name string) error {
+	return fmt.Errorf("%w: %s", ErrProfileExists, name)
+}
+
+// NewAlreadyInitializedError reports an init for something that is already
+// set up.
+func NewAlreadyInitializedError(what string) error {
+	return fmt.Errorf("%s is %w", what, ErrAlreadyInitialized)
+}
+
+// ErrTimedOut is wrapped when a hook or selection plugin outlives its timeout
+// or a file lock is not released within --lock-timeout.
+var ErrTimedOut = errors.New("timed out")
+
+// HookFailedError reports a hook that could not run or exited unsuccessfully.
+type HookFailedError struct {
+	Event   string
+	Command string
+	Output  string
+	Err     error
+}
+
+func (e *HookFailedError) Error() string {
+	message := fmt.Sprintf("%s hook %q failed: %v", e.Event, e.Command, e.Err)
+	if e.Output != "" {
+		message += ": " + e.Output
+	}
+	return message
+}
+
+func (e *HookFailedError) Unwrap() error {
+	return e.Err
+}
+
+func NewHookFailedError(event, command, output string, err error) error {
+	return &HookFailedError{Event: event, Command: command, Output: output, Err: err}
+}
+
 var (
 	topLevelErrors = []error{
 		ErrConfigurationNotFound, ErrCategoryNotFound, ErrNoOutfitsAvailable,
 		ErrFileSystem, ErrCache, ErrInvalidConfiguration,
+		ErrSlotNotFound, ErrNoPreviousActivation, ErrHookNotFound,
+		ErrStaleRevision, ErrNewerSchema, ErrNothingToRestore, ErrInvalidBackup,
+		ErrProfileNotFound, ErrProfileExists, ErrAlreadyInitialized,
+		ErrWearQueued, ErrOutfitInArchive, ErrPickCancelled,
+		ErrNoWearHistory, ErrIncompleteJournal,
 	}
 	configErrors = []error{
 		ErrPathTraversal, ErrPathTooLong, ErrRestrictedPath,
-		ErrSymlinkNotAllowed, ErrInvalidCharacters,
+		ErrSymlinkNotAllowed, ErrSymlinkLoop, ErrSymlinkOutsideRoots, ErrInvalidCharacters,
 	}
 	cacheErrors = []error{
 		ErrCacheEncoding, ErrCacheDecoding, ErrInvalidData,
@@ -3,6 +3,7 @@ package errors
 import (
 	"errors"
 	"testing"
+	"time"
 )
 
 func TestOutfitPickerError_Error(t *testing.T) {
@@ -16,6 +17,10 @@ func TestOutfitPickerError_Error(t *testing.T) {
 		{ErrFileSystem, "file system error"},
 		{ErrCache, "cache error"},
 		{ErrInvalidConfiguration, "invalid configuration"},
+		{ErrSlotNotFound, "activation slot not found"},
+		{ErrNoPreviousActivation, "no previously active outfit to restore"},
+		{ErrHookNotFound, "hook not found"},
+		{ErrStaleRevision, "stored state changed since it was loaded"},
 	}
 
 	for _, tt := range tests {
@@ -43,6 +48,87 @@ func TestNewRotationCompletedError(t *testing.T) {
 	}
 }
 
+func TestNewStaleRevisionError(t *testing.T) {
+	err := NewStaleRevisionError(3, 5)
+	want := "stored state changed since it was loaded: saving revision 3 over revision 5"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %v, want %v", got, want)
+	}
+	if MapError(err) != err {
+		t.Errorf("MapError(%v) = %v, want it unchanged", err, MapError(err))
+	}
+}
+
+func TestNewNewerSchemaError(t *testing.T) {
+	err := NewNewerSchemaError("config.json", 3, 1)
+	want := "stored data was written by a newer version of outfitpicker: config.json has schema version 3, but this version only supports up to 1; upgrade outfitpicker to use it"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %v, want %v", got, want)
+	}
+	if !errors.Is(MapError(err), ErrNewerSchema) {
+		t.Errorf("MapError(%v) = %v, want %v", err, MapError(err), ErrNewerSchema)
+	}
+}
+
+func TestNewNothingToRestoreError(t *testing.T) {
+	err := NewNothingToRestoreError(time.Date(2026, 9, 1, 23, 59, 59, 0, time.UTC))
+	want := "journal has no configuration to restore as of 2026-09-01T23:59:59Z"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %v, want %v", got, want)
+	}
+	if !errors.Is(MapError(err), ErrNothingToRestore) {
+		t.Errorf("MapError(%v) = %v, want %v", err, MapError(err), ErrNothingToRestore)
+	}
+}
+
+func TestNewInvalidBackupError(t *testing.T) {
+	err := NewInvalidBackupError("backup.tar.gz", "checksum mismatch for config.json")
+	want := "invalid backup archive backup.tar.gz: checksum mismatch for config.json"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %v, want %v", got, want)
+	}
+	if !errors.Is(MapError(err), ErrInvalidBackup) {
+		t.Errorf("MapError(%v) = %v, want %v", err, MapError(err), ErrInvalidBackup)
+	}
+}
+
+func TestNewProfileAndSetupErrors(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+		is   error
+	}{
+		{NewProfileNotFoundError("alex"), "profile not found: alex", ErrProfileNotFound},
+		{NewProfileExistsError("sam"), "profile already exists: sam", ErrProfileExists},
+		{NewAlreadyInitializedError("/wardrobe"), "/wardrobe is already set up", ErrAlreadyInitialized},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %v, want %v", got, tt.want)
+		}
+		if !errors.Is(MapError(tt.err), tt.is) {
+			t.Errorf("MapError(%v) = %v, want %v", tt.err, MapError(tt.err), tt.is)
+		}
+	}
+}
+
+func TestNewHookFailedError(t *testing.T) {
+	cause := errors.New("exit status 3")
+	err := NewHookFailedError("post-wear", "notify", "disk full", cause)
+	want := `post-wear hook "notify" failed: exit status 3: disk full`
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %v, want %v", got, want)
+	}
+	if !errors.Is(err, cause) {
+		t.Errorf("errors.Is(%v, cause) = false, want true", err)
+	}
+
+	timedOut := NewHookFailedError("pre-pick", "sync", "", ErrTimedOut)
+	if got := timedOut.Error(); got != `pre-pick hook "sync" failed: timed out` {
+		t.Errorf("Error() = %v", got)
+	}
+}
+
 func TestMapError(t *testing.T) {
 	tests := []struct {
 		name string
@@ -51,6 +137,9 @@ func TestMapError(t *testing.T) {
 	}{
 		{"nil error", nil, nil},
 		{"already top-level", ErrCategoryNotFound, ErrCategoryNotFound},
+		{"slot not found", ErrSlotNotFound, ErrSlotNotFound},
+		{"wear queued", ErrWearQueued, ErrWearQueued},
+		{"outfit in archive", ErrOutfitInArchive, ErrOutfitInArchive},
 		{"invalid input", NewInvalidInputError("test"), NewInvalidInputError("test")},
 		{"rotation completed", NewRotationCompletedError("casual"), NewRotationCompletedError("casual")},
 	}
@@ -63,6 +152,8 @@ func TestMapError(t *testing.T) {
 		{"path too long", ErrPathTooLong},
 		{"restricted path", ErrRestrictedPath},
 		{"symlink", ErrSymlinkNotAllowed},
+		{"symlink loop", ErrSymlinkLoop},
+		{"symlink outside roots", ErrSymlinkOutsideRoots},
 		{"invalid chars", ErrInvalidCharacters},
 	}
 	for _, ce := range configErrors {
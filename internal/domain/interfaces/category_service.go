@@ -1,9 +1,20 @@
 package interfaces
 
-import "github.com/dh85/outfitpicker/internal/domain/entities"
+import (
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/validation"
+)
 
 // CategoryService handles category-related operations.
 type CategoryService interface {
 	ScanCategories(rootPath string, excludedCategories map[string]bool) ([]entities.CategoryInfo, error)
 	GetOutfits(categoryPath string) ([]entities.FileEntry, error)
 }
+
+// SymlinkFollower is a CategoryService that can follow categories that are
+// symbolic links. Without it, such categories are not read.
+type SymlinkFollower interface {
+	// FollowingSymlinks returns the service treating symbolic links as
+	// policy says.
+	FollowingSymlinks(policy validation.SymlinkPolicy) CategoryService
+}
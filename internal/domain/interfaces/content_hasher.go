@@ -0,0 +1,17 @@
+package interfaces
+
+// ContentHasher identifies outfit files by their contents.
+type ContentHasher interface {
+	// Hash returns the content hash of the file at path, reusing the one
+	// recorded for it while the file's size and modification time are
+	// unchanged.
+	Hash(path string) (string, error)
+	// Recorded returns the hash last recorded for path, which may no longer
+	// exist.
+	Recorded(path string) (string, bool)
+	// Retain forgets the hashes recorded for every path but those in paths,
+	// such as files that were deleted.
+	Retain(paths map[string]bool)
+	// Save stores the hashes recorded and forgotten since the last save.
+	Save() error
+}
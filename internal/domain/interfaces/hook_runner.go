@@ -0,0 +1,8 @@
+package interfaces
+
+import "github.com/dh85/outfitpicker/internal/domain/entities"
+
+// HookRunner runs user-defined hooks for wardrobe events.
+type HookRunner interface {
+	Run(hook entities.Hook, event entities.HookEvent) error
+}
@@ -0,0 +1,9 @@
+package interfaces
+
+import "github.com/dh85/outfitpicker/internal/domain/entities"
+
+// OutfitInstaller installs outfit files into activation slots.
+type OutfitInstaller interface {
+	Install(sourcePath string, slot entities.ActivationSlot) error
+	Restore(slot entities.ActivationSlot) error
+}
@@ -6,6 +6,13 @@ import "github.com/dh85/outfitpicker/internal/domain/entities"
 type ConfigRepository interface {
 	Load() (*entities.Config, error)
 	Save(config *entities.Config) error
+	// Replace saves config in place of whatever is stored, whatever its
+	// revision and even when it cannot be read.
+	Replace(config *entities.Config) error
+	// Update loads, changes and saves the configuration as one locked step.
+	// change receives nil when nothing is stored and may return nil to skip
+	// the write.
+	Update(change func(current *entities.Config) (*entities.Config, error)) error
 	Delete() error
 }
 
@@ -13,5 +20,75 @@ type ConfigRepository interface {
 type CacheRepository interface {
 	Load() (*entities.OutfitCache, error)
 	Save(cache *entities.OutfitCache) error
+	// Replace saves cache in place of whatever is stored, whatever its
+	// revision and even when it cannot be read.
+	Replace(cache *entities.OutfitCache) error
+	// Update loads, changes and saves the cache as one locked step.
+	Update(change func(current *entities.OutfitCache) (*entities.OutfitCache, error)) error
 	Delete() error
-}
\ No newline at end of file
+}
+
+// WearHistoryRepository answers queries over every recorded wear, including
+// wears from rotations that have since been reset.
+type WearHistoryRepository interface {
+	// WearHistory returns the most recent wears first, limited to category
+	// unless it is empty and to limit records unless it is zero.
+	WearHistory(category string, limit int) ([]entities.WearRecord, error)
+	// WearStats returns per-outfit wear counts, most worn first.
+	WearStats(category string) ([]entities.OutfitWearStats, error)
+}
+
+// KnownWardrobeRepository stores the wardrobe recorded by the last run that
+// could read all of it.
+type KnownWardrobeRepository interface {
+	// Load returns nil when nothing was recorded.
+	Load() (*entities.KnownWardrobe, error)
+	// Update loads, changes and saves the recording as one locked step.
+	Update(change func(current *entities.KnownWardrobe) (*entities.KnownWardrobe, error)) error
+}
+
+// WearQueueRepository stores the wears queued while the wardrobe is offline.
+type WearQueueRepository interface {
+	// Load returns nil when nothing was ever queued.
+	Load() (*entities.WearQueue, error)
+	// Update loads, changes and saves the queue as one locked step.
+	Update(change func(current *entities.WearQueue) (*entities.WearQueue, error)) error
+}
+
+// StorageSelector moves config and cache to another storage backend.
+type StorageSelector interface {
+	SelectStorage(backend entities.StorageBackend) error
+}
+
+// Journal reads the append-only record of every change made to config and
+// cache, oldest first.
+type Journal interface {
+	Events() ([]entities.JournalEvent, error)
+}
+
+// BackupRepository stores backup archives of config and cache.
+type BackupRepository interface {
+	// Create writes contents to a new archive, at path if it is not empty.
+	Create(kind entities.BackupKind, reason string, contents entities.BackupContents, path string) (entities.BackupInfo, error)
+	// List returns the stored archives, newest first.
+	List() ([]entities.BackupInfo, error)
+	// Open reads and validates the stored archive called name, or the
+	// archive at that path.
+	Open(name string) (entities.BackupInfo, entities.BackupContents, error)
+	Delete(backup entities.BackupInfo) error
+}
+
+// ProfileRepository stores the directories that hold each profile's files.
+type ProfileRepository interface {
+	// List returns the default profile followed by the named profiles in
+	// name order.
+	List() ([]entities.Profile, error)
+	// Get returns the named profile, or ErrProfileNotFound if it has not
+	// been created. The default profile always exists.
+	Get(name string) (entities.Profile, error)
+	Create(name string) (entities.Profile, error)
+	Delete(name string) error
+	// Copy creates profile to with a copy of from's config, worn outfits,
+	// and journal.
+	Copy(from, to string) (entities.Profile, error)
+}
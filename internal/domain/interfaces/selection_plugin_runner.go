@@ -0,0 +1,8 @@
+package interfaces
+
+import "github.com/dh85/outfitpicker/internal/domain/entities"
+
+// SelectionPluginRunner asks an external selection plugin to rank candidates.
+type SelectionPluginRunner interface {
+	Select(plugin entities.SelectionPlugin, request entities.SelectionRequest) (entities.SelectionResponse, error)
+}
@@ -1,6 +1,8 @@
 package logic
 
 import (
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/dh85/outfitpicker/internal/domain/entities"
@@ -11,11 +13,33 @@ const (
 	OutfitFileExtension = "avatar"
 )
 
+// PreviewImageExtensions lists companion image extensions in order of preference.
+var PreviewImageExtensions = []string{"png", "jpg", "jpeg"}
+
 // IsValidOutfitFile checks if a filename is a valid outfit file.
 func IsValidOutfitFile(fileName string) bool {
 	return strings.HasSuffix(strings.ToLower(fileName), "."+OutfitFileExtension)
 }
 
+// IsPreviewImageFile checks if a filename is a supported companion image.
+func IsPreviewImageFile(fileName string) bool {
+	return previewExtensionRank(fileName) >= 0
+}
+
+func previewExtensionRank(fileName string) int {
+	extension := strings.TrimPrefix(strings.ToLower(filepath.Ext(fileName)), ".")
+	for rank, candidate := range PreviewImageExtensions {
+		if extension == candidate {
+			return rank
+		}
+	}
+	return -1
+}
+
+func fileStem(fileName string) string {
+	return strings.TrimSuffix(fileName, filepath.Ext(fileName))
+}
+
 // IsValidCategoryName checks if a category name is valid.
 func IsValidCategoryName(name string) bool {
 	return strings.TrimSpace(name) != ""
@@ -64,7 +88,7 @@ func ValidateOutfit(outfit entities.OutfitReference) error {
 func FilterAvailableOutfits(files []entities.FileEntry, wornOutfits map[string]bool) []entities.FileEntry {
 	var available []entities.FileEntry
 	for _, file := range files {
-		if !wornOutfits[file.FileName] {
+		if !wornOutfits[file.Key()] {
 			available = append(available, file)
 		}
 	}
@@ -86,9 +110,253 @@ func FilterOutfitFiles(files []entities.FileEntry) []entities.FileEntry {
 func FilterUnwornOutfits(files []entities.FileEntry, wornOutfits map[string]bool) []entities.FileEntry {
 	var unworn []entities.FileEntry
 	for _, file := range files {
-		if !wornOutfits[file.FileName] {
+		if !wornOutfits[file.Key()] {
 			unworn = append(unworn, file)
 		}
 	}
 	return unworn
 }
+
+// LinkOutfitPreviews links each outfit to an image in entries with the same
+// stem, preferring extensions in PreviewImageExtensions order.
+func LinkOutfitPreviews(outfits, entries []entities.FileEntry) []entities.FileEntry {
+	previews := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDirectory || !IsPreviewImageFile(entry.FileName) {
+			continue
+		}
+		stem := fileStem(entry.FileName)
+		current, exists := previews[stem]
+		if !exists || previewExtensionRank(entry.FileName) < previewExtensionRank(current) {
+			previews[stem] = entry.FileName
+		}
+	}
+
+	linked := make([]entities.FileEntry, len(outfits))
+	for index, outfit := range outfits {
+		outfit.PreviewFileName = previews[fileStem(outfit.FileName)]
+		linked[index] = outfit
+	}
+	return linked
+}
+
+// CanonicalRoot returns the absolute, symlink-resolved form of a wardrobe
+// root, so that one wardrobe reached by different paths keeps one history. A
+// root that cannot be resolved, such as an unmounted one, is only made
+// absolute and cleaned.
+func CanonicalRoot(root string) string {
+	if root == "" {
+		return ""
+	}
+	absolute, err := filepath.Abs(root)
+	if err != nil {
+		return filepath.Clean(root)
+	}
+	if resolved, err := filepath.EvalSymlinks(absolute); err == nil {
+		return resolved
+	}
+	return absolute
+}
+
+// MergeCategoryInfos merges the categories scanned in each wardrobe root into
+// one list sorted by name. A category keeps the path of the first root it is
+// in, counts the outfits of every root, and has outfits if any root gives it
+// some; failing that, it keeps the error of a root that could not read it.
+func MergeCategoryInfos(perRoot ...[]entities.CategoryInfo) []entities.CategoryInfo {
+	merged := make(map[string]entities.CategoryInfo)
+	for _, infos := range perRoot {
+		for _, info := range infos {
+			current, exists := merged[info.Category.Name]
+			if !exists {
+				merged[info.Category.Name] = info
+				continue
+			}
+			current.OutfitCount += info.OutfitCount
+			if categoryStateRank(info.State) > categoryStateRank(current.State) {
+				current.State = info.State
+				current.Error = info.Error
+			}
+			merged[info.Category.Name] = current
+		}
+	}
+
+	result := make([]entities.CategoryInfo, 0, len(merged))
+	for _, info := range merged {
+		result = append(result, info)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Category.Name < result[j].Category.Name
+	})
+	return result
+}
+
+func categoryStateRank(state entities.CategoryState) int {
+	switch state {
+	case entities.CategoryStateUserExcluded:
+		return 4
+	case entities.CategoryStateHasOutfits:
+		return 3
+	case entities.CategoryStateNoAvatarFiles:
+		return 1
+	default:
+		if state.Failed() {
+			return 2
+		}
+		return 0
+	}
+}
+
+// ReconcileCache matches cache to the wardrobe, whose outfit keys are given
+// by category name. Totals are set to the number of outfits, worn outfits
+// without a file are dropped, and so are categories no longer in the
+// wardrobe. It returns the reconciled cache and what differed, by category
+// name.
+func ReconcileCache(cache entities.OutfitCache, outfits map[string][]string) (entities.OutfitCache, []entities.CategoryReconciliation) {
+	names := make([]string, 0, len(cache.Categories))
+	for name := range cache.Categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var changes []entities.CategoryReconciliation
+	for _, name := range names {
+		category := cache.Categories[name]
+		keys, exists := outfits[name]
+		present := make(map[string]bool, len(keys))
+		for _, key := range keys {
+			present[key] = true
+		}
+		change := entities.CategoryReconciliation{Category: name, OldTotal: category.TotalOutfits, NewTotal: len(keys), Removed: !exists}
+		for key := range category.WornOutfits {
+			if !present[key] {
+				change.Orphans = append(change.Orphans, key)
+			}
+		}
+		if !change.Removed && len(change.Orphans) == 0 && change.OldTotal == change.NewTotal {
+			continue
+		}
+		sort.Strings(change.Orphans)
+		changes = append(changes, change)
+
+		if change.Removed {
+			cache = cache.Removing(name)
+			continue
+		}
+		for _, orphan := range change.Orphans {
+			category = category.Removing(orphan)
+		}
+		category.TotalOutfits = change.NewTotal
+		cache = cache.Updating(name, category)
+	}
+	return cache, changes
+}
+
+// DiffWardrobe compares outfits, the outfit keys of each category by name,
+// with the wardrobe known from the last run. It returns the categories with
+// added or removed outfits in name order.
+func DiffWardrobe(known entities.KnownWardrobe, outfits map[string][]string) []entities.CategoryChanges {
+	names := make([]string, 0, len(outfits)+len(known.Categories))
+	for name := range outfits {
+		names = append(names, name)
+	}
+	for name := range known.Categories {
+		if _, ok := outfits[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []entities.CategoryChanges
+	for _, name := range names {
+		keys, exists := outfits[name]
+		change := entities.CategoryChanges{Category: name, Added: !known.HasCategory(name), Removed: !exists}
+		present := make(map[string]bool, len(keys))
+		for _, key := range keys {
+			present[key] = true
+			if !known.HasOutfit(name, key) {
+				change.AddedOutfits = append(change.AddedOutfits, key)
+			}
+		}
+		for key := range known.Categories[name] {
+			if !present[key] {
+				change.RemovedOutfits = append(change.RemovedOutfits, key)
+			}
+		}
+		if !change.Added && !change.Removed && len(change.AddedOutfits) == 0 && len(change.RemovedOutfits) == 0 {
+			continue
+		}
+		sort.Strings(change.AddedOutfits)
+		sort.Strings(change.RemovedOutfits)
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// TrackNewArrivals returns arrivals with the outfits added by changes, less
+// those no longer in outfits or worn in cache. It returns nil when no new
+// arrivals are left.
+func TrackNewArrivals(arrivals map[string]map[string]bool, changes []entities.CategoryChanges, outfits map[string][]string, cache entities.OutfitCache) map[string]map[string]bool {
+	candidates := make(map[string]map[string]bool, len(arrivals)+len(changes))
+	for category, keys := range arrivals {
+		candidates[category] = make(map[string]bool, len(keys))
+		for key := range keys {
+			candidates[category][key] = true
+		}
+	}
+	for _, change := range changes {
+		if len(change.AddedOutfits) > 0 && candidates[change.Category] == nil {
+			candidates[change.Category] = make(map[string]bool, len(change.AddedOutfits))
+		}
+		for _, key := range change.AddedOutfits {
+			candidates[change.Category][key] = true
+		}
+	}
+
+	var tracked map[string]map[string]bool
+	for category, keys := range candidates {
+		present := make(map[string]bool, len(outfits[category]))
+		for _, key := range outfits[category] {
+			present[key] = true
+		}
+		for key := range keys {
+			if !present[key] || cache.Categories[category].WornOutfits[key] {
+				continue
+			}
+			if tracked == nil {
+				tracked = make(map[string]map[string]bool)
+			}
+			if tracked[category] == nil {
+				tracked[category] = make(map[string]bool)
+			}
+			tracked[category][key] = true
+		}
+	}
+	return tracked
+}
+
+// NewArrivalsFirst returns the outfits that known lists as new arrivals, or
+// all of outfits when none of them is.
+func NewArrivalsFirst(outfits []entities.OutfitReference, known entities.KnownWardrobe) []entities.OutfitReference {
+	var arrivals []entities.OutfitReference
+	for _, outfit := range outfits {
+		if known.IsNewArrival(outfit.Category.Name, outfit.Key()) {
+			arrivals = append(arrivals, outfit)
+		}
+	}
+	if len(arrivals) == 0 {
+		return outfits
+	}
+	return arrivals
+}
+
+// SplitOutfitKey returns the file name and wardrobe root of the outfit with
+// key among roots. The root is empty for the first root, as in
+// entities.OutfitKey, and for keys that name none of the other roots.
+func SplitOutfitKey(key string, roots []string) (fileName, root string) {
+	for _, candidate := range roots[min(1, len(roots)):] {
+		if name, found := strings.CutSuffix(key, "@"+candidate); found && name != "" {
+			return name, candidate
+		}
+	}
+	return key, ""
+}
@@ -1,6 +1,10 @@
 package logic
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/dh85/outfitpicker/internal/domain/entities"
@@ -230,3 +234,227 @@ func TestFilterUnwornOutfits(t *testing.T) {
 		t.Errorf("FilterUnwornOutfits() length = %v, want 2", len(unworn))
 	}
 }
+
+func TestIsPreviewImageFile(t *testing.T) {
+	tests := map[string]bool{
+		"chic4.png":    true,
+		"CHIC4.PNG":    true,
+		"look.jpg":     true,
+		"look.jpeg":    true,
+		"look.gif":     false,
+		"look.avatar":  false,
+		"png":          false,
+		"archive.png/": false,
+	}
+
+	for fileName, want := range tests {
+		if got := IsPreviewImageFile(fileName); got != want {
+			t.Errorf("IsPreviewImageFile(%q) = %v, want %v", fileName, got, want)
+		}
+	}
+}
+
+func TestLinkOutfitPreviews(t *testing.T) {
+	entries := []entities.FileEntry{
+		{FileName: "chic1.avatar"},
+		{FileName: "chic1.jpg"},
+		{FileName: "chic1.png"},
+		{FileName: "chic2.avatar"},
+		{FileName: "chic3.avatar"},
+		{FileName: "chic3.png", IsDirectory: true},
+		{FileName: "chic4.png"},
+	}
+	outfits := FilterOutfitFiles(entries)
+
+	linked := LinkOutfitPreviews(outfits, entries)
+
+	want := map[string]string{"chic1.avatar": "chic1.png", "chic2.avatar": "", "chic3.avatar": ""}
+	if len(linked) != len(want) {
+		t.Fatalf("LinkOutfitPreviews() length = %v, want %v", len(linked), len(want))
+	}
+	for _, outfit := range linked {
+		if outfit.PreviewFileName != want[outfit.FileName] {
+			t.Errorf("%s preview = %q, want %q", outfit.FileName, outfit.PreviewFileName, want[outfit.FileName])
+		}
+	}
+	if outfits[0].PreviewFileName != "" {
+		t.Errorf("LinkOutfitPreviews() modified its input")
+	}
+}
+
+func TestCanonicalRoot(t *testing.T) {
+	dir := t.TempDir()
+	wardrobe := filepath.Join(dir, "wardrobe")
+	if err := os.Mkdir(wardrobe, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := filepath.EvalSymlinks(wardrobe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(wardrobe, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	if got := CanonicalRoot(link + string(filepath.Separator)); got != resolved {
+		t.Fatalf("CanonicalRoot(link) = %q, want %q", got, resolved)
+	}
+	if got := CanonicalRoot(filepath.Join(wardrobe, "..", "wardrobe")); got != resolved {
+		t.Fatalf("CanonicalRoot(unclean) = %q, want %q", got, resolved)
+	}
+	missing := filepath.Join(dir, "unmounted", "..", "unmounted")
+	if got := CanonicalRoot(missing); got != filepath.Join(dir, "unmounted") {
+		t.Fatalf("CanonicalRoot(missing) = %q, want it cleaned", got)
+	}
+	if got := CanonicalRoot(""); got != "" {
+		t.Fatalf("CanonicalRoot(\"\") = %q, want empty", got)
+	}
+}
+
+func TestMergeCategoryInfos(t *testing.T) {
+	info := func(root, name string, state entities.CategoryState, count int) entities.CategoryInfo {
+		return entities.NewCategoryInfo(entities.NewCategoryReference(name, filepath.Join(root, name)), state, count)
+	}
+	locked := errors.New("/nas/shoes: permission denied")
+	merged := MergeCategoryInfos(
+		[]entities.CategoryInfo{info("/ssd", "formal", entities.CategoryStateEmpty, 0), info("/ssd", "casual", entities.CategoryStateHasOutfits, 2), info("/ssd", "hats", entities.CategoryStateUserExcluded, 0), info("/ssd", "shoes", entities.CategoryStateEmpty, 0), info("/ssd", "bags", entities.CategoryStateHasOutfits, 1)},
+		[]entities.CategoryInfo{info("/nas", "formal", entities.CategoryStateNoAvatarFiles, 0), info("/nas", "casual", entities.CategoryStateHasOutfits, 3), info("/nas", "beach", entities.CategoryStateHasOutfits, 1), info("/nas", "hats", entities.CategoryStateHasOutfits, 4), info("/nas", "shoes", entities.CategoryStatePermissionDenied, 0).WithError(locked), info("/nas", "bags", entities.CategoryStateTimedOut, 0).WithError(locked)},
+	)
+
+	want := []entities.CategoryInfo{
+		info("/ssd", "bags", entities.CategoryStateHasOutfits, 1),
+		info("/nas", "beach", entities.CategoryStateHasOutfits, 1),
+		info("/ssd", "casual", entities.CategoryStateHasOutfits, 5),
+		info("/ssd", "formal", entities.CategoryStateNoAvatarFiles, 0),
+		info("/ssd", "hats", entities.CategoryStateUserExcluded, 4),
+		info("/ssd", "shoes", entities.CategoryStatePermissionDenied, 0).WithError(locked),
+	}
+	if len(merged) != len(want) {
+		t.Fatalf("MergeCategoryInfos() = %+v, want %+v", merged, want)
+	}
+	for index := range want {
+		if merged[index] != want[index] {
+			t.Errorf("MergeCategoryInfos()[%d] = %+v, want %+v", index, merged[index], want[index])
+		}
+	}
+}
+
+func TestReconcileCache(t *testing.T) {
+	casual := entities.NewCategoryCache(3).Adding("kept.avatar").Adding("gone.avatar")
+	formal := entities.NewCategoryCache(1).Adding("suit.avatar")
+	beach := entities.NewCategoryCache(2).Adding("towel.avatar")
+	cache := entities.NewOutfitCache().Updating("casual", casual).Updating("formal", formal).Updating("beach", beach)
+
+	reconciled, changes := ReconcileCache(cache, map[string][]string{
+		"casual": {"kept.avatar", "new.avatar", "other.avatar", "more.avatar"},
+		"formal": {"suit.avatar"},
+	})
+
+	want := []entities.CategoryReconciliation{
+		{Category: "beach", OldTotal: 2, NewTotal: 0, Orphans: []string{"towel.avatar"}, Removed: true},
+		{Category: "casual", OldTotal: 3, NewTotal: 4, Orphans: []string{"gone.avatar"}},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("ReconcileCache() changes = %+v, want %+v", changes, want)
+	}
+	if _, ok := reconciled.Categories["beach"]; ok {
+		t.Error("beach is still cached after its directory went away")
+	}
+	got := reconciled.Categories["casual"]
+	if got.TotalOutfits != 4 || len(got.WornOutfits) != 1 || !got.WornOutfits["kept.avatar"] {
+		t.Errorf("casual = %+v, want a total of 4 with only kept.avatar worn", got)
+	}
+	if !reflect.DeepEqual(reconciled.Categories["formal"], formal) {
+		t.Errorf("formal = %+v, want it unchanged", reconciled.Categories["formal"])
+	}
+	if len(cache.Categories["casual"].WornOutfits) != 2 {
+		t.Error("ReconcileCache() changed the cache it was given")
+	}
+}
+
+func TestDiffWardrobe(t *testing.T) {
+	known := entities.NewKnownWardrobe(map[string][]string{
+		"casual": {"kept.avatar", "gone.avatar"},
+		"formal": {"suit.avatar"},
+		"hats":   {"cap.avatar"},
+	}, nil)
+
+	changes := DiffWardrobe(known, map[string][]string{
+		"beach":  {"towel.avatar"},
+		"casual": {"kept.avatar", "new.avatar"},
+		"formal": {"suit.avatar"},
+	})
+
+	want := []entities.CategoryChanges{
+		{Category: "beach", Added: true, AddedOutfits: []string{"towel.avatar"}},
+		{Category: "casual", AddedOutfits: []string{"new.avatar"}, RemovedOutfits: []string{"gone.avatar"}},
+		{Category: "hats", Removed: true, RemovedOutfits: []string{"cap.avatar"}},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("DiffWardrobe() = %+v, want %+v", changes, want)
+	}
+}
+
+func TestTrackNewArrivals(t *testing.T) {
+	arrivals := map[string]map[string]bool{
+		"casual": {"unworn.avatar": true, "worn.avatar": true, "deleted.avatar": true},
+	}
+	changes := []entities.CategoryChanges{{Category: "beach", Added: true, AddedOutfits: []string{"towel.avatar"}}}
+	outfits := map[string][]string{
+		"beach":  {"towel.avatar"},
+		"casual": {"unworn.avatar", "worn.avatar"},
+	}
+	cache := entities.NewOutfitCache().Updating("casual", entities.NewCategoryCache(2).Adding("worn.avatar"))
+
+	tracked := TrackNewArrivals(arrivals, changes, outfits, cache)
+
+	want := map[string]map[string]bool{"beach": {"towel.avatar": true}, "casual": {"unworn.avatar": true}}
+	if !reflect.DeepEqual(tracked, want) {
+		t.Fatalf("TrackNewArrivals() = %v, want %v", tracked, want)
+	}
+	if len(arrivals["casual"]) != 3 {
+		t.Error("TrackNewArrivals() changed the arrivals it was given")
+	}
+	if got := TrackNewArrivals(nil, nil, outfits, cache); got != nil {
+		t.Errorf("TrackNewArrivals() with none = %v, want nil", got)
+	}
+}
+
+func TestNewArrivalsFirst(t *testing.T) {
+	casual := entities.NewCategoryReference("casual", "/wardrobe/casual")
+	outfits := []entities.OutfitReference{
+		entities.NewOutfitReference("old.avatar", casual),
+		entities.NewOutfitReference("new.avatar", casual),
+	}
+	known := entities.NewKnownWardrobe(map[string][]string{"casual": {"old.avatar", "new.avatar"}}, nil)
+
+	if got := NewArrivalsFirst(outfits, known); len(got) != 2 {
+		t.Errorf("NewArrivalsFirst() without arrivals = %v, want every outfit", got)
+	}
+	known.NewArrivals = map[string]map[string]bool{"casual": {"new.avatar": true}}
+	if got := NewArrivalsFirst(outfits, known); len(got) != 1 || got[0].FileName != "new.avatar" {
+		t.Errorf("NewArrivalsFirst() = %v, want only new.avatar", got)
+	}
+}
+
+func TestSplitOutfitKey(t *testing.T) {
+	roots := []string{"/wardrobe", "/nas"}
+	tests := []struct {
+		key, wantName, wantRoot string
+	}{
+		{"look.avatar", "look.avatar", ""},
+		{"look.avatar@/nas", "look.avatar", "/nas"},
+		{"look.avatar@/wardrobe", "look.avatar@/wardrobe", ""},
+		{"me@/usb.avatar", "me@/usb.avatar", ""},
+	}
+	for _, tt := range tests {
+		name, root := SplitOutfitKey(tt.key, roots)
+		if name != tt.wantName || root != tt.wantRoot {
+			t.Errorf("SplitOutfitKey(%q) = %q, %q; want %q, %q", tt.key, name, root, tt.wantName, tt.wantRoot)
+		}
+	}
+	if name, root := SplitOutfitKey("look.avatar", nil); name != "look.avatar" || root != "" {
+		t.Errorf("SplitOutfitKey() without roots = %q, %q; want the key", name, root)
+	}
+}
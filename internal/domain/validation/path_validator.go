@@ -15,8 +15,15 @@ var restrictedPaths = []string{
 	"/etc", "/usr", "/bin", "/sbin", "/System", "/private", "/var", "/tmp", "/root",
 }
 
-// ValidatePath validates a filesystem path for security issues.
+// ValidatePath validates a filesystem path for security issues, rejecting
+// paths that go through symbolic links.
 func ValidatePath(path string) error {
+	return ValidatePathWithPolicy(path, SymlinkPolicy{})
+}
+
+// ValidatePathWithPolicy validates a filesystem path like ValidatePath, but
+// lets it go through the symbolic links policy follows.
+func ValidatePathWithPolicy(path string, policy SymlinkPolicy) error {
 	if err := validateCharacters(path); err != nil {
 		return err
 	}
@@ -29,7 +36,7 @@ func ValidatePath(path string) error {
 	if err := validateRestrictedPaths(path); err != nil {
 		return err
 	}
-	if err := validateSymlinks(path); err != nil {
+	if err := validateSymlinks(path, policy); err != nil {
 		return err
 	}
 	return nil
@@ -74,7 +81,7 @@ func validateRestrictedPaths(path string) error {
 	return nil
 }
 
-func validateSymlinks(path string) error {
+func validateSymlinks(path string, policy SymlinkPolicy) error {
 	cleaned := filepath.Clean(path)
 	volume := filepath.VolumeName(cleaned)
 	remaining := strings.TrimPrefix(cleaned, volume)
@@ -107,7 +114,9 @@ func validateSymlinks(path string) error {
 			return err
 		}
 		if info.Mode()&os.ModeSymlink != 0 {
-			return errors.ErrSymlinkNotAllowed
+			if _, err := policy.FollowLink(current); err != nil {
+				return err
+			}
 		}
 	}
 
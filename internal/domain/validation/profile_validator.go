@@ -0,0 +1,23 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+const maxProfileNameLength = 64
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateProfileName checks that name can be used as a profile's directory
+// name: letters, digits, '.', '-' and '_', starting with a letter or digit.
+func ValidateProfileName(name string) error {
+	if len(name) > maxProfileNameLength || !profileNamePattern.MatchString(name) {
+		return errors.NewInvalidInputError(fmt.Sprintf(
+			"profile name %q must start with a letter or digit and contain only letters, digits, '.', '-' and '_' (at most %d characters)",
+			name, maxProfileNameLength))
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package validation
+
+import (
+	stderrors "errors"
+	"strings"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+func TestValidateProfileName(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		wantErr bool
+	}{
+		{name: "simple", profile: "alex"},
+		{name: "punctuation", profile: "Sam_2.work-wear"},
+		{name: "longest", profile: strings.Repeat("a", maxProfileNameLength)},
+		{name: "empty", profile: "", wantErr: true},
+		{name: "hidden", profile: ".alex", wantErr: true},
+		{name: "path", profile: "../alex", wantErr: true},
+		{name: "separator", profile: "a/b", wantErr: true},
+		{name: "space", profile: "alex b", wantErr: true},
+		{name: "too long", profile: strings.Repeat("a", maxProfileNameLength+1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProfileName(tt.profile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateProfileName(%q) error = %v, wantErr %v", tt.profile, err, tt.wantErr)
+			}
+			var invalidInput *errors.InvalidInputError
+			if err != nil && !stderrors.As(err, &invalidInput) {
+				t.Fatalf("ValidateProfileName(%q) error = %T, want invalid input", tt.profile, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package validation
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+// SymlinkPolicy decides whether wardrobe paths may go through symbolic
+// links. The zero policy rejects them.
+type SymlinkPolicy struct {
+	// Follow lets a path go through symbolic links that resolve without a
+	// loop.
+	Follow bool
+	// AllowedRoots, when set, are the directories a followed link must lead
+	// into.
+	AllowedRoots []string
+}
+
+// FollowLink returns where the symbolic link at link leads, if the policy
+// lets it be followed. It fails with ErrSymlinkNotAllowed when links are not
+// followed, with ErrSymlinkLoop when the link leads back to itself or to a
+// directory it is in, and with ErrSymlinkOutsideRoots when it leads outside
+// the allowed roots or into a restricted path.
+func (p SymlinkPolicy) FollowLink(link string) (string, error) {
+	if !p.Follow {
+		return "", errors.ErrSymlinkNotAllowed
+	}
+	target, err := EvalSymlinks(link)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(target); err != nil {
+		return "", err
+	}
+	if parent, err := EvalSymlinks(filepath.Dir(link)); err == nil && isWithin(parent, target) {
+		return "", fmt.Errorf("%s leads to %s, which holds it: %w", link, target, errors.ErrSymlinkLoop)
+	}
+	if validateRestrictedPaths(target) != nil || !p.allows(target) {
+		return "", fmt.Errorf("%s leads to %s: %w", link, target, errors.ErrSymlinkOutsideRoots)
+	}
+	return target, nil
+}
+
+// allows reports whether target is inside one of the allowed roots, or
+// whether any target is allowed.
+func (p SymlinkPolicy) allows(target string) bool {
+	if len(p.AllowedRoots) == 0 {
+		return true
+	}
+	for _, root := range p.AllowedRoots {
+		if resolved, err := EvalSymlinks(root); err == nil && isWithin(target, resolved) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWithin reports whether path is dir or inside it.
+func isWithin(path, dir string) bool {
+	relative, err := filepath.Rel(dir, path)
+	return err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
+
+// EvalSymlinks returns the absolute path path leads to once every symbolic
+// link in it is followed. Unlike filepath.EvalSymlinks it tells a loop apart
+// from other failures, failing with ErrSymlinkLoop when a link is met again
+// on the way, and it leaves the part of path that does not exist yet as it
+// is.
+func EvalSymlinks(path string) (string, error) {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	separator := string(filepath.Separator)
+	volume := filepath.VolumeName(absolute)
+	resolved := volume + separator
+	pending := strings.Split(strings.TrimPrefix(absolute[len(volume):], separator), separator)
+	followed := make(map[string]bool)
+	for len(pending) > 0 {
+		part := pending[0]
+		pending = pending[1:]
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			resolved = filepath.Dir(resolved)
+			continue
+		}
+
+		next := filepath.Join(resolved, part)
+		info, err := os.Lstat(next)
+		if os.IsNotExist(err) {
+			return filepath.Join(append([]string{next}, pending...)...), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&os.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+		if followed[next] {
+			return "", fmt.Errorf("%s: %w", path, errors.ErrSymlinkLoop)
+		}
+		followed[next] = true
+		target, err := os.Readlink(next)
+		if err != nil {
+			return "", err
+		}
+		if filepath.IsAbs(target) {
+			targetVolume := filepath.VolumeName(target)
+			resolved = targetVolume + separator
+			target = strings.TrimPrefix(target[len(targetVolume):], separator)
+		}
+		pending = append(strings.Split(target, separator), pending...)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,127 @@
+package validation
+
+import (
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+// linkedWardrobe creates, under the working directory, a wardrobe whose
+// "shared" category is a symbolic link to a pack kept beside it:
+//
+//	base/wardrobe/shared -> base/packs/summer
+func linkedWardrobe(t *testing.T) (base, wardrobe, link string) {
+	t.Helper()
+	workspaceDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd() error = %v", err)
+	}
+	base, err = os.MkdirTemp(workspaceDir, "symlink-policy-")
+	if err != nil {
+		t.Fatalf("MkdirTemp() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.RemoveAll(base)
+	})
+
+	wardrobe = filepath.Join(base, "wardrobe")
+	pack := filepath.Join(base, "packs", "summer")
+	for _, dir := range []string{wardrobe, pack} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+	}
+	link = filepath.Join(wardrobe, "shared")
+	if err := os.Symlink(pack, link); err != nil {
+		t.Skipf("os.Symlink() unavailable: %v", err)
+	}
+	return base, wardrobe, link
+}
+
+func TestSymlinkPolicy_FollowLink(t *testing.T) {
+	base, wardrobe, link := linkedWardrobe(t)
+	pack, err := EvalSymlinks(filepath.Join(base, "packs", "summer"))
+	if err != nil {
+		t.Fatalf("EvalSymlinks() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		policy  SymlinkPolicy
+		wantErr error
+	}{
+		{"rejected by default", SymlinkPolicy{}, errors.ErrSymlinkNotAllowed},
+		{"followed anywhere", SymlinkPolicy{Follow: true}, nil},
+		{"followed within its roots", SymlinkPolicy{Follow: true, AllowedRoots: []string{wardrobe, filepath.Join(base, "packs")}}, nil},
+		{"outside its roots", SymlinkPolicy{Follow: true, AllowedRoots: []string{wardrobe}}, errors.ErrSymlinkOutsideRoots},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := tt.policy.FollowLink(link)
+			if !stderrors.Is(err, tt.wantErr) {
+				t.Fatalf("FollowLink() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && target != pack {
+				t.Errorf("FollowLink() = %q, want %q", target, pack)
+			}
+		})
+	}
+}
+
+func TestSymlinkPolicy_FollowLink_DetectsLoops(t *testing.T) {
+	_, wardrobe, _ := linkedWardrobe(t)
+	policy := SymlinkPolicy{Follow: true}
+
+	back := filepath.Join(wardrobe, "back")
+	if err := os.Symlink("..", back); err != nil {
+		t.Fatalf("Symlink() error = %v", err)
+	}
+	if _, err := policy.FollowLink(back); !stderrors.Is(err, errors.ErrSymlinkLoop) {
+		t.Errorf("FollowLink() of a link to its own parent error = %v, want %v", err, errors.ErrSymlinkLoop)
+	}
+
+	first, second := filepath.Join(wardrobe, "first"), filepath.Join(wardrobe, "second")
+	if err := os.Symlink(second, first); err != nil {
+		t.Fatalf("Symlink() error = %v", err)
+	}
+	if err := os.Symlink(first, second); err != nil {
+		t.Fatalf("Symlink() error = %v", err)
+	}
+	if _, err := policy.FollowLink(first); !stderrors.Is(err, errors.ErrSymlinkLoop) {
+		t.Errorf("FollowLink() of links leading to each other error = %v, want %v", err, errors.ErrSymlinkLoop)
+	}
+	if err := ValidatePathWithPolicy(filepath.Join(first, "outfits"), policy); !stderrors.Is(err, errors.ErrSymlinkLoop) {
+		t.Errorf("ValidatePathWithPolicy() error = %v, want %v", err, errors.ErrSymlinkLoop)
+	}
+}
+
+func TestValidatePathWithPolicy_FollowsLinks(t *testing.T) {
+	_, wardrobe, link := linkedWardrobe(t)
+	path := filepath.Join(link, "casual")
+
+	if err := ValidatePathWithPolicy(path, SymlinkPolicy{Follow: true}); err != nil {
+		t.Errorf("ValidatePathWithPolicy() error = %v, want the link followed", err)
+	}
+	if err := ValidatePathWithPolicy(path, SymlinkPolicy{Follow: true, AllowedRoots: []string{wardrobe}}); !stderrors.Is(err, errors.ErrSymlinkOutsideRoots) {
+		t.Errorf("ValidatePathWithPolicy() error = %v, want %v", err, errors.ErrSymlinkOutsideRoots)
+	}
+}
+
+func TestEvalSymlinks_KeepsMissingPartsOfThePath(t *testing.T) {
+	base, _, link := linkedWardrobe(t)
+	want, err := filepath.EvalSymlinks(filepath.Join(base, "packs", "summer"))
+	if err != nil {
+		t.Fatalf("filepath.EvalSymlinks() error = %v", err)
+	}
+
+	got, err := EvalSymlinks(filepath.Join(link, ".", "casual", "..", "formal"))
+	if err != nil {
+		t.Fatalf("EvalSymlinks() error = %v", err)
+	}
+	if want = filepath.Join(want, "formal"); got != want {
+		t.Errorf("EvalSymlinks() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,430 @@
+package persistence
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+)
+
+// BackupDirName is the directory next to config.json that backups and
+// snapshots are kept in.
+const BackupDirName = "backups"
+
+const (
+	backupExtension    = ".tar.gz"
+	backupFormat       = 1
+	backupMetadataFile = "metadata.json"
+	backupConfigFile   = "config.json"
+	backupCacheFile    = "cache.json"
+	// maxBackupEntrySize bounds what is read from one archive entry, so a
+	// hostile archive cannot exhaust memory.
+	maxBackupEntrySize = 16 << 20
+)
+
+// backupMetadata is the first entry of every archive. Files maps each other
+// entry to its SHA-256 checksum.
+type backupMetadata struct {
+	Format        int                 `json:"format"`
+	Kind          entities.BackupKind `json:"kind"`
+	Reason        string              `json:"reason,omitempty"`
+	CreatedAt     time.Time           `json:"createdAt"`
+	ConfigVersion int                 `json:"configVersion"`
+	CacheVersion  int                 `json:"cacheVersion,omitempty"`
+	Files         map[string]string   `json:"files"`
+}
+
+// BackupStore keeps backup archives: gzip-compressed tarballs holding the
+// configuration and cache as JSON, whichever storage backend they came from,
+// plus metadata with checksums.
+type BackupStore struct {
+	dir func() (string, error)
+	now func() time.Time
+}
+
+// NewBackupStore returns a store for archives in dir.
+func NewBackupStore(dir func() (string, error)) *BackupStore {
+	return &BackupStore{dir: dir, now: time.Now}
+}
+
+// Create writes contents to a new archive in the backup directory, or at path
+// if it is not empty. It never replaces an existing file.
+func (s *BackupStore) Create(kind entities.BackupKind, reason string, contents entities.BackupContents, path string) (entities.BackupInfo, error) {
+	if contents.Config == nil {
+		return entities.BackupInfo{}, errors.ErrConfigurationNotFound
+	}
+	metadata := backupMetadata{
+		Format:    backupFormat,
+		Kind:      kind,
+		Reason:    reason,
+		CreatedAt: s.now().UTC(),
+		Files:     map[string]string{},
+	}
+	config := *contents.Config
+	config.Storage = ""
+	configStamps.stamp(&config, 0)
+	metadata.ConfigVersion = config.Version
+	entries := []backupEntry{}
+	configData, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return entities.BackupInfo{}, err
+	}
+	entries = append(entries, backupEntry{name: backupConfigFile, data: configData})
+	if contents.Cache != nil {
+		cache := *contents.Cache
+		cacheStamps.stamp(&cache, 0)
+		metadata.CacheVersion = cache.Version
+		cacheData, err := json.MarshalIndent(cache, "", "  ")
+		if err != nil {
+			return entities.BackupInfo{}, err
+		}
+		entries = append(entries, backupEntry{name: backupCacheFile, data: cacheData})
+	}
+	for _, entry := range entries {
+		metadata.Files[entry.name] = checksum(entry.data)
+	}
+	metadataData, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return entities.BackupInfo{}, err
+	}
+	entries = append([]backupEntry{{name: backupMetadataFile, data: metadataData}}, entries...)
+
+	archive, err := writeBackupArchive(entries, metadata.CreatedAt)
+	if err != nil {
+		return entities.BackupInfo{}, err
+	}
+	if path == "" {
+		path, err = s.newArchivePath(metadata)
+		if err != nil {
+			return entities.BackupInfo{}, err
+		}
+	}
+	if err := writeNewFile(path, archive); err != nil {
+		return entities.BackupInfo{}, err
+	}
+	return backupInfo(path, metadata, int64(len(archive))), nil
+}
+
+// List returns the archives in the backup directory, newest first. Files
+// whose metadata cannot be read are left out.
+func (s *BackupStore) List() ([]entities.BackupInfo, error) {
+	dir, err := s.dir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list backups: %w", err)
+	}
+	var backups []entities.BackupInfo
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), backupExtension) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		archive, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		files, err := readBackupArchive(archive)
+		if err != nil {
+			continue
+		}
+		var metadata backupMetadata
+		if json.Unmarshal(files[backupMetadataFile], &metadata) != nil {
+			continue
+		}
+		backups = append(backups, backupInfo(path, metadata, int64(len(archive))))
+	}
+	entities.SortBackupsNewestFirst(backups)
+	return backups, nil
+}
+
+// Open reads the archive called name in the backup directory, or at path
+// name, and checks it completely: every entry must be listed in the metadata
+// with a matching checksum, and the documents must decode at a schema version
+// this build supports.
+func (s *BackupStore) Open(name string) (entities.BackupInfo, entities.BackupContents, error) {
+	path, err := s.resolve(name)
+	if err != nil {
+		return entities.BackupInfo{}, entities.BackupContents{}, err
+	}
+	archive, err := os.ReadFile(path)
+	if err != nil {
+		return entities.BackupInfo{}, entities.BackupContents{}, fmt.Errorf("read backup: %w", err)
+	}
+	files, err := readBackupArchive(archive)
+	if err != nil {
+		return entities.BackupInfo{}, entities.BackupContents{}, errors.NewInvalidBackupError(filepath.Base(path), err.Error())
+	}
+	metadata, contents, err := decodeBackup(filepath.Base(path), files)
+	if err != nil {
+		return entities.BackupInfo{}, entities.BackupContents{}, err
+	}
+	return backupInfo(path, metadata, int64(len(archive))), contents, nil
+}
+
+// Delete removes the archive of backup.
+func (s *BackupStore) Delete(backup entities.BackupInfo) error {
+	if err := os.Remove(backup.Path); err != nil {
+		return fmt.Errorf("delete backup: %w", err)
+	}
+	return nil
+}
+
+func (s *BackupStore) resolve(name string) (string, error) {
+	if filepath.Base(name) != name {
+		return name, nil
+	}
+	dir, err := s.dir()
+	if err != nil {
+		return "", err
+	}
+	for _, candidate := range []string{filepath.Join(dir, name), filepath.Join(dir, name+backupExtension), name} {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no backup named %q", name)
+}
+
+func (s *BackupStore) newArchivePath(metadata backupMetadata) (string, error) {
+	dir, err := s.dir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("create backup directory: %w", err)
+	}
+	base := string(metadata.Kind) + "-" + metadata.CreatedAt.Format("20060102-150405.000")
+	if metadata.Reason != "" {
+		base += "-" + metadata.Reason
+	}
+	path := filepath.Join(dir, base+backupExtension)
+	for n := 2; fileExists(path); n++ {
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d%s", base, n, backupExtension))
+	}
+	return path, nil
+}
+
+func backupInfo(path string, metadata backupMetadata, size int64) entities.BackupInfo {
+	return entities.BackupInfo{
+		Name:      strings.TrimSuffix(filepath.Base(path), backupExtension),
+		Path:      path,
+		Kind:      metadata.Kind,
+		Reason:    metadata.Reason,
+		CreatedAt: metadata.CreatedAt,
+		Size:      size,
+	}
+}
+
+type backupEntry struct {
+	name string
+	data []byte
+}
+
+func writeBackupArchive(entries []backupEntry, modified time.Time) ([]byte, error) {
+	var archive bytes.Buffer
+	compressed := gzip.NewWriter(&archive)
+	tarball := tar.NewWriter(compressed)
+	for _, entry := range entries {
+		header := &tar.Header{
+			Name:     entry.name,
+			Mode:     0o600,
+			Size:     int64(len(entry.data)),
+			ModTime:  modified,
+			Typeflag: tar.TypeReg,
+		}
+		if err := tarball.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := tarball.Write(entry.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tarball.Close(); err != nil {
+		return nil, err
+	}
+	if err := compressed.Close(); err != nil {
+		return nil, err
+	}
+	return archive.Bytes(), nil
+}
+
+// readBackupArchive returns the entries of archive. Only the known files may
+// appear, each once and as a regular file.
+func readBackupArchive(archive []byte) (map[string][]byte, error) {
+	compressed, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("not a gzip archive: %w", err)
+	}
+	tarball := tar.NewReader(compressed)
+	files := map[string][]byte{}
+	for {
+		header, err := tarball.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("not a tar archive: %w", err)
+		}
+		switch header.Name {
+		case backupMetadataFile, backupConfigFile, backupCacheFile:
+		default:
+			return nil, fmt.Errorf("unexpected entry %q", header.Name)
+		}
+		if header.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("%s is not a regular file", header.Name)
+		}
+		if _, ok := files[header.Name]; ok {
+			return nil, fmt.Errorf("%s appears twice", header.Name)
+		}
+		data, err := io.ReadAll(io.LimitReader(tarball, maxBackupEntrySize+1))
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", header.Name, err)
+		}
+		if len(data) > maxBackupEntrySize {
+			return nil, fmt.Errorf("%s is larger than %d bytes", header.Name, maxBackupEntrySize)
+		}
+		files[header.Name] = data
+	}
+}
+
+func decodeBackup(name string, files map[string][]byte) (backupMetadata, entities.BackupContents, error) {
+	invalid := func(format string, args ...any) error {
+		return errors.NewInvalidBackupError(name, fmt.Sprintf(format, args...))
+	}
+	var metadata backupMetadata
+	data, ok := files[backupMetadataFile]
+	if !ok {
+		return metadata, entities.BackupContents{}, invalid("%s is missing", backupMetadataFile)
+	}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return metadata, entities.BackupContents{}, invalid("%s is unreadable: %v", backupMetadataFile, err)
+	}
+	if metadata.Format > backupFormat {
+		return metadata, entities.BackupContents{}, errors.NewNewerSchemaError(name, metadata.Format, backupFormat)
+	}
+	if metadata.Format < 1 || (metadata.Kind != entities.BackupManual && metadata.Kind != entities.BackupSnapshot) {
+		return metadata, entities.BackupContents{}, invalid("%s does not describe a backup", backupMetadataFile)
+	}
+
+	stored := make([]string, 0, len(files))
+	for file := range files {
+		if file != backupMetadataFile {
+			stored = append(stored, file)
+		}
+	}
+	sort.Strings(stored)
+	for _, file := range stored {
+		want, listed := metadata.Files[file]
+		if !listed {
+			return metadata, entities.BackupContents{}, invalid("%s is not listed in %s", file, backupMetadataFile)
+		}
+		if checksum(files[file]) != want {
+			return metadata, entities.BackupContents{}, invalid("checksum mismatch for %s", file)
+		}
+	}
+	if len(stored) != len(metadata.Files) {
+		return metadata, entities.BackupContents{}, invalid("files listed in %s are missing", backupMetadataFile)
+	}
+
+	var contents entities.BackupContents
+	config, err := decodeBackupDocument[entities.Config](files, backupConfigFile, ConfigSchema)
+	if err != nil {
+		return metadata, contents, wrapBackupDocumentError(name, err)
+	}
+	if config == nil {
+		return metadata, contents, invalid("%s is missing", backupConfigFile)
+	}
+	contents.Config = config
+	cache, err := decodeBackupDocument[entities.OutfitCache](files, backupCacheFile, CacheSchema)
+	if err != nil {
+		return metadata, contents, wrapBackupDocumentError(name, err)
+	}
+	contents.Cache = cache
+	return metadata, contents, nil
+}
+
+func decodeBackupDocument[T any](files map[string][]byte, file string, schema *Schema) (*T, error) {
+	data, ok := files[file]
+	if !ok {
+		return nil, nil
+	}
+	upgraded, _, err := schema.Upgrade(data)
+	if err != nil {
+		return nil, err
+	}
+	if upgraded != nil {
+		data = upgraded
+	}
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("%s is unreadable: %w", file, err)
+	}
+	return &value, nil
+}
+
+func wrapBackupDocumentError(name string, err error) error {
+	if stderrors.Is(err, errors.ErrNewerSchema) {
+		return err
+	}
+	return errors.NewInvalidBackupError(name, err.Error())
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// writeNewFile writes data to a temporary file next to path and renames it
+// into place, refusing to replace an existing file.
+func writeNewFile(path string, data []byte) error {
+	if fileExists(path) {
+		return fmt.Errorf("%s already exists", path)
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create backup: %w", err)
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("write backup: %w", err)
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return fmt.Errorf("write backup: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("write backup: %w", err)
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("write backup: %w", err)
+	}
+	return nil
+}
+
+var _ interfaces.BackupRepository = (*BackupStore)(nil)
@@ -0,0 +1,300 @@
+package persistence
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+func newTestBackupStore(t *testing.T) (*BackupStore, string) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), BackupDirName)
+	store := NewBackupStore(func() (string, error) { return dir, nil })
+	now := time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC)
+	store.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	return store, dir
+}
+
+func testBackupContents() entities.BackupContents {
+	cache := entities.NewOutfitCache().Updating("shoes", entities.NewCategoryCache(2).Adding("a.avatar"))
+	cache.Revision = 7
+	return entities.BackupContents{
+		Config: &entities.Config{Root: "/wardrobe", Language: "en", Storage: entities.StorageSQLite, Revision: 3},
+		Cache:  &cache,
+	}
+}
+
+func writeTestArchive(t *testing.T, path string, entries map[string]string, order ...string) {
+	t.Helper()
+	var archive bytes.Buffer
+	compressed := gzip.NewWriter(&archive)
+	tarball := tar.NewWriter(compressed)
+	for _, name := range order {
+		data := entries[name]
+		if err := tarball.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarball.Write([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tarball.Close()
+	compressed.Close()
+	if err := os.WriteFile(path, archive.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testArchiveMetadata(t *testing.T, files map[string]string, format int) string {
+	t.Helper()
+	metadata := backupMetadata{Format: format, Kind: entities.BackupManual, CreatedAt: time.Now().UTC(), Files: map[string]string{}}
+	for name, data := range files {
+		metadata.Files[name] = checksum([]byte(data))
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestBackupStore_CreateListOpen(t *testing.T) {
+	store, dir := newTestBackupStore(t)
+	if backups, err := store.List(); err != nil || backups != nil {
+		t.Fatalf("List() of missing directory = %v, %v; want none", backups, err)
+	}
+
+	contents := testBackupContents()
+	manual, err := store.Create(entities.BackupManual, "", contents, "")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	snapshot, err := store.Create(entities.BackupSnapshot, "factory-reset", entities.BackupContents{Config: contents.Config}, "")
+	if err != nil {
+		t.Fatalf("Create() snapshot error = %v", err)
+	}
+	if manual.Name != "backup-20260901-080100.000" || snapshot.Name != "snapshot-20260901-080200.000-factory-reset" {
+		t.Fatalf("names = %q, %q; want kind, time and reason", manual.Name, snapshot.Name)
+	}
+	if filepath.Dir(manual.Path) != dir || manual.Size == 0 {
+		t.Fatalf("backup = %+v, want a non-empty archive in %s", manual, dir)
+	}
+	if contents.Config.Storage != entities.StorageSQLite || contents.Config.Revision != 3 {
+		t.Fatalf("config = %+v, want it left alone", contents.Config)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "broken.tar.gz"), []byte("not an archive"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(backups) != 2 || backups[0].Name != snapshot.Name || backups[1].Reason != "" || backups[0].Reason != "factory-reset" {
+		t.Fatalf("List() = %+v, want the snapshot then the backup", backups)
+	}
+
+	for _, name := range []string{manual.Name, manual.Name + backupExtension, manual.Path} {
+		info, opened, err := store.Open(name)
+		if err != nil {
+			t.Fatalf("Open(%q) error = %v", name, err)
+		}
+		if info.Kind != entities.BackupManual || opened.Config.Root != "/wardrobe" || opened.Config.Storage != "" || opened.Config.Revision != 0 {
+			t.Fatalf("Open(%q) = %+v, %+v; want the stored config without storage or revision", name, info, opened.Config)
+		}
+		if opened.Cache == nil || !opened.Cache.Categories["shoes"].WornOutfits["a.avatar"] || opened.Cache.Revision != 0 {
+			t.Fatalf("Open(%q) cache = %+v, want a.avatar worn", name, opened.Cache)
+		}
+	}
+	if _, opened, err := store.Open(snapshot.Name); err != nil || opened.Cache != nil {
+		t.Fatalf("Open() snapshot = %+v, %v; want no cache", opened, err)
+	}
+
+	if err := store.Delete(manual); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := store.Delete(manual); err == nil {
+		t.Fatal("Delete() of a removed backup error = nil, want error")
+	}
+	if _, _, err := store.Open(manual.Name); err == nil {
+		t.Fatal("Open() of a removed backup error = nil, want error")
+	}
+}
+
+func TestBackupStore_CreateAtPath(t *testing.T) {
+	store, _ := newTestBackupStore(t)
+	path := filepath.Join(t.TempDir(), "wardrobe.tar.gz")
+	backup, err := store.Create(entities.BackupManual, "", testBackupContents(), path)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if backup.Path != path || backup.Name != "wardrobe" {
+		t.Fatalf("Create() = %+v, want the archive at %s", backup, path)
+	}
+	if _, err := store.Create(entities.BackupManual, "", testBackupContents(), path); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("Create() over an existing file error = %v, want refusal", err)
+	}
+	if _, err := store.Create(entities.BackupManual, "", entities.BackupContents{}, path); !stderrors.Is(err, errors.ErrConfigurationNotFound) {
+		t.Fatalf("Create() without config error = %v, want %v", err, errors.ErrConfigurationNotFound)
+	}
+	if _, err := store.Create(entities.BackupManual, "", testBackupContents(), filepath.Join(path, "nested.tar.gz")); err == nil {
+		t.Fatal("Create() under a file error = nil, want error")
+	}
+}
+
+func TestBackupStore_CreateAvoidsNameCollisions(t *testing.T) {
+	store, _ := newTestBackupStore(t)
+	store.now = func() time.Time { return time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC) }
+	first, err := store.Create(entities.BackupManual, "", testBackupContents(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := store.Create(entities.BackupManual, "", testBackupContents(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Name != first.Name+"-2" {
+		t.Fatalf("second name = %q, want %q", second.Name, first.Name+"-2")
+	}
+}
+
+func TestBackupStore_DirectoryErrors(t *testing.T) {
+	store := NewBackupStore(func() (string, error) { return "", assert.AnError })
+	if _, err := store.Create(entities.BackupManual, "", testBackupContents(), ""); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("Create() error = %v, want %v", err, assert.AnError)
+	}
+	if _, err := store.List(); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("List() error = %v, want %v", err, assert.AnError)
+	}
+	if _, _, err := store.Open("backup"); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("Open() error = %v, want %v", err, assert.AnError)
+	}
+
+	blocked := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocked, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	store = NewBackupStore(func() (string, error) { return filepath.Join(blocked, BackupDirName), nil })
+	if _, err := store.Create(entities.BackupManual, "", testBackupContents(), ""); err == nil {
+		t.Fatal("Create() under a file error = nil, want error")
+	}
+	if _, err := store.List(); err == nil {
+		t.Fatal("List() under a file error = nil, want error")
+	}
+	if _, _, err := store.Open("missing"); err == nil || !strings.Contains(err.Error(), "no backup named") {
+		t.Fatalf("Open() of unknown name error = %v, want not found", err)
+	}
+}
+
+func TestBackupStore_OpenRejectsInvalidArchives(t *testing.T) {
+	config := `{"root":"/wardrobe","language":"en","version":1}`
+	cache := `{"categories":{}}`
+	valid := map[string]string{backupConfigFile: config, backupCacheFile: cache}
+
+	tests := []struct {
+		name    string
+		entries map[string]string
+		order   []string
+		raw     string
+		want    string
+	}{
+		{name: "not gzip", raw: "plain text", want: "not a gzip archive"},
+		{name: "metadata missing", entries: map[string]string{backupConfigFile: config}, order: []string{backupConfigFile}, want: "metadata.json is missing"},
+		{name: "metadata unreadable", entries: map[string]string{backupMetadataFile: "{"}, order: []string{backupMetadataFile}, want: "metadata.json is unreadable"},
+		{name: "metadata not a backup", entries: map[string]string{backupMetadataFile: `{"format":1}`}, order: []string{backupMetadataFile}, want: "does not describe a backup"},
+		{name: "unexpected entry", entries: map[string]string{"../evil": "x"}, order: []string{"../evil"}, want: "unexpected entry"},
+		{
+			name:    "duplicate entry",
+			entries: map[string]string{backupMetadataFile: testArchiveMetadata(t, nil, 1)},
+			order:   []string{backupMetadataFile, backupMetadataFile},
+			want:    "appears twice",
+		},
+		{
+			name:    "checksum mismatch",
+			entries: map[string]string{backupMetadataFile: testArchiveMetadata(t, valid, 1), backupConfigFile: `{"root":"/elsewhere"}`, backupCacheFile: cache},
+			order:   []string{backupMetadataFile, backupConfigFile, backupCacheFile},
+			want:    "checksum mismatch for config.json",
+		},
+		{
+			name:    "unlisted file",
+			entries: map[string]string{backupMetadataFile: testArchiveMetadata(t, map[string]string{backupConfigFile: config}, 1), backupConfigFile: config, backupCacheFile: cache},
+			order:   []string{backupMetadataFile, backupConfigFile, backupCacheFile},
+			want:    "cache.json is not listed",
+		},
+		{
+			name:    "listed file missing",
+			entries: map[string]string{backupMetadataFile: testArchiveMetadata(t, valid, 1), backupConfigFile: config},
+			order:   []string{backupMetadataFile, backupConfigFile},
+			want:    "files listed in metadata.json are missing",
+		},
+		{
+			name:    "config missing",
+			entries: map[string]string{backupMetadataFile: testArchiveMetadata(t, map[string]string{backupCacheFile: cache}, 1), backupCacheFile: cache},
+			order:   []string{backupMetadataFile, backupCacheFile},
+			want:    "config.json is missing",
+		},
+		{
+			name:    "config unreadable",
+			entries: map[string]string{backupMetadataFile: testArchiveMetadata(t, map[string]string{backupConfigFile: "[1]"}, 1), backupConfigFile: "[1]"},
+			order:   []string{backupMetadataFile, backupConfigFile},
+			want:    "invalid backup archive",
+		},
+		{
+			name:    "cache unreadable",
+			entries: map[string]string{backupMetadataFile: testArchiveMetadata(t, map[string]string{backupConfigFile: config, backupCacheFile: "[1]"}, 1), backupConfigFile: config, backupCacheFile: "[1]"},
+			order:   []string{backupMetadataFile, backupConfigFile, backupCacheFile},
+			want:    "invalid backup archive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "backup.tar.gz")
+			if tt.raw != "" {
+				if err := os.WriteFile(path, []byte(tt.raw), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			} else {
+				writeTestArchive(t, path, tt.entries, tt.order...)
+			}
+			store, _ := newTestBackupStore(t)
+			_, _, err := store.Open(path)
+			if !stderrors.Is(err, errors.ErrInvalidBackup) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Open() error = %v, want invalid backup mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackupStore_OpenRejectsNewerFormats(t *testing.T) {
+	store, _ := newTestBackupStore(t)
+	path := filepath.Join(t.TempDir(), "backup.tar.gz")
+	writeTestArchive(t, path, map[string]string{backupMetadataFile: testArchiveMetadata(t, nil, backupFormat+1)}, backupMetadataFile)
+	if _, _, err := store.Open(path); !stderrors.Is(err, errors.ErrNewerSchema) {
+		t.Fatalf("Open() error = %v, want %v", err, errors.ErrNewerSchema)
+	}
+
+	config := `{"root":"/wardrobe","version":99}`
+	writeTestArchive(t, path, map[string]string{
+		backupMetadataFile: testArchiveMetadata(t, map[string]string{backupConfigFile: config}, backupFormat),
+		backupConfigFile:   config,
+	}, backupMetadataFile, backupConfigFile)
+	if _, _, err := store.Open(path); !stderrors.Is(err, errors.ErrNewerSchema) {
+		t.Fatalf("Open() with newer config error = %v, want %v", err, errors.ErrNewerSchema)
+	}
+}
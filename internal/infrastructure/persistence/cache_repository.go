@@ -22,9 +22,29 @@ func (r *CacheRepository) Load() (*entities.OutfitCache, error) {
 	return r.fileService.Load()
 }
 
-// Save persists the outfit cache to storage.
+// Save persists the outfit cache to storage and stamps it with the current
+// schema version and its new revision. It fails with ErrStaleRevision if the
+// stored cache has changed since cache was loaded, and with the decode error
+// if the stored cache cannot be read.
 func (r *CacheRepository) Save(cache *entities.OutfitCache) error {
-	return r.fileService.Save(*cache)
+	return saveAtRevision(r.fileService, cache, cacheStamps)
+}
+
+// Replace persists the outfit cache in place of the stored one, even one
+// that cannot be read, and stamps it like Save.
+func (r *CacheRepository) Replace(cache *entities.OutfitCache) error {
+	return replaceAtRevision(r.fileService, cache, cacheStamps)
+}
+
+// Update applies change to the stored outfit cache under the storage lock.
+func (r *CacheRepository) Update(change func(current *entities.OutfitCache) (*entities.OutfitCache, error)) error {
+	return updateAtRevision(r.fileService, change, cacheStamps)
+}
+
+var cacheStamps = stamps[entities.OutfitCache]{
+	schema:   CacheSchema,
+	version:  func(cache *entities.OutfitCache) *int { return &cache.Version },
+	revision: func(cache *entities.OutfitCache) *uint64 { return &cache.Revision },
 }
 
 // Delete removes the outfit cache from storage.
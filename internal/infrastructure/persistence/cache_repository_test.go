@@ -166,3 +166,27 @@ func TestCacheRepository_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestCacheRepository_Update(t *testing.T) {
+	mockFS := &mockFileService[entities.OutfitCache]{}
+	repo := NewCacheRepository(mockFS)
+
+	err := repo.Update(func(current *entities.OutfitCache) (*entities.OutfitCache, error) {
+		if current != nil {
+			t.Fatalf("current = %+v, want nil", current)
+		}
+		cache := entities.NewOutfitCache()
+		return &cache, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mockFS.saved == nil {
+		t.Error("expected cache to be saved")
+	}
+
+	mockFS.saveError = assert.AnError
+	if err := repo.Update(func(current *entities.OutfitCache) (*entities.OutfitCache, error) { return mockFS.saved, nil }); err == nil {
+		t.Error("expected error but got none")
+	}
+}
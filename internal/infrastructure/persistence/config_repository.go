@@ -9,6 +9,7 @@ import (
 type FileServiceInterface[T any] interface {
 	Load() (*T, error)
 	Save(obj T) error
+	Update(change func(current *T) (*T, error)) error
 	Delete() error
 }
 
@@ -29,9 +30,29 @@ func (r *ConfigRepository) Load() (*entities.Config, error) {
 	return r.fileService.Load()
 }
 
-// Save persists the configuration to storage.
+// Save persists the configuration to storage and stamps it with the current
+// schema version and its new revision. It fails with ErrStaleRevision if the
+// stored configuration has changed since config was loaded, and with the
+// decode error if the stored configuration cannot be read.
 func (r *ConfigRepository) Save(config *entities.Config) error {
-	return r.fileService.Save(*config)
+	return saveAtRevision(r.fileService, config, configStamps)
+}
+
+// Replace persists the configuration in place of the stored one, even one
+// that cannot be read, and stamps it like Save.
+func (r *ConfigRepository) Replace(config *entities.Config) error {
+	return replaceAtRevision(r.fileService, config, configStamps)
+}
+
+// Update applies change to the stored configuration under the storage lock.
+func (r *ConfigRepository) Update(change func(current *entities.Config) (*entities.Config, error)) error {
+	return updateAtRevision(r.fileService, change, configStamps)
+}
+
+var configStamps = stamps[entities.Config]{
+	schema:   ConfigSchema,
+	version:  func(config *entities.Config) *int { return &config.Version },
+	revision: func(config *entities.Config) *uint64 { return &config.Revision },
 }
 
 // Delete removes the configuration from storage.
@@ -168,11 +168,35 @@ func TestConfigRepository_Delete(t *testing.T) {
 }
 
 // Mock implementations
+func TestConfigRepository_Update(t *testing.T) {
+	mockFS := &mockFileService[entities.Config]{loadResult: &entities.Config{Root: "/old"}}
+	repo := NewConfigRepository(mockFS)
+
+	err := repo.Update(func(current *entities.Config) (*entities.Config, error) {
+		if current == nil || current.Root != "/old" {
+			t.Fatalf("current = %+v, want stored config", current)
+		}
+		return &entities.Config{Root: "/new"}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mockFS.saved == nil || mockFS.saved.Root != "/new" {
+		t.Errorf("saved = %+v, want root /new", mockFS.saved)
+	}
+
+	failing := NewConfigRepository(&mockFileService[entities.Config]{loadError: assert.AnError})
+	if err := failing.Update(func(current *entities.Config) (*entities.Config, error) { return current, nil }); err == nil {
+		t.Error("expected error but got none")
+	}
+}
+
 type mockFileService[T any] struct {
 	loadResult  *T
 	loadError   error
 	saveError   error
 	deleteError error
+	saved       *T
 }
 
 func (m *mockFileService[T]) Load() (*T, error) {
@@ -180,6 +204,22 @@ func (m *mockFileService[T]) Load() (*T, error) {
 }
 
 func (m *mockFileService[T]) Save(obj T) error {
+	if m.saveError != nil {
+		return m.saveError
+	}
+	m.saved = &obj
+	return nil
+}
+
+func (m *mockFileService[T]) Update(change func(current *T) (*T, error)) error {
+	if m.loadError != nil {
+		return m.loadError
+	}
+	updated, err := change(m.loadResult)
+	if err != nil || updated == nil {
+		return err
+	}
+	m.saved = updated
 	return m.saveError
 }
 
@@ -0,0 +1,274 @@
+package persistence
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+)
+
+// JournalFileName is the journal kept next to cache.json.
+const JournalFileName = "journal.jsonl"
+
+// PathLock runs fn while holding an exclusive lock for path that other
+// processes respect.
+type PathLock func(path string, fn func() error) error
+
+// Journal is an append-only file with one JSON event per line recording every
+// change made to config and cache.
+type Journal struct {
+	path func() (string, error)
+	now  func() time.Time
+	lock PathLock
+	// mu keeps changes made in this process in journal order when there is
+	// no cross-process lock.
+	mu sync.Mutex
+}
+
+// NewJournal returns the journal at path. A nil lock only orders the changes
+// made by this process.
+func NewJournal(path func() (string, error), lock PathLock) *Journal {
+	return &Journal{path: path, now: time.Now, lock: lock}
+}
+
+// Path returns the journal file path.
+func (j *Journal) Path() (string, error) {
+	return j.path()
+}
+
+// Append writes events to the end of the journal in a single write and
+// flushes them to disk.
+func (j *Journal) Append(events ...entities.JournalEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	var lines bytes.Buffer
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("encode journal event: %w", err)
+		}
+		lines.Write(data)
+		lines.WriteByte('\n')
+	}
+
+	path, err := j.path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create journal directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("open journal: %w", err)
+	}
+	if _, err := file.Write(lines.Bytes()); err != nil {
+		file.Close()
+		return fmt.Errorf("append to journal: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("append to journal: %w", err)
+	}
+	return file.Close()
+}
+
+// Events returns every recorded event, oldest first. A missing journal has no
+// events. Lines that do not decode, such as one left incomplete by a crash,
+// are skipped.
+func (j *Journal) Events() ([]entities.JournalEvent, error) {
+	path, err := j.path()
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open journal: %w", err)
+	}
+	defer file.Close()
+
+	var events []entities.JournalEvent
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		var event entities.JournalEvent
+		if len(bytes.TrimSpace(line)) > 0 && json.Unmarshal(line, &event) == nil {
+			events = append(events, event)
+		}
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read journal: %w", err)
+		}
+	}
+}
+
+// record runs write while holding the journal lock and appends the events
+// it returns once it succeeds, so that the journal holds every stored change
+// in the order it was stored and nothing that failed. A new journal starts
+// with a baseline of what was stored before it.
+func (j *Journal) record(baseline func(at time.Time) entities.JournalEvent, write func() ([]entities.JournalEvent, error)) error {
+	path, err := j.path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create journal directory: %w", err)
+	}
+	return j.withLock(path, func() error {
+		var events []entities.JournalEvent
+		if _, err := os.Stat(path); os.IsNotExist(err) && baseline != nil {
+			events = append(events, baseline(j.now()))
+		}
+		changes, err := write()
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+		return j.Append(append(events, changes...)...)
+	})
+}
+
+func (j *Journal) withLock(path string, fn func() error) error {
+	if j.lock == nil {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		return fn()
+	}
+	return j.lock(path, fn)
+}
+
+// journaled records the changes made through a file service in a journal.
+type journaled[T any] struct {
+	FileServiceInterface[T]
+	journal *Journal
+	// baseline returns the event recording what is stored when the journal
+	// is started.
+	baseline func(at time.Time) entities.JournalEvent
+	// changes returns the events that turn before into after; before is nil
+	// when nothing was stored.
+	changes func(before, after *T, at time.Time) []entities.JournalEvent
+	// replaced returns the events that replace whatever was stored with
+	// after, which may not have been readable.
+	replaced func(after *T, at time.Time) []entities.JournalEvent
+	deleted  entities.JournalEventType
+}
+
+// JournaledStorage records every configuration save and every outfit worn,
+// unworn or reset through config and cache in journal. Deleting the
+// configuration is recorded as a factory reset and deleting the cache as
+// resetting everything.
+func JournaledStorage(
+	config FileServiceInterface[entities.Config],
+	cache FileServiceInterface[entities.OutfitCache],
+	journal *Journal,
+) (FileServiceInterface[entities.Config], FileServiceInterface[entities.OutfitCache]) {
+	baseline := func(at time.Time) entities.JournalEvent {
+		return baselineEvent(config, cache, at)
+	}
+	journaledConfig := &journaled[entities.Config]{
+		FileServiceInterface: config,
+		journal:              journal,
+		baseline:             baseline,
+		changes:              configChanges,
+		replaced: func(after *entities.Config, at time.Time) []entities.JournalEvent {
+			return configChanges(nil, after, at)
+		},
+		deleted: entities.JournalFactoryReset,
+	}
+	journaledCache := &journaled[entities.OutfitCache]{
+		FileServiceInterface: cache,
+		journal:              journal,
+		baseline:             baseline,
+		changes:              entities.CacheChanges,
+		replaced:             entities.CacheReplacement,
+		deleted:              entities.JournalResetAll,
+	}
+	return journaledConfig, journaledCache
+}
+
+// baselineEvent records the stored config and cache. Whichever cannot be read
+// is left out, as if nothing were stored.
+func baselineEvent(
+	configStorage FileServiceInterface[entities.Config],
+	cacheStorage FileServiceInterface[entities.OutfitCache],
+	at time.Time,
+) entities.JournalEvent {
+	event := entities.JournalEvent{Type: entities.JournalBaseline, At: at}
+	if config, err := configStorage.Load(); err == nil && config != nil {
+		event.Config = configChanges(nil, config, at)[0].Config
+	}
+	if cache, err := cacheStorage.Load(); err == nil && cache != nil {
+		event.Cache = cache
+	}
+	return event
+}
+
+// configChanges records the whole configuration. The storage selection is
+// left out because it describes where the configuration lives, not what it
+// is.
+func configChanges(_, after *entities.Config, at time.Time) []entities.JournalEvent {
+	config := *after
+	config.Storage = ""
+	return []entities.JournalEvent{{Type: entities.JournalConfigUpdate, At: at, Config: &config}}
+}
+
+func (j *journaled[T]) Save(obj T) error {
+	return j.journal.record(j.baseline, func() ([]entities.JournalEvent, error) {
+		if err := j.FileServiceInterface.Save(obj); err != nil {
+			return nil, err
+		}
+		return j.replaced(&obj, j.journal.now()), nil
+	})
+}
+
+func (j *journaled[T]) Update(change func(current *T) (*T, error)) error {
+	return j.journal.record(j.baseline, func() ([]entities.JournalEvent, error) {
+		var events []entities.JournalEvent
+		err := j.FileServiceInterface.Update(func(current *T) (*T, error) {
+			// change may update current in place and return it, so keep
+			// what was stored to compare against.
+			var before *T
+			if current != nil {
+				stored := *current
+				before = &stored
+			}
+			updated, err := change(current)
+			if err != nil || updated == nil {
+				return updated, err
+			}
+			events = j.changes(before, updated, j.journal.now())
+			return updated, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return events, nil
+	})
+}
+
+func (j *journaled[T]) Delete() error {
+	return j.journal.record(j.baseline, func() ([]entities.JournalEvent, error) {
+		if err := j.FileServiceInterface.Delete(); err != nil {
+			return nil, err
+		}
+		return []entities.JournalEvent{{Type: j.deleted, At: j.journal.now()}}, nil
+	})
+}
+
+var _ interfaces.Journal = (*Journal)(nil)
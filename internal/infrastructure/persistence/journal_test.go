@@ -0,0 +1,303 @@
+package persistence
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+)
+
+func newTestJournal(t *testing.T) *Journal {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "outfitpicker", JournalFileName)
+	journal := NewJournal(func() (string, error) { return path, nil }, nil)
+	now := time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC)
+	journal.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	return journal
+}
+
+func journalTypes(t *testing.T, journal *Journal) []entities.JournalEventType {
+	t.Helper()
+	events, err := journal.Events()
+	if err != nil {
+		t.Fatalf("Events() error = %v", err)
+	}
+	types := make([]entities.JournalEventType, 0, len(events))
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func TestJournal_AppendAndEvents(t *testing.T) {
+	journal := newTestJournal(t)
+	if events, err := journal.Events(); err != nil || events != nil {
+		t.Fatalf("Events() of missing journal = %v, %v; want none", events, err)
+	}
+	if err := journal.Append(); err != nil {
+		t.Fatalf("Append() with no events error = %v", err)
+	}
+
+	first := entities.JournalEvent{Type: entities.JournalWear, At: journal.now(), Category: "shoes", Outfit: "a.avatar", TotalOutfits: 2}
+	second := entities.JournalEvent{Type: entities.JournalReset, At: journal.now(), Category: "shoes"}
+	if err := journal.Append(first); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if err := journal.Append(second); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	path, _ := journal.Path()
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = file.WriteString("{\"type\":\"wear\",\"cat")
+	file.Close()
+
+	events, err := journal.Events()
+	if err != nil {
+		t.Fatalf("Events() error = %v", err)
+	}
+	want := []entities.JournalEvent{first, second}
+	if len(events) != 2 || events[0].Outfit != "a.avatar" || events[1].Type != entities.JournalReset || !events[1].At.Equal(second.At) {
+		t.Fatalf("Events() = %+v, want %+v without the incomplete line", events, want)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("journal mode = %v, %v; want 0600", info, err)
+	}
+}
+
+func TestJournal_PathErrors(t *testing.T) {
+	journal := NewJournal(func() (string, error) { return "", assert.AnError }, nil)
+	if err := journal.Append(entities.JournalEvent{Type: entities.JournalResetAll}); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("Append() error = %v, want %v", err, assert.AnError)
+	}
+	if _, err := journal.Events(); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("Events() error = %v, want %v", err, assert.AnError)
+	}
+
+	blocked := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocked, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	journal = NewJournal(func() (string, error) { return filepath.Join(blocked, JournalFileName), nil }, nil)
+	if err := journal.Append(entities.JournalEvent{Type: entities.JournalResetAll}); err == nil {
+		t.Fatal("Append() under a file error = nil, want error")
+	}
+	journal = NewJournal(func() (string, error) { return blocked + string(filepath.Separator), nil }, nil)
+	if _, err := journal.Events(); err == nil {
+		t.Fatal("Events() of a directory path error = nil, want error")
+	}
+}
+
+func TestJournaledRepositories_RecordChanges(t *testing.T) {
+	journal := newTestJournal(t)
+	configFile := &memoryFileService[entities.Config]{}
+	cacheFile := &memoryFileService[entities.OutfitCache]{}
+	configStorage, cacheStorage := JournaledStorage(configFile, cacheFile, journal)
+	configRepo, cacheRepo := NewConfigRepository(configStorage), NewCacheRepository(cacheStorage)
+
+	config := &entities.Config{Root: "/wardrobe", Language: "en", Storage: entities.StorageSQLite}
+	if err := configRepo.Save(config); err != nil {
+		t.Fatal(err)
+	}
+	wear := func(outfit string) {
+		t.Helper()
+		err := cacheRepo.Update(func(current *entities.OutfitCache) (*entities.OutfitCache, error) {
+			if current == nil {
+				cache := entities.NewOutfitCache()
+				current = &cache
+			}
+			category := current.Categories["shoes"]
+			if category.WornOutfits == nil {
+				category = entities.NewCategoryCache(3)
+			}
+			*current = current.Updating("shoes", category.Adding(outfit))
+			return current, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	wear("a.avatar")
+	wear("a.avatar")
+	wear("b.avatar")
+	if err := cacheRepo.Update(func(*entities.OutfitCache) (*entities.OutfitCache, error) { return nil, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := cacheRepo.Update(func(*entities.OutfitCache) (*entities.OutfitCache, error) { return nil, assert.AnError }); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("Update() error = %v, want %v", err, assert.AnError)
+	}
+	if err := cacheRepo.Delete(); err != nil {
+		t.Fatal(err)
+	}
+	if err := configRepo.Delete(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []entities.JournalEventType{
+		entities.JournalBaseline, entities.JournalConfigUpdate,
+		entities.JournalWear, entities.JournalWear,
+		entities.JournalResetAll, entities.JournalFactoryReset,
+	}
+	if got := journalTypes(t, journal); !reflect.DeepEqual(got, want) {
+		t.Fatalf("journal = %v, want %v", got, want)
+	}
+	events, _ := journal.Events()
+	if events[0].Config != nil || events[0].Cache != nil {
+		t.Fatalf("baseline = %+v, want nothing stored", events[0])
+	}
+	if recorded := events[1].Config; recorded == nil || recorded.Root != "/wardrobe" || recorded.Storage != "" || recorded.Revision != 1 {
+		t.Fatalf("recorded config = %+v, want stamped config without the storage selection", recorded)
+	}
+	if config.Storage != entities.StorageSQLite {
+		t.Fatalf("saved config storage = %q, want it left alone", config.Storage)
+	}
+}
+
+func TestJournaledCache_ReplacingUnreadableCache(t *testing.T) {
+	journal := newTestJournal(t)
+	cacheFile := &memoryFileService[entities.OutfitCache]{loadError: &json.SyntaxError{Offset: 1}}
+	_, cacheStorage := JournaledStorage(&memoryFileService[entities.Config]{}, cacheFile, journal)
+	repo := NewCacheRepository(cacheStorage)
+
+	cache := entities.NewOutfitCache().Updating("shoes", entities.NewCategoryCache(2).Adding("a.avatar"))
+	if err := repo.Replace(&cache); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+	want := []entities.JournalEventType{entities.JournalBaseline, entities.JournalResetAll, entities.JournalWear}
+	if got := journalTypes(t, journal); !reflect.DeepEqual(got, want) {
+		t.Fatalf("journal = %v, want %v", got, want)
+	}
+	if cacheFile.stored == nil {
+		t.Fatal("cache was not saved")
+	}
+}
+
+func TestJournaled_StopsWhenTheJournalFails(t *testing.T) {
+	journal := NewJournal(func() (string, error) { return "", assert.AnError }, nil)
+	configFile := &memoryFileService[entities.Config]{}
+	configStorage, _ := JournaledStorage(configFile, &memoryFileService[entities.OutfitCache]{}, journal)
+
+	if err := configStorage.Save(entities.Config{Root: "/wardrobe"}); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("Save() error = %v, want %v", err, assert.AnError)
+	}
+	if err := configStorage.Update(func(*entities.Config) (*entities.Config, error) {
+		return &entities.Config{Root: "/wardrobe"}, nil
+	}); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("Update() error = %v, want %v", err, assert.AnError)
+	}
+	configFile.stored = &entities.Config{Root: "/wardrobe"}
+	if err := configStorage.Delete(); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("Delete() error = %v, want %v", err, assert.AnError)
+	}
+	if configFile.stored == nil || configFile.stored.Root != "/wardrobe" {
+		t.Fatalf("stored config = %+v, want it unchanged", configFile.stored)
+	}
+}
+
+func TestStorageSelector_WithJournal(t *testing.T) {
+	selector, _, _ := newTestStorageSelector(t)
+	journal := newTestJournal(t)
+	journaled := selector.WithJournal(journal)
+	if journaled == selector || selector.journal != nil {
+		t.Fatal("WithJournal() changed the original selector")
+	}
+
+	configRepo, _ := journaled.Repositories()
+	if err := configRepo.Save(&entities.Config{Root: "/wardrobe"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := journaled.SelectStorage(entities.StorageSQLite); err != nil {
+		t.Fatal(err)
+	}
+	_, cacheRepo := journaled.Repositories()
+	cache := entities.NewOutfitCache().Updating("shoes", entities.NewCategoryCache(2).Adding("a.avatar"))
+	if err := cacheRepo.Save(&cache); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []entities.JournalEventType{entities.JournalBaseline, entities.JournalConfigUpdate, entities.JournalWear}
+	if got := journalTypes(t, journal); !reflect.DeepEqual(got, want) {
+		t.Fatalf("journal = %v, want %v without the storage switch", got, want)
+	}
+}
+
+func TestJournaledStorage_BaselineRecordsWhatWasStored(t *testing.T) {
+	journal := newTestJournal(t)
+	cache := entities.NewOutfitCache().Updating("shoes", entities.NewCategoryCache(2).Adding("a.avatar"))
+	configFile := &memoryFileService[entities.Config]{stored: &entities.Config{Root: "/wardrobe", Storage: entities.StorageJSON}}
+	cacheFile := &memoryFileService[entities.OutfitCache]{stored: &cache}
+	_, cacheStorage := JournaledStorage(configFile, cacheFile, journal)
+	repo := NewCacheRepository(cacheStorage)
+
+	if err := repo.Update(func(current *entities.OutfitCache) (*entities.OutfitCache, error) {
+		updated := current.Updating("shoes", current.Categories["shoes"].Adding("b.avatar"))
+		return &updated, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Update(func(current *entities.OutfitCache) (*entities.OutfitCache, error) {
+		updated := current.Updating("hats", entities.NewCategoryCache(1).Adding("c.avatar"))
+		return &updated, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := journal.Events()
+	if err != nil || len(events) != 3 || events[0].Type != entities.JournalBaseline || events[2].Type != entities.JournalWear {
+		t.Fatalf("Events() = %+v, %v; want one baseline before both wears", events, err)
+	}
+	if baseline := events[0]; baseline.Config == nil || baseline.Config.Root != "/wardrobe" || baseline.Config.Storage != "" ||
+		baseline.Cache == nil || !baseline.Cache.Categories["shoes"].WornOutfits["a.avatar"] || baseline.Cache.Categories["shoes"].WornOutfits["b.avatar"] {
+		t.Fatalf("baseline = %+v, want config and cache as stored before the first change", baseline)
+	}
+	state := entities.ReplayJournal(events, time.Time{})
+	if worn := state.Cache.Categories["shoes"].WornOutfits; !worn["a.avatar"] || !worn["b.avatar"] {
+		t.Fatalf("replayed shoes = %v, want the outfit worn before the journal too", worn)
+	}
+}
+
+func TestJournaledStorage_RecordsOnlyStoredChangesUnderTheLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), JournalFileName)
+	configFile := &memoryFileService[entities.Config]{}
+	var locked []string
+	journal := NewJournal(func() (string, error) { return path, nil }, func(lockPath string, fn func() error) error {
+		locked = append(locked, lockPath)
+		return fn()
+	})
+	configStorage, _ := JournaledStorage(configFile, &memoryFileService[entities.OutfitCache]{}, journal)
+
+	configFile.saveError = assert.AnError
+	if err := configStorage.Save(entities.Config{Root: "/wardrobe"}); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("Save() error = %v, want %v", err, assert.AnError)
+	}
+	if err := configStorage.Update(func(*entities.Config) (*entities.Config, error) {
+		return &entities.Config{Root: "/wardrobe"}, nil
+	}); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("Update() error = %v, want %v", err, assert.AnError)
+	}
+	if got := journalTypes(t, journal); len(got) != 0 {
+		t.Fatalf("journal = %v, want nothing recorded for failed writes", got)
+	}
+
+	configFile.saveError = nil
+	if err := configStorage.Save(entities.Config{Root: "/wardrobe"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []entities.JournalEventType{entities.JournalBaseline, entities.JournalConfigUpdate}
+	if got := journalTypes(t, journal); !reflect.DeepEqual(got, want) {
+		t.Fatalf("journal = %v, want %v", got, want)
+	}
+	if len(locked) != 3 || locked[0] != path {
+		t.Fatalf("locked = %v, want the journal locked for every change", locked)
+	}
+}
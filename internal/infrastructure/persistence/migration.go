@@ -0,0 +1,215 @@
+package persistence
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+// schemaVersionKey is the JSON field every stored document records its
+// schema version in. Documents without it predate versioning and are read as
+// version 0.
+const schemaVersionKey = "version"
+
+// Migration upgrades a stored document from schema version From to From+1.
+// Apply edits the decoded JSON object in place; numbers are json.Number.
+type Migration struct {
+	From        int
+	Description string
+	Apply       func(document map[string]any) error
+}
+
+// Schema is the migration registry for one persisted document: its current
+// version and the migrations that bring older documents up to it.
+type Schema struct {
+	document   string
+	current    int
+	migrations map[int]Migration
+}
+
+// NewSchema registers the migrations for document, which is at version
+// current. Every version from the oldest migration up to current must have
+// exactly one migration; a gap is a programming error and panics.
+func NewSchema(document string, current int, migrations ...Migration) *Schema {
+	schema := &Schema{document: document, current: current, migrations: make(map[int]Migration, len(migrations))}
+	oldest := current
+	for _, migration := range migrations {
+		if migration.From < 0 || migration.From >= current {
+			panic(fmt.Sprintf("%s migration from version %d is outside 0..%d", document, migration.From, current-1))
+		}
+		if _, exists := schema.migrations[migration.From]; exists {
+			panic(fmt.Sprintf("%s has two migrations from version %d", document, migration.From))
+		}
+		schema.migrations[migration.From] = migration
+		oldest = min(oldest, migration.From)
+	}
+	for version := oldest; version < current; version++ {
+		if _, exists := schema.migrations[version]; !exists {
+			panic(fmt.Sprintf("%s has no migration from version %d", document, version))
+		}
+	}
+	return schema
+}
+
+// Current returns the schema version new documents are written with.
+func (s *Schema) Current() int {
+	return s.current
+}
+
+// Upgrade brings data written with an older schema version up to the current
+// one and returns the version it was written with; the data is nil when it is
+// already current. Data that is not a JSON object, or whose version is not a
+// number, is left alone so that decoding reports it as unreadable.
+func (s *Schema) Upgrade(data []byte) ([]byte, int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document map[string]any
+	if err := decoder.Decode(&document); err != nil || document == nil {
+		return nil, 0, nil
+	}
+
+	version, ok := documentVersion(document)
+	if !ok {
+		return nil, 0, nil
+	}
+	if version > s.current {
+		return nil, version, errors.NewNewerSchemaError(s.document, version, s.current)
+	}
+	if version == s.current {
+		return nil, version, nil
+	}
+
+	for from := version; from < s.current; from++ {
+		migration, exists := s.migrations[from]
+		if !exists {
+			return nil, version, fmt.Errorf("%s schema version %d is too old to upgrade", s.document, version)
+		}
+		if err := migration.Apply(document); err != nil {
+			return nil, version, fmt.Errorf("upgrade %s from schema version %d: %w", s.document, from, err)
+		}
+		document[schemaVersionKey] = from + 1
+	}
+
+	upgraded, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, version, err
+	}
+	return upgraded, version, nil
+}
+
+func documentVersion(document map[string]any) (int, bool) {
+	raw, exists := document[schemaVersionKey]
+	if !exists || raw == nil {
+		return 0, true
+	}
+	number, ok := raw.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	version, err := number.Int64()
+	if err != nil || version < 0 {
+		return 0, false
+	}
+	return int(version), true
+}
+
+// ensureObjects replaces missing or null fields of document with empty
+// objects, so entities decode them as empty maps instead of nil.
+func ensureObjects(document map[string]any, keys ...string) {
+	for _, key := range keys {
+		if document[key] == nil {
+			document[key] = map[string]any{}
+		}
+	}
+}
+
+// ConfigSchema is the version history of config.json.
+var ConfigSchema = NewSchema("config.json", 8,
+	Migration{
+		From:        0,
+		Description: "record the schema version and default missing category maps to empty",
+		Apply: func(document map[string]any) error {
+			ensureObjects(document, "excludedCategories", "knownCategories", "knownCategoryFiles")
+			return nil
+		},
+	},
+	Migration{
+		From: 1,
+		// Older builds would drop the extra roots when saving, so they must
+		// not read configs that can list them.
+		Description: "allow several wardrobe roots; the existing root is the only one",
+		Apply:       func(map[string]any) error { return nil },
+	},
+	Migration{
+		From: 2,
+		// Older builds would drop wears queued while the wardrobe was
+		// offline when saving, so they must not read configs that queue them.
+		Description: "queue wears made while the wardrobe is offline; nothing is queued yet",
+		Apply:       func(map[string]any) error { return nil },
+	},
+	Migration{
+		From: 3,
+		// Wears queued while offline are kept in queued-wears.json so that
+		// queuing one does not rewrite or journal the configuration. Those
+		// queued in the configuration are not carried over.
+		Description: "keep queued wears out of the configuration",
+		Apply: func(document map[string]any) error {
+			delete(document, "queuedWears")
+			return nil
+		},
+	},
+	Migration{
+		From: 4,
+		// Older builds would take a config.json that only selects the
+		// database for a configuration without a root.
+		Description: "select where config and cache are stored; existing ones stay in the JSON files",
+		Apply:       func(map[string]any) error { return nil },
+	},
+	Migration{
+		From: 5,
+		// Older builds would drop the identity setting when saving and
+		// match worn outfits by name again.
+		Description: "match worn outfits by content when asked; existing ones are matched by file name",
+		Apply:       func(map[string]any) error { return nil },
+	},
+	Migration{
+		From: 6,
+		// Older builds would drop the new-arrival preference when saving.
+		// The known categories were never read, and the wardrobe is
+		// recorded in known-wardrobe.json instead.
+		Description: "prefer new arrivals when asked and drop the unused known categories",
+		Apply: func(document map[string]any) error {
+			delete(document, "knownCategories")
+			delete(document, "knownCategoryFiles")
+			return nil
+		},
+	},
+	Migration{
+		From: 7,
+		// Older builds would drop the symbolic link policy when saving and
+		// refuse the linked roots it allows.
+		Description: "follow symbolic links when asked; existing wardrobes do not follow them",
+		Apply:       func(map[string]any) error { return nil },
+	},
+)
+
+// CacheSchema is the version history of cache.json.
+var CacheSchema = NewSchema("cache.json", 2,
+	Migration{
+		From:        0,
+		Description: "record the schema version and default missing categories to empty",
+		Apply: func(document map[string]any) error {
+			ensureObjects(document, "categories")
+			return nil
+		},
+	},
+	Migration{
+		From: 1,
+		// Older builds would drop the history of other roots when saving,
+		// so they must not read caches that keep it.
+		Description: "keep worn outfits per wardrobe root; existing ones belong to the configured root",
+		Apply:       func(map[string]any) error { return nil },
+	},
+)
@@ -0,0 +1,152 @@
+package persistence
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"strings"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+func TestConfigSchema_UpgradesUnversionedConfig(t *testing.T) {
+	upgraded, from, err := ConfigSchema.Upgrade([]byte(`{"root":"/wardrobe","language":"en","excludedCategories":null}`))
+	if err != nil {
+		t.Fatalf("Upgrade() error = %v", err)
+	}
+	if from != 0 {
+		t.Fatalf("Upgrade() from = %d, want 0", from)
+	}
+
+	var config entities.Config
+	if err := json.Unmarshal(upgraded, &config); err != nil {
+		t.Fatalf("upgraded config does not decode: %v", err)
+	}
+	if config.Version != ConfigSchema.Current() || config.Root != "/wardrobe" {
+		t.Fatalf("upgraded config = %+v, want the current version with root kept", config)
+	}
+	if config.ExcludedCategories == nil {
+		t.Fatalf("upgraded config = %+v, want empty excluded categories", config)
+	}
+	if strings.Contains(string(upgraded), "knownCategor") {
+		t.Fatalf("upgraded config = %s, want the unused known categories dropped", upgraded)
+	}
+}
+
+func TestConfigSchema_DropsQueuedWears(t *testing.T) {
+	upgraded, from, err := ConfigSchema.Upgrade([]byte(`{"version":3,"root":"/wardrobe","queuedWears":[{"category":"casual","fileName":"a.avatar"}]}`))
+	if err != nil || from != 3 {
+		t.Fatalf("Upgrade() from = %d, error = %v; want from 3", from, err)
+	}
+	var document map[string]any
+	if err := json.Unmarshal(upgraded, &document); err != nil {
+		t.Fatalf("upgraded config does not decode: %v", err)
+	}
+	if _, ok := document["queuedWears"]; ok || document["root"] != "/wardrobe" {
+		t.Fatalf("upgraded config = %v, want the queued wears gone and the root kept", document)
+	}
+}
+
+func TestCacheSchema_UpgradesUnversionedCache(t *testing.T) {
+	upgraded, from, err := CacheSchema.Upgrade([]byte(`{"revision":9007199254740993}`))
+	if err != nil || from != 0 {
+		t.Fatalf("Upgrade() = %d, %v; want from 0", from, err)
+	}
+
+	var cache entities.OutfitCache
+	if err := json.Unmarshal(upgraded, &cache); err != nil {
+		t.Fatalf("upgraded cache does not decode: %v", err)
+	}
+	if cache.Version != 2 || cache.Categories == nil || cache.Revision != 9007199254740993 || cache.Root != "" {
+		t.Fatalf("upgraded cache = %+v, want version 2, categories and exact revision", cache)
+	}
+}
+
+func TestSchema_Upgrade(t *testing.T) {
+	var applied []int
+	record := func(from int) Migration {
+		return Migration{From: from, Apply: func(document map[string]any) error {
+			applied = append(applied, from)
+			document["step"] = from
+			return nil
+		}}
+	}
+	applyErr := stderrors.New("bad document")
+	schema := NewSchema("test.json", 3, record(1), record(2), Migration{From: 0, Apply: func(map[string]any) error { return applyErr }})
+
+	tests := []struct {
+		name        string
+		data        string
+		wantApplied []int
+		wantFrom    int
+		wantData    bool
+		wantErr     error
+	}{
+		{name: "chains every migration", data: `{"version":1}`, wantApplied: []int{1, 2}, wantFrom: 1, wantData: true},
+		{name: "current document", data: `{"version":3}`, wantFrom: 3},
+		{name: "newer document", data: `{"version":4}`, wantFrom: 4, wantErr: domainerrors.ErrNewerSchema},
+		{name: "failing migration", data: `{}`, wantErr: applyErr},
+		{name: "not json", data: `not json`},
+		{name: "not an object", data: `[1]`},
+		{name: "non-numeric version", data: `{"version":"two"}`},
+		{name: "negative version", data: `{"version":-1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applied = nil
+			upgraded, from, err := schema.Upgrade([]byte(tt.data))
+			if tt.wantErr != nil {
+				if !stderrors.Is(err, tt.wantErr) {
+					t.Fatalf("Upgrade() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Upgrade() error = %v", err)
+			}
+			if from != tt.wantFrom || (upgraded != nil) != tt.wantData {
+				t.Fatalf("Upgrade() = %s, %d; want from %d, data %t", upgraded, from, tt.wantFrom, tt.wantData)
+			}
+			if len(applied) != len(tt.wantApplied) {
+				t.Fatalf("applied = %v, want %v", applied, tt.wantApplied)
+			}
+			if tt.wantData {
+				var document map[string]any
+				if err := json.Unmarshal(upgraded, &document); err != nil || document["version"] != float64(3) || document["step"] != float64(2) {
+					t.Fatalf("upgraded = %s, %v", upgraded, err)
+				}
+			}
+		})
+	}
+}
+
+func TestSchema_UpgradeRejectsVersionsOlderThanItsMigrations(t *testing.T) {
+	schema := NewSchema("test.json", 2, Migration{From: 1, Apply: func(map[string]any) error { return nil }})
+	if _, _, err := schema.Upgrade([]byte(`{"version":0}`)); err == nil {
+		t.Fatal("Upgrade() error = nil, want error for a version without a migration")
+	}
+}
+
+func TestNewSchema_PanicsOnInconsistentMigrations(t *testing.T) {
+	noop := func(map[string]any) error { return nil }
+	tests := []struct {
+		name       string
+		migrations []Migration
+	}{
+		{name: "gap", migrations: []Migration{{From: 0, Apply: noop}, {From: 2, Apply: noop}}},
+		{name: "duplicate", migrations: []Migration{{From: 1, Apply: noop}, {From: 1, Apply: noop}, {From: 2, Apply: noop}}},
+		{name: "from current", migrations: []Migration{{From: 3, Apply: noop}}},
+		{name: "negative", migrations: []Migration{{From: -1, Apply: noop}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("NewSchema() did not panic")
+				}
+			}()
+			NewSchema("test.json", 3, tt.migrations...)
+		})
+	}
+}
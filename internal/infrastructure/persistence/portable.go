@@ -0,0 +1,79 @@
+package persistence
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+)
+
+// portableConfig keeps roots inside a portable wardrobe relative to it.
+type portableConfig struct {
+	FileServiceInterface[entities.Config]
+	wardrobe string
+}
+
+// PortableConfig stores configured roots inside wardrobe relative to it and
+// resolves them against wardrobe on load, so that a portable wardrobe works
+// wherever it is mounted. Roots outside wardrobe are stored as they are.
+func PortableConfig(fileService FileServiceInterface[entities.Config], wardrobe string) FileServiceInterface[entities.Config] {
+	return &portableConfig{FileServiceInterface: fileService, wardrobe: wardrobe}
+}
+
+func (p *portableConfig) Load() (*entities.Config, error) {
+	config, err := p.FileServiceInterface.Load()
+	if config != nil {
+		*config = mapRoots(*config, p.wardrobe, resolveInWardrobe)
+	}
+	return config, err
+}
+
+func (p *portableConfig) Save(obj entities.Config) error {
+	return p.FileServiceInterface.Save(mapRoots(obj, p.wardrobe, relativeToWardrobe))
+}
+
+func (p *portableConfig) Update(change func(current *entities.Config) (*entities.Config, error)) error {
+	return p.FileServiceInterface.Update(func(current *entities.Config) (*entities.Config, error) {
+		if current != nil {
+			resolved := mapRoots(*current, p.wardrobe, resolveInWardrobe)
+			current = &resolved
+		}
+		updated, err := change(current)
+		if err != nil || updated == nil {
+			return updated, err
+		}
+		stored := mapRoots(*updated, p.wardrobe, relativeToWardrobe)
+		return &stored, nil
+	})
+}
+
+// mapRoots returns config with each of its roots passed through convert.
+func mapRoots(config entities.Config, wardrobe string, convert func(wardrobe, root string) string) entities.Config {
+	config.Root = convert(wardrobe, config.Root)
+	if config.Roots != nil {
+		roots := make([]string, len(config.Roots))
+		for index, root := range config.Roots {
+			roots[index] = convert(wardrobe, root)
+		}
+		config.Roots = roots
+	}
+	return config
+}
+
+func resolveInWardrobe(wardrobe, root string) string {
+	if root == "" || filepath.IsAbs(root) {
+		return root
+	}
+	return filepath.Join(wardrobe, filepath.FromSlash(root))
+}
+
+func relativeToWardrobe(wardrobe, root string) string {
+	if root == "" || !filepath.IsAbs(root) {
+		return root
+	}
+	relative, err := filepath.Rel(wardrobe, root)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return root
+	}
+	return filepath.ToSlash(relative)
+}
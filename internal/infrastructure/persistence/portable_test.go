@@ -0,0 +1,106 @@
+package persistence
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+)
+
+func TestPortableConfig_StoresRootsInsideTheWardrobeRelatively(t *testing.T) {
+	wardrobe := filepath.Join(t.TempDir(), "usb", "wardrobe")
+	file := &memoryFileService[entities.Config]{}
+	repo := NewConfigRepository(PortableConfig(file, wardrobe))
+
+	if err := repo.Save(&entities.Config{Root: wardrobe, Language: "en"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if file.stored.Root != "." {
+		t.Fatalf("stored root = %q, want it relative to the wardrobe", file.stored.Root)
+	}
+	config, err := repo.Load()
+	if err != nil || config.Root != wardrobe {
+		t.Fatalf("Load() = %+v, %v; want the root resolved against the wardrobe", config, err)
+	}
+
+	moved := filepath.Join(t.TempDir(), "media", "wardrobe")
+	config, err = NewConfigRepository(PortableConfig(file, moved)).Load()
+	if err != nil || config.Root != moved {
+		t.Fatalf("Load() from another mount = %+v, %v; want %q", config, err, moved)
+	}
+
+	nested := filepath.Join(wardrobe, "outfits")
+	if err := repo.Update(func(current *entities.Config) (*entities.Config, error) {
+		if current.Root != wardrobe {
+			t.Fatalf("Update() current root = %q, want it resolved", current.Root)
+		}
+		updated := *current
+		updated.Root = nested
+		return &updated, nil
+	}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if file.stored.Root != "outfits" {
+		t.Fatalf("stored root = %q, want outfits", file.stored.Root)
+	}
+
+	elsewhere := filepath.Join(t.TempDir(), "other")
+	if err := repo.Update(func(current *entities.Config) (*entities.Config, error) {
+		updated := *current
+		updated.Root = elsewhere
+		return &updated, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if file.stored.Root != elsewhere {
+		t.Fatalf("stored root = %q, want a root outside the wardrobe kept as it is", file.stored.Root)
+	}
+	if err := repo.Update(func(*entities.Config) (*entities.Config, error) { return nil, nil }); err != nil {
+		t.Fatalf("Update() without a change error = %v", err)
+	}
+}
+
+func TestPortableConfig_MapsEveryRoot(t *testing.T) {
+	wardrobe := filepath.Join(t.TempDir(), "usb", "wardrobe")
+	elsewhere := filepath.Join(t.TempDir(), "nas")
+	file := &memoryFileService[entities.Config]{}
+	repo := NewConfigRepository(PortableConfig(file, wardrobe))
+
+	config := &entities.Config{Root: wardrobe, Roots: []string{wardrobe, filepath.Join(wardrobe, "extra"), elsewhere}, Language: "en"}
+	if err := repo.Save(config); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if want := []string{".", "extra", elsewhere}; !reflect.DeepEqual(file.stored.Roots, want) {
+		t.Fatalf("stored roots = %v, want %v", file.stored.Roots, want)
+	}
+	loaded, err := repo.Load()
+	if err != nil || !reflect.DeepEqual(loaded.Roots, config.Roots) {
+		t.Fatalf("Load() = %+v, %v; want roots %v", loaded, err, config.Roots)
+	}
+}
+
+func TestStorageSelector_PortableWardrobeInSQLite(t *testing.T) {
+	wardrobe := filepath.Join(t.TempDir(), "wardrobe")
+	selector, configFile, _ := newTestStorageSelector(t)
+	selector = selector.WithPortableWardrobe(wardrobe)
+	jsonConfig, _ := selector.Repositories()
+	if err := jsonConfig.Save(&entities.Config{Root: wardrobe, Language: "en"}); err != nil {
+		t.Fatal(err)
+	}
+	if configFile.stored.Root != "." {
+		t.Fatalf("config.json root = %q, want .", configFile.stored.Root)
+	}
+
+	if err := selector.SelectStorage(entities.StorageSQLite); err != nil {
+		t.Fatalf("SelectStorage(sqlite) error = %v", err)
+	}
+	stored, err := selector.store.ConfigStorage().Load()
+	if err != nil || stored.Root != "." {
+		t.Fatalf("database root = %+v, %v; want .", stored, err)
+	}
+	sqliteConfig, _ := selector.Repositories()
+	if config, err := sqliteConfig.Load(); err != nil || config.Root != wardrobe {
+		t.Fatalf("sqlite config = %+v, %v; want the root resolved", config, err)
+	}
+}
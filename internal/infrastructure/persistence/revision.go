@@ -0,0 +1,98 @@
+package persistence
+
+import (
+	"encoding/json"
+	stderrors "errors"
+
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+// stamps locates the fields a repository maintains on every value it
+// stores: the schema version and the revision.
+type stamps[T any] struct {
+	schema   *Schema
+	version  func(value *T) *int
+	revision func(value *T) *uint64
+}
+
+// stamp marks value as written with the current schema at revision.
+func (s stamps[T]) stamp(value *T, revision uint64) {
+	*s.version(value) = s.schema.Current()
+	*s.revision(value) = revision
+}
+
+func (s stamps[T]) storedRevision(current *T) uint64 {
+	if current == nil {
+		return 0
+	}
+	return *s.revision(current)
+}
+
+// saveAtRevision stores value if it was based on the stored revision and
+// stamps value with the next one. A value with revision zero has never been
+// loaded, so it replaces whatever is stored. Stored data that cannot be
+// decoded is not replaced: its decode error is returned, so that it is not
+// lost without the user deciding to.
+func saveAtRevision[T any](fileService FileServiceInterface[T], value *T, stamps stamps[T]) error {
+	expected := *stamps.revision(value)
+	var saved uint64
+	err := fileService.Update(func(current *T) (*T, error) {
+		stored := stamps.storedRevision(current)
+		if expected != 0 && expected != stored {
+			return nil, errors.NewStaleRevisionError(expected, stored)
+		}
+		next := *value
+		saved = stored + 1
+		stamps.stamp(&next, saved)
+		return &next, nil
+	})
+	if err != nil {
+		return err
+	}
+	stamps.stamp(value, saved)
+	return nil
+}
+
+// replaceAtRevision stores value in place of whatever is stored, whatever
+// its revision and even when it cannot be decoded, and stamps value with
+// the next revision, which is 1 after unreadable data.
+func replaceAtRevision[T any](fileService FileServiceInterface[T], value *T, stamps stamps[T]) error {
+	var saved uint64
+	err := fileService.Update(func(current *T) (*T, error) {
+		next := *value
+		saved = stamps.storedRevision(current) + 1
+		stamps.stamp(&next, saved)
+		return &next, nil
+	})
+	if isDecodeError(err) {
+		next := *value
+		saved = 1
+		stamps.stamp(&next, saved)
+		err = fileService.Save(next)
+	}
+	if err != nil {
+		return err
+	}
+	stamps.stamp(value, saved)
+	return nil
+}
+
+// updateAtRevision applies change to the stored value and stamps the result
+// with the revision after the stored one.
+func updateAtRevision[T any](fileService FileServiceInterface[T], change func(current *T) (*T, error), stamps stamps[T]) error {
+	return fileService.Update(func(current *T) (*T, error) {
+		stored := stamps.storedRevision(current)
+		updated, err := change(current)
+		if err != nil || updated == nil {
+			return nil, err
+		}
+		stamps.stamp(updated, stored+1)
+		return updated, nil
+	})
+}
+
+func isDecodeError(err error) bool {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	return stderrors.As(err, &syntaxErr) || stderrors.As(err, &typeErr)
+}
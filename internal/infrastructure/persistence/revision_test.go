@@ -0,0 +1,130 @@
+package persistence
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+func TestConfigRepository_SaveChecksRevision(t *testing.T) {
+	tests := []struct {
+		name         string
+		stored       *entities.Config
+		loadError    error
+		revision     uint64
+		wantErr      bool
+		wantStale    bool
+		wantRevision uint64
+	}{
+		{name: "first save", revision: 0, wantRevision: 1},
+		{name: "based on stored revision", stored: &entities.Config{Revision: 3}, revision: 3, wantRevision: 4},
+		{name: "new config replaces stored one", stored: &entities.Config{Revision: 5}, revision: 0, wantRevision: 6},
+		{name: "stale revision", stored: &entities.Config{Revision: 3}, revision: 2, wantErr: true, wantStale: true},
+		{name: "new config over unreadable file", loadError: &json.SyntaxError{}, revision: 0, wantErr: true},
+		{name: "loaded config over unreadable file", loadError: &json.SyntaxError{}, revision: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFS := &mockFileService[entities.Config]{loadResult: tt.stored, loadError: tt.loadError}
+			config := &entities.Config{Root: "/wardrobe", Revision: tt.revision}
+
+			err := NewConfigRepository(mockFS).Save(config)
+			if tt.wantErr {
+				if err == nil || stderrors.Is(err, domainerrors.ErrStaleRevision) != tt.wantStale {
+					t.Fatalf("Save() error = %v, want stale %t", err, tt.wantStale)
+				}
+				if mockFS.saved != nil {
+					t.Fatalf("saved = %+v, want nothing written", mockFS.saved)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+			if mockFS.saved == nil || mockFS.saved.Revision != tt.wantRevision {
+				t.Fatalf("saved = %+v, want revision %d", mockFS.saved, tt.wantRevision)
+			}
+			if config.Revision != tt.wantRevision {
+				t.Fatalf("config.Revision = %d, want %d", config.Revision, tt.wantRevision)
+			}
+			if mockFS.saved.Version != ConfigSchema.Current() || config.Version != ConfigSchema.Current() {
+				t.Fatalf("saved version = %d, config version = %d, want %d", mockFS.saved.Version, config.Version, ConfigSchema.Current())
+			}
+		})
+	}
+}
+
+func TestConfigRepository_ReplaceIgnoresWhatIsStored(t *testing.T) {
+	tests := []struct {
+		name         string
+		stored       *entities.Config
+		loadError    error
+		wantRevision uint64
+	}{
+		{name: "nothing stored", wantRevision: 1},
+		{name: "newer config stored", stored: &entities.Config{Revision: 5}, wantRevision: 6},
+		{name: "unreadable file", loadError: &json.SyntaxError{}, wantRevision: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockFS := &mockFileService[entities.Config]{loadResult: tt.stored, loadError: tt.loadError}
+			config := &entities.Config{Root: "/wardrobe", Revision: 2}
+
+			if err := NewConfigRepository(mockFS).Replace(config); err != nil {
+				t.Fatalf("Replace() error = %v", err)
+			}
+			if mockFS.saved == nil || mockFS.saved.Root != "/wardrobe" || mockFS.saved.Revision != tt.wantRevision || config.Revision != tt.wantRevision {
+				t.Fatalf("saved = %+v, config revision = %d, want revision %d", mockFS.saved, config.Revision, tt.wantRevision)
+			}
+		})
+	}
+}
+
+func TestCacheRepository_SaveRejectsStaleRevision(t *testing.T) {
+	stored := entities.NewOutfitCache()
+	stored.Revision = 4
+	mockFS := &mockFileService[entities.OutfitCache]{loadResult: &stored}
+	repo := NewCacheRepository(mockFS)
+
+	stale := entities.NewOutfitCache()
+	stale.Revision = 3
+	if err := repo.Save(&stale); !stderrors.Is(err, domainerrors.ErrStaleRevision) {
+		t.Fatalf("Save() error = %v, want %v", err, domainerrors.ErrStaleRevision)
+	}
+
+	current := stored
+	if err := repo.Save(&current); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if current.Revision != 5 {
+		t.Fatalf("Revision = %d, want 5", current.Revision)
+	}
+}
+
+func TestRepository_UpdateAdvancesRevision(t *testing.T) {
+	stored := entities.NewOutfitCache()
+	stored.Revision = 8
+	mockFS := &mockFileService[entities.OutfitCache]{loadResult: &stored}
+
+	err := NewCacheRepository(mockFS).Update(func(current *entities.OutfitCache) (*entities.OutfitCache, error) {
+		fresh := entities.NewOutfitCache()
+		return &fresh, nil
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if mockFS.saved == nil || mockFS.saved.Revision != 9 || mockFS.saved.Version != CacheSchema.Current() {
+		t.Fatalf("saved = %+v, want revision 9 at the current schema version", mockFS.saved)
+	}
+
+	mockFS.saved = nil
+	err = NewCacheRepository(mockFS).Update(func(*entities.OutfitCache) (*entities.OutfitCache, error) { return nil, nil })
+	if err != nil || mockFS.saved != nil {
+		t.Fatalf("Update() with nil change = %v, saved %+v; want no write", err, mockFS.saved)
+	}
+}
@@ -0,0 +1,648 @@
+package persistence
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// SQLiteFileName is the database kept next to config.json when the sqlite
+// storage backend is selected.
+const SQLiteFileName = "outfitpicker.db"
+
+// sqliteMigrations upgrade the database schema; entry i takes PRAGMA
+// user_version from i to i+1.
+var sqliteMigrations = []string{
+	`CREATE TABLE config (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		document TEXT NOT NULL
+	);
+	CREATE TABLE cache (
+		id INTEGER PRIMARY KEY CHECK (id = 1),
+		version INTEGER NOT NULL,
+		revision INTEGER NOT NULL,
+		created_at INTEGER NOT NULL
+	);
+	CREATE TABLE categories (
+		name TEXT PRIMARY KEY,
+		total_outfits INTEGER NOT NULL,
+		last_updated INTEGER NOT NULL
+	);
+	CREATE TABLE wears (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		category TEXT NOT NULL,
+		outfit TEXT NOT NULL,
+		worn_at INTEGER NOT NULL,
+		cleared_at INTEGER
+	);
+	CREATE UNIQUE INDEX wears_current ON wears (category, outfit) WHERE cleared_at IS NULL;
+	CREATE INDEX wears_by_outfit ON wears (category, outfit, worn_at);
+	CREATE INDEX wears_by_category_time ON wears (category, worn_at);
+	CREATE INDEX wears_by_time ON wears (worn_at);`,
+	`ALTER TABLE cache ADD COLUMN root TEXT NOT NULL DEFAULT '';
+	ALTER TABLE cache ADD COLUMN other_roots TEXT;`,
+	`ALTER TABLE wears ADD COLUMN root TEXT NOT NULL DEFAULT '';
+	UPDATE wears SET root = COALESCE((SELECT root FROM cache WHERE id = 1), '') WHERE cleared_at IS NULL;
+	DROP INDEX wears_current;
+	CREATE UNIQUE INDEX wears_current ON wears (root, category, outfit) WHERE cleared_at IS NULL;`,
+}
+
+// SQLiteStore keeps config, the outfit cache and the full wear history in an
+// embedded SQLite database. Every change runs in an immediate transaction, so
+// concurrent instances wait for each other instead of losing updates.
+type SQLiteStore struct {
+	path        func() (string, error)
+	lockTimeout time.Duration
+
+	once    sync.Once
+	db      *sql.DB
+	openErr error
+}
+
+// NewSQLiteStore returns a store for the database at path. The database is
+// created and upgraded on first use. lockTimeout bounds how long a
+// transaction waits for another instance; zero waits indefinitely.
+func NewSQLiteStore(path func() (string, error), lockTimeout time.Duration) *SQLiteStore {
+	return &SQLiteStore{path: path, lockTimeout: lockTimeout}
+}
+
+// Path returns the database file path.
+func (s *SQLiteStore) Path() (string, error) {
+	return s.path()
+}
+
+// Close releases the database. The store cannot be used afterwards.
+func (s *SQLiteStore) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) open() (*sql.DB, error) {
+	s.once.Do(func() {
+		s.db, s.openErr = s.openDatabase()
+	})
+	return s.db, s.openErr
+}
+
+func (s *SQLiteStore) openDatabase() (*sql.DB, error) {
+	path, err := s.path()
+	if err != nil {
+		return nil, err
+	}
+	// The driver reads everything after the first '?' as connection options.
+	if strings.Contains(path, "?") {
+		return nil, errors.NewInvalidInputError(fmt.Sprintf("database path %q cannot contain '?'", path))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	// Create the file first so that it is private to the user.
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+
+	busyTimeout := int64(math.MaxInt32)
+	if s.lockTimeout > 0 {
+		busyTimeout = max(s.lockTimeout.Milliseconds(), 1)
+	}
+	query := url.Values{}
+	query.Set("_txlock", "immediate")
+	query.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", busyTimeout))
+	db, err := sql.Open("sqlite", path+"?"+query.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.migrate(db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// migrate brings the database schema up to date, failing if it was created
+// by a newer version.
+func (s *SQLiteStore) migrate(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return s.mapError(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var version int
+	if err := tx.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return s.mapError(err)
+	}
+	if version > len(sqliteMigrations) {
+		return errors.NewNewerSchemaError(SQLiteFileName, version, len(sqliteMigrations))
+	}
+	if version == len(sqliteMigrations) {
+		return nil
+	}
+	for _, migration := range sqliteMigrations[version:] {
+		if _, err := tx.Exec(migration); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, len(sqliteMigrations))); err != nil {
+		return err
+	}
+	return s.mapError(tx.Commit())
+}
+
+// write runs fn in an immediate transaction and commits it unless fn fails.
+func (s *SQLiteStore) write(fn func(tx *sql.Tx) error) error {
+	return s.transaction(false, fn)
+}
+
+// read runs fn in a deferred transaction so that it sees one snapshot.
+func (s *SQLiteStore) read(fn func(tx *sql.Tx) error) error {
+	return s.transaction(true, fn)
+}
+
+func (s *SQLiteStore) transaction(readOnly bool, fn func(tx *sql.Tx) error) error {
+	db, err := s.open()
+	if err != nil {
+		return err
+	}
+	tx, err := db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: readOnly})
+	if err != nil {
+		return s.mapError(err)
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return s.mapError(err)
+	}
+	return s.mapError(tx.Commit())
+}
+
+// mapError reports a transaction that gave up waiting for another instance
+// as a lock timeout, like the JSON files do.
+func (s *SQLiteStore) mapError(err error) error {
+	var sqliteErr *sqlite.Error
+	if stderrors.As(err, &sqliteErr) && sqliteErr.Code()&0xff == sqlite3.SQLITE_BUSY {
+		path, _ := s.path()
+		return fmt.Errorf("%w waiting for database lock %q", errors.ErrTimedOut, path)
+	}
+	return err
+}
+
+// ConfigStorage returns the storage ConfigRepository uses to keep the
+// configuration in the database.
+func (s *SQLiteStore) ConfigStorage() FileServiceInterface[entities.Config] {
+	return sqliteConfigStorage{store: s}
+}
+
+// CacheStorage returns the storage CacheRepository uses to keep the outfit
+// cache and wear history in the database.
+func (s *SQLiteStore) CacheStorage() FileServiceInterface[entities.OutfitCache] {
+	return sqliteCacheStorage{store: s}
+}
+
+// Import replaces the stored configuration and cache with config and cache
+// in one transaction. A nil cache leaves no cache stored.
+func (s *SQLiteStore) Import(config *entities.Config, cache *entities.OutfitCache) error {
+	return s.write(func(tx *sql.Tx) error {
+		if err := deleteCache(tx); err != nil {
+			return err
+		}
+		if err := writeConfig(tx, *config); err != nil {
+			return err
+		}
+		if cache == nil {
+			return nil
+		}
+		return writeCache(tx, *cache)
+	})
+}
+
+// WearHistory implements interfaces.WearHistoryRepository.
+func (s *SQLiteStore) WearHistory(category string, limit int) ([]entities.WearRecord, error) {
+	query, args := wearHistoryQuery(category, limit)
+	var records []entities.WearRecord
+	err := s.read(func(tx *sql.Tx) error {
+		rows, err := tx.Query(query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var record entities.WearRecord
+			var wornAt int64
+			if err := rows.Scan(&record.Category, &record.Outfit, &wornAt); err != nil {
+				return err
+			}
+			record.WornAt = time.Unix(0, wornAt)
+			records = append(records, record)
+		}
+		return rows.Err()
+	})
+	return records, err
+}
+
+// WearStats implements interfaces.WearHistoryRepository.
+func (s *SQLiteStore) WearStats(category string) ([]entities.OutfitWearStats, error) {
+	query, args := wearStatsQuery(category)
+	var stats []entities.OutfitWearStats
+	err := s.read(func(tx *sql.Tx) error {
+		rows, err := tx.Query(query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var stat entities.OutfitWearStats
+			var lastWorn int64
+			if err := rows.Scan(&stat.Category, &stat.Outfit, &stat.Wears, &lastWorn); err != nil {
+				return err
+			}
+			stat.LastWorn = time.Unix(0, lastWorn)
+			stats = append(stats, stat)
+		}
+		return rows.Err()
+	})
+	return stats, err
+}
+
+// wearHistoryQuery selects wears newest first. The wears_by_category_time
+// and wears_by_time indexes serve it with and without a category.
+func wearHistoryQuery(category string, limit int) (string, []any) {
+	query := `SELECT category, outfit, worn_at FROM wears`
+	var args []any
+	if category != "" {
+		query += ` WHERE category = ?`
+		args = append(args, category)
+	}
+	query += ` ORDER BY worn_at DESC, id DESC`
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	return query, args
+}
+
+// wearStatsQuery counts wears per outfit from the covering wears_by_outfit
+// index.
+func wearStatsQuery(category string) (string, []any) {
+	query := `SELECT category, outfit, COUNT(*), MAX(worn_at) FROM wears`
+	var args []any
+	if category != "" {
+		query += ` WHERE category = ?`
+		args = append(args, category)
+	}
+	query += ` GROUP BY category, outfit ORDER BY COUNT(*) DESC, category, outfit`
+	return query, args
+}
+
+type sqliteConfigStorage struct {
+	store *SQLiteStore
+}
+
+func (c sqliteConfigStorage) Load() (*entities.Config, error) {
+	var config *entities.Config
+	err := c.store.read(func(tx *sql.Tx) error {
+		var err error
+		config, err = readConfig(tx)
+		return err
+	})
+	return config, err
+}
+
+func (c sqliteConfigStorage) Save(config entities.Config) error {
+	return c.store.write(func(tx *sql.Tx) error {
+		return writeConfig(tx, config)
+	})
+}
+
+func (c sqliteConfigStorage) Update(change func(current *entities.Config) (*entities.Config, error)) error {
+	return c.store.write(func(tx *sql.Tx) error {
+		current, err := readConfig(tx)
+		if err != nil {
+			return err
+		}
+		updated, err := change(current)
+		if err != nil || updated == nil {
+			return err
+		}
+		return writeConfig(tx, *updated)
+	})
+}
+
+func (c sqliteConfigStorage) Delete() error {
+	return c.store.write(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DELETE FROM config`)
+		return err
+	})
+}
+
+// readConfig decodes the stored configuration document, upgrading it like
+// config.json when it was written with an older schema.
+func readConfig(tx *sql.Tx) (*entities.Config, error) {
+	var document []byte
+	err := tx.QueryRow(`SELECT document FROM config WHERE id = 1`).Scan(&document)
+	if stderrors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	upgraded, _, err := ConfigSchema.Upgrade(document)
+	if err != nil {
+		return nil, err
+	}
+	if upgraded != nil {
+		document = upgraded
+	}
+	var config entities.Config
+	if err := json.Unmarshal(document, &config); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func writeConfig(tx *sql.Tx, config entities.Config) error {
+	config.Storage = entities.StorageSQLite
+	document, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO config (id, document) VALUES (1, ?)
+		ON CONFLICT (id) DO UPDATE SET document = excluded.document`, document)
+	return err
+}
+
+type sqliteCacheStorage struct {
+	store *SQLiteStore
+}
+
+func (c sqliteCacheStorage) Load() (*entities.OutfitCache, error) {
+	var cache *entities.OutfitCache
+	err := c.store.read(func(tx *sql.Tx) error {
+		var err error
+		cache, err = readCache(tx)
+		return err
+	})
+	return cache, err
+}
+
+func (c sqliteCacheStorage) Save(cache entities.OutfitCache) error {
+	return c.store.write(func(tx *sql.Tx) error {
+		return writeCache(tx, cache)
+	})
+}
+
+func (c sqliteCacheStorage) Update(change func(current *entities.OutfitCache) (*entities.OutfitCache, error)) error {
+	return c.store.write(func(tx *sql.Tx) error {
+		current, err := readCache(tx)
+		if err != nil {
+			return err
+		}
+		updated, err := change(current)
+		if err != nil || updated == nil {
+			return err
+		}
+		return writeCache(tx, *updated)
+	})
+}
+
+// Delete removes the cache together with its wear history, like deleting
+// cache.json does.
+func (c sqliteCacheStorage) Delete() error {
+	return c.store.write(deleteCache)
+}
+
+func readCache(tx *sql.Tx) (*entities.OutfitCache, error) {
+	var cache entities.OutfitCache
+	var createdAt int64
+	var otherRoots sql.NullString
+	err := tx.QueryRow(`SELECT version, revision, created_at, root, other_roots FROM cache WHERE id = 1`).
+		Scan(&cache.Version, &cache.Revision, &createdAt, &cache.Root, &otherRoots)
+	if stderrors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cache.CreatedAt = time.Unix(0, createdAt)
+	if otherRoots.Valid {
+		if err := json.Unmarshal([]byte(otherRoots.String), &cache.OtherRoots); err != nil {
+			return nil, fmt.Errorf("read history of other roots: %w", err)
+		}
+	}
+	cache.Categories = make(map[string]entities.CategoryCache)
+
+	rows, err := tx.Query(`SELECT name, total_outfits, last_updated FROM categories`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var category entities.CategoryCache
+		var lastUpdated int64
+		if err := rows.Scan(&name, &category.TotalOutfits, &lastUpdated); err != nil {
+			return nil, err
+		}
+		category.LastUpdated = time.Unix(0, lastUpdated)
+		category.WornOutfits = make(map[string]bool)
+		cache.Categories[name] = category
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	worn, err := currentWears(tx)
+	if err != nil {
+		return nil, err
+	}
+	for name, outfits := range worn[cache.Root] {
+		if category, ok := cache.Categories[name]; ok {
+			for outfit := range outfits {
+				category.WornOutfits[outfit] = true
+			}
+		}
+	}
+	return &cache, nil
+}
+
+// writeCache stores cache, recording outfits newly marked worn as wears at
+// their category's update time and ending wears of outfits no longer marked.
+// Wears belong to the canonical root they were made under: those of roots
+// kept in OtherRoots stay current, so switching back to a root does not
+// record its worn outfits again. Only the current root's categories gain wear
+// rows; the categories of other roots are kept as a JSON document.
+func writeCache(tx *sql.Tx, cache entities.OutfitCache) error {
+	now := time.Now().UnixNano()
+	stored, err := storedRoots(tx)
+	if err != nil {
+		return err
+	}
+	var otherRoots sql.NullString
+	if len(cache.OtherRoots) > 0 {
+		document, err := json.Marshal(cache.OtherRoots)
+		if err != nil {
+			return err
+		}
+		otherRoots = sql.NullString{String: string(document), Valid: true}
+	}
+	if _, err := tx.Exec(`INSERT INTO cache (id, version, revision, created_at, root, other_roots) VALUES (1, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET version = excluded.version, revision = excluded.revision, created_at = excluded.created_at,
+			root = excluded.root, other_roots = excluded.other_roots`,
+		cache.Version, cache.Revision, cache.CreatedAt.UnixNano(), cache.Root, otherRoots); err != nil {
+		return err
+	}
+	if err := moveWears(tx, stored, cache, now); err != nil {
+		return err
+	}
+
+	worn, err := currentWears(tx)
+	if err != nil {
+		return err
+	}
+	for root, categories := range worn {
+		kept := cache.OtherRoots[root]
+		if root == cache.Root {
+			kept = cache.Categories
+		}
+		for name, outfits := range categories {
+			for outfit := range outfits {
+				if kept[name].WornOutfits[outfit] {
+					continue
+				}
+				if _, err := tx.Exec(`UPDATE wears SET cleared_at = ? WHERE root = ? AND category = ? AND outfit = ? AND cleared_at IS NULL`,
+					now, root, name, outfit); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM categories`); err != nil {
+		return err
+	}
+	for name, category := range cache.Categories {
+		if _, err := tx.Exec(`INSERT INTO categories (name, total_outfits, last_updated) VALUES (?, ?, ?)`,
+			name, category.TotalOutfits, category.LastUpdated.UnixNano()); err != nil {
+			return err
+		}
+		for outfit, isWorn := range category.WornOutfits {
+			if !isWorn || worn[cache.Root][name][outfit] {
+				continue
+			}
+			if _, err := tx.Exec(`INSERT INTO wears (root, category, outfit, worn_at) VALUES (?, ?, ?, ?)`,
+				cache.Root, name, outfit, category.LastUpdated.UnixNano()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// cacheRoots are the root of the stored cache and the other roots it keeps.
+type cacheRoots struct {
+	root   string
+	others map[string]json.RawMessage
+}
+
+func storedRoots(tx *sql.Tx) (cacheRoots, error) {
+	var roots cacheRoots
+	var otherRoots sql.NullString
+	err := tx.QueryRow(`SELECT root, other_roots FROM cache WHERE id = 1`).Scan(&roots.root, &otherRoots)
+	if stderrors.Is(err, sql.ErrNoRows) {
+		return roots, nil
+	}
+	if err != nil || !otherRoots.Valid {
+		return roots, err
+	}
+	if err := json.Unmarshal([]byte(otherRoots.String), &roots.others); err != nil {
+		return roots, fmt.Errorf("read history of other roots: %w", err)
+	}
+	return roots, nil
+}
+
+// moveWears hands the current wears of the stored root to the root its
+// categories went to when cache changes root: the one newly kept in
+// OtherRoots for a switch, or cache.Root for a moved wardrobe, replacing the
+// wears kept for it.
+func moveWears(tx *sql.Tx, stored cacheRoots, cache entities.OutfitCache, now int64) error {
+	if stored.root == cache.Root {
+		return nil
+	}
+	var wears int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM wears WHERE root = ? AND cleared_at IS NULL`, stored.root).Scan(&wears); err != nil {
+		return err
+	}
+	if wears == 0 {
+		return nil
+	}
+	to := cache.Root
+	for root := range cache.OtherRoots {
+		if _, ok := stored.others[root]; !ok {
+			to = root
+		}
+	}
+	if to == stored.root {
+		return nil
+	}
+	if _, err := tx.Exec(`UPDATE wears SET cleared_at = ? WHERE root = ? AND cleared_at IS NULL`, now, to); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`UPDATE wears SET root = ? WHERE root = ? AND cleared_at IS NULL`, to, stored.root)
+	return err
+}
+
+// currentWears returns the outfits worn and not since cleared, by root and
+// category.
+func currentWears(tx *sql.Tx) (map[string]map[string]map[string]bool, error) {
+	rows, err := tx.Query(`SELECT root, category, outfit FROM wears WHERE cleared_at IS NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	worn := make(map[string]map[string]map[string]bool)
+	for rows.Next() {
+		var root, category, outfit string
+		if err := rows.Scan(&root, &category, &outfit); err != nil {
+			return nil, err
+		}
+		if worn[root] == nil {
+			worn[root] = make(map[string]map[string]bool)
+		}
+		if worn[root][category] == nil {
+			worn[root][category] = make(map[string]bool)
+		}
+		worn[root][category][outfit] = true
+	}
+	return worn, rows.Err()
+}
+
+func deleteCache(tx *sql.Tx) error {
+	for _, table := range []string{"wears", "categories", "cache"} {
+		if _, err := tx.Exec(`DELETE FROM ` + table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ interfaces.WearHistoryRepository = (*SQLiteStore)(nil)
@@ -0,0 +1,462 @@
+package persistence
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+func newTestSQLiteStore(t *testing.T, path string, lockTimeout time.Duration) *SQLiteStore {
+	t.Helper()
+	store := NewSQLiteStore(func() (string, error) { return path, nil }, lockTimeout)
+	t.Cleanup(func() { _ = store.Close() })
+	return store
+}
+
+func testSQLitePath(t *testing.T) string {
+	return filepath.Join(t.TempDir(), "outfitpicker", SQLiteFileName)
+}
+
+func TestSQLiteStore_ConfigRepository(t *testing.T) {
+	repo := NewConfigRepository(newTestSQLiteStore(t, testSQLitePath(t), 0).ConfigStorage())
+
+	loaded, err := repo.Load()
+	if err != nil || loaded != nil {
+		t.Fatalf("Load() = %+v, %v; want nothing stored", loaded, err)
+	}
+
+	config := &entities.Config{Root: "/wardrobe", Language: "en", ExcludedCategories: map[string]bool{"formal": true}}
+	if err := repo.Save(config); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err = repo.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Root != "/wardrobe" || !loaded.ExcludedCategories["formal"] {
+		t.Fatalf("Load() = %+v, want saved config", loaded)
+	}
+	if loaded.Storage != entities.StorageSQLite || loaded.Version != ConfigSchema.Current() || loaded.Revision != 1 {
+		t.Fatalf("Load() = %+v, want sqlite storage, current version and revision 1", loaded)
+	}
+
+	stale := *loaded
+	if err := repo.Update(func(current *entities.Config) (*entities.Config, error) {
+		current.Language = "fr"
+		return current, nil
+	}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if err := repo.Save(&stale); !stderrors.Is(err, domainerrors.ErrStaleRevision) {
+		t.Fatalf("Save() of stale config error = %v, want %v", err, domainerrors.ErrStaleRevision)
+	}
+	if err := repo.Update(func(*entities.Config) (*entities.Config, error) { return nil, nil }); err != nil {
+		t.Fatalf("Update() with nil change error = %v", err)
+	}
+	loaded, _ = repo.Load()
+	if loaded.Language != "fr" || loaded.Revision != 2 {
+		t.Fatalf("Load() = %+v, want updated language at revision 2", loaded)
+	}
+
+	if err := repo.Delete(); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if loaded, err := repo.Load(); err != nil || loaded != nil {
+		t.Fatalf("Load() after Delete = %+v, %v; want nothing stored", loaded, err)
+	}
+}
+
+func TestSQLiteStore_ConfigDocumentsAreUpgradedAndReplaceable(t *testing.T) {
+	store := newTestSQLiteStore(t, testSQLitePath(t), 0)
+	setDocument := func(document string) {
+		t.Helper()
+		if err := store.write(func(tx *sql.Tx) error {
+			_, err := tx.Exec(`INSERT OR REPLACE INTO config (id, document) VALUES (1, ?)`, document)
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo := NewConfigRepository(store.ConfigStorage())
+
+	setDocument(`{"root":"/wardrobe","language":"en"}`)
+	loaded, err := repo.Load()
+	if err != nil || loaded.Version != ConfigSchema.Current() || loaded.ExcludedCategories == nil {
+		t.Fatalf("Load() = %+v, %v; want unversioned document upgraded", loaded, err)
+	}
+
+	setDocument(`{"version":99}`)
+	if _, err := repo.Load(); !stderrors.Is(err, domainerrors.ErrNewerSchema) {
+		t.Fatalf("Load() error = %v, want %v", err, domainerrors.ErrNewerSchema)
+	}
+
+	setDocument(`not json`)
+	if _, err := repo.Load(); err == nil {
+		t.Fatal("Load() error = nil, want decode error")
+	}
+	if err := repo.Save(&entities.Config{Root: "/replaced"}); err == nil {
+		t.Fatal("Save() over unreadable document error = nil, want decode error")
+	}
+	if err := repo.Replace(&entities.Config{Root: "/replaced"}); err != nil {
+		t.Fatalf("Replace() over unreadable document error = %v", err)
+	}
+	if loaded, _ := repo.Load(); loaded == nil || loaded.Root != "/replaced" {
+		t.Fatalf("Load() = %+v, want replaced config", loaded)
+	}
+}
+
+func TestSQLiteStore_CacheKeepsWearHistoryAcrossResets(t *testing.T) {
+	store := newTestSQLiteStore(t, testSQLitePath(t), 0)
+	repo := NewCacheRepository(store.CacheStorage())
+	wear := func(category, outfit string) {
+		t.Helper()
+		if err := repo.Update(func(current *entities.OutfitCache) (*entities.OutfitCache, error) {
+			if current == nil {
+				fresh := entities.NewOutfitCache()
+				current = &fresh
+			}
+			categoryCache, ok := current.Categories[category]
+			if !ok {
+				categoryCache = entities.NewCategoryCache(3)
+			}
+			updated := current.Updating(category, categoryCache.Adding(outfit))
+			return &updated, nil
+		}); err != nil {
+			t.Fatalf("wear %s/%s: %v", category, outfit, err)
+		}
+	}
+
+	if loaded, err := repo.Load(); err != nil || loaded != nil {
+		t.Fatalf("Load() = %+v, %v; want nothing stored", loaded, err)
+	}
+	wear("casual", "a.avatar")
+	wear("casual", "b.avatar")
+	wear("formal", "suit.avatar")
+
+	loaded, err := repo.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	casual := loaded.Categories["casual"]
+	if len(casual.WornOutfits) != 2 || casual.TotalOutfits != 3 || loaded.Version != CacheSchema.Current() || loaded.Revision != 3 {
+		t.Fatalf("Load() = %+v, want two casual wears at revision 3", loaded)
+	}
+
+	reset := loaded.Removing("formal").Updating("casual", casual.Reset())
+	if err := repo.Save(&reset); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, _ = repo.Load()
+	if len(loaded.Categories) != 1 || len(loaded.Categories["casual"].WornOutfits) != 0 {
+		t.Fatalf("Load() after reset = %+v, want casual only with nothing worn", loaded)
+	}
+	wear("casual", "a.avatar")
+
+	history, err := store.WearHistory("", 0)
+	if err != nil || len(history) != 4 {
+		t.Fatalf("WearHistory() = %+v, %v; want all four wears", history, err)
+	}
+	if history[0].Category != "casual" || history[0].Outfit != "a.avatar" {
+		t.Fatalf("WearHistory()[0] = %+v, want the latest wear first", history[0])
+	}
+	if limited, _ := store.WearHistory("casual", 2); len(limited) != 2 {
+		t.Fatalf("WearHistory(casual, 2) = %+v, want two records", limited)
+	}
+
+	stats, err := store.WearStats("")
+	if err != nil || len(stats) != 3 {
+		t.Fatalf("WearStats() = %+v, %v; want three outfits", stats, err)
+	}
+	if stats[0].Outfit != "a.avatar" || stats[0].Wears != 2 || stats[0].LastWorn.IsZero() {
+		t.Fatalf("WearStats()[0] = %+v, want a.avatar worn twice", stats[0])
+	}
+	if formal, _ := store.WearStats("formal"); len(formal) != 1 || formal[0].Outfit != "suit.avatar" {
+		t.Fatalf("WearStats(formal) = %+v, want suit.avatar", formal)
+	}
+
+	if err := repo.Delete(); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if loaded, _ := repo.Load(); loaded != nil {
+		t.Fatalf("Load() after Delete = %+v, want nothing stored", loaded)
+	}
+	if history, _ := store.WearHistory("", 0); len(history) != 0 {
+		t.Fatalf("WearHistory() after Delete = %+v, want none", history)
+	}
+}
+
+func TestSQLiteStore_HistoryQueriesUseIndexes(t *testing.T) {
+	store := newTestSQLiteStore(t, testSQLitePath(t), 0)
+	// Stats are ordered by their counts, which no index can provide, but
+	// they must still be grouped straight from an index.
+	queries := []struct {
+		name    string
+		build   func() (string, []any)
+		allowed string
+	}{
+		{name: "history", build: func() (string, []any) { return wearHistoryQuery("", 10) }},
+		{name: "category history", build: func() (string, []any) { return wearHistoryQuery("casual", 10) }},
+		{name: "unlimited history", build: func() (string, []any) { return wearHistoryQuery("casual", 0) }},
+		{name: "stats", build: func() (string, []any) { return wearStatsQuery("") }, allowed: "USE TEMP B-TREE FOR ORDER BY"},
+		{name: "category stats", build: func() (string, []any) { return wearStatsQuery("casual") }, allowed: "USE TEMP B-TREE FOR ORDER BY"},
+	}
+	for _, tt := range queries {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := tt.build()
+			var plan []string
+			err := store.read(func(tx *sql.Tx) error {
+				rows, err := tx.Query(`EXPLAIN QUERY PLAN `+query, args...)
+				if err != nil {
+					return err
+				}
+				defer rows.Close()
+				for rows.Next() {
+					var id, parent, unused int
+					var detail string
+					if err := rows.Scan(&id, &parent, &unused, &detail); err != nil {
+						return err
+					}
+					plan = append(plan, detail)
+				}
+				return rows.Err()
+			})
+			if err != nil {
+				t.Fatalf("EXPLAIN QUERY PLAN error = %v", err)
+			}
+			joined := strings.Join(plan, "; ")
+			sorted := strings.Contains(strings.ReplaceAll(joined, tt.allowed, ""), "TEMP B-TREE")
+			if !strings.Contains(joined, "INDEX wears_by_") || sorted {
+				t.Fatalf("query plan = %q, want an index scan without sorting", joined)
+			}
+		})
+	}
+}
+
+func TestSQLiteStore_ConcurrentUpdatesAreNotLost(t *testing.T) {
+	path := testSQLitePath(t)
+	const workers = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			repo := NewCacheRepository(newTestSQLiteStore(t, path, 0).CacheStorage())
+			errs <- repo.Update(func(current *entities.OutfitCache) (*entities.OutfitCache, error) {
+				if current == nil {
+					fresh := entities.NewOutfitCache()
+					current = &fresh
+				}
+				category := current.Categories["casual"]
+				category.TotalOutfits++
+				updated := current.Updating("casual", category)
+				return &updated, nil
+			})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+	}
+
+	loaded, err := NewCacheRepository(newTestSQLiteStore(t, path, 0).CacheStorage()).Load()
+	if err != nil || loaded.Categories["casual"].TotalOutfits != workers || loaded.Revision != workers {
+		t.Fatalf("Load() = %+v, %v; want %d updates", loaded, err, workers)
+	}
+}
+
+func TestSQLiteStore_LockTimeout(t *testing.T) {
+	path := testSQLitePath(t)
+	holder := newTestSQLiteStore(t, path, 0)
+	held := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- holder.write(func(*sql.Tx) error {
+			close(held)
+			<-release
+			return nil
+		})
+	}()
+	<-held
+
+	waiter := NewConfigRepository(newTestSQLiteStore(t, path, 20*time.Millisecond).ConfigStorage())
+	err := waiter.Save(&entities.Config{Root: "/wardrobe"})
+	close(release)
+	if !stderrors.Is(err, domainerrors.ErrTimedOut) {
+		t.Fatalf("Save() error = %v, want %v", err, domainerrors.ErrTimedOut)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("holder error = %v", err)
+	}
+}
+
+func TestSQLiteStore_OpenErrors(t *testing.T) {
+	pathErr := stderrors.New("no home directory")
+	tests := []struct {
+		name    string
+		path    func(t *testing.T) (string, error)
+		wantErr error
+	}{
+		{name: "path", path: func(*testing.T) (string, error) { return "", pathErr }, wantErr: pathErr},
+		{name: "question mark", path: func(t *testing.T) (string, error) {
+			return filepath.Join(t.TempDir(), "what?.db"), nil
+		}},
+		{name: "directory", path: func(t *testing.T) (string, error) { return t.TempDir(), nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := tt.path(t)
+			store := NewSQLiteStore(func() (string, error) { return path, err }, 0)
+			_, loadErr := store.ConfigStorage().Load()
+			if loadErr == nil || (tt.wantErr != nil && !stderrors.Is(loadErr, tt.wantErr)) {
+				t.Fatalf("Load() error = %v, want %v", loadErr, tt.wantErr)
+			}
+			if err := store.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestSQLiteStore_RejectsNewerDatabase(t *testing.T) {
+	path := testSQLitePath(t)
+	store := newTestSQLiteStore(t, path, 0)
+	if err := store.write(func(tx *sql.Tx) error {
+		_, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, len(sqliteMigrations)+1))
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := newTestSQLiteStore(t, path, 0).CacheStorage().Load()
+	if !stderrors.Is(err, domainerrors.ErrNewerSchema) {
+		t.Fatalf("Load() error = %v, want %v", err, domainerrors.ErrNewerSchema)
+	}
+}
+
+func TestSQLiteStore_KeepsHistoryOfOtherRoots(t *testing.T) {
+	store := newTestSQLiteStore(t, testSQLitePath(t), 0)
+	repo := NewCacheRepository(store.CacheStorage())
+
+	cache := entities.NewOutfitCache().Updating("casual", entities.NewCategoryCache(2).Adding("a.avatar"))
+	cache.Root = "/work"
+	cache.OtherRoots = map[string]map[string]entities.CategoryCache{"/home": {"formal": entities.NewCategoryCache(1).Adding("suit.avatar")}}
+	if err := repo.Save(&cache); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := repo.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Root != "/work" || !loaded.OtherRoots["/home"]["formal"].WornOutfits["suit.avatar"] || !loaded.Categories["casual"].WornOutfits["a.avatar"] {
+		t.Fatalf("Load() = %+v, want the /work rotation with /home kept", loaded)
+	}
+
+	switched := loaded.SwitchingRoot("", "/home", "")
+	if err := repo.Save(&switched); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, _ = repo.Load()
+	if loaded.Root != "/home" || len(loaded.OtherRoots) != 1 || !loaded.Categories["formal"].WornOutfits["suit.avatar"] {
+		t.Fatalf("Load() after switching = %+v, want the /home rotation current", loaded)
+	}
+
+	if err := store.write(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`UPDATE cache SET other_roots = '[' WHERE id = 1`)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Load(); err == nil {
+		t.Fatal("Load() with unreadable history of other roots error = nil, want error")
+	}
+}
+
+func TestSQLiteStore_SwitchingRootsBackDoesNotRecordWearsAgain(t *testing.T) {
+	store := newTestSQLiteStore(t, testSQLitePath(t), 0)
+	repo := NewCacheRepository(store.CacheStorage())
+	save := func(cache entities.OutfitCache) *entities.OutfitCache {
+		t.Helper()
+		if err := repo.Save(&cache); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		loaded, err := repo.Load()
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		return loaded
+	}
+
+	cache := entities.NewOutfitCache().Updating("casual", entities.NewCategoryCache(2).Adding("a.avatar"))
+	cache.Root = "/home"
+	loaded := save(cache)
+	// Both roots have a casual category; only the current one's wears show.
+	loaded = save(loaded.SwitchingRoot("", "/work", ""))
+	if len(loaded.Categories) != 0 {
+		t.Fatalf("Load() after switching = %+v, want nothing worn at /work", loaded)
+	}
+	loaded = save(loaded.Updating("casual", entities.NewCategoryCache(2).Adding("b.avatar")))
+	loaded = save(loaded.SwitchingRoot("", "/home", ""))
+	if worn := loaded.Categories["casual"].WornOutfits; len(worn) != 1 || !worn["a.avatar"] {
+		t.Fatalf("Load() after switching back = %+v, want a.avatar worn at /home", loaded)
+	}
+	loaded = save(loaded.SwitchingRoot("", "/work", ""))
+	if worn := loaded.Categories["casual"].WornOutfits; len(worn) != 1 || !worn["b.avatar"] {
+		t.Fatalf("Load() after switching again = %+v, want b.avatar worn at /work", loaded)
+	}
+	// Moving the wardrobe keeps its wears current under the new root.
+	loaded = save(loaded.MovingRoot("/office", ""))
+	if worn := loaded.Categories["casual"].WornOutfits; len(worn) != 1 || !worn["b.avatar"] {
+		t.Fatalf("Load() after moving = %+v, want b.avatar worn at /office", loaded)
+	}
+	save(loaded.SwitchingRoot("", "/home", ""))
+
+	if history, err := store.WearHistory("", 0); err != nil || len(history) != 2 {
+		t.Fatalf("WearHistory() = %+v, %v; want one wear of each outfit", history, err)
+	}
+	stats, err := store.WearStats("casual")
+	if err != nil || len(stats) != 2 || stats[0].Wears != 1 || stats[1].Wears != 1 {
+		t.Fatalf("WearStats(casual) = %+v, %v; want each outfit worn once", stats, err)
+	}
+}
+
+func TestSQLiteStore_UpgradesDatabaseWithoutRoots(t *testing.T) {
+	path := testSQLitePath(t)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, statement := range []string{
+		sqliteMigrations[0],
+		`INSERT INTO cache (id, version, revision, created_at) VALUES (1, 1, 4, 0)`,
+		`PRAGMA user_version = 1`,
+	} {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("Exec(%.30q) error = %v", statement, err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := NewCacheRepository(newTestSQLiteStore(t, path, 0).CacheStorage()).Load()
+	if err != nil || loaded == nil || loaded.Root != "" || loaded.OtherRoots != nil || loaded.Revision != 4 {
+		t.Fatalf("Load() = %+v, %v; want the stored cache with no root history", loaded, err)
+	}
+}
@@ -0,0 +1,161 @@
+package persistence
+
+import (
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+)
+
+// StorageSelector picks the repositories for the storage backend recorded in
+// config.json and moves config and cache when another backend is selected.
+// While sqlite is selected, config.json only records that selection.
+type StorageSelector struct {
+	configFile FileServiceInterface[entities.Config]
+	cacheFile  FileServiceInterface[entities.OutfitCache]
+	store      *SQLiteStore
+	journal    *Journal
+	// portableWardrobe is the wardrobe holding config and cache in portable
+	// mode, and empty otherwise.
+	portableWardrobe string
+}
+
+// NewStorageSelector creates a selector over the JSON files and the SQLite
+// database.
+func NewStorageSelector(
+	configFile FileServiceInterface[entities.Config],
+	cacheFile FileServiceInterface[entities.OutfitCache],
+	store *SQLiteStore,
+) *StorageSelector {
+	return &StorageSelector{configFile: configFile, cacheFile: cacheFile, store: store}
+}
+
+// WithJournal returns a selector whose repositories record every change in
+// journal.
+func (s *StorageSelector) WithJournal(journal *Journal) *StorageSelector {
+	journaled := *s
+	journaled.journal = journal
+	return &journaled
+}
+
+// WithPortableWardrobe returns a selector for config and cache kept inside
+// wardrobe, whose configured root is stored relative to it in either backend.
+func (s *StorageSelector) WithPortableWardrobe(wardrobe string) *StorageSelector {
+	portable := *s
+	portable.configFile = PortableConfig(s.configFile, wardrobe)
+	portable.portableWardrobe = wardrobe
+	return &portable
+}
+
+// Selected returns the backend config.json selects. A missing or unreadable
+// config.json selects the JSON files, so that loading them reports the
+// problem.
+func (s *StorageSelector) Selected() entities.StorageBackend {
+	config, err := s.configFile.Load()
+	if err != nil || config == nil {
+		return entities.StorageJSON
+	}
+	backend, err := entities.ParseStorageBackend(string(config.Storage))
+	if err != nil {
+		return entities.StorageJSON
+	}
+	return backend
+}
+
+// Repositories returns the config and cache repositories of the selected
+// backend.
+func (s *StorageSelector) Repositories() (*ConfigRepository, *CacheRepository) {
+	return s.repositories(s.Selected())
+}
+
+func (s *StorageSelector) repositories(backend entities.StorageBackend) (*ConfigRepository, *CacheRepository) {
+	configStorage, cacheStorage := s.configFile, s.cacheFile
+	if backend == entities.StorageSQLite {
+		configStorage, cacheStorage = s.store.ConfigStorage(), s.store.CacheStorage()
+		if s.portableWardrobe != "" {
+			configStorage = PortableConfig(configStorage, s.portableWardrobe)
+		}
+	}
+	if s.journal != nil {
+		configStorage, cacheStorage = JournaledStorage(configStorage, cacheStorage, s.journal)
+	}
+	return NewConfigRepository(configStorage), NewCacheRepository(cacheStorage)
+}
+
+// SelectStorage copies config and cache to backend and records it in
+// config.json. Selecting sqlite imports config.json and cache.json into the
+// database in one transaction, replacing anything it held, and keeps
+// cache.json as it was. Selecting json writes both files back out and leaves
+// the database in place.
+func (s *StorageSelector) SelectStorage(backend entities.StorageBackend) error {
+	backend, err := entities.ParseStorageBackend(string(backend))
+	if err != nil {
+		return err
+	}
+	selected := s.Selected()
+	if backend == selected {
+		return nil
+	}
+
+	configRepo, cacheRepo := s.repositories(selected)
+	config, err := configRepo.Load()
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return errors.ErrConfigurationNotFound
+	}
+	cache, err := cacheRepo.Load()
+	if err != nil {
+		return err
+	}
+
+	jsonConfig := NewConfigRepository(s.configFile)
+	if backend == entities.StorageSQLite {
+		configStamps.stamp(config, config.Revision)
+		if cache != nil {
+			cacheStamps.stamp(cache, cache.Revision)
+		}
+		if s.portableWardrobe != "" {
+			*config = mapRoots(*config, s.portableWardrobe, relativeToWardrobe)
+		}
+		if err := s.store.Import(config, cache); err != nil {
+			return err
+		}
+		return jsonConfig.Save(&entities.Config{Storage: entities.StorageSQLite})
+	}
+
+	if cache == nil {
+		if err := s.cacheFile.Delete(); err != nil {
+			return err
+		}
+	} else {
+		if err := NewCacheRepository(s.cacheFile).Replace(cache); err != nil {
+			return err
+		}
+	}
+	config.Storage = ""
+	return jsonConfig.Replace(config)
+}
+
+var _ interfaces.StorageSelector = (*StorageSelector)(nil)
+
+// WearHistory implements interfaces.WearHistoryRepository. Only the sqlite
+// backend keeps every wear; with the JSON files it fails with
+// ErrNoWearHistory.
+func (s *StorageSelector) WearHistory(category string, limit int) ([]entities.WearRecord, error) {
+	if s.Selected() != entities.StorageSQLite {
+		return nil, errors.ErrNoWearHistory
+	}
+	return s.store.WearHistory(category, limit)
+}
+
+// WearStats implements interfaces.WearHistoryRepository, failing like
+// WearHistory with the JSON files.
+func (s *StorageSelector) WearStats(category string) ([]entities.OutfitWearStats, error) {
+	if s.Selected() != entities.StorageSQLite {
+		return nil, errors.ErrNoWearHistory
+	}
+	return s.store.WearStats(category)
+}
+
+var _ interfaces.WearHistoryRepository = (*StorageSelector)(nil)
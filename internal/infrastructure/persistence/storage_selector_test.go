@@ -0,0 +1,184 @@
+package persistence
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	domainerrors "github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+// memoryFileService stores one value like a JSON file would.
+type memoryFileService[T any] struct {
+	stored    *T
+	loadError error
+	saveError error
+}
+
+func (m *memoryFileService[T]) Load() (*T, error) {
+	if m.loadError != nil || m.stored == nil {
+		return nil, m.loadError
+	}
+	value := *m.stored
+	return &value, nil
+}
+
+func (m *memoryFileService[T]) Save(obj T) error {
+	if m.saveError != nil {
+		return m.saveError
+	}
+	m.stored = &obj
+	return nil
+}
+
+func (m *memoryFileService[T]) Update(change func(current *T) (*T, error)) error {
+	current, err := m.Load()
+	if err != nil {
+		return err
+	}
+	updated, err := change(current)
+	if err != nil || updated == nil {
+		return err
+	}
+	return m.Save(*updated)
+}
+
+func (m *memoryFileService[T]) Delete() error {
+	m.stored = nil
+	return nil
+}
+
+func newTestStorageSelector(t *testing.T) (*StorageSelector, *memoryFileService[entities.Config], *memoryFileService[entities.OutfitCache]) {
+	configFile := &memoryFileService[entities.Config]{}
+	cacheFile := &memoryFileService[entities.OutfitCache]{}
+	store := newTestSQLiteStore(t, testSQLitePath(t), 0)
+	return NewStorageSelector(configFile, cacheFile, store), configFile, cacheFile
+}
+
+func TestStorageSelector_Selected(t *testing.T) {
+	tests := []struct {
+		name      string
+		stored    *entities.Config
+		loadError error
+		want      entities.StorageBackend
+	}{
+		{name: "no config", want: entities.StorageJSON},
+		{name: "unreadable config", loadError: assert.AnError, want: entities.StorageJSON},
+		{name: "unset", stored: &entities.Config{Root: "/wardrobe"}, want: entities.StorageJSON},
+		{name: "sqlite", stored: &entities.Config{Storage: entities.StorageSQLite}, want: entities.StorageSQLite},
+		{name: "unknown", stored: &entities.Config{Storage: "postgres"}, want: entities.StorageJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, configFile, _ := newTestStorageSelector(t)
+			configFile.stored, configFile.loadError = tt.stored, tt.loadError
+			if got := selector.Selected(); got != tt.want {
+				t.Fatalf("Selected() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStorageSelector_MovesDataBetweenBackends(t *testing.T) {
+	selector, configFile, cacheFile := newTestStorageSelector(t)
+	jsonConfig, jsonCache := selector.Repositories()
+	if err := jsonConfig.Save(&entities.Config{Root: "/wardrobe", Language: "en"}); err != nil {
+		t.Fatal(err)
+	}
+	cache := entities.NewOutfitCache().Updating("casual", entities.NewCategoryCache(2).Adding("a.avatar"))
+	if err := jsonCache.Save(&cache); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := selector.SelectStorage(entities.StorageSQLite); err != nil {
+		t.Fatalf("SelectStorage(sqlite) error = %v", err)
+	}
+	if configFile.stored.Storage != entities.StorageSQLite || configFile.stored.Root != "" {
+		t.Fatalf("config.json = %+v, want only the sqlite selection", configFile.stored)
+	}
+	if cacheFile.stored == nil {
+		t.Fatal("cache.json was removed, want it kept")
+	}
+	sqliteConfig, sqliteCache := selector.Repositories()
+	config, err := sqliteConfig.Load()
+	if err != nil || config.Root != "/wardrobe" || config.Storage != entities.StorageSQLite {
+		t.Fatalf("sqlite config = %+v, %v; want imported config", config, err)
+	}
+	imported, err := sqliteCache.Load()
+	if err != nil || !imported.Categories["casual"].WornOutfits["a.avatar"] {
+		t.Fatalf("sqlite cache = %+v, %v; want imported wear", imported, err)
+	}
+	if err := selector.SelectStorage(entities.StorageSQLite); err != nil {
+		t.Fatalf("SelectStorage(sqlite) again error = %v", err)
+	}
+
+	updated := imported.Updating("casual", imported.Categories["casual"].Adding("b.avatar"))
+	if err := sqliteCache.Save(&updated); err != nil {
+		t.Fatal(err)
+	}
+	if err := selector.SelectStorage(entities.StorageJSON); err != nil {
+		t.Fatalf("SelectStorage(json) error = %v", err)
+	}
+	if configFile.stored.Root != "/wardrobe" || configFile.stored.Storage != "" {
+		t.Fatalf("config.json = %+v, want exported config", configFile.stored)
+	}
+	if worn := cacheFile.stored.Categories["casual"].WornOutfits; !worn["a.avatar"] || !worn["b.avatar"] {
+		t.Fatalf("cache.json worn = %v, want both wears exported", worn)
+	}
+}
+
+func TestStorageSelector_ExportWithoutCacheRemovesCacheFile(t *testing.T) {
+	selector, _, cacheFile := newTestStorageSelector(t)
+	jsonConfig, _ := selector.Repositories()
+	if err := jsonConfig.Save(&entities.Config{Root: "/wardrobe"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := selector.SelectStorage(entities.StorageSQLite); err != nil {
+		t.Fatal(err)
+	}
+	cacheFile.stored = &entities.OutfitCache{}
+
+	if err := selector.SelectStorage(entities.StorageJSON); err != nil {
+		t.Fatalf("SelectStorage(json) error = %v", err)
+	}
+	if cacheFile.stored != nil {
+		t.Fatalf("cache.json = %+v, want it removed", cacheFile.stored)
+	}
+}
+
+func TestStorageSelector_SelectStorageErrors(t *testing.T) {
+	selector, _, cacheFile := newTestStorageSelector(t)
+	if err := selector.SelectStorage("postgres"); err == nil {
+		t.Fatal("SelectStorage(postgres) error = nil, want invalid input")
+	}
+	if err := selector.SelectStorage(entities.StorageSQLite); !stderrors.Is(err, domainerrors.ErrConfigurationNotFound) {
+		t.Fatalf("SelectStorage() without config error = %v, want %v", err, domainerrors.ErrConfigurationNotFound)
+	}
+
+	jsonConfig, _ := selector.Repositories()
+	if err := jsonConfig.Save(&entities.Config{Root: "/wardrobe"}); err != nil {
+		t.Fatal(err)
+	}
+	cacheFile.loadError = assert.AnError
+	if err := selector.SelectStorage(entities.StorageSQLite); !stderrors.Is(err, assert.AnError) {
+		t.Fatalf("SelectStorage() with unreadable cache error = %v, want %v", err, assert.AnError)
+	}
+}
+
+func TestStorageSelector_WearHistoryNeedsSQLite(t *testing.T) {
+	selector, configFile, _ := newTestStorageSelector(t)
+	if _, err := selector.WearHistory("", 0); !stderrors.Is(err, domainerrors.ErrNoWearHistory) {
+		t.Fatalf("WearHistory() on json error = %v, want %v", err, domainerrors.ErrNoWearHistory)
+	}
+	if _, err := selector.WearStats(""); !stderrors.Is(err, domainerrors.ErrNoWearHistory) {
+		t.Fatalf("WearStats() on json error = %v, want %v", err, domainerrors.ErrNoWearHistory)
+	}
+
+	configFile.stored = &entities.Config{Storage: entities.StorageSQLite}
+	if history, err := selector.WearHistory("", 0); err != nil || len(history) != 0 {
+		t.Fatalf("WearHistory() on sqlite = %+v, %v; want an empty history", history, err)
+	}
+	if stats, err := selector.WearStats(""); err != nil || len(stats) != 0 {
+		t.Fatalf("WearStats() on sqlite = %+v, %v; want no stats", stats, err)
+	}
+}
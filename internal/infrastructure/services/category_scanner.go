@@ -1,40 +1,180 @@
 package services
 
 import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io/fs"
+	"path"
 	"path/filepath"
 	"sort"
+	"sync"
+	"time"
 
 	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
 	"github.com/dh85/outfitpicker/internal/domain/interfaces"
 	"github.com/dh85/outfitpicker/internal/domain/logic"
+	"github.com/dh85/outfitpicker/internal/domain/validation"
 )
 
-// FileManager defines filesystem operations needed by CategoryScanner.
-type FileManager interface {
-	ReadDir(path string) ([]entities.FileEntry, error)
-	FileExists(path string) bool
+// WardrobeSource opens wardrobe roots as file systems, so that the scanner
+// reads plain directories and archives alike. The categories of a root are
+// the directories at the top of its file system.
+type WardrobeSource interface {
+	// Opens reports whether the source reads the wardrobe root at path.
+	Opens(root string) bool
+	// Open returns the file system of the wardrobe root at path.
+	Open(root string) (fs.FS, error)
 }
 
-// CategoryScanner scans filesystem for categories and outfits.
+// WardrobeSources reads each wardrobe root with the first of its sources
+// that opens it.
+type WardrobeSources []WardrobeSource
+
+// Opens reports whether any of the sources reads the root.
+func (s WardrobeSources) Opens(root string) bool {
+	_, ok := s.source(root)
+	return ok
+}
+
+// Open opens the root with the first source that reads it.
+func (s WardrobeSources) Open(root string) (fs.FS, error) {
+	source, ok := s.source(root)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", root, errors.ErrDirectoryNotFound)
+	}
+	return source.Open(root)
+}
+
+func (s WardrobeSources) source(root string) (WardrobeSource, bool) {
+	for _, source := range s {
+		if source.Opens(root) {
+			return source, true
+		}
+	}
+	return nil, false
+}
+
+// Save saves the listings kept by those sources that index them, returning
+// the first error.
+func (s WardrobeSources) Save() error {
+	var first error
+	for _, source := range s {
+		if saver, ok := source.(indexSaver); ok {
+			if err := saver.Save(); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
+
+const (
+	// DefaultScanWorkers is how many category directories are read at once.
+	DefaultScanWorkers = 8
+	// DefaultDirectoryTimeout is how long a directory may take to be read
+	// before it is given up on.
+	DefaultDirectoryTimeout = 10 * time.Second
+)
+
+// CategoryScanner scans wardrobe sources for categories and outfits.
 type CategoryScanner struct {
-	fileManager FileManager
+	source           WardrobeSource
+	workers          int
+	directoryTimeout time.Duration
+	scanContext      func() (context.Context, context.CancelFunc)
+	symlinks         validation.SymlinkPolicy
+	reads            *pendingReads
+}
+
+// CategoryScannerOption configures a CategoryScanner.
+type CategoryScannerOption func(*CategoryScanner)
+
+// WithScanWorkers sets how many category directories are read at once. Less
+// than one reads them one at a time.
+func WithScanWorkers(workers int) CategoryScannerOption {
+	return func(s *CategoryScanner) {
+		s.workers = max(workers, 1)
+	}
+}
+
+// WithDirectoryTimeout sets how long a directory may take to be read. Zero
+// waits for as long as it takes.
+func WithDirectoryTimeout(timeout time.Duration) CategoryScannerOption {
+	return func(s *CategoryScanner) {
+		s.directoryTimeout = timeout
+	}
 }
 
-// NewCategoryScanner creates a new category scanner.
-func NewCategoryScanner(fm FileManager) *CategoryScanner {
-	return &CategoryScanner{fileManager: fm}
+// WithScanContext sets how each scan and listing gets its context, so that
+// it can be cancelled, such as when the user interrupts outfitpicker. The
+// scan calls the returned cancel function once it is done.
+func WithScanContext(start func() (context.Context, context.CancelFunc)) CategoryScannerOption {
+	return func(s *CategoryScanner) {
+		s.scanContext = start
+	}
+}
+
+// NewCategoryScanner creates a category scanner reading from source.
+func NewCategoryScanner(source WardrobeSource, opts ...CategoryScannerOption) *CategoryScanner {
+	s := &CategoryScanner{
+		source:           source,
+		workers:          DefaultScanWorkers,
+		directoryTimeout: DefaultDirectoryTimeout,
+		reads:            &pendingReads{reads: map[string]*pendingRead{}},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// FollowingSymlinks returns a scanner that reads categories that are
+// symbolic links when policy follows them.
+func (s *CategoryScanner) FollowingSymlinks(policy validation.SymlinkPolicy) interfaces.CategoryService {
+	following := *s
+	following.symlinks = policy
+	return &following
 }
 
-// ScanCategories scans a root path for categories and their outfit counts.
+// ScanCategories scans a root path for categories and their outfit counts,
+// in the context set with WithScanContext.
 func (s *CategoryScanner) ScanCategories(rootPath string, excludedCategories map[string]bool) ([]entities.CategoryInfo, error) {
-	entries, err := s.fileManager.ReadDir(rootPath)
+	ctx, cancel := s.startScan()
+	defer cancel()
+	return s.ScanCategoriesContext(ctx, rootPath, excludedCategories)
+}
+
+// startScan returns the context of a scan started without one.
+func (s *CategoryScanner) startScan() (context.Context, context.CancelFunc) {
+	if s.scanContext == nil {
+		return context.Background(), func() {}
+	}
+	return s.scanContext()
+}
+
+// ScanCategoriesContext scans a root path for categories and their outfit
+// counts, reading the category directories in parallel. A category that
+// cannot be read is reported in its own state, with its error, and the scan
+// carries on; only a root that cannot be read fails it. A category whose
+// directory does not answer within the directory timeout is reported as
+// CategoryStateTimedOut rather than holding up the rest. The scan stops when
+// ctx is done.
+func (s *CategoryScanner) ScanCategoriesContext(ctx context.Context, rootPath string, excludedCategories map[string]bool) ([]entities.CategoryInfo, error) {
+	wardrobe, err := s.source.Open(rootPath)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.readDir(ctx, wardrobe, rootPath, ".")
 	if err != nil {
 		return nil, err
 	}
 
 	var categories []entities.CategoryInfo
+	var unread []int
 	for _, entry := range entries {
-		if !entry.IsDirectory {
+		if !entry.IsDirectory || entry.FileName == entities.PortableDirName {
 			continue
 		}
 
@@ -50,56 +190,241 @@ func (s *CategoryScanner) ScanCategories(rootPath string, excludedCategories map
 			))
 			continue
 		}
-
-		outfits, err := s.GetOutfits(categoryPath)
-		if err != nil {
-			return nil, err
+		info := entities.CategoryInfo{Category: categoryRef}
+		if entry.IsSymlink {
+			target, err := s.symlinks.FollowLink(categoryPath)
+			if err != nil {
+				categories = append(categories, rejectedLink(categoryRef, err))
+				continue
+			}
+			info.LinkTarget = target
 		}
+		unread = append(unread, len(categories))
+		categories = append(categories, info)
+	}
 
-		allFiles, err := s.fileManager.ReadDir(categoryPath)
-		if err != nil {
-			return nil, err
-		}
+	if err := s.scanEach(ctx, wardrobe, categories, unread); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].Category.Name < categories[j].Category.Name
+	})
+
+	s.saveIndex()
+	return categories, nil
+}
 
-		var state entities.CategoryState
-		if len(outfits) == 0 {
-			if len(allFiles) == 0 {
-				state = entities.CategoryStateEmpty
-			} else {
-				state = entities.CategoryStateNoAvatarFiles
+// scanEach fills in the categories at the given indexes, reading at most
+// s.workers directories of wardrobe at once.
+func (s *CategoryScanner) scanEach(ctx context.Context, wardrobe fs.FS, categories []entities.CategoryInfo, indexes []int) error {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(s.workers, len(indexes)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				scanned := s.scanCategory(ctx, wardrobe, categories[index].Category)
+				scanned.LinkTarget = categories[index].LinkTarget
+				categories[index] = scanned
 			}
+		}()
+	}
+queue:
+	for _, index := range indexes {
+		select {
+		case jobs <- index:
+		case <-ctx.Done():
+			break queue
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	return ctx.Err()
+}
+
+// scanCategory reads the directory of category and counts its outfits.
+func (s *CategoryScanner) scanCategory(ctx context.Context, wardrobe fs.FS, category entities.CategoryReference) entities.CategoryInfo {
+	allFiles, err := s.readDir(ctx, wardrobe, category.Path, category.Name)
+	if err != nil {
+		return unreadableCategory(category, err)
+	}
+	outfits := outfitsIn(allFiles)
+
+	var state entities.CategoryState
+	if len(outfits) == 0 {
+		if len(allFiles) == 0 {
+			state = entities.CategoryStateEmpty
 		} else {
-			state = entities.CategoryStateHasOutfits
+			state = entities.CategoryStateNoAvatarFiles
 		}
+	} else {
+		state = entities.CategoryStateHasOutfits
+	}
+	return entities.NewCategoryInfo(category, state, len(outfits))
+}
 
-		categories = append(categories, entities.NewCategoryInfo(
-			categoryRef,
-			state,
-			len(outfits),
-		))
+// unreadableCategory describes a category whose directory could not be read
+// because of err.
+func unreadableCategory(category entities.CategoryReference, err error) entities.CategoryInfo {
+	state := entities.CategoryStateUnreadable
+	switch {
+	case stderrors.Is(err, errors.ErrTimedOut):
+		state = entities.CategoryStateTimedOut
+	case stderrors.Is(err, fs.ErrPermission):
+		state = entities.CategoryStatePermissionDenied
 	}
+	return entities.NewCategoryInfo(category, state, 0).WithError(err)
+}
 
-	sort.Slice(categories, func(i, j int) bool {
-		return categories[i].Category.Name < categories[j].Category.Name
+// rejectedLink describes a category that is a symbolic link the scanner's
+// policy does not follow, because of err.
+func rejectedLink(category entities.CategoryReference, err error) entities.CategoryInfo {
+	var state entities.CategoryState
+	switch {
+	case stderrors.Is(err, errors.ErrSymlinkLoop):
+		state = entities.CategoryStateSymlinkLoop
+	case stderrors.Is(err, errors.ErrSymlinkOutsideRoots):
+		state = entities.CategoryStateSymlinkOutsideRoots
+	case stderrors.Is(err, errors.ErrSymlinkNotAllowed):
+		state = entities.CategoryStateSymlinkRejected
+		err = fmt.Errorf("%s: %w", category.Path, err)
+	default:
+		return unreadableCategory(category, err)
+	}
+	return entities.NewCategoryInfo(category, state, 0).WithError(err)
+}
+
+// readDir lists the directory name of wardrobe, found at path, giving up
+// once ctx is done or the directory timeout passes. A read that is given up
+// on is left to finish in the background, since a directory read cannot be
+// interrupted, and a later read of the same path waits for it rather than
+// starting another.
+func (s *CategoryScanner) readDir(ctx context.Context, wardrobe fs.FS, path, name string) ([]entities.FileEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	readCtx := ctx
+	if s.directoryTimeout > 0 {
+		var cancel context.CancelFunc
+		readCtx, cancel = context.WithTimeout(ctx, s.directoryTimeout)
+		defer cancel()
+	}
+	if readCtx.Done() == nil {
+		return listDir(wardrobe, name)
+	}
+
+	read := s.reads.start(path, func() ([]entities.FileEntry, error) {
+		return listDir(wardrobe, name)
 	})
+	select {
+	case <-read.done:
+		return read.entries, read.err
+	case <-readCtx.Done():
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		return nil, fmt.Errorf("reading %s %w after %s", path, errors.ErrTimedOut, s.directoryTimeout)
+	}
+}
 
-	return categories, nil
+// pendingReads tracks the directory reads still running, by path, so that a
+// directory that hangs holds up one goroutine however often it is read. It is
+// shared by the copies of a scanner.
+type pendingReads struct {
+	mu    sync.Mutex
+	reads map[string]*pendingRead
+}
+
+// pendingRead is a directory read whose result is ready once done is closed.
+type pendingRead struct {
+	done    chan struct{}
+	entries []entities.FileEntry
+	err     error
+}
+
+// start returns the read of path that is still running, or starts one that
+// lists it with list.
+func (p *pendingReads) start(path string, list func() ([]entities.FileEntry, error)) *pendingRead {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if read, ok := p.reads[path]; ok {
+		return read
+	}
+	read := &pendingRead{done: make(chan struct{})}
+	p.reads[path] = read
+	go func() {
+		read.entries, read.err = list()
+		p.mu.Lock()
+		delete(p.reads, path)
+		p.mu.Unlock()
+		close(read.done)
+	}()
+	return read
 }
 
-// GetOutfits returns all outfit files in a category path.
+// GetOutfits returns all outfit files in a category path. Like a scan, it
+// gives up on a directory that does not answer within the directory timeout
+// or once its context is cancelled.
 func (s *CategoryScanner) GetOutfits(categoryPath string) ([]entities.FileEntry, error) {
-	entries, err := s.fileManager.ReadDir(categoryPath)
+	wardrobe, err := s.source.Open(filepath.Dir(categoryPath))
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := s.startScan()
+	defer cancel()
+	entries, err := s.readDir(ctx, wardrobe, categoryPath, filepath.Base(categoryPath))
 	if err != nil {
 		return nil, err
 	}
+	s.saveIndex()
+	return outfitsIn(entries), nil
+}
 
-	outfits := logic.FilterOutfitFiles(entries)
+// listDir lists the directory name of wardrobe. A symbolic link is marked as
+// one, and counts as a directory when it points at one, or when where it
+// points cannot be told for any reason other than the target being missing,
+// such as a loop, so that the scan reports it.
+func listDir(wardrobe fs.FS, name string) ([]entities.FileEntry, error) {
+	entries, err := fs.ReadDir(wardrobe, name)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]entities.FileEntry, len(entries))
+	for index, entry := range entries {
+		files[index] = entities.FileEntry{FileName: entry.Name(), IsDirectory: entry.IsDir()}
+		if entry.Type()&fs.ModeSymlink != 0 {
+			files[index].IsSymlink = true
+			info, err := fs.Stat(wardrobe, path.Join(name, entry.Name()))
+			files[index].IsDirectory = err == nil && info.IsDir() || err != nil && !stderrors.Is(err, fs.ErrNotExist)
+		}
+	}
+	return files, nil
+}
 
+// outfitsIn returns the outfit files among a category's entries, sorted by
+// name.
+func outfitsIn(entries []entities.FileEntry) []entities.FileEntry {
+	outfits := logic.LinkOutfitPreviews(logic.FilterOutfitFiles(entries), entries)
 	sort.Slice(outfits, func(i, j int) bool {
 		return outfits[i].FileName < outfits[j].FileName
 	})
+	return outfits
+}
 
-	return outfits, nil
+// indexSaver is a WardrobeSource that keeps the listings it reads for later
+// runs.
+type indexSaver interface {
+	Save() error
+}
+
+// saveIndex saves the listings read by an indexing source. A failed save is
+// not an error: the next run only reads the directories again.
+func (s *CategoryScanner) saveIndex() {
+	if saver, ok := s.source.(indexSaver); ok {
+		_ = saver.Save()
+	}
 }
 
 // Ensure CategoryScanner implements the interface
@@ -1,16 +1,26 @@
 package services
 
 import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/dh85/outfitpicker/internal/domain/entities"
 	"github.com/dh85/outfitpicker/internal/domain/errors"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+	"github.com/dh85/outfitpicker/internal/domain/validation"
 )
 
 func TestCategoryScanner_ScanCategories(t *testing.T) {
 	t.Run("returns sorted categories", func(t *testing.T) {
-		fm := &fakeFileManager{
+		source := &fakeWardrobe{
 			dirs: map[string][]string{
 				"/test": {"casual", "formal"},
 			},
@@ -19,7 +29,7 @@ func TestCategoryScanner_ScanCategories(t *testing.T) {
 				testCategoryPath("formal"): {"suit.avatar"},
 			},
 		}
-		scanner := NewCategoryScanner(fm)
+		scanner := NewCategoryScanner(source)
 
 		result, err := scanner.ScanCategories("/test", nil)
 
@@ -37,8 +47,29 @@ func TestCategoryScanner_ScanCategories(t *testing.T) {
 		}
 	})
 
+	t.Run("skips the portable state directory", func(t *testing.T) {
+		source := &fakeWardrobe{
+			dirs: map[string][]string{
+				"/test": {entities.PortableDirName, "casual"},
+			},
+			files: map[string][]string{
+				testCategoryPath("casual"): {"outfit.avatar"},
+			},
+		}
+		scanner := NewCategoryScanner(source)
+
+		result, err := scanner.ScanCategories("/test", nil)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 1 || result[0].Category.Name != "casual" {
+			t.Fatalf("categories = %+v, want only casual", result)
+		}
+	})
+
 	t.Run("skips non-directory root entries", func(t *testing.T) {
-		fm := &fakeFileManager{
+		source := &fakeWardrobe{
 			dirs: map[string][]string{
 				"/test": {"casual"},
 			},
@@ -47,7 +78,7 @@ func TestCategoryScanner_ScanCategories(t *testing.T) {
 				testCategoryPath("casual"): {"outfit.avatar"},
 			},
 		}
-		scanner := NewCategoryScanner(fm)
+		scanner := NewCategoryScanner(source)
 
 		result, err := scanner.ScanCategories("/test", nil)
 
@@ -63,7 +94,7 @@ func TestCategoryScanner_ScanCategories(t *testing.T) {
 	})
 
 	t.Run("excludes specified categories", func(t *testing.T) {
-		fm := &fakeFileManager{
+		source := &fakeWardrobe{
 			dirs: map[string][]string{
 				"/test": {"casual", "old"},
 			},
@@ -72,7 +103,7 @@ func TestCategoryScanner_ScanCategories(t *testing.T) {
 				testCategoryPath("old"):    {"outfit.avatar"},
 			},
 		}
-		scanner := NewCategoryScanner(fm)
+		scanner := NewCategoryScanner(source)
 
 		result, err := scanner.ScanCategories("/test", map[string]bool{"old": true})
 
@@ -92,7 +123,7 @@ func TestCategoryScanner_ScanCategories(t *testing.T) {
 	})
 
 	t.Run("detects empty categories", func(t *testing.T) {
-		fm := &fakeFileManager{
+		source := &fakeWardrobe{
 			dirs: map[string][]string{
 				"/test": {"empty"},
 			},
@@ -100,7 +131,7 @@ func TestCategoryScanner_ScanCategories(t *testing.T) {
 				testCategoryPath("empty"): {},
 			},
 		}
-		scanner := NewCategoryScanner(fm)
+		scanner := NewCategoryScanner(source)
 
 		result, err := scanner.ScanCategories("/test", nil)
 
@@ -113,7 +144,7 @@ func TestCategoryScanner_ScanCategories(t *testing.T) {
 	})
 
 	t.Run("detects no avatar files", func(t *testing.T) {
-		fm := &fakeFileManager{
+		source := &fakeWardrobe{
 			dirs: map[string][]string{
 				"/test": {"noavatars"},
 			},
@@ -121,7 +152,7 @@ func TestCategoryScanner_ScanCategories(t *testing.T) {
 				testCategoryPath("noavatars"): {"readme.txt"},
 			},
 		}
-		scanner := NewCategoryScanner(fm)
+		scanner := NewCategoryScanner(source)
 
 		result, err := scanner.ScanCategories("/test", nil)
 
@@ -134,8 +165,8 @@ func TestCategoryScanner_ScanCategories(t *testing.T) {
 	})
 
 	t.Run("returns error on filesystem failure", func(t *testing.T) {
-		fm := &fakeFileManager{err: errors.ErrFileSystem}
-		scanner := NewCategoryScanner(fm)
+		source := &fakeWardrobe{err: errors.ErrFileSystem}
+		scanner := NewCategoryScanner(source)
 
 		_, err := scanner.ScanCategories("/test", nil)
 
@@ -144,54 +175,82 @@ func TestCategoryScanner_ScanCategories(t *testing.T) {
 		}
 	})
 
-	t.Run("returns error when getting outfits for category fails", func(t *testing.T) {
-		fm := &fakeFileManager{
+	t.Run("reports categories that cannot be read and carries on", func(t *testing.T) {
+		source := &fakeWardrobe{
 			dirs: map[string][]string{
-				"/test": {"casual"},
+				"/test": {"casual", "locked", "broken"},
+			},
+			links: map[string][]string{
+				"/test": {"shared"},
+			},
+			files: map[string][]string{
+				testCategoryPath("casual"): {"outfit.avatar"},
 			},
 			readDirErrors: map[string]error{
-				testCategoryPath("casual"): errors.ErrFileSystem,
+				testCategoryPath("locked"): &fs.PathError{Op: "open", Path: testCategoryPath("locked"), Err: fs.ErrPermission},
+				testCategoryPath("broken"): errors.ErrFileSystem,
 			},
 		}
-		scanner := NewCategoryScanner(fm)
+		scanner := NewCategoryScanner(source)
 
-		_, err := scanner.ScanCategories("/test", nil)
+		result, err := scanner.ScanCategories("/test", nil)
 
-		if err != errors.ErrFileSystem {
-			t.Errorf("expected ErrFileSystem from GetOutfits, got %v", err)
+		if err != nil {
+			t.Fatalf("ScanCategories() error = %v, want the scan to carry on", err)
+		}
+		want := map[string]entities.CategoryState{
+			"broken": entities.CategoryStateUnreadable,
+			"casual": entities.CategoryStateHasOutfits,
+			"locked": entities.CategoryStatePermissionDenied,
+			"shared": entities.CategoryStateSymlinkRejected,
+		}
+		if len(result) != len(want) {
+			t.Fatalf("ScanCategories() = %+v, want %d categories", result, len(want))
+		}
+		for _, info := range result {
+			if info.State != want[info.Category.Name] {
+				t.Errorf("%s state = %s, want %s", info.Category.Name, info.State, want[info.Category.Name])
+			}
+			if failed := info.State.Failed(); failed != (info.Error != "") {
+				t.Errorf("%s error = %q, want one only when it failed", info.Category.Name, info.Error)
+			}
+		}
+		if source.readDirCalls[testCategoryPath("shared")] != 0 {
+			t.Error("ScanCategories() read a symlinked category")
 		}
 	})
 
-	t.Run("returns error when reading all category files fails after outfits load", func(t *testing.T) {
-		fm := &fakeFileManager{
+	t.Run("reads each category directory once", func(t *testing.T) {
+		source := &fakeWardrobe{
 			dirs: map[string][]string{
-				"/test": {"casual"},
+				"/test": {"casual", "docs"},
 			},
 			files: map[string][]string{
 				testCategoryPath("casual"): {"outfit.avatar"},
-			},
-			readDirErrorSequence: map[string][]error{
-				testCategoryPath("casual"): {nil, errors.ErrFileSystem},
+				testCategoryPath("docs"):   {"notes.txt"},
 			},
 		}
-		scanner := NewCategoryScanner(fm)
-
-		_, err := scanner.ScanCategories("/test", nil)
+		scanner := NewCategoryScanner(source)
 
-		if err != errors.ErrFileSystem {
-			t.Errorf("expected ErrFileSystem from second ReadDir, got %v", err)
+		if _, err := scanner.ScanCategories("/test", nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, path := range []string{"/test", testCategoryPath("casual"), testCategoryPath("docs")} {
+			if calls := source.readDirCalls[path]; calls != 1 {
+				t.Errorf("ReadDir(%s) calls = %d, want 1", path, calls)
+			}
 		}
 	})
 }
 
 func TestCategoryScanner_GetOutfits(t *testing.T) {
 	t.Run("returns sorted outfit files", func(t *testing.T) {
-		fm := &fakeFileManager{
+		source := &fakeWardrobe{
 			files: map[string][]string{
 				testCategoryPath("casual"): {"zebra.avatar", "apple.avatar", "readme.txt"},
 			},
 		}
-		scanner := NewCategoryScanner(fm)
+		scanner := NewCategoryScanner(source)
 
 		result, err := scanner.GetOutfits(testCategoryPath("casual"))
 
@@ -209,9 +268,33 @@ func TestCategoryScanner_GetOutfits(t *testing.T) {
 		}
 	})
 
+	t.Run("links companion images with the same stem", func(t *testing.T) {
+		source := &fakeWardrobe{
+			files: map[string][]string{
+				testCategoryPath("chic"): {"chic1.avatar", "chic1.png", "chic2.avatar", "chic4.png"},
+			},
+		}
+		scanner := NewCategoryScanner(source)
+
+		result, err := scanner.GetOutfits(testCategoryPath("chic"))
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result) != 2 {
+			t.Fatalf("expected 2 outfits, got %d", len(result))
+		}
+		if result[0].PreviewFileName != "chic1.png" {
+			t.Errorf("expected chic1.avatar preview 'chic1.png', got %q", result[0].PreviewFileName)
+		}
+		if result[1].PreviewFileName != "" {
+			t.Errorf("expected chic2.avatar without preview, got %q", result[1].PreviewFileName)
+		}
+	})
+
 	t.Run("returns error on filesystem failure", func(t *testing.T) {
-		fm := &fakeFileManager{err: errors.ErrFileSystem}
-		scanner := NewCategoryScanner(fm)
+		source := &fakeWardrobe{err: errors.ErrFileSystem}
+		scanner := NewCategoryScanner(source)
 
 		_, err := scanner.GetOutfits(testCategoryPath("casual"))
 
@@ -221,50 +304,430 @@ func TestCategoryScanner_GetOutfits(t *testing.T) {
 	})
 }
 
-type fakeFileManager struct {
-	dirs                 map[string][]string
-	files                map[string][]string
-	readDirErrors        map[string]error
-	readDirErrorSequence map[string][]error
-	readDirCalls         map[string]int
-	err                  error
+type fakeWardrobe struct {
+	dirs          map[string][]string
+	files         map[string][]string
+	readDirErrors map[string]error
+	readDirCalls  map[string]int
+	// links are symbolic links to directories.
+	links map[string][]string
+	// hung directories are not read until their channel is closed.
+	hung map[string]chan struct{}
+	err  error
+
+	mu          sync.Mutex
+	reading     int
+	mostReading int
+}
+
+func (f *fakeWardrobe) Opens(root string) bool {
+	return true
+}
+
+func (f *fakeWardrobe) Open(root string) (fs.FS, error) {
+	return fakeFS{wardrobe: f, root: root}, nil
+}
+
+// fakeFS is the file system of one root of a fakeWardrobe. It only lists
+// directories; every symbolic link points at a directory.
+type fakeFS struct {
+	wardrobe *fakeWardrobe
+	root     string
+}
+
+func (f fakeFS) Open(name string) (fs.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
 }
 
-func (f *fakeFileManager) ReadDir(path string) ([]entities.FileEntry, error) {
+func (f fakeFS) Stat(name string) (fs.FileInfo, error) {
+	return fakeFileInfo{name: path.Base(name), mode: fs.ModeDir}, nil
+}
+
+func (f fakeFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return f.wardrobe.readDir(filepath.Join(f.root, filepath.FromSlash(name)))
+}
+
+func (f *fakeWardrobe) readDir(path string) ([]fs.DirEntry, error) {
 	if f.err != nil {
 		return nil, f.err
 	}
-	if sequence, ok := f.readDirErrorSequence[path]; ok {
-		if f.readDirCalls == nil {
-			f.readDirCalls = map[string]int{}
-		}
-		call := f.readDirCalls[path]
-		f.readDirCalls[path] = call + 1
-		if call < len(sequence) && sequence[call] != nil {
-			return nil, sequence[call]
-		}
+	f.mu.Lock()
+	if f.readDirCalls == nil {
+		f.readDirCalls = map[string]int{}
 	}
+	f.readDirCalls[path]++
+	f.reading++
+	f.mostReading = max(f.mostReading, f.reading)
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.reading--
+		f.mu.Unlock()
+	}()
+
+	if hung, ok := f.hung[path]; ok {
+		<-hung
+	}
+	time.Sleep(time.Millisecond)
 	if err, ok := f.readDirErrors[path]; ok {
 		return nil, err
 	}
-	var entries []entities.FileEntry
-	if dirs, ok := f.dirs[path]; ok {
-		for _, dir := range dirs {
-			entries = append(entries, entities.NewFileEntryWithDir(filepath.Join(path, dir), true))
-		}
+	var entries []fs.DirEntry
+	for _, dir := range f.dirs[path] {
+		entries = append(entries, fs.FileInfoToDirEntry(fakeFileInfo{name: dir, mode: fs.ModeDir}))
 	}
-	if files, ok := f.files[path]; ok {
-		for _, file := range files {
-			entries = append(entries, entities.NewFileEntryWithDir(filepath.Join(path, file), false))
-		}
+	for _, link := range f.links[path] {
+		entries = append(entries, fs.FileInfoToDirEntry(fakeFileInfo{name: link, mode: fs.ModeSymlink}))
+	}
+	for _, file := range f.files[path] {
+		entries = append(entries, fs.FileInfoToDirEntry(fakeFileInfo{name: file}))
 	}
 	return entries, nil
 }
 
-func (f *fakeFileManager) FileExists(path string) bool {
-	return true
+type fakeFileInfo struct {
+	name string
+	mode fs.FileMode
 }
 
+func (i fakeFileInfo) Name() string       { return i.name }
+func (i fakeFileInfo) Size() int64        { return 0 }
+func (i fakeFileInfo) Mode() fs.FileMode  { return i.mode }
+func (i fakeFileInfo) ModTime() time.Time { return time.Time{} }
+func (i fakeFileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i fakeFileInfo) Sys() any           { return nil }
+
 func testCategoryPath(name string) string {
 	return filepath.Join("/test", name)
 }
+
+// indexingWardrobe is a fakeWardrobe that records saves of its index.
+type indexingWardrobe struct {
+	fakeWardrobe
+	saves int
+}
+
+func (f *indexingWardrobe) Save() error {
+	f.saves++
+	return errors.ErrFileSystem
+}
+
+// symlinkedWardrobe creates a wardrobe under the working directory whose
+// categories are symbolic links: shared leads to a pack beside the
+// wardrobe, outside somewhere else, back to the wardrobe itself, first and
+// second to each other, and gone nowhere.
+func symlinkedWardrobe(t *testing.T) (root, packs string) {
+	t.Helper()
+	workspaceDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd() error = %v", err)
+	}
+	base, err := os.MkdirTemp(workspaceDir, "category-scanner-")
+	if err != nil {
+		t.Fatalf("MkdirTemp() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.RemoveAll(base)
+	})
+	root, packs = filepath.Join(base, "wardrobe"), filepath.Join(base, "packs")
+	for _, dir := range []string{root, filepath.Join(packs, "summer"), filepath.Join(base, "elsewhere")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("MkdirAll() error = %v", err)
+		}
+	}
+	links := map[string]string{
+		"shared":  filepath.Join(packs, "summer"),
+		"outside": filepath.Join(base, "elsewhere"),
+		"back":    ".",
+		"first":   "second",
+		"second":  "first",
+		"gone":    filepath.Join(base, "missing"),
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, name)); err != nil {
+			t.Skipf("os.Symlink() unavailable: %v", err)
+		}
+	}
+	return root, packs
+}
+
+func TestCategoryScanner_FollowsSymlinkedCategories(t *testing.T) {
+	root, packs := symlinkedWardrobe(t)
+	source := &fakeWardrobe{
+		dirs:  map[string][]string{root: {"casual"}},
+		links: map[string][]string{root: {"back", "first", "gone", "outside", "second", "shared"}},
+		files: map[string][]string{
+			filepath.Join(root, "casual"): {"tee.avatar"},
+			filepath.Join(root, "shared"): {"linen.avatar", "shorts.avatar"},
+		},
+	}
+	var scanner interfaces.CategoryService = NewCategoryScanner(source)
+	policy := validation.SymlinkPolicy{Follow: true, AllowedRoots: []string{root, packs}}
+	scanner = scanner.(interfaces.SymlinkFollower).FollowingSymlinks(policy)
+
+	result, err := scanner.ScanCategories(root, nil)
+
+	if err != nil {
+		t.Fatalf("ScanCategories() error = %v", err)
+	}
+	want := map[string]entities.CategoryState{
+		"back":    entities.CategoryStateSymlinkLoop,
+		"casual":  entities.CategoryStateHasOutfits,
+		"first":   entities.CategoryStateSymlinkLoop,
+		"gone":    entities.CategoryStateUnreadable,
+		"outside": entities.CategoryStateSymlinkOutsideRoots,
+		"second":  entities.CategoryStateSymlinkLoop,
+		"shared":  entities.CategoryStateHasOutfits,
+	}
+	if len(result) != len(want) {
+		t.Fatalf("ScanCategories() = %+v, want %d categories", result, len(want))
+	}
+	for _, info := range result {
+		if info.State != want[info.Category.Name] {
+			t.Errorf("%s state = %s, want %s", info.Category.Name, info.State, want[info.Category.Name])
+		}
+		if failed := info.State.Failed(); failed != (info.Error != "") {
+			t.Errorf("%s error = %q, want one only when it failed", info.Category.Name, info.Error)
+		}
+		if linked := info.LinkTarget != ""; linked != (info.Category.Name == "shared") {
+			t.Errorf("%s link target = %q, want one only for the followed link", info.Category.Name, info.LinkTarget)
+		}
+	}
+	if result[len(result)-1].OutfitCount != 2 {
+		t.Errorf("shared outfits = %d, want the pack's outfits read", result[len(result)-1].OutfitCount)
+	}
+
+	rejecting, err := NewCategoryScanner(source).ScanCategories(root, nil)
+	if err != nil {
+		t.Fatalf("ScanCategories() error = %v", err)
+	}
+	for _, info := range rejecting {
+		if info.Category.Name != "casual" && info.State != entities.CategoryStateSymlinkRejected {
+			t.Errorf("%s state = %s without following links, want %s", info.Category.Name, info.State, entities.CategoryStateSymlinkRejected)
+		}
+	}
+}
+
+func TestCategoryScanner_SavesIndex(t *testing.T) {
+	source := &indexingWardrobe{fakeWardrobe: fakeWardrobe{
+		dirs:  map[string][]string{"/test": {"casual"}},
+		files: map[string][]string{testCategoryPath("casual"): {"outfit.avatar"}},
+	}}
+	scanner := NewCategoryScanner(source)
+
+	if _, err := scanner.ScanCategories("/test", nil); err != nil {
+		t.Fatalf("ScanCategories() error = %v, want a failed index save ignored", err)
+	}
+	if _, err := scanner.GetOutfits(testCategoryPath("casual")); err != nil {
+		t.Fatalf("GetOutfits() error = %v, want a failed index save ignored", err)
+	}
+	if source.saves != 2 {
+		t.Errorf("index saves = %d, want one per scan and listing", source.saves)
+	}
+}
+
+// hang makes the directory at path hang until the test ends.
+func hang(t *testing.T, source *fakeWardrobe, path string) {
+	t.Helper()
+	if source.hung == nil {
+		source.hung = map[string]chan struct{}{}
+	}
+	release := make(chan struct{})
+	source.hung[path] = release
+	t.Cleanup(func() { close(release) })
+}
+
+func TestCategoryScanner_ReadsCategoriesInParallel(t *testing.T) {
+	source := &fakeWardrobe{dirs: map[string][]string{}, files: map[string][]string{}}
+	var names []string
+	for index := range 12 {
+		name := fmt.Sprintf("category%02d", 11-index)
+		names = append(names, name)
+		source.files[testCategoryPath(name)] = []string{"outfit.avatar"}
+	}
+	source.dirs["/test"] = names
+	scanner := NewCategoryScanner(source, WithScanWorkers(3))
+
+	result, err := scanner.ScanCategories("/test", nil)
+
+	if err != nil {
+		t.Fatalf("ScanCategories() error = %v", err)
+	}
+	if len(result) != 12 {
+		t.Fatalf("ScanCategories() found %d categories, want 12", len(result))
+	}
+	for index, info := range result {
+		if want := fmt.Sprintf("category%02d", index); info.Category.Name != want || info.OutfitCount != 1 {
+			t.Errorf("category %d = %+v, want %s with one outfit", index, info, want)
+		}
+	}
+	if source.mostReading > 3 {
+		t.Errorf("read %d directories at once, want at most 3", source.mostReading)
+	}
+}
+
+func TestCategoryScanner_ReadsOneAtATimeWithoutWorkers(t *testing.T) {
+	source := &fakeWardrobe{
+		dirs:  map[string][]string{"/test": {"casual", "formal"}},
+		files: map[string][]string{testCategoryPath("casual"): {"a.avatar"}, testCategoryPath("formal"): {"b.avatar"}},
+	}
+	scanner := NewCategoryScanner(source, WithScanWorkers(0), WithDirectoryTimeout(0))
+
+	result, err := scanner.ScanCategories("/test", nil)
+
+	if err != nil || len(result) != 2 {
+		t.Fatalf("ScanCategories() = %+v, %v", result, err)
+	}
+	if source.mostReading != 1 {
+		t.Errorf("read %d directories at once, want 1", source.mostReading)
+	}
+}
+
+func TestCategoryScanner_ReportsCategoriesThatTimeOut(t *testing.T) {
+	source := &fakeWardrobe{
+		dirs:  map[string][]string{"/test": {"casual", "nas"}},
+		files: map[string][]string{testCategoryPath("casual"): {"outfit.avatar"}},
+	}
+	hang(t, source, testCategoryPath("nas"))
+	scanner := NewCategoryScanner(source, WithDirectoryTimeout(20*time.Millisecond))
+
+	result, err := scanner.ScanCategories("/test", nil)
+
+	if err != nil {
+		t.Fatalf("ScanCategories() error = %v, want the hung category reported on its own", err)
+	}
+	if len(result) != 2 || result[0].State != entities.CategoryStateHasOutfits {
+		t.Fatalf("ScanCategories() = %+v, want casual scanned", result)
+	}
+	if nas := result[1]; nas.State != entities.CategoryStateTimedOut || nas.Error == "" {
+		t.Errorf("hung category = %+v, want timed out with its error", nas)
+	}
+}
+
+func TestCategoryScanner_TimesOutHungDirectories(t *testing.T) {
+	source := &fakeWardrobe{}
+	hang(t, source, "/test")
+	hang(t, source, testCategoryPath("nas"))
+	scanner := NewCategoryScanner(source, WithDirectoryTimeout(20*time.Millisecond))
+
+	if _, err := scanner.ScanCategories("/test", nil); !stderrors.Is(err, errors.ErrTimedOut) {
+		t.Errorf("ScanCategories() of a hung root error = %v, want %v", err, errors.ErrTimedOut)
+	}
+	if _, err := scanner.GetOutfits(testCategoryPath("nas")); !stderrors.Is(err, errors.ErrTimedOut) {
+		t.Errorf("GetOutfits() of a hung category error = %v, want %v", err, errors.ErrTimedOut)
+	}
+}
+
+func TestCategoryScanner_StopsWhenCancelled(t *testing.T) {
+	source := &fakeWardrobe{dirs: map[string][]string{"/test": {"casual", "formal", "nas"}}}
+	hang(t, source, testCategoryPath("nas"))
+	scanner := NewCategoryScanner(source, WithDirectoryTimeout(0))
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	if _, err := scanner.ScanCategoriesContext(ctx, "/test", nil); !stderrors.Is(err, context.Canceled) {
+		t.Errorf("ScanCategoriesContext() error = %v, want %v", err, context.Canceled)
+	}
+	if _, err := scanner.ScanCategoriesContext(ctx, "/test", nil); !stderrors.Is(err, context.Canceled) {
+		t.Errorf("ScanCategoriesContext() with a cancelled context error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestCategoryScanner_ScansInItsContext(t *testing.T) {
+	source := &fakeWardrobe{dirs: map[string][]string{"/test": {"nas"}}}
+	hang(t, source, testCategoryPath("nas"))
+	started, stopped := 0, 0
+	scanner := NewCategoryScanner(source, WithDirectoryTimeout(0), WithScanContext(func() (context.Context, context.CancelFunc) {
+		started++
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		return ctx, func() {
+			stopped++
+			cancel()
+		}
+	}))
+
+	if _, err := scanner.ScanCategories("/test", nil); !stderrors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ScanCategories() error = %v, want the scan's context done", err)
+	}
+	if _, err := scanner.GetOutfits(testCategoryPath("nas")); !stderrors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetOutfits() error = %v, want the listing's context done", err)
+	}
+	if started != 2 || stopped != 2 {
+		t.Errorf("started %d and stopped %d contexts, want one of each per scan and listing", started, stopped)
+	}
+}
+
+func TestCategoryScanner_WaitsForAHungReadInsteadOfStartingAnother(t *testing.T) {
+	source := &fakeWardrobe{dirs: map[string][]string{"/test": {"nas"}}}
+	nas := testCategoryPath("nas")
+	hang(t, source, nas)
+	scanner := NewCategoryScanner(source, WithDirectoryTimeout(20*time.Millisecond))
+
+	for range 2 {
+		result, err := scanner.ScanCategories("/test", nil)
+		if err != nil || len(result) != 1 || result[0].State != entities.CategoryStateTimedOut {
+			t.Fatalf("ScanCategories() = %+v, %v; want nas timed out", result, err)
+		}
+	}
+	if _, err := scanner.FollowingSymlinks(validation.SymlinkPolicy{}).GetOutfits(nas); !stderrors.Is(err, errors.ErrTimedOut) {
+		t.Errorf("GetOutfits() error = %v, want %v", err, errors.ErrTimedOut)
+	}
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	if calls := source.readDirCalls[nas]; calls != 1 {
+		t.Errorf("read the hung directory %d times, want once", calls)
+	}
+	if calls := source.readDirCalls["/test"]; calls != 2 {
+		t.Errorf("read /test %d times, want a finished read started again", calls)
+	}
+}
+
+// rootSource opens only the roots it was given.
+type rootSource struct {
+	fakeWardrobe
+	roots   map[string]bool
+	saveErr error
+	saves   int
+}
+
+func (r *rootSource) Opens(root string) bool {
+	return r.roots[root]
+}
+
+func (r *rootSource) Save() error {
+	r.saves++
+	return r.saveErr
+}
+
+func TestWardrobeSources(t *testing.T) {
+	archives := &rootSource{
+		fakeWardrobe: fakeWardrobe{dirs: map[string][]string{"/packs.zip": {"summer"}}},
+		roots:        map[string]bool{"/packs.zip": true},
+		saveErr:      errors.ErrFileSystem,
+	}
+	folders := &rootSource{
+		fakeWardrobe: fakeWardrobe{dirs: map[string][]string{"/test": {"casual"}}},
+		roots:        map[string]bool{"/test": true},
+	}
+	sources := WardrobeSources{archives, folders}
+	scanner := NewCategoryScanner(sources)
+
+	for root, want := range map[string]string{"/packs.zip": "summer", "/test": "casual"} {
+		result, err := scanner.ScanCategories(root, nil)
+		if err != nil || len(result) != 1 || result[0].Category.Name != want {
+			t.Errorf("ScanCategories(%s) = %+v, %v; want %s from its own source", root, result, err, want)
+		}
+	}
+	if sources.Opens("/elsewhere") {
+		t.Error("Opens() = true for a root no source opens")
+	}
+	if _, err := scanner.ScanCategories("/elsewhere", nil); !stderrors.Is(err, errors.ErrDirectoryNotFound) {
+		t.Errorf("ScanCategories() of a root no source opens error = %v, want %v", err, errors.ErrDirectoryNotFound)
+	}
+	if err := sources.Save(); !stderrors.Is(err, errors.ErrFileSystem) {
+		t.Errorf("Save() error = %v, want the failed save", err)
+	}
+	if archives.saves != 3 || folders.saves != 3 {
+		t.Errorf("saves = %d and %d, want each source saved after both scans and Save()", archives.saves, folders.saves)
+	}
+}
@@ -0,0 +1,305 @@
+package system
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+)
+
+// ZipSource reads wardrobe roots that are zip archives, such as outfit
+// packs, without extracting them. The top-level folders of an archive are
+// its categories.
+type ZipSource struct {
+	archives archiveCache
+}
+
+// NewZipSource creates a source for zip archives.
+func NewZipSource() *ZipSource {
+	return &ZipSource{archives: archiveCache{format: entities.ArchiveZip, read: readZip}}
+}
+
+// Opens reports whether root names a zip archive.
+func (s *ZipSource) Opens(root string) bool {
+	return entities.ArchiveFormatOf(root) == entities.ArchiveZip
+}
+
+// Open returns the file system of the zip archive at root.
+func (s *ZipSource) Open(root string) (fs.FS, error) {
+	return s.archives.open(root)
+}
+
+func readZip(path string) (fs.FS, io.Closer, error) {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return reader, reader, nil
+}
+
+// Since a tar archive is held in memory while it is in use, maxTarEntrySize
+// and maxTarSize bound what is read from one file of it and from all of them,
+// so that a hostile archive cannot exhaust memory.
+const (
+	maxTarEntrySize = 256 << 20
+	maxTarSize      = 1 << 30
+)
+
+// TarSource reads wardrobe roots that are tar archives, gzipped or not,
+// without extracting them. Since a tar archive can only be read from start
+// to end, its files are held in memory while it is in use.
+type TarSource struct {
+	archives archiveCache
+}
+
+// NewTarSource creates a source for tar archives.
+func NewTarSource() *TarSource {
+	return &TarSource{archives: archiveCache{format: entities.ArchiveTar, read: readTar}}
+}
+
+// Opens reports whether root names a tar archive.
+func (s *TarSource) Opens(root string) bool {
+	return entities.ArchiveFormatOf(root) == entities.ArchiveTar
+}
+
+// Open returns the file system of the tar archive at root.
+func (s *TarSource) Open(root string) (fs.FS, error) {
+	return s.archives.open(root)
+}
+
+// readTar reads the regular files and directories of the tar archive at
+// path. Links and other special entries are left out. It fails on an
+// archive holding a file larger than maxTarEntrySize, or more than
+// maxTarSize bytes of files.
+func readTar(archivePath string) (fs.FS, io.Closer, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if lower := strings.ToLower(archivePath); strings.HasSuffix(lower, ".gz") || strings.HasSuffix(lower, ".tgz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	files := newMemoryFS()
+	var total int64
+	archive := tar.NewReader(reader)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if name == "." || !fs.ValidPath(name) {
+			continue
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			files.add(name, fs.ModeDir|header.FileInfo().Mode().Perm(), header.ModTime, nil)
+		case tar.TypeReg:
+			if header.Size > maxTarEntrySize {
+				return nil, nil, fmt.Errorf("%s in %s is larger than %d bytes", name, archivePath, maxTarEntrySize)
+			}
+			if total += header.Size; total > maxTarSize {
+				return nil, nil, fmt.Errorf("%s holds more than %d bytes of files", archivePath, maxTarSize)
+			}
+			data, err := io.ReadAll(io.LimitReader(archive, header.Size))
+			if err != nil {
+				return nil, nil, err
+			}
+			files.add(name, header.FileInfo().Mode().Perm(), header.ModTime, data)
+		}
+	}
+	files.sort()
+	return files, nil, nil
+}
+
+// archiveCache keeps archives open between reads. An archive is read again,
+// and the copy read before closed, once its size or modification time
+// changes.
+type archiveCache struct {
+	format entities.ArchiveFormat
+	read   func(path string) (fs.FS, io.Closer, error)
+
+	mu     sync.Mutex
+	opened map[string]openedArchive
+}
+
+type openedArchive struct {
+	fsys    fs.FS
+	closer  io.Closer
+	size    int64
+	modTime time.Time
+}
+
+func (c *archiveCache) open(archivePath string) (fs.FS, error) {
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	previous, ok := c.opened[archivePath]
+	if ok && previous.size == info.Size() && previous.modTime.Equal(info.ModTime()) {
+		return previous.fsys, nil
+	}
+	fsys, closer, err := c.read(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	if ok && previous.closer != nil {
+		_ = previous.closer.Close()
+	}
+	if c.opened == nil {
+		c.opened = make(map[string]openedArchive)
+	}
+	opened := archiveFS{FS: fsys, archive: entities.ArchivePath{Format: c.format, Archive: archivePath}}
+	c.opened[archivePath] = openedArchive{fsys: opened, closer: closer, size: info.Size(), modTime: info.ModTime()}
+	return opened, nil
+}
+
+// archiveFS is the file system of an archive, reporting errors with the URI
+// of the file in the archive rather than its bare name.
+type archiveFS struct {
+	fs.FS
+	archive entities.ArchivePath
+}
+
+func (a archiveFS) Open(name string) (fs.File, error) {
+	file, err := a.FS.Open(name)
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		archived := a.archive
+		archived.Name = pathErr.Path
+		return nil, &fs.PathError{Op: pathErr.Op, Path: archived.URI(), Err: pathErr.Err}
+	}
+	return file, err
+}
+
+// memoryFS is a read-only file system held in memory.
+type memoryFS struct {
+	files map[string]*memoryFile
+}
+
+func newMemoryFS() *memoryFS {
+	return &memoryFS{files: map[string]*memoryFile{
+		".": {name: ".", mode: fs.ModeDir | 0o755},
+	}}
+}
+
+// add adds the file or directory name, adding the directories it is in that
+// the archive did not list itself. A name added twice keeps the last copy.
+func (m *memoryFS) add(name string, mode fs.FileMode, modTime time.Time, data []byte) {
+	if existing, ok := m.files[name]; ok {
+		existing.mode, existing.modTime, existing.data = mode, modTime, data
+		return
+	}
+	parent := path.Dir(name)
+	if _, ok := m.files[parent]; !ok {
+		m.add(parent, fs.ModeDir|0o755, modTime, nil)
+	}
+	file := &memoryFile{name: path.Base(name), mode: mode, modTime: modTime, data: data}
+	m.files[name] = file
+	m.files[parent].children = append(m.files[parent].children, file)
+}
+
+// sort orders the children of each directory by name, as fs.ReadDir does.
+func (m *memoryFS) sort() {
+	for _, file := range m.files {
+		sort.Slice(file.children, func(i, j int) bool {
+			return file.children[i].name < file.children[j].name
+		})
+	}
+}
+
+func (m *memoryFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	file, ok := m.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &openMemoryFile{memoryFile: file, path: name, reader: bytes.NewReader(file.data)}, nil
+}
+
+// memoryFile is a file or directory of a memoryFS, and its own FileInfo.
+type memoryFile struct {
+	name     string
+	mode     fs.FileMode
+	modTime  time.Time
+	data     []byte
+	children []*memoryFile
+}
+
+func (f *memoryFile) Name() string       { return f.name }
+func (f *memoryFile) Size() int64        { return int64(len(f.data)) }
+func (f *memoryFile) Mode() fs.FileMode  { return f.mode }
+func (f *memoryFile) ModTime() time.Time { return f.modTime }
+func (f *memoryFile) IsDir() bool        { return f.mode.IsDir() }
+func (f *memoryFile) Sys() any           { return nil }
+
+// openMemoryFile is an open file or directory of a memoryFS.
+type openMemoryFile struct {
+	*memoryFile
+	path   string
+	reader *bytes.Reader
+	listed int
+}
+
+func (f *openMemoryFile) Stat() (fs.FileInfo, error) {
+	return f.memoryFile, nil
+}
+
+func (f *openMemoryFile) Read(p []byte) (int, error) {
+	if f.IsDir() {
+		return 0, &fs.PathError{Op: "read", Path: f.path, Err: fs.ErrInvalid}
+	}
+	return f.reader.Read(p)
+}
+
+func (f *openMemoryFile) Close() error {
+	return nil
+}
+
+func (f *openMemoryFile) ReadDir(count int) ([]fs.DirEntry, error) {
+	if !f.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: f.path, Err: fs.ErrInvalid}
+	}
+	children := f.children[f.listed:]
+	if count > 0 {
+		if len(children) == 0 {
+			return nil, io.EOF
+		}
+		children = children[:min(count, len(children))]
+	}
+	f.listed += len(children)
+	entries := make([]fs.DirEntry, len(children))
+	for index, child := range children {
+		entries[index] = fs.FileInfoToDirEntry(child)
+	}
+	return entries, nil
+}
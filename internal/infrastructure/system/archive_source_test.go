@@ -0,0 +1,221 @@
+package system
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	stderrors "errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// packFiles is the outfit pack written to test archives.
+var packFiles = map[string]string{
+	"casual/one.avatar":  "one",
+	"casual/one.png":     "preview",
+	"formal/suit.avatar": "suit",
+}
+
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	archive := zip.NewWriter(file)
+	for name, data := range files {
+		entry, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(entry, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTar(t *testing.T, path string, headers []*tar.Header, files map[string]string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var writer io.Writer = file
+	if strings.HasSuffix(path, ".gz") {
+		gz := gzip.NewWriter(file)
+		defer gz.Close()
+		writer = gz
+	}
+	archive := tar.NewWriter(writer)
+	for _, header := range headers {
+		if err := archive.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, data := range files {
+		if err := archive.WriteHeader(&tar.Header{Name: "./" + name, Mode: 0o644, Size: int64(len(data)), ModTime: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(archive, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func packNames() []string {
+	names := make([]string, 0, len(packFiles))
+	for name := range packFiles {
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestZipSource_ReadsArchives(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summer.zip")
+	writeZip(t, path, packFiles)
+	source := NewZipSource()
+	if !source.Opens(path) || source.Opens(filepath.Dir(path)) || source.Opens("summer.tar") {
+		t.Error("Opens() should take only zip archives")
+	}
+
+	wardrobe, err := source.Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := fstest.TestFS(wardrobe, packNames()...); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := fs.ReadFile(wardrobe, "casual/one.avatar"); err != nil || string(data) != "one" {
+		t.Errorf("ReadFile() = %q, %v", data, err)
+	}
+}
+
+func TestTarSource_ReadsArchives(t *testing.T) {
+	for _, name := range []string{"summer.tar", "summer.tar.gz"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			writeTar(t, path, []*tar.Header{
+				{Name: "formal/", Typeflag: tar.TypeDir, Mode: 0o755, ModTime: time.Now()},
+				{Name: "formal/latest.avatar", Typeflag: tar.TypeSymlink, Linkname: "suit.avatar"},
+				{Name: "../escape.avatar", Mode: 0o644},
+			}, packFiles)
+			source := NewTarSource()
+			if !source.Opens(path) || source.Opens(filepath.Join(filepath.Dir(path), "summer.zip")) {
+				t.Error("Opens() should take only tar archives")
+			}
+
+			wardrobe, err := source.Open(path)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			if err := fstest.TestFS(wardrobe, packNames()...); err != nil {
+				t.Fatal(err)
+			}
+			entries, err := fs.ReadDir(wardrobe, "formal")
+			if err != nil || len(entries) != 1 || entries[0].Name() != "suit.avatar" {
+				t.Errorf("ReadDir() = %v, %v; want only suit.avatar, without the link", entries, err)
+			}
+			if _, err := fs.Stat(wardrobe, "escape.avatar"); !stderrors.Is(err, fs.ErrNotExist) {
+				t.Errorf("Stat() of an entry outside the archive error = %v, want it left out", err)
+			}
+		})
+	}
+}
+
+func TestArchiveSources_KeepArchivesOpenUntilTheyChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summer.zip")
+	writeZip(t, path, packFiles)
+	source := NewZipSource()
+	first, err := source.Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if again, err := source.Open(path); err != nil || again != first {
+		t.Errorf("Open() of an unchanged archive = %v, %v; want it kept open", again, err)
+	}
+
+	writeZip(t, path, map[string]string{"winter/coat.avatar": "coat"})
+	setModTime(t, path, time.Now().Add(time.Minute))
+	changed, err := source.Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if entries, err := fs.ReadDir(changed, "."); err != nil || len(entries) != 1 || entries[0].Name() != "winter" {
+		t.Errorf("ReadDir() of a changed archive = %v, %v; want it read again", entries, err)
+	}
+}
+
+func TestArchiveSources_ReportErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewZipSource().Open(filepath.Join(dir, "missing.zip")); !stderrors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Open() of a missing archive error = %v, want %v", err, fs.ErrNotExist)
+	}
+
+	broken := filepath.Join(dir, "broken.tar.gz")
+	if err := os.WriteFile(broken, []byte("not gzip"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewTarSource().Open(broken); err == nil {
+		t.Error("Open() of a corrupt archive succeeded")
+	}
+	if _, err := NewZipSource().Open(broken); err == nil {
+		t.Error("Open() of a file that isn't a zip archive succeeded")
+	}
+
+	// The header of a file too large to hold in memory is enough to refuse
+	// the archive; its contents are never written or read.
+	oversized := filepath.Join(dir, "oversized.tar")
+	file, err := os.Create(oversized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tar.NewWriter(file).WriteHeader(&tar.Header{Name: "casual/huge.avatar", Mode: 0o644, Size: maxTarEntrySize + 1}); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+	if _, err := NewTarSource().Open(oversized); err == nil || !strings.Contains(err.Error(), "casual/huge.avatar") {
+		t.Errorf("Open() of an archive holding an oversized file error = %v, want it refused", err)
+	}
+
+	path := filepath.Join(dir, "summer.tar")
+	writeTar(t, path, nil, packFiles)
+	wardrobe, err := NewTarSource().Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	_, err = fs.ReadDir(wardrobe, "missing")
+	if want := "tar:" + filepath.ToSlash(path) + "!/missing"; !stderrors.Is(err, fs.ErrNotExist) || !strings.Contains(err.Error(), want) {
+		t.Errorf("ReadDir() of a missing category error = %v, want %s not found", err, want)
+	}
+}
+
+func TestArchiveSources_OpenOnlyRegularFiles(t *testing.T) {
+	dir := t.TempDir()
+	named := filepath.Join(dir, "summer.zip")
+	if err := os.Mkdir(named, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if NewZipSource().Opens(named) || !NewDirectorySource(nil).Opens(named) {
+		t.Error("a directory named like a zip archive should be read as a directory")
+	}
+
+	archive := filepath.Join(dir, "winter.tar")
+	writeTar(t, archive, nil, packFiles)
+	if !NewTarSource().Opens(archive) || NewDirectorySource(nil).Opens(archive) {
+		t.Error("a tar archive should be read as an archive")
+	}
+}
@@ -0,0 +1,141 @@
+package system
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+)
+
+// ContentHashStore persists the index of content hashes.
+type ContentHashStore interface {
+	Load() (*entities.ContentHashIndex, error)
+	Update(change func(current *entities.ContentHashIndex) (*entities.ContentHashIndex, error)) error
+}
+
+// ContentHasher hashes outfit files with SHA-256. The index is read from its
+// store on first use, and a file is only read again once its size or
+// modification time changes.
+type ContentHasher struct {
+	store     ContentHashStore
+	files     map[string]entities.ContentHash
+	changed   map[string]entities.ContentHash
+	forgotten map[string]bool
+}
+
+// NewContentHasher creates a content hasher that keeps its index in store.
+func NewContentHasher(store ContentHashStore) *ContentHasher {
+	return &ContentHasher{store: store, changed: make(map[string]entities.ContentHash), forgotten: make(map[string]bool)}
+}
+
+func (h *ContentHasher) load() error {
+	if h.files != nil {
+		return nil
+	}
+	index, err := h.store.Load()
+	if err != nil {
+		return err
+	}
+	h.files = make(map[string]entities.ContentHash)
+	if index != nil {
+		for path, hash := range index.Files {
+			h.files[path] = hash
+		}
+	}
+	return nil
+}
+
+// Hash returns the content hash of the file at path.
+func (h *ContentHasher) Hash(path string) (string, error) {
+	if err := h.load(); err != nil {
+		return "", err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if recorded, ok := h.files[path]; ok && recorded.Matches(info.Size(), info.ModTime()) {
+		return recorded.Hash, nil
+	}
+	hash, err := hashFile(path)
+	if err != nil {
+		return "", err
+	}
+	entry := entities.ContentHash{Size: info.Size(), ModTime: info.ModTime(), Hash: hash}
+	h.files[path] = entry
+	h.changed[path] = entry
+	delete(h.forgotten, path)
+	return hash, nil
+}
+
+// Recorded returns the hash last recorded for path.
+func (h *ContentHasher) Recorded(path string) (string, bool) {
+	if err := h.load(); err != nil {
+		return "", false
+	}
+	recorded, ok := h.files[path]
+	return recorded.Hash, ok
+}
+
+// Retain forgets the hashes of every path but those in paths.
+func (h *ContentHasher) Retain(paths map[string]bool) {
+	if err := h.load(); err != nil {
+		return
+	}
+	for path := range h.files {
+		if !paths[path] {
+			delete(h.files, path)
+			delete(h.changed, path)
+			h.forgotten[path] = true
+		}
+	}
+}
+
+// Save adds the hashes computed since the last save to the stored index, and
+// drops the ones forgotten, keeping any others that another process recorded
+// in the meantime.
+func (h *ContentHasher) Save() error {
+	if len(h.changed) == 0 && len(h.forgotten) == 0 {
+		return nil
+	}
+	err := h.store.Update(func(current *entities.ContentHashIndex) (*entities.ContentHashIndex, error) {
+		if current == nil {
+			current = &entities.ContentHashIndex{}
+		}
+		if current.Files == nil {
+			current.Files = make(map[string]entities.ContentHash)
+		}
+		for path, hash := range h.changed {
+			current.Files[path] = hash
+		}
+		for path := range h.forgotten {
+			delete(current.Files, path)
+		}
+		return current, nil
+	})
+	if err != nil {
+		return err
+	}
+	h.changed = make(map[string]entities.ContentHash)
+	h.forgotten = make(map[string]bool)
+	return nil
+}
+
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Ensure ContentHasher implements the interface
+var _ interfaces.ContentHasher = (*ContentHasher)(nil)
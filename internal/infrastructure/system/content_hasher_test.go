@@ -0,0 +1,154 @@
+package system
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+)
+
+func newTestHashStore(dir string) *FileService[entities.ContentHashIndex] {
+	return NewFileService[entities.ContentHashIndex]("hashes.json",
+		WithDirectoryProvider[entities.ContentHashIndex](newMockDirProvider(dir, nil)))
+}
+
+func TestContentHasher_ReusesHashesUntilTheFileChanges(t *testing.T) {
+	dir := t.TempDir()
+	outfit := filepath.Join(dir, "outfit.avatar")
+	if err := os.WriteFile(outfit, []byte("first"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(outfit, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	store := newTestHashStore(dir)
+	hasher := NewContentHasher(store)
+
+	first, err := hasher.Hash(outfit)
+	sum := sha256.Sum256([]byte("first"))
+	if err != nil || first != "sha256:"+hex.EncodeToString(sum[:]) {
+		t.Fatalf("Hash() = %q, %v; want the SHA-256 of the contents", first, err)
+	}
+	if err := hasher.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// Same size and time: the stored hash is trusted without reading the file.
+	if err := os.WriteFile(outfit, []byte("other"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(outfit, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := NewContentHasher(store)
+	if got, err := reloaded.Hash(outfit); err != nil || got != first {
+		t.Fatalf("Hash() of an unchanged file = %q, %v; want the stored %q", got, err, first)
+	}
+
+	if err := os.Chtimes(outfit, modTime.Add(time.Minute), modTime.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := reloaded.Hash(outfit); err != nil || got == first {
+		t.Fatalf("Hash() of a modified file = %q, %v; want a new hash", got, err)
+	}
+
+	if err := os.Remove(outfit); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reloaded.Hash(outfit); err == nil {
+		t.Fatal("Hash() of a missing file succeeded")
+	}
+	if got, ok := reloaded.Recorded(outfit); !ok || got == first {
+		t.Fatalf("Recorded() = %q, %t; want the latest hash kept for the removed file", got, ok)
+	}
+	if _, ok := reloaded.Recorded(filepath.Join(dir, "never.avatar")); ok {
+		t.Fatal("Recorded() found a file that was never hashed")
+	}
+}
+
+func TestContentHasher_SaveKeepsHashesRecordedElsewhere(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.avatar", "b.avatar"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store := newTestHashStore(dir)
+	one, other := NewContentHasher(store), NewContentHasher(store)
+	if err := one.Save(); err != nil {
+		t.Fatalf("Save() without changes error = %v", err)
+	}
+	if _, err := one.Hash(filepath.Join(dir, "a.avatar")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Hash(filepath.Join(dir, "b.avatar")); err != nil {
+		t.Fatal(err)
+	}
+	if err := one.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := store.Load()
+	if err != nil || len(index.Files) != 2 {
+		t.Fatalf("stored index = %+v, %v; want both hashes", index, err)
+	}
+}
+
+func TestContentHasher_RetainForgetsOtherHashes(t *testing.T) {
+	dir := t.TempDir()
+	kept, deleted := filepath.Join(dir, "kept.avatar"), filepath.Join(dir, "deleted.avatar")
+	for _, path := range []string{kept, deleted} {
+		if err := os.WriteFile(path, []byte(path), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store := newTestHashStore(dir)
+	hasher := NewContentHasher(store)
+	for _, path := range []string{kept, deleted} {
+		if _, err := hasher.Hash(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := hasher.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := NewContentHasher(store)
+	reloaded.Retain(map[string]bool{kept: true})
+	if _, ok := reloaded.Recorded(deleted); ok {
+		t.Error("Recorded() found a forgotten hash")
+	}
+	if err := reloaded.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	index, err := store.Load()
+	if _, ok := index.Files[kept]; err != nil || len(index.Files) != 1 || !ok {
+		t.Fatalf("stored index = %+v, %v; want only the retained hash", index, err)
+	}
+}
+
+func TestContentHasher_UnreadableIndex(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, appName), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, appName, "hashes.json"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	hasher := NewContentHasher(newTestHashStore(dir))
+
+	if _, err := hasher.Hash(filepath.Join(dir, "outfit.avatar")); err == nil {
+		t.Fatal("Hash() with an unreadable index succeeded")
+	}
+	if _, ok := hasher.Recorded(filepath.Join(dir, "outfit.avatar")); ok {
+		t.Fatal("Recorded() with an unreadable index found a hash")
+	}
+}
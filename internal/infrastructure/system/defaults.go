@@ -1,41 +1,62 @@
 package system
 
 import (
-	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
-	"strconv"
-	"strings"
-	"sync"
 	"time"
 )
 
-var fileLocks sync.Map
-
-const (
-	fileLockPollInterval = 10 * time.Millisecond
-	fileLockTimeout      = 5 * time.Second
-	staleFileLockAfter   = 30 * time.Second
-)
+type defaultDataManager struct {
+	lockTimeout time.Duration
+}
 
-type defaultDataManager struct{}
+// NewDefaultDataManager returns the file-backed DataManager. lockTimeout
+// bounds how long a read or write waits for the file lock; zero waits for as
+// long as another process holds it.
+func NewDefaultDataManager(lockTimeout time.Duration) DataManager {
+	return &defaultDataManager{lockTimeout: lockTimeout}
+}
 
+// Read returns the contents of path while holding a shared lock, so readers
+// never overlap a writer's read-modify-write but do not block each other.
 func (d *defaultDataManager) Read(path string) ([]byte, error) {
-	return os.ReadFile(path)
+	var data []byte
+	err := withSharedPathLock(path, d.lockTimeout, func() error {
+		var err error
+		data, err = os.ReadFile(path)
+		return err
+	})
+	return data, err
 }
 
 func (d *defaultDataManager) Write(path string, data []byte) error {
-	lock := lockForPath(path)
-	lock.Lock()
-	defer lock.Unlock()
+	return withPathLock(path, d.lockTimeout, func() error {
+		return writeFileAtomically(path, data, 0600)
+	})
+}
 
-	release, err := acquireFileLock(path)
-	if err != nil {
-		return err
-	}
-	defer release()
+// Update holds the lock for path while it reads the current contents, passes
+// them to change, and writes the result, so concurrent processes cannot lose
+// each other's changes. A missing file reads as nil, and a nil result leaves
+// the file untouched.
+func (d *defaultDataManager) Update(path string, change func(current []byte) ([]byte, error)) error {
+	return withPathLock(path, d.lockTimeout, func() error {
+		current, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		data, err := change(current)
+		if err != nil || data == nil {
+			return err
+		}
+		return writeFileAtomically(path, data, 0600)
+	})
+}
 
+// writeFileAtomically replaces path with data by writing a synced temp file in
+// the same directory and renaming it into place.
+func writeFileAtomically(path string, data []byte, perm os.FileMode) error {
 	dir := filepath.Dir(path)
 	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-")
 	if err != nil {
@@ -60,7 +81,7 @@ func (d *defaultDataManager) Write(path string, data []byte) error {
 	if err := tmp.Close(); err != nil {
 		return err
 	}
-	if err := os.Chmod(tmpPath, 0600); err != nil {
+	if err := os.Chmod(tmpPath, perm); err != nil {
 		return err
 	}
 	if err := os.Rename(tmpPath, path); err != nil {
@@ -71,66 +92,6 @@ func (d *defaultDataManager) Write(path string, data []byte) error {
 	return syncDirectory(dir)
 }
 
-func lockForPath(path string) *sync.Mutex {
-	absolute, err := filepath.Abs(path)
-	if err != nil {
-		absolute = filepath.Clean(path)
-	}
-	value, _ := fileLocks.LoadOrStore(absolute, &sync.Mutex{})
-	return value.(*sync.Mutex)
-}
-
-func acquireFileLock(path string) (func(), error) {
-	lockPath := path + ".lock"
-	deadline := time.Now().Add(fileLockTimeout)
-
-	for {
-		lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
-		if err == nil {
-			_, _ = fmt.Fprintf(lockFile, "%d\n", os.Getpid())
-			return func() {
-				_ = lockFile.Close()
-				_ = os.Remove(lockPath)
-			}, nil
-		}
-		if !os.IsExist(err) {
-			return nil, err
-		}
-		stale, staleErr := lockIsStale(lockPath)
-		if staleErr != nil && !os.IsNotExist(staleErr) {
-			return nil, staleErr
-		}
-		if stale {
-			_ = os.Remove(lockPath)
-			continue
-		}
-		if time.Now().After(deadline) {
-			return nil, fmt.Errorf("timed out waiting for file lock %q", lockPath)
-		}
-		time.Sleep(fileLockPollInterval)
-	}
-}
-
-func lockIsStale(path string) (bool, error) {
-	info, err := os.Stat(path)
-	if err != nil {
-		return false, err
-	}
-	if time.Since(info.ModTime()) <= staleFileLockAfter {
-		return false, nil
-	}
-
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return false, err
-	}
-	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
-	if err != nil {
-		return true, nil
-	}
-	return !processExists(pid), nil
-}
-
 func syncDirectory(path string) error {
 	if runtime.GOOS == "windows" {
 		return nil
@@ -0,0 +1,270 @@
+package system
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+)
+
+// racyInterval is how recently a directory may have been modified for its
+// listing to still be read again every time. A listing read within the same
+// tick of the filesystem clock as a change could miss it and still match.
+const racyInterval = 2 * time.Second
+
+// WardrobeIndexStore persists the wardrobe index.
+type WardrobeIndexStore interface {
+	Load() (*entities.WardrobeIndex, error)
+	Update(change func(current *entities.WardrobeIndex) (*entities.WardrobeIndex, error)) error
+}
+
+// DirectorySource reads wardrobe roots that are plain directories. It lists
+// their directories from an index kept in its store, so a directory costs a
+// stat instead of a read until its modification time changes. The index is
+// read from the store on first use and kept in memory for the rest of the
+// run. It is safe for concurrent use; directories are read without holding
+// its lock.
+type DirectorySource struct {
+	mu      sync.Mutex
+	store   WardrobeIndexStore
+	now     func() time.Time
+	dirs    map[string]entities.IndexedDirectory
+	changed map[string]entities.IndexedDirectory
+	removed map[string]bool
+}
+
+// NewDirectorySource creates a directory source that keeps its index in
+// store.
+func NewDirectorySource(store WardrobeIndexStore) *DirectorySource {
+	return &DirectorySource{
+		store:   store,
+		now:     time.Now,
+		changed: make(map[string]entities.IndexedDirectory),
+		removed: make(map[string]bool),
+	}
+}
+
+// Opens reports whether root is read as a directory, which is any root that
+// does not name an archive.
+func (s *DirectorySource) Opens(root string) bool {
+	return entities.ArchiveFormatOf(root) == ""
+}
+
+// Open returns the file system of the directory at root. Its errors carry
+// filesystem paths rather than names within root.
+func (s *DirectorySource) Open(root string) (fs.FS, error) {
+	return directoryFS{source: s, root: root}, nil
+}
+
+// directoryFS is the file system of one directory root, listing its
+// directories through the source's index.
+type directoryFS struct {
+	source *DirectorySource
+	root   string
+}
+
+func (d directoryFS) path(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return filepath.Join(d.root, filepath.FromSlash(name)), nil
+}
+
+func (d directoryFS) Open(name string) (fs.File, error) {
+	path, err := d.path("open", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// Stat follows symbolic links.
+func (d directoryFS) Stat(name string) (fs.FileInfo, error) {
+	path, err := d.path("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Stat(path)
+}
+
+// ReadDir lists the directory name, reading it only when the index has no
+// listing for its current modification time.
+func (d directoryFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	path, err := d.path("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	return d.source.readDir(path)
+}
+
+// load reads the index once. An index that can't be read is started afresh;
+// it only saves reading directories again.
+func (s *DirectorySource) load() {
+	if s.dirs != nil {
+		return
+	}
+	s.dirs = make(map[string]entities.IndexedDirectory)
+	index, err := s.store.Load()
+	if err != nil || index == nil {
+		return
+	}
+	for path, dir := range index.Directories {
+		s.dirs[path] = dir
+	}
+}
+
+func (s *DirectorySource) readDir(path string) ([]fs.DirEntry, error) {
+	info, err := os.Stat(path)
+	if indexed, ok := s.lookup(path, info, err); ok || err != nil {
+		return indexedEntries(path, indexed), err
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	s.record(path, info, entries)
+	return entries, nil
+}
+
+// lookup returns the indexed listing of path if it is still current. A path
+// that could not be stat'ed is dropped from the index.
+func (s *DirectorySource) lookup(path string, info os.FileInfo, statErr error) ([]entities.FileEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load()
+	if statErr != nil {
+		s.forget(path)
+		return nil, false
+	}
+	if indexed, ok := s.dirs[path]; ok && indexed.Matches(info.ModTime()) {
+		return indexed.FileEntries(), true
+	}
+	return nil, false
+}
+
+// record indexes the listing just read from path, unless the directory was
+// modified too recently for its modification time to be trusted.
+func (s *DirectorySource) record(path string, info os.FileInfo, dirEntries []fs.DirEntry) {
+	entries := make([]entities.FileEntry, len(dirEntries))
+	for index, entry := range dirEntries {
+		entries[index] = entities.FileEntry{
+			FileName:    entry.Name(),
+			IsDirectory: entry.IsDir(),
+			IsSymlink:   entry.Type()&fs.ModeSymlink != 0,
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if previous, ok := s.dirs[path]; ok {
+		s.forgetRemovedDirectories(path, previous, entries)
+	}
+	if s.now().Sub(info.ModTime()) < racyInterval {
+		s.forget(path)
+		return
+	}
+	indexed := entities.NewIndexedDirectory(info.ModTime(), entries)
+	s.dirs[path] = indexed
+	s.changed[path] = indexed
+	delete(s.removed, path)
+}
+
+// forgetRemovedDirectories drops the listings of subdirectories of path that
+// were in its previous listing but are gone now.
+func (s *DirectorySource) forgetRemovedDirectories(path string, previous entities.IndexedDirectory, entries []entities.FileEntry) {
+	present := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		present[entry.FileName] = true
+	}
+	for _, entry := range previous.Entries {
+		if entry.IsDirectory && !present[entry.Name] {
+			s.forget(filepath.Join(path, entry.Name))
+		}
+	}
+}
+
+// indexedEntries returns an indexed listing of the directory at path as
+// directory entries.
+func indexedEntries(path string, files []entities.FileEntry) []fs.DirEntry {
+	if files == nil {
+		return nil
+	}
+	entries := make([]fs.DirEntry, len(files))
+	for index, file := range files {
+		entries[index] = indexedEntry{dir: path, file: file}
+	}
+	return entries
+}
+
+// indexedEntry is an entry of an indexed listing. Its type comes from the
+// index; its info is read when asked for.
+type indexedEntry struct {
+	dir  string
+	file entities.FileEntry
+}
+
+func (e indexedEntry) Name() string {
+	return e.file.FileName
+}
+
+func (e indexedEntry) IsDir() bool {
+	return e.Type().IsDir()
+}
+
+func (e indexedEntry) Type() fs.FileMode {
+	switch {
+	case e.file.IsSymlink:
+		return fs.ModeSymlink
+	case e.file.IsDirectory:
+		return fs.ModeDir
+	default:
+		return 0
+	}
+}
+
+func (e indexedEntry) Info() (fs.FileInfo, error) {
+	return os.Lstat(filepath.Join(e.dir, e.file.FileName))
+}
+
+func (s *DirectorySource) forget(path string) {
+	if _, ok := s.dirs[path]; !ok {
+		return
+	}
+	delete(s.dirs, path)
+	delete(s.changed, path)
+	s.removed[path] = true
+}
+
+// Save writes the listings read since the last save to the stored index,
+// keeping those another process recorded in the meantime.
+func (s *DirectorySource) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.changed) == 0 && len(s.removed) == 0 {
+		return nil
+	}
+	err := s.store.Update(func(current *entities.WardrobeIndex) (*entities.WardrobeIndex, error) {
+		if current == nil {
+			current = &entities.WardrobeIndex{}
+		}
+		if current.Directories == nil {
+			current.Directories = make(map[string]entities.IndexedDirectory)
+		}
+		for path := range s.removed {
+			delete(current.Directories, path)
+		}
+		for path, dir := range s.changed {
+			current.Directories[path] = dir
+		}
+		return current, nil
+	})
+	if err != nil {
+		return err
+	}
+	s.changed = make(map[string]entities.IndexedDirectory)
+	s.removed = make(map[string]bool)
+	return nil
+}
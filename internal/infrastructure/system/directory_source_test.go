@@ -0,0 +1,283 @@
+package system
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+)
+
+func newTestIndexStore(t *testing.T) *FileService[entities.WardrobeIndex] {
+	return NewFileService[entities.WardrobeIndex]("index.json",
+		WithDirectoryProvider[entities.WardrobeIndex](newMockDirProvider(t.TempDir(), nil)))
+}
+
+func setModTime(t *testing.T, path string, modTime time.Time) {
+	t.Helper()
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func entryNames(entries []fs.DirEntry) map[string]bool {
+	names := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		names[entry.Name()] = true
+	}
+	return names
+}
+
+// readDir lists the directory at path as the root of source.
+func readDir(source *DirectorySource, path string) ([]fs.DirEntry, error) {
+	wardrobe, err := source.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadDir(wardrobe, ".")
+}
+
+func TestDirectorySource_ReadsDirectoriesAgainOnlyWhenModified(t *testing.T) {
+	casual := filepath.Join(t.TempDir(), "casual")
+	if err := os.MkdirAll(filepath.Join(casual, "extras"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(casual, "one.avatar"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	setModTime(t, casual, modTime)
+	store := newTestIndexStore(t)
+
+	entries, err := readDir(NewDirectorySource(store), casual)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("ReadDir() = %v, %v; want one.avatar and extras", entries, err)
+	}
+	manager := NewDirectorySource(store)
+	if _, err := readDir(manager, casual); err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// Same modification time: the stored listing is used without reading.
+	if err := os.WriteFile(filepath.Join(casual, "two.avatar"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	setModTime(t, casual, modTime)
+	reloaded := NewDirectorySource(store)
+	entries, err = readDir(reloaded, casual)
+	if names := entryNames(entries); err != nil || names["two.avatar"] || !names["extras"] {
+		t.Fatalf("ReadDir() of an unmodified directory = %v, %v; want the indexed listing", entries, err)
+	}
+	for _, entry := range entries {
+		if entry.Name() == "extras" && !entry.IsDir() {
+			t.Error("indexed extras lost its directory flag")
+		}
+	}
+
+	setModTime(t, casual, modTime.Add(time.Minute))
+	entries, err = readDir(reloaded, casual)
+	if names := entryNames(entries); err != nil || !names["two.avatar"] {
+		t.Fatalf("ReadDir() of a modified directory = %v, %v; want two.avatar listed", entries, err)
+	}
+}
+
+func TestDirectorySource_DoesNotIndexRecentlyModifiedDirectories(t *testing.T) {
+	casual := t.TempDir()
+	store := newTestIndexStore(t)
+	manager := NewDirectorySource(store)
+
+	if _, err := readDir(manager, casual); err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if index, err := store.Load(); err != nil || index != nil {
+		t.Fatalf("stored index = %+v, %v; want nothing saved for a directory modified just now", index, err)
+	}
+}
+
+func TestDirectorySource_ForgetsRemovedDirectories(t *testing.T) {
+	root := t.TempDir()
+	hats := filepath.Join(root, "hats")
+	if err := os.Mkdir(hats, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	setModTime(t, hats, modTime)
+	setModTime(t, root, modTime)
+	store := newTestIndexStore(t)
+	manager := NewDirectorySource(store)
+	for _, path := range []string{root, hats} {
+		if _, err := readDir(manager, path); err != nil {
+			t.Fatalf("ReadDir(%s) error = %v", path, err)
+		}
+	}
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if err := os.Remove(hats); err != nil {
+		t.Fatal(err)
+	}
+	setModTime(t, root, modTime.Add(time.Minute))
+	reloaded := NewDirectorySource(store)
+	if _, err := readDir(reloaded, root); err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if _, err := readDir(reloaded, hats); err == nil {
+		t.Fatal("ReadDir() of a removed directory succeeded")
+	}
+	if err := reloaded.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	index, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, ok := index.Directories[hats]; ok || len(index.Directories) != 1 {
+		t.Fatalf("stored index = %+v, want only the root", index.Directories)
+	}
+}
+
+func TestDirectorySource_StartsAfreshFromUnreadableIndex(t *testing.T) {
+	store := newTestIndexStore(t)
+	path, err := store.FilePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	casual := t.TempDir()
+	if err := os.WriteFile(filepath.Join(casual, "one.avatar"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := readDir(NewDirectorySource(store), casual)
+	if err != nil || !entryNames(entries)["one.avatar"] {
+		t.Fatalf("ReadDir() = %v, %v; want the directory read", entries, err)
+	}
+}
+
+func TestDirectorySource_ReadsDirectoriesConcurrently(t *testing.T) {
+	root := t.TempDir()
+	modTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var paths []string
+	for index := range 8 {
+		path := filepath.Join(root, fmt.Sprintf("category%d", index))
+		if err := os.Mkdir(path, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		setModTime(t, path, modTime)
+		paths = append(paths, path)
+	}
+	store := newTestIndexStore(t)
+	manager := NewDirectorySource(store)
+
+	var wg sync.WaitGroup
+	for _, path := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := readDir(manager, path); err != nil {
+				t.Errorf("ReadDir(%s) error = %v", path, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := manager.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	index, err := store.Load()
+	if err != nil || len(index.Directories) != len(paths) {
+		t.Fatalf("stored index = %+v, %v; want every directory", index, err)
+	}
+}
+
+func TestDirectorySource_ReadsWardrobeRoots(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "casual"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "casual", "one.avatar"), []byte("outfit"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	source := NewDirectorySource(newTestIndexStore(t))
+	if !source.Opens(root) || source.Opens(filepath.Join(root, "pack.zip")) {
+		t.Error("Opens() should take directories and leave archives to their sources")
+	}
+	wardrobe, err := source.Open(root)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	if data, err := fs.ReadFile(wardrobe, "casual/one.avatar"); err != nil || string(data) != "outfit" {
+		t.Errorf("ReadFile() = %q, %v", data, err)
+	}
+	if info, err := fs.Stat(wardrobe, "casual"); err != nil || !info.IsDir() {
+		t.Errorf("Stat() = %v, %v; want a directory", info, err)
+	}
+	for _, name := range []string{"../casual", "/casual"} {
+		if _, err := wardrobe.Open(name); !stderrors.Is(err, fs.ErrInvalid) {
+			t.Errorf("Open(%q) error = %v, want %v", name, err, fs.ErrInvalid)
+		}
+		if _, err := fs.Stat(wardrobe, name); !stderrors.Is(err, fs.ErrInvalid) {
+			t.Errorf("Stat(%q) error = %v, want %v", name, err, fs.ErrInvalid)
+		}
+		if _, err := fs.ReadDir(wardrobe, name); !stderrors.Is(err, fs.ErrInvalid) {
+			t.Errorf("ReadDir(%q) error = %v, want %v", name, err, fs.ErrInvalid)
+		}
+	}
+	if _, err := fs.ReadDir(wardrobe, "missing"); err == nil || !strings.Contains(err.Error(), filepath.Join(root, "missing")) {
+		t.Errorf("ReadDir() of a missing directory error = %v, want its filesystem path", err)
+	}
+}
+
+func TestDirectorySource_MarksSymlinks(t *testing.T) {
+	root := t.TempDir()
+	target := t.TempDir()
+	if err := os.Symlink(target, filepath.Join(root, "shared")); err != nil {
+		t.Skipf("os.Symlink() unavailable: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(target, "missing"), filepath.Join(root, "broken")); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	setModTime(t, root, modTime)
+	store := newTestIndexStore(t)
+	source := NewDirectorySource(store)
+	if _, err := readDir(source, root); err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if err := source.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// The indexed listing marks the links just as a fresh read does.
+	entries, err := readDir(NewDirectorySource(store), root)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("ReadDir() = %v, %v; want both links", entries, err)
+	}
+	for _, entry := range entries {
+		if entry.Type() != fs.ModeSymlink || entry.IsDir() {
+			t.Errorf("entry %s type = %v, want a symlink", entry.Name(), entry.Type())
+		}
+		if info, err := entry.Info(); err != nil || info.Mode()&fs.ModeSymlink == 0 {
+			t.Errorf("entry %s info = %v, %v; want the link itself", entry.Name(), info, err)
+		}
+	}
+}
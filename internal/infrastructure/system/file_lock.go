@@ -0,0 +1,162 @@
+package system
+
+import (
+	stderrors "errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+var fileLocks sync.Map
+
+const (
+	fileLockPollInterval = 10 * time.Millisecond
+	staleFileLockAfter   = 30 * time.Second
+
+	// advisoryLockSuffix names the file that carries OS advisory locks. It is
+	// never removed, so there is no window in which two processes lock
+	// different inodes for the same path.
+	advisoryLockSuffix = ".flock"
+	// lockFileSuffix names the O_EXCL lock file used where advisory locks are
+	// unavailable.
+	lockFileSuffix = ".lock"
+)
+
+// errAdvisoryLocksUnsupported is returned by acquireAdvisoryLock when the
+// platform or filesystem cannot provide advisory locks for a path.
+var errAdvisoryLocksUnsupported = stderrors.New("advisory file locks are not supported")
+
+// LockPath runs fn while holding the exclusive lock for path, waiting at most
+// timeout for it.
+func LockPath(path string, timeout time.Duration, fn func() error) error {
+	return withPathLock(path, timeout, fn)
+}
+
+// withPathLock runs fn while holding the exclusive in-process and
+// cross-process lock for path.
+func withPathLock(path string, timeout time.Duration, fn func() error) error {
+	lock := lockForPath(path)
+	lock.Lock()
+	defer lock.Unlock()
+
+	release, err := acquireFileLock(path, false, timeout)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return fn()
+}
+
+// withSharedPathLock runs fn while holding a shared lock for path. Shared
+// holders exclude writers but not each other.
+func withSharedPathLock(path string, timeout time.Duration, fn func() error) error {
+	lock := lockForPath(path)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	release, err := acquireFileLock(path, true, timeout)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return fn()
+}
+
+func lockForPath(path string) *sync.RWMutex {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		absolute = filepath.Clean(path)
+	}
+	value, _ := fileLocks.LoadOrStore(absolute, &sync.RWMutex{})
+	return value.(*sync.RWMutex)
+}
+
+// acquireFileLock takes an advisory lock on path where the OS supports it and
+// otherwise falls back to an O_EXCL lock file. The fallback has no shared
+// mode, so shared requests proceed unlocked and rely on atomic renames.
+func acquireFileLock(path string, shared bool, timeout time.Duration) (func(), error) {
+	release, err := acquireAdvisoryLock(path, shared, timeout)
+	if !stderrors.Is(err, errAdvisoryLocksUnsupported) {
+		return release, err
+	}
+	if shared {
+		return func() {}, nil
+	}
+	return acquireLockFile(path, timeout)
+}
+
+func acquireLockFile(path string, timeout time.Duration) (func(), error) {
+	lockPath := path + lockFileSuffix
+	deadline := lockDeadline(timeout)
+
+	for {
+		lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_, _ = fmt.Fprintf(lockFile, "%d\n", os.Getpid())
+			return func() {
+				_ = lockFile.Close()
+				_ = os.Remove(lockPath)
+			}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		stale, staleErr := lockIsStale(lockPath)
+		if staleErr != nil && !os.IsNotExist(staleErr) {
+			return nil, staleErr
+		}
+		if stale {
+			_ = os.Remove(lockPath)
+			continue
+		}
+		if lockExpired(deadline) {
+			return nil, newLockTimeoutError(lockPath)
+		}
+		time.Sleep(fileLockPollInterval)
+	}
+}
+
+func lockIsStale(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	if time.Since(info.ModTime()) <= staleFileLockAfter {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return true, nil
+	}
+	return !processExists(pid), nil
+}
+
+// lockDeadline returns the zero time, meaning wait indefinitely, when timeout
+// is not positive.
+func lockDeadline(timeout time.Duration) time.Time {
+	if timeout <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(timeout)
+}
+
+func lockExpired(deadline time.Time) bool {
+	return !deadline.IsZero() && time.Now().After(deadline)
+}
+
+func newLockTimeoutError(lockPath string) error {
+	return fmt.Errorf("%w waiting for file lock %q", errors.ErrTimedOut, lockPath)
+}
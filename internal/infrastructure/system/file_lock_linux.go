@@ -0,0 +1,78 @@
+//go:build linux
+
+package system
+
+import (
+	stderrors "errors"
+	"os"
+	"syscall"
+	"time"
+)
+
+// acquireAdvisoryLock takes a flock(2) lock on the companion lock file for
+// path. The kernel drops the lock when the holder exits, so no stale-lock
+// detection is needed. A non-positive timeout blocks until the lock is free.
+func acquireAdvisoryLock(path string, shared bool, timeout time.Duration) (func(), error) {
+	lockPath := path + advisoryLockSuffix
+	lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		if shared {
+			// Readers of a directory they cannot write to still get the
+			// atomic-rename guarantee.
+			return nil, errAdvisoryLocksUnsupported
+		}
+		return nil, err
+	}
+
+	how := syscall.LOCK_EX
+	if shared {
+		how = syscall.LOCK_SH
+	}
+	fd := int(lockFile.Fd())
+	if err := flock(fd, how, timeout); err != nil {
+		_ = lockFile.Close()
+		if err == errLockWaitExpired {
+			return nil, newLockTimeoutError(lockPath)
+		}
+		if advisoryLocksUnsupported(err) {
+			return nil, errAdvisoryLocksUnsupported
+		}
+		return nil, &os.PathError{Op: "flock", Path: lockPath, Err: err}
+	}
+
+	return func() {
+		_ = syscall.Flock(fd, syscall.LOCK_UN)
+		_ = lockFile.Close()
+	}, nil
+}
+
+var errLockWaitExpired = stderrors.New("lock wait expired")
+
+func flock(fd, how int, timeout time.Duration) error {
+	if timeout <= 0 {
+		for {
+			err := syscall.Flock(fd, how)
+			if err != syscall.EINTR {
+				return err
+			}
+		}
+	}
+
+	deadline := lockDeadline(timeout)
+	for {
+		err := syscall.Flock(fd, how|syscall.LOCK_NB)
+		if err != syscall.EWOULDBLOCK && err != syscall.EINTR {
+			return err
+		}
+		if lockExpired(deadline) {
+			return errLockWaitExpired
+		}
+		time.Sleep(fileLockPollInterval)
+	}
+}
+
+// advisoryLocksUnsupported reports whether err means the filesystem cannot
+// hold flock locks, as with some network mounts.
+func advisoryLocksUnsupported(err error) bool {
+	return err == syscall.ENOLCK || err == syscall.EOPNOTSUPP || err == syscall.ENOSYS
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package system
+
+import "time"
+
+func acquireAdvisoryLock(string, bool, time.Duration) (func(), error) {
+	return nil, errAdvisoryLocksUnsupported
+}
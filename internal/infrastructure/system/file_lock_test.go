@@ -0,0 +1,196 @@
+package system
+
+import (
+	"bufio"
+	stderrors "errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+const lockHelperPathEnv = "OUTFITPICKER_TEST_LOCK_PATH"
+
+// TestFileLockHelperProcess is not a real test; it holds an exclusive lock on
+// the path in lockHelperPathEnv until it is killed.
+func TestFileLockHelperProcess(t *testing.T) {
+	path := os.Getenv(lockHelperPathEnv)
+	if path == "" {
+		return
+	}
+	if _, err := acquireFileLock(path, false, 0); err != nil {
+		os.Exit(2)
+	}
+	os.Stdout.WriteString("locked\n")
+	time.Sleep(time.Minute)
+	os.Exit(0)
+}
+
+func skipWithoutAdvisoryLocks(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS != "linux" {
+		t.Skip("advisory file locks are only used on Linux")
+	}
+}
+
+func TestAcquireFileLock_SharedAndExclusive(t *testing.T) {
+	skipWithoutAdvisoryLocks(t)
+	path := filepath.Join(t.TempDir(), "test.json")
+	const wait = 50 * time.Millisecond
+
+	firstReader, err := acquireFileLock(path, true, wait)
+	if err != nil {
+		t.Fatalf("first shared lock error = %v", err)
+	}
+	secondReader, err := acquireFileLock(path, true, wait)
+	if err != nil {
+		t.Fatalf("second shared lock error = %v, want readers not to block each other", err)
+	}
+	if _, err := acquireFileLock(path, false, wait); !stderrors.Is(err, errors.ErrTimedOut) {
+		t.Fatalf("exclusive lock under readers error = %v, want %v", err, errors.ErrTimedOut)
+	}
+	firstReader()
+	secondReader()
+
+	writer, err := acquireFileLock(path, false, wait)
+	if err != nil {
+		t.Fatalf("exclusive lock error = %v", err)
+	}
+	if _, err := acquireFileLock(path, true, wait); !stderrors.Is(err, errors.ErrTimedOut) {
+		t.Fatalf("shared lock under writer error = %v, want %v", err, errors.ErrTimedOut)
+	}
+	writer()
+}
+
+func TestAcquireFileLock_WaitsWithoutTimeout(t *testing.T) {
+	skipWithoutAdvisoryLocks(t)
+	path := filepath.Join(t.TempDir(), "test.json")
+	release, err := acquireFileLock(path, false, 0)
+	if err != nil {
+		t.Fatalf("acquireFileLock() error = %v", err)
+	}
+
+	acquired := make(chan error, 1)
+	go func() {
+		waiterRelease, err := acquireFileLock(path, false, 0)
+		if err == nil {
+			waiterRelease()
+		}
+		acquired <- err
+	}()
+
+	select {
+	case err := <-acquired:
+		t.Fatalf("waiter acquired the lock while it was held, error = %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	release()
+	if err := <-acquired; err != nil {
+		t.Fatalf("waiter error = %v", err)
+	}
+}
+
+func TestAcquireFileLock_ReleasedWhenHolderExits(t *testing.T) {
+	skipWithoutAdvisoryLocks(t)
+	path := filepath.Join(t.TempDir(), "test.json")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFileLockHelperProcess$")
+	cmd.Env = append(os.Environ(), lockHelperPathEnv+"="+path)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("StdoutPipe() error = %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = cmd.Process.Kill() })
+	if line, err := bufio.NewReader(stdout).ReadString('\n'); err != nil || line != "locked\n" {
+		t.Fatalf("helper output = %q, %v", line, err)
+	}
+
+	if _, err := acquireFileLock(path, false, 50*time.Millisecond); !stderrors.Is(err, errors.ErrTimedOut) {
+		t.Fatalf("lock held by helper error = %v, want %v", err, errors.ErrTimedOut)
+	}
+
+	_ = cmd.Process.Kill()
+	_ = cmd.Wait()
+	release, err := acquireFileLock(path, false, time.Second)
+	if err != nil {
+		t.Fatalf("lock after holder exit error = %v", err)
+	}
+	release()
+	if _, err := os.Stat(path + advisoryLockSuffix); err != nil {
+		t.Fatalf("advisory lock file should persist, stat error = %v", err)
+	}
+}
+
+func TestAcquireFileLock_MissingDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "test.json")
+
+	release, err := acquireFileLock(path, true, time.Second)
+	if err != nil {
+		t.Fatalf("shared lock error = %v, want unlocked read", err)
+	}
+	release()
+	if _, err := acquireFileLock(path, false, time.Second); !os.IsNotExist(err) {
+		t.Fatalf("exclusive lock error = %v, want not-exist", err)
+	}
+}
+
+func TestDefaultDataManager_ReadTimesOutWhileWriterHoldsLock(t *testing.T) {
+	skipWithoutAdvisoryLocks(t)
+	path := filepath.Join(t.TempDir(), "test.json")
+	if err := os.WriteFile(path, []byte("{}"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	release, err := acquireFileLock(path, false, 0)
+	if err != nil {
+		t.Fatalf("acquireFileLock() error = %v", err)
+	}
+	defer release()
+
+	manager := NewDefaultDataManager(50 * time.Millisecond)
+	if _, err := manager.Read(path); !stderrors.Is(err, errors.ErrTimedOut) {
+		t.Fatalf("Read() error = %v, want %v", err, errors.ErrTimedOut)
+	}
+	if err := manager.Write(path, []byte("[]")); !stderrors.Is(err, errors.ErrTimedOut) {
+		t.Fatalf("Write() error = %v, want %v", err, errors.ErrTimedOut)
+	}
+}
+
+func TestAcquireLockFile_RecoversStaleLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.json")
+	lockPath := path + lockFileSuffix
+	if err := os.WriteFile(lockPath, []byte("stale"), 0o600); err != nil {
+		t.Fatalf("WriteFile(lock) error = %v", err)
+	}
+	staleTime := time.Now().Add(-staleFileLockAfter - time.Second)
+	if err := os.Chtimes(lockPath, staleTime, staleTime); err != nil {
+		t.Fatalf("Chtimes() error = %v", err)
+	}
+
+	release, err := acquireLockFile(path, time.Second)
+	if err != nil {
+		t.Fatalf("acquireLockFile() error = %v", err)
+	}
+	release()
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Fatalf("lock file was not removed, stat error = %v", err)
+	}
+}
+
+func TestAcquireLockFile_TimesOutOnLiveLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.json")
+	release, err := acquireLockFile(path, 0)
+	if err != nil {
+		t.Fatalf("acquireLockFile() error = %v", err)
+	}
+	defer release()
+
+	if _, err := acquireLockFile(path, 30*time.Millisecond); !stderrors.Is(err, errors.ErrTimedOut) {
+		t.Fatalf("acquireLockFile() error = %v, want %v", err, errors.ErrTimedOut)
+	}
+}
@@ -2,6 +2,7 @@ package system
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 )
@@ -11,23 +12,51 @@ const appName = "outfitpicker"
 type DataManager interface {
 	Read(path string) ([]byte, error)
 	Write(path string, data []byte) error
+	Update(path string, change func(current []byte) ([]byte, error)) error
 }
 
 type DirectoryProvider interface {
 	BaseDirectory() (string, error)
 }
 
+// AppDirectoryProvider is implemented by directory providers that choose the
+// outfitpicker directory itself instead of the directory it is created in.
+type AppDirectoryProvider interface {
+	AppDirectory() (string, error)
+}
+
+// appDirectory returns the directory holding the default profile's files.
+func appDirectory(provider DirectoryProvider) (string, error) {
+	if appProvider, ok := provider.(AppDirectoryProvider); ok {
+		return appProvider.AppDirectory()
+	}
+	baseDir, err := provider.BaseDirectory()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(baseDir, appName), nil
+}
+
 type FileManager interface {
 	Exists(path string) bool
 	Remove(path string) error
 	MkdirAll(path string) error
 }
 
+// Upgrader rewrites a stored document written with an older schema into the
+// current one before it is decoded. Upgrade returns nil data when the document
+// is already current, and otherwise the schema version it was upgraded from.
+type Upgrader interface {
+	Upgrade(data []byte) (upgraded []byte, fromVersion int, err error)
+}
+
 type FileService[T any] struct {
 	fileName          string
+	profile           string
 	dataManager       DataManager
 	directoryProvider DirectoryProvider
 	fileManager       FileManager
+	upgrader          Upgrader
 }
 
 type FileServiceOption[T any] func(*FileService[T])
@@ -50,6 +79,22 @@ func WithFileManager[T any](fm FileManager) FileServiceOption[T] {
 	}
 }
 
+// WithUpgrader upgrades documents written with older schemas on load. The
+// original file is kept next to it as <name>.v<version>.bak.
+func WithUpgrader[T any](u Upgrader) FileServiceOption[T] {
+	return func(fs *FileService[T]) {
+		fs.upgrader = u
+	}
+}
+
+// WithProfile keeps the file in the named profile's directory instead of the
+// default profile's.
+func WithProfile[T any](name string) FileServiceOption[T] {
+	return func(fs *FileService[T]) {
+		fs.profile = name
+	}
+}
+
 func NewFileService[T any](fileName string, opts ...FileServiceOption[T]) *FileService[T] {
 	fs := &FileService[T]{
 		fileName:          fileName,
@@ -66,11 +111,11 @@ func NewFileService[T any](fileName string, opts ...FileServiceOption[T]) *FileS
 }
 
 func (fs *FileService[T]) FilePath() (string, error) {
-	baseDir, err := fs.directoryProvider.BaseDirectory()
+	appDir, err := appDirectory(fs.directoryProvider)
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(baseDir, appName, fs.fileName), nil
+	return filepath.Join(profileDirectory(appDir, fs.profile), fs.fileName), nil
 }
 
 func (fs *FileService[T]) Load() (*T, error) {
@@ -91,12 +136,7 @@ func (fs *FileService[T]) Load() (*T, error) {
 		return nil, err
 	}
 
-	var result T
-	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, err
-	}
-
-	return &result, nil
+	return fs.decode(path, data)
 }
 
 func (fs *FileService[T]) Save(obj T) error {
@@ -117,6 +157,75 @@ func (fs *FileService[T]) Save(obj T) error {
 	return fs.dataManager.Write(path, data)
 }
 
+// Update loads the stored value, passes it to change, and saves the result
+// while holding the file lock. change receives nil when nothing is stored yet
+// and may return nil to leave the file untouched.
+func (fs *FileService[T]) Update(change func(current *T) (*T, error)) error {
+	path, err := fs.FilePath()
+	if err != nil {
+		return err
+	}
+
+	if err := fs.fileManager.MkdirAll(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	return fs.dataManager.Update(path, func(data []byte) ([]byte, error) {
+		var current *T
+		if data != nil {
+			value, err := fs.decode(path, data)
+			if err != nil {
+				return nil, err
+			}
+			current = value
+		}
+
+		updated, err := change(current)
+		if err != nil || updated == nil {
+			return nil, err
+		}
+		return json.MarshalIndent(updated, "", "  ")
+	})
+}
+
+// decode upgrades data read from path to the current schema, backing up the
+// original first, and unmarshals it.
+func (fs *FileService[T]) decode(path string, data []byte) (*T, error) {
+	if fs.upgrader != nil {
+		upgraded, fromVersion, err := fs.upgrader.Upgrade(data)
+		if err != nil {
+			return nil, err
+		}
+		if upgraded != nil {
+			if err := fs.backUp(path, fromVersion, data); err != nil {
+				return nil, err
+			}
+			data = upgraded
+		}
+	}
+
+	var result T
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// backUp keeps the document as it was before its first upgrade from
+// fromVersion. An existing backup is left alone so that repeated loads before
+// the next save do not replace it.
+func (fs *FileService[T]) backUp(path string, fromVersion int, data []byte) error {
+	backupPath := fmt.Sprintf("%s.v%d.bak", path, fromVersion)
+	if fs.fileManager.Exists(backupPath) {
+		return nil
+	}
+	if err := fs.dataManager.Write(backupPath, data); err != nil {
+		return fmt.Errorf("back up %s before upgrading it: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
 func (fs *FileService[T]) Delete() error {
 	path, err := fs.FilePath()
 	if err != nil {
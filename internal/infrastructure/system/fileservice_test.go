@@ -31,6 +31,18 @@ func (m *mockDataManager) Write(path string, data []byte) error {
 	return m.writeFunc(path, data)
 }
 
+func (m *mockDataManager) Update(path string, change func([]byte) ([]byte, error)) error {
+	current, err := m.readFunc(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	data, err := change(current)
+	if err != nil || data == nil {
+		return err
+	}
+	return m.writeFunc(path, data)
+}
+
 type mockDirectoryProvider struct {
 	baseDirFunc func() (string, error)
 }
@@ -98,6 +110,7 @@ func TestFileService_FilePath(t *testing.T) {
 		name    string
 		baseDir string
 		baseErr error
+		profile string
 		want    string
 		wantErr bool
 	}{
@@ -106,6 +119,18 @@ func TestFileService_FilePath(t *testing.T) {
 			baseDir: "/Users/user/.config",
 			want:    filepath.Join("/Users/user/.config", "outfitpicker", "test.json"),
 		},
+		{
+			name:    "default profile",
+			baseDir: "/Users/user/.config",
+			profile: "default",
+			want:    filepath.Join("/Users/user/.config", "outfitpicker", "test.json"),
+		},
+		{
+			name:    "named profile",
+			baseDir: "/Users/user/.config",
+			profile: "alex",
+			want:    filepath.Join("/Users/user/.config", "outfitpicker", "profiles", "alex", "test.json"),
+		},
 		{
 			name:    "directory provider error",
 			baseErr: errors.New("no directory"),
@@ -116,7 +141,8 @@ func TestFileService_FilePath(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			fs := NewFileService[testConfig]("test.json",
-				WithDirectoryProvider[testConfig](newMockDirProvider(tt.baseDir, tt.baseErr)))
+				WithDirectoryProvider[testConfig](newMockDirProvider(tt.baseDir, tt.baseErr)),
+				WithProfile[testConfig](tt.profile))
 
 			got, err := fs.FilePath()
 			if (err != nil) != tt.wantErr {
@@ -330,38 +356,130 @@ func TestFileService_Save_DefaultDataManagerSerializesConcurrentWrites(t *testin
 	}
 }
 
-func TestFileService_Save_DefaultDataManagerRecoversStaleLock(t *testing.T) {
+func TestFileService_Update(t *testing.T) {
+	t.Run("passes nil when nothing is stored", func(t *testing.T) {
+		var written []byte
+		dm := newMockDataManager("", os.ErrNotExist, nil)
+		dm.writeFunc = func(_ string, data []byte) error {
+			written = data
+			return nil
+		}
+		fs := NewFileService[testConfig]("test.json",
+			WithDataManager[testConfig](dm),
+			WithDirectoryProvider[testConfig](newMockDirProvider("/tmp", nil)),
+			WithFileManager[testConfig](newMockFileManager(false, nil, nil)))
+
+		err := fs.Update(func(current *testConfig) (*testConfig, error) {
+			if current != nil {
+				t.Fatalf("current = %+v, want nil", current)
+			}
+			return &testConfig{Name: "created", Value: 1}, nil
+		})
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		var saved testConfig
+		if err := json.Unmarshal(written, &saved); err != nil || saved.Name != "created" {
+			t.Fatalf("written = %s, %v", written, err)
+		}
+	})
+
+	t.Run("nil result leaves file untouched", func(t *testing.T) {
+		dm := newMockDataManager(`{"name":"kept","value":3}`, nil, nil)
+		dm.writeFunc = func(string, []byte) error {
+			t.Fatal("Write called for a nil update")
+			return nil
+		}
+		fs := NewFileService[testConfig]("test.json",
+			WithDataManager[testConfig](dm),
+			WithDirectoryProvider[testConfig](newMockDirProvider("/tmp", nil)),
+			WithFileManager[testConfig](newMockFileManager(true, nil, nil)))
+
+		err := fs.Update(func(current *testConfig) (*testConfig, error) {
+			if current == nil || current.Name != "kept" {
+				t.Fatalf("current = %+v, want stored value", current)
+			}
+			return nil, nil
+		})
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		changeErr := errors.New("change failed")
+		tests := []struct {
+			name    string
+			dirErr  error
+			mkdir   error
+			read    string
+			readErr error
+			change  error
+		}{
+			{name: "directory", dirErr: errors.New("no home")},
+			{name: "mkdir", mkdir: errors.New("read-only")},
+			{name: "read", readErr: errors.New("io error")},
+			{name: "decode", read: "not json"},
+			{name: "change", read: `{"name":"x"}`, change: changeErr},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				fs := NewFileService[testConfig]("test.json",
+					WithDataManager[testConfig](newMockDataManager(tt.read, tt.readErr, nil)),
+					WithDirectoryProvider[testConfig](newMockDirProvider("/tmp", tt.dirErr)),
+					WithFileManager[testConfig](newMockFileManager(true, nil, tt.mkdir)))
+
+				err := fs.Update(func(current *testConfig) (*testConfig, error) {
+					return current, tt.change
+				})
+				if err == nil {
+					t.Fatal("Update() error = nil, want error")
+				}
+				if tt.change != nil && !errors.Is(err, changeErr) {
+					t.Fatalf("Update() error = %v, want %v", err, changeErr)
+				}
+			})
+		}
+	})
+}
+
+func TestFileService_Update_DefaultDataManagerKeepsConcurrentChanges(t *testing.T) {
 	baseDir := t.TempDir()
-	fs := NewFileService[testConfig]("test.json",
-		WithDirectoryProvider[testConfig](newMockDirProvider(baseDir, nil)))
-	path, err := fs.FilePath()
-	if err != nil {
-		t.Fatalf("FilePath() error = %v", err)
+	newService := func() *FileService[testConfig] {
+		return NewFileService[testConfig]("test.json",
+			WithDirectoryProvider[testConfig](newMockDirProvider(baseDir, nil)))
 	}
-	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
-		t.Fatalf("MkdirAll() error = %v", err)
-	}
-	lockPath := path + ".lock"
-	if err := os.WriteFile(lockPath, []byte("stale"), 0o600); err != nil {
-		t.Fatalf("WriteFile(lock) error = %v", err)
+
+	const workers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- newService().Update(func(current *testConfig) (*testConfig, error) {
+				if current == nil {
+					current = &testConfig{Name: "counter"}
+				}
+				current.Value++
+				return current, nil
+			})
+		}()
 	}
-	staleTime := time.Now().Add(-staleFileLockAfter - time.Second)
-	if err := os.Chtimes(lockPath, staleTime, staleTime); err != nil {
-		t.Fatalf("Chtimes() error = %v", err)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Update() concurrent error = %v", err)
+		}
 	}
 
-	if err := fs.Save(testConfig{Name: "test", Value: 7}); err != nil {
-		t.Fatalf("Save() error = %v", err)
-	}
-	loaded, err := fs.Load()
+	loaded, err := newService().Load()
 	if err != nil {
 		t.Fatalf("Load() error = %v", err)
 	}
-	if loaded == nil || loaded.Value != 7 {
-		t.Fatalf("Load() = %+v, want value 7", loaded)
-	}
-	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
-		t.Fatalf("lock file was not removed, stat error = %v", err)
+	if loaded == nil || loaded.Value != workers {
+		t.Fatalf("Load() = %+v, want value %d", loaded, workers)
 	}
 }
 
@@ -537,3 +655,124 @@ func TestFileService_Save_WriteError(t *testing.T) {
 		t.Error("Save() expected write error, got nil")
 	}
 }
+
+type stubUpgrader struct {
+	upgraded    string
+	fromVersion int
+	err         error
+}
+
+func (u stubUpgrader) Upgrade([]byte) ([]byte, int, error) {
+	if u.err != nil || u.upgraded == "" {
+		return nil, u.fromVersion, u.err
+	}
+	return []byte(u.upgraded), u.fromVersion, nil
+}
+
+func TestFileService_Upgrader(t *testing.T) {
+	newService := func(baseDir string, upgrader Upgrader) *FileService[testConfig] {
+		return NewFileService[testConfig]("test.json",
+			WithDirectoryProvider[testConfig](newMockDirProvider(baseDir, nil)),
+			WithUpgrader[testConfig](upgrader))
+	}
+	writeStored := func(t *testing.T, fs *FileService[testConfig], data string) string {
+		t.Helper()
+		path, err := fs.FilePath()
+		if err != nil {
+			t.Fatalf("FilePath() error = %v", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("load decodes upgraded data and keeps the original", func(t *testing.T) {
+		fs := newService(t.TempDir(), stubUpgrader{upgraded: `{"name":"new","value":2}`, fromVersion: 1})
+		path := writeStored(t, fs, `{"name":"old"}`)
+
+		loaded, err := fs.Load()
+		if err != nil || loaded == nil || loaded.Name != "new" {
+			t.Fatalf("Load() = %+v, %v; want upgraded value", loaded, err)
+		}
+		backup, err := os.ReadFile(path + ".v1.bak")
+		if err != nil || string(backup) != `{"name":"old"}` {
+			t.Fatalf("backup = %q, %v; want original document", backup, err)
+		}
+		stored, _ := os.ReadFile(path)
+		if string(stored) != `{"name":"old"}` {
+			t.Fatalf("stored = %q, want it untouched until the next save", stored)
+		}
+
+		writeStored(t, fs, `{"name":"edited"}`)
+		if _, err := fs.Load(); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if backup, _ := os.ReadFile(path + ".v1.bak"); string(backup) != `{"name":"old"}` {
+			t.Fatalf("backup = %q, want the first original kept", backup)
+		}
+	})
+
+	t.Run("update writes the upgraded document", func(t *testing.T) {
+		fs := newService(t.TempDir(), stubUpgrader{upgraded: `{"name":"new","value":2}`})
+		path := writeStored(t, fs, `{"name":"old"}`)
+
+		err := fs.Update(func(current *testConfig) (*testConfig, error) {
+			current.Value++
+			return current, nil
+		})
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		var stored testConfig
+		data, _ := os.ReadFile(path)
+		if err := json.Unmarshal(data, &stored); err != nil || stored != (testConfig{Name: "new", Value: 3}) {
+			t.Fatalf("stored = %s, %v", data, err)
+		}
+		if _, err := os.Stat(path + ".v0.bak"); err != nil {
+			t.Fatalf("backup missing: %v", err)
+		}
+	})
+
+	t.Run("current data is decoded as stored", func(t *testing.T) {
+		baseDir := t.TempDir()
+		fs := newService(baseDir, stubUpgrader{fromVersion: 2})
+		path := writeStored(t, fs, `{"name":"current"}`)
+
+		loaded, err := fs.Load()
+		if err != nil || loaded == nil || loaded.Name != "current" {
+			t.Fatalf("Load() = %+v, %v", loaded, err)
+		}
+		if _, err := os.Stat(path + ".v2.bak"); !os.IsNotExist(err) {
+			t.Fatalf("backup stat error = %v, want none written", err)
+		}
+	})
+
+	t.Run("upgrade error fails the load", func(t *testing.T) {
+		upgradeErr := errors.New("written by a newer version")
+		fs := newService(t.TempDir(), stubUpgrader{err: upgradeErr})
+		writeStored(t, fs, `{"name":"future"}`)
+
+		if _, err := fs.Load(); !errors.Is(err, upgradeErr) {
+			t.Fatalf("Load() error = %v, want %v", err, upgradeErr)
+		}
+	})
+
+	t.Run("backup failure fails the load", func(t *testing.T) {
+		writeErr := errors.New("read-only")
+		fs := NewFileService[testConfig]("test.json",
+			WithDataManager[testConfig](newMockDataManager(`{"name":"old"}`, nil, writeErr)),
+			WithDirectoryProvider[testConfig](newMockDirProvider("/tmp", nil)),
+			WithFileManager[testConfig](&mockFileManager{
+				existsFunc: func(path string) bool { return !strings.HasSuffix(path, ".bak") },
+			}),
+			WithUpgrader[testConfig](stubUpgrader{upgraded: `{"name":"new"}`}))
+
+		if _, err := fs.Load(); !errors.Is(err, writeErr) {
+			t.Fatalf("Load() error = %v, want %v", err, writeErr)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package system
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+)
+
+// hookOutputLimit caps how much hook stderr is quoted in a failure message.
+const hookOutputLimit = 512
+
+// hookStderrTail caps how much hook stderr is kept to find the last line in,
+// so a hook writing without end cannot exhaust memory.
+const hookStderrTail = 8 * hookOutputLimit
+
+// hookWaitDelay bounds how long a stopped hook may keep its pipes open.
+const hookWaitDelay = time.Second
+
+// HookRunner runs hook executables with the event as JSON on stdin and the
+// outfit details in OUTFITPICKER_* environment variables.
+type HookRunner struct {
+	output io.Writer
+}
+
+// NewHookRunner creates a hook runner that forwards hook output to output.
+func NewHookRunner(output io.Writer) *HookRunner {
+	if output == nil {
+		output = io.Discard
+	}
+	return &HookRunner{output: output}
+}
+
+// Run executes hook for event and waits for it to exit or time out.
+func (r *HookRunner) Run(hook entities.Hook, event entities.HookEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.NewHookFailedError(string(event.Event), hook.Command, "", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hook.Timeout())
+	defer cancel()
+
+	stderr := tailBuffer{limit: hookStderrTail}
+	cmd := exec.CommandContext(ctx, hook.Command, hook.Args...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = r.output
+	cmd.Stderr = io.MultiWriter(r.output, &stderr)
+	cmd.Env = append(os.Environ(), hookEnvironment(event)...)
+	cmd.WaitDelay = hookWaitDelay
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		err = errors.ErrTimedOut
+	}
+	if err != nil {
+		return errors.NewHookFailedError(string(event.Event), hook.Command, lastOutputLine(string(stderr.data)), err)
+	}
+	return nil
+}
+
+func hookEnvironment(event entities.HookEvent) []string {
+	return []string{
+		"OUTFITPICKER_EVENT=" + string(event.Event),
+		"OUTFITPICKER_ROOT=" + event.Root,
+		"OUTFITPICKER_CATEGORY=" + event.Category,
+		"OUTFITPICKER_OUTFIT=" + event.Outfit,
+		"OUTFITPICKER_OUTFIT_PATH=" + event.OutfitPath,
+	}
+}
+
+func lastOutputLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	line := strings.TrimSpace(lines[len(lines)-1])
+	if len(line) > hookOutputLimit {
+		line = line[:hookOutputLimit] + "..."
+	}
+	return line
+}
+
+// tailBuffer keeps the last limit bytes written to it and discards the rest.
+type tailBuffer struct {
+	data  []byte
+	limit int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	written := len(p)
+	if len(p) >= b.limit {
+		b.data = append(b.data[:0], p[len(p)-b.limit:]...)
+		return written, nil
+	}
+	if excess := len(b.data) + len(p) - b.limit; excess > 0 {
+		b.data = b.data[:copy(b.data, b.data[excess:])]
+	}
+	b.data = append(b.data, p...)
+	return written, nil
+}
+
+// Ensure HookRunner implements the interface
+var _ interfaces.HookRunner = (*HookRunner)(nil)
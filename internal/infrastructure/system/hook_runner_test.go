@@ -0,0 +1,173 @@
+package system
+
+import (
+	"bytes"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+const hookHelperModeEnv = "OUTFITPICKER_TEST_HOOK_MODE"
+
+// TestHookRunnerHelperProcess is not a real test; hook and selection plugin
+// tests run the test binary as the executable and select its behavior through
+// the environment.
+func TestHookRunnerHelperProcess(t *testing.T) {
+	mode := os.Getenv(hookHelperModeEnv)
+	if mode == "" {
+		return
+	}
+	switch mode {
+	case "record":
+		stdin, _ := io.ReadAll(os.Stdin)
+		record := fmt.Sprintf("%s\n%s|%s|%s|%s|%s\n", stdin,
+			os.Getenv("OUTFITPICKER_EVENT"), os.Getenv("OUTFITPICKER_ROOT"), os.Getenv("OUTFITPICKER_CATEGORY"),
+			os.Getenv("OUTFITPICKER_OUTFIT"), os.Getenv("OUTFITPICKER_OUTFIT_PATH"))
+		_ = os.WriteFile(os.Getenv("OUTFITPICKER_TEST_HOOK_RECORD"), []byte(record), 0o600)
+		fmt.Println("recorded")
+		os.Exit(0)
+	case "fail":
+		fmt.Fprintln(os.Stderr, "first line")
+		fmt.Fprintln(os.Stderr, "cannot reach wardrobe server")
+		os.Exit(3)
+	case "sleep":
+		time.Sleep(time.Minute)
+		os.Exit(0)
+	case "rank-last":
+		var request entities.SelectionRequest
+		if err := json.NewDecoder(os.Stdin).Decode(&request); err != nil {
+			os.Exit(4)
+		}
+		ranking := []string{"unknown/outfit.avatar"}
+		for index := len(request.Candidates) - 1; index >= 0; index-- {
+			ranking = append(ranking, request.Candidates[index].ID)
+		}
+		_ = json.NewEncoder(os.Stdout).Encode(entities.SelectionResponse{ProtocolVersion: request.ProtocolVersion, Ranking: ranking})
+		os.Exit(0)
+	case "garbage":
+		fmt.Println("not json")
+		os.Exit(0)
+	case "flood":
+		_, _ = os.Stdout.Write(bytes.Repeat([]byte("x"), selectionResponseLimit+1))
+		os.Exit(0)
+	}
+}
+
+func helperHook(t *testing.T, mode string, timeout time.Duration) entities.Hook {
+	t.Helper()
+	t.Setenv(hookHelperModeEnv, mode)
+	hook, err := entities.NewHook(os.Args[0], []string{"-test.run=^TestHookRunnerHelperProcess$"}, timeout)
+	if err != nil {
+		t.Fatalf("NewHook() error = %v", err)
+	}
+	return hook
+}
+
+func TestHookRunner_PassesEventOnStdinAndEnvironment(t *testing.T) {
+	record := filepath.Join(t.TempDir(), "record.txt")
+	t.Setenv("OUTFITPICKER_TEST_HOOK_RECORD", record)
+	hook := helperHook(t, "record", 0)
+	outfit := entities.NewOutfitReference("boots.avatar", entities.NewCategoryReference("shoes", filepath.Join("wardrobe", "shoes")))
+	event := entities.NewHookEvent(entities.HookEventPostWear, "wardrobe", time.Unix(1700000000, 0)).WithOutfit(outfit)
+	var output bytes.Buffer
+
+	if err := NewHookRunner(&output).Run(hook, event); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	data, err := os.ReadFile(record)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	payload, env, _ := strings.Cut(string(data), "\n")
+	var received entities.HookEvent
+	if err := json.Unmarshal([]byte(payload), &received); err != nil {
+		t.Fatalf("hook stdin %q is not JSON: %v", payload, err)
+	}
+	if received != event {
+		t.Fatalf("hook stdin = %#v, want %#v", received, event)
+	}
+	wantEnv := strings.Join([]string{"post-wear", "wardrobe", "shoes", "boots.avatar", outfit.FilePath()}, "|")
+	if strings.TrimSpace(env) != wantEnv {
+		t.Fatalf("hook environment = %q, want %q", env, wantEnv)
+	}
+	if !strings.Contains(output.String(), "recorded") {
+		t.Fatalf("hook output = %q, want forwarded stdout", output.String())
+	}
+}
+
+func TestHookRunner_Failures(t *testing.T) {
+	event := entities.NewHookEvent(entities.HookEventPrePick, "wardrobe", time.Now())
+
+	t.Run("non-zero exit", func(t *testing.T) {
+		err := NewHookRunner(nil).Run(helperHook(t, "fail", 0), event)
+
+		var hookErr *errors.HookFailedError
+		if !stderrors.As(err, &hookErr) {
+			t.Fatalf("Run() error = %v, want HookFailedError", err)
+		}
+		if hookErr.Event != "pre-pick" || hookErr.Output != "cannot reach wardrobe server" {
+			t.Fatalf("HookFailedError = %#v", hookErr)
+		}
+		if !strings.Contains(err.Error(), "exit status 3") {
+			t.Fatalf("Run() error = %v, want exit status", err)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		start := time.Now()
+		err := NewHookRunner(nil).Run(helperHook(t, "sleep", time.Second), event)
+
+		if !stderrors.Is(err, errors.ErrTimedOut) {
+			t.Fatalf("Run() error = %v, want ErrTimedOut", err)
+		}
+		if elapsed := time.Since(start); elapsed > 30*time.Second {
+			t.Fatalf("Run() took %v after timeout", elapsed)
+		}
+	})
+
+	t.Run("missing executable", func(t *testing.T) {
+		hook := entities.Hook{Command: filepath.Join(t.TempDir(), "missing-hook")}
+		err := NewHookRunner(nil).Run(hook, event)
+
+		var hookErr *errors.HookFailedError
+		if !stderrors.As(err, &hookErr) || hookErr.Command != hook.Command {
+			t.Fatalf("Run() error = %v, want HookFailedError for %s", err, hook.Command)
+		}
+	})
+}
+
+func TestLastOutputLine(t *testing.T) {
+	if got := lastOutputLine(""); got != "" {
+		t.Fatalf("lastOutputLine(\"\") = %q", got)
+	}
+	long := strings.Repeat("x", hookOutputLimit+10)
+	if got := lastOutputLine("first\n" + long + "\n"); got != long[:hookOutputLimit]+"..." {
+		t.Fatalf("lastOutputLine() length = %d", len(got))
+	}
+}
+
+func TestTailBuffer_KeepsTheLastBytes(t *testing.T) {
+	buffer := tailBuffer{limit: 8}
+	for _, chunk := range []string{"abc", "defgh", "ij"} {
+		if n, err := buffer.Write([]byte(chunk)); n != len(chunk) || err != nil {
+			t.Fatalf("Write(%q) = %d, %v", chunk, n, err)
+		}
+	}
+	if got := string(buffer.data); got != "cdefghij" {
+		t.Fatalf("tail = %q, want %q", got, "cdefghij")
+	}
+	buffer.Write([]byte("0123456789"))
+	if string(buffer.data) != "23456789" {
+		t.Fatalf("tail after a long write = %q, want %q", buffer.data, "23456789")
+	}
+}
@@ -0,0 +1,189 @@
+package system
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+)
+
+// SlotLocksDirName is the directory in the outfitpicker directory holding the
+// lock files for activation slot targets.
+const SlotLocksDirName = "slot-locks"
+
+// OutfitInstaller installs outfit files into activation slots. Every change to
+// a slot target is an atomic rename, and the file it replaces is kept at the
+// slot's previous path so it can be restored.
+type OutfitInstaller struct {
+	directoryProvider DirectoryProvider
+	lockTimeout       time.Duration
+}
+
+// NewOutfitInstaller creates a new outfit installer that keeps its slot locks
+// in directoryProvider's outfitpicker directory. lockTimeout bounds the wait
+// for another process changing the same slot; zero waits indefinitely.
+func NewOutfitInstaller(directoryProvider DirectoryProvider, lockTimeout time.Duration) *OutfitInstaller {
+	return &OutfitInstaller{directoryProvider: directoryProvider, lockTimeout: lockTimeout}
+}
+
+// Install copies or links sourcePath into the slot target. The new file is
+// staged beside the target first, and the file it replaces only becomes the
+// previous file once it is in place, so a failed install leaves both alone.
+func (i *OutfitInstaller) Install(sourcePath string, slot entities.ActivationSlot) error {
+	return i.withSlotLock(slot, func() error {
+		staged, err := stageOutfit(sourcePath, slot)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = os.Remove(staged) }()
+
+		replaced := uniqueSiblingPath(slot.Target, "previous")
+		hadCurrent, err := preserveFile(slot.Target, replaced)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = os.Remove(replaced) }()
+
+		if err := os.Rename(staged, slot.Target); err != nil {
+			return err
+		}
+		if hadCurrent {
+			if err := os.Rename(replaced, slot.PreviousPath()); err != nil {
+				return err
+			}
+		}
+		return syncDirectory(filepath.Dir(slot.Target))
+	})
+}
+
+// withSlotLock runs fn while holding the lock for the slot target. The lock
+// file is named after the target path and kept in outfitpicker's own
+// directory, so nothing is left beside the target in the directory of the
+// application that reads it.
+func (i *OutfitInstaller) withSlotLock(slot entities.ActivationSlot, fn func() error) error {
+	appDir, err := appDirectory(i.directoryProvider)
+	if err != nil {
+		return err
+	}
+	target, err := filepath.Abs(slot.Target)
+	if err != nil {
+		return err
+	}
+	lockDir := filepath.Join(appDir, SlotLocksDirName)
+	if err := os.MkdirAll(lockDir, 0700); err != nil {
+		return err
+	}
+	sum := sha256.Sum256([]byte(target))
+	return withPathLock(filepath.Join(lockDir, hex.EncodeToString(sum[:16])), i.lockTimeout, fn)
+}
+
+// stageOutfit copies or links sourcePath to a new path beside the slot target
+// and returns that path.
+func stageOutfit(sourcePath string, slot entities.ActivationSlot) (string, error) {
+	staged := uniqueSiblingPath(slot.Target, "install")
+	if slot.Mode == entities.ActivationModeSymlink {
+		source, err := filepath.Abs(sourcePath)
+		if err != nil {
+			return "", err
+		}
+		if err := os.Symlink(source, staged); err != nil {
+			return "", err
+		}
+		return staged, nil
+	}
+	if err := copyFileAtomically(sourcePath, staged); err != nil {
+		return "", err
+	}
+	return staged, nil
+}
+
+// Restore swaps the slot target with the previously active file, so restoring
+// twice returns to the most recent activation.
+func (i *OutfitInstaller) Restore(slot entities.ActivationSlot) error {
+	return i.withSlotLock(slot, func() error {
+		previous := slot.PreviousPath()
+		if _, err := os.Lstat(previous); err != nil {
+			if os.IsNotExist(err) {
+				return errors.ErrNoPreviousActivation
+			}
+			return err
+		}
+
+		swap := uniqueSiblingPath(slot.Target, "restore")
+		hadCurrent, err := preserveFile(slot.Target, swap)
+		if err != nil {
+			return err
+		}
+		if err := os.Rename(previous, slot.Target); err != nil {
+			_ = os.Remove(swap)
+			return err
+		}
+		if hadCurrent {
+			if err := os.Rename(swap, previous); err != nil {
+				return err
+			}
+		}
+		return syncDirectory(filepath.Dir(slot.Target))
+	})
+}
+
+// preserveFile atomically replaces destination with a copy of source, or with
+// the same link when source is a symlink. It reports whether source existed.
+func preserveFile(source, destination string) (bool, error) {
+	info, err := os.Lstat(source)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if info.Mode()&os.ModeSymlink != 0 {
+		link, err := os.Readlink(source)
+		if err != nil {
+			return false, err
+		}
+		return true, replaceWithSymlink(destination, link)
+	}
+	return true, copyFileAtomically(source, destination)
+}
+
+func copyFileAtomically(source, destination string) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", source)
+	}
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomically(destination, data, info.Mode().Perm())
+}
+
+func replaceWithSymlink(path, linkTarget string) error {
+	tmpPath := uniqueSiblingPath(path, "link")
+	if err := os.Symlink(linkTarget, tmpPath); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	return syncDirectory(filepath.Dir(path))
+}
+
+func uniqueSiblingPath(path, purpose string) string {
+	name := fmt.Sprintf(".%s.%s-%d-%d", filepath.Base(path), purpose, os.Getpid(), time.Now().UnixNano())
+	return filepath.Join(filepath.Dir(path), name)
+}
+
+// Ensure OutfitInstaller implements the interface
+var _ interfaces.OutfitInstaller = (*OutfitInstaller)(nil)
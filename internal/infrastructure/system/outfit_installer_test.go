@@ -0,0 +1,221 @@
+package system
+
+import (
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+func TestOutfitInstaller_InstallCopy(t *testing.T) {
+	dir := t.TempDir()
+	first := writeInstallerFile(t, dir, "first.avatar", "first")
+	second := writeInstallerFile(t, dir, "second.avatar", "second")
+	slot := entities.ActivationSlot{Target: filepath.Join(dir, "slot", "current.avatar"), Mode: entities.ActivationModeCopy}
+	if err := os.MkdirAll(filepath.Dir(slot.Target), 0o700); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	installer := newTestOutfitInstaller(t)
+
+	if err := installer.Install(first, slot); err != nil {
+		t.Fatalf("Install(first) error = %v", err)
+	}
+	assertInstallerFile(t, slot.Target, "first")
+	if _, err := os.Lstat(slot.PreviousPath()); !os.IsNotExist(err) {
+		t.Fatalf("previous file exists after first install, stat error = %v", err)
+	}
+
+	if err := installer.Install(second, slot); err != nil {
+		t.Fatalf("Install(second) error = %v", err)
+	}
+	assertInstallerFile(t, slot.Target, "second")
+	assertInstallerFile(t, slot.PreviousPath(), "first")
+	assertInstallerFile(t, first, "first")
+
+	leftovers, err := filepath.Glob(filepath.Join(filepath.Dir(slot.Target), ".current.avatar.*"))
+	if err != nil {
+		t.Fatalf("Glob() error = %v", err)
+	}
+	if len(leftovers) != 0 {
+		t.Fatalf("temporary files left behind: %v", leftovers)
+	}
+	if _, err := os.Stat(slot.Target + ".lock"); !os.IsNotExist(err) {
+		t.Fatalf("lock file was not removed, stat error = %v", err)
+	}
+}
+
+func TestOutfitInstaller_InstallSymlinkAndRestore(t *testing.T) {
+	dir := t.TempDir()
+	first := writeInstallerFile(t, dir, "first.avatar", "first")
+	second := writeInstallerFile(t, dir, "second.avatar", "second")
+	slot := entities.ActivationSlot{Target: filepath.Join(dir, "current.avatar"), Mode: entities.ActivationModeSymlink}
+	installer := newTestOutfitInstaller(t)
+
+	if err := installer.Install(first, slot); err != nil {
+		t.Skipf("symlinks are not available: %v", err)
+	}
+	if err := installer.Install(second, slot); err != nil {
+		t.Fatalf("Install(second) error = %v", err)
+	}
+	assertInstallerLink(t, slot.Target, second)
+	assertInstallerLink(t, slot.PreviousPath(), first)
+
+	if err := installer.Restore(slot); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	assertInstallerLink(t, slot.Target, first)
+	assertInstallerLink(t, slot.PreviousPath(), second)
+
+	if err := installer.Restore(slot); err != nil {
+		t.Fatalf("second Restore() error = %v", err)
+	}
+	assertInstallerLink(t, slot.Target, second)
+}
+
+func TestOutfitInstaller_RestoreCopy(t *testing.T) {
+	dir := t.TempDir()
+	slot := entities.ActivationSlot{Target: filepath.Join(dir, "current.avatar"), Mode: entities.ActivationModeCopy}
+	installer := newTestOutfitInstaller(t)
+
+	if err := installer.Restore(slot); !stderrors.Is(err, errors.ErrNoPreviousActivation) {
+		t.Fatalf("Restore() without previous error = %v, want ErrNoPreviousActivation", err)
+	}
+
+	writeInstallerFile(t, dir, "current.avatar.previous", "previous")
+	if err := installer.Restore(slot); err != nil {
+		t.Fatalf("Restore() without current error = %v", err)
+	}
+	assertInstallerFile(t, slot.Target, "previous")
+	if _, err := os.Lstat(slot.PreviousPath()); !os.IsNotExist(err) {
+		t.Fatalf("previous file still exists, stat error = %v", err)
+	}
+
+	writeInstallerFile(t, dir, "current.avatar.previous", "older")
+	if err := installer.Restore(slot); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	assertInstallerFile(t, slot.Target, "older")
+	assertInstallerFile(t, slot.PreviousPath(), "previous")
+}
+
+func TestOutfitInstaller_InstallErrors(t *testing.T) {
+	dir := t.TempDir()
+	slot := entities.ActivationSlot{Target: filepath.Join(dir, "current.avatar"), Mode: entities.ActivationModeCopy}
+	installer := newTestOutfitInstaller(t)
+
+	if err := installer.Install(filepath.Join(dir, "missing.avatar"), slot); !os.IsNotExist(err) {
+		t.Fatalf("Install(missing) error = %v, want not exist", err)
+	}
+	if err := installer.Install(dir, slot); err == nil {
+		t.Fatal("Install(directory) error = nil, want error")
+	}
+
+	source := writeInstallerFile(t, dir, "look.avatar", "look")
+	missingDir := entities.ActivationSlot{Target: filepath.Join(dir, "missing", "current.avatar"), Mode: entities.ActivationModeCopy}
+	if err := installer.Install(source, missingDir); err == nil {
+		t.Fatal("Install() into missing directory error = nil, want error")
+	}
+	if _, err := os.Lstat(slot.Target); !os.IsNotExist(err) {
+		t.Fatalf("target created after failed installs, stat error = %v", err)
+	}
+}
+
+func TestOutfitInstaller_FailedInstallKeepsPreviousFile(t *testing.T) {
+	dir := t.TempDir()
+	slot := entities.ActivationSlot{Target: filepath.Join(dir, "current.avatar"), Mode: entities.ActivationModeCopy}
+	installer := newTestOutfitInstaller(t)
+	writeInstallerFile(t, dir, "current.avatar", "current")
+	writeInstallerFile(t, dir, "current.avatar.previous", "older")
+
+	if err := installer.Install(filepath.Join(dir, "missing.avatar"), slot); !os.IsNotExist(err) {
+		t.Fatalf("Install(missing) error = %v, want not exist", err)
+	}
+	assertInstallerFile(t, slot.Target, "current")
+	assertInstallerFile(t, slot.PreviousPath(), "older")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			t.Fatalf("failed install left %s behind", entry.Name())
+		}
+	}
+}
+
+func TestOutfitInstaller_KeepsSlotLocksInStateDirectory(t *testing.T) {
+	stateDir, targetDir := t.TempDir(), t.TempDir()
+	installer := NewOutfitInstaller(&mockDirectoryProvider{baseDirFunc: func() (string, error) { return stateDir, nil }}, 0)
+	slot := entities.ActivationSlot{Target: filepath.Join(targetDir, "current.avatar"), Mode: entities.ActivationModeCopy}
+	source := writeInstallerFile(t, t.TempDir(), "look.avatar", "look")
+
+	if err := installer.Install(source, slot); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if err := installer.Install(source, slot); err != nil {
+		t.Fatalf("Install() again error = %v", err)
+	}
+
+	entries, err := os.ReadDir(targetDir)
+	if err != nil {
+		t.Fatalf("ReadDir(target) error = %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if strings.Join(names, " ") != "current.avatar current.avatar.previous" {
+		t.Fatalf("target directory holds %v, want only the target and its previous file", names)
+	}
+	locks, err := os.ReadDir(filepath.Join(stateDir, appName, SlotLocksDirName))
+	if err != nil || len(locks) != 1 {
+		t.Fatalf("slot locks = %v, %v; want one lock file", locks, err)
+	}
+	if err := installer.Restore(entities.ActivationSlot{Target: filepath.Join(targetDir, "other.avatar")}); !stderrors.Is(err, errors.ErrNoPreviousActivation) {
+		t.Fatalf("Restore() error = %v, want %v", err, errors.ErrNoPreviousActivation)
+	}
+	if locks, _ = os.ReadDir(filepath.Join(stateDir, appName, SlotLocksDirName)); len(locks) != 2 {
+		t.Fatalf("slot locks = %d, want one per target", len(locks))
+	}
+}
+
+func newTestOutfitInstaller(t *testing.T) *OutfitInstaller {
+	t.Helper()
+	stateDir := t.TempDir()
+	return NewOutfitInstaller(&mockDirectoryProvider{baseDirFunc: func() (string, error) { return stateDir, nil }}, 0)
+}
+
+func writeInstallerFile(t *testing.T, dir, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	return path
+}
+
+func assertInstallerFile(t *testing.T, path, want string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile(%q) error = %v", path, err)
+	}
+	if string(data) != want {
+		t.Fatalf("%s contents = %q, want %q", path, data, want)
+	}
+}
+
+func assertInstallerLink(t *testing.T, path, want string) {
+	t.Helper()
+	got, err := os.Readlink(path)
+	if err != nil {
+		t.Fatalf("Readlink(%q) error = %v", path, err)
+	}
+	if got != want {
+		t.Fatalf("%s links to %q, want %q", path, got, want)
+	}
+}
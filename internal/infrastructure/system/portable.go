@@ -0,0 +1,72 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+type portableDirectoryProvider struct {
+	root string
+}
+
+// NewPortableDirectoryProvider keeps outfitpicker's files in the
+// .outfitpicker directory of the wardrobe at root, so they travel with it.
+func NewPortableDirectoryProvider(root string) DirectoryProvider {
+	return &portableDirectoryProvider{root: root}
+}
+
+func (p *portableDirectoryProvider) BaseDirectory() (string, error) {
+	return p.root, nil
+}
+
+func (p *portableDirectoryProvider) AppDirectory() (string, error) {
+	return filepath.Join(p.root, entities.PortableDirName), nil
+}
+
+// IsPortableWardrobe reports whether root keeps its own state in a
+// .outfitpicker directory.
+func IsPortableWardrobe(root string) bool {
+	info, err := os.Stat(filepath.Join(root, entities.PortableDirName))
+	return err == nil && info.IsDir()
+}
+
+// FindPortableWardrobe returns the nearest of start and its parents that is
+// a portable wardrobe.
+func FindPortableWardrobe(start string) (string, bool) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", false
+	}
+	for {
+		if IsPortableWardrobe(dir) {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// CreatePortableDirectory makes root a portable wardrobe. It fails if root is
+// not a directory or is portable already.
+func CreatePortableDirectory(root string) error {
+	info, err := os.Stat(root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return errors.NewInvalidInputError(root + " is not a directory")
+	}
+	if err := os.Mkdir(filepath.Join(root, entities.PortableDirName), 0700); err != nil {
+		if os.IsExist(err) {
+			return errors.NewAlreadyInitializedError(root)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package system
+
+import (
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+func TestPortableDirectoryProvider(t *testing.T) {
+	root := t.TempDir()
+	provider := NewPortableDirectoryProvider(root)
+
+	config := NewFileService[testConfig]("config.json", WithDirectoryProvider[testConfig](provider))
+	if path, err := config.FilePath(); err != nil || path != filepath.Join(root, ".outfitpicker", "config.json") {
+		t.Fatalf("FilePath() = %q, %v; want config.json in the wardrobe", path, err)
+	}
+	profiled := NewFileService[testConfig]("config.json", WithDirectoryProvider[testConfig](provider), WithProfile[testConfig]("alex"))
+	if path, err := profiled.FilePath(); err != nil || path != filepath.Join(root, ".outfitpicker", "profiles", "alex", "config.json") {
+		t.Fatalf("FilePath() for a profile = %q, %v", path, err)
+	}
+	if base, err := provider.BaseDirectory(); err != nil || base != root {
+		t.Fatalf("BaseDirectory() = %q, %v; want the wardrobe", base, err)
+	}
+
+	profile, err := NewProfileStore(provider).Create("sam")
+	if err != nil || profile.Dir != filepath.Join(root, ".outfitpicker", "profiles", "sam") {
+		t.Fatalf("Create() = %+v, %v; want the profile in the wardrobe", profile, err)
+	}
+}
+
+func TestCreatePortableDirectoryAndFind(t *testing.T) {
+	root := t.TempDir()
+	category := filepath.Join(root, "casual")
+	if err := os.Mkdir(category, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := FindPortableWardrobe(category); ok || IsPortableWardrobe(root) {
+		t.Fatal("found a portable wardrobe before one was created")
+	}
+
+	if err := CreatePortableDirectory(root); err != nil {
+		t.Fatalf("CreatePortableDirectory() error = %v", err)
+	}
+	if !IsPortableWardrobe(root) {
+		t.Fatal("IsPortableWardrobe() = false after CreatePortableDirectory()")
+	}
+	if found, ok := FindPortableWardrobe(category); !ok || found != root {
+		t.Fatalf("FindPortableWardrobe() = %q, %t; want %q", found, ok, root)
+	}
+	if err := CreatePortableDirectory(root); !stderrors.Is(err, errors.ErrAlreadyInitialized) {
+		t.Fatalf("CreatePortableDirectory() again error = %v, want %v", err, errors.ErrAlreadyInitialized)
+	}
+
+	file := filepath.Join(root, "notes.txt")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	var invalidInput *errors.InvalidInputError
+	if err := CreatePortableDirectory(file); !stderrors.As(err, &invalidInput) {
+		t.Fatalf("CreatePortableDirectory(file) error = %v, want invalid input", err)
+	}
+	if err := CreatePortableDirectory(filepath.Join(root, "missing")); !os.IsNotExist(err) {
+		t.Fatalf("CreatePortableDirectory(missing) error = %v, want not exist", err)
+	}
+	if err := os.WriteFile(filepath.Join(category, entities.PortableDirName), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if IsPortableWardrobe(category) {
+		t.Fatal("IsPortableWardrobe() = true for a .outfitpicker file")
+	}
+}
@@ -0,0 +1,208 @@
+package system
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+// ProfilesDirName is the directory, inside the default profile's, that holds
+// one directory per named profile.
+const ProfilesDirName = "profiles"
+
+// profileDirectory returns the directory holding profile's files in appDir.
+// The default profile keeps the layout from before profiles existed.
+func profileDirectory(appDir, profile string) string {
+	if entities.IsDefaultProfile(profile) {
+		return appDir
+	}
+	return filepath.Join(appDir, ProfilesDirName, profile)
+}
+
+// ProfileStore keeps each named profile in its own directory inside the
+// default profile's.
+type ProfileStore struct {
+	directoryProvider DirectoryProvider
+	uncopied          map[string]bool
+}
+
+// NewProfileStore returns a store for the profiles in directoryProvider's
+// outfitpicker directory. Copy leaves out the files and directories named in
+// uncopied, as well as lock files and other profiles.
+func NewProfileStore(directoryProvider DirectoryProvider, uncopied ...string) *ProfileStore {
+	store := &ProfileStore{directoryProvider: directoryProvider, uncopied: map[string]bool{ProfilesDirName: true, SlotLocksDirName: true}}
+	for _, name := range uncopied {
+		store.uncopied[name] = true
+	}
+	return store
+}
+
+func (s *ProfileStore) profile(name string) (entities.Profile, error) {
+	appDir, err := appDirectory(s.directoryProvider)
+	if err != nil {
+		return entities.Profile{}, err
+	}
+	if entities.IsDefaultProfile(name) {
+		name = entities.DefaultProfile
+	}
+	return entities.Profile{Name: name, Dir: profileDirectory(appDir, name)}, nil
+}
+
+func (s *ProfileStore) List() ([]entities.Profile, error) {
+	defaultProfile, err := s.profile(entities.DefaultProfile)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(defaultProfile.Dir, ProfilesDirName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	profiles := []entities.Profile{defaultProfile}
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		profile, err := s.profile(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	sort.Slice(profiles[1:], func(i, j int) bool { return profiles[i+1].Name < profiles[j+1].Name })
+	return profiles, nil
+}
+
+func (s *ProfileStore) Get(name string) (entities.Profile, error) {
+	profile, err := s.profile(name)
+	if err != nil || entities.IsDefaultProfile(name) {
+		return profile, err
+	}
+	if _, err := os.Stat(profile.Dir); err != nil {
+		if os.IsNotExist(err) {
+			return entities.Profile{}, errors.NewProfileNotFoundError(name)
+		}
+		return entities.Profile{}, err
+	}
+	return profile, nil
+}
+
+func (s *ProfileStore) Create(name string) (entities.Profile, error) {
+	profile, err := s.profile(name)
+	if err != nil {
+		return entities.Profile{}, err
+	}
+	if err := os.MkdirAll(filepath.Dir(profile.Dir), 0700); err != nil {
+		return entities.Profile{}, err
+	}
+	if err := os.Mkdir(profile.Dir, 0700); err != nil {
+		if os.IsExist(err) {
+			return entities.Profile{}, errors.NewProfileExistsError(name)
+		}
+		return entities.Profile{}, err
+	}
+	return profile, nil
+}
+
+func (s *ProfileStore) Delete(name string) error {
+	profile, err := s.Get(name)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(profile.Dir)
+}
+
+// Copy copies from's files into a temporary directory and renames it into
+// place, so a failed copy never leaves a half-filled profile behind.
+func (s *ProfileStore) Copy(from, to string) (entities.Profile, error) {
+	source, err := s.Get(from)
+	if err != nil {
+		return entities.Profile{}, err
+	}
+	target, err := s.profile(to)
+	if err != nil {
+		return entities.Profile{}, err
+	}
+	if _, err := os.Stat(target.Dir); err == nil {
+		return entities.Profile{}, errors.NewProfileExistsError(to)
+	}
+	if err := os.MkdirAll(filepath.Dir(target.Dir), 0700); err != nil {
+		return entities.Profile{}, err
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(target.Dir), "."+to+".tmp-")
+	if err != nil {
+		return entities.Profile{}, err
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := s.copyDirectory(source.Dir, tmp, true); err != nil {
+		return entities.Profile{}, err
+	}
+	if err := os.Rename(tmp, target.Dir); err != nil {
+		if _, statErr := os.Stat(target.Dir); statErr == nil {
+			return entities.Profile{}, errors.NewProfileExistsError(to)
+		}
+		return entities.Profile{}, err
+	}
+	return target, nil
+}
+
+// copyDirectory copies the regular files and directories under from into to.
+// A missing from, such as a default profile that was never set up, copies
+// nothing.
+func (s *ProfileStore) copyDirectory(from, to string, top bool) error {
+	entries, err := os.ReadDir(from)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if (top && s.uncopied[name]) || isLockOrTempFile(name) {
+			continue
+		}
+		source, target := filepath.Join(from, name), filepath.Join(to, name)
+		switch {
+		case entry.IsDir():
+			if err := os.Mkdir(target, 0700); err != nil {
+				return err
+			}
+			if err := s.copyDirectory(source, target, false); err != nil {
+				return err
+			}
+		case entry.Type().IsRegular():
+			if err := copyFile(source, target); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isLockOrTempFile(name string) bool {
+	return strings.HasSuffix(name, advisoryLockSuffix) || strings.HasSuffix(name, lockFileSuffix) ||
+		(strings.HasPrefix(name, ".") && strings.Contains(name, ".tmp-"))
+}
+
+func copyFile(from, to string) error {
+	source, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+	target, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(target, source); err != nil {
+		_ = target.Close()
+		return err
+	}
+	return target.Close()
+}
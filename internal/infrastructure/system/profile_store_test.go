@@ -0,0 +1,133 @@
+package system
+
+import (
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+func writeProfileTestFile(t *testing.T, path, contents string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProfileStore_CreateListAndDelete(t *testing.T) {
+	baseDir := t.TempDir()
+	store := NewProfileStore(newMockDirProvider(baseDir, nil))
+
+	profiles, err := store.List()
+	if err != nil || len(profiles) != 1 || profiles[0].Name != entities.DefaultProfile || profiles[0].Dir != filepath.Join(baseDir, "outfitpicker") {
+		t.Fatalf("List() before any profile = %+v, %v; want only the default profile", profiles, err)
+	}
+
+	for _, name := range []string{"sam", "alex"} {
+		if _, err := store.Create(name); err != nil {
+			t.Fatalf("Create(%q) error = %v", name, err)
+		}
+	}
+	if _, err := store.Create("alex"); !stderrors.Is(err, errors.ErrProfileExists) {
+		t.Fatalf("Create() of an existing profile error = %v, want %v", err, errors.ErrProfileExists)
+	}
+	writeProfileTestFile(t, filepath.Join(baseDir, "outfitpicker", "profiles", "stray.json"), "{}")
+
+	profiles, err = store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, profile := range profiles {
+		names = append(names, profile.Name)
+	}
+	if len(names) != 3 || names[0] != entities.DefaultProfile || names[1] != "alex" || names[2] != "sam" {
+		t.Fatalf("List() names = %v, want default, alex, sam", names)
+	}
+	if profiles[1].Dir != filepath.Join(baseDir, "outfitpicker", "profiles", "alex") {
+		t.Fatalf("alex dir = %q", profiles[1].Dir)
+	}
+
+	if profile, err := store.Get("alex"); err != nil || profile.Name != "alex" {
+		t.Fatalf("Get(alex) = %+v, %v", profile, err)
+	}
+	if profile, err := store.Get(""); err != nil || profile.Name != entities.DefaultProfile {
+		t.Fatalf("Get(\"\") = %+v, %v; want the default profile", profile, err)
+	}
+	if err := store.Delete("alex"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Get("alex"); !stderrors.Is(err, errors.ErrProfileNotFound) {
+		t.Fatalf("Get() after Delete() error = %v, want %v", err, errors.ErrProfileNotFound)
+	}
+	if err := store.Delete("alex"); !stderrors.Is(err, errors.ErrProfileNotFound) {
+		t.Fatalf("Delete() of a missing profile error = %v, want %v", err, errors.ErrProfileNotFound)
+	}
+}
+
+func TestProfileStore_Copy(t *testing.T) {
+	baseDir := t.TempDir()
+	defaultDir := filepath.Join(baseDir, "outfitpicker")
+	writeProfileTestFile(t, filepath.Join(defaultDir, "config.json"), `{"root":"/wardrobe"}`)
+	writeProfileTestFile(t, filepath.Join(defaultDir, "config.json.flock"), "")
+	writeProfileTestFile(t, filepath.Join(defaultDir, ".cache.json.tmp-123"), "")
+	writeProfileTestFile(t, filepath.Join(defaultDir, "history", "2026.jsonl"), "{}\n")
+	writeProfileTestFile(t, filepath.Join(defaultDir, "backups", "backup.tar.gz"), "archive")
+	store := NewProfileStore(newMockDirProvider(baseDir, nil), "backups")
+	if _, err := store.Create("alex"); err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := store.Copy(entities.DefaultProfile, "sam")
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(profile.Dir, "config.json")); err != nil || string(data) != `{"root":"/wardrobe"}` {
+		t.Fatalf("copied config = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(profile.Dir, "history", "2026.jsonl")); err != nil {
+		t.Fatalf("nested file was not copied: %v", err)
+	}
+	for _, left := range []string{"config.json.flock", ".cache.json.tmp-123", "backups", "profiles"} {
+		if _, err := os.Stat(filepath.Join(profile.Dir, left)); !os.IsNotExist(err) {
+			t.Fatalf("%s was copied: %v", left, err)
+		}
+	}
+
+	if _, err := store.Copy("sam", "alex"); !stderrors.Is(err, errors.ErrProfileExists) {
+		t.Fatalf("Copy() onto an existing profile error = %v, want %v", err, errors.ErrProfileExists)
+	}
+	if _, err := store.Copy("nobody", "kim"); !stderrors.Is(err, errors.ErrProfileNotFound) {
+		t.Fatalf("Copy() from a missing profile error = %v, want %v", err, errors.ErrProfileNotFound)
+	}
+	entries, err := os.ReadDir(filepath.Join(defaultDir, "profiles"))
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("profiles dir = %v, %v; want only alex and sam without temp dirs", entries, err)
+	}
+
+	if _, err := NewProfileStore(newMockDirProvider(t.TempDir(), nil)).Copy("", "kim"); err != nil {
+		t.Fatalf("Copy() of a default profile that was never set up error = %v", err)
+	}
+}
+
+func TestProfileStore_DirectoryErrors(t *testing.T) {
+	store := NewProfileStore(newMockDirProvider("", stderrors.New("no home")))
+	if _, err := store.List(); err == nil {
+		t.Fatal("List() error = nil, want the directory error")
+	}
+	if _, err := store.Get("alex"); err == nil {
+		t.Fatal("Get() error = nil, want the directory error")
+	}
+	if _, err := store.Create("alex"); err == nil {
+		t.Fatal("Create() error = nil, want the directory error")
+	}
+	if _, err := store.Copy("", "alex"); err == nil {
+		t.Fatal("Copy() error = nil, want the directory error")
+	}
+}
@@ -0,0 +1,94 @@
+package system
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+	"github.com/dh85/outfitpicker/internal/domain/interfaces"
+)
+
+// selectionResponseLimit caps how much plugin stdout is read.
+const selectionResponseLimit = 1 << 20
+
+// SelectionPluginRunner runs selection plugins with the request as JSON on
+// stdin and decodes their ranking from stdout.
+type SelectionPluginRunner struct {
+	stderr io.Writer
+}
+
+// NewSelectionPluginRunner creates a runner that forwards plugin stderr to
+// stderr.
+func NewSelectionPluginRunner(stderr io.Writer) *SelectionPluginRunner {
+	if stderr == nil {
+		stderr = io.Discard
+	}
+	return &SelectionPluginRunner{stderr: stderr}
+}
+
+// Select runs plugin and returns its response.
+func (r *SelectionPluginRunner) Select(plugin entities.SelectionPlugin, request entities.SelectionRequest) (entities.SelectionResponse, error) {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return entities.SelectionResponse{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), plugin.Timeout())
+	defer cancel()
+
+	stdout := limitedBuffer{limit: selectionResponseLimit}
+	cmd := exec.CommandContext(ctx, plugin.Command, plugin.Args...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout
+	cmd.Stderr = r.stderr
+	cmd.WaitDelay = hookWaitDelay
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return entities.SelectionResponse{}, fmt.Errorf("selection plugin %q %w", plugin.Command, errors.ErrTimedOut)
+	}
+	if err != nil {
+		return entities.SelectionResponse{}, fmt.Errorf("selection plugin %q failed: %w", plugin.Command, err)
+	}
+	if stdout.truncated {
+		return entities.SelectionResponse{}, fmt.Errorf("selection plugin %q wrote more than %d bytes", plugin.Command, selectionResponseLimit)
+	}
+
+	var response entities.SelectionResponse
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return entities.SelectionResponse{}, fmt.Errorf("selection plugin %q returned invalid JSON: %w", plugin.Command, err)
+	}
+	return response, nil
+}
+
+// limitedBuffer keeps the first limit bytes written to it and discards the
+// rest, so a runaway plugin cannot exhaust memory. It wraps rather than embeds
+// bytes.Buffer so that io.Copy cannot bypass Write through ReadFrom.
+type limitedBuffer struct {
+	buffer    bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buffer.Len(); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.buffer.Write(p)
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buffer.Bytes()
+}
+
+// Ensure SelectionPluginRunner implements the interface
+var _ interfaces.SelectionPluginRunner = (*SelectionPluginRunner)(nil)
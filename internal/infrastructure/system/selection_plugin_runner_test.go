@@ -0,0 +1,70 @@
+package system
+
+import (
+	stderrors "errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dh85/outfitpicker/internal/domain/entities"
+	"github.com/dh85/outfitpicker/internal/domain/errors"
+)
+
+func helperSelectionPlugin(t *testing.T, mode string, timeout time.Duration) entities.SelectionPlugin {
+	t.Helper()
+	t.Setenv(hookHelperModeEnv, mode)
+	plugin, err := entities.NewSelectionPlugin(os.Args[0], []string{"-test.run=^TestHookRunnerHelperProcess$"}, timeout)
+	if err != nil {
+		t.Fatalf("NewSelectionPlugin() error = %v", err)
+	}
+	return plugin
+}
+
+func selectionRequestFixture() entities.SelectionRequest {
+	category := entities.NewCategoryReference("shoes", "wardrobe/shoes")
+	return entities.NewSelectionRequest("", []entities.OutfitReference{
+		entities.NewOutfitReference("boots.avatar", category),
+		entities.NewOutfitReference("sandals.avatar", category),
+	}, nil, nil)
+}
+
+func TestSelectionPluginRunner_Select(t *testing.T) {
+	request := selectionRequestFixture()
+
+	response, err := NewSelectionPluginRunner(nil).Select(helperSelectionPlugin(t, "rank-last", 0), request)
+	if err != nil {
+		t.Fatalf("Select() error = %v", err)
+	}
+	index, err := response.Choose(request)
+	if err != nil || index != 1 {
+		t.Fatalf("Choose() = %d, %v, want last candidate", index, err)
+	}
+}
+
+func TestSelectionPluginRunner_Failures(t *testing.T) {
+	request := selectionRequestFixture()
+
+	tests := []struct {
+		name    string
+		mode    string
+		timeout time.Duration
+		want    string
+	}{
+		{name: "non-zero exit", mode: "fail", want: "exit status 3"},
+		{name: "invalid JSON", mode: "garbage", want: "invalid JSON"},
+		{name: "oversized response", mode: "flood", want: "wrote more than"},
+		{name: "timeout", mode: "sleep", timeout: time.Second, want: "timed out"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSelectionPluginRunner(nil).Select(helperSelectionPlugin(t, tt.mode, tt.timeout), request)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Select() error = %v, want %q", err, tt.want)
+			}
+			if tt.mode == "sleep" && !stderrors.Is(err, errors.ErrTimedOut) {
+				t.Fatalf("Select() error = %v, want ErrTimedOut", err)
+			}
+		})
+	}
+}